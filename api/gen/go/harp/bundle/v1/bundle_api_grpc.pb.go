@@ -38,6 +38,8 @@ const _ = grpc.SupportPackageIsVersion7
 type BundleAPIClient interface {
 	// GetSecret returns the matching RAW secret value according to requested path.
 	GetSecret(ctx context.Context, in *GetSecretRequest, opts ...grpc.CallOption) (*GetSecretResponse, error)
+	// ListPackages returns the package names matching the requested prefix.
+	ListPackages(ctx context.Context, in *ListPackagesRequest, opts ...grpc.CallOption) (*ListPackagesResponse, error)
 }
 
 type bundleAPIClient struct {
@@ -57,12 +59,23 @@ func (c *bundleAPIClient) GetSecret(ctx context.Context, in *GetSecretRequest, o
 	return out, nil
 }
 
+func (c *bundleAPIClient) ListPackages(ctx context.Context, in *ListPackagesRequest, opts ...grpc.CallOption) (*ListPackagesResponse, error) {
+	out := new(ListPackagesResponse)
+	err := c.cc.Invoke(ctx, "/harp.bundle.v1.BundleAPI/ListPackages", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BundleAPIServer is the server API for BundleAPI service.
 // All implementations must embed UnimplementedBundleAPIServer
 // for forward compatibility
 type BundleAPIServer interface {
 	// GetSecret returns the matching RAW secret value according to requested path.
 	GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error)
+	// ListPackages returns the package names matching the requested prefix.
+	ListPackages(context.Context, *ListPackagesRequest) (*ListPackagesResponse, error)
 	mustEmbedUnimplementedBundleAPIServer()
 }
 
@@ -72,6 +85,9 @@ type UnimplementedBundleAPIServer struct{}
 func (UnimplementedBundleAPIServer) GetSecret(context.Context, *GetSecretRequest) (*GetSecretResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetSecret not implemented")
 }
+func (UnimplementedBundleAPIServer) ListPackages(context.Context, *ListPackagesRequest) (*ListPackagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPackages not implemented")
+}
 func (UnimplementedBundleAPIServer) mustEmbedUnimplementedBundleAPIServer() {}
 
 // UnsafeBundleAPIServer may be embedded to opt out of forward compatibility for this service.
@@ -103,6 +119,24 @@ func _BundleAPI_GetSecret_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BundleAPI_ListPackages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPackagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BundleAPIServer).ListPackages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/harp.bundle.v1.BundleAPI/ListPackages",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BundleAPIServer).ListPackages(ctx, req.(*ListPackagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BundleAPI_ServiceDesc is the grpc.ServiceDesc for BundleAPI service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -114,6 +148,10 @@ var BundleAPI_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetSecret",
 			Handler:    _BundleAPI_GetSecret_Handler,
 		},
+		{
+			MethodName: "ListPackages",
+			Handler:    _BundleAPI_ListPackages_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "harp/bundle/v1/bundle_api.proto",
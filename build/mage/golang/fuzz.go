@@ -59,6 +59,21 @@ func FuzzRun(name string) func() error {
 	}
 }
 
+// FuzzNative starts a native go fuzzing process for the given fuzz target.
+// The fuzzing duration can be bounded with the FUZZTIME environment variable.
+func FuzzNative(packageName, target string) func() error {
+	return func() error {
+		fmt.Fprintf(os.Stdout, " > Fuzzing %s [%s]\n", target, packageName)
+
+		args := []string{"test", "-run", "^$", "-fuzz", fmt.Sprintf("^%s$", target)}
+		if fuzzTime := os.Getenv("FUZZTIME"); fuzzTime != "" {
+			args = append(args, "-fuzztime", fuzzTime)
+		}
+
+		return sh.RunV("go", append(args, packageName)...)
+	}
+}
+
 func existDir(fpath string) bool {
 	st, err := os.Stat(fpath)
 	if err != nil {
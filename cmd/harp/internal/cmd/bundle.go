@@ -40,6 +40,8 @@ var bundleCmd = func() *cobra.Command {
 	cmd.AddCommand(bundleFilterCmd())
 	cmd.AddCommand(bundleLintCmd())
 	cmd.AddCommand(bundlePrefixerCmd())
+	cmd.AddCommand(bundleMergeCmd())
+	cmd.AddCommand(bundleMoveCmd())
 
 	return cmd
 }
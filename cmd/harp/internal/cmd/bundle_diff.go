@@ -31,6 +31,7 @@ type bundleDiffParams struct {
 	sourcePath      string
 	destinationPath string
 	generatePatch   bool
+	summaryOnly     bool
 }
 
 var bundleDiffCmd = func() *cobra.Command {
@@ -50,6 +51,7 @@ var bundleDiffCmd = func() *cobra.Command {
 				DestinationReader: cmdutil.FileReader(params.destinationPath),
 				OutputWriter:      cmdutil.StdoutWriter(),
 				GeneratePatch:     params.generatePatch,
+				SummaryOnly:       params.summaryOnly,
 			}
 
 			// Run the task
@@ -64,6 +66,7 @@ var bundleDiffCmd = func() *cobra.Command {
 	cmd.Flags().StringVar(&params.destinationPath, "new", "", "Container path ('-' for stdin or filename)")
 	log.CheckErr("unable to mark 'dst' flag as required.", cmd.MarkFlagRequired("dst"))
 	cmd.Flags().BoolVar(&params.generatePatch, "patch", false, "Output as a bundle patch")
+	cmd.Flags().BoolVar(&params.summaryOnly, "summary", false, "Output value-level changes without secret values")
 
 	return cmd
 }
@@ -33,6 +33,8 @@ type bundleFilterParams struct {
 	excludePaths []string
 	keepPaths    []string
 	jmesPath     string
+	pathGlob     string
+	labels       map[string]string
 	reverseLogic bool
 }
 
@@ -55,6 +57,8 @@ var bundleFilterCmd = func() *cobra.Command {
 				ExcludePaths:    params.excludePaths,
 				KeepPaths:       params.keepPaths,
 				JMESPath:        params.jmesPath,
+				PathGlob:        params.pathGlob,
+				Labels:          params.labels,
 				ReverseLogic:    params.reverseLogic,
 			}
 
@@ -71,6 +75,8 @@ var bundleFilterCmd = func() *cobra.Command {
 	cmd.Flags().StringArrayVar(&params.excludePaths, "exclude", []string{}, "Exclude path")
 	cmd.Flags().StringArrayVar(&params.keepPaths, "keep", []string{}, "Keep path")
 	cmd.Flags().StringVar(&params.jmesPath, "query", "", "JMESPath query used as package filter")
+	cmd.Flags().StringVar(&params.pathGlob, "glob", "", "Package path glob ('**' for recursive match)")
+	cmd.Flags().StringToStringVar(&params.labels, "label", map[string]string{}, "Package label selector (key=value)")
 	cmd.Flags().BoolVar(&params.reverseLogic, "not", false, "Reverse filter logic expression")
 
 	return cmd
@@ -0,0 +1,81 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	sdkbundle "github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks"
+	"github.com/elastic/harp/pkg/tasks/bundle"
+)
+
+// -----------------------------------------------------------------------------
+type bundleMergeParams struct {
+	inputPaths []string
+	outputPath string
+	strategy   string
+}
+
+var bundleMergeCmd = func() *cobra.Command {
+	params := bundleMergeParams{}
+
+	cmd := &cobra.Command{
+		Use:   "merge",
+		Short: "Merge multiple containers",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-bundle-merge", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Parse strategy
+			strategy, err := sdkbundle.ParseMergeStrategy(params.strategy)
+			if err != nil {
+				log.For(ctx).Fatal("unable to prepare task", zap.Error(err))
+			}
+
+			// Prepare readers
+			readers := []tasks.ReaderProvider{}
+			for _, p := range params.inputPaths {
+				readers = append(readers, cmdutil.FileReader(p))
+			}
+
+			// Prepare task
+			t := &bundle.MergeTask{
+				ContainerReaders: readers,
+				OutputWriter:     cmdutil.FileWriter(params.outputPath),
+				Strategy:         strategy,
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringArrayVar(&params.inputPaths, "in", []string{}, "Container inputs merged in order (filenames)")
+	cmd.Flags().StringVar(&params.outputPath, "out", "", "Container output ('-' for stdout or a filename)")
+	cmd.Flags().StringVar(&params.strategy, "strategy", "error", "Conflict strategy (overwrite, skip, error)")
+
+	return cmd
+}
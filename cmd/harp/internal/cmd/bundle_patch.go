@@ -38,6 +38,7 @@ var bundlePatchCmd = func() *cobra.Command {
 		values       []string
 		stringValues []string
 		fileValues   []string
+		dryRun       bool
 	)
 
 	cmd := &cobra.Command{
@@ -66,6 +67,7 @@ var bundlePatchCmd = func() *cobra.Command {
 				PatchReader:     cmdutil.FileReader(patchPath),
 				OutputWriter:    cmdutil.FileWriter(outputPath),
 				Values:          values,
+				DryRun:          dryRun,
 			}
 
 			// Run the task
@@ -84,6 +86,7 @@ var bundlePatchCmd = func() *cobra.Command {
 	cmd.Flags().StringArrayVar(&values, "set", []string{}, "Specifies value (k=v)")
 	cmd.Flags().StringArrayVar(&stringValues, "set-string", []string{}, "Specifies value (k=string)")
 	cmd.Flags().StringArrayVar(&fileValues, "set-file", []string{}, "Specifies value (k=filepath)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Display patch changes as JSON instead of the patched container")
 
 	return cmd
 }
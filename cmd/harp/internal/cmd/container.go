@@ -33,7 +33,9 @@ var containerCmd = func() *cobra.Command {
 	// Bundle commands
 	cmd.AddCommand(containerIdentityCmd())
 	cmd.AddCommand(containerRecoveryCmd())
+	cmd.AddCommand(containerRewrapCmd())
 	cmd.AddCommand(containerSealCmd())
+	cmd.AddCommand(containerServeCmd())
 	cmd.AddCommand(containerUnsealCmd())
 
 	return cmd
@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/awnumar/memguard"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/container/identity"
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/container"
+)
+
+// -----------------------------------------------------------------------------
+
+type containerRewrapParams struct {
+	inputPath       string
+	outputPath      string
+	containerKeyRaw string
+	identities      []string
+}
+
+var containerRewrapCmd = func() *cobra.Command {
+	params := containerRewrapParams{}
+
+	cmd := &cobra.Command{
+		Use:   "rewrap",
+		Short: "Wrap the payload key of a sealed container for a new recipient set",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-container-rewrap", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare passphrase
+			containerKey := memguard.NewBufferFromBytes([]byte(params.containerKeyRaw))
+			if params.containerKeyRaw == "" {
+				var err error
+				// Read passphrase from stdin
+				containerKey, err = cmdutil.ReadSecret("Enter container key", false)
+				if err != nil {
+					log.For(ctx).Fatal("unable to read passphrase", zap.Error(err))
+				}
+			}
+			defer containerKey.Destroy()
+
+			// Convert identities to sealing keys
+			peerPublicKeys, err := identity.SealingKeys(params.identities...)
+			if err != nil {
+				log.For(ctx).Fatal("unable to transform identity to a sealing key", zap.Error(err))
+			}
+
+			// Prepare task
+			t := &container.RewrapTask{
+				ContainerReader: cmdutil.FileReader(params.inputPath),
+				OutputWriter:    cmdutil.FileWriter(params.outputPath),
+				ContainerKey:    containerKey,
+				PeerPublicKeys:  peerPublicKeys,
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&params.inputPath, "in", "", "Sealed container input ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&params.outputPath, "out", "", "Sealed container output ('-' for stdout or filename)")
+	log.CheckErr("unable to mark 'out' flag as required.", cmd.MarkFlagRequired("out"))
+	cmd.Flags().StringVar(&params.containerKeyRaw, "key", "", "Container key")
+	cmd.Flags().StringArrayVar(&params.identities, "identity", []string{}, "Identity allowed to unseal the new container")
+	log.CheckErr("unable to mark 'identity' flag as required.", cmd.MarkFlagRequired("identity"))
+
+	return cmd
+}
@@ -41,6 +41,8 @@ type containerSealParams struct {
 	target              string
 	noContainerIdentity bool
 	jsonOutput          bool
+	usePassword         bool
+	compress            bool
 }
 
 var containerSealCmd = func() *cobra.Command {
@@ -94,6 +96,19 @@ var containerSealCmd = func() *cobra.Command {
 				JSONOutput:               params.jsonOutput,
 				PeerPublicKeys:           peerPublicKeys,
 				DisableContainerIdentity: params.noContainerIdentity,
+				Compress:                 params.compress,
+			}
+
+			// Read sealing password
+			if params.usePassword {
+				password, errPassword := cmdutil.ReadSecret("Enter container password", true)
+				if errPassword != nil {
+					log.For(ctx).Fatal("unable to read password", zap.Error(errPassword))
+				}
+				defer password.Destroy()
+
+				// Assign password
+				t.Password = password
 			}
 
 			// Check container sealing master key usage
@@ -138,6 +153,8 @@ var containerSealCmd = func() *cobra.Command {
 	cmd.Flags().BoolVar(&params.noContainerIdentity, "no-container-identity", false, "Disable container identity")
 	cmd.Flags().StringVar(&params.masterKey, "dckd-master-key", "", "Master key used for deterministic container key derivation")
 	cmd.Flags().StringVar(&params.target, "dckd-target", "", "Target parameter for deterministic container key derivation")
+	cmd.Flags().BoolVar(&params.usePassword, "password", false, "Prompt for a password allowed to unseal")
+	cmd.Flags().BoolVar(&params.compress, "compress", false, "Compress the container content before encryption (bundle payloads are already compressed)")
 
 	return cmd
 }
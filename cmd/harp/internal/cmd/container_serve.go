@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/awnumar/memguard"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/container"
+)
+
+// -----------------------------------------------------------------------------
+
+type containerServeParams struct {
+	inputPath       string
+	containerKeyRaw string
+	usePassword     bool
+	listenAddress   string
+	tlsCertFile     string
+	tlsKeyFile      string
+	clientCAFile    string
+	rateLimit       float64
+	rateBurst       int
+}
+
+var containerServeCmd = func() *cobra.Command {
+	params := containerServeParams{}
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve sealed container secrets over mTLS gRPC",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-container-serve", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare task
+			t := &container.ServeTask{
+				ContainerReader: cmdutil.FileReader(params.inputPath),
+				ListenAddress:   params.listenAddress,
+				TLSCertFile:     params.tlsCertFile,
+				TLSKeyFile:      params.tlsKeyFile,
+				ClientCAFile:    params.clientCAFile,
+				RateLimit:       params.rateLimit,
+				RateBurst:       params.rateBurst,
+			}
+
+			// Prepare unsealing secret
+			var err error
+			switch {
+			case params.usePassword:
+				t.Password, err = cmdutil.ReadSecret("Enter container password", false)
+				if err != nil {
+					log.For(ctx).Fatal("unable to read password", zap.Error(err))
+				}
+				defer t.Password.Destroy()
+			case params.containerKeyRaw == "":
+				t.ContainerKey, err = cmdutil.ReadSecret("Enter container key", false)
+				if err != nil {
+					log.For(ctx).Fatal("unable to read passphrase", zap.Error(err))
+				}
+				defer t.ContainerKey.Destroy()
+			default:
+				t.ContainerKey = memguard.NewBufferFromBytes([]byte(params.containerKeyRaw))
+				defer t.ContainerKey.Destroy()
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&params.inputPath, "in", "", "Sealed container input ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&params.containerKeyRaw, "key", "", "Container key")
+	cmd.Flags().BoolVar(&params.usePassword, "password", false, "Unseal a password sealed container (prompted)")
+	cmd.Flags().StringVar(&params.listenAddress, "listen", "127.0.0.1:8443", "gRPC listen address")
+	cmd.Flags().StringVar(&params.tlsCertFile, "tls-cert", "", "Server certificate path")
+	log.CheckErr("unable to mark 'tls-cert' flag as required.", cmd.MarkFlagRequired("tls-cert"))
+	cmd.Flags().StringVar(&params.tlsKeyFile, "tls-key", "", "Server private key path")
+	log.CheckErr("unable to mark 'tls-key' flag as required.", cmd.MarkFlagRequired("tls-key"))
+	cmd.Flags().StringVar(&params.clientCAFile, "client-ca", "", "Client certificate authority path")
+	log.CheckErr("unable to mark 'client-ca' flag as required.", cmd.MarkFlagRequired("client-ca"))
+	cmd.Flags().Float64Var(&params.rateLimit, "rate-limit", 10, "Allowed requests per second per client certificate (0 to disable)")
+	cmd.Flags().IntVar(&params.rateBurst, "rate-burst", 20, "Rate limit burst size per client certificate")
+
+	return cmd
+}
@@ -31,7 +31,8 @@ import (
 type containerUnsealParams struct {
 	inputPath       string
 	outputPath      string
-	containerKeyRaw string
+	containerKeyRaw []string
+	usePassword     bool
 }
 
 var containerUnsealCmd = func() *cobra.Command {
@@ -45,23 +46,57 @@ var containerUnsealCmd = func() *cobra.Command {
 			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-container-unseal", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
 			defer cancel()
 
+			// Password sealed container
+			if params.usePassword {
+				password, err := cmdutil.ReadSecret("Enter container password", false)
+				if err != nil {
+					log.For(ctx).Fatal("unable to read password", zap.Error(err))
+				}
+				defer password.Destroy()
+
+				// Prepare task
+				t := &container.UnsealTask{
+					ContainerReader: cmdutil.FileReader(params.inputPath),
+					OutputWriter:    cmdutil.StdoutWriter(),
+					Password:        password,
+				}
+
+				// Run the task
+				if err := t.Run(ctx); err != nil {
+					log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+				}
+
+				return
+			}
+
 			// Prepare passphrase
-			containerKey := memguard.NewBufferFromBytes([]byte(params.containerKeyRaw))
-			if params.containerKeyRaw == "" {
+			var containerKey *memguard.LockedBuffer
+			if len(params.containerKeyRaw) == 0 || params.containerKeyRaw[0] == "" {
 				var err error
 				// Read passphrase from stdin
 				containerKey, err = cmdutil.ReadSecret("Enter container key", false)
 				if err != nil {
 					log.For(ctx).Fatal("unable to read passphrase", zap.Error(err))
 				}
+			} else {
+				containerKey = memguard.NewBufferFromBytes([]byte(params.containerKeyRaw[0]))
 			}
 			defer containerKey.Destroy()
 
+			// Prepare additional identities
+			containerKeys := []*memguard.LockedBuffer{}
+			for i := 1; i < len(params.containerKeyRaw); i++ {
+				k := memguard.NewBufferFromBytes([]byte(params.containerKeyRaw[i]))
+				defer k.Destroy()
+				containerKeys = append(containerKeys, k)
+			}
+
 			// Prepare task
 			t := &container.UnsealTask{
 				ContainerReader: cmdutil.FileReader(params.inputPath),
 				OutputWriter:    cmdutil.StdoutWriter(),
 				ContainerKey:    containerKey,
+				ContainerKeys:   containerKeys,
 			}
 
 			// Run the task
@@ -74,8 +109,8 @@ var containerUnsealCmd = func() *cobra.Command {
 	// Parameters
 	cmd.Flags().StringVar(&params.inputPath, "in", "", "Sealed container input ('-' for stdin or filename)")
 	cmd.Flags().StringVar(&params.outputPath, "out", "", "Unsealed container output ('-' for stdout or filename)")
-	cmd.Flags().StringVar(&params.containerKeyRaw, "key", "", "Container key")
-	log.CheckErr("unable to mark 'key' flag as required.", cmd.MarkFlagRequired("key"))
+	cmd.Flags().StringArrayVar(&params.containerKeyRaw, "key", []string{}, "Container key (repeatable, each key is tried until one matches a recipient)")
+	cmd.Flags().BoolVar(&params.usePassword, "password", false, "Prompt for the container password instead of a container key")
 
 	return cmd
 }
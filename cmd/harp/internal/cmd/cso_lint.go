@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/bundle"
+)
+
+// -----------------------------------------------------------------------------
+
+type csoLintParams struct {
+	inputPath  string
+	rulesPath  string
+	outputPath string
+}
+
+var csoLintCmd = func() *cobra.Command {
+	params := &csoLintParams{}
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "Lint bundle package paths using custom naming rules",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-cso-lint", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare task
+			t := &bundle.CSOLintTask{
+				ContainerReader: cmdutil.FileReader(params.inputPath),
+				RulesReader:     cmdutil.FileReader(params.rulesPath),
+				OutputWriter:    cmdutil.FileWriter(params.outputPath),
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&params.inputPath, "in", "-", "Container input ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&params.rulesPath, "rules", "", "Rules specification path ('-' for stdin or filename)")
+	log.CheckErr("unable to mark 'rules' flag as required.", cmd.MarkFlagRequired("rules"))
+	cmd.Flags().StringVar(&params.outputPath, "out", "-", "Violation report output ('-' for stdout or filename)")
+
+	return cmd
+}
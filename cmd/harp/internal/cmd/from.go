@@ -39,6 +39,8 @@ var fromCmd = func() *cobra.Command {
 	cmd.AddCommand(fromConsulCmd())
 	cmd.AddCommand(fromEtcd3Cmd())
 	cmd.AddCommand(fromZookeeperCmd())
+	cmd.AddCommand(fromKubernetesCmd())
+	cmd.AddCommand(fromEnvFileCmd())
 
 	return cmd
 }
@@ -40,6 +40,7 @@ var fromTemplateCmd = func() *cobra.Command {
 		values       []string
 		stringValues []string
 		fileValues   []string
+		sandbox      bool
 	)
 
 	cmd := &cobra.Command{
@@ -81,6 +82,7 @@ var fromTemplateCmd = func() *cobra.Command {
 					engine.WithName(inputPath),
 					engine.WithValues(values),
 					engine.WithFiles(files),
+					engine.WithSandbox(sandbox),
 				),
 			}
 
@@ -99,6 +101,7 @@ var fromTemplateCmd = func() *cobra.Command {
 	cmd.Flags().StringArrayVar(&values, "set", []string{}, "Specifies value (k=v)")
 	cmd.Flags().StringArrayVar(&stringValues, "set-string", []string{}, "Specifies value (k=string)")
 	cmd.Flags().StringArrayVar(&fileValues, "set-file", []string{}, "Specifies value (k=filepath)")
+	cmd.Flags().BoolVar(&sandbox, "sandbox", false, "Disable template functions giving access to the host environment (env, expandenv, getHostByName, secret, secretOr)")
 
 	return cmd
 }
@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/from"
+)
+
+// -----------------------------------------------------------------------------
+
+var fromEnvFileCmd = func() *cobra.Command {
+	var (
+		inputPath   string
+		outputPath  string
+		packagePath string
+		format      string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "envfile",
+		Short: "Convert a dotenv or CSV file to a secret-container package.",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-from-envfile", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare task
+			t := &from.EnvFileTask{
+				FileReader:   cmdutil.FileReader(inputPath),
+				OutputWriter: cmdutil.FileWriter(outputPath),
+				PackagePath:  packagePath,
+				CSV:          format == "csv",
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&inputPath, "in", "-", "Dotenv or CSV file path ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&outputPath, "out", "-", "Container output ('-' for stdout or filename)")
+	cmd.Flags().StringVar(&packagePath, "package", "", "Package path")
+	cmd.Flags().StringVar(&format, "format", "dotenv", "File format (dotenv / csv)")
+	log.CheckErr("unable to mark 'package' flag as required.", cmd.MarkFlagRequired("package"))
+
+	return cmd
+}
@@ -37,6 +37,7 @@ var fromVaultCmd = func() *cobra.Command {
 		withMetadata      bool
 		withVaultMetadata bool
 		maxWorkerCount    int64
+		secretVersion     uint32
 	)
 
 	cmd := &cobra.Command{
@@ -67,6 +68,7 @@ var fromVaultCmd = func() *cobra.Command {
 				WithMetadata:    withMetadata || withVaultMetadata,
 				AsVaultMetadata: withVaultMetadata,
 				MaxWorkerCount:  maxWorkerCount,
+				SecretVersion:   secretVersion,
 			}
 
 			// Run the task
@@ -84,6 +86,7 @@ var fromVaultCmd = func() *cobra.Command {
 	cmd.Flags().BoolVar(&withMetadata, "with-metadata", false, "Push container metadata as secret data")
 	cmd.Flags().BoolVar(&withVaultMetadata, "with-vault-metadata", false, "Push container metadata as secret metadata (requires Vault >=1.9)")
 	cmd.Flags().Int64Var(&maxWorkerCount, "worker-count", 4, "Active worker count limit")
+	cmd.Flags().Uint32Var(&secretVersion, "version", 0, "Pin the secret version to read (KV v2 only, latest if 0)")
 
 	return cmd
 }
@@ -39,7 +39,7 @@ var keygenPasetoCmd = func() *cobra.Command {
 			_, cancel := cmdutil.Context(cmd.Context(), "harp-keygen-paseto", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
 			defer cancel()
 
-			fmt.Fprintf(os.Stdout, "paseto:%s", base64.URLEncoding.EncodeToString(memguard.NewBufferRandom(32).Bytes()))
+			fmt.Fprintf(os.Stdout, "paseto:local:%s", base64.URLEncoding.EncodeToString(memguard.NewBufferRandom(32).Bytes()))
 		},
 	}
 
@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/bundle/keyring"
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/bundle"
+)
+
+// -----------------------------------------------------------------------------
+type pasetoRotateKeyParams struct {
+	inputPath      string
+	outputPath     string
+	packagePath    string
+	retention      time.Duration
+	revokePrevious bool
+}
+
+var pasetoRotateKeyCmd = func() *cobra.Command {
+	params := pasetoRotateKeyParams{}
+
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Rotate the v4.public signing key stored in a bundle keyring package",
+		Long: `Generate a new v4.public signing key in the given keyring package.
+
+The previous active key is kept as verify-only during the retention window,
+verify-only keys with an elapsed retention window are removed.`,
+		Example: `$ harp paseto rotate-key --in app.bundle --out app.bundle --package app/production/security/paseto/keyring --retention 168h`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-paseto-rotate-key", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare task
+			t := &bundle.PasetoRotateKeyTask{
+				ContainerReader: cmdutil.FileReader(params.inputPath),
+				OutputWriter:    cmdutil.FileWriter(params.outputPath),
+				PackagePath:     params.packagePath,
+				Retention:       params.retention,
+				RevokePrevious:  params.revokePrevious,
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&params.inputPath, "in", "-", "Container input ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&params.outputPath, "out", "", "Container output ('-' for stdout or a filename)")
+	cmd.Flags().StringVar(&params.packagePath, "package", "", "Keyring package path")
+	cmd.Flags().DurationVar(&params.retention, "retention", keyring.DefaultRetention, "Retention window of the previous signing key")
+	cmd.Flags().BoolVar(&params.revokePrevious, "revoke-previous", false, "Remove the previous signing key instead of keeping it as verify-only")
+	log.CheckErr("unable to mark 'package' flag as required.", cmd.MarkFlagRequired("package"))
+
+	return cmd
+}
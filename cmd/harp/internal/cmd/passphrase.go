@@ -20,6 +20,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
@@ -27,9 +28,13 @@ import (
 	"github.com/elastic/harp/pkg/sdk/cmdutil"
 	"github.com/elastic/harp/pkg/sdk/log"
 	"github.com/elastic/harp/pkg/sdk/security/diceware"
+	"github.com/elastic/harp/pkg/sdk/security/generator"
 )
 
-var passphraseWordCount int8
+var (
+	passphraseWordCount int8
+	passphraseProfile   string
+)
 
 // -----------------------------------------------------------------------------
 
@@ -42,6 +47,7 @@ var passphraseCmd = func() *cobra.Command {
 
 	// Parameters
 	cmd.Flags().Int8VarP(&passphraseWordCount, "word-count", "w", 8, "Word count in diceware passphrase")
+	cmd.Flags().StringVar(&passphraseProfile, "profile", "", fmt.Sprintf("Generate a credential using a named profile instead (%s)", strings.Join(generator.Profiles(), ", ")))
 
 	return cmd
 }
@@ -50,6 +56,20 @@ func runPassphrase(cmd *cobra.Command, args []string) {
 	ctx, cancel := cmdutil.Context(cmd.Context(), "harp-passphrase", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
 	defer cancel()
 
+	// Generate from profile
+	if passphraseProfile != "" {
+		s, err := generator.Password(passphraseProfile)
+		if err != nil {
+			log.For(ctx).Fatal("unable to generate credential", zap.Error(err))
+		}
+
+		// Print the credential, entropy is printed on stderr
+		// lgtm [go/clear-text-logging]
+		fmt.Fprintln(os.Stdout, s.Value)
+		fmt.Fprintf(os.Stderr, "Estimated entropy: %.1f bits\n", s.Entropy)
+		return
+	}
+
 	// Check lower limit
 	if passphraseWordCount < 4 {
 		passphraseWordCount = 4
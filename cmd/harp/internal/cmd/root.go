@@ -64,6 +64,7 @@ var mainCmd = func() *cobra.Command {
 
 	cmd.AddCommand(pluginCmd())
 	cmd.AddCommand(csoCmd())
+	cmd.AddCommand(pasetoCmd())
 
 	cmd.AddCommand(templateCmd())
 	cmd.AddCommand(valuesCmd())
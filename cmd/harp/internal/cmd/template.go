@@ -18,8 +18,11 @@
 package cmd
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/fs"
+	"os"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/spf13/afero"
@@ -27,10 +30,12 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/bundle/plan"
 	"github.com/elastic/harp/pkg/sdk/cmdutil"
 	"github.com/elastic/harp/pkg/sdk/log"
 	tplcmdutil "github.com/elastic/harp/pkg/template/cmdutil"
 	"github.com/elastic/harp/pkg/template/engine"
+	"github.com/elastic/harp/pkg/template/render"
 	"github.com/elastic/harp/pkg/vault/kv"
 )
 
@@ -46,6 +51,10 @@ var (
 	templateRightDelims   string
 	templateAltDelims     bool
 	templateRootPath      string
+	templatePasswordSeed  string
+	templateSandbox       bool
+	templatePartialsPath  string
+	templateDryRun        bool
 )
 
 // -----------------------------------------------------------------------------
@@ -70,6 +79,10 @@ var templateCmd = func() *cobra.Command {
 	cmd.Flags().StringVar(&templateLeftDelims, "left-delimiter", "{{", "Template left delimiter (default to '{{')")
 	cmd.Flags().StringVar(&templateRightDelims, "right-delimiter", "}}", "Template right delimiter (default to '}}')")
 	cmd.Flags().BoolVar(&templateAltDelims, "alt-delims", false, "Define '[[' and ']]' as template delimiters.")
+	cmd.Flags().StringVar(&templatePartialsPath, "partials", "", "Defines partials root path used by 'include' template function")
+	cmd.Flags().BoolVar(&templateSandbox, "sandbox", false, "Disable template functions giving access to the host environment (env, expandenv, getHostByName, secret, secretOr)")
+	cmd.Flags().BoolVar(&templateDryRun, "dry-run", false, "Display the output file change plan without writing it")
+	cmd.Flags().StringVar(&templatePasswordSeed, "password-seed-from", "", "Secret seed file used by 'passwordFor' to derive passwords (must be kept secret)")
 
 	return cmd
 }
@@ -111,6 +124,12 @@ func runTemplate(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	// Prepare partial loader
+	var partials fs.FS
+	if templatePartialsPath != "" {
+		partials = os.DirFS(templatePartialsPath)
+	}
+
 	// Drain reader
 	body, err := io.ReadAll(reader)
 	if err != nil {
@@ -153,6 +172,20 @@ func runTemplate(cmd *cobra.Command, args []string) {
 		secretReaders = append(secretReaders, bundle.SecretReader(b))
 	}
 
+	// Load password derivation seed
+	var passwordSeed []byte
+	if templatePasswordSeed != "" {
+		seedReader, errSeed := cmdutil.Reader(templatePasswordSeed)
+		if errSeed != nil {
+			log.For(ctx).Fatal("unable to open password seed", zap.Error(errSeed), zap.String("path", templatePasswordSeed))
+		}
+		seed, errSeed := io.ReadAll(seedReader)
+		if errSeed != nil {
+			log.For(ctx).Fatal("unable to read password seed", zap.Error(errSeed), zap.String("path", templatePasswordSeed))
+		}
+		passwordSeed = bytes.TrimSpace(seed)
+	}
+
 	// Compile and execute template
 	out, err := engine.RenderContext(engine.NewContext(
 		engine.WithName(templateInputPath),
@@ -160,11 +193,42 @@ func runTemplate(cmd *cobra.Command, args []string) {
 		engine.WithValues(values),
 		engine.WithFiles(files),
 		engine.WithSecretReaders(secretReaders...),
+		engine.WithPasswordSeed(passwordSeed),
+		engine.WithSandbox(templateSandbox),
+		engine.WithPartials(partials),
 	), string(body))
 	if err != nil {
 		log.For(ctx).Fatal("unable to produce output content", zap.Error(err), zap.String("path", templateInputPath))
 	}
 
+	// Display the change plan only
+	if templateDryRun {
+		if templateOutputPath == "" || templateOutputPath == "-" {
+			log.For(ctx).Fatal("dry-run mode requires a file output path")
+		}
+
+		p, errPlan := plan.New(plan.FileState(afero.NewOsFs())).Plan(ctx, map[string]map[string]interface{}{
+			templateOutputPath: {plan.FileContentKey: out},
+		})
+		if errPlan != nil {
+			log.For(ctx).Fatal("unable to compute output plan", zap.Error(errPlan), zap.String("path", templateOutputPath))
+		}
+		if errRender := p.Render(os.Stdout); errRender != nil {
+			log.For(ctx).Fatal("unable to display output plan", zap.Error(errRender))
+		}
+		return
+	}
+
+	// Skip unchanged output file
+	if templateOutputPath != "" && templateOutputPath != "-" {
+		changed, errWrite := render.WriteIfChanged(templateOutputPath, []byte(out))
+		if errWrite != nil {
+			log.For(ctx).Fatal("unable to write output file", zap.Error(errWrite), zap.String("path", templateOutputPath))
+		}
+		log.For(ctx).Debug("output rendered", zap.String("path", templateOutputPath), zap.Bool("changed", changed))
+		return
+	}
+
 	// Create output writer
 	writer, err := cmdutil.Writer(templateOutputPath)
 	if err != nil {
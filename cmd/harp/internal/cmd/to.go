@@ -36,6 +36,8 @@ var toCmd = func() *cobra.Command {
 	cmd.AddCommand(toEtcd3Cmd())
 	cmd.AddCommand(toConsulCmd())
 	cmd.AddCommand(toZookeeperCmd())
+	cmd.AddCommand(toKubernetesCmd())
+	cmd.AddCommand(toEnvFileCmd())
 
 	return cmd
 }
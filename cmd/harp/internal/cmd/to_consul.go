@@ -18,10 +18,13 @@
 package cmd
 
 import (
+	"context"
+
 	"github.com/hashicorp/consul/api"
 	"github.com/spf13/cobra"
 	"go.uber.org/zap"
 
+	"github.com/elastic/harp/pkg/bundle"
 	"github.com/elastic/harp/pkg/kv/consul"
 	"github.com/elastic/harp/pkg/sdk/cmdutil"
 	"github.com/elastic/harp/pkg/sdk/log"
@@ -34,6 +37,7 @@ type toConsulParams struct {
 	inputPath    string
 	secretAsLeaf bool
 	prefix       string
+	transaction  bool
 }
 
 var toConsulCmd = func() *cobra.Command {
@@ -57,6 +61,12 @@ var toConsulCmd = func() *cobra.Command {
 				return
 			}
 
+			// Import packages atomically
+			if params.transaction {
+				runToConsulTransaction(ctx, client.KV(), &params)
+				return
+			}
+
 			// Prepare store.
 			store := consul.Store(client.KV())
 			defer log.SafeClose(store, "unable to close consul store")
@@ -66,6 +76,7 @@ var toConsulCmd = func() *cobra.Command {
 				Store:           store,
 				ContainerReader: cmdutil.FileReader(params.inputPath),
 				SecretAsKey:     params.secretAsLeaf,
+				Prefix:          params.prefix,
 			}
 
 			// Run the task
@@ -80,6 +91,35 @@ var toConsulCmd = func() *cobra.Command {
 	cmd.Flags().StringVar(&params.inputPath, "in", "-", "Container path ('-' for stdin or filename)")
 	cmd.Flags().BoolVarP(&params.secretAsLeaf, "secret-as-leaf", "s", false, "Expand package path to secrets for provisioning")
 	cmd.Flags().StringVar(&params.prefix, "prefix", "", "Path prefix for insertion")
+	cmd.Flags().BoolVar(&params.transaction, "transaction", false, "Write packages using Consul KV transactions (by batches of 64 packages)")
 
 	return cmd
 }
+
+func runToConsulTransaction(ctx context.Context, client consul.TxnClient, params *toConsulParams) {
+	// Check arguments
+	if params.secretAsLeaf {
+		log.For(ctx).Fatal("secret expansion is not supported with transactions")
+		return
+	}
+
+	// Create the reader
+	reader, err := cmdutil.Reader(params.inputPath)
+	if err != nil {
+		log.For(ctx).Fatal("unable to open input bundle reader", zap.Error(err))
+		return
+	}
+
+	// Extract bundle from container
+	b, err := bundle.FromContainerReader(reader)
+	if err != nil {
+		log.For(ctx).Fatal("unable to load bundle", zap.Error(err))
+		return
+	}
+
+	// Delegate to importer
+	if err := consul.Import(ctx, client, b, params.prefix); err != nil {
+		log.For(ctx).Fatal("unable to import bundle in consul", zap.Error(err))
+		return
+	}
+}
@@ -18,12 +18,14 @@
 package cmd
 
 import (
+	"context"
 	"time"
 
 	"github.com/spf13/cobra"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 
+	"github.com/elastic/harp/pkg/bundle"
 	"github.com/elastic/harp/pkg/kv/etcd3"
 	"github.com/elastic/harp/pkg/sdk/cmdutil"
 	"github.com/elastic/harp/pkg/sdk/log"
@@ -37,6 +39,7 @@ type toEtcd3Params struct {
 	inputPath    string
 	secretAsLeaf bool
 	prefix       string
+	ttl          time.Duration
 
 	endpoints   []string
 	dialTimeout time.Duration
@@ -95,6 +98,12 @@ var toEtcd3Cmd = func() *cobra.Command {
 				return
 			}
 
+			// Import packages with leases
+			if params.ttl > 0 {
+				runToEtcd3WithTTL(ctx, client, &params)
+				return
+			}
+
 			// Prepare store.
 			store := etcd3.Store(client)
 			defer log.SafeClose(store, "unable to close etcd3 store")
@@ -119,6 +128,7 @@ var toEtcd3Cmd = func() *cobra.Command {
 	cmd.Flags().StringVar(&params.inputPath, "in", "-", "Container path ('-' for stdin or filename)")
 	cmd.Flags().BoolVarP(&params.secretAsLeaf, "secret-as-leaf", "s", false, "Expand package path to secrets for provisioning")
 	cmd.Flags().StringVar(&params.prefix, "prefix", "", "Path prefix for insertion")
+	cmd.Flags().DurationVar(&params.ttl, "ttl", 0, "Attach a lease with the given TTL to published packages, overridden by the 'harp.elastic.co/v1/package#ttl' package annotation")
 
 	cmd.Flags().StringArrayVar(&params.endpoints, "endpoints", []string{"http://localhost:2379"}, "Etcd cluster endpoints")
 	cmd.Flags().DurationVar(&params.dialTimeout, "dial-timeout", 15*time.Second, "Etcd cluster dial timeout")
@@ -134,3 +144,36 @@ var toEtcd3Cmd = func() *cobra.Command {
 
 	return cmd
 }
+
+func runToEtcd3WithTTL(ctx context.Context, client *clientv3.Client, params *toEtcd3Params) {
+	defer log.SafeClose(client, "unable to close etcd3 client")
+
+	// Check arguments
+	if params.secretAsLeaf {
+		log.For(ctx).Fatal("secret expansion is not supported with leases")
+		return
+	}
+
+	// Create the reader
+	reader, err := cmdutil.Reader(params.inputPath)
+	if err != nil {
+		log.For(ctx).Fatal("unable to open input bundle reader", zap.Error(err))
+		return
+	}
+
+	// Extract bundle from container
+	b, err := bundle.FromContainerReader(reader)
+	if err != nil {
+		log.For(ctx).Fatal("unable to load bundle", zap.Error(err))
+		return
+	}
+
+	// Delegate to importer
+	leases, err := etcd3.Import(ctx, client, b, etcd3.WithPrefix(params.prefix), etcd3.WithTTL(params.ttl))
+	if err != nil {
+		log.For(ctx).Fatal("unable to import bundle in etcd3", zap.Error(err))
+		return
+	}
+
+	log.For(ctx).Info("bundle packages published", zap.Int("leased", len(leases)))
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/tasks/to"
+)
+
+// -----------------------------------------------------------------------------
+
+var toKubernetesCmd = func() *cobra.Command {
+	var (
+		inputPath       string
+		outputPath      string
+		certPath        string
+		namespace       string
+		nameTemplate    string
+		propagateLabels bool
+	)
+
+	cmd := &cobra.Command{
+		Use:     "kubernetes",
+		Aliases: []string{"k8s"},
+		Short:   "Export all packages of a secret container as Kubernetes Secret manifests.",
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-to-kubernetes", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Prepare task
+			t := &to.KubernetesTask{
+				ContainerReader: cmdutil.FileReader(inputPath),
+				OutputWriter:    cmdutil.FileWriter(outputPath),
+				Namespace:       namespace,
+				NameTemplate:    nameTemplate,
+				PropagateLabels: propagateLabels,
+			}
+			if certPath != "" {
+				t.CertificateReader = cmdutil.FileReader(certPath)
+			}
+
+			// Run the task
+			if err := t.Run(ctx); err != nil {
+				log.For(ctx).Fatal("unable to execute task", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&inputPath, "in", "-", "Container path ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&outputPath, "out", "-", "Manifest output ('-' for stdout or filename)")
+	cmd.Flags().StringVar(&namespace, "namespace", "", "Namespace assigned to generated objects")
+	cmd.Flags().StringVar(&nameTemplate, "name-template", "", "Object name template ('.Path' and '.Labels' are available)")
+	cmd.Flags().BoolVar(&propagateLabels, "labels", false, "Propagate bundle and package labels")
+	cmd.Flags().StringVar(&certPath, "sealed-secrets-cert", "", "Sealed-secrets controller certificate path, produces SealedSecret objects")
+
+	return cmd
+}
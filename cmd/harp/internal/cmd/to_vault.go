@@ -36,6 +36,9 @@ var toVaultCmd = func() *cobra.Command {
 		withMetadata      bool
 		withVaultMetadata bool
 		maxWorkerCount    int64
+		checkAndSet       bool
+		createOnly        bool
+		dryRun            bool
 	)
 
 	cmd := &cobra.Command{
@@ -54,6 +57,10 @@ var toVaultCmd = func() *cobra.Command {
 				AsVaultMetadata: withVaultMetadata,
 				VaultNamespace:  namespace,
 				MaxWorkerCount:  maxWorkerCount,
+				CheckAndSet:     checkAndSet,
+				CreateOnly:      createOnly,
+				DryRun:          dryRun,
+				OutputWriter:    cmdutil.StdoutWriter(),
 			}
 
 			// Run the task
@@ -70,6 +77,10 @@ var toVaultCmd = func() *cobra.Command {
 	cmd.Flags().BoolVar(&withMetadata, "with-metadata", false, "Push container metadata as secret data")
 	cmd.Flags().BoolVar(&withVaultMetadata, "with-vault-metadata", false, "Push container metadata as secret metadata (requires Vault >=1.9)")
 	cmd.Flags().Int64Var(&maxWorkerCount, "worker-count", 4, "Active worker count limit")
+	cmd.Flags().BoolVar(&checkAndSet, "cas", false, "Use check-and-set writes with the container secret version (K/V v2 only)")
+	cmd.Flags().BoolVar(&createOnly, "create-only", false, "Only create missing secrets, existing secrets are not overwritten (K/V v2 only)")
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Display the secret path change plan without writing secrets")
 
 	return cmd
 }
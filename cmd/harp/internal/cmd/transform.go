@@ -31,6 +31,7 @@ var transformCmd = func() *cobra.Command {
 
 	// Add commands
 	cmd.AddCommand(transformEncryptionCmd())
+	cmd.AddCommand(transformConvertCmd())
 
 	return cmd
 }
@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cmd
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+// -----------------------------------------------------------------------------
+
+var transformConvertCmd = func() *cobra.Command {
+	var (
+		inputPath  string
+		outputPath string
+		fromRaw    string
+		toRaw      string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "convert",
+		Short:   "Re-encrypt a value from a transformer to another",
+		Example: `$ harp transform convert --in secret.enc --out secret.paseto --from aes-gcm:<key> --to paseto:<key>`,
+		Run: func(cmd *cobra.Command, args []string) {
+			// Initialize logger and context
+			ctx, cancel := cmdutil.Context(cmd.Context(), "harp-transform-convert", conf.Debug.Enable, conf.Instrumentation.Logs.Level)
+			defer cancel()
+
+			// Resolve tranformers
+			from, err := encryption.FromKey(fromRaw)
+			if err != nil {
+				log.For(ctx).Fatal("unable to initialize the source transformer from key", zap.Error(err))
+			}
+			to, err := encryption.FromKey(toRaw)
+			if err != nil {
+				log.For(ctx).Fatal("unable to initialize the target transformer from key", zap.Error(err))
+			}
+
+			// Read input
+			reader, err := cmdutil.Reader(inputPath)
+			if err != nil {
+				log.For(ctx).Fatal("unable to initialize input reader", zap.Error(err))
+			}
+
+			// Drain reader
+			content, err := io.ReadAll(reader)
+			if err != nil {
+				log.For(ctx).Fatal("unable to drain input reader", zap.Error(err))
+			}
+
+			// Convert value
+			out, err := value.ConvertContext(ctx, content, from, to)
+			if err != nil {
+				log.For(ctx).Fatal("unable to convert the input value", zap.Error(err))
+			}
+
+			// Prepare output
+			writer, err := cmdutil.Writer(outputPath)
+			if err != nil {
+				log.For(ctx).Fatal("unable to initialize output writer", zap.Error(err))
+			}
+
+			if _, err = writer.Write(out); err != nil {
+				log.For(ctx).Fatal("unable to write result to writer", zap.Error(err))
+			}
+		},
+	}
+
+	// Parameters
+	cmd.Flags().StringVar(&fromRaw, "from", "", "Source transformer key")
+	log.CheckErr("unable to mark 'from' flag as required.", cmd.MarkFlagRequired("from"))
+	cmd.Flags().StringVar(&toRaw, "to", "", "Target transformer key")
+	log.CheckErr("unable to mark 'to' flag as required.", cmd.MarkFlagRequired("to"))
+
+	cmd.Flags().StringVar(&inputPath, "in", "-", "Input path ('-' for stdin or filename)")
+	cmd.Flags().StringVar(&outputPath, "out", "-", "Output path ('-' for stdout or filename)")
+
+	return cmd
+}
@@ -25,8 +25,16 @@ import (
 	"github.com/elastic/harp/pkg/sdk/log"
 
 	// Register encryption transformers
+	_ "github.com/elastic/harp/pkg/kms/aws"
+	_ "github.com/elastic/harp/pkg/kms/azure"
+	_ "github.com/elastic/harp/pkg/kms/gcp"
+	_ "github.com/elastic/harp/pkg/sdk/value/compression"
+	_ "github.com/elastic/harp/pkg/sdk/value/encoding"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/age"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/cache"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/fernet"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/hkdf"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/jwe"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/paseto"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/secretbox"
@@ -0,0 +1,375 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package analyzer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" //nolint:gosec // required by the HaveIBeenPwned range API
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// RuleLeaked is reported when the value is found in a known breach corpus.
+const RuleLeaked = "leaked"
+
+const (
+	// DefaultHIBPBaseURL is the HaveIBeenPwned password range API endpoint.
+	DefaultHIBPBaseURL = "https://api.pwnedpasswords.com/range/"
+	hibpPrefixLength   = 5
+	hibpMaxRetries     = 3
+	hibpMaxRetryAfter  = 30 * time.Second
+	maxRangeBodySize   = 4 << 20
+)
+
+// RangeSource resolves SHA-1 hash suffixes for a 5 hex characters uppercase
+// prefix (k-anonymity model). The returned map associates the 35 characters
+// uppercase suffix to the breach occurrence count.
+type RangeSource interface {
+	Range(ctx context.Context, prefix string) (map[string]int, error)
+}
+
+// -----------------------------------------------------------------------------
+
+// HIBPOption is used to configure the HaveIBeenPwned range source.
+type HIBPOption func(*hibpSource)
+
+// WithHTTPClient sets the HTTP client used to query the range API.
+func WithHTTPClient(client *http.Client) HIBPOption {
+	return func(s *hibpSource) {
+		s.client = client
+	}
+}
+
+// WithBaseURL sets the range API endpoint, the prefix is appended to it.
+func WithBaseURL(u string) HIBPOption {
+	return func(s *hibpSource) {
+		s.baseURL = u
+	}
+}
+
+// WithRequestRate sets the maximum request rate sent to the range API.
+func WithRequestRate(r rate.Limit) HIBPOption {
+	return func(s *hibpSource) {
+		s.limiter = rate.NewLimiter(r, 1)
+	}
+}
+
+// HIBPSource returns a RangeSource backed by the HaveIBeenPwned range API.
+// Only the 5 first characters of the SHA-1 hash are sent, prefix lookups are
+// cached and HTTP 429 responses are retried according to `Retry-After`.
+func HIBPSource(opts ...HIBPOption) RangeSource {
+	s := &hibpSource{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		baseURL: DefaultHIBPBaseURL,
+		limiter: rate.NewLimiter(rate.Every(100*time.Millisecond), 1),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+
+	return CachedSource(s)
+}
+
+type hibpSource struct {
+	client  *http.Client
+	baseURL string
+	limiter *rate.Limiter
+}
+
+func (s *hibpSource) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	for attempt := 0; ; attempt++ {
+		// Respect local request rate
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		// Prepare request
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+prefix, http.NoBody)
+		if err != nil {
+			return nil, fmt.Errorf("unable to prepare range request: %w", err)
+		}
+		req.Header.Set("Add-Padding", "true")
+		req.Header.Set("User-Agent", "harp-analyzer")
+
+		// Send request
+		resp, err := s.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("unable to query range API: %w", err)
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests && attempt < hibpMaxRetries:
+			resp.Body.Close()
+			if err := sleep(ctx, retryAfter(resp.Header.Get("Retry-After"))); err != nil {
+				return nil, err
+			}
+			continue
+		case resp.StatusCode != http.StatusOK:
+			resp.Body.Close()
+			return nil, fmt.Errorf("unexpected range API status code %d", resp.StatusCode)
+		default:
+		}
+
+		// Decode response
+		res, err := parseRange(io.LimitReader(resp.Body, maxRangeBodySize))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode range API response: %w", err)
+		}
+
+		return res, nil
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// OfflineSource returns a RangeSource built from a `SHA1:COUNT` line based
+// hash list (HaveIBeenPwned downloadable format), allowing offline checks.
+func OfflineSource(r io.Reader) (RangeSource, error) {
+	// Check arguments
+	if types.IsNil(r) {
+		return nil, errors.New("reader is nil")
+	}
+
+	s := offlineSource{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		hash, count, err := parseRangeLine(line)
+		if err != nil {
+			return nil, err
+		}
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("invalid hash length %d", len(hash))
+		}
+		if s[hash[:hibpPrefixLength]] == nil {
+			s[hash[:hibpPrefixLength]] = map[string]int{}
+		}
+		s[hash[:hibpPrefixLength]][hash[hibpPrefixLength:]] += count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read hash list: %w", err)
+	}
+
+	return s, nil
+}
+
+type offlineSource map[string]map[string]int
+
+func (s offlineSource) Range(_ context.Context, prefix string) (map[string]int, error) {
+	if res, ok := s[prefix]; ok {
+		return res, nil
+	}
+	return map[string]int{}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// CachedSource wraps the given source to memoize prefix lookups.
+func CachedSource(source RangeSource) RangeSource {
+	return &cachedSource{
+		source: source,
+		cache:  map[string]map[string]int{},
+	}
+}
+
+type cachedSource struct {
+	sync.Mutex
+	source RangeSource
+	cache  map[string]map[string]int
+}
+
+func (s *cachedSource) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	if res, ok := s.cache[prefix]; ok {
+		return res, nil
+	}
+
+	res, err := s.source.Range(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	s.cache[prefix] = res
+
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// LeakOption is used to configure leaked secret analysis.
+type LeakOption func(*leakOptions)
+
+type leakOptions struct {
+	keyPattern *regexp.Regexp
+}
+
+// WithKeyPattern sets the secret key pattern used to select password values.
+func WithKeyPattern(re *regexp.Regexp) LeakOption {
+	return func(opts *leakOptions) {
+		opts.keyPattern = re
+	}
+}
+
+var defaultPasswordKeyPattern = regexp.MustCompile(`(?i)(pass(word|wd|phrase)?|pwd)`)
+
+// LeakedSecrets checks password secret values against the given range source.
+// Values are never sent, only the 5 first characters of their SHA-1 hash are
+// given to the source.
+func LeakedSecrets(ctx context.Context, b *bundlev1.Bundle, source RangeSource, opts ...LeakOption) ([]Finding, error) {
+	// Check arguments
+	if b == nil {
+		return nil, errors.New("unable to analyze a nil bundle")
+	}
+	if types.IsNil(source) {
+		return nil, errors.New("unable to analyze with a nil range source")
+	}
+
+	// Default options
+	dopts := &leakOptions{
+		keyPattern: defaultPasswordKeyPattern,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	findings := []Finding{}
+	for _, p := range b.Packages {
+		if p == nil || p.Secrets == nil {
+			continue
+		}
+		for _, kv := range p.Secrets.Data {
+			if kv == nil || !dopts.keyPattern.MatchString(kv.Key) {
+				continue
+			}
+
+			// Unpack value
+			var in interface{}
+			if err := secret.Unpack(kv.Value, &in); err != nil {
+				continue
+			}
+			value, ok := in.(string)
+			if !ok || value == "" {
+				continue
+			}
+
+			// Compute value hash
+			h := sha1.Sum([]byte(value)) //nolint:gosec // required by the HaveIBeenPwned range API
+			hash := strings.ToUpper(hex.EncodeToString(h[:]))
+
+			// Query source
+			suffixes, err := source.Range(ctx, hash[:hibpPrefixLength])
+			if err != nil {
+				return nil, fmt.Errorf("unable to check secret '%s' of package '%s': %w", kv.Key, p.Name, err)
+			}
+			if count := suffixes[hash[hibpPrefixLength:]]; count > 0 {
+				findings = append(findings, Finding{
+					Path:    p.Name,
+					Key:     kv.Key,
+					Rule:    RuleLeaked,
+					Message: fmt.Sprintf("secret value appears %d time(s) in known breaches", count),
+				})
+			}
+		}
+	}
+
+	// No error
+	return findings, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func parseRange(r io.Reader) (map[string]int, error) {
+	res := map[string]int{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		suffix, count, err := parseRangeLine(line)
+		if err != nil {
+			return nil, err
+		}
+		// Skip padding entries
+		if count == 0 {
+			continue
+		}
+		res[suffix] = count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func parseRangeLine(line string) (string, int, error) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return "", 0, errors.New("invalid range line, expected 'HASH:COUNT'")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+	if err != nil || count < 0 {
+		return "", 0, errors.New("invalid range line count")
+	}
+
+	return strings.ToUpper(strings.TrimSpace(parts[0])), count, nil
+}
+
+func retryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return time.Second
+	}
+	if d := time.Duration(seconds) * time.Second; d < hibpMaxRetryAfter {
+		return d
+	}
+	return hibpMaxRetryAfter
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
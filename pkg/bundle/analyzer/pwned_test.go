@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package analyzer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+)
+
+// SHA1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+
+func testLeakBundle(t *testing.T) *bundlev1.Bundle {
+	t.Helper()
+
+	b, err := bundle.FromMap(map[string]bundle.KV{
+		"app/production/db": {
+			"user":     "password",
+			"password": "password",
+		},
+		"app/staging/db": {
+			"db_pass": "password",
+			"pwd":     "Vg3r!x9Qm#Lp2Zt$8kWb",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to prepare bundle: %v", err)
+	}
+
+	return b
+}
+
+func TestLeakedSecrets_HIBP(t *testing.T) {
+	var calls, throttled int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Throttle first request
+		if atomic.AddInt32(&throttled, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, "true", r.Header.Get("Add-Padding"))
+		prefix := strings.TrimPrefix(r.URL.Path, "/range/")
+		assert.Len(t, prefix, 5)
+
+		if prefix == "5BAA6" {
+			fmt.Fprintf(w, "%s:3861493\r\n0000000000000000000000000000000000A:0\r\n", passwordSuffix)
+			return
+		}
+		fmt.Fprint(w, "0000000000000000000000000000000000A:0\r\n")
+	}))
+	defer srv.Close()
+
+	source := HIBPSource(
+		WithHTTPClient(srv.Client()),
+		WithBaseURL(srv.URL+"/range/"),
+		WithRequestRate(rate.Inf),
+	)
+
+	findings, err := LeakedSecrets(context.Background(), testLeakBundle(t), source)
+	assert.NoError(t, err)
+	assert.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.Equal(t, RuleLeaked, f.Rule)
+		assert.Contains(t, []string{"password", "db_pass"}, f.Key)
+		assert.Contains(t, f.Message, "3861493")
+	}
+
+	// Prefix lookups are cached
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLeakedSecrets_HIBPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	source := HIBPSource(WithHTTPClient(srv.Client()), WithBaseURL(srv.URL+"/"), WithRequestRate(rate.Inf))
+	_, err := LeakedSecrets(context.Background(), testLeakBundle(t), source)
+	assert.Error(t, err)
+	assert.NotContains(t, err.Error(), "Vg3r")
+}
+
+func TestLeakedSecrets_Offline(t *testing.T) {
+	source, err := OfflineSource(strings.NewReader("5baa6" + strings.ToLower(passwordSuffix) + ":12\n\n"))
+	assert.NoError(t, err)
+
+	findings, err := LeakedSecrets(context.Background(), testLeakBundle(t), source)
+	assert.NoError(t, err)
+	assert.Len(t, findings, 2)
+
+	// Invalid lists
+	for _, in := range []string{"5BAA6:12", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8", "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:x"} {
+		_, err := OfflineSource(strings.NewReader(in))
+		assert.Error(t, err)
+	}
+
+	// Invalid arguments
+	_, err = LeakedSecrets(context.Background(), nil, source)
+	assert.Error(t, err)
+	_, err = LeakedSecrets(context.Background(), testLeakBundle(t), nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,211 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package analyzer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+)
+
+const (
+	// RulePlaceholder is reported when the value matches a placeholder.
+	RulePlaceholder = "placeholder"
+	// RuleEmpty is reported when the value is empty or only contains spaces.
+	RuleEmpty = "empty"
+	// RuleRepeatedChar is reported when the value uses a single character.
+	RuleRepeatedChar = "repeated-char"
+	// RuleLowEntropy is reported when the value estimated entropy is below the
+	// threshold of its declared type.
+	RuleLowEntropy = "low-entropy"
+)
+
+// DefaultStringEntropyThreshold is the default minimal estimated entropy (in
+// bits) of string values.
+const DefaultStringEntropyThreshold = 28.0
+
+// DefaultPlaceholders returns the default placeholder list, values are compared
+// case-insensitively.
+func DefaultPlaceholders() []string {
+	return []string{
+		"changeme", "change_me", "change-me", "replaceme", "replace_me",
+		"todo", "tbd", "fixme", "placeholder", "dummy", "example", "sample",
+		"secret", "password", "passw0rd", "default", "undefined", "null", "nil",
+		"none", "xxx", "<secret>", "<password>", "${secret}",
+	}
+}
+
+// Finding describes a weak secret value. It never contains the value itself.
+type Finding struct {
+	Path    string  `json:"path"`
+	Key     string  `json:"key"`
+	Rule    string  `json:"rule"`
+	Entropy float64 `json:"entropy,omitempty"`
+	Message string  `json:"message"`
+}
+
+// Option is used to configure the weak secret analysis.
+type Option func(*options)
+
+type options struct {
+	placeholders map[string]struct{}
+	thresholds   map[string]float64
+}
+
+// WithPlaceholders replaces the placeholder list.
+func WithPlaceholders(values ...string) Option {
+	return func(opts *options) {
+		opts.placeholders = placeholderSet(values)
+	}
+}
+
+// WithEntropyThreshold sets the minimal estimated entropy (in bits) of values
+// declared with the given type (`string`, `[]uint8`, ...). A zero threshold
+// disables the entropy check for this type.
+func WithEntropyThreshold(valueType string, bits float64) Option {
+	return func(opts *options) {
+		opts.thresholds[valueType] = bits
+	}
+}
+
+// WeakSecrets returns findings for secret values that look like placeholders or
+// have a low estimated entropy. Only string and byte array values are analyzed.
+func WeakSecrets(b *bundlev1.Bundle, opts ...Option) []Finding {
+	findings := []Finding{}
+
+	// Check arguments
+	if b == nil {
+		return findings
+	}
+
+	// Default options
+	dopts := &options{
+		placeholders: placeholderSet(DefaultPlaceholders()),
+		thresholds: map[string]float64{
+			"string":  DefaultStringEntropyThreshold,
+			"[]uint8": DefaultStringEntropyThreshold,
+		},
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Analyze all values
+	for _, p := range b.Packages {
+		if p == nil || p.Secrets == nil {
+			continue
+		}
+		for _, kv := range p.Secrets.Data {
+			if kv == nil {
+				continue
+			}
+
+			// Unpack value
+			var in interface{}
+			if err := secret.Unpack(kv.Value, &in); err != nil {
+				continue
+			}
+
+			var value string
+			switch v := in.(type) {
+			case string:
+				value = v
+			case []byte:
+				value = string(v)
+			default:
+				continue
+			}
+
+			if f := analyze(dopts, kv.Type, value); f != nil {
+				f.Path = p.Name
+				f.Key = kv.Key
+				findings = append(findings, *f)
+			}
+		}
+	}
+
+	return findings
+}
+
+// Entropy returns the estimated entropy in bits of the given value, computed
+// as the Shannon entropy per character times the character count.
+func Entropy(value string) float64 {
+	// Count character frequencies
+	freqs := map[rune]float64{}
+	for _, r := range value {
+		freqs[r]++
+	}
+
+	// Compute Shannon entropy
+	length := float64(utf8.RuneCountInString(value))
+	h := 0.0
+	for _, count := range freqs {
+		p := count / length
+		h -= p * math.Log2(p)
+	}
+
+	return h * length
+}
+
+// -----------------------------------------------------------------------------
+
+func placeholderSet(values []string) map[string]struct{} {
+	res := map[string]struct{}{}
+	for _, v := range values {
+		res[strings.ToLower(v)] = struct{}{}
+	}
+	return res
+}
+
+func analyze(opts *options, valueType, value string) *Finding {
+	trimmed := strings.TrimSpace(value)
+
+	// Empty value
+	if trimmed == "" {
+		return &Finding{Rule: RuleEmpty, Message: "secret value is empty"}
+	}
+
+	// Placeholder value
+	if _, ok := opts.placeholders[strings.ToLower(trimmed)]; ok {
+		return &Finding{Rule: RulePlaceholder, Message: "secret value looks like a placeholder"}
+	}
+
+	// Single repeated character
+	if strings.Count(value, value[:1]) == len(value) {
+		return &Finding{Rule: RuleRepeatedChar, Message: "secret value uses a single repeated character"}
+	}
+
+	// Entropy threshold
+	threshold := opts.thresholds[valueType]
+	if threshold <= 0 {
+		return nil
+	}
+	if e := Entropy(value); e < threshold {
+		return &Finding{
+			Rule:    RuleLowEntropy,
+			Entropy: e,
+			Message: fmt.Sprintf("secret value estimated entropy %.1f bits is below %.1f bits", e, threshold),
+		}
+	}
+
+	return nil
+}
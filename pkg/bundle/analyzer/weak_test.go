@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package analyzer
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+)
+
+func TestEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, Entropy(""))
+	assert.Equal(t, 0.0, Entropy("aaaa"))
+	assert.Equal(t, 8.0, Entropy("abcd"))
+	assert.True(t, math.Abs(Entropy("aabb")-4.0) < 1e-9)
+	assert.True(t, Entropy("c2VjcmV0LXZhbHVlLWhpZ2gtZW50cm9weQ") > DefaultStringEntropyThreshold)
+}
+
+func TestWeakSecrets(t *testing.T) {
+	b, err := bundle.FromMap(map[string]bundle.KV{
+		"app/production/db": {
+			"empty":       "  ",
+			"placeholder": "ChangeMe",
+			"repeated":    "zzzzzzzzzzzzzzzzzzzzzzzzzzz",
+			"short":       "admin123",
+			"strong":      "Vg3r!x9Qm#Lp2Zt$8kWb",
+			"port":        5432,
+		},
+	})
+	assert.NoError(t, err)
+
+	findings := WeakSecrets(b)
+	byKey := map[string]Finding{}
+	for _, f := range findings {
+		assert.Equal(t, "app/production/db", f.Path)
+		assert.NotContains(t, f.Message, "admin123")
+		byKey[f.Key] = f
+	}
+	assert.Len(t, byKey, 4)
+	assert.Equal(t, RuleEmpty, byKey["empty"].Rule)
+	assert.Equal(t, RulePlaceholder, byKey["placeholder"].Rule)
+	assert.Equal(t, RuleRepeatedChar, byKey["repeated"].Rule)
+	assert.Equal(t, RuleLowEntropy, byKey["short"].Rule)
+	assert.Equal(t, 24.0, byKey["short"].Entropy)
+}
+
+func TestWeakSecrets_Options(t *testing.T) {
+	b, err := bundle.FromMap(map[string]bundle.KV{
+		"app/production/db": {
+			"password": "admin123",
+			"user":     "n/a",
+		},
+	})
+	assert.NoError(t, err)
+
+	findings := WeakSecrets(b,
+		WithPlaceholders("N/A"),
+		WithEntropyThreshold("string", 0),
+	)
+	assert.Equal(t, []Finding{
+		{Path: "app/production/db", Key: "user", Rule: RulePlaceholder, Message: "secret value looks like a placeholder"},
+	}, findings)
+
+	assert.Empty(t, WeakSecrets(nil))
+	assert.Empty(t, WeakSecrets(&bundlev1.Bundle{Packages: []*bundlev1.Package{nil, {Name: "empty"}}}))
+}
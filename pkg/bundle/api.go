@@ -51,6 +51,7 @@ var (
 	packageLabels               = "harp.elastic.co/v1/package#labels"
 	packageEncryptionAnnotation = "harp.elastic.co/v1/package#encryptionKeyAlias"
 	packageEncryptedValueType   = "harp.elastic.co/v1/package#encryptedValue"
+	bundleSignatureAnnotation   = "harp.elastic.co/v1/bundle#signature"
 )
 
 // AnnotationOwner defines annotations owner contract
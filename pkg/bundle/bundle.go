@@ -27,8 +27,12 @@ import (
 
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	csov1 "github.com/elastic/harp/pkg/cso/v1"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/hash"
 )
 
+// treeHashAlgorithm is the merkle tree hash function.
+const treeHashAlgorithm = hash.Blake2b512
+
 // Annotate a bundle object.
 func Annotate(obj AnnotationOwner, key, value string) {
 	updateStringMap(obj, obj.GetAnnotations(), "Annotations", key, value)
@@ -46,16 +50,10 @@ func Tree(b *bundlev1.Bundle) (*merkletree.Tree, *Statistic, error) {
 		return nil, nil, fmt.Errorf("unable to process nil bundle")
 	}
 
-	// Calculate merkle tree root
-	h, err := blake2b.New512(nil)
-	if err != nil {
-		return nil, nil, fmt.Errorf("unable to initialize hash function for merkle tree")
-	}
-
 	// Initialize merkle tree
-	tree := merkletree.New(h)
-	if err = tree.SetIndex(1); err != nil {
-		return nil, nil, fmt.Errorf("unable to initialize merkle tree")
+	tree, err := newTree()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	// Prepare statistics
@@ -72,35 +70,7 @@ func Tree(b *bundlev1.Bundle) (*merkletree.Tree, *Statistic, error) {
 
 	// All packages
 	for _, p := range b.Packages {
-		// Increment package count
-		stats.PackageCount++
-
-		// Check compliance with CSO
-		if errValidate := csov1.Validate(p.Name); errValidate == nil {
-			stats.CSOCompliantPackageNameCount++
-		}
-
-		// Prepare secret uri list
-		uris := []string{}
-
-		// Follow secret chain
-		if p.Secrets != nil {
-			for _, s := range p.Secrets.Data {
-				// Increment secret count
-				stats.SecretCount++
-
-				// Build merkle tree leaf
-				uris = append(uris, fmt.Sprintf("%s:%d:%s:%x", p.Name, p.Secrets.Version, s.Key, blake2b.Sum512(s.Value)))
-			}
-
-			// Sort them
-			sort.Strings(uris)
-
-			// Push sorted secret uri as proof
-			for _, u := range uris {
-				tree.Push([]byte(u))
-			}
-		}
+		pushPackage(tree, stats, p)
 	}
 
 	// Return the tree
@@ -137,6 +107,76 @@ func SecretReader(b *bundlev1.Bundle) func(path string) (map[string]interface{},
 }
 
 // -----------------------------------------------------------------------------
+
+func newTree() (*merkletree.Tree, error) {
+	// Calculate merkle tree root
+	h, err := hash.New(treeHashAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize hash function for merkle tree: %w", err)
+	}
+
+	// Initialize merkle tree
+	tree := merkletree.New(h)
+	if err = tree.SetIndex(1); err != nil {
+		return nil, fmt.Errorf("unable to initialize merkle tree")
+	}
+
+	return tree, nil
+}
+
+// pushPackage adds package secrets as merkle tree leaves, packages must be
+// pushed ordered by name.
+func pushPackage(tree *merkletree.Tree, stats *Statistic, p *bundlev1.Package) {
+	// Increment package count
+	stats.PackageCount++
+
+	// Check compliance with CSO
+	if errValidate := csov1.Validate(p.Name); errValidate == nil {
+		stats.CSOCompliantPackageNameCount++
+	}
+
+	// Increment secret count
+	if p.Secrets != nil {
+		stats.SecretCount += uint32(len(p.Secrets.Data))
+	}
+
+	// Push sorted secret uri as proof
+	for _, u := range packageLeaves(p) {
+		tree.Push(u)
+	}
+}
+
+// packageLeaves returns the sorted merkle tree leaves of the package secrets.
+func packageLeaves(p *bundlev1.Package) [][]byte {
+	// Prepare secret uri list
+	uris := []string{}
+
+	// Follow secret chain
+	if p.Secrets != nil {
+		for _, s := range p.Secrets.Data {
+			// Build merkle tree leaf
+			uris = append(uris, fmt.Sprintf("%s:%d:%s:%x", p.Name, p.Secrets.Version, s.Key, blake2b.Sum512(s.Value)))
+		}
+	}
+
+	// Follow secret history (absent for unversioned packages)
+	for _, chain := range p.Versions {
+		for _, s := range chain.Data {
+			uris = append(uris, fmt.Sprintf("%s@%d:%s:%x", p.Name, chain.Version, s.Key, blake2b.Sum512(s.Value)))
+		}
+	}
+
+	// Sort them
+	sort.Strings(uris)
+
+	leaves := make([][]byte, len(uris))
+	for i, u := range uris {
+		leaves[i] = []byte(u)
+	}
+
+	return leaves
+}
+
 func updateStringMap(obj interface{}, m map[string]string, fieldName, key, value string) {
 	// Check allocation
 	if m == nil {
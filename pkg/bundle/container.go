@@ -80,26 +80,10 @@ func ToContainerWriter(w io.Writer, b *bundlev1.Bundle) error {
 
 // FromContainer unwraps a Bundle from a secret container.
 func FromContainer(c *containerv1.Container) (*bundlev1.Bundle, error) {
-	// Check parameters
-	if types.IsNil(c) {
-		return nil, fmt.Errorf("unable to process nil container")
-	}
-
-	// Check headers
-	if types.IsNil(c.Headers) {
-		return nil, fmt.Errorf("unable to process nil container headers")
-	}
-	if c.Headers.ContentType != bundleContentType {
-		return nil, fmt.Errorf("invalid content type for Bundle loader")
-	}
-	if c.Headers.ContentEncoding != "gzip" {
-		return nil, fmt.Errorf("invalid content encoding for Bundle loader")
-	}
-
 	// Decompress bundle
-	zr, err := gzip.NewReader(bytes.NewReader(c.Raw))
+	zr, err := containerPayload(c)
 	if err != nil {
-		return nil, fmt.Errorf("unable to initialize compression reader")
+		return nil, err
 	}
 
 	// Delegate to bundle loader
@@ -140,3 +124,34 @@ func ToContainer(b *bundlev1.Bundle) (*containerv1.Container, error) {
 		Raw: payload.Bytes(),
 	}, nil
 }
+
+// -----------------------------------------------------------------------------
+
+// containerPayload returns the decompressed bundle stream from the given
+// container.
+func containerPayload(c *containerv1.Container) (io.Reader, error) {
+	// Check parameters
+	if types.IsNil(c) {
+		return nil, fmt.Errorf("unable to process nil container")
+	}
+
+	// Check headers
+	if types.IsNil(c.Headers) {
+		return nil, fmt.Errorf("unable to process nil container headers")
+	}
+	if c.Headers.ContentType != bundleContentType {
+		return nil, fmt.Errorf("invalid content type for Bundle loader")
+	}
+	if c.Headers.ContentEncoding != "gzip" {
+		return nil, fmt.Errorf("invalid content encoding for Bundle loader")
+	}
+
+	// Decompress bundle
+	zr, err := gzip.NewReader(bytes.NewReader(c.Raw))
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize compression reader")
+	}
+
+	// No error
+	return zr, nil
+}
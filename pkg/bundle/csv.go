@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// FromCSV builds a bundle with a single package from a `key,value` CSV stream.
+// The `key,value` header row is optional.
+func FromCSV(r io.Reader, packagePath string) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if types.IsNil(r) {
+		return nil, fmt.Errorf("unable to process nil reader")
+	}
+
+	keys := []string{}
+	values := map[string]string{}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CSV content: %w", err)
+		}
+
+		// Skip header
+		if row == 1 && strings.EqualFold(record[0], "key") && strings.EqualFold(record[1], "value") {
+			continue
+		}
+		if record[0] == "" {
+			return nil, fmt.Errorf("row %d: blank secret key", row)
+		}
+
+		if _, ok := values[record[0]]; !ok {
+			keys = append(keys, record[0])
+		}
+		values[record[0]] = record[1]
+	}
+
+	// Delegate to bundle builder
+	return singlePackageBundle(packagePath, keys, values)
+}
+
+// ToCSV writes the given package secrets as a `key,value` CSV stream with a
+// header row. Secret keys are written as-is, use ToDotEnv for environment
+// variable compatible names.
+func ToCSV(b *bundlev1.Bundle, packagePath string, w io.Writer) error {
+	// Check arguments
+	if types.IsNil(w) {
+		return fmt.Errorf("unable to process nil writer")
+	}
+
+	// Retrieve package
+	p, err := packageByName(b, packagePath)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"key", "value"}); err != nil {
+		return fmt.Errorf("unable to write CSV header: %w", err)
+	}
+	for _, kv := range sortedSecrets(p) {
+		value, err := secretBytes(kv)
+		if err != nil {
+			return err
+		}
+		if err := cw.Write([]string{kv.Key, string(value)}); err != nil {
+			return fmt.Errorf("unable to write CSV record: %w", err)
+		}
+	}
+
+	// Flush content
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("unable to write CSV content: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,57 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CSV(t *testing.T) {
+	input := "key,value\ndb.user,admin\ndb.password,\"p@ss, \"\"word\"\"\"\n"
+
+	b, err := FromCSV(strings.NewReader(input), "app/production/db")
+	assert.NoError(t, err)
+
+	secrets, err := AsSecretMap(b.Packages[0])
+	assert.NoError(t, err)
+	assert.Equal(t, KV{"db.user": "admin", "db.password": `p@ss, "word"`}, secrets)
+
+	// Header is optional
+	b, err = FromCSV(strings.NewReader("user,admin\n"), "app/production/db")
+	assert.NoError(t, err)
+	assert.Len(t, b.Packages[0].Secrets.Data, 1)
+
+	// Export
+	var out bytes.Buffer
+	assert.NoError(t, ToCSV(b, "app/production/db", &out))
+	assert.Equal(t, "key,value\nuser,admin\n", out.String())
+
+	// Invalid inputs
+	for _, input := range []string{"key,value,extra\n", ",value\n", "\"unterminated\n"} {
+		_, err := FromCSV(strings.NewReader(input), "app")
+		assert.Error(t, err, input)
+	}
+	_, err = FromCSV(nil, "app")
+	assert.Error(t, err)
+	assert.Error(t, ToCSV(b, "app/unknown", &out))
+	assert.Error(t, ToCSV(b, "app/production/db", nil))
+}
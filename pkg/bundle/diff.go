@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/security"
+)
+
+// DiffResult describes value-level differences between two bundles without
+// exposing secret values.
+type DiffResult struct {
+	AddedPackages   []string       `json:"added_packages,omitempty"`
+	RemovedPackages []string       `json:"removed_packages,omitempty"`
+	Packages        []*PackageDiff `json:"packages,omitempty"`
+}
+
+// HasChanges returns true when the compared bundles differ.
+func (r *DiffResult) HasChanges() bool {
+	return len(r.AddedPackages) > 0 || len(r.RemovedPackages) > 0 || len(r.Packages) > 0
+}
+
+// PackageDiff describes secret key differences of a package.
+type PackageDiff struct {
+	Name     string   `json:"name"`
+	Added    []string `json:"added,omitempty"`
+	Removed  []string `json:"removed,omitempty"`
+	Modified []string `json:"modified,omitempty"`
+	// ValueChanged is true when at least one secret present in both bundles
+	// has a different value.
+	ValueChanged bool `json:"value_changed"`
+}
+
+// Diff computes the differences between a and b bundles.
+//
+// Secret values are compared using their hashes in constant time and never
+// copied to the result, so that it can be displayed safely.
+func Diff(a, b *bundlev1.Bundle) (*DiffResult, error) {
+	// Check arguments
+	if a == nil {
+		return nil, errors.New("unable to diff with a nil source bundle")
+	}
+	if b == nil {
+		return nil, errors.New("unable to diff with a nil destination bundle")
+	}
+
+	// Index packages
+	srcIndex := packageIndex(a)
+	dstIndex := packageIndex(b)
+
+	res := &DiffResult{}
+
+	// Removed or modified packages
+	for name, srcSecrets := range srcIndex {
+		dstSecrets, ok := dstIndex[name]
+		if !ok {
+			res.RemovedPackages = append(res.RemovedPackages, name)
+			res.Packages = append(res.Packages, &PackageDiff{
+				Name:    name,
+				Removed: sortedKeys(srcSecrets),
+			})
+			continue
+		}
+
+		// Compare secrets
+		pd := &PackageDiff{Name: name}
+		for key, srcHash := range srcSecrets {
+			dstHash, ok := dstSecrets[key]
+			switch {
+			case !ok:
+				pd.Removed = append(pd.Removed, key)
+			case !security.SecureCompare(srcHash, dstHash):
+				pd.Modified = append(pd.Modified, key)
+				pd.ValueChanged = true
+			default:
+			}
+		}
+		for key := range dstSecrets {
+			if _, ok := srcSecrets[key]; !ok {
+				pd.Added = append(pd.Added, key)
+			}
+		}
+
+		// Skip unchanged packages
+		if len(pd.Added) == 0 && len(pd.Removed) == 0 && len(pd.Modified) == 0 {
+			continue
+		}
+
+		sort.Strings(pd.Added)
+		sort.Strings(pd.Removed)
+		sort.Strings(pd.Modified)
+		res.Packages = append(res.Packages, pd)
+	}
+
+	// Added packages
+	for name, dstSecrets := range dstIndex {
+		if _, ok := srcIndex[name]; ok {
+			continue
+		}
+
+		res.AddedPackages = append(res.AddedPackages, name)
+		res.Packages = append(res.Packages, &PackageDiff{
+			Name:  name,
+			Added: sortedKeys(dstSecrets),
+		})
+	}
+
+	// Ensure deterministic order
+	sort.Strings(res.AddedPackages)
+	sort.Strings(res.RemovedPackages)
+	sort.SliceStable(res.Packages, func(i, j int) bool {
+		return res.Packages[i].Name < res.Packages[j].Name
+	})
+
+	// No error
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// packageIndex returns secret value hashes indexed by package name and secret
+// key.
+func packageIndex(b *bundlev1.Bundle) map[string]map[string][]byte {
+	index := map[string]map[string][]byte{}
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+
+		secrets := map[string][]byte{}
+		if p.Secrets != nil {
+			for _, s := range p.Secrets.Data {
+				if s == nil {
+					continue
+				}
+				h := blake2b.Sum256(s.Value)
+				secrets[s.Key] = h[:]
+			}
+		}
+
+		index[p.Name] = secrets
+	}
+
+	return index
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
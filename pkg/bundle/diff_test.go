@@ -0,0 +1,95 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func diffBundle(packages map[string]map[string]string) *bundlev1.Bundle {
+	b := &bundlev1.Bundle{}
+	for name, secrets := range packages {
+		p := &bundlev1.Package{
+			Name:    name,
+			Secrets: &bundlev1.SecretChain{},
+		}
+		for k, v := range secrets {
+			p.Secrets.Data = append(p.Secrets.Data, &bundlev1.KV{Key: k, Value: []byte(v)})
+		}
+		b.Packages = append(b.Packages, p)
+	}
+	return b
+}
+
+func Test_Diff(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		_, err := Diff(nil, &bundlev1.Bundle{})
+		assert.Error(t, err)
+		_, err = Diff(&bundlev1.Bundle{}, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("identical", func(t *testing.T) {
+		a := diffBundle(map[string]map[string]string{
+			"app/production/db": {"user": "foo", "password": "bar"},
+		})
+		b := diffBundle(map[string]map[string]string{
+			"app/production/db": {"password": "bar", "user": "foo"},
+		})
+
+		res, err := Diff(a, b)
+		assert.NoError(t, err)
+		assert.False(t, res.HasChanges())
+	})
+
+	t.Run("changes", func(t *testing.T) {
+		a := diffBundle(map[string]map[string]string{
+			"app/production/db":    {"user": "foo", "password": "bar", "port": "5432"},
+			"app/production/cache": {"password": "secret"},
+			"app/production/queue": {"token": "unchanged"},
+		})
+		b := diffBundle(map[string]map[string]string{
+			"app/production/db":    {"user": "foo", "password": "rotated", "host": "db.internal"},
+			"app/production/queue": {"token": "unchanged"},
+			"app/production/smtp":  {"password": "new"},
+		})
+
+		res, err := Diff(a, b)
+		assert.NoError(t, err)
+		assert.True(t, res.HasChanges())
+		assert.Equal(t, &DiffResult{
+			AddedPackages:   []string{"app/production/smtp"},
+			RemovedPackages: []string{"app/production/cache"},
+			Packages: []*PackageDiff{
+				{Name: "app/production/cache", Removed: []string{"password"}},
+				{
+					Name:         "app/production/db",
+					Added:        []string{"host"},
+					Removed:      []string{"port"},
+					Modified:     []string{"password"},
+					ValueChanged: true,
+				},
+				{Name: "app/production/smtp", Added: []string{"password"}},
+			},
+		}, res)
+	})
+}
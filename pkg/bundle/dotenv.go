@@ -0,0 +1,276 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+var (
+	envIdentifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	envInvalidChars     = regexp.MustCompile(`[^A-Z0-9_]`)
+)
+
+// EnvKey returns the environment variable identifier for the given secret key.
+//
+// The key is uppercased, all characters out of `[A-Z0-9_]` are replaced by `_`
+// and the result is prefixed by `_` if it starts with a digit. For example
+// `db.password` becomes `DB_PASSWORD` and `2fa-seed` becomes `_2FA_SEED`.
+func EnvKey(key string) string {
+	name := envInvalidChars.ReplaceAllString(strings.ToUpper(key), "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// FromDotEnv builds a bundle with a single package from a dotenv stream.
+//
+// Supported syntax is `[export ]KEY=value` per line, with blank lines and `#`
+// comments ignored. Values can be single quoted (literal), double quoted
+// (with `\n`, `\r`, `\t`, `\"` and `\\` escapes) or unquoted (trimmed, ` #` starts
+// a comment).
+func FromDotEnv(r io.Reader, packagePath string) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if types.IsNil(r) {
+		return nil, fmt.Errorf("unable to process nil reader")
+	}
+
+	keys := []string{}
+	values := map[string]string{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip blank lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		// Split assignment
+		idx := strings.Index(line, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("line %d: missing '=' separator", lineNum)
+		}
+		key, raw := strings.TrimSpace(line[:idx]), strings.TrimSpace(line[idx+1:])
+		if !envIdentifierRegexp.MatchString(key) {
+			return nil, fmt.Errorf("line %d: '%s' is not a valid variable name", lineNum, key)
+		}
+
+		// Decode value
+		value, err := dotEnvValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read dotenv content: %w", err)
+	}
+
+	// Delegate to bundle builder
+	return singlePackageBundle(packagePath, keys, values)
+}
+
+// ToDotEnv writes the given package secrets as a dotenv stream. Secret keys are
+// converted using EnvKey, a warning is logged and the secret is skipped when
+// two keys have the same environment variable name.
+func ToDotEnv(b *bundlev1.Bundle, packagePath string, w io.Writer) error {
+	// Check arguments
+	if types.IsNil(w) {
+		return fmt.Errorf("unable to process nil writer")
+	}
+
+	// Retrieve package
+	p, err := packageByName(b, packagePath)
+	if err != nil {
+		return err
+	}
+
+	// Sanitize keys
+	names := []string{}
+	values := map[string][]byte{}
+	sources := map[string]string{}
+	for _, kv := range sortedSecrets(p) {
+		name := EnvKey(kv.Key)
+		if name == "" {
+			return fmt.Errorf("unable to convert '%s' secret key as a variable name", kv.Key)
+		}
+		if previous, ok := sources[name]; ok {
+			log.Bg().Warn("secret key collision after sanitization, secret is skipped", zap.String("package", packagePath), zap.String("key", kv.Key), zap.String("variable", name), zap.String("kept", previous))
+			continue
+		}
+
+		value, err := secretBytes(kv)
+		if err != nil {
+			return err
+		}
+
+		names = append(names, name)
+		values[name] = value
+		sources[name] = kv.Key
+	}
+	sort.Strings(names)
+
+	// Write assignments
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, quoteDotEnv(string(values[name]))); err != nil {
+			return fmt.Errorf("unable to write dotenv content: %w", err)
+		}
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func dotEnvValue(raw string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", fmt.Errorf("unterminated single quoted value")
+		}
+		return raw[1 : end+1], nil
+	case strings.HasPrefix(raw, `"`):
+		var sb strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return sb.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 'r':
+					sb.WriteByte('\r')
+				case 't':
+					sb.WriteByte('\t')
+				default:
+					sb.WriteByte(raw[i])
+				}
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated double quoted value")
+	default:
+		if idx := strings.Index(raw, " #"); idx >= 0 {
+			raw = raw[:idx]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+}
+
+// quoteDotEnv uses single quotes when possible to prevent variable expansion
+// by dotenv loaders.
+func quoteDotEnv(value string) string {
+	if !strings.ContainsAny(value, "'\n\r") {
+		return "'" + value + "'"
+	}
+
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + r.Replace(value) + `"`
+}
+
+func singlePackageBundle(packagePath string, keys []string, values map[string]string) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if strings.TrimSpace(packagePath) == "" {
+		return nil, fmt.Errorf("unable to build a package with a blank path")
+	}
+
+	chain := &bundlev1.SecretChain{
+		Data: make([]*bundlev1.KV, 0, len(keys)),
+	}
+	for _, k := range keys {
+		packed, err := secret.Pack(values[k])
+		if err != nil {
+			return nil, fmt.Errorf("unable to pack '%s' secret value: %w", k, err)
+		}
+
+		chain.Data = append(chain.Data, &bundlev1.KV{
+			Key:   k,
+			Type:  "string",
+			Value: packed,
+		})
+	}
+
+	// No error
+	return &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{
+				Name:    packagePath,
+				Secrets: chain,
+			},
+		},
+	}, nil
+}
+
+func packageByName(b *bundlev1.Bundle, packagePath string) (*bundlev1.Package, error) {
+	// Check arguments
+	if b == nil {
+		return nil, fmt.Errorf("unable to process nil bundle")
+	}
+
+	for _, p := range b.Packages {
+		if p == nil || p.Name != packagePath {
+			continue
+		}
+		if p.Secrets == nil || p.Secrets.Locked != nil {
+			return nil, fmt.Errorf("package '%s' has no readable secrets", packagePath)
+		}
+		return p, nil
+	}
+
+	return nil, fmt.Errorf("unable to lookup package with path '%s'", packagePath)
+}
+
+func sortedSecrets(p *bundlev1.Package) []*bundlev1.KV {
+	kvs := []*bundlev1.KV{}
+	for _, kv := range p.Secrets.Data {
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	sort.SliceStable(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+
+	return kvs
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+)
+
+func Test_EnvKey(t *testing.T) {
+	testCases := map[string]string{
+		"password":    "PASSWORD",
+		"db.password": "DB_PASSWORD",
+		"2fa-seed":    "_2FA_SEED",
+		"API_KEY":     "API_KEY",
+		"clé":         "CL_",
+		"":            "",
+	}
+	for in, expected := range testCases {
+		assert.Equal(t, expected, EnvKey(in), in)
+	}
+}
+
+func Test_FromDotEnv(t *testing.T) {
+	input := `
+# Database settings
+DB_USER=admin
+export DB_PASSWORD='p@ss $word # not a comment'
+DB_HOST=db.internal # inline comment
+MULTILINE="line1\nline2 \"quoted\" \\"
+EMPTY=
+DB_USER=root
+`
+
+	b, err := FromDotEnv(strings.NewReader(input), "app/production/db")
+	assert.NoError(t, err)
+	assert.Len(t, b.Packages, 1)
+	assert.Equal(t, "app/production/db", b.Packages[0].Name)
+
+	secrets, err := AsSecretMap(b.Packages[0])
+	assert.NoError(t, err)
+	assert.Equal(t, KV{
+		"DB_USER":     "root",
+		"DB_PASSWORD": "p@ss $word # not a comment",
+		"DB_HOST":     "db.internal",
+		"MULTILINE":   "line1\nline2 \"quoted\" \\",
+		"EMPTY":       "",
+	}, secrets)
+
+	// Invalid inputs
+	for _, input := range []string{"NOVALUE", "1KEY=value", "my-key=value", "KEY='unterminated", `KEY="unterminated`} {
+		_, err := FromDotEnv(strings.NewReader(input), "app")
+		assert.Error(t, err, input)
+	}
+	_, err = FromDotEnv(strings.NewReader("KEY=value"), "")
+	assert.Error(t, err)
+	_, err = FromDotEnv(nil, "app")
+	assert.Error(t, err)
+}
+
+func Test_ToDotEnv(t *testing.T) {
+	b := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{
+				Name: "app/production/db",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: secret.MustPack("admin")},
+						{Key: "db.password", Value: secret.MustPack("skipped")},
+						{Key: "db-password", Value: secret.MustPack("collision")},
+						{Key: "port", Value: secret.MustPack(5432)},
+						{Key: "cert", Value: secret.MustPack("it's a \"cert\"\n")},
+					},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	assert.NoError(t, ToDotEnv(b, "app/production/db", &out))
+	assert.Equal(t, "CERT=\"it's a \\\"cert\\\"\\n\"\nDB_PASSWORD='collision'\nPORT='5432'\nUSER='admin'\n", out.String())
+
+	// Round-trip
+	imported, err := FromDotEnv(&out, "app/production/db")
+	assert.NoError(t, err)
+	secrets, err := AsSecretMap(imported.Packages[0])
+	assert.NoError(t, err)
+	assert.Equal(t, KV{"CERT": "it's a \"cert\"\n", "DB_PASSWORD": "collision", "PORT": "5432", "USER": "admin"}, secrets)
+
+	// Errors
+	assert.Error(t, ToDotEnv(b, "app/unknown", &out))
+	assert.Error(t, ToDotEnv(nil, "app/production/db", &out))
+	assert.Error(t, ToDotEnv(b, "app/production/db", nil))
+}
@@ -24,9 +24,11 @@ import (
 
 	"github.com/awnumar/memguard"
 	"github.com/golang/protobuf/ptypes/wrappers"
+	"go.uber.org/zap"
 
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/log"
 	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/sdk/value"
 )
@@ -65,38 +67,13 @@ func PartialLock(ctx context.Context, b *bundlev1.Bundle, transformerMap map[str
 			return fmt.Errorf("key alias '%s' refers to a nil transformer", keyAlias)
 		}
 
-		// Convert secret as a map
-		secrets := map[string]interface{}{}
-		for _, s := range p.Secrets.Data {
-			var out interface{}
-			if err := secret.Unpack(s.Value, &out); err != nil {
-				return fmt.Errorf("unable to load secret value, corrupted bundle: %w", err)
-			}
-
-			// Assign to secret map
-			secrets[s.Key] = out
-		}
-
-		// Export secrets as JSON
-		content, err := json.Marshal(secrets)
+		// Lock active and archived secret chains
+		size, err := lockPackage(ctx, p, transformer)
 		if err != nil {
-			return fmt.Errorf("unable to extract secret map as json")
+			return err
 		}
 
-		// Apply transformer
-		out, err := transformer.To(ctx, content)
-		if err != nil {
-			return fmt.Errorf("unable to apply secret transformer: %w", err)
-		}
-
-		// Cleanup
-		memguard.WipeBytes(content)
-		p.Secrets.Data = nil
-
-		// Assign locked secret
-		p.Secrets.Locked = &wrappers.BytesValue{
-			Value: out,
-		}
+		log.For(ctx).Debug("transformer applied", zap.String("operation", "lock"), zap.String("package", p.Name), zap.String("key_alias", keyAlias), zap.Int("size", size))
 	}
 
 	// No error
@@ -115,38 +92,13 @@ func Lock(ctx context.Context, b *bundlev1.Bundle, transformer value.Transformer
 
 	// For each packages
 	for _, p := range b.Packages {
-		// Convert secret as a map
-		secrets := map[string]interface{}{}
-		for _, s := range p.Secrets.Data {
-			var out interface{}
-			if err := secret.Unpack(s.Value, &out); err != nil {
-				return fmt.Errorf("unable to load secret value, corrupted bundle: %w", err)
-			}
-
-			// Assign to secret map
-			secrets[s.Key] = out
-		}
-
-		// Export secrets as JSON
-		content, err := json.Marshal(secrets)
-		if err != nil {
-			return fmt.Errorf("unable to extract secret map as json")
-		}
-
-		// Apply transformer
-		out, err := transformer.To(ctx, content)
+		// Lock active and archived secret chains
+		size, err := lockPackage(ctx, p, transformer)
 		if err != nil {
-			return fmt.Errorf("unable to apply secret transformer: %w", err)
+			return err
 		}
 
-		// Cleanup
-		memguard.WipeBytes(content)
-		p.Secrets.Data = nil
-
-		// Assign locked secret
-		p.Secrets.Locked = &wrappers.BytesValue{
-			Value: out,
-		}
+		log.For(ctx).Debug("transformer applied", zap.String("operation", "lock"), zap.String("package", p.Name), zap.Int("size", size))
 	}
 
 	// No error
@@ -165,6 +117,16 @@ func UnLock(ctx context.Context, b *bundlev1.Bundle, transformers []value.Transf
 
 	// For each packages
 	for _, p := range b.Packages {
+		// Unlock archived secret chains
+		for _, chain := range p.Versions {
+			if err := unlockChain(ctx, chain, transformers); err != nil {
+				if skipNotDecryptable {
+					continue
+				}
+				return fmt.Errorf("unable to transform '%s' version %d: %w", p.Name, chain.Version, err)
+			}
+		}
+
 		// Skip not locked package
 		if p.Secrets.Locked == nil {
 			continue
@@ -173,62 +135,133 @@ func UnLock(ctx context.Context, b *bundlev1.Bundle, transformers []value.Transf
 			continue
 		}
 
-		// Try all transformers
-		var (
-			out          []byte
-			errTransform error
-		)
-	LOOP:
-		for _, t := range transformers {
-			// Apply transformation
-			out, errTransform = t.From(ctx, p.Secrets.Locked.Value)
-			switch {
-			case errTransform != nil:
-				// Try next transformer
-				continue
-			default:
-				break LOOP
-			}
-		}
-		if errTransform != nil {
+		// Unlock active secret chain
+		if err := unlockChain(ctx, p.Secrets, transformers); err != nil {
 			if skipNotDecryptable {
 				// Skip not decrypted secrets.
 				continue
 			}
-			return fmt.Errorf("unable to transform '%s': %w", p.Name, errTransform)
+			return fmt.Errorf("unable to transform '%s': %w", p.Name, err)
 		}
 
-		// Unpack secrets
-		raw := map[string]interface{}{}
-		if err := json.Unmarshal(out, &raw); err != nil {
-			return fmt.Errorf("unable to unpack locked secret: %w", err)
+		log.For(ctx).Debug("transformer applied", zap.String("operation", "unlock"), zap.String("package", p.Name), zap.Int("secrets", len(p.Secrets.Data)))
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// lockPackage locks the active and archived secret chains of the given
+// package, the active locked value size is returned.
+func lockPackage(ctx context.Context, p *bundlev1.Package, transformer value.Transformer) (int, error) {
+	// Lock secret history
+	for _, chain := range p.Versions {
+		if _, err := lockChain(ctx, chain, transformer); err != nil {
+			return 0, err
 		}
+	}
 
-		// Prepare secrets collection
-		secrets := []*bundlev1.KV{}
-		for key, value := range raw {
-			// Pack secret value
-			s, err := secret.Pack(value)
-			if err != nil {
-				return fmt.Errorf("unable to pack as secret bundle: %w", err)
-			}
+	// Delegate to chain locker
+	return lockChain(ctx, p.Secrets, transformer)
+}
 
-			// Add to secret collection
-			secrets = append(secrets, &bundlev1.KV{
-				Key:   key,
-				Type:  fmt.Sprintf("%T", value),
-				Value: s,
-			})
+func lockChain(ctx context.Context, chain *bundlev1.SecretChain, transformer value.Transformer) (int, error) {
+	// Convert secret as a map
+	secrets := map[string]interface{}{}
+	for _, s := range chain.Data {
+		var out interface{}
+		if err := secret.Unpack(s.Value, &out); err != nil {
+			return 0, fmt.Errorf("unable to load secret value, corrupted bundle: %w", err)
 		}
 
-		// Cleanup
-		memguard.WipeBytes(p.Secrets.Locked.Value)
-		p.Secrets.Locked = nil
+		// Assign to secret map
+		secrets[s.Key] = out
+	}
+
+	// Export secrets as JSON
+	content, err := json.Marshal(secrets)
+	if err != nil {
+		return 0, fmt.Errorf("unable to extract secret map as json")
+	}
+
+	// Apply transformer
+	out, err := transformer.To(ctx, content)
+	if err != nil {
+		return 0, fmt.Errorf("unable to apply secret transformer: %w", err)
+	}
+
+	// Cleanup
+	memguard.WipeBytes(content)
+	chain.Data = nil
+
+	// Assign locked secret
+	chain.Locked = &wrappers.BytesValue{
+		Value: out,
+	}
+
+	// No error
+	return len(out), nil
+}
 
-		// Assign unlocked secrets
-		p.Secrets.Data = secrets
+func unlockChain(ctx context.Context, chain *bundlev1.SecretChain, transformers []value.Transformer) error {
+	// Skip not locked chain
+	if chain.Locked == nil || len(chain.Locked.Value) == 0 {
+		return nil
 	}
 
+	// Try all transformers
+	var (
+		out          []byte
+		errTransform error
+	)
+LOOP:
+	for _, t := range transformers {
+		// Apply transformation
+		out, errTransform = t.From(ctx, chain.Locked.Value)
+		switch {
+		case errTransform != nil:
+			// Try next transformer
+			continue
+		default:
+			break LOOP
+		}
+	}
+	if errTransform != nil {
+		return errTransform
+	}
+
+	// Unpack secrets
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		return fmt.Errorf("unable to unpack locked secret: %w", err)
+	}
+
+	// Prepare secrets collection
+	secrets := []*bundlev1.KV{}
+	for key, value := range raw {
+		// Pack secret value
+		s, err := secret.Pack(value)
+		if err != nil {
+			return fmt.Errorf("unable to pack as secret bundle: %w", err)
+		}
+
+		// Add to secret collection
+		secrets = append(secrets, &bundlev1.KV{
+			Key:   key,
+			Type:  fmt.Sprintf("%T", value),
+			Value: s,
+		})
+	}
+
+	// Cleanup
+	memguard.WipeBytes(chain.Locked.Value)
+	chain.Locked = nil
+
+	// Assign unlocked secrets
+	chain.Data = secrets
+
 	// No error
 	return nil
 }
@@ -19,10 +19,16 @@ package bundle
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/log"
 	"github.com/elastic/harp/pkg/sdk/value"
 )
 
@@ -264,3 +270,54 @@ func TestPartialLock(t *testing.T) {
 		})
 	}
 }
+
+type copyTransformer struct{}
+
+func (copyTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	return append([]byte{}, input...), nil
+}
+func (copyTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	return append([]byte{}, input...), nil
+}
+
+func TestLock_Logging(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	log.SetLogger(zap.New(core))
+	defer log.SetLogger(nil)
+
+	b := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{
+				Name: "app/production/db",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "password", Type: "string", Value: secret.MustPack("very-secret-value")},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	if err := Lock(ctx, b, copyTransformer{}); err != nil {
+		t.Fatalf("unable to lock bundle: %v", err)
+	}
+	if err := UnLock(ctx, b, []value.Transformer{copyTransformer{}}, false); err != nil {
+		t.Fatalf("unable to unlock bundle: %v", err)
+	}
+
+	entries := logs.FilterMessage("transformer applied").All()
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 log entries, got %d", len(entries))
+	}
+	for _, e := range entries {
+		if e.Level != zapcore.DebugLevel {
+			t.Errorf("expected debug level, got %s", e.Level)
+		}
+		for k, v := range e.ContextMap() {
+			if strings.Contains(fmt.Sprintf("%v", v), "very-secret-value") {
+				t.Errorf("secret value logged in field '%s'", k)
+			}
+		}
+	}
+}
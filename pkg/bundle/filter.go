@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gobwas/glob"
+	"google.golang.org/protobuf/proto"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/selector"
+)
+
+// Selector describes the packages to extract from a bundle.
+type Selector struct {
+	// PathGlob is matched against package names, '*' matches a single path
+	// segment and '**' matches recursively.
+	PathGlob string
+	// Labels must all be assigned to the package with the same value.
+	Labels map[string]string
+}
+
+// Specification returns the package specification matching the selector.
+func (s Selector) Specification() (selector.Specification, error) {
+	specs := []selector.Specification{}
+
+	if s.PathGlob != "" {
+		g, err := glob.Compile(s.PathGlob, '/')
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile path glob '%s': %w", s.PathGlob, err)
+		}
+		specs = append(specs, selector.MatchPathGlob(g))
+	}
+	if len(s.Labels) > 0 {
+		specs = append(specs, selector.MatchLabels(s.Labels))
+	}
+
+	// No error
+	return selector.All(specs...), nil
+}
+
+// Filter returns a new bundle containing only packages matching the given
+// selector. The input bundle is not modified.
+func Filter(b *bundlev1.Bundle, s Selector) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if b == nil {
+		return nil, errors.New("unable to filter a nil bundle")
+	}
+
+	// Prepare specification
+	spec, err := s.Specification()
+	if err != nil {
+		return nil, err
+	}
+
+	// Clone bundle (we don't want to modify input bundle)
+	out, ok := proto.Clone(b).(*bundlev1.Bundle)
+	if !ok {
+		return nil, fmt.Errorf("the cloned bundle does not have a correct type: %T", out)
+	}
+	out.MerkleTreeRoot = nil
+
+	// Apply package filtering
+	pkgs := []*bundlev1.Package{}
+	for _, p := range out.Packages {
+		if p != nil && spec.IsSatisfiedBy(p) {
+			pkgs = append(pkgs, p)
+		}
+	}
+	out.Packages = pkgs
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,104 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func Test_Filter(t *testing.T) {
+	b := &bundlev1.Bundle{
+		Labels: map[string]string{"owner": "security"},
+		Packages: []*bundlev1.Package{
+			{Name: "app/production/db", Labels: map[string]string{"env": "prod"}},
+			{Name: "app/staging/db", Labels: map[string]string{"env": "staging"}},
+			{Name: "app/production/eu/db", Labels: map[string]string{"env": "prod"}},
+			{Name: "app/production/cache", Labels: map[string]string{"env": "prod"}},
+		},
+	}
+
+	names := func(b *bundlev1.Bundle) []string {
+		res := []string{}
+		for _, p := range b.Packages {
+			res = append(res, p.Name)
+		}
+		return res
+	}
+
+	testCases := []struct {
+		name     string
+		selector Selector
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "invalid glob",
+			selector: Selector{PathGlob: "app/[production"},
+			wantErr:  true,
+		},
+		{
+			name:     "empty selector",
+			selector: Selector{},
+			want:     []string{"app/production/db", "app/staging/db", "app/production/eu/db", "app/production/cache"},
+		},
+		{
+			name:     "glob",
+			selector: Selector{PathGlob: "app/*/db"},
+			want:     []string{"app/production/db", "app/staging/db"},
+		},
+		{
+			name:     "recursive glob",
+			selector: Selector{PathGlob: "app/**/db"},
+			want:     []string{"app/production/db", "app/staging/db", "app/production/eu/db"},
+		},
+		{
+			name:     "labels",
+			selector: Selector{Labels: map[string]string{"env": "prod"}},
+			want:     []string{"app/production/db", "app/production/eu/db", "app/production/cache"},
+		},
+		{
+			name:     "glob and labels",
+			selector: Selector{PathGlob: "app/*/db", Labels: map[string]string{"env": "prod"}},
+			want:     []string{"app/production/db"},
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := Filter(b, testCase.selector)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("error: got %v, wantErr %v", err, testCase.wantErr)
+			}
+			if testCase.wantErr {
+				return
+			}
+			assert.Equal(t, testCase.want, names(got))
+			assert.Equal(t, b.Labels, got.Labels)
+		})
+	}
+
+	// Input bundle is not modified
+	assert.Len(t, b.Packages, 4)
+
+	_, err := Filter(nil, Selector{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,349 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyring
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+const (
+	// StatusActive marks the key used to sign new tokens.
+	StatusActive = "active"
+	// StatusVerifyOnly marks a retired key only used to verify tokens.
+	StatusVerifyOnly = "verify-only"
+
+	// DefaultRetention is the default verify-only key retention window.
+	DefaultRetention = 30 * 24 * time.Hour
+)
+
+// Entry describes a keyring key stored in the bundle.
+type Entry struct {
+	ID        string     `json:"kid"`
+	Status    string     `json:"status"`
+	PublicKey string     `json:"public"`
+	SecretKey string     `json:"secret,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	RetiredAt *time.Time `json:"retired_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RotateOption represents rotation option function.
+type RotateOption func(*rotateOptions)
+
+type rotateOptions struct {
+	retention      time.Duration
+	revokePrevious bool
+	now            func() time.Time
+	random         io.Reader
+}
+
+// WithRetention sets how long the previous active key is kept as verify-only.
+func WithRetention(d time.Duration) RotateOption {
+	return func(opts *rotateOptions) {
+		opts.retention = d
+	}
+}
+
+// WithRevokePrevious removes the previous active key instead of keeping it as
+// verify-only.
+func WithRevokePrevious() RotateOption {
+	return func(opts *rotateOptions) {
+		opts.revokePrevious = true
+	}
+}
+
+// WithClock overrides the time source used for key timestamps.
+func WithClock(now func() time.Time) RotateOption {
+	return func(opts *rotateOptions) {
+		opts.now = now
+	}
+}
+
+// WithRandom overrides the key generation random source.
+func WithRandom(r io.Reader) RotateOption {
+	return func(opts *rotateOptions) {
+		opts.random = r
+	}
+}
+
+// Rotate generates a new v4.public key pair, registers it as the active key of
+// the given bundle package, marks the previous active key as verify-only and
+// purges verify-only keys with an elapsed retention window. The package is
+// created when missing. The new key identifier is returned.
+func Rotate(b *bundlev1.Bundle, packagePath string, opts ...RotateOption) (string, error) {
+	// Check arguments
+	if b == nil {
+		return "", errors.New("unable to rotate keys of a nil bundle")
+	}
+	if packagePath == "" {
+		return "", errors.New("keyring package path must not be blank")
+	}
+
+	// Apply options
+	dopts := &rotateOptions{
+		retention: DefaultRetention,
+		now:       time.Now,
+		random:    rand.Reader,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+	if dopts.retention < 0 {
+		return "", fmt.Errorf("retention window must not be negative, got %s", dopts.retention)
+	}
+
+	// Load current entries
+	p := lookupPackage(b, packagePath)
+	entries, err := decodeEntries(p)
+	if err != nil {
+		return "", err
+	}
+
+	// Retire or purge existing keys
+	now := dopts.now().UTC()
+	kept := []*Entry{}
+	for _, e := range entries {
+		switch {
+		case e.Status == StatusActive && dopts.revokePrevious:
+			continue
+		case e.Status == StatusActive:
+			expiresAt := now.Add(dopts.retention)
+			e.Status = StatusVerifyOnly
+			e.SecretKey = ""
+			e.RetiredAt = &now
+			e.ExpiresAt = &expiresAt
+		case e.ExpiresAt != nil && !now.Before(*e.ExpiresAt):
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	// Generate new key pair
+	pk, sk, err := v4.GenerateKeyPair(dopts.random)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate signing key: %w", err)
+	}
+	active, err := newEntry(pk, sk, now)
+	if err != nil {
+		return "", err
+	}
+	kept = append(kept, active)
+
+	// Update package
+	if p == nil {
+		p = &bundlev1.Package{Name: packagePath}
+		b.Packages = append(b.Packages, p)
+	}
+	if err := encodeEntries(p, kept); err != nil {
+		return "", err
+	}
+
+	// No error
+	return active.ID, nil
+}
+
+// Entries returns the keyring entries stored in the given bundle package
+// ordered by key identifier.
+func Entries(b *bundlev1.Bundle, packagePath string) ([]*Entry, error) {
+	// Check arguments
+	if b == nil {
+		return nil, errors.New("unable to read keys of a nil bundle")
+	}
+
+	// Lookup package
+	p := lookupPackage(b, packagePath)
+	if p == nil {
+		return nil, fmt.Errorf("keyring package '%s' not found", packagePath)
+	}
+
+	// Delegate to decoder
+	return decodeEntries(p)
+}
+
+// Load returns a v4 keyring built from the given bundle package and the active
+// key identifier. Verify-only keys with an elapsed retention window are not
+// registered.
+func Load(b *bundlev1.Bundle, packagePath string, opts ...v4.KeyringOption) (*v4.Keyring, string, error) {
+	return LoadAt(b, packagePath, time.Now(), opts...)
+}
+
+// LoadAt returns a v4 keyring built from the given bundle package and the
+// active key identifier, retention windows are evaluated at the given time.
+func LoadAt(b *bundlev1.Bundle, packagePath string, now time.Time, opts ...v4.KeyringOption) (*v4.Keyring, string, error) {
+	// Retrieve entries
+	entries, err := Entries(b, packagePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Register keys
+	kr := v4.NewKeyring(opts...)
+	activeKid := ""
+	for _, e := range entries {
+		// Skip expired keys
+		if e.Status != StatusActive && e.ExpiresAt != nil && !now.Before(*e.ExpiresAt) {
+			continue
+		}
+
+		if e.SecretKey != "" {
+			sk, err := paserk.DecodeSecret(e.SecretKey)
+			if err != nil {
+				return nil, "", fmt.Errorf("unable to decode '%s' secret key: %w", e.ID, err)
+			}
+			if _, err := kr.AddSecretKey(sk); err != nil {
+				return nil, "", err
+			}
+		} else {
+			pk, err := paserk.DecodePublic(e.PublicKey)
+			if err != nil {
+				return nil, "", fmt.Errorf("unable to decode '%s' public key: %w", e.ID, err)
+			}
+			if _, err := kr.AddPublicKey(pk); err != nil {
+				return nil, "", err
+			}
+		}
+		if e.Status == StatusActive {
+			activeKid = e.ID
+		}
+	}
+
+	// No error
+	return kr, activeKid, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func lookupPackage(b *bundlev1.Bundle, packagePath string) *bundlev1.Package {
+	for _, p := range b.Packages {
+		if p != nil && p.Name == packagePath {
+			return p
+		}
+	}
+	return nil
+}
+
+func newEntry(pk ed25519.PublicKey, sk ed25519.PrivateKey, now time.Time) (*Entry, error) {
+	kid, err := paserk.PublicID(pk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compute key identifier: %w", err)
+	}
+	encodedPk, err := paserk.EncodePublic(pk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode public key: %w", err)
+	}
+	encodedSk, err := paserk.EncodeSecret(sk)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode secret key: %w", err)
+	}
+
+	// No error
+	return &Entry{
+		ID:        kid,
+		Status:    StatusActive,
+		PublicKey: encodedPk,
+		SecretKey: encodedSk,
+		CreatedAt: now,
+	}, nil
+}
+
+func decodeEntries(p *bundlev1.Package) ([]*Entry, error) {
+	entries := []*Entry{}
+
+	// Empty package
+	if p == nil || p.Secrets == nil {
+		return entries, nil
+	}
+	if p.Secrets.Locked != nil {
+		return nil, fmt.Errorf("keyring package '%s' is locked", p.Name)
+	}
+
+	for _, kv := range p.Secrets.Data {
+		// Unpack secret value
+		var raw string
+		if err := secret.Unpack(kv.Value, &raw); err != nil {
+			return nil, fmt.Errorf("unable to unpack '%s' key entry: %w", kv.Key, err)
+		}
+
+		// Decode entry
+		var e Entry
+		if err := json.Unmarshal([]byte(raw), &e); err != nil {
+			return nil, fmt.Errorf("unable to decode '%s' key entry: %w", kv.Key, err)
+		}
+		if e.ID != kv.Key {
+			return nil, fmt.Errorf("key entry '%s' has a mismatching identifier '%s'", kv.Key, e.ID)
+		}
+		entries = append(entries, &e)
+	}
+
+	// Stable order
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	// No error
+	return entries, nil
+}
+
+func encodeEntries(p *bundlev1.Package, entries []*Entry) error {
+	data := make([]*bundlev1.KV, 0, len(entries))
+	for _, e := range entries {
+		// Encode entry
+		raw, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("unable to encode '%s' key entry: %w", e.ID, err)
+		}
+
+		// Pack secret value
+		packed, err := secret.Pack(string(raw))
+		if err != nil {
+			return fmt.Errorf("unable to pack '%s' key entry: %w", e.ID, err)
+		}
+
+		data = append(data, &bundlev1.KV{
+			Key:   e.ID,
+			Type:  "string",
+			Value: packed,
+		})
+	}
+
+	// Stable order
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Key < data[j].Key
+	})
+
+	// Replace package secrets
+	if p.Secrets == nil {
+		p.Secrets = &bundlev1.SecretChain{}
+	}
+	p.Secrets.Data = data
+
+	// No error
+	return nil
+}
@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyring
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+const keyringPath = "app/production/security/paseto/keyring"
+
+func TestRotate(t *testing.T) {
+	b := &bundlev1.Bundle{}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	// First rotation creates the package
+	kid1, err := Rotate(b, keyringPath, WithClock(clock))
+	assert.NoError(t, err)
+	assert.Len(t, b.Packages, 1)
+
+	kr, active, err := LoadAt(b, keyringPath, now)
+	assert.NoError(t, err)
+	assert.Equal(t, kid1, active)
+	token, err := kr.Sign(kid1, []byte("payload"), "", "")
+	assert.NoError(t, err)
+
+	// Second rotation retires the first key
+	now = now.Add(time.Hour)
+	kid2, err := Rotate(b, keyringPath, WithClock(clock), WithRetention(24*time.Hour))
+	assert.NoError(t, err)
+	assert.NotEqual(t, kid1, kid2)
+
+	entries, err := Entries(b, keyringPath)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	for _, e := range entries {
+		switch e.ID {
+		case kid1:
+			assert.Equal(t, StatusVerifyOnly, e.Status)
+			assert.Empty(t, e.SecretKey)
+			assert.Equal(t, now, *e.RetiredAt)
+			assert.Equal(t, now.Add(24*time.Hour), *e.ExpiresAt)
+		case kid2:
+			assert.Equal(t, StatusActive, e.Status)
+			assert.NotEmpty(t, e.SecretKey)
+		default:
+			t.Fatalf("unexpected key %q", e.ID)
+		}
+	}
+
+	// Old tokens are still verifiable, old key can't sign anymore
+	kr, active, err = LoadAt(b, keyringPath, now)
+	assert.NoError(t, err)
+	assert.Equal(t, kid2, active)
+	m, err := kr.Verify(token, "")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("payload"), m)
+	_, err = kr.Sign(kid1, []byte("payload"), "", "")
+	assert.Error(t, err)
+
+	// Retention window is enforced when loading the keyring
+	kr, _, err = LoadAt(b, keyringPath, now.Add(23*time.Hour))
+	assert.NoError(t, err)
+	_, err = kr.Verify(token, "")
+	assert.NoError(t, err)
+	kr, active, err = LoadAt(b, keyringPath, now.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, kid2, active)
+	_, err = kr.Verify(token, "")
+	assert.Error(t, err)
+
+	// Rotation after the retention window purges the first key
+	now = now.Add(48 * time.Hour)
+	kid3, err := Rotate(b, keyringPath, WithClock(clock))
+	assert.NoError(t, err)
+	entries, err = Entries(b, keyringPath)
+	assert.NoError(t, err)
+	ids := []string{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	assert.ElementsMatch(t, []string{kid2, kid3}, ids)
+
+	// Revocation removes the previous key immediately
+	kid4, err := Rotate(b, keyringPath, WithClock(clock), WithRevokePrevious())
+	assert.NoError(t, err)
+	entries, err = Entries(b, keyringPath)
+	assert.NoError(t, err)
+	ids = []string{}
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+	assert.ElementsMatch(t, []string{kid2, kid4}, ids)
+}
+
+func TestRotate_Invalid(t *testing.T) {
+	_, err := Rotate(nil, keyringPath)
+	assert.Error(t, err)
+	_, err = Rotate(&bundlev1.Bundle{}, "")
+	assert.Error(t, err)
+	_, err = Rotate(&bundlev1.Bundle{}, keyringPath, WithRetention(-time.Second))
+	assert.Error(t, err)
+
+	// Locked package
+	b := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{Name: keyringPath, Secrets: &bundlev1.SecretChain{Locked: &wrappers.BytesValue{Value: []byte("locked")}}},
+		},
+	}
+	_, err = Rotate(b, keyringPath)
+	assert.Error(t, err)
+
+	// Missing package
+	_, _, err = Load(&bundlev1.Bundle{}, keyringPath)
+	assert.Error(t, err)
+}
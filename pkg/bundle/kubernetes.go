@@ -0,0 +1,412 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/Masterminds/sprig/v3"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// KubernetesOptions defines Kubernetes manifest rendering options.
+type KubernetesOptions struct {
+	// Namespace assigned to all generated objects.
+	Namespace string
+	// NameTemplate is a text/template (with sprig functions) rendered with
+	// the package `.Path` and `.Labels` to build the object name. The package
+	// path is sanitized as a DNS subdomain name when blank.
+	NameTemplate string
+	// PropagateLabels copies bundle and package labels as object labels.
+	PropagateLabels bool
+	// SealingKey is the sealed-secrets controller public key, SealedSecret
+	// objects are produced instead of Secret objects when set.
+	SealingKey *rsa.PublicKey
+	// Random source used for sealing, defaults to crypto/rand.Reader.
+	Random io.Reader
+}
+
+// ToKubernetes renders each bundle package as a Kubernetes Secret (or a
+// SealedSecret) and returns a multi-document YAML stream ordered as the
+// bundle packages.
+func ToKubernetes(b *bundlev1.Bundle, opts KubernetesOptions) ([]byte, error) {
+	// Check arguments
+	if b == nil {
+		return nil, fmt.Errorf("unable to process nil bundle")
+	}
+	if opts.SealingKey != nil && opts.Namespace == "" {
+		return nil, errors.New("namespace is required to produce sealed secrets")
+	}
+	if opts.Random == nil {
+		opts.Random = rand.Reader
+	}
+
+	// Prepare name template
+	var nameTpl *template.Template
+	if opts.NameTemplate != "" {
+		var err error
+		nameTpl, err = template.New("name").Funcs(sprig.TxtFuncMap()).Parse(opts.NameTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse name template: %w", err)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+
+		// Render manifest
+		obj, err := kubernetesObject(b, p, nameTpl, &opts)
+		if err != nil {
+			return nil, fmt.Errorf("unable to render package '%s': %w", p.Name, err)
+		}
+
+		// Encode as YAML
+		doc, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode package '%s' manifest: %w", p.Name, err)
+		}
+
+		if out.Len() > 0 {
+			out.WriteString("---\n")
+		}
+		out.Write(doc)
+	}
+
+	// No error
+	return out.Bytes(), nil
+}
+
+// FromKubernetes builds a bundle from a YAML stream of Kubernetes Secret
+// manifests. Each Secret is mapped to a `<namespace>/<name>` package, objects of
+// other kinds are ignored. `stringData` entries take precedence over `data`
+// ones, as done by the Kubernetes API server. Object labels are copied as
+// package labels, annotations are not imported as they could contain secret
+// values (kubectl last applied configuration).
+func FromKubernetes(r io.Reader) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if types.IsNil(r) {
+		return nil, fmt.Errorf("unable to process nil reader")
+	}
+
+	res := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{},
+	}
+
+	dec := yamlv3.NewDecoder(r)
+	for i := 0; ; i++ {
+		// Decode next document
+		var obj k8sManifest
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode document #%d: %w", i, err)
+		}
+
+		// Ignore other objects
+		if obj.Kind != "Secret" || !strings.HasPrefix(obj.APIVersion, "v1") {
+			continue
+		}
+
+		// Convert as package
+		p, err := fromKubernetesSecret(&obj)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert document #%d: %w", i, err)
+		}
+		res.Packages = append(res.Packages, p)
+	}
+
+	// No error
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	dnsSubdomainRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	invalidNameChars   = regexp.MustCompile(`[^a-z0-9.-]+`)
+)
+
+const (
+	dnsSubdomainMaxLength = 253
+	sealingSessionKeySize = 32
+)
+
+type k8sObjectMeta struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+type k8sSecret struct {
+	APIVersion string            `json:"apiVersion"`
+	Kind       string            `json:"kind"`
+	Metadata   k8sObjectMeta     `json:"metadata"`
+	Type       string            `json:"type"`
+	Data       map[string]string `json:"data,omitempty"`
+}
+
+type k8sManifest struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string            `yaml:"name"`
+		Namespace string            `yaml:"namespace"`
+		Labels    map[string]string `yaml:"labels"`
+	} `yaml:"metadata"`
+	Data       map[string]string `yaml:"data"`
+	StringData map[string]string `yaml:"stringData"`
+}
+
+type k8sSealedSecretTemplate struct {
+	Metadata k8sObjectMeta `json:"metadata"`
+	Type     string        `json:"type"`
+}
+
+type k8sSealedSecretSpec struct {
+	EncryptedData map[string]string       `json:"encryptedData"`
+	Template      k8sSealedSecretTemplate `json:"template"`
+}
+
+type k8sSealedSecret struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   k8sObjectMeta       `json:"metadata"`
+	Spec       k8sSealedSecretSpec `json:"spec"`
+}
+
+func kubernetesObject(b *bundlev1.Bundle, p *bundlev1.Package, nameTpl *template.Template, opts *KubernetesOptions) (interface{}, error) {
+	// Check package
+	if p.Secrets == nil {
+		return nil, errors.New("package has no secrets")
+	}
+	if p.Secrets.Locked != nil {
+		return nil, errors.New("package is locked")
+	}
+
+	// Build object metadata
+	name, err := kubernetesName(p, nameTpl)
+	if err != nil {
+		return nil, err
+	}
+	meta := k8sObjectMeta{
+		Name:      name,
+		Namespace: opts.Namespace,
+	}
+	if opts.PropagateLabels && (len(b.Labels) > 0 || len(p.Labels) > 0) {
+		meta.Labels = map[string]string{}
+		for k, v := range b.Labels {
+			meta.Labels[k] = v
+		}
+		for k, v := range p.Labels {
+			meta.Labels[k] = v
+		}
+	}
+
+	// Prepare secret data
+	data := map[string]string{}
+	for _, kv := range p.Secrets.Data {
+		value, err := secretBytes(kv)
+		if err != nil {
+			return nil, err
+		}
+
+		// Seal the value
+		if opts.SealingKey != nil {
+			label := []byte(fmt.Sprintf("%s/%s", meta.Namespace, meta.Name))
+			value, err = sealValue(opts.Random, opts.SealingKey, value, label)
+			if err != nil {
+				return nil, fmt.Errorf("unable to seal '%s' secret value: %w", kv.Key, err)
+			}
+		}
+
+		data[kv.Key] = base64.StdEncoding.EncodeToString(value)
+	}
+
+	if opts.SealingKey != nil {
+		return &k8sSealedSecret{
+			APIVersion: "bitnami.com/v1alpha1",
+			Kind:       "SealedSecret",
+			Metadata:   meta,
+			Spec: k8sSealedSecretSpec{
+				EncryptedData: data,
+				Template: k8sSealedSecretTemplate{
+					Metadata: meta,
+					Type:     "Opaque",
+				},
+			},
+		}, nil
+	}
+
+	// No error
+	return &k8sSecret{
+		APIVersion: "v1",
+		Kind:       "Secret",
+		Metadata:   meta,
+		Type:       "Opaque",
+		Data:       data,
+	}, nil
+}
+
+func kubernetesName(p *bundlev1.Package, nameTpl *template.Template) (string, error) {
+	name := ""
+	if nameTpl != nil {
+		var buf strings.Builder
+		if err := nameTpl.Execute(&buf, map[string]interface{}{
+			"Path":   p.Name,
+			"Labels": p.Labels,
+		}); err != nil {
+			return "", fmt.Errorf("unable to render object name: %w", err)
+		}
+		name = strings.TrimSpace(buf.String())
+	} else {
+		name = strings.ToLower(p.Name)
+		name = invalidNameChars.ReplaceAllString(strings.ReplaceAll(name, "/", "-"), "-")
+		name = strings.Trim(name, "-.")
+	}
+
+	// Validate name
+	if len(name) > dnsSubdomainMaxLength || !dnsSubdomainRegexp.MatchString(name) {
+		return "", fmt.Errorf("'%s' is not a valid object name", name)
+	}
+
+	return name, nil
+}
+
+// sealValue encrypts the value using the sealed-secrets hybrid encryption
+// scheme.
+//
+//	len(rsaCiphertext) (uint16 BE) || RSA-OAEP-SHA256(sessionKey, label) || AES-GCM(sessionKey, zeroNonce, value)
+func sealValue(r io.Reader, pk *rsa.PublicKey, value, label []byte) ([]byte, error) {
+	// Generate a session key
+	sessionKey := make([]byte, sealingSessionKeySize)
+	if _, err := io.ReadFull(r, sessionKey); err != nil {
+		return nil, fmt.Errorf("unable to generate session key: %w", err)
+	}
+
+	// Wrap session key
+	rsaCiphertext, err := rsa.EncryptOAEP(sha256.New(), r, pk, sessionKey, label)
+	if err != nil {
+		return nil, fmt.Errorf("unable to wrap session key: %w", err)
+	}
+
+	// Initialize AEAD
+	block, err := aes.NewCipher(sessionKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize aead: %w", err)
+	}
+
+	// Assemble ciphertext, the session key is used once so the nonce can be
+	// zero.
+	out := make([]byte, 2, 2+len(rsaCiphertext)+len(value)+aead.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(rsaCiphertext)))
+	out = append(out, rsaCiphertext...)
+	out = aead.Seal(out, make([]byte, aead.NonceSize()), value, nil)
+
+	// No error
+	return out, nil
+}
+
+func fromKubernetesSecret(obj *k8sManifest) (*bundlev1.Package, error) {
+	// Check arguments
+	if obj.Metadata.Name == "" {
+		return nil, errors.New("secret name is blank")
+	}
+	namespace := obj.Metadata.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	name := fmt.Sprintf("%s/%s", namespace, obj.Metadata.Name)
+
+	// Decode values
+	values := map[string][]byte{}
+	for k, v := range obj.Data {
+		decoded, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode '%s' - '%s' secret value", name, k)
+		}
+		values[k] = decoded
+	}
+	for k, v := range obj.StringData {
+		values[k] = []byte(v)
+	}
+
+	// Ensure deterministic order
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Pack secrets
+	chain := &bundlev1.SecretChain{
+		Data: make([]*bundlev1.KV, 0, len(keys)),
+	}
+	for _, k := range keys {
+		var value interface{} = values[k]
+		if utf8.Valid(values[k]) {
+			value = string(values[k])
+		}
+
+		packed, err := secret.Pack(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to pack '%s' - '%s' secret value: %w", name, k, err)
+		}
+
+		chain.Data = append(chain.Data, &bundlev1.KV{
+			Key:   k,
+			Type:  fmt.Sprintf("%T", value),
+			Value: packed,
+		})
+	}
+
+	// No error
+	return &bundlev1.Package{
+		Name:    name,
+		Labels:  obj.Metadata.Labels,
+		Secrets: chain,
+	}, nil
+}
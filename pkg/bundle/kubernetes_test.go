@@ -0,0 +1,254 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"sigs.k8s.io/yaml"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+)
+
+func kubernetesFixture() *bundlev1.Bundle {
+	return &bundlev1.Bundle{
+		Labels: map[string]string{"owner": "security"},
+		Packages: []*bundlev1.Package{
+			{
+				Name:   "app/production/Security/DB",
+				Labels: map[string]string{"env": "prod"},
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "password", Value: secret.MustPack("foo")},
+						{Key: "port", Value: secret.MustPack(5432)},
+					},
+				},
+			},
+			{
+				Name: "app/production/smtp",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "password", Value: secret.MustPack("bar")},
+					},
+				},
+			},
+		},
+	}
+}
+
+func splitManifests(t *testing.T, out []byte) []map[string]interface{} {
+	t.Helper()
+
+	res := []map[string]interface{}{}
+	for _, doc := range bytes.Split(out, []byte("---\n")) {
+		obj := map[string]interface{}{}
+		assert.NoError(t, yaml.Unmarshal(doc, &obj))
+		res = append(res, obj)
+	}
+
+	return res
+}
+
+func Test_ToKubernetes(t *testing.T) {
+	b := kubernetesFixture()
+
+	t.Run("nil", func(t *testing.T) {
+		_, err := ToKubernetes(nil, KubernetesOptions{})
+		assert.Error(t, err)
+	})
+
+	t.Run("secrets", func(t *testing.T) {
+		out, err := ToKubernetes(b, KubernetesOptions{
+			Namespace:       "apps",
+			PropagateLabels: true,
+		})
+		assert.NoError(t, err)
+
+		objs := splitManifests(t, out)
+		assert.Len(t, objs, 2)
+		assert.Equal(t, "Secret", objs[0]["kind"])
+		assert.Equal(t, map[string]interface{}{
+			"name":      "app-production-security-db",
+			"namespace": "apps",
+			"labels":    map[string]interface{}{"owner": "security", "env": "prod"},
+		}, objs[0]["metadata"])
+		assert.Equal(t, map[string]interface{}{
+			"password": base64.StdEncoding.EncodeToString([]byte("foo")),
+			"port":     base64.StdEncoding.EncodeToString([]byte("5432")),
+		}, objs[0]["data"])
+		assert.Equal(t, "app-production-smtp", objs[1]["metadata"].(map[string]interface{})["name"])
+	})
+
+	t.Run("name template", func(t *testing.T) {
+		out, err := ToKubernetes(b, KubernetesOptions{
+			NameTemplate: `{{ .Path | base | lower }}-{{ .Labels.env | default "none" }}`,
+		})
+		assert.NoError(t, err)
+
+		objs := splitManifests(t, out)
+		assert.Equal(t, map[string]interface{}{"name": "db-prod"}, objs[0]["metadata"])
+		assert.Equal(t, map[string]interface{}{"name": "smtp-none"}, objs[1]["metadata"])
+	})
+
+	t.Run("invalid names", func(t *testing.T) {
+		_, err := ToKubernetes(b, KubernetesOptions{NameTemplate: `{{ .Path }}`})
+		assert.Error(t, err)
+		_, err = ToKubernetes(b, KubernetesOptions{NameTemplate: `{{ .Path`})
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid packages", func(t *testing.T) {
+		_, err := ToKubernetes(&bundlev1.Bundle{
+			Packages: []*bundlev1.Package{{Name: "app"}},
+		}, KubernetesOptions{})
+		assert.Error(t, err)
+
+		_, err = ToKubernetes(&bundlev1.Bundle{
+			Packages: []*bundlev1.Package{{Name: "app", Secrets: &bundlev1.SecretChain{Locked: wrapperspb.Bytes([]byte("locked"))}}},
+		}, KubernetesOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func Test_ToKubernetes_Sealed(t *testing.T) {
+	sk, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	b := kubernetesFixture()
+
+	// Namespace is required for strict scope
+	_, err = ToKubernetes(b, KubernetesOptions{SealingKey: &sk.PublicKey})
+	assert.Error(t, err)
+
+	out, err := ToKubernetes(b, KubernetesOptions{
+		Namespace:  "apps",
+		SealingKey: &sk.PublicKey,
+	})
+	assert.NoError(t, err)
+
+	objs := splitManifests(t, out)
+	assert.Len(t, objs, 2)
+	assert.Equal(t, "SealedSecret", objs[0]["kind"])
+	assert.Equal(t, "bitnami.com/v1alpha1", objs[0]["apiVersion"])
+
+	// Unseal the value as the controller would do
+	spec := objs[1]["spec"].(map[string]interface{})
+	encrypted, err := base64.StdEncoding.DecodeString(spec["encryptedData"].(map[string]interface{})["password"].(string))
+	assert.NoError(t, err)
+
+	l := int(binary.BigEndian.Uint16(encrypted))
+	sessionKey, err := rsa.DecryptOAEP(sha256.New(), nil, sk, encrypted[2:2+l], []byte("apps/app-production-smtp"))
+	assert.NoError(t, err)
+	block, err := aes.NewCipher(sessionKey)
+	assert.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	value, err := aead.Open(nil, make([]byte, aead.NonceSize()), encrypted[2+l:], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), value)
+}
+
+func Test_FromKubernetes(t *testing.T) {
+	input := `
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+  namespace: apps
+  labels:
+    env: prod
+  annotations:
+    kubectl.kubernetes.io/last-applied-configuration: '{"data":{"password":"Zm9v"}}'
+type: Opaque
+data:
+  password: Zm9v
+  user: YWRtaW4=
+stringData:
+  user: root
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+data:
+  region: eu
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: tls
+data:
+  key: 3q2+7w==
+`
+
+	b, err := FromKubernetes(strings.NewReader(input))
+	assert.NoError(t, err)
+	assert.Len(t, b.Packages, 2)
+
+	db := b.Packages[0]
+	assert.Equal(t, "apps/db", db.Name)
+	assert.Equal(t, map[string]string{"env": "prod"}, db.Labels)
+	assert.Empty(t, db.Annotations)
+
+	secrets, err := AsSecretMap(db)
+	assert.NoError(t, err)
+	assert.Equal(t, KV{"password": "foo", "user": "root"}, secrets)
+
+	tls := b.Packages[1]
+	assert.Equal(t, "default/tls", tls.Name)
+	var raw []byte
+	assert.NoError(t, secret.Unpack(tls.Secrets.Data[0].Value, &raw))
+	assert.Equal(t, []byte{0xde, 0xad, 0xbe, 0xef}, raw)
+
+	// Round-trip
+	out, err := ToKubernetes(b, KubernetesOptions{Namespace: "apps"})
+	assert.NoError(t, err)
+	imported, err := FromKubernetes(bytes.NewReader(out))
+	assert.NoError(t, err)
+	assert.Equal(t, "apps/apps-db", imported.Packages[0].Name)
+	importedSecrets, err := AsSecretMap(imported.Packages[0])
+	assert.NoError(t, err)
+	assert.Equal(t, secrets, importedSecrets)
+}
+
+func Test_FromKubernetes_Invalid(t *testing.T) {
+	inputs := []string{
+		"apiVersion: v1\nkind: Secret\nmetadata: {}\n",
+		"apiVersion: v1\nkind: Secret\nmetadata:\n  name: db\ndata:\n  password: '%%%'\n",
+		"apiVersion: v1\nkind: [Secret\n",
+	}
+	for _, input := range inputs {
+		_, err := FromKubernetes(strings.NewReader(input))
+		assert.Error(t, err)
+	}
+
+	_, err := FromKubernetes(nil)
+	assert.Error(t, err)
+}
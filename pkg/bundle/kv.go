@@ -18,6 +18,7 @@
 package bundle
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 
@@ -105,3 +106,27 @@ func FromSecretMap(secretKv KV) ([]*bundlev1.KV, error) {
 	// No error
 	return secrets, nil
 }
+
+// secretBytes returns the unpacked secret value as bytes, strings are returned
+// as-is and other values are JSON encoded.
+func secretBytes(kv *bundlev1.KV) ([]byte, error) {
+	// Unpack secret value
+	var data interface{}
+	if err := secret.Unpack(kv.Value, &data); err != nil {
+		return nil, fmt.Errorf("unable to unpack '%s' secret value: %w", kv.Key, err)
+	}
+
+	switch v := data.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+		// Encode complex values as JSON
+		out, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode '%s' secret value as json: %w", kv.Key, err)
+		}
+		return out, nil
+	}
+}
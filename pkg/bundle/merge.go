@@ -0,0 +1,270 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+// MergeStrategy defines the behavior to apply when the same secret is defined
+// in both merged bundles.
+type MergeStrategy int
+
+const (
+	// MergeOverwrite replaces destination content by the source one.
+	MergeOverwrite MergeStrategy = iota
+	// MergeSkip keeps destination content and ignores the source one.
+	MergeSkip
+	// MergeError stops the merge and returns a MergeConflictError.
+	MergeError
+)
+
+// String returns the strategy name.
+func (s MergeStrategy) String() string {
+	switch s {
+	case MergeOverwrite:
+		return "overwrite"
+	case MergeSkip:
+		return "skip"
+	case MergeError:
+		return "error"
+	default:
+		return fmt.Sprintf("MergeStrategy(%d)", int(s))
+	}
+}
+
+// ParseMergeStrategy returns the strategy matching the given name.
+func ParseMergeStrategy(name string) (MergeStrategy, error) {
+	for _, s := range []MergeStrategy{MergeOverwrite, MergeSkip, MergeError} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+
+	return MergeOverwrite, fmt.Errorf("unsupported merge strategy '%s'", name)
+}
+
+// MergeConflictError is raised by the MergeError strategy.
+type MergeConflictError struct {
+	// Path of the conflicting object, '<package>#<key>' for secrets.
+	Path string
+}
+
+// Error returns the error message.
+func (e *MergeConflictError) Error() string {
+	return fmt.Sprintf("merge conflict on '%s'", e.Path)
+}
+
+// Merge returns a new bundle containing dst content merged with src one.
+//
+// Packages are merged secret by secret, labels and annotations are merged key
+// by key. A conflict happens only when both bundles define the same entry with
+// a different value. Locked packages can't be inspected, the package is
+// considered as a whole in this case. The merkle tree root is not copied as the
+// merged content differs from both inputs.
+//
+// Inputs are never modified.
+func Merge(dst, src *bundlev1.Bundle, strategy MergeStrategy) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if dst == nil {
+		return nil, errors.New("unable to merge with a nil destination bundle")
+	}
+	if src == nil {
+		return nil, errors.New("unable to merge with a nil source bundle")
+	}
+	if strategy < MergeOverwrite || strategy > MergeError {
+		return nil, fmt.Errorf("unsupported merge strategy %s", strategy)
+	}
+
+	// Clone bundles (we don't want to modify input bundles)
+	out, ok := proto.Clone(dst).(*bundlev1.Bundle)
+	if !ok {
+		return nil, fmt.Errorf("the cloned bundle does not have a correct type: %T", out)
+	}
+	in, ok := proto.Clone(src).(*bundlev1.Bundle)
+	if !ok {
+		return nil, fmt.Errorf("the cloned bundle does not have a correct type: %T", in)
+	}
+	out.MerkleTreeRoot = nil
+
+	// Merge bundle metadata
+	var err error
+	if out.Labels, err = mergeStringMap(out.Labels, in.Labels, "labels", strategy); err != nil {
+		return nil, err
+	}
+	if out.Annotations, err = mergeStringMap(out.Annotations, in.Annotations, "annotations", strategy); err != nil {
+		return nil, err
+	}
+	if out.Template == nil {
+		out.Template = in.Template
+	}
+	if out.Values == nil {
+		out.Values = in.Values
+	}
+
+	// Index destination packages
+	index := map[string]int{}
+	for i, p := range out.Packages {
+		if p != nil {
+			index[p.Name] = i
+		}
+	}
+
+	// Merge packages, respecting source order for new ones
+	for _, p := range in.Packages {
+		if p == nil {
+			continue
+		}
+
+		i, ok := index[p.Name]
+		if !ok {
+			index[p.Name] = len(out.Packages)
+			out.Packages = append(out.Packages, p)
+			continue
+		}
+
+		merged, err := mergePackage(out.Packages[i], p, strategy)
+		if err != nil {
+			return nil, err
+		}
+		out.Packages[i] = merged
+	}
+
+	// No error
+	return out, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func mergePackage(dst, src *bundlev1.Package, strategy MergeStrategy) (*bundlev1.Package, error) {
+	// Locked packages are merged as a whole
+	if isLocked(dst) || isLocked(src) {
+		if proto.Equal(dst, src) {
+			return dst, nil
+		}
+
+		switch strategy {
+		case MergeSkip:
+			return dst, nil
+		case MergeError:
+			return nil, &MergeConflictError{Path: dst.Name}
+		default:
+			return src, nil
+		}
+	}
+
+	// Merge package metadata
+	var err error
+	if dst.Labels, err = mergeStringMap(dst.Labels, src.Labels, fmt.Sprintf("%s#labels", dst.Name), strategy); err != nil {
+		return nil, err
+	}
+	if dst.Annotations, err = mergeStringMap(dst.Annotations, src.Annotations, fmt.Sprintf("%s#annotations", dst.Name), strategy); err != nil {
+		return nil, err
+	}
+
+	// Nothing to merge
+	if src.Secrets == nil {
+		return dst, nil
+	}
+	if dst.Secrets == nil {
+		dst.Secrets = src.Secrets
+		return dst, nil
+	}
+
+	// Merge secret chain metadata
+	if dst.Secrets.Labels, err = mergeStringMap(dst.Secrets.Labels, src.Secrets.Labels, fmt.Sprintf("%s#secrets.labels", dst.Name), strategy); err != nil {
+		return nil, err
+	}
+	if dst.Secrets.Annotations, err = mergeStringMap(dst.Secrets.Annotations, src.Secrets.Annotations, fmt.Sprintf("%s#secrets.annotations", dst.Name), strategy); err != nil {
+		return nil, err
+	}
+
+	// Index destination secrets
+	index := map[string]int{}
+	for i, kv := range dst.Secrets.Data {
+		if kv != nil {
+			index[kv.Key] = i
+		}
+	}
+
+	// Merge secrets
+	for _, kv := range src.Secrets.Data {
+		if kv == nil {
+			continue
+		}
+
+		i, ok := index[kv.Key]
+		if !ok {
+			index[kv.Key] = len(dst.Secrets.Data)
+			dst.Secrets.Data = append(dst.Secrets.Data, kv)
+			continue
+		}
+		if proto.Equal(dst.Secrets.Data[i], kv) {
+			continue
+		}
+
+		switch strategy {
+		case MergeSkip:
+		case MergeError:
+			return nil, &MergeConflictError{Path: fmt.Sprintf("%s#%s", dst.Name, kv.Key)}
+		default:
+			dst.Secrets.Data[i] = kv
+		}
+	}
+
+	// No error
+	return dst, nil
+}
+
+func mergeStringMap(dst, src map[string]string, path string, strategy MergeStrategy) (map[string]string, error) {
+	// Nothing to merge
+	if len(src) == 0 {
+		return dst, nil
+	}
+	if dst == nil {
+		dst = map[string]string{}
+	}
+
+	for k, v := range src {
+		current, ok := dst[k]
+		if !ok || current == v {
+			dst[k] = v
+			continue
+		}
+
+		switch strategy {
+		case MergeSkip:
+		case MergeError:
+			return nil, &MergeConflictError{Path: fmt.Sprintf("%s/%s", path, k)}
+		default:
+			dst[k] = v
+		}
+	}
+
+	// No error
+	return dst, nil
+}
+
+func isLocked(p *bundlev1.Package) bool {
+	return p.Secrets != nil && p.Secrets.Locked != nil
+}
@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func mergeFixtures() (dst, src *bundlev1.Bundle) {
+	dst = &bundlev1.Bundle{
+		Labels: map[string]string{"owner": "security"},
+		Packages: []*bundlev1.Package{
+			{
+				Name:        "app/production/db",
+				Annotations: map[string]string{"harp.elastic.co/v1/package#encryptionKeyAlias": "db"},
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: []byte("foo")},
+						{Key: "password", Value: []byte("bar")},
+					},
+				},
+			},
+		},
+	}
+	src = &bundlev1.Bundle{
+		Labels: map[string]string{"source": "vault"},
+		Packages: []*bundlev1.Package{
+			{
+				Name:   "app/production/db",
+				Labels: map[string]string{"rotated": "true"},
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: []byte("foo")},
+						{Key: "password", Value: []byte("rotated")},
+						{Key: "host", Value: []byte("db.internal")},
+					},
+				},
+			},
+			{
+				Name: "app/production/smtp",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "password", Value: []byte("smtp")},
+					},
+				},
+			},
+		},
+	}
+
+	return dst, src
+}
+
+func Test_Merge(t *testing.T) {
+	t.Run("nil", func(t *testing.T) {
+		_, err := Merge(nil, &bundlev1.Bundle{}, MergeOverwrite)
+		assert.Error(t, err)
+		_, err = Merge(&bundlev1.Bundle{}, nil, MergeOverwrite)
+		assert.Error(t, err)
+		_, err = Merge(&bundlev1.Bundle{}, &bundlev1.Bundle{}, MergeStrategy(42))
+		assert.Error(t, err)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		dst, src := mergeFixtures()
+		dstOrig, srcOrig := mergeFixtures()
+
+		out, err := Merge(dst, src, MergeOverwrite)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]string{"owner": "security", "source": "vault"}, out.Labels)
+		assert.Len(t, out.Packages, 2)
+
+		db := out.Packages[0]
+		assert.Equal(t, "app/production/db", db.Name)
+		assert.Equal(t, map[string]string{"rotated": "true"}, db.Labels)
+		assert.Equal(t, map[string]string{"harp.elastic.co/v1/package#encryptionKeyAlias": "db"}, db.Annotations)
+		assert.Len(t, db.Secrets.Data, 3)
+		assert.Equal(t, []byte("rotated"), db.Secrets.Data[1].Value)
+		assert.Equal(t, "host", db.Secrets.Data[2].Key)
+		assert.Equal(t, "app/production/smtp", out.Packages[1].Name)
+
+		// Inputs are not modified
+		assert.Empty(t, cmp.Diff(dstOrig, dst, ignoreOpts...))
+		assert.Empty(t, cmp.Diff(srcOrig, src, ignoreOpts...))
+
+		// Output doesn't share memory with inputs
+		out.Packages[1].Secrets.Data[0].Value[0] = 'x'
+		assert.Equal(t, []byte("smtp"), src.Packages[1].Secrets.Data[0].Value)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		dst, src := mergeFixtures()
+
+		out, err := Merge(dst, src, MergeSkip)
+		assert.NoError(t, err)
+		assert.Len(t, out.Packages, 2)
+		assert.Len(t, out.Packages[0].Secrets.Data, 3)
+		assert.Equal(t, []byte("bar"), out.Packages[0].Secrets.Data[1].Value)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dst, src := mergeFixtures()
+
+		_, err := Merge(dst, src, MergeError)
+		assert.Error(t, err)
+
+		var conflict *MergeConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Equal(t, "app/production/db#password", conflict.Path)
+
+		// Identical values are not conflicting
+		dst, _ = mergeFixtures()
+		out, err := Merge(dst, dst, MergeError)
+		assert.NoError(t, err)
+		assert.Empty(t, cmp.Diff(dst, out, ignoreOpts...))
+	})
+
+	t.Run("locked", func(t *testing.T) {
+		dst, src := mergeFixtures()
+		src.Packages[0].Secrets.Locked = wrapperspb.Bytes([]byte("locked"))
+
+		_, err := Merge(dst, src, MergeError)
+		var conflict *MergeConflictError
+		assert.True(t, errors.As(err, &conflict))
+		assert.Equal(t, "app/production/db", conflict.Path)
+
+		out, err := Merge(dst, src, MergeOverwrite)
+		assert.NoError(t, err)
+		assert.NotNil(t, out.Packages[0].Secrets.Locked)
+	})
+}
+
+func Test_ParseMergeStrategy(t *testing.T) {
+	for _, s := range []MergeStrategy{MergeOverwrite, MergeSkip, MergeError} {
+		got, err := ParseMergeStrategy(s.String())
+		assert.NoError(t, err)
+		assert.Equal(t, s, got)
+	}
+
+	_, err := ParseMergeStrategy("concat")
+	assert.Error(t, err)
+}
@@ -0,0 +1,171 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+// MoveOption represents move option function.
+type MoveOption func(*moveOptions)
+
+type moveOptions struct {
+	overwrite bool
+}
+
+// WithOverwrite allows the move to replace existing destination packages.
+func WithOverwrite() MoveOption {
+	return func(opts *moveOptions) {
+		opts.overwrite = true
+	}
+}
+
+// Move renames a package path, or moves a subtree when both paths end with
+// `/*` (`app/old/*` to `app/new/*` moves all packages under `app/old/`
+// recursively).
+//
+// Secret values and metadata are preserved, bundle and package annotation
+// values referencing a moved package path are updated. The bundle is not
+// modified when an error is returned.
+func Move(b *bundlev1.Bundle, from, to string, opts ...MoveOption) error {
+	// Check arguments
+	if b == nil {
+		return fmt.Errorf("unable to process nil bundle")
+	}
+
+	// Apply options
+	dopts := &moveOptions{}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Prepare path mapping
+	rename, err := moveMapper(from, to)
+	if err != nil {
+		return err
+	}
+
+	// Compute renamed packages
+	moved := map[string]string{}
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		if target, ok := rename(p.Name); ok {
+			moved[p.Name] = target
+		}
+	}
+	if len(moved) == 0 {
+		return fmt.Errorf("no package matches '%s'", from)
+	}
+
+	// Check destination conflicts
+	targets := map[string]struct{}{}
+	for _, target := range moved {
+		targets[target] = struct{}{}
+	}
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		if _, isMoved := moved[p.Name]; isMoved {
+			continue
+		}
+		if _, conflict := targets[p.Name]; conflict && !dopts.overwrite {
+			return fmt.Errorf("unable to move to '%s', destination package already exists", p.Name)
+		}
+	}
+
+	// Apply changes
+	pkgs := make([]*bundlev1.Package, 0, len(b.Packages))
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		// Remove overwritten packages
+		if _, isMoved := moved[p.Name]; !isMoved {
+			if _, conflict := targets[p.Name]; conflict {
+				continue
+			}
+		}
+		pkgs = append(pkgs, p)
+	}
+	for _, p := range pkgs {
+		if target, ok := moved[p.Name]; ok {
+			p.Name = target
+		}
+		updateReferences(p.Annotations, moved)
+	}
+	updateReferences(b.Annotations, moved)
+	b.Packages = pkgs
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func moveMapper(from, to string) (func(string) (string, bool), error) {
+	// Check arguments
+	if strings.TrimSpace(from) == "" {
+		return nil, errors.New("unable to move with a blank source path")
+	}
+	if strings.TrimSpace(to) == "" {
+		return nil, errors.New("unable to move with a blank destination path")
+	}
+
+	fromTree, toTree := strings.HasSuffix(from, "/*"), strings.HasSuffix(to, "/*")
+	if fromTree != toTree {
+		return nil, errors.New("source and destination paths must both be package paths or subtrees")
+	}
+
+	// Package rename
+	if !fromTree {
+		src, dst := path.Clean(from), path.Clean(to)
+		return func(name string) (string, bool) {
+			return dst, name == src
+		}, nil
+	}
+
+	// Subtree move
+	srcPrefix := path.Clean(strings.TrimSuffix(from, "/*")) + "/"
+	dstPrefix := path.Clean(strings.TrimSuffix(to, "/*")) + "/"
+	if strings.HasPrefix(dstPrefix, srcPrefix) || strings.HasPrefix(srcPrefix, dstPrefix) {
+		return nil, errors.New("unable to move a subtree into itself or one of its parents")
+	}
+
+	return func(name string) (string, bool) {
+		if !strings.HasPrefix(name, srcPrefix) {
+			return "", false
+		}
+		return dstPrefix + strings.TrimPrefix(name, srcPrefix), true
+	}, nil
+}
+
+func updateReferences(m map[string]string, moved map[string]string) {
+	for k, v := range m {
+		if target, ok := moved[v]; ok {
+			m[k] = target
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func moveFixture() *bundlev1.Bundle {
+	return &bundlev1.Bundle{
+		Annotations: map[string]string{"harp.elastic.co/v1/bundle#primary": "app/old/db"},
+		Packages: []*bundlev1.Package{
+			{
+				Name:   "app/old/db",
+				Labels: map[string]string{"env": "prod"},
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "password", Value: []byte("foo")}},
+				},
+			},
+			{
+				Name:        "app/old/eu/cache",
+				Annotations: map[string]string{"harp.elastic.co/v1/package#replicaOf": "app/old/db"},
+			},
+			{Name: "app/new/smtp"},
+			{Name: "app/older/db"},
+		},
+	}
+}
+
+func packageNames(b *bundlev1.Bundle) []string {
+	res := []string{}
+	for _, p := range b.Packages {
+		res = append(res, p.Name)
+	}
+	return res
+}
+
+func Test_Move(t *testing.T) {
+	t.Run("package", func(t *testing.T) {
+		b := moveFixture()
+		assert.NoError(t, Move(b, "app/old/db", "app/new/db"))
+		assert.Equal(t, []string{"app/new/db", "app/old/eu/cache", "app/new/smtp", "app/older/db"}, packageNames(b))
+
+		// Metadata and values are preserved
+		assert.Equal(t, map[string]string{"env": "prod"}, b.Packages[0].Labels)
+		assert.Equal(t, []byte("foo"), b.Packages[0].Secrets.Data[0].Value)
+
+		// References are updated
+		assert.Equal(t, "app/new/db", b.Annotations["harp.elastic.co/v1/bundle#primary"])
+		assert.Equal(t, "app/new/db", b.Packages[1].Annotations["harp.elastic.co/v1/package#replicaOf"])
+	})
+
+	t.Run("subtree", func(t *testing.T) {
+		b := moveFixture()
+		assert.NoError(t, Move(b, "app/old/*", "app/new/*"))
+		assert.Equal(t, []string{"app/new/db", "app/new/eu/cache", "app/new/smtp", "app/older/db"}, packageNames(b))
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		b := moveFixture()
+		assert.Error(t, Move(b, "app/old/db", "app/older/db"))
+		assert.Equal(t, moveFixture().Packages[0].Name, b.Packages[0].Name)
+
+		assert.NoError(t, Move(b, "app/old/db", "app/older/db", WithOverwrite()))
+		assert.Equal(t, []string{"app/older/db", "app/old/eu/cache", "app/new/smtp"}, packageNames(b))
+		assert.Equal(t, []byte("foo"), b.Packages[0].Secrets.Data[0].Value)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		b := moveFixture()
+		assert.Error(t, Move(nil, "a", "b"))
+		assert.Error(t, Move(b, "", "app/new/db"))
+		assert.Error(t, Move(b, "app/old/db", " "))
+		assert.Error(t, Move(b, "app/old/*", "app/new/db"))
+		assert.Error(t, Move(b, "app/old/*", "app/old/eu/*"))
+		assert.Error(t, Move(b, "app/unknown", "app/new/db"))
+		assert.Equal(t, packageNames(moveFixture()), packageNames(b))
+	})
+}
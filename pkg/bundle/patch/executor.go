@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"regexp"
 
+	"github.com/gobwas/glob"
 	"github.com/imdario/mergo"
 	"github.com/jmespath/go-jmespath"
 
@@ -93,37 +94,16 @@ func compileSelector(s *bundlev1.PatchSelector, values map[string]interface{}) (
 		return nil, fmt.Errorf("cannot process nil selector")
 	}
 
+	specs := []selector.Specification{}
+
 	// Has matchPath selector
 	if s.MatchPath != nil {
-		if s.MatchPath.Strict != "" {
-			// Evaluation with template engine first
-			value, err := engine.Render(s.MatchPath.Strict, map[string]interface{}{
-				"Values": values,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("unable to evaluate template before matchPath build: %w", err)
-			}
-
-			// Return specification
-			return selector.MatchPathStrict(value), nil
+		spec, err := compileMatchPath(s.MatchPath, values)
+		if err != nil {
+			return nil, err
 		}
-		if s.MatchPath.Regex != "" {
-			// Evaluation with template engine first
-			value, err := engine.Render(s.MatchPath.Regex, map[string]interface{}{
-				"Values": values,
-			})
-			if err != nil {
-				return nil, fmt.Errorf("unable to evaluate template before matchPath build: %w", err)
-			}
-
-			// Compile regexp
-			re, err := regexp.Compile(value)
-			if err != nil {
-				return nil, fmt.Errorf("unable to compile macthPath regexp `%s`: %w", s.MatchPath.Regex, err)
-			}
-
-			// Return specification
-			return selector.MatchPathRegex(re), nil
+		if spec != nil {
+			specs = append(specs, spec)
 		}
 	}
 
@@ -135,12 +115,85 @@ func compileSelector(s *bundlev1.PatchSelector, values map[string]interface{}) (
 			return nil, fmt.Errorf("unable to compile jmesPath expression `%s`: %w", s.JmesPath, err)
 		}
 
+		// Append specification
+		specs = append(specs, selector.MatchJMESPath(exp))
+	}
+
+	// Has matchLabels selector
+	if len(s.MatchLabels) > 0 {
+		labels, err := precompileMap(s.MatchLabels, values)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile matchLabels templates: %w", err)
+		}
+
+		// Append specification
+		specs = append(specs, selector.MatchLabels(labels))
+	}
+
+	switch len(specs) {
+	case 0:
+		// Fallback to default as error
+		return nil, fmt.Errorf("no supported selector specified")
+	case 1:
+		return specs[0], nil
+	default:
+		// All selectors must match
+		return selector.All(specs...), nil
+	}
+}
+
+func compileMatchPath(s *bundlev1.PatchSelectorMatchPath, values map[string]interface{}) (selector.Specification, error) {
+	if s.Strict != "" {
+		// Evaluation with template engine first
+		value, err := engine.Render(s.Strict, map[string]interface{}{
+			"Values": values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate template before matchPath build: %w", err)
+		}
+
+		// Return specification
+		return selector.MatchPathStrict(value), nil
+	}
+	if s.Regex != "" {
+		// Evaluation with template engine first
+		value, err := engine.Render(s.Regex, map[string]interface{}{
+			"Values": values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate template before matchPath build: %w", err)
+		}
+
+		// Compile regexp
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile macthPath regexp `%s`: %w", s.Regex, err)
+		}
+
+		// Return specification
+		return selector.MatchPathRegex(re), nil
+	}
+	if s.Glob != "" {
+		// Evaluation with template engine first
+		value, err := engine.Render(s.Glob, map[string]interface{}{
+			"Values": values,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate template before matchPath build: %w", err)
+		}
+
+		// Compile glob
+		g, err := glob.Compile(value, '/')
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile matchPath glob `%s`: %w", s.Glob, err)
+		}
+
 		// Return specification
-		return selector.MatchJMESPath(exp), nil
+		return selector.MatchPathGlob(g), nil
 	}
 
-	// Fallback to default as error
-	return nil, fmt.Errorf("no supported selector specified")
+	// No path matcher
+	return nil, nil
 }
 
 func applyPackagePatch(pkg *bundlev1.Package, p *bundlev1.PatchPackage, values map[string]interface{}) error {
@@ -259,6 +312,14 @@ func applySecretPatch(secrets *bundlev1.SecretChain, op *bundlev1.PatchSecret, v
 		}
 	}
 
+	// Check key rewrite
+	if op.RewriteKey != nil {
+		var err error
+		if secrets.Data, err = applySecretKeyRewrite(secrets.Data, op.RewriteKey, values); err != nil {
+			return fmt.Errorf("unable to rewrite keys: %w", err)
+		}
+	}
+
 	// No error
 	return nil
 }
@@ -306,6 +367,74 @@ func applySecretKVPatch(kv []*bundlev1.KV, op *bundlev1.PatchOperation, values m
 	return out, nil
 }
 
+func applySecretKeyRewrite(kv []*bundlev1.KV, op *bundlev1.PatchKeyRewrite, values map[string]interface{}) ([]*bundlev1.KV, error) {
+	// Check parameters
+	if op == nil {
+		return nil, fmt.Errorf("cannot process nil operation")
+	}
+	if op.Match == "" {
+		return nil, fmt.Errorf("cannot process rewrite with blank match expression")
+	}
+
+	// Evaluation with template engine first
+	match, err := engine.Render(op.Match, map[string]interface{}{
+		"Values": values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate match template: %w", err)
+	}
+	replace, err := engine.Render(op.Replace, map[string]interface{}{
+		"Values": values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to evaluate replace template: %w", err)
+	}
+
+	// Compile regexp
+	re, err := regexp.Compile(match)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile match regexp `%s`: %w", op.Match, err)
+	}
+
+	out := make([]*bundlev1.KV, 0, len(kv))
+	keys := map[string]string{}
+
+	// Rewrite keys by preserving secret order
+	for _, s := range kv {
+		// Ignore nil
+		if s == nil {
+			continue
+		}
+
+		key := s.Key
+		if re.MatchString(key) {
+			key = re.ReplaceAllString(key, replace)
+		}
+		if key == "" {
+			return nil, fmt.Errorf("key `%s` is rewritten as a blank key", s.Key)
+		}
+
+		// Reject collisions with existing or already rewritten keys
+		if previous, ok := keys[key]; ok {
+			return nil, fmt.Errorf("key `%s` rewritten as `%s` collides with key `%s`", s.Key, key, previous)
+		}
+		keys[key] = s.Key
+
+		// Append to result
+		if key != s.Key {
+			s = &bundlev1.KV{
+				Key:   key,
+				Type:  s.Type,
+				Value: s.Value,
+			}
+		}
+		out = append(out, s)
+	}
+
+	// No error
+	return out, nil
+}
+
 func removeSecret(input []*bundlev1.KV, removeList []string) []*bundlev1.KV {
 	out := []*bundlev1.KV{}
 
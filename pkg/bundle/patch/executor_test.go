@@ -226,3 +226,26 @@ func Test_applySecretKVPatch_Fuzz(t *testing.T) {
 		applySecretKVPatch(file.Packages[0].Secrets.Data, spec, values)
 	}
 }
+
+func Test_applySecretKeyRewrite_Fuzz(t *testing.T) {
+	// Making sure the applySecretKeyRewrite never panics
+	for i := 0; i < 500; i++ {
+		f := fuzz.New()
+
+		// Prepare arguments
+		values := map[string]interface{}{}
+		spec := &bundlev1.PatchKeyRewrite{}
+		data := []*bundlev1.KV{
+			{
+				Key:   "k1",
+				Value: []byte("v1"),
+			},
+		}
+
+		f.Fuzz(&data)
+		f.Fuzz(&spec)
+
+		// Execute
+		applySecretKeyRewrite(data, spec, values)
+	}
+}
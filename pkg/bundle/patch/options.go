@@ -0,0 +1,47 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package patch
+
+type options struct {
+	report *Report
+}
+
+// OptionFunc defines the functional pattern for patch execution settings.
+type OptionFunc func(*options)
+
+// WithReport collects rule execution statistics in the given report.
+func WithReport(r *Report) OptionFunc {
+	return func(opts *options) {
+		opts.report = r
+	}
+}
+
+// Report holds patch execution statistics.
+type Report struct {
+	Rules []*RuleReport `json:"rules"`
+}
+
+// RuleReport holds the package counts of a patch rule execution.
+type RuleReport struct {
+	// Index of the rule in the patch specification.
+	Index int `json:"index"`
+	// Applied is the count of packages matched and patched by the rule.
+	Applied int `json:"applied"`
+	// Skipped is the count of packages ignored by the rule selector.
+	Skipped int `json:"skipped"`
+}
@@ -76,7 +76,7 @@ func Checksum(spec *bundlev1.Patch) (string, error) {
 
 // Apply given patch to the given bundle.
 //nolint:interfacer // Explicit type restriction
-func Apply(spec *bundlev1.Patch, b *bundlev1.Bundle, values map[string]interface{}) (*bundlev1.Bundle, error) {
+func Apply(spec *bundlev1.Patch, b *bundlev1.Bundle, values map[string]interface{}, opts ...OptionFunc) (*bundlev1.Bundle, error) {
 	// Validate spec
 	if err := Validate(spec); err != nil {
 		return nil, fmt.Errorf("unable to validate spec: %w", err)
@@ -90,6 +90,18 @@ func Apply(spec *bundlev1.Patch, b *bundlev1.Bundle, values map[string]interface
 		return nil, fmt.Errorf("empty bundle patch")
 	}
 
+	// Apply options
+	dopts := &options{}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Prepare rule statistics
+	rules := make([]*RuleReport, len(spec.Spec.Rules))
+	for i := range rules {
+		rules[i] = &RuleReport{Index: i}
+	}
+
 	// Copy bundle
 	bCopy, ok := proto.Clone(b).(*bundlev1.Bundle)
 	if !ok {
@@ -129,6 +141,14 @@ func Apply(spec *bundlev1.Patch, b *bundlev1.Bundle, values map[string]interface
 			if err != nil {
 				return nil, fmt.Errorf("unable to execute rule index %d: %w", i, err)
 			}
+
+			// Update statistics
+			if action == packageUnchanged {
+				rules[i].Skipped++
+			} else {
+				rules[i].Applied++
+			}
+
 			if action == packagedRemoved {
 				lastAction = action
 				break
@@ -144,6 +164,11 @@ func Apply(spec *bundlev1.Patch, b *bundlev1.Bundle, values map[string]interface
 	// Reassign packages
 	bCopy.Packages = packageList
 
+	// Publish statistics
+	if dopts.report != nil {
+		dopts.report.Rules = rules
+	}
+
 	// No error
 	return bCopy, nil
 }
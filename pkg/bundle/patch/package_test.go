@@ -405,6 +405,65 @@ func TestApply(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "rewrite keys",
+			args: args{
+				spec: mustLoadPatch("../../../test/fixtures/patch/valid/key-rewriter.yaml"),
+				b: &bundlev1.Bundle{
+					Packages: []*bundlev1.Package{
+						{
+							Name: "application/component-1",
+							Secrets: &bundlev1.SecretChain{
+								Data: []*bundlev1.KV{
+									{Key: "DB_USER", Type: "string", Value: []byte("user")},
+									{Key: "DB_PASSWORD", Type: "string", Value: []byte("password")},
+								},
+							},
+						},
+					},
+				},
+				values: map[string]interface{}{},
+			},
+			wantErr: false,
+			want: &bundlev1.Bundle{
+				Packages: []*bundlev1.Package{
+					{
+						Name: "application/component-1",
+						Annotations: map[string]string{
+							"patched":      "true",
+							"key-rewriter": "true",
+						},
+						Secrets: &bundlev1.SecretChain{
+							Data: []*bundlev1.KV{
+								{Key: "DB_USER", Type: "string", Value: []byte("user")},
+								{Key: "DB_SECRET", Type: "string", Value: []byte("password")},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "rewrite keys collision",
+			args: args{
+				spec: mustLoadPatch("../../../test/fixtures/patch/valid/key-rewriter.yaml"),
+				b: &bundlev1.Bundle{
+					Packages: []*bundlev1.Package{
+						{
+							Name: "application/component-1",
+							Secrets: &bundlev1.SecretChain{
+								Data: []*bundlev1.KV{
+									{Key: "DB_PASSWORD", Type: "string", Value: []byte("password")},
+									{Key: "DB_SECRET", Type: "string", Value: []byte("secret")},
+								},
+							},
+						},
+					},
+				},
+				values: map[string]interface{}{},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -419,3 +478,34 @@ func TestApply(t *testing.T) {
 		})
 	}
 }
+
+func TestApply_Report(t *testing.T) {
+	spec := mustLoadPatch("../../../test/fixtures/patch/valid/label-annotator.yaml")
+	b := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{Name: "app/production/db", Labels: map[string]string{"env": "production"}},
+			{Name: "app/staging/db", Labels: map[string]string{"env": "staging"}},
+			{Name: "infra/production/db", Labels: map[string]string{"env": "production"}},
+		},
+	}
+
+	report := &Report{}
+	got, err := Apply(spec, b, map[string]interface{}{"env": "production"}, WithReport(report))
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	// Only the matching package is annotated
+	if got.Packages[0].Annotations["audit"] != "required" {
+		t.Errorf("expected matching package to be annotated, got %v", got.Packages[0].Annotations)
+	}
+	if len(got.Packages[1].Annotations) > 0 || len(got.Packages[2].Annotations) > 0 {
+		t.Errorf("expected non matching packages to be unchanged")
+	}
+
+	// Rule statistics
+	want := []*RuleReport{{Index: 0, Applied: 1, Skipped: 2}}
+	if !reflect.DeepEqual(report.Rules, want) {
+		t.Errorf("unexpected report, got %v, want %v", report.Rules, want)
+	}
+}
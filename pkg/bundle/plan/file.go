@@ -0,0 +1,58 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	"github.com/spf13/afero"
+)
+
+// FileContentKey is the data key holding the file content in file states.
+const FileContentKey = "content"
+
+// FileState returns a state reader backed by the given filesystem, file
+// contents are exposed with the FileContentKey key.
+func FileState(fs afero.Fs) State {
+	return &fileState{
+		fs: fs,
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type fileState struct {
+	fs afero.Fs
+}
+
+func (s *fileState) Read(_ context.Context, filePath string) (map[string]interface{}, bool, error) {
+	content, err := afero.ReadFile(s.fs, filePath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+
+	// No error
+	return map[string]interface{}{
+		FileContentKey: string(content),
+	}, true, nil
+}
@@ -0,0 +1,98 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package plan computes the changes a publication would apply to a target
+// (Vault K/V backends, rendered files) without applying them.
+package plan
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ActionType describes the change applied to a path.
+type ActionType string
+
+const (
+	// Create is used when the path doesn't exist in the current state.
+	Create ActionType = "create"
+	// Update is used when the path exists with a different content.
+	Update ActionType = "update"
+	// NoOp is used when the path exists with the desired content.
+	NoOp ActionType = "no-op"
+)
+
+// Action describes the change applied to a path. Only key names are
+// recorded, secret values are never exposed.
+type Action struct {
+	Path    string
+	Type    ActionType
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Plan holds the actions ordered by path.
+type Plan struct {
+	Actions []Action
+}
+
+// Count returns the action count of the given type.
+func (p *Plan) Count(t ActionType) int {
+	count := 0
+	for _, a := range p.Actions {
+		if a.Type == t {
+			count++
+		}
+	}
+	return count
+}
+
+// HasChanges returns true if at least one path will be created or updated.
+func (p *Plan) HasChanges() bool {
+	return p.Count(Create)+p.Count(Update) > 0
+}
+
+// Render writes the human readable plan to the given writer.
+func (p *Plan) Render(w io.Writer) error {
+	var sb strings.Builder
+
+	for _, a := range p.Actions {
+		switch a.Type {
+		case Create:
+			fmt.Fprintf(&sb, "  + %s\n", a.Path)
+		case Update:
+			fmt.Fprintf(&sb, "  ~ %s\n", a.Path)
+		case NoOp:
+			fmt.Fprintf(&sb, "    %s\n", a.Path)
+		}
+		for _, k := range a.Added {
+			fmt.Fprintf(&sb, "      + %s\n", k)
+		}
+		for _, k := range a.Changed {
+			fmt.Fprintf(&sb, "      ~ %s\n", k)
+		}
+		for _, k := range a.Removed {
+			fmt.Fprintf(&sb, "      - %s\n", k)
+		}
+	}
+	fmt.Fprintf(&sb, "\nPlan: %d to create, %d to update, %d unchanged.\n", p.Count(Create), p.Count(Update), p.Count(NoOp))
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
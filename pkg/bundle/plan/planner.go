@@ -0,0 +1,195 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// State describes the current state reader contract.
+type State interface {
+	// Read returns the current data of the given path, found is false when
+	// the path doesn't exist.
+	Read(ctx context.Context, path string) (data map[string]interface{}, found bool, err error)
+}
+
+// Planner computes plans by comparing the current state to the desired one.
+type Planner struct {
+	state State
+}
+
+// New returns a planner instance reading the current state from the given
+// state reader.
+func New(state State) *Planner {
+	return &Planner{
+		state: state,
+	}
+}
+
+// Plan computes the actions required to reach the desired state, desired
+// data are indexed by path.
+func (p *Planner) Plan(ctx context.Context, desired map[string]map[string]interface{}) (*Plan, error) {
+	// Check arguments
+	if types.IsNil(p.state) {
+		return nil, errors.New("unable to compute a plan with a nil state")
+	}
+
+	// Sort paths
+	paths := make([]string, 0, len(desired))
+	for k := range desired {
+		paths = append(paths, k)
+	}
+	sort.Strings(paths)
+
+	out := &Plan{
+		Actions: make([]Action, 0, len(paths)),
+	}
+	for _, secretPath := range paths {
+		// Read current state
+		current, found, err := p.state.Read(ctx, secretPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read current state of '%s': %w", secretPath, err)
+		}
+
+		// Compare with the desired state
+		action, err := diff(secretPath, current, found, desired[secretPath])
+		if err != nil {
+			return nil, err
+		}
+
+		out.Actions = append(out.Actions, action)
+	}
+
+	// No error
+	return out, nil
+}
+
+// Bundle computes the actions required to publish the given bundle packages
+// with the given path prefix.
+func (p *Planner) Bundle(ctx context.Context, b *bundlev1.Bundle, prefix string) (*Plan, error) {
+	// Check arguments
+	if b == nil {
+		return nil, errors.New("unable to compute a plan with a nil bundle")
+	}
+
+	// Unpack desired state
+	desired := map[string]map[string]interface{}{}
+	for _, pkg := range b.Packages {
+		if pkg == nil || pkg.Secrets == nil {
+			continue
+		}
+
+		data := map[string]interface{}{}
+		for _, s := range pkg.Secrets.Data {
+			var value interface{}
+			if err := secret.Unpack(s.Value, &value); err != nil {
+				return nil, fmt.Errorf("unable to unpack secret value for path '%s' with key '%s': %w", pkg.Name, s.Key, err)
+			}
+			data[s.Key] = value
+		}
+
+		// Assemble secret path
+		secretPath := pkg.Name
+		if prefix != "" {
+			secretPath = path.Join(prefix, secretPath)
+		}
+		desired[secretPath] = data
+	}
+
+	// Delegate to planner
+	return p.Plan(ctx, desired)
+}
+
+// -----------------------------------------------------------------------------
+
+func diff(secretPath string, current map[string]interface{}, found bool, desired map[string]interface{}) (Action, error) {
+	action := Action{
+		Path: secretPath,
+		Type: NoOp,
+	}
+
+	// Missing path
+	if !found {
+		action.Type = Create
+		action.Added = sortedKeys(desired)
+		return action, nil
+	}
+
+	// Compare keys
+	for _, k := range sortedKeys(desired) {
+		v, ok := current[k]
+		if !ok {
+			action.Added = append(action.Added, k)
+			continue
+		}
+
+		equal, err := jsonEqual(v, desired[k])
+		if err != nil {
+			return action, fmt.Errorf("unable to compare '%s' key of '%s': %w", k, secretPath, err)
+		}
+		if !equal {
+			action.Changed = append(action.Changed, k)
+		}
+	}
+	for _, k := range sortedKeys(current) {
+		if _, ok := desired[k]; !ok {
+			action.Removed = append(action.Removed, k)
+		}
+	}
+
+	if len(action.Added)+len(action.Changed)+len(action.Removed) > 0 {
+		action.Type = Update
+	}
+
+	// No error
+	return action, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// jsonEqual compares values using their JSON encoding so that backend
+// specific value types (json.Number, []byte) are compared by content.
+func jsonEqual(a, b interface{}) (bool, error) {
+	ja, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	jb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(ja, jb), nil
+}
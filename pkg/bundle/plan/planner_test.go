@@ -0,0 +1,170 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+	"github.com/elastic/harp/pkg/vault/kv"
+)
+
+type mapState map[string]map[string]interface{}
+
+func (s mapState) Read(_ context.Context, path string) (map[string]interface{}, bool, error) {
+	if path == "failing" {
+		return nil, false, errors.New("test")
+	}
+	data, ok := s[path]
+	return data, ok, nil
+}
+
+func mustPack(t *testing.T, value interface{}) []byte {
+	out, err := secret.Pack(value)
+	assert.NoError(t, err)
+	return out
+}
+
+func TestPlanner_Bundle(t *testing.T) {
+	state := mapState{
+		"secrets/app/unchanged": {"user": "admin", "port": json.Number("5432")},
+		"secrets/app/updated":   {"user": "admin", "password": "old", "legacy": "value"},
+	}
+
+	b := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{
+				Name: "app/updated",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: mustPack(t, "admin")},
+						{Key: "password", Value: mustPack(t, "new")},
+						{Key: "token", Value: mustPack(t, "token")},
+					},
+				},
+			},
+			{
+				Name: "app/unchanged",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: mustPack(t, "admin")},
+						{Key: "port", Value: mustPack(t, 5432)},
+					},
+				},
+			},
+			{
+				Name: "app/created",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{
+						{Key: "b", Value: mustPack(t, "b")},
+						{Key: "a", Value: mustPack(t, "a")},
+					},
+				},
+			},
+		},
+	}
+
+	p, err := New(state).Bundle(context.Background(), b, "secrets")
+	assert.NoError(t, err)
+	assert.True(t, p.HasChanges())
+	assert.Equal(t, []Action{
+		{Path: "secrets/app/created", Type: Create, Added: []string{"a", "b"}},
+		{Path: "secrets/app/unchanged", Type: NoOp},
+		{Path: "secrets/app/updated", Type: Update, Added: []string{"token"}, Changed: []string{"password"}, Removed: []string{"legacy"}},
+	}, p.Actions)
+
+	// Render doesn't expose values
+	var out bytes.Buffer
+	assert.NoError(t, p.Render(&out))
+	assert.Contains(t, out.String(), "  + secrets/app/created\n")
+	assert.Contains(t, out.String(), "  ~ secrets/app/updated\n      + token\n      ~ password\n      - legacy\n")
+	assert.Contains(t, out.String(), "Plan: 1 to create, 1 to update, 1 unchanged.")
+	assert.NotContains(t, out.String(), "new")
+}
+
+func TestPlanner_Errors(t *testing.T) {
+	_, err := New(nil).Plan(context.Background(), map[string]map[string]interface{}{})
+	assert.Error(t, err)
+
+	_, err = New(mapState{}).Bundle(context.Background(), nil, "")
+	assert.Error(t, err)
+
+	_, err = New(mapState{}).Plan(context.Background(), map[string]map[string]interface{}{"failing": {}})
+	assert.Error(t, err)
+}
+
+type readErrorService struct {
+	kv.Service
+	errs map[string]error
+}
+
+func (s *readErrorService) Read(_ context.Context, path string) (kv.SecretData, kv.SecretMetadata, error) {
+	return nil, nil, s.errs[path]
+}
+
+func TestVaultState_Absent(t *testing.T) {
+	state := &vaultState{
+		backends: map[string]kv.Service{
+			"secrets": &readErrorService{errs: map[string]error{
+				"secrets/app/missing":      fmt.Errorf("unable to retrieve secret: %w", kv.ErrPathNotFound),
+				"secrets/app/soft-deleted": fmt.Errorf("unable to retrieve secret: %w", kv.ErrNoData),
+				"secrets/app/failing":      errors.New("test"),
+			}},
+		},
+	}
+
+	p, err := New(state).Plan(context.Background(), map[string]map[string]interface{}{
+		"secrets/app/missing":      {"user": "admin"},
+		"secrets/app/soft-deleted": {"user": "admin"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []Action{
+		{Path: "secrets/app/missing", Type: Create, Added: []string{"user"}},
+		{Path: "secrets/app/soft-deleted", Type: Create, Added: []string{"user"}},
+	}, p.Actions)
+
+	_, err = New(state).Plan(context.Background(), map[string]map[string]interface{}{
+		"secrets/app/failing": {"user": "admin"},
+	})
+	assert.Error(t, err)
+}
+
+func TestFileState(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "unchanged.txt", []byte("content"), 0o600))
+	assert.NoError(t, afero.WriteFile(fs, "updated.txt", []byte("old"), 0o600))
+
+	p, err := New(FileState(fs)).Plan(context.Background(), map[string]map[string]interface{}{
+		"unchanged.txt": {FileContentKey: "content"},
+		"updated.txt":   {FileContentKey: "new"},
+		"created.txt":   {FileContentKey: "new"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p.Count(Create))
+	assert.Equal(t, 1, p.Count(Update))
+	assert.Equal(t, 1, p.Count(NoOp))
+}
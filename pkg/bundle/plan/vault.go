@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package plan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/elastic/harp/pkg/vault/kv"
+	vpath "github.com/elastic/harp/pkg/vault/path"
+)
+
+// VaultState returns a state reader backed by Vault K/V backends.
+func VaultState(client *api.Client) State {
+	return &vaultState{
+		client:   client,
+		backends: map[string]kv.Service{},
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type vaultState struct {
+	client   *api.Client
+	backends map[string]kv.Service
+	mu       sync.Mutex
+}
+
+func (s *vaultState) Read(ctx context.Context, secretPath string) (map[string]interface{}, bool, error) {
+	// Resolve backend service
+	service, err := s.backend(secretPath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	// Read current secret
+	data, _, err := service.Read(ctx, secretPath)
+	switch {
+	case errors.Is(err, kv.ErrPathNotFound), errors.Is(err, kv.ErrNoData):
+		// Soft-deleted secrets are written as new ones.
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+
+	// Ignore the metadata storage key
+	delete(data, kv.VaultMetadataDataKey)
+
+	// No error
+	return data, true, nil
+}
+
+func (s *vaultState) backend(secretPath string) (kv.Service, error) {
+	// Extract root backend path
+	rootPath := strings.Split(vpath.SanitizePath(secretPath), "/")[0]
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Check backend initialization
+	if service, ok := s.backends[rootPath]; ok {
+		return service, nil
+	}
+
+	// Initialize new service for backend
+	service, err := kv.New(s.client, rootPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize Vault service for '%s' KV backend: %w", rootPath, err)
+	}
+	s.backends[rootPath] = service
+
+	// No error
+	return service, nil
+}
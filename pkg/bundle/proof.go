@@ -0,0 +1,174 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"gitlab.com/NebulousLabs/merkletree"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/hash"
+)
+
+var (
+	// ErrPackageNotFound is raised when the proof target package doesn't exist.
+	ErrPackageNotFound = errors.New("package not found")
+	// ErrInvalidProof is raised when the package doesn't match the proof and
+	// the trusted merkle tree root.
+	ErrInvalidProof = errors.New("invalid package proof")
+)
+
+// merkleLeafPrefix is the leaf domain separation prefix used by the merkle
+// tree implementation.
+var merkleLeafPrefix = []byte{0x00}
+
+// Proof is a package membership proof against the bundle merkle tree root.
+//
+// Package secrets are contiguous leaves of the bundle merkle tree, the proof
+// holds the subtree hashes required to recompute the root from these leaves
+// only.
+type Proof struct {
+	Path   string   `json:"path"`
+	Start  uint64   `json:"start"`
+	End    uint64   `json:"end"`
+	Hashes [][]byte `json:"hashes"`
+}
+
+// ProofFor returns the membership proof of the given package path. The proof
+// is verified with VerifyProof against the bundle merkle tree root.
+func ProofFor(b *bundlev1.Bundle, packagePath string) (*Proof, error) {
+	// Check arguments
+	if b == nil {
+		return nil, fmt.Errorf("unable to process nil bundle")
+	}
+
+	// Ensure packages order without modifying the bundle
+	pkgs := append([]*bundlev1.Package{}, b.Packages...)
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	// Compute leaf hashes
+	var (
+		leafHashes [][]byte
+		start      = -1
+		end        = -1
+	)
+	for _, p := range pkgs {
+		leaves := packageLeaves(p)
+		if p.Name == packagePath {
+			start, end = len(leafHashes), len(leafHashes)+len(leaves)
+		}
+		for _, l := range leaves {
+			h, err := leafHash(l)
+			if err != nil {
+				return nil, err
+			}
+			leafHashes = append(leafHashes, h)
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("unable to build proof for '%s': %w", packagePath, ErrPackageNotFound)
+	}
+	if start == end {
+		return nil, fmt.Errorf("unable to build proof for '%s': package has no secret", packagePath)
+	}
+
+	// Build range proof
+	h, err := hash.New(treeHashAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize hash function for merkle tree: %w", err)
+	}
+	hashes, err := merkletree.BuildRangeProof(start, end, merkletree.NewCachedSubtreeHasher(leafHashes, h))
+	if err != nil {
+		return nil, fmt.Errorf("unable to build proof for '%s': %w", packagePath, err)
+	}
+
+	// No error
+	return &Proof{
+		Path:   packagePath,
+		Start:  uint64(start),
+		End:    uint64(end),
+		Hashes: hashes,
+	}, nil
+}
+
+// VerifyProof checks that the given package belongs to the bundle identified
+// by the given trusted merkle tree root.
+func VerifyProof(root []byte, proof *Proof, p *bundlev1.Package) error {
+	// Check arguments
+	if len(root) == 0 {
+		return errors.New("unable to verify a proof with a blank root")
+	}
+	if proof == nil {
+		return errors.New("unable to verify a nil proof")
+	}
+	if p == nil {
+		return errors.New("unable to verify a nil package")
+	}
+	if proof.Path != p.Name {
+		return fmt.Errorf("%w: proof is for '%s' package", ErrInvalidProof, proof.Path)
+	}
+
+	// Compute package leaf hashes
+	leaves := packageLeaves(p)
+	if proof.End <= proof.Start || proof.End-proof.Start != uint64(len(leaves)) {
+		return fmt.Errorf("%w: leaf range doesn't match package secret count", ErrInvalidProof)
+	}
+	leafHashes := make([][]byte, len(leaves))
+	for i, l := range leaves {
+		h, err := leafHash(l)
+		if err != nil {
+			return err
+		}
+		leafHashes[i] = h
+	}
+
+	// Verify range proof
+	h, err := hash.New(treeHashAlgorithm)
+	if err != nil {
+		return fmt.Errorf("unable to initialize hash function for merkle tree: %w", err)
+	}
+	valid, err := merkletree.VerifyRangeProof(merkletree.NewCachedLeafHasher(leafHashes), h, int(proof.Start), int(proof.End), proof.Hashes, root)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProof, err)
+	}
+	if !valid {
+		return ErrInvalidProof
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func leafHash(leaf []byte) ([]byte, error) {
+	h, err := hash.New(treeHashAlgorithm)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize hash function for merkle tree: %w", err)
+	}
+
+	h.Write(merkleLeafPrefix)
+	h.Write(leaf)
+
+	return h.Sum(nil), nil
+}
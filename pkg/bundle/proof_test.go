@@ -0,0 +1,123 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func proofBundle(count int) *bundlev1.Bundle {
+	b := &bundlev1.Bundle{}
+	for i := count - 1; i >= 0; i-- {
+		secrets := []*bundlev1.KV{}
+		for j := 0; j <= i%4; j++ {
+			secrets = append(secrets, &bundlev1.KV{
+				Key:   fmt.Sprintf("key-%d", j),
+				Value: []byte(fmt.Sprintf("value-%d-%d", i, j)),
+			})
+		}
+		b.Packages = append(b.Packages, &bundlev1.Package{
+			Name: fmt.Sprintf("app/production/service-%02d", i),
+			Secrets: &bundlev1.SecretChain{
+				Version: uint32(i),
+				Data:    secrets,
+			},
+		})
+	}
+
+	return b
+}
+
+func Test_ProofFor(t *testing.T) {
+	b := proofBundle(13)
+	b.Packages = append(b.Packages, &bundlev1.Package{Name: "app/production/empty"})
+
+	tree, _, err := Tree(proofBundle(13))
+	assert.NoError(t, err)
+	root := tree.Root()
+
+	for _, p := range proofBundle(13).Packages {
+		pkg := p
+		t.Run(pkg.Name, func(t *testing.T) {
+			proof, err := ProofFor(b, pkg.Name)
+			assert.NoError(t, err)
+			assert.NoError(t, VerifyProof(root, proof, pkg))
+		})
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		pkg := proofBundle(13).Packages[3]
+		proof, err := ProofFor(b, pkg.Name)
+		assert.NoError(t, err)
+
+		pkg.Secrets.Data[0].Value = []byte("tampered")
+		err = VerifyProof(root, proof, pkg)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("other package", func(t *testing.T) {
+		proof, err := ProofFor(b, "app/production/service-01")
+		assert.NoError(t, err)
+
+		other := proofBundle(13).Packages[0]
+		other.Name = "app/production/service-01"
+		err = VerifyProof(root, proof, other)
+		assert.Error(t, err)
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+
+		err = VerifyProof(root, proof, proofBundle(13).Packages[0])
+		assert.True(t, errors.Is(err, ErrInvalidProof))
+	})
+
+	t.Run("untrusted root", func(t *testing.T) {
+		pkg := proofBundle(13).Packages[5]
+		proof, err := ProofFor(b, pkg.Name)
+		assert.NoError(t, err)
+
+		otherTree, _, err := Tree(proofBundle(12))
+		assert.NoError(t, err)
+		assert.Error(t, VerifyProof(otherTree.Root(), proof, pkg))
+	})
+}
+
+func Test_ProofFor_Errors(t *testing.T) {
+	b := proofBundle(2)
+	b.Packages = append(b.Packages, &bundlev1.Package{Name: "app/production/empty"})
+
+	_, err := ProofFor(nil, "app")
+	assert.Error(t, err)
+
+	_, err = ProofFor(b, "app/unknown")
+	assert.True(t, errors.Is(err, ErrPackageNotFound))
+
+	_, err = ProofFor(b, "app/production/empty")
+	assert.Error(t, err)
+
+	proof, err := ProofFor(b, "app/production/service-00")
+	assert.NoError(t, err)
+	assert.Error(t, VerifyProof(nil, proof, b.Packages[1]))
+	assert.Error(t, VerifyProof([]byte("root"), nil, b.Packages[1]))
+	assert.Error(t, VerifyProof([]byte("root"), proof, nil))
+}
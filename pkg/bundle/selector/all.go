@@ -0,0 +1,41 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package selector
+
+// All returns a specification satisfied when all given specifications are
+// satisfied.
+func All(specs ...Specification) Specification {
+	return &allOf{
+		specs: specs,
+	}
+}
+
+type allOf struct {
+	specs []Specification
+}
+
+// IsSatisfiedBy returns specification satisfaction status
+func (s *allOf) IsSatisfiedBy(object interface{}) bool {
+	for _, spec := range s.specs {
+		if !spec.IsSatisfiedBy(object) {
+			return false
+		}
+	}
+
+	return true
+}
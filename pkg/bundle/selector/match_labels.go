@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package selector
+
+import (
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+// MatchLabels returns a package label matcher specification. All given labels
+// must be assigned to the package with the same value.
+func MatchLabels(labels map[string]string) Specification {
+	return &matchLabels{
+		labels: labels,
+	}
+}
+
+type matchLabels struct {
+	labels map[string]string
+}
+
+// IsSatisfiedBy returns specification satisfaction status
+func (s *matchLabels) IsSatisfiedBy(object interface{}) bool {
+	// If object is a package
+	if p, ok := object.(*bundlev1.Package); ok {
+		for k, v := range s.labels {
+			if value, ok := p.Labels[k]; !ok || value != v {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return false
+}
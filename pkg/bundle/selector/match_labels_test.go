@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package selector
+
+import (
+	"testing"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func Test_matchLabels_IsSatisfiedBy(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		object interface{}
+		want   bool
+	}{
+		{
+			name: "nil",
+			want: false,
+		},
+		{
+			name:   "not supported type",
+			object: struct{}{},
+			want:   false,
+		},
+		{
+			name:   "no labels",
+			object: &bundlev1.Package{},
+			want:   true,
+		},
+		{
+			name:   "missing label",
+			labels: map[string]string{"env": "prod"},
+			object: &bundlev1.Package{},
+			want:   false,
+		},
+		{
+			name:   "different value",
+			labels: map[string]string{"env": "prod"},
+			object: &bundlev1.Package{
+				Labels: map[string]string{"env": "staging"},
+			},
+			want: false,
+		},
+		{
+			name:   "partial match",
+			labels: map[string]string{"env": "prod", "tier": "db"},
+			object: &bundlev1.Package{
+				Labels: map[string]string{"env": "prod"},
+			},
+			want: false,
+		},
+		{
+			name:   "match",
+			labels: map[string]string{"env": "prod"},
+			object: &bundlev1.Package{
+				Labels: map[string]string{"env": "prod", "tier": "db"},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchLabels(tt.labels).IsSatisfiedBy(tt.object); got != tt.want {
+				t.Errorf("matchLabels.IsSatisfiedBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -21,6 +21,8 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/gobwas/glob"
+
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 )
 
@@ -38,10 +40,18 @@ func MatchPathRegex(regex *regexp.Regexp) Specification {
 	}
 }
 
+// MatchPathGlob returns a path matcher specification with glob pattern.
+func MatchPathGlob(g glob.Glob) Specification {
+	return &matchPath{
+		glob: g,
+	}
+}
+
 // MatchPath checks if secret path match the given string
 type matchPath struct {
 	strict string
 	regex  *regexp.Regexp
+	glob   glob.Glob
 }
 
 // IsSatisfiedBy returns specification satisfaction status
@@ -57,6 +67,11 @@ func (s *matchPath) IsSatisfiedBy(object interface{}) bool {
 		if s.regex != nil {
 			return s.regex.MatchString(p.Name)
 		}
+
+		// Glob mode
+		if s.glob != nil {
+			return s.glob.Match(p.Name)
+		}
 	}
 
 	return false
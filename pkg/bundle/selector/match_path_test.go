@@ -21,6 +21,8 @@ import (
 	"regexp"
 	"testing"
 
+	"github.com/gobwas/glob"
+
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	fuzz "github.com/google/gofuzz"
 )
@@ -29,6 +31,7 @@ func Test_matchPath_IsSatisfiedBy(t *testing.T) {
 	type fields struct {
 		strict string
 		regex  *regexp.Regexp
+		glob   glob.Glob
 	}
 	type args struct {
 		object interface{}
@@ -119,12 +122,49 @@ func Test_matchPath_IsSatisfiedBy(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "supported type: glob mode not match",
+			fields: fields{
+				glob: glob.MustCompile("app/*/db", '/'),
+			},
+			args: args{
+				object: &bundlev1.Package{
+					Name: "app/production/eu/db",
+				},
+			},
+			want: false,
+		},
+		{
+			name: "supported type: glob mode with match",
+			fields: fields{
+				glob: glob.MustCompile("app/*/db", '/'),
+			},
+			args: args{
+				object: &bundlev1.Package{
+					Name: "app/production/db",
+				},
+			},
+			want: true,
+		},
+		{
+			name: "supported type: recursive glob mode with match",
+			fields: fields{
+				glob: glob.MustCompile("app/**/db", '/'),
+			},
+			args: args{
+				object: &bundlev1.Package{
+					Name: "app/production/eu/db",
+				},
+			},
+			want: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &matchPath{
 				strict: tt.fields.strict,
 				regex:  tt.fields.regex,
+				glob:   tt.fields.glob,
 			}
 			if got := s.IsSatisfiedBy(tt.args.object); got != tt.want {
 				t.Errorf("matchPath.IsSatisfiedBy() = %v, want %v", got, tt.want)
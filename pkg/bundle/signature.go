@@ -0,0 +1,250 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+
+	"golang.org/x/crypto/blake2b"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/security"
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+var (
+	// ErrMissingSignature is raised when the bundle is not signed.
+	ErrMissingSignature = errors.New("bundle signature not found")
+	// ErrInvalidSignature is raised when the bundle signature doesn't match the
+	// bundle content.
+	ErrInvalidSignature = errors.New("invalid bundle signature")
+)
+
+// Sign computes the bundle canonical digest and attaches a detached signature
+// as a bundle annotation. An existing signature is replaced.
+//
+// The signature is a PASETO v4.public token wrapping the digest, it covers
+// bundle labels and annotations, packages metadata, secrets and archived secret
+// versions. Template and values are not covered.
+func Sign(b *bundlev1.Bundle, sk ed25519.PrivateKey) error {
+	// Check arguments
+	if b == nil {
+		return fmt.Errorf("unable to process nil bundle")
+	}
+	if len(sk) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid signing key length")
+	}
+
+	// Compute canonical digest
+	digest, err := canonicalDigest(b)
+	if err != nil {
+		return err
+	}
+
+	// Sign the digest
+	token, err := v4.Sign(digest, sk, "", bundleSignatureAnnotation)
+	if err != nil {
+		return fmt.Errorf("unable to sign bundle digest: %w", err)
+	}
+
+	// Attach the signature
+	if b.Annotations == nil {
+		b.Annotations = map[string]string{}
+	}
+	b.Annotations[bundleSignatureAnnotation] = string(token)
+
+	// No error
+	return nil
+}
+
+// VerifySignature checks the bundle signature using the given public key.
+func VerifySignature(b *bundlev1.Bundle, pk ed25519.PublicKey) error {
+	// Check arguments
+	if b == nil {
+		return fmt.Errorf("unable to process nil bundle")
+	}
+	if len(pk) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid verification key length")
+	}
+
+	// Retrieve the signature
+	token, ok := b.Annotations[bundleSignatureAnnotation]
+	if !ok {
+		return ErrMissingSignature
+	}
+
+	// Compute canonical digest
+	digest, err := canonicalDigest(b)
+	if err != nil {
+		return err
+	}
+
+	// Verify the signature
+	signed, err := v4.Verify([]byte(token), pk, "", bundleSignatureAnnotation)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	// Compare digests
+	if !security.SecureCompare(signed, digest) {
+		return ErrInvalidSignature
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// canonicalDigest returns a BLAKE2b-512 digest of the bundle content. All
+// fields are length prefixed, maps are sorted by keys, packages by name and
+// secrets by key so that the digest doesn't depend on in-memory ordering.
+func canonicalDigest(b *bundlev1.Bundle) ([]byte, error) {
+	h, err := blake2b.New512(nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize hash function for bundle digest")
+	}
+
+	// Bundle metadata
+	writeField(h, []byte("harp.elastic.co/v1/bundle#digest"))
+	writeUint(h, uint64(b.Version))
+	writeMap(h, b.Labels, "")
+	writeMap(h, b.Annotations, bundleSignatureAnnotation)
+
+	// Sort packages without modifying the bundle
+	pkgs := []*bundlev1.Package{}
+	for _, p := range b.Packages {
+		if p != nil {
+			pkgs = append(pkgs, p)
+		}
+	}
+	sort.SliceStable(pkgs, func(i, j int) bool {
+		return pkgs[i].Name < pkgs[j].Name
+	})
+
+	// Packages
+	writeUint(h, uint64(len(pkgs)))
+	for _, p := range pkgs {
+		writeField(h, []byte(p.Name))
+		writeMap(h, p.Labels, "")
+		writeMap(h, p.Annotations, "")
+
+		// Current secret chain
+		writeSecretChain(h, p.Secrets)
+
+		// Archived secret chains sorted by version
+		versions := make([]uint32, 0, len(p.Versions))
+		for v := range p.Versions {
+			versions = append(versions, v)
+		}
+		sort.Slice(versions, func(i, j int) bool {
+			return versions[i] < versions[j]
+		})
+
+		writeUint(h, uint64(len(versions)))
+		for _, v := range versions {
+			writeUint(h, uint64(v))
+			writeSecretChain(h, p.Versions[v])
+		}
+	}
+
+	// No error
+	return h.Sum(nil), nil
+}
+
+func writeSecretChain(h hash.Hash, sc *bundlev1.SecretChain) {
+	// Presence
+	if sc == nil {
+		writeUint(h, 0)
+		return
+	}
+	writeUint(h, 1)
+
+	// Metadata
+	writeUint(h, uint64(sc.Version))
+	writeMap(h, sc.Labels, "")
+	writeMap(h, sc.Annotations, "")
+	writeOptionalUint(h, sc.PreviousVersion)
+	writeOptionalUint(h, sc.NextVersion)
+	if sc.Locked == nil {
+		writeUint(h, 0)
+	} else {
+		writeUint(h, 1)
+		writeField(h, sc.Locked.Value)
+	}
+
+	// Sort secrets
+	kvs := []*bundlev1.KV{}
+	for _, kv := range sc.Data {
+		if kv != nil {
+			kvs = append(kvs, kv)
+		}
+	}
+	sort.SliceStable(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+
+	writeUint(h, uint64(len(kvs)))
+	for _, kv := range kvs {
+		writeField(h, []byte(kv.Key))
+		writeField(h, []byte(kv.Type))
+		writeField(h, kv.Value)
+	}
+}
+
+func writeOptionalUint(h hash.Hash, v *wrapperspb.UInt32Value) {
+	if v == nil {
+		writeUint(h, 0)
+		return
+	}
+	writeUint(h, 1)
+	writeUint(h, uint64(v.Value))
+}
+
+func writeUint(h hash.Hash, v uint64) {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	h.Write(buf[:])
+}
+
+func writeField(h hash.Hash, value []byte) {
+	writeUint(h, uint64(len(value)))
+	h.Write(value)
+}
+
+func writeMap(h hash.Hash, m map[string]string, ignored string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		if k != ignored {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	writeUint(h, uint64(len(keys)))
+	for _, k := range keys {
+		writeField(h, []byte(k))
+		writeField(h, []byte(m[k]))
+	}
+}
@@ -0,0 +1,214 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func signatureFixture() *bundlev1.Bundle {
+	return &bundlev1.Bundle{
+		Labels: map[string]string{"env": "production", "owner": "security"},
+		Packages: []*bundlev1.Package{
+			{
+				Name:   "app/production/smtp",
+				Labels: map[string]string{"tier": "mail"},
+				Secrets: &bundlev1.SecretChain{
+					Version:         2,
+					PreviousVersion: wrapperspb.UInt32(1),
+					Data: []*bundlev1.KV{
+						{Key: "user", Value: []byte("smtp")},
+						{Key: "password", Value: []byte("bar")},
+					},
+				},
+				Versions: map[uint32]*bundlev1.SecretChain{
+					1: {
+						Version:     1,
+						NextVersion: wrapperspb.UInt32(2),
+						Data: []*bundlev1.KV{
+							{Key: "user", Value: []byte("smtp")},
+							{Key: "password", Value: []byte("old")},
+						},
+					},
+				},
+			},
+			{
+				Name: "app/production/db",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "password", Value: []byte("foo")}},
+				},
+			},
+		},
+	}
+}
+
+func Test_Sign_VerifySignature(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	b := signatureFixture()
+	assert.ErrorIs(t, VerifySignature(b, pk), ErrMissingSignature)
+
+	// Sign and verify
+	assert.NoError(t, Sign(b, sk))
+	assert.Contains(t, b.Annotations[bundleSignatureAnnotation], "v4.public.")
+	assert.NoError(t, VerifySignature(b, pk))
+
+	// Package and secret order don't matter
+	reordered := proto.Clone(b).(*bundlev1.Bundle)
+	reordered.Packages[0], reordered.Packages[1] = reordered.Packages[1], reordered.Packages[0]
+	data := reordered.Packages[1].Secrets.Data
+	data[0], data[1] = data[1], data[0]
+	assert.NoError(t, VerifySignature(reordered, pk))
+
+	// Signing twice replaces the signature
+	assert.NoError(t, Sign(b, sk))
+	assert.NoError(t, VerifySignature(b, pk))
+
+	// Wrong key
+	otherPk, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, VerifySignature(b, otherPk), ErrInvalidSignature)
+
+	// Invalid keys
+	assert.Error(t, Sign(b, sk[:10]))
+	assert.Error(t, VerifySignature(b, pk[:10]))
+	assert.Error(t, Sign(nil, sk))
+	assert.Error(t, VerifySignature(nil, pk))
+}
+
+func Test_VerifySignature_Tampering(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		tamper func(b *bundlev1.Bundle)
+	}{
+		{
+			name: "secret value",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[1].Secrets.Data[0].Value = []byte("rotated")
+			},
+		},
+		{
+			name: "secret key",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[1].Secrets.Data[0].Key = "pass"
+			},
+		},
+		{
+			name: "added secret",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[1].Secrets.Data = append(b.Packages[1].Secrets.Data, &bundlev1.KV{Key: "host"})
+			},
+		},
+		{
+			name: "removed package",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages = b.Packages[:1]
+			},
+		},
+		{
+			name: "package name",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Name = "app/staging/smtp"
+			},
+		},
+		{
+			name: "package label",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Labels["tier"] = "db"
+			},
+		},
+		{
+			name: "bundle label",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Labels["env"] = "staging"
+			},
+		},
+		{
+			name: "archived secret value",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Versions[1].Data[1].Value = []byte("forged")
+			},
+		},
+		{
+			name: "archived version number",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Versions[3] = b.Packages[0].Versions[1]
+				delete(b.Packages[0].Versions, 1)
+			},
+		},
+		{
+			name: "removed archived version",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Versions = nil
+			},
+		},
+		{
+			name: "previous version",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Secrets.PreviousVersion = nil
+			},
+		},
+		{
+			name: "next version",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[0].Versions[1].NextVersion = wrapperspb.UInt32(3)
+			},
+		},
+		{
+			name: "empty locked value",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[1].Secrets.Locked = wrapperspb.Bytes([]byte{})
+			},
+		},
+		{
+			name: "removed secret chain",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Packages[1].Secrets = nil
+			},
+		},
+		{
+			name: "signature",
+			tamper: func(b *bundlev1.Bundle) {
+				b.Annotations[bundleSignatureAnnotation] = "v4.public.invalid"
+			},
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			b := signatureFixture()
+			assert.NoError(t, Sign(b, sk))
+
+			testCase.tamper(b)
+			assert.True(t, errors.Is(VerifySignature(b, pk), ErrInvalidSignature))
+		})
+	}
+}
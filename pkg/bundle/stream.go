@@ -0,0 +1,354 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"gitlab.com/NebulousLabs/merkletree"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// Streaming codec works on the same serialized form as Load / Dump.
+//
+// Ordering guarantees:
+//
+//   - StreamReader returns packages in their serialized order, which is the package
+//     name order for bundles produced by Dump or StreamWriter;
+//   - StreamWriter requires packages to be added by ascending name order, as the
+//     merkle tree root depends on it;
+//   - bundle metadata (labels, annotations, version) is serialized before
+//     packages, template, values and merkle tree root after them.
+const (
+	bundlePackagesField       protowire.Number = 4
+	bundleMerkleTreeRootField protowire.Number = 7
+
+	// MaxStreamFieldSize is the maximum size of a serialized package handled
+	// by the streaming codec.
+	MaxStreamFieldSize = 64 << 20
+)
+
+// ErrOutOfOrderPackage is raised when packages are not ordered by name.
+var ErrOutOfOrderPackage = errors.New("package is not ordered by name")
+
+// StreamReader iterates over packages of a serialized bundle without loading the
+// whole bundle in memory.
+//
+// Package content is not trusted until Next returns io.EOF, the merkle tree
+// root is checked once the whole stream has been consumed.
+type StreamReader struct {
+	src      *bufio.Reader
+	meta     *bundlev1.Bundle
+	tree     *merkletree.Tree
+	stats    *Statistic
+	lastName string
+	err      error
+}
+
+// NewStreamReader returns a streaming bundle reader for the Dump serialized form.
+func NewStreamReader(r io.Reader) (*StreamReader, error) {
+	// Check parameters
+	if types.IsNil(r) {
+		return nil, fmt.Errorf("unable to process nil reader")
+	}
+
+	// Initialize merkle tree
+	tree, err := newTree()
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return &StreamReader{
+		src:   bufio.NewReader(r),
+		meta:  &bundlev1.Bundle{},
+		tree:  tree,
+		stats: &Statistic{},
+	}, nil
+}
+
+// NewContainerStreamReader returns a streaming bundle reader for the given container.
+func NewContainerStreamReader(c *containerv1.Container) (*StreamReader, error) {
+	// Decompress bundle
+	zr, err := containerPayload(c)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to bundle reader
+	return NewStreamReader(zr)
+}
+
+// Next returns the next package, io.EOF is returned when all packages have
+// been read and the bundle integrity has been checked.
+func (r *StreamReader) Next() (*bundlev1.Package, error) {
+	// Check previous error
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	p, err := r.next()
+	if err != nil {
+		r.err = err
+		return nil, err
+	}
+
+	// No error
+	return p, nil
+}
+
+// Metadata returns the bundle without packages. Fields serialized after
+// packages are available once Next returned io.EOF.
+func (r *StreamReader) Metadata() *bundlev1.Bundle {
+	return r.meta
+}
+
+// Statistic returns statistics of packages read so far.
+func (r *StreamReader) Statistic() Statistic {
+	return *r.stats
+}
+
+// -----------------------------------------------------------------------------
+
+func (r *StreamReader) next() (*bundlev1.Package, error) {
+	for {
+		// Read field tag
+		tag, err := binary.ReadUvarint(r.src)
+		if errors.Is(err, io.EOF) {
+			return nil, r.verify()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to read field tag: %w", err)
+		}
+		num, typ := protowire.DecodeTag(tag)
+
+		// Read field value
+		value, err := r.readValue(typ)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read field %d: %w", num, err)
+		}
+
+		// Accumulate metadata fields
+		if num != bundlePackagesField || typ != protowire.BytesType {
+			raw := protowire.AppendTag(nil, num, typ)
+			if typ == protowire.BytesType {
+				raw = protowire.AppendBytes(raw, value)
+			} else {
+				raw = append(raw, value...)
+			}
+			if err := (proto.UnmarshalOptions{Merge: true}).Unmarshal(raw, r.meta); err != nil {
+				return nil, fmt.Errorf("unable to decode bundle field %d", num)
+			}
+			continue
+		}
+
+		// Decode package
+		p := &bundlev1.Package{}
+		if err := proto.Unmarshal(value, p); err != nil {
+			return nil, fmt.Errorf("unable to decode package content")
+		}
+
+		// Check order
+		if p.Name < r.lastName {
+			return nil, fmt.Errorf("unable to read package '%s': %w", p.Name, ErrOutOfOrderPackage)
+		}
+		r.lastName = p.Name
+
+		// Update merkle tree
+		pushPackage(r.tree, r.stats, p)
+
+		// No error
+		return p, nil
+	}
+}
+
+func (r *StreamReader) readValue(typ protowire.Type) ([]byte, error) {
+	switch typ {
+	case protowire.VarintType:
+		v, err := binary.ReadUvarint(r.src)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return protowire.AppendVarint(nil, v), nil
+	case protowire.Fixed32Type:
+		return r.readFull(4)
+	case protowire.Fixed64Type:
+		return r.readFull(8)
+	case protowire.BytesType:
+		l, err := binary.ReadUvarint(r.src)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if l > MaxStreamFieldSize {
+			return nil, fmt.Errorf("field size %d exceeds the %d bytes limit", l, MaxStreamFieldSize)
+		}
+		return r.readFull(int(l))
+	default:
+		return nil, fmt.Errorf("unsupported wire type %d", typ)
+	}
+}
+
+func (r *StreamReader) readFull(n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r.src, buf); err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	return buf, nil
+}
+
+func (r *StreamReader) verify() error {
+	// Check if root match
+	if !security.SecureCompare(r.meta.MerkleTreeRoot, r.tree.Root()) {
+		return fmt.Errorf("invalid merkle tree root, bundle is corrupted")
+	}
+
+	return io.EOF
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// -----------------------------------------------------------------------------
+
+// StreamWriter serializes a bundle package by package, the produced content can be
+// read using Load or StreamReader.
+type StreamWriter struct {
+	dst      io.Writer
+	meta     *bundlev1.Bundle
+	tree     *merkletree.Tree
+	stats    *Statistic
+	lastName string
+	closed   bool
+}
+
+// NewStreamWriter returns a streaming bundle writer. Packages of the given metadata
+// bundle are ignored, they must be added using Add.
+func NewStreamWriter(w io.Writer, meta *bundlev1.Bundle) (*StreamWriter, error) {
+	// Check parameters
+	if types.IsNil(w) {
+		return nil, fmt.Errorf("unable to process nil writer")
+	}
+	if meta == nil {
+		meta = &bundlev1.Bundle{}
+	}
+
+	// Initialize merkle tree
+	tree, err := newTree()
+	if err != nil {
+		return nil, err
+	}
+
+	// Serialize leading metadata
+	payload, err := proto.Marshal(&bundlev1.Bundle{
+		Labels:      meta.Labels,
+		Annotations: meta.Annotations,
+		Version:     meta.Version,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode bundle metadata: %w", err)
+	}
+	if _, err := w.Write(payload); err != nil {
+		return nil, fmt.Errorf("unable to write serialized bundle metadata: %w", err)
+	}
+
+	// No error
+	return &StreamWriter{
+		dst:   w,
+		meta:  meta,
+		tree:  tree,
+		stats: &Statistic{},
+	}, nil
+}
+
+// Add serializes the given package. Packages must be added by ascending name
+// order.
+func (w *StreamWriter) Add(p *bundlev1.Package) error {
+	// Check parameters
+	if w.closed {
+		return errors.New("unable to add a package to a closed writer")
+	}
+	if p == nil {
+		return fmt.Errorf("unable to process nil package")
+	}
+	if p.Name < w.lastName {
+		return fmt.Errorf("unable to add package '%s': %w", p.Name, ErrOutOfOrderPackage)
+	}
+
+	// Serialize package
+	payload, err := proto.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("unable to encode package content: %w", err)
+	}
+	if len(payload) > MaxStreamFieldSize {
+		return fmt.Errorf("package size %d exceeds the %d bytes limit", len(payload), MaxStreamFieldSize)
+	}
+
+	// Write as bundle packages field
+	raw := protowire.AppendTag(nil, bundlePackagesField, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, payload)
+	if _, err := w.dst.Write(raw); err != nil {
+		return fmt.Errorf("unable to write serialized package: %w", err)
+	}
+
+	// Update merkle tree
+	pushPackage(w.tree, w.stats, p)
+	w.lastName = p.Name
+
+	// No error
+	return nil
+}
+
+// Close writes trailing bundle fields and the merkle tree root. The underlying
+// writer is not closed.
+func (w *StreamWriter) Close() error {
+	// Check state
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	// Serialize trailing metadata
+	payload, err := proto.Marshal(&bundlev1.Bundle{
+		Template:       w.meta.Template,
+		Values:         w.meta.Values,
+		MerkleTreeRoot: w.tree.Root(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to encode bundle metadata: %w", err)
+	}
+	if _, err := w.dst.Write(payload); err != nil {
+		return fmt.Errorf("unable to write serialized bundle metadata: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,201 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/container"
+)
+
+func streamFixture() *bundlev1.Bundle {
+	return &bundlev1.Bundle{
+		Labels:      map[string]string{"env": "production"},
+		Annotations: map[string]string{"harp.elastic.co/v1/bundle#owner": "security"},
+		Version:     1,
+		Values:      wrapperspb.Bytes([]byte(`{"region":"eu"}`)),
+		Packages: []*bundlev1.Package{
+			{
+				Name: "app/production/db",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "password", Value: []byte("foo")}},
+				},
+			},
+			{
+				Name: "app/production/smtp",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "password", Value: []byte("bar")}},
+				},
+			},
+		},
+	}
+}
+
+func readAll(t *testing.T, r *StreamReader) ([]*bundlev1.Package, error) {
+	t.Helper()
+
+	pkgs := []*bundlev1.Package{}
+	for {
+		p, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return pkgs, nil
+		}
+		if err != nil {
+			return pkgs, err
+		}
+		pkgs = append(pkgs, p)
+	}
+}
+
+func Test_StreamWriter_Load(t *testing.T) {
+	expected := streamFixture()
+
+	// Write package by package
+	var buf bytes.Buffer
+	w, err := NewStreamWriter(&buf, expected)
+	assert.NoError(t, err)
+	for _, p := range expected.Packages {
+		assert.NoError(t, w.Add(p))
+	}
+	assert.NoError(t, w.Close())
+
+	// Closed writer
+	assert.Error(t, w.Add(expected.Packages[0]))
+	assert.NoError(t, w.Close())
+
+	// Must be readable by the bundle loader
+	got, err := Load(&buf)
+	assert.NoError(t, err)
+	tree, _, err := Tree(expected)
+	assert.NoError(t, err)
+	expected.MerkleTreeRoot = tree.Root()
+	assert.True(t, proto.Equal(expected, got))
+}
+
+func Test_StreamWriter_Order(t *testing.T) {
+	w, err := NewStreamWriter(io.Discard, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, w.Add(&bundlev1.Package{Name: "b"}))
+
+	err = w.Add(&bundlev1.Package{Name: "a"})
+	assert.True(t, errors.Is(err, ErrOutOfOrderPackage))
+
+	_, err = NewStreamWriter(nil, nil)
+	assert.Error(t, err)
+}
+
+func Test_StreamReader_Dump(t *testing.T) {
+	expected := streamFixture()
+
+	var buf bytes.Buffer
+	assert.NoError(t, Dump(&buf, expected))
+
+	r, err := NewStreamReader(&buf)
+	assert.NoError(t, err)
+
+	pkgs, err := readAll(t, r)
+	assert.NoError(t, err)
+	assert.Len(t, pkgs, 2)
+	assert.Equal(t, "app/production/db", pkgs[0].Name)
+	assert.Equal(t, "app/production/smtp", pkgs[1].Name)
+	assert.Equal(t, uint32(2), r.Statistic().SecretCount)
+
+	// Metadata is available
+	meta := r.Metadata()
+	assert.Equal(t, expected.Labels, meta.Labels)
+	assert.Equal(t, expected.Annotations, meta.Annotations)
+	assert.Equal(t, expected.Version, meta.Version)
+	assert.Equal(t, expected.Values.Value, meta.Values.Value)
+	assert.Empty(t, meta.Packages)
+
+	// Reader stays at end of stream
+	_, err = r.Next()
+	assert.True(t, errors.Is(err, io.EOF))
+
+	_, err = NewStreamReader(nil)
+	assert.Error(t, err)
+}
+
+func Test_StreamReader_Container(t *testing.T) {
+	f, err := os.Open("../../test/fixtures/bundles/complete.bundle")
+	assert.NoError(t, err)
+	defer f.Close()
+
+	c, err := container.Load(f)
+	assert.NoError(t, err)
+
+	expected, err := FromContainer(c)
+	assert.NoError(t, err)
+
+	r, err := NewContainerStreamReader(c)
+	assert.NoError(t, err)
+
+	pkgs, err := readAll(t, r)
+	assert.NoError(t, err)
+	assert.Len(t, pkgs, len(expected.Packages))
+
+	_, err = NewContainerStreamReader(nil)
+	assert.Error(t, err)
+}
+
+func Test_StreamReader_Corrupted(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, Dump(&buf, streamFixture()))
+	payload := buf.Bytes()
+
+	t.Run("altered secret", func(t *testing.T) {
+		altered := bytes.Replace(payload, []byte("foo"), []byte("baz"), 1)
+		r, err := NewStreamReader(bytes.NewReader(altered))
+		assert.NoError(t, err)
+
+		_, err = readAll(t, r)
+		assert.Error(t, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		r, err := NewStreamReader(bytes.NewReader(payload[:len(payload)-10]))
+		assert.NoError(t, err)
+
+		_, err = readAll(t, r)
+		assert.Error(t, err)
+	})
+
+	t.Run("out of order", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := NewStreamWriter(&buf, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Add(&bundlev1.Package{Name: "b"}))
+		w.lastName = ""
+		assert.NoError(t, w.Add(&bundlev1.Package{Name: "a"}))
+		assert.NoError(t, w.Close())
+
+		r, err := NewStreamReader(&buf)
+		assert.NoError(t, err)
+		_, err = readAll(t, r)
+		assert.True(t, errors.Is(err, ErrOutOfOrderPackage))
+	})
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vault
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/vault/api"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/vault/internal/operation"
+)
+
+// ImportResult describes a secret path write result.
+type ImportResult struct {
+	Path string
+	Err  error
+}
+
+// ImportReport holds all secret path write results of an import.
+type ImportReport struct {
+	Results []ImportResult
+}
+
+// Failed returns the failed secret path write results.
+func (r *ImportReport) Failed() []ImportResult {
+	out := []ImportResult{}
+	for _, res := range r.Results {
+		if res.Err != nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Err returns an error describing all failed secret paths, nil if all writes
+// succeeded.
+func (r *ImportReport) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, 0, len(failed))
+	for _, res := range failed {
+		msgs = append(msgs, res.Err.Error())
+	}
+
+	return fmt.Errorf("%d secret path(s) could not be written: %s", len(failed), strings.Join(msgs, "; "))
+}
+
+// Import writes the given bundle packages in Hashicorp Vault K/V backends.
+//
+// Contrary to Push, a failing secret path doesn't abort the import, all
+// results are reported in the returned report. Use WithCheckAndSet or
+// WithCreateOnly options to prevent concurrent writes from being overwritten.
+func Import(ctx context.Context, client *api.Client, b *bundlev1.Bundle, opts ...Option) (*ImportReport, error) {
+	// Check parameters
+	if client == nil {
+		return nil, fmt.Errorf("unable to process nil vault client")
+	}
+	if b == nil {
+		return nil, fmt.Errorf("unable to process nil bundle")
+	}
+
+	// Create default option instance
+	defaultOpts := &options{
+		prefix:      "",
+		exclusions:  []*regexp.Regexp{},
+		includes:    []*regexp.Regexp{},
+		workerCount: int64(4),
+	}
+
+	// Apply option functions
+	for _, o := range opts {
+		if err := o(defaultOpts); err != nil {
+			return nil, fmt.Errorf("unable to apply option: %w", err)
+		}
+	}
+
+	// Collect results
+	var (
+		report = &ImportReport{}
+		mutex  sync.Mutex
+	)
+	resultHandler := operation.WithResultHandler(func(secretPath string, err error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		report.Results = append(report.Results, ImportResult{Path: secretPath, Err: err})
+	})
+
+	// Run the push process
+	if err := runPush(ctx, b, client, defaultOpts, resultHandler); err != nil {
+		return nil, err
+	}
+
+	// Sort results by path
+	sort.SliceStable(report.Results, func(i, j int) bool {
+		return report.Results[i].Path < report.Results[j].Path
+	})
+
+	// No error
+	return report, nil
+}
@@ -0,0 +1,39 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vault
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportReport(t *testing.T) {
+	report := &ImportReport{}
+	assert.NoError(t, report.Err())
+	assert.Empty(t, report.Failed())
+
+	report.Results = []ImportResult{
+		{Path: "app/production/db"},
+		{Path: "app/production/cache", Err: errors.New("check-and-set version mismatch")},
+		{Path: "app/production/queue"},
+	}
+	assert.Equal(t, []ImportResult{report.Results[1]}, report.Failed())
+	assert.EqualError(t, report.Err(), "1 secret path(s) could not be written: check-and-set version mismatch")
+}
@@ -28,6 +28,7 @@ import (
 	"strings"
 
 	"github.com/imdario/mergo"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -35,18 +36,21 @@ import (
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/secret"
 	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/vault/kv"
 	vaultPath "github.com/elastic/harp/pkg/vault/path"
 )
 
-// Exporter initialize a secret exporter operation
-func Exporter(service kv.Service, backendPath string, output chan *bundlev1.Package, withMetadata bool, maxWorkerCount int64) Operation {
+// Exporter initialize a secret exporter operation. A non-zero version pins the
+// secret version to read when the path doesn't specify one.
+func Exporter(service kv.Service, backendPath string, output chan *bundlev1.Package, withMetadata bool, maxWorkerCount int64, version uint32) Operation {
 	return &exporter{
 		service:        service,
 		path:           backendPath,
 		withMetadata:   withMetadata,
 		output:         output,
 		maxWorkerCount: maxWorkerCount,
+		version:        version,
 	}
 }
 
@@ -58,6 +62,7 @@ type exporter struct {
 	withMetadata   bool
 	output         chan *bundlev1.Package
 	maxWorkerCount int64
+	version        uint32
 }
 
 // Run the implemented operation
@@ -115,9 +120,17 @@ func (op *exporter) Run(ctx context.Context) error {
 				if errPackagePath != nil {
 					return fmt.Errorf("unable to parse package path '%s': %w", secPath, errPackagePath)
 				}
+				if vaultVersion == 0 {
+					vaultVersion = op.version
+				}
 
 				// Read from Vault
-				secretData, secretMeta, errRead := op.service.ReadVersion(gReaderCtx, vaultPackagePath, vaultVersion)
+				readCtx, end := tracing.Start(gReaderCtx, "vault.kv.read",
+					attribute.String("harp.vault.path", vaultPackagePath),
+					attribute.Int64("harp.vault.version", int64(vaultVersion)),
+				)
+				secretData, secretMeta, errRead := op.service.ReadVersion(readCtx, vaultPackagePath, vaultVersion)
+				end(errRead, attribute.Int("harp.vault.keys", len(secretData)))
 				if errRead != nil {
 					// Mask path not found or empty secret value
 					if errors.Is(errRead, kv.ErrNoData) || errors.Is(errRead, kv.ErrPathNotFound) {
@@ -18,7 +18,17 @@
 package operation
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
 	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/vault/kv"
 )
 
 func Test_extractVersion(t *testing.T) {
@@ -85,3 +95,85 @@ func Test_extractVersion(t *testing.T) {
 		})
 	}
 }
+
+// -----------------------------------------------------------------------------
+
+type fakeKV struct {
+	sync.Mutex
+	keys     map[string][]string
+	secrets  map[string]kv.SecretData
+	versions map[string]uint32
+}
+
+func (s *fakeKV) List(_ context.Context, p string) ([]string, error) {
+	return s.keys[p], nil
+}
+
+func (s *fakeKV) Read(ctx context.Context, p string) (kv.SecretData, kv.SecretMetadata, error) {
+	return s.ReadVersion(ctx, p, 0)
+}
+
+func (s *fakeKV) ReadVersion(_ context.Context, p string, version uint32) (kv.SecretData, kv.SecretMetadata, error) {
+	s.Lock()
+	defer s.Unlock()
+	s.versions[p] = version
+
+	data, ok := s.secrets[p]
+	if !ok {
+		return nil, nil, kv.ErrPathNotFound
+	}
+	return data, kv.SecretMetadata{"version": json.Number("3")}, nil
+}
+
+func (s *fakeKV) Write(context.Context, string, kv.SecretData) error {
+	return errors.New("not implemented")
+}
+
+func (s *fakeKV) WriteWithMeta(context.Context, string, kv.SecretData, kv.SecretMetadata) error {
+	return errors.New("not implemented")
+}
+
+func Test_exporter_Run(t *testing.T) {
+	service := &fakeKV{
+		keys: map[string][]string{
+			"secrets":          {"app/", "root"},
+			"secrets/app":      {"prod/", "staging"},
+			"secrets/app/prod": {"db"},
+		},
+		secrets: map[string]kv.SecretData{
+			"secrets/root":         {"token": "root"},
+			"secrets/app/staging":  {"user": "staging"},
+			"secrets/app/prod/db":  {"user": "prod"},
+			"secrets/app/prod/old": {"user": "old"},
+		},
+		versions: map[string]uint32{},
+	}
+
+	output := make(chan *bundlev1.Package)
+	names := []string{}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for p := range output {
+			names = append(names, p.Name)
+			if p.Secrets.Version != 3 {
+				t.Errorf("unexpected secret version %d for '%s'", p.Secrets.Version, p.Name)
+			}
+		}
+	}()
+
+	err := Exporter(service, "secrets", output, false, 2, 2).Run(context.Background())
+	close(output)
+	<-done
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(names)
+	if diff := cmp.Diff([]string{"secrets/app/prod/db", "secrets/app/staging", "secrets/root"}, names); diff != "" {
+		t.Errorf("unexpected packages (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]uint32{"secrets/app/prod/db": 2, "secrets/app/staging": 2, "secrets/root": 2}, service.versions); diff != "" {
+		t.Errorf("unexpected versions (-want +got):\n%s", diff)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"sync"
 
 	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
@@ -32,13 +33,42 @@ import (
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/secret"
 	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/vault/kv"
 	vpath "github.com/elastic/harp/pkg/vault/path"
 )
 
+// ImporterOption defines importer operation optional settings.
+type ImporterOption func(*importer)
+
+// WithCheckAndSet enables check-and-set writes (K/V v2 only), the package
+// secret chain version is used as expected current secret version.
+func WithCheckAndSet(value bool) ImporterOption {
+	return func(op *importer) {
+		op.checkAndSet = value
+	}
+}
+
+// WithCreateOnly enables check-and-set writes with a zero version so that
+// existing secrets are never overwritten (K/V v2 only).
+func WithCreateOnly(value bool) ImporterOption {
+	return func(op *importer) {
+		op.createOnly = value
+	}
+}
+
+// WithResultHandler registers a function called for each written secret path
+// with the write error if any. When set, write errors don't abort the
+// operation.
+func WithResultHandler(value func(secretPath string, err error)) ImporterOption {
+	return func(op *importer) {
+		op.resultHandler = value
+	}
+}
+
 // Importer initialize a secret importer operation
-func Importer(client *api.Client, bundleFile *bundlev1.Bundle, prefix string, withMetadata, withVaultMetadata bool, maxWorkerCount int64) Operation {
-	return &importer{
+func Importer(client *api.Client, bundleFile *bundlev1.Bundle, prefix string, withMetadata, withVaultMetadata bool, maxWorkerCount int64, opts ...ImporterOption) Operation {
+	op := &importer{
 		client:            client,
 		bundle:            bundleFile,
 		prefix:            prefix,
@@ -47,6 +77,13 @@ func Importer(client *api.Client, bundleFile *bundlev1.Bundle, prefix string, wi
 		backends:          map[string]kv.Service{},
 		maxWorkerCount:    maxWorkerCount,
 	}
+
+	// Apply options
+	for _, o := range opts {
+		o(op)
+	}
+
+	return op
 }
 
 // -----------------------------------------------------------------------------
@@ -60,6 +97,9 @@ type importer struct {
 	backends          map[string]kv.Service
 	backendsMutex     sync.RWMutex
 	maxWorkerCount    int64
+	checkAndSet       bool
+	createOnly        bool
+	resultHandler     func(string, error)
 }
 
 // Run the implemented operation
@@ -123,7 +163,7 @@ func (op *importer) Run(ctx context.Context) error {
 					// Unpack secret to original value
 					var value interface{}
 					if err := secret.Unpack(s.Value, &value); err != nil {
-						return fmt.Errorf("unable to unpack secret value for path '%s' with key '%s': %w", secretPackage.Name, s.Key, err)
+						return op.done(secretPackage.Name, fmt.Errorf("unable to unpack secret value for path '%s' with key '%s': %w", secretPackage.Name, s.Key, err))
 					}
 
 					// Assign to map for vault storage
@@ -154,30 +194,15 @@ func (op *importer) Run(ctx context.Context) error {
 					secretPath = path.Join(op.prefix, secretPath)
 				}
 
-				// Extract root backend path
-				rootPath := strings.Split(vpath.SanitizePath(secretPath), "/")[0]
-
-				// Check backend initialization
-				if _, ok := op.backends[rootPath]; !ok {
-					// Initialize new service for backend
-					service, err := kv.New(op.client, rootPath, kv.WithVaultMetatadata(op.withVaultMetadata))
-					if err != nil {
-						return fmt.Errorf("unable to initialize Vault service for '%s' KV backend: %w", op.prefix, err)
-					}
-
-					// All queries will be handled by same backend service
-					op.backendsMutex.Lock()
-					op.backends[rootPath] = service
-					op.backendsMutex.Unlock()
-				}
-
 				// Write secret to Vault
-				if err := op.backends[rootPath].WriteWithMeta(gWriterCtx, secretPath, data, metadata); err != nil {
-					return fmt.Errorf("unable to write secret data for path '%s': %w", secretPath, err)
-				}
-
-				// No error
-				return nil
+				writeCtx, end := tracing.Start(gWriterCtx, "vault.kv.write",
+					attribute.String("harp.vault.path", secretPath),
+					attribute.Int("harp.vault.keys", len(data)),
+				)
+				errWrite := op.write(writeCtx, secretPath, data, metadata, secretPackage.Secrets.Version)
+				end(errWrite)
+
+				return op.done(secretPath, errWrite)
 			})
 		}
 
@@ -211,3 +236,62 @@ func (op *importer) Run(ctx context.Context) error {
 	// No error
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+
+// done reports the secret path processing result, the error is returned to
+// abort the operation only when no result handler is registered.
+func (op *importer) done(secretPath string, err error) error {
+	if op.resultHandler != nil {
+		op.resultHandler(secretPath, err)
+		return nil
+	}
+
+	return err
+}
+
+func (op *importer) write(ctx context.Context, secretPath string, data kv.SecretData, metadata kv.SecretMetadata, version uint32) error {
+	// Extract root backend path
+	rootPath := strings.Split(vpath.SanitizePath(secretPath), "/")[0]
+
+	// Check backend initialization
+	op.backendsMutex.RLock()
+	service, ok := op.backends[rootPath]
+	op.backendsMutex.RUnlock()
+	if !ok {
+		// Initialize new service for backend
+		var err error
+		service, err = kv.New(op.client, rootPath, kv.WithVaultMetatadata(op.withVaultMetadata))
+		if err != nil {
+			return fmt.Errorf("unable to initialize Vault service for '%s' KV backend: %w", op.prefix, err)
+		}
+
+		// All queries will be handled by same backend service
+		op.backendsMutex.Lock()
+		op.backends[rootPath] = service
+		op.backendsMutex.Unlock()
+	}
+
+	// Simple write
+	if !op.checkAndSet && !op.createOnly {
+		if err := service.WriteWithMeta(ctx, secretPath, data, metadata); err != nil {
+			return fmt.Errorf("unable to write secret data for path '%s': %w", secretPath, err)
+		}
+		return nil
+	}
+
+	// Check-and-set write
+	casWriter, ok := service.(kv.SecretCASWriter)
+	if !ok {
+		return fmt.Errorf("unable to write secret data for path '%s': check-and-set requires a K/V v2 backend", secretPath)
+	}
+	if op.createOnly {
+		version = 0
+	}
+	if err := casWriter.WriteCAS(ctx, secretPath, data, metadata, version); err != nil {
+		return fmt.Errorf("unable to write secret data for path '%s' (cas=%d): %w", secretPath, version, err)
+	}
+
+	// No error
+	return nil
+}
@@ -27,6 +27,9 @@ type options struct {
 	withSecretMetadata bool
 	withVaultMetadata  bool
 	workerCount        int64
+	version            uint32
+	checkAndSet        bool
+	createOnly         bool
 	exclusions         []*regexp.Regexp
 	includes           []*regexp.Regexp
 }
@@ -103,3 +106,33 @@ func WithMaxWorkerCount(value int64) Option {
 		return nil
 	}
 }
+
+// WithVersion pins the secret version to read during pull, paths specifying a
+// version have precedence. Latest version is read when zero.
+func WithVersion(value uint32) Option {
+	return func(opts *options) error {
+		opts.version = value
+		// No error
+		return nil
+	}
+}
+
+// WithCheckAndSet enables check-and-set writes during push (K/V v2 only), the
+// package secret version is used as the expected current secret version.
+func WithCheckAndSet(value bool) Option {
+	return func(opts *options) error {
+		opts.checkAndSet = value
+		// No error
+		return nil
+	}
+}
+
+// WithCreateOnly enables check-and-set writes with a zero version during push
+// so that existing secrets are never overwritten (K/V v2 only).
+func WithCreateOnly(value bool) Option {
+	return func(opts *options) error {
+		opts.createOnly = value
+		// No error
+		return nil
+	}
+}
@@ -23,14 +23,25 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/otel/attribute"
 	"golang.org/x/sync/errgroup"
 
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/vault/internal/operation"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/vault/kv"
 	vpath "github.com/elastic/harp/pkg/vault/path"
 )
 
+// Export walks the given Vault KV backend path recursively and returns all
+// secrets as a bundle, package names preserve the secret path hierarchy.
+//
+// The latest secret versions are read unless a version is pinned using
+// WithVersion option.
+func Export(ctx context.Context, client *api.Client, backendPath string, opts ...Option) (*bundlev1.Bundle, error) {
+	return Pull(ctx, client, []string{backendPath}, opts...)
+}
+
 // Pull all given path as a bundle.
 func Pull(ctx context.Context, client *api.Client, paths []string, opts ...Option) (*bundlev1.Bundle, error) {
 	// Check parameters
@@ -79,8 +90,11 @@ func Pull(ctx context.Context, client *api.Client, paths []string, opts ...Optio
 }
 
 // runPull starts a multithreaded Vault secret puller.
-func runPull(ctx context.Context, client *api.Client, paths []string, opts *options) (*bundlev1.Bundle, error) {
-	var res *bundlev1.Bundle
+func runPull(ctx context.Context, client *api.Client, paths []string, opts *options) (res *bundlev1.Bundle, err error) {
+	ctx, end := tracing.Start(ctx, "vault.pull", attribute.Int("harp.vault.paths", len(paths)))
+	defer func() {
+		end(err, attribute.Int("harp.bundle.packages", len(res.GetPackages())))
+	}()
 
 	// Initialize operation
 	packageChan := make(chan *bundlev1.Package)
@@ -130,7 +144,7 @@ func runPull(ctx context.Context, client *api.Client, paths []string, opts *opti
 				}
 
 				// Create an exporter
-				op := operation.Exporter(service, vpath.SanitizePath(p), packageChan, opts.withSecretMetadata, opts.workerCount)
+				op := operation.Exporter(service, vpath.SanitizePath(p), packageChan, opts.withSecretMetadata, opts.workerCount, opts.version)
 
 				// Run the job
 				if err := op.Run(gReaderctx); err != nil {
@@ -23,9 +23,11 @@ import (
 	"regexp"
 
 	"github.com/hashicorp/vault/api"
+	"go.opentelemetry.io/otel/attribute"
 
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle/vault/internal/operation"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 )
 
 // Push the given bundle in Hashicorp Vault.
@@ -69,7 +71,7 @@ func Push(ctx context.Context, b *bundlev1.Bundle, client *api.Client, opts ...O
 	return runPush(ctx, b, client, defaultOpts)
 }
 
-func runPush(ctx context.Context, b *bundlev1.Bundle, client *api.Client, opts *options) error {
+func runPush(ctx context.Context, b *bundlev1.Bundle, client *api.Client, opts *options, importerOpts ...operation.ImporterOption) (err error) {
 	// Prepare bundle
 	if len(opts.includes) > 0 {
 		filteredPackages := []*bundlev1.Package{}
@@ -91,7 +93,16 @@ func runPush(ctx context.Context, b *bundlev1.Bundle, client *api.Client, opts *
 	}
 
 	// Initialize operation
-	op := operation.Importer(client, b, opts.prefix, opts.withSecretMetadata, opts.withVaultMetadata, opts.workerCount)
+	importerOpts = append(importerOpts,
+		operation.WithCheckAndSet(opts.checkAndSet),
+		operation.WithCreateOnly(opts.createOnly),
+	)
+	op := operation.Importer(client, b, opts.prefix, opts.withSecretMetadata, opts.withVaultMetadata, opts.workerCount, importerOpts...)
+
+	ctx, end := tracing.Start(ctx, "vault.push", attribute.Int("harp.bundle.packages", len(b.Packages)))
+	defer func() {
+		end(err)
+	}()
 
 	// Run the vault operation
 	if err := op.Run(ctx); err != nil {
@@ -0,0 +1,271 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"google.golang.org/protobuf/proto"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle/secret"
+)
+
+// DefaultVersionRetention is the count of previous secret chain versions kept
+// by SetVersioned.
+const DefaultVersionRetention = 5
+
+// versionSeparator separates the secret key and the version in versioned key
+// references (`key@version`).
+const versionSeparator = "@"
+
+var (
+	// ErrVersionNotFound is raised when the requested secret version doesn't
+	// exist in the package history.
+	ErrVersionNotFound = errors.New("secret version not found")
+	// ErrKeyNotFound is raised when the secret key doesn't exist in the
+	// requested version.
+	ErrKeyNotFound = errors.New("secret key not found")
+)
+
+// VersionOption represents versioning option function.
+type VersionOption func(*versionOptions)
+
+type versionOptions struct {
+	retention int
+}
+
+// WithVersionRetention sets the count of previous versions kept in the
+// package history, older versions are removed.
+func WithVersionRetention(value int) VersionOption {
+	return func(opts *versionOptions) {
+		opts.retention = value
+	}
+}
+
+// SetVersioned assigns the given secret value and keeps the previous secret
+// chain in the package history so that it can be restored for rollback.
+//
+// The package gets a new secret chain version linked to the previous one,
+// packages without history are serialized as before.
+func SetVersioned(p *bundlev1.Package, key string, value interface{}, opts ...VersionOption) error {
+	// Check arguments
+	if p == nil {
+		return errors.New("unable to process nil package")
+	}
+	if key == "" {
+		return fmt.Errorf("invalid secret key '%s'", key)
+	}
+	if _, _, versioned, err := splitVersionRef(key); err != nil || versioned {
+		return fmt.Errorf("invalid secret key '%s', it must not end with a version suffix", key)
+	}
+	if p.Secrets != nil && p.Secrets.Locked != nil {
+		return fmt.Errorf("unable to update locked package '%s'", p.Name)
+	}
+
+	// Apply options
+	dopts := &versionOptions{
+		retention: DefaultVersionRetention,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+	if dopts.retention < 0 {
+		return fmt.Errorf("invalid version retention %d", dopts.retention)
+	}
+
+	// Pack secret value
+	packed, err := secret.Pack(value)
+	if err != nil {
+		return fmt.Errorf("unable to pack secret value for `%s`: %w", key, err)
+	}
+	kv := &bundlev1.KV{
+		Key:   key,
+		Type:  fmt.Sprintf("%T", value),
+		Value: packed,
+	}
+
+	// First version
+	if p.Secrets == nil {
+		p.Secrets = &bundlev1.SecretChain{
+			Data: []*bundlev1.KV{kv},
+		}
+		return nil
+	}
+
+	// Archive the current chain
+	current := p.Secrets
+	archived, ok := proto.Clone(current).(*bundlev1.SecretChain)
+	if !ok {
+		return fmt.Errorf("the cloned secret chain does not have a correct type: %T", archived)
+	}
+	if p.Versions == nil {
+		p.Versions = map[uint32]*bundlev1.SecretChain{}
+	}
+
+	// Prepare the next chain
+	next := archived.Version + 1
+	archived.NextVersion = &wrappers.UInt32Value{Value: next}
+	p.Versions[archived.Version] = archived
+
+	data := []*bundlev1.KV{}
+	for _, s := range current.Data {
+		if s.Key != key {
+			data = append(data, s)
+		}
+	}
+	current.Data = append(data, kv)
+	current.Version = next
+	current.PreviousVersion = &wrappers.UInt32Value{Value: archived.Version}
+	current.NextVersion = nil
+
+	// Apply retention policy
+	pruneVersions(p, dopts.retention)
+
+	// No error
+	return nil
+}
+
+// GetVersion returns the secret value of the given key in the given secret
+// chain version. The active chain is used when the version matches it.
+func GetVersion(p *bundlev1.Package, key string, version uint32) (interface{}, error) {
+	// Check arguments
+	if p == nil {
+		return nil, errors.New("unable to process nil package")
+	}
+
+	// Resolve the secret chain
+	var chain *bundlev1.SecretChain
+	switch {
+	case p.Secrets != nil && p.Secrets.Version == version:
+		chain = p.Secrets
+	case p.Versions[version] != nil:
+		chain = p.Versions[version]
+	default:
+		return nil, fmt.Errorf("unable to read '%s' version %d of '%s': %w", key, version, p.Name, ErrVersionNotFound)
+	}
+	if chain.Locked != nil {
+		return nil, fmt.Errorf("unable to read '%s' version %d of locked package '%s'", key, version, p.Name)
+	}
+
+	// Lookup secret key
+	for _, s := range chain.Data {
+		if s.Key != key {
+			continue
+		}
+
+		var out interface{}
+		if err := secret.Unpack(s.Value, &out); err != nil {
+			return nil, fmt.Errorf("unable to unpack '%s' secret value: %w", key, err)
+		}
+
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("unable to read '%s' version %d of '%s': %w", key, version, p.Name, ErrKeyNotFound)
+}
+
+// GetVersioned returns the secret value addressed with a `key@version`
+// reference, a key without version returns the active value.
+func GetVersioned(p *bundlev1.Package, ref string) (interface{}, error) {
+	// Check arguments
+	if p == nil {
+		return nil, errors.New("unable to process nil package")
+	}
+	if p.Secrets == nil {
+		return nil, fmt.Errorf("unable to read '%s' of '%s': %w", ref, p.Name, ErrKeyNotFound)
+	}
+
+	// Extract version
+	key, version, versioned, err := splitVersionRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if !versioned {
+		version = p.Secrets.Version
+	}
+
+	// Delegate to version reader
+	return GetVersion(p, key, version)
+}
+
+// -----------------------------------------------------------------------------
+
+// splitVersionRef splits a `key@version` reference. The suffix is a version
+// only when it is made of digits, so that keys such as `user@example.com` are
+// returned as is.
+func splitVersionRef(ref string) (key string, version uint32, versioned bool, err error) {
+	idx := strings.LastIndex(ref, versionSeparator)
+	if idx < 0 || !isDigits(ref[idx+1:]) {
+		return ref, 0, false, nil
+	}
+
+	// Parse version
+	v, err := strconv.ParseUint(ref[idx+1:], 10, 32)
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid secret version in '%s' reference: %w", ref, err)
+	}
+
+	// No error
+	return ref[:idx], uint32(v), true, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneVersions removes the oldest archived versions over the retention.
+func pruneVersions(p *bundlev1.Package, retention int) {
+	if len(p.Versions) <= retention {
+		return
+	}
+
+	versions := make([]uint32, 0, len(p.Versions))
+	for v := range p.Versions {
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i] < versions[j] })
+
+	removed := versions[:len(versions)-retention]
+	for _, v := range removed {
+		delete(p.Versions, v)
+	}
+
+	// Unlink the oldest kept version
+	if retention == 0 {
+		p.Secrets.PreviousVersion = nil
+		p.Versions = nil
+		return
+	}
+	if oldest, ok := p.Versions[versions[len(removed)]]; ok {
+		oldest.PreviousVersion = nil
+	}
+}
@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/value"
+)
+
+type prefixTransformer struct{}
+
+func (prefixTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	return append([]byte("locked:"), input...), nil
+}
+
+func (prefixTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	if !bytes.HasPrefix(input, []byte("locked:")) {
+		return nil, errors.New("not locked")
+	}
+	return bytes.TrimPrefix(input, []byte("locked:")), nil
+}
+
+func Test_SetVersioned(t *testing.T) {
+	p := &bundlev1.Package{Name: "app/production/db"}
+
+	assert.NoError(t, SetVersioned(p, "user", "admin"))
+	assert.NoError(t, SetVersioned(p, "password", "v1"))
+	assert.NoError(t, SetVersioned(p, "password", "v2"))
+	assert.NoError(t, SetVersioned(p, "password", "v3", WithVersionRetention(2)))
+
+	// Active chain
+	assert.Equal(t, uint32(3), p.Secrets.Version)
+	assert.Equal(t, uint32(2), p.Secrets.PreviousVersion.GetValue())
+	assert.Nil(t, p.Secrets.NextVersion)
+	v, err := GetVersioned(p, "password")
+	assert.NoError(t, err)
+	assert.Equal(t, "v3", v)
+	v, err = GetVersioned(p, "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", v)
+
+	// History
+	assert.Len(t, p.Versions, 2)
+	v, err = GetVersion(p, "password", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v)
+	v, err = GetVersioned(p, "password@1")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", v)
+	assert.Nil(t, p.Versions[1].PreviousVersion)
+	assert.Equal(t, uint32(2), p.Versions[1].NextVersion.GetValue())
+
+	// Pruned version
+	_, err = GetVersioned(p, "password@0")
+	assert.True(t, errors.Is(err, ErrVersionNotFound))
+
+	// Unknown key
+	_, err = GetVersion(p, "token", 1)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	// Invalid references
+	_, err = GetVersioned(p, "password@latest")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+	_, err = GetVersioned(p, "password@99999999999")
+	assert.Error(t, err)
+	assert.Error(t, SetVersioned(p, "password@2", "v4"))
+	assert.Error(t, SetVersioned(p, "password", "v4", WithVersionRetention(-1)))
+	assert.Error(t, SetVersioned(nil, "password", "v4"))
+}
+
+func Test_GetVersioned_KeyWithSeparator(t *testing.T) {
+	p := &bundlev1.Package{Name: "app/production/smtp"}
+
+	assert.NoError(t, SetVersioned(p, "user@example.com", "v1"))
+	assert.NoError(t, SetVersioned(p, "user@example.com", "v2"))
+
+	// Non numeric suffix is part of the key
+	v, err := GetVersioned(p, "user@example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v)
+
+	// Numeric suffix is a version
+	v, err = GetVersioned(p, "user@example.com@0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", v)
+
+	// Ambiguous keys are rejected
+	assert.Error(t, SetVersioned(p, "user@2", "v1"))
+}
+
+func Test_SetVersioned_NoRetention(t *testing.T) {
+	p := &bundlev1.Package{Name: "app/production/db"}
+
+	assert.NoError(t, SetVersioned(p, "password", "v1"))
+	assert.NoError(t, SetVersioned(p, "password", "v2", WithVersionRetention(0)))
+	assert.Nil(t, p.Versions)
+	assert.Nil(t, p.Secrets.PreviousVersion)
+}
+
+func Test_Versions_Serialization(t *testing.T) {
+	p := &bundlev1.Package{Name: "app/production/db"}
+	assert.NoError(t, SetVersioned(p, "password", "v1"))
+	b := &bundlev1.Bundle{Packages: []*bundlev1.Package{p}}
+
+	// Unversioned packages keep the same merkle tree root
+	before, _, err := Tree(b)
+	assert.NoError(t, err)
+	legacy := &bundlev1.Bundle{Packages: []*bundlev1.Package{{Name: "app/production/db", Secrets: p.Secrets}}}
+	legacyTree, _, err := Tree(legacy)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyTree.Root(), before.Root())
+
+	// History is protected by the merkle tree root
+	assert.NoError(t, SetVersioned(p, "password", "v2"))
+	after, _, err := Tree(b)
+	assert.NoError(t, err)
+	assert.NotEqual(t, before.Root(), after.Root())
+
+	var buf bytes.Buffer
+	assert.NoError(t, Dump(&buf, b))
+	loaded, err := Load(&buf)
+	assert.NoError(t, err)
+	v, err := GetVersioned(loaded.Packages[0], "password@0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", v)
+
+	// History is locked with the active chain
+	ctx := context.Background()
+	assert.NoError(t, Lock(ctx, loaded, prefixTransformer{}))
+	assert.Nil(t, loaded.Packages[0].Versions[0].Data)
+	assert.NotNil(t, loaded.Packages[0].Versions[0].Locked)
+	_, err = GetVersioned(loaded.Packages[0], "password@0")
+	assert.Error(t, err)
+
+	assert.NoError(t, UnLock(ctx, loaded, []value.Transformer{prefixTransformer{}}, false))
+	v, err = GetVersioned(loaded.Packages[0], "password@0")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", v)
+	v, err = GetVersioned(loaded.Packages[0], "password")
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", v)
+}
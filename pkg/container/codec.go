@@ -26,12 +26,14 @@ import (
 	"io"
 
 	"github.com/awnumar/memguard"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/nacl/box"
 	"golang.org/x/crypto/nacl/secretbox"
 	"google.golang.org/protobuf/proto"
 
 	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/log"
 	"github.com/elastic/harp/pkg/sdk/security/crypto/extra25519"
 	"github.com/elastic/harp/pkg/sdk/types"
 )
@@ -40,6 +42,10 @@ const (
 	containerMagic             = uint32(0x53CB3701)
 	containerVersion           = uint16(0x0002)
 	containerSealedContentType = "application/vnd.harp.v1.SealedContainer"
+	containerSealVersion       = uint32(3)
+	sealVersionInitial         = uint32(1)
+	sealVersionCompressed      = uint32(2)
+	sealVersionDetached        = uint32(3)
 	publicKeySize              = 32
 	privateKeySize             = 32
 	encryptionKeySize          = 32
@@ -69,7 +75,10 @@ func Load(r io.Reader) (*containerv1.Container, error) {
 		return nil, fmt.Errorf("unable to read container version: %w", err)
 	}
 
-	// Check magic value
+	// Check container version
+	if version > containerVersion {
+		return nil, ErrUnsupportedContainerVersion{Got: uint32(version), Max: uint32(containerVersion)}
+	}
 	if version != containerVersion {
 		return nil, fmt.Errorf("invalid container version %d", version)
 	}
@@ -133,8 +142,38 @@ func Dump(w io.Writer, c *containerv1.Container) error {
 }
 
 // Seal a secret container
-//nolint:funlen // To refactor
 func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*containerv1.Container, error) {
+	// Check parameters
+	if len(peersPublicKey) == 0 {
+		return nil, fmt.Errorf("unable to process empty public keys")
+	}
+
+	// Delegate to implementation
+	return seal(container, peersPublicKey, nil, nil, compression{})
+}
+
+// SealWithOptions seals a secret container for the recipients given by
+// WithRecipients. Use WithCompression to compress the content before
+// encryption.
+func SealWithOptions(container *containerv1.Container, opts ...SealOption) (*containerv1.Container, error) {
+	// Apply options
+	dopts := &sealOptions{}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Check parameters
+	if len(dopts.peersPublicKey) == 0 {
+		return nil, fmt.Errorf("unable to process empty public keys")
+	}
+
+	// Delegate to implementation
+	return seal(container, dopts.peersPublicKey, nil, nil, dopts.compression)
+}
+
+// Unseal a sealed container with the given identities. Each identity is tried
+// in order until one of them matches a container recipient.
+func Unseal(container *containerv1.Container, identities ...*memguard.LockedBuffer) (*containerv1.Container, error) {
 	// Check parameters
 	if types.IsNil(container) {
 		return nil, fmt.Errorf("unable to process nil container")
@@ -142,8 +181,74 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 	if types.IsNil(container.Headers) {
 		return nil, fmt.Errorf("unable to process nil container headers")
 	}
-	if len(peersPublicKey) == 0 {
-		return nil, fmt.Errorf("unable to process empty public keys")
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("unable to process without container key")
+	}
+	for _, identity := range identities {
+		if identity == nil {
+			return nil, fmt.Errorf("unable to process without container key")
+		}
+	}
+
+	// Check headers
+	if err := checkSealedHeaders(container.Headers); err != nil {
+		return nil, err
+	}
+
+	// Check ephemeral container public encryption key
+	if len(container.Headers.EncryptionPublicKey) != publicKeySize {
+		return nil, fmt.Errorf("invalid container public size")
+	}
+	var publicKey [publicKeySize]byte
+	copy(publicKey[:], container.Headers.EncryptionPublicKey[:publicKeySize])
+
+	// Try identities
+	payloadKey, err := tryIdentities(&publicKey, identities, container.Headers.Recipients)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unseal container: error occurred during recipient key tests: %w", err)
+	}
+
+	// Delegate to implementation
+	return unseal(container, payloadKey)
+}
+
+// -----------------------------------------------------------------------------
+
+func seal(container *containerv1.Container, peersPublicKey []*[32]byte, password *passwordRecipient, signerKeyID []byte, compress compression) (*containerv1.Container, error) {
+	// Check parameters
+	if types.IsNil(container) {
+		return nil, fmt.Errorf("unable to process nil container")
+	}
+	if types.IsNil(container.Headers) {
+		return nil, fmt.Errorf("unable to process nil container headers")
+	}
+
+	// Serialize protobuf payload
+	content, err := proto.Marshal(container)
+	if err != nil {
+		return container, fmt.Errorf("unable to encode container content: %w", err)
+	}
+	contentSize := len(content)
+
+	// Compress before encryption
+	contentEncoding := ""
+	if compress.enabled {
+		compressed, errCompress := compressContent(content, compress.level)
+		memguard.WipeBytes(content)
+		if errCompress != nil {
+			return nil, errCompress
+		}
+		content = compressed
+		contentEncoding = contentEncodingGzip
+	}
+
+	// Delegate to implementation
+	return sealContent(content, contentSize, contentEncoding, sealVersionDetached, peersPublicKey, password, signerKeyID)
+}
+
+func checkRecipients(peersPublicKey []*[32]byte, password *passwordRecipient) error {
+	if len(peersPublicKey) == 0 && password == nil {
+		return fmt.Errorf("unable to process without recipients")
 	}
 	for _, pub := range peersPublicKey {
 		if pub == nil {
@@ -153,19 +258,27 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 
 		k := *pub
 		if extra25519.IsEdLowOrder(k[:]) {
-			return nil, fmt.Errorf("unable to process with low order public key")
+			return fmt.Errorf("unable to process with low order public key")
 		}
 	}
 
-	// Serialize protobuf payload
-	content, err := proto.Marshal(container)
-	if err != nil {
-		return container, fmt.Errorf("unable to encode container content: %w", err)
+	// No error
+	return nil
+}
+
+// sealContent encrypts the given encoded content with the given format
+// version, contentSize is the content size before encoding.
+//
+//nolint:funlen // To refactor
+func sealContent(content []byte, contentSize int, contentEncoding string, sealVersion uint32, peersPublicKey []*[32]byte, password *passwordRecipient, signerKeyID []byte) (*containerv1.Container, error) {
+	// Check parameters
+	if err := checkRecipients(peersPublicKey, password); err != nil {
+		return nil, err
 	}
 
 	// Generate payload encryption key
 	var payloadKey [32]byte
-	if _, err = io.ReadFull(rand.Reader, payloadKey[:]); err != nil {
+	if _, err := io.ReadFull(rand.Reader, payloadKey[:]); err != nil {
 		return nil, fmt.Errorf("unable to generate payload key for encryption")
 	}
 
@@ -190,6 +303,9 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 	// Prepare sealed container
 	containerHeaders := &containerv1.Header{
 		ContentType:         containerSealedContentType,
+		ContentEncoding:     contentEncoding,
+		SealVersion:         sealVersion,
+		SignerKeyId:         signerKeyID,
 		EncryptionPublicKey: encPub[:],
 		ContainerBox:        encryptedPubSig,
 		Recipients:          []*containerv1.Recipient{},
@@ -205,13 +321,24 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 		// Pack recipient using its public key
 		r, errPack := packRecipient(&payloadKey, encPriv, peerPublicKey)
 		if errPack != nil {
-			return nil, fmt.Errorf("unable to pack container recipient (%X): %w", *peerPublicKey, err)
+			return nil, fmt.Errorf("unable to pack container recipient (%X): %w", *peerPublicKey, errPack)
 		}
 
 		// Append to container
 		containerHeaders.Recipients = append(containerHeaders.Recipients, r)
 	}
 
+	// Process password recipient
+	if password != nil {
+		r, errPack := packPasswordRecipient(&payloadKey, password)
+		if errPack != nil {
+			return nil, fmt.Errorf("unable to pack container password recipient: %w", errPack)
+		}
+
+		// Append to container
+		containerHeaders.PasswordRecipients = append(containerHeaders.PasswordRecipients, r)
+	}
+
 	// Compute header hash
 	headerHash, err := computeHeaderHash(containerHeaders)
 	if err != nil {
@@ -233,6 +360,15 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 	var sigNonce [24]byte
 	copy(sigNonce[:], headerHash[:24])
 
+	log.Bg().Debug("container sealed",
+		zap.Int("recipients", len(containerHeaders.Recipients)),
+		zap.Bool("password", password != nil),
+		zap.Bool("signed", len(signerKeyID) > 0),
+		zap.String("content_encoding", contentEncoding),
+		zap.Int("content_size", contentSize),
+		zap.Int("encoded_content_size", len(content)),
+	)
+
 	// No error
 	return &containerv1.Container{
 		Headers: containerHeaders,
@@ -240,49 +376,9 @@ func Seal(container *containerv1.Container, peersPublicKey ...*[32]byte) (*conta
 	}, nil
 }
 
-// Unseal a sealed container with the given identity
-//nolint:funlen,gocyclo // To refactor
-func Unseal(container *containerv1.Container, identity *memguard.LockedBuffer) (*containerv1.Container, error) {
-	// Check parameters
-	if types.IsNil(container) {
-		return nil, fmt.Errorf("unable to process nil container")
-	}
-	if types.IsNil(container.Headers) {
-		return nil, fmt.Errorf("unable to process nil container headers")
-	}
-	if identity == nil {
-		return nil, fmt.Errorf("unable to process without container key")
-	}
-
-	// Check headers
-	if container.Headers.ContentType != containerSealedContentType {
-		return nil, fmt.Errorf("unable to unseal container")
-	}
-
-	// Check ephemeral container public encryption key
-	if len(container.Headers.EncryptionPublicKey) != publicKeySize {
-		return nil, fmt.Errorf("invalid container public size")
-	}
-	var publicKey [publicKeySize]byte
-	copy(publicKey[:], container.Headers.EncryptionPublicKey[:publicKeySize])
-
-	// Check identity private encryption key
-	privRaw := identity.Bytes()
-	if len(privRaw) != privateKeySize {
-		return nil, fmt.Errorf("invalid identity private key length")
-	}
-	var pk [privateKeySize]byte
-	copy(pk[:], privRaw[:privateKeySize])
-
-	// Precompute identifier
-	derivedKey := deriveSharedKeyFromRecipient(&publicKey, &pk)
-
-	// Try recipients
-	payloadKey, err := tryRecipientKeys(&derivedKey, container.Headers.Recipients)
-	if err != nil {
-		return nil, fmt.Errorf("unable to unseal container: error occurred during recipient key tests: %w", err)
-	}
-
+// openContent decrypts the container payload and returns the encoded content
+// once its signature has been verified.
+func openContent(container *containerv1.Container, payloadKey []byte) ([]byte, error) {
 	// Check private key
 	if len(payloadKey) != encryptionKeySize {
 		return nil, fmt.Errorf("unable to unseal container: invalid encryption key size")
@@ -336,12 +432,35 @@ func Unseal(container *containerv1.Container, identity *memguard.LockedBuffer) (
 		return nil, fmt.Errorf("invalid container signature")
 	}
 
+	// No error
+	return content, nil
+}
+
+func unseal(container *containerv1.Container, payloadKey []byte) (*containerv1.Container, error) {
+	// Decrypt content
+	content, err := openContent(container, payloadKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode content, the encoding is authenticated by the header hash
+	decoded, err := decodeContent(container.Headers.ContentEncoding, content)
+	if err != nil {
+		return nil, err
+	}
+
 	// Unmarshal inner container
 	out := &containerv1.Container{}
-	if err := proto.Unmarshal(content, out); err != nil {
+	if err := proto.Unmarshal(decoded, out); err != nil {
 		return nil, fmt.Errorf("unable to unpack inner content: %w", err)
 	}
 
+	log.Bg().Debug("container unsealed",
+		zap.String("content_encoding", container.Headers.ContentEncoding),
+		zap.Int("content_size", len(decoded)),
+		zap.Int("encoded_content_size", len(content)),
+	)
+
 	// No error
 	return out, nil
 }
@@ -297,6 +297,57 @@ func Test_Seal_Unseal(t *testing.T) {
 	}
 }
 
+func Test_Seal_Unseal_MultipleRecipients(t *testing.T) {
+	publicKey1, privateKey1, err := box.GenerateKey(bytes.NewReader([]byte("0003-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	publicKey2, privateKey2, err := box.GenerateKey(bytes.NewReader([]byte("0004-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	_, privateKey3, err := box.GenerateKey(bytes.NewReader([]byte("0005-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x00},
+	}
+
+	sealed, err := Seal(input, publicKey1, publicKey2)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	if len(sealed.Headers.Recipients) != 2 {
+		t.Fatalf("expected 2 recipients, got %d", len(sealed.Headers.Recipients))
+	}
+
+	// Each recipient can unseal the container
+	for _, pk := range []*[32]byte{privateKey1, privateKey2} {
+		unsealed, err := Unseal(sealed, memguard.NewBufferFromBytes(append([]byte{}, pk[:]...)))
+		if err != nil {
+			t.Fatalf("unable to unseal container: %v", err)
+		}
+		if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+			t.Errorf("Seal/Unseal()\n-got/+want\ndiff %s", diff)
+		}
+	}
+
+	// Identities are tried until one matches
+	if _, err := Unseal(sealed, memguard.NewBufferFromBytes(append([]byte{}, privateKey3[:]...)), memguard.NewBufferFromBytes(append([]byte{}, privateKey2[:]...))); err != nil {
+		t.Fatalf("unable to unseal container: %v", err)
+	}
+
+	// Unknown identity
+	if _, err := Unseal(sealed, memguard.NewBufferFromBytes(append([]byte{}, privateKey3[:]...))); err == nil {
+		t.Fatal("expected error with non recipient identity")
+	}
+}
+
 // -----------------------------------------------------------------------------
 
 func Test_Load_Fuzz(t *testing.T) {
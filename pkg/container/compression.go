@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/nacl/secretbox"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+)
+
+const (
+	// MaxUncompressedContentSize is the maximum content size accepted when
+	// decompressing a sealed container payload.
+	MaxUncompressedContentSize = 512 << 20
+
+	contentEncodingGzip   = "gzip"
+	sealedPayloadOverhead = ed25519.SignatureSize + secretbox.Overhead
+)
+
+// ErrContentTooLarge is raised when the decompressed sealed container payload
+// exceeds MaxUncompressedContentSize.
+var ErrContentTooLarge = errors.New("container content exceeds the maximum uncompressed size")
+
+// uncompressedContentLimit is overridden by tests.
+var uncompressedContentLimit = MaxUncompressedContentSize
+
+// SealedContentSize returns the encoded content size of the given sealed
+// container, the payload signature and authentication tag are excluded.
+func SealedContentSize(c *containerv1.Container) int {
+	if c == nil || len(c.Raw) < sealedPayloadOverhead {
+		return 0
+	}
+	return len(c.Raw) - sealedPayloadOverhead
+}
+
+// compression describes the sealed content compression settings.
+type compression struct {
+	enabled bool
+	level   int
+}
+
+func compressContent(content []byte, level int) ([]byte, error) {
+	var buf bytes.Buffer
+
+	// Prepare compression writer
+	zw, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize compression writer: %w", err)
+	}
+
+	// Compress content
+	if _, err := zw.Write(content); err != nil {
+		return nil, fmt.Errorf("unable to compress content: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("unable to close compression writer: %w", err)
+	}
+
+	// No error
+	return buf.Bytes(), nil
+}
+
+func decodeContent(encoding string, content []byte) ([]byte, error) {
+	switch encoding {
+	case "":
+		return content, nil
+	case contentEncodingGzip:
+	default:
+		return nil, fmt.Errorf("unsupported container content encoding '%s'", encoding)
+	}
+
+	// Prepare decompression reader
+	zr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize decompression reader: %w", err)
+	}
+
+	// Decompress content, the decompressed size is capped to prevent
+	// decompression bombs.
+	out, err := io.ReadAll(io.LimitReader(zr, int64(uncompressedContentLimit)+1))
+	if err != nil {
+		memguard.WipeBytes(out)
+		return nil, fmt.Errorf("unable to decompress content: %w", err)
+	}
+	if len(out) > uncompressedContentLimit {
+		memguard.WipeBytes(out)
+		return nil, ErrContentTooLarge
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/nacl/box"
+	"google.golang.org/protobuf/proto"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+)
+
+func Test_SealWithOptions_Compression(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: bytes.Repeat([]byte("harp"), 4096),
+	}
+
+	// Recipients are required
+	if _, err := SealWithOptions(input, WithCompression(gzip.BestCompression)); err == nil {
+		t.Fatal("error should be raised without recipients")
+	}
+
+	plain, err := SealWithOptions(input, WithRecipients(publicKey))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	compressed, err := SealWithOptions(input, WithRecipients(publicKey), WithCompression(gzip.BestCompression))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+
+	// Check headers
+	if plain.Headers.ContentEncoding != "" || plain.Headers.SealVersion != sealVersionDetached {
+		t.Errorf("uncompressed container headers are invalid, got %q / %d", plain.Headers.ContentEncoding, plain.Headers.SealVersion)
+	}
+	if compressed.Headers.ContentEncoding != "gzip" || compressed.Headers.SealVersion != sealVersionDetached {
+		t.Errorf("compressed container headers are invalid, got %q / %d", compressed.Headers.ContentEncoding, compressed.Headers.SealVersion)
+	}
+	if SealedContentSize(plain) != proto.Size(input) {
+		t.Errorf("invalid sealed content size, got %d, expected %d", SealedContentSize(plain), proto.Size(input))
+	}
+	if len(compressed.Raw) >= len(plain.Raw) {
+		t.Errorf("compressed payload should be smaller, got %d >= %d", len(compressed.Raw), len(plain.Raw))
+	}
+
+	// Unseal decompresses transparently
+	unsealed, err := Unseal(compressed, memguard.NewBufferFromBytes(append([]byte{}, privateKey[:]...)))
+	if err != nil {
+		t.Fatalf("unable to unseal container: %v", err)
+	}
+	if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+		t.Errorf("SealWithOptions/Unseal()\n-got/+want\ndiff %s", diff)
+	}
+
+	// Identification reports the encoding
+	var in, out bytes.Buffer
+	if err := Dump(&in, compressed); err != nil {
+		t.Fatalf("unable to dump container: %v", err)
+	}
+	id, err := Identify(bytes.NewReader(in.Bytes()))
+	if err != nil {
+		t.Fatalf("unable to identify container: %v", err)
+	}
+	if id.ContentEncoding != "gzip" || id.PayloadSize != len(compressed.Raw) {
+		t.Errorf("invalid identification, got %q / %d", id.ContentEncoding, id.PayloadSize)
+	}
+
+	// Rewrap keeps the compression
+	if err := Rewrap(&in, &out, memguard.NewBufferFromBytes(append([]byte{}, privateKey[:]...)), publicKey); err != nil {
+		t.Fatalf("unable to rewrap container: %v", err)
+	}
+	rewrapped, err := Load(&out)
+	if err != nil {
+		t.Fatalf("unable to load container: %v", err)
+	}
+	if rewrapped.Headers.ContentEncoding != "gzip" {
+		t.Errorf("rewrapped container must be compressed")
+	}
+}
+
+func Test_Unseal_ForgedContentEncoding(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sealed, err := Seal(&containerv1.Container{Headers: &containerv1.Header{}, Raw: []byte("foo")}, publicKey)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+
+	// Content encoding is authenticated
+	sealed.Headers.ContentEncoding = "gzip"
+	if _, err := Unseal(sealed, memguard.NewBufferFromBytes(append([]byte{}, privateKey[:]...))); err == nil {
+		t.Fatal("error should be raised with forged headers")
+	}
+}
+
+func Test_Unseal_DecompressionLimit(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	sealed, err := SealWithOptions(&containerv1.Container{
+		Headers: &containerv1.Header{},
+		Raw:     make([]byte, 1<<20),
+	}, WithRecipients(publicKey), WithCompression(gzip.BestCompression))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+
+	// Lower the limit
+	defer func(limit int) { uncompressedContentLimit = limit }(uncompressedContentLimit)
+	uncompressedContentLimit = 1 << 16
+
+	_, err = Unseal(sealed, memguard.NewBufferFromBytes(append([]byte{}, privateKey[:]...)))
+	if !errors.Is(err, ErrContentTooLarge) {
+		t.Fatalf("expected ErrContentTooLarge, got %v", err)
+	}
+}
+
+func Test_decodeContent(t *testing.T) {
+	if _, err := decodeContent("br", []byte("foo")); err == nil {
+		t.Error("error should be raised for unsupported encoding")
+	}
+	if _, err := decodeContent("gzip", []byte("foo")); err == nil {
+		t.Error("error should be raised for invalid compressed content")
+	}
+	out, err := decodeContent("", []byte("foo"))
+	if err != nil || string(out) != "foo" {
+		t.Errorf("identity encoding should be a passthrough, got %q / %v", out, err)
+	}
+}
@@ -0,0 +1,33 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import "fmt"
+
+// ErrUnsupportedContainerVersion is raised when the container has been
+// produced by a more recent harp version using an unknown format.
+type ErrUnsupportedContainerVersion struct {
+	// Got is the container format version.
+	Got uint32
+	// Max is the most recent format version supported by this build.
+	Max uint32
+}
+
+func (e ErrUnsupportedContainerVersion) Error() string {
+	return fmt.Sprintf("unsupported container version %d (maximum supported version is %d), please upgrade harp", e.Got, e.Max)
+}
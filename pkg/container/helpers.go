@@ -31,6 +31,7 @@ import (
 
 	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
 	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/mac"
 )
 
 func packRecipient(payloadKey, ephPrivKey, peerPublicKey *[32]byte) (*containerv1.Recipient, error) {
@@ -70,12 +71,39 @@ func packRecipient(payloadKey, ephPrivKey, peerPublicKey *[32]byte) (*containerv
 	return recipient, nil
 }
 
+func checkSealedHeaders(headers *containerv1.Header) error {
+	// Check content type
+	if headers.ContentType != containerSealedContentType {
+		return fmt.Errorf("unable to unseal container")
+	}
+
+	// Check sealed container format version
+	if headers.SealVersion > containerSealVersion {
+		return ErrUnsupportedContainerVersion{Got: headers.SealVersion, Max: containerSealVersion}
+	}
+
+	// No error
+	return nil
+}
+
 func computeHeaderHash(headers *containerv1.Header) ([]byte, error) {
 	// Check arguments
 	if headers == nil {
 		return nil, errors.New("unable process with nil headers")
 	}
 
+	// Public key recipients are detached from the header hash so that they
+	// can be rewrapped without encrypting the payload again.
+	if headers.SealVersion >= sealVersionDetached {
+		detached, ok := proto.Clone(headers).(*containerv1.Header)
+		if !ok {
+			return nil, errors.New("unable to copy container headers")
+		}
+		detached.EncryptionPublicKey = nil
+		detached.Recipients = nil
+		headers = detached
+	}
+
 	// Prepare signature
 	header, err := proto.Marshal(headers)
 	if err != nil {
@@ -109,16 +137,13 @@ func deriveSharedKeyFromRecipient(publicKey, privateKey *[32]byte) [32]byte {
 
 func keyIdentifierFromDerivedKey(derivedKey *[32]byte) ([]byte, error) {
 	// Hash the derived key
-	h, err := blake2b.New512([]byte("harp signcryption box key identifier"))
+	h, err := mac.Blake2bMAC([]byte("harp signcryption box key identifier"), derivedKey[:], mac.Blake2bMaxSize)
 	if err != nil {
-		return nil, fmt.Errorf("unable to generate recipient identifier hasher")
-	}
-	if _, err := h.Write(derivedKey[:]); err != nil {
-		return nil, fmt.Errorf("unable to generate recipient identifier")
+		return nil, fmt.Errorf("unable to generate recipient identifier: %w", err)
 	}
 
 	// Return 32 bytes trucanted hash.
-	return h.Sum(nil)[0:32], nil
+	return h[0:32], nil
 }
 
 func tryRecipientKeys(derivedKey *[32]byte, recipients []*containerv1.Recipient) ([]byte, error) {
@@ -151,3 +176,35 @@ func tryRecipientKeys(derivedKey *[32]byte, recipients []*containerv1.Recipient)
 	// No recipient found in list.
 	return nil, fmt.Errorf("no recipient found")
 }
+
+func tryIdentities(publicKey *[32]byte, identities []*memguard.LockedBuffer, recipients []*containerv1.Recipient) ([]byte, error) {
+	var lastErr error
+
+	for _, identity := range identities {
+		// Check identity private encryption key
+		privRaw := identity.Bytes()
+		if len(privRaw) != privateKeySize {
+			return nil, fmt.Errorf("invalid identity private key length")
+		}
+		var pk [privateKeySize]byte
+		copy(pk[:], privRaw[:privateKeySize])
+
+		// Precompute identifier
+		derivedKey := deriveSharedKeyFromRecipient(publicKey, &pk)
+		memguard.WipeBytes(pk[:])
+
+		// Try recipients
+		payloadKey, err := tryRecipientKeys(&derivedKey, recipients)
+		memguard.WipeBytes(derivedKey[:])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		// Encryption key found, return no error.
+		return payloadKey, nil
+	}
+
+	// No identity matched.
+	return nil, lastErr
+}
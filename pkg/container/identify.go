@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SealMode describes how a container can be unsealed.
+type SealMode uint8
+
+const (
+	// SealModeNone is used for unsealed containers.
+	SealModeNone SealMode = iota
+	// SealModeIdentity is used for containers unsealable with an identity key.
+	SealModeIdentity
+	// SealModePassword is used for containers unsealable with a password.
+	SealModePassword
+	// SealModeIdentityAndPassword is used for containers unsealable with an
+	// identity key or a password.
+	SealModeIdentityAndPassword
+)
+
+// String returns the seal mode name.
+func (m SealMode) String() string {
+	switch m {
+	case SealModeNone:
+		return "none"
+	case SealModeIdentity:
+		return "identity"
+	case SealModePassword:
+		return "password"
+	case SealModeIdentityAndPassword:
+		return "identity+password"
+	default:
+		return "unknown"
+	}
+}
+
+// Identification describes container format attributes.
+type Identification struct {
+	// Version is the container envelope format version.
+	Version uint32
+	// SealVersion is the sealed container format version.
+	SealVersion uint32
+	// SealMode describes how the container can be unsealed.
+	SealMode SealMode
+	// ContentType is the container payload content type.
+	ContentType string
+	// ContentEncoding is the sealed content encoding, blank when the content
+	// is not compressed.
+	ContentEncoding string
+	// PayloadSize is the encrypted payload size.
+	PayloadSize int
+	// Recipients is the identity recipient count.
+	Recipients int
+	// PasswordRecipients is the password recipient count.
+	PasswordRecipients int
+}
+
+// Identify reads the given container and reports its format attributes
+// without unsealing it. Containers produced with an unsupported format version
+// return the partial identification with an ErrUnsupportedContainerVersion
+// error.
+func Identify(r io.Reader) (*Identification, error) {
+	// Load container
+	c, err := Load(r)
+	if err != nil {
+		var errVersion ErrUnsupportedContainerVersion
+		if errors.As(err, &errVersion) {
+			return &Identification{Version: errVersion.Got}, err
+		}
+		return nil, fmt.Errorf("unable to load container: %w", err)
+	}
+
+	// Prepare identification
+	id := &Identification{
+		Version:     uint32(containerVersion),
+		ContentType: c.Headers.ContentType,
+	}
+
+	// Unsealed container
+	if c.Headers.ContentType != containerSealedContentType {
+		return id, nil
+	}
+
+	// Seal attributes
+	id.SealVersion = c.Headers.SealVersion
+	id.ContentEncoding = c.Headers.ContentEncoding
+	id.PayloadSize = len(c.Raw)
+	id.Recipients = len(c.Headers.Recipients)
+	id.PasswordRecipients = len(c.Headers.PasswordRecipients)
+	switch {
+	case id.Recipients > 0 && id.PasswordRecipients > 0:
+		id.SealMode = SealModeIdentityAndPassword
+	case id.PasswordRecipients > 0:
+		id.SealMode = SealModePassword
+	default:
+		id.SealMode = SealModeIdentity
+	}
+
+	// Check sealed container format version
+	if id.SealVersion > containerSealVersion {
+		return id, ErrUnsupportedContainerVersion{Got: id.SealVersion, Max: containerSealVersion}
+	}
+
+	// No error
+	return id, nil
+}
@@ -0,0 +1,138 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/nacl/box"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+)
+
+func TestIdentify(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(bytes.NewReader([]byte("identify-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x00},
+	}
+	identitySealed, err := Seal(input, publicKey)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	passwordSealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")), WithPasswordKDF(1, 64, 1))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	mixedSealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")), WithPasswordKDF(1, 64, 1), WithRecipients(publicKey))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		container *containerv1.Container
+		want      SealMode
+	}{
+		{name: "unsealed", container: input, want: SealModeNone},
+		{name: "identity", container: identitySealed, want: SealModeIdentity},
+		{name: "password", container: passwordSealed, want: SealModePassword},
+		{name: "identity and password", container: mixedSealed, want: SealModeIdentityAndPassword},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Dump(&buf, testCase.container); err != nil {
+				t.Fatalf("unable to dump container: %v", err)
+			}
+
+			id, err := Identify(&buf)
+			if err != nil {
+				t.Fatalf("unable to identify container: %v", err)
+			}
+			if id.SealMode != testCase.want {
+				t.Errorf("expected %s seal mode, got %s", testCase.want, id.SealMode)
+			}
+			if id.Version != uint32(containerVersion) {
+				t.Errorf("expected version %d, got %d", containerVersion, id.Version)
+			}
+		})
+	}
+
+	// Unseal still works with the detected mode
+	if _, err := Unseal(identitySealed, memguard.NewBufferFromBytes(privateKey[:])); err != nil {
+		t.Fatalf("unable to unseal container: %v", err)
+	}
+}
+
+func TestUnsupportedContainerVersion(t *testing.T) {
+	// Envelope produced by a newer version
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, containerMagic); err != nil {
+		t.Fatalf("%v", err)
+	}
+	if err := binary.Write(&buf, binary.BigEndian, containerVersion+1); err != nil {
+		t.Fatalf("%v", err)
+	}
+	buf.Write([]byte{0x00, 0x01})
+
+	id, err := Identify(bytes.NewReader(buf.Bytes()))
+	var errVersion ErrUnsupportedContainerVersion
+	if !errors.As(err, &errVersion) {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+	if errVersion.Got != uint32(containerVersion)+1 || errVersion.Max != uint32(containerVersion) {
+		t.Errorf("unexpected error content: %v", errVersion)
+	}
+	if id == nil || id.Version != uint32(containerVersion)+1 {
+		t.Errorf("expected partial identification, got %v", id)
+	}
+	if _, err := Load(bytes.NewReader(buf.Bytes())); !errors.As(err, &errVersion) {
+		t.Errorf("expected unsupported version error, got %v", err)
+	}
+
+	// Sealed container produced by a newer version
+	publicKey, privateKey, err := box.GenerateKey(bytes.NewReader([]byte("version-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	sealed, err := Seal(&containerv1.Container{Headers: &containerv1.Header{}, Raw: []byte{0x00}}, publicKey)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	sealed.Headers.SealVersion = containerSealVersion + 1
+
+	_, err = Unseal(sealed, memguard.NewBufferFromBytes(privateKey[:]))
+	if !errors.As(err, &errVersion) {
+		t.Fatalf("expected unsupported version error, got %v", err)
+	}
+	if errVersion.Got != containerSealVersion+1 || errVersion.Max != containerSealVersion {
+		t.Errorf("unexpected error content: %v", errVersion)
+	}
+}
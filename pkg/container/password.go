@@ -0,0 +1,279 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/nacl/secretbox"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/kdf"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+const (
+	passwordSaltSize  = 16
+	maxPasswordMemory = kdf.MaxMemory
+)
+
+// SealOption represents functional pattern builder for sealing optional
+// parameters.
+type SealOption func(o *sealOptions)
+
+type sealOptions struct {
+	peersPublicKey []*[32]byte
+	kdf            string
+	kdfParams      kdf.Parameters
+	randomSource   io.Reader
+	compression    compression
+}
+
+// WithRecipients adds public key recipients to the sealed container. Password
+// sealed containers can be unsealed using the password or any of the
+// identities.
+func WithRecipients(peersPublicKey ...*[32]byte) SealOption {
+	return func(o *sealOptions) {
+		o.peersPublicKey = append(o.peersPublicKey, peersPublicKey...)
+	}
+}
+
+// WithCompression compresses the container content with gzip at the given
+// level before encryption, the encoding is recorded in the authenticated
+// container headers so that it is transparently decompressed when unsealed.
+func WithCompression(level int) SealOption {
+	return func(o *sealOptions) {
+		o.compression = compression{enabled: true, level: level}
+	}
+}
+
+// WithPasswordKDF overrides the Argon2id password derivation parameters, the
+// memory cost is expressed in KiB.
+func WithPasswordKDF(iterations, memory uint32, parallelism uint8) SealOption {
+	return func(o *sealOptions) {
+		o.kdf = kdf.Argon2id
+		o.kdfParams = kdf.Parameters{
+			Iterations:  iterations,
+			Memory:      memory,
+			Parallelism: uint32(parallelism),
+		}
+	}
+}
+
+// WithPasswordKDFAlgorithm selects the password derivation function by its
+// registry name (argon2id, scrypt, pbkdf2-sha256), zero parameters select the
+// algorithm defaults.
+func WithPasswordKDFAlgorithm(name string, params kdf.Parameters) SealOption {
+	return func(o *sealOptions) {
+		o.kdf = name
+		o.kdfParams = params
+	}
+}
+
+// WithPasswordSaltSource provides the random source used to generate the
+// password derivation salt.
+func WithPasswordSaltSource(random io.Reader) SealOption {
+	return func(o *sealOptions) {
+		o.randomSource = random
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// SealWithPassword seals a secret container with a payload key wrapped by a
+// password derived key. The derivation algorithm and its parameters are
+// stored in the container headers, Argon2id is used by default.
+func SealWithPassword(container *containerv1.Container, password *memguard.LockedBuffer, opts ...SealOption) (*containerv1.Container, error) {
+	// Check parameters
+	if password == nil || password.Size() == 0 {
+		return nil, errors.New("unable to process blank password")
+	}
+
+	// Prepare defaults
+	dopts := &sealOptions{
+		kdf:          kdf.Argon2id,
+		randomSource: rand.Reader,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Resolve KDF
+	alg, err := kdf.Get(dopts.kdf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve password kdf: %w", err)
+	}
+	params := dopts.kdfParams
+	if params == (kdf.Parameters{}) {
+		params = alg.DefaultParameters()
+	}
+
+	// Check KDF parameters
+	if err := alg.Validate(params); err != nil {
+		return nil, fmt.Errorf("invalid password kdf parameters: %w", err)
+	}
+
+	// Generate salt
+	salt := make([]byte, passwordSaltSize)
+	if _, err := io.ReadFull(dopts.randomSource, salt); err != nil {
+		return nil, fmt.Errorf("unable to generate password salt: %w", err)
+	}
+
+	// Delegate to implementation
+	return seal(container, dopts.peersPublicKey, &passwordRecipient{
+		password: password,
+		salt:     salt,
+		kdf:      alg,
+		params:   params,
+	}, nil, dopts.compression)
+}
+
+// UnsealWithPassword unseals a password sealed container.
+func UnsealWithPassword(container *containerv1.Container, password *memguard.LockedBuffer) (*containerv1.Container, error) {
+	// Check parameters
+	if types.IsNil(container) {
+		return nil, fmt.Errorf("unable to process nil container")
+	}
+	if types.IsNil(container.Headers) {
+		return nil, fmt.Errorf("unable to process nil container headers")
+	}
+	if password == nil || password.Size() == 0 {
+		return nil, errors.New("unable to process blank password")
+	}
+
+	// Check headers
+	if err := checkSealedHeaders(container.Headers); err != nil {
+		return nil, err
+	}
+
+	// Try password recipients
+	payloadKey, err := tryPasswordRecipients(password, container.Headers.PasswordRecipients)
+	if err != nil {
+		return nil, fmt.Errorf("unable to unseal container: error occurred during password recipient tests: %w", err)
+	}
+
+	// Delegate to implementation
+	return unseal(container, payloadKey)
+}
+
+// -----------------------------------------------------------------------------
+
+type passwordRecipient struct {
+	password *memguard.LockedBuffer
+	salt     []byte
+	kdf      kdf.Algorithm
+	params   kdf.Parameters
+}
+
+func derivePasswordKey(password *memguard.LockedBuffer, salt []byte, alg kdf.Algorithm, params kdf.Parameters) (*[32]byte, error) {
+	var key [32]byte
+
+	// Derive key encryption key
+	dk, err := alg.Derive(password.Bytes(), salt, params, encryptionKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive password key: %w", err)
+	}
+	copy(key[:], dk)
+	memguard.WipeBytes(dk)
+
+	return &key, nil
+}
+
+func packPasswordRecipient(payloadKey *[32]byte, p *passwordRecipient) (*containerv1.PasswordRecipient, error) {
+	// Check arguments
+	if payloadKey == nil {
+		return nil, fmt.Errorf("unable to proceed with nil payload key")
+	}
+	if p == nil {
+		return nil, fmt.Errorf("unable to proceed with nil password recipient")
+	}
+
+	// Derive key encryption key
+	recipientKey, err := derivePasswordKey(p.password, p.salt, p.kdf, p.params)
+	if err != nil {
+		return nil, err
+	}
+	defer memguard.WipeBytes(recipientKey[:])
+
+	// Generate recipient nonce
+	var recipientNonce [24]byte
+	if _, err := io.ReadFull(rand.Reader, recipientNonce[:]); err != nil {
+		return nil, fmt.Errorf("unable to generate recipient nonce for encryption")
+	}
+
+	// Return recipient
+	return &containerv1.PasswordRecipient{
+		Kdf:         p.kdf.Name(),
+		Salt:        p.salt,
+		Iterations:  p.params.Iterations,
+		Memory:      p.params.Memory,
+		Parallelism: p.params.Parallelism,
+		Key:         secretbox.Seal(recipientNonce[:], payloadKey[:], &recipientNonce, recipientKey),
+	}, nil
+}
+
+func tryPasswordRecipients(password *memguard.LockedBuffer, recipients []*containerv1.PasswordRecipient) ([]byte, error) {
+	for _, r := range recipients {
+		// Ignore nil and invalid recipients
+		if r == nil || len(r.Key) < 24 {
+			continue
+		}
+
+		// Ignore unsupported recipients
+		alg, err := kdf.Get(r.Kdf)
+		if err != nil {
+			continue
+		}
+
+		// Check KDF parameters before derivation
+		params := kdf.Parameters{
+			Iterations:  r.Iterations,
+			Memory:      r.Memory,
+			Parallelism: r.Parallelism,
+		}
+		if err := alg.Validate(params); err != nil {
+			return nil, fmt.Errorf("invalid password kdf parameters: %w", err)
+		}
+
+		// Derive key encryption key
+		recipientKey, err := derivePasswordKey(password, r.Salt, alg, params)
+		if err != nil {
+			return nil, err
+		}
+
+		var nonce [24]byte
+		copy(nonce[:], r.Key[:24])
+
+		// Try to decrypt the secretbox with the derived key.
+		payloadKey, isValid := secretbox.Open(nil, r.Key[24:], &nonce, recipientKey)
+		memguard.WipeBytes(recipientKey[:])
+		if !isValid {
+			continue
+		}
+
+		// Encryption key found, return no error.
+		return payloadKey, nil
+	}
+
+	// No recipient found in list.
+	return nil, fmt.Errorf("no password recipient found")
+}
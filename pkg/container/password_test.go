@@ -0,0 +1,180 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/nacl/box"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/kdf"
+)
+
+func Test_SealWithPassword_Unseal(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(bytes.NewReader([]byte("password-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x00},
+	}
+
+	sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+		WithRecipients(publicKey),
+		WithPasswordKDF(1, 64, 1),
+	)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	if len(sealed.Headers.PasswordRecipients) != 1 {
+		t.Fatalf("expected 1 password recipient, got %d", len(sealed.Headers.PasswordRecipients))
+	}
+	if r := sealed.Headers.PasswordRecipients[0]; r.Kdf != "argon2id" || r.Iterations != 1 || r.Memory != 64 || r.Parallelism != 1 || len(r.Salt) != passwordSaltSize {
+		t.Fatalf("unexpected password recipient parameters: %v", r)
+	}
+
+	// Unseal with password
+	unsealed, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("correct horse battery staple")))
+	if err != nil {
+		t.Fatalf("unable to unseal container with password: %v", err)
+	}
+	if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+		t.Errorf("SealWithPassword/UnsealWithPassword()\n-got/+want\ndiff %s", diff)
+	}
+
+	// Unseal with identity
+	unsealed, err = Unseal(sealed, memguard.NewBufferFromBytes(privateKey[:]))
+	if err != nil {
+		t.Fatalf("unable to unseal container with identity: %v", err)
+	}
+	if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+		t.Errorf("SealWithPassword/Unseal()\n-got/+want\ndiff %s", diff)
+	}
+
+	// Invalid password
+	if _, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("wrong password"))); err == nil {
+		t.Fatal("expected error with invalid password")
+	}
+}
+
+func Test_SealWithPassword_KDFAlgorithm(t *testing.T) {
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x00},
+	}
+
+	testCases := []struct {
+		name   string
+		alg    string
+		params kdf.Parameters
+	}{
+		{name: "scrypt", alg: kdf.Scrypt, params: kdf.Parameters{Iterations: 1024, Memory: 128, Parallelism: 1}},
+		{name: "pbkdf2-sha256", alg: kdf.PBKDF2SHA256, params: kdf.Parameters{Iterations: 1000}},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+				WithPasswordKDFAlgorithm(testCase.alg, testCase.params),
+			)
+			if err != nil {
+				t.Fatalf("unable to seal container: %v", err)
+			}
+			if r := sealed.Headers.PasswordRecipients[0]; r.Kdf != testCase.alg || r.Iterations != testCase.params.Iterations || r.Memory != testCase.params.Memory || r.Parallelism != testCase.params.Parallelism {
+				t.Fatalf("unexpected password recipient parameters: %v", r)
+			}
+
+			// Unseal with password
+			unsealed, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("correct horse battery staple")))
+			if err != nil {
+				t.Fatalf("unable to unseal container with password: %v", err)
+			}
+			if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+				t.Errorf("SealWithPassword/UnsealWithPassword()\n-got/+want\ndiff %s", diff)
+			}
+
+			// Unsupported algorithm recipients are ignored
+			sealed.Headers.PasswordRecipients[0].Kdf = "bcrypt"
+			if _, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("correct horse battery staple"))); err == nil {
+				t.Error("expected error with unsupported kdf")
+			}
+		})
+	}
+
+	// Forged scrypt parameters are rejected before derivation
+	sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")),
+		WithPasswordKDFAlgorithm(kdf.Scrypt, kdf.Parameters{Iterations: 1024, Memory: 128, Parallelism: 1}),
+	)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	r := sealed.Headers.PasswordRecipients[0]
+	r.Iterations, r.Memory, r.Parallelism = 2, kdf.MaxMemory, 16
+	if _, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("password"))); err == nil {
+		t.Error("expected error with forged scrypt parameters")
+	}
+
+	// Unknown algorithm
+	if _, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")), WithPasswordKDFAlgorithm("bcrypt", kdf.Parameters{})); err == nil {
+		t.Error("expected error with unknown kdf")
+	}
+}
+
+func Test_SealWithPassword_Invalid(t *testing.T) {
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{},
+		Raw:     []byte{0x00, 0x00},
+	}
+
+	// Blank password
+	if _, err := SealWithPassword(input, memguard.NewBuffer(0)); err == nil {
+		t.Error("expected error with blank password")
+	}
+
+	// Invalid KDF parameters
+	for _, opt := range []SealOption{
+		WithPasswordKDF(0, 64, 1),
+		WithPasswordKDF(1, 4, 1),
+		WithPasswordKDF(1, maxPasswordMemory+1, 1),
+		WithPasswordKDF(1, 64, 0),
+	} {
+		if _, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")), opt); err == nil {
+			t.Error("expected error with invalid kdf parameters")
+		}
+	}
+
+	// Tampered KDF parameters are rejected before derivation
+	sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("password")), WithPasswordKDF(1, 64, 1))
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	sealed.Headers.PasswordRecipients[0].Memory = maxPasswordMemory + 1
+	if _, err := UnsealWithPassword(sealed, memguard.NewBufferFromBytes([]byte("password"))); err == nil {
+		t.Error("expected error with tampered kdf parameters")
+	}
+}
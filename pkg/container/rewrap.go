@@ -0,0 +1,186 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+	"google.golang.org/protobuf/proto"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/extra25519"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// ErrRewrapUnsupported is raised when a container sealed with a previous
+// format, which binds the payload encryption to the recipient list, has
+// password recipients that can't be carried over without the password.
+var ErrRewrapUnsupported = errors.New("container format doesn't support rewrapping password recipients, it must be unsealed and sealed again")
+
+// Rewrap reads a sealed container, decrypts its payload key with the given
+// identity and writes a container where the payload key is wrapped for the
+// new recipient set.
+//
+// The encrypted payload is copied unchanged, so the content encoding, the
+// password recipients and the signer key identifier are preserved. A removed
+// recipient which kept the payload key is still able to decrypt the payload,
+// and detached signatures of the previous container must be issued again.
+//
+// Containers sealed with a previous format are upgraded, their encoded
+// content is encrypted again with a fresh payload key in memory.
+func Rewrap(r io.Reader, w io.Writer, identity *memguard.LockedBuffer, peersPublicKey ...*[32]byte) error {
+	// Check parameters
+	if types.IsNil(r) {
+		return fmt.Errorf("unable to process nil reader")
+	}
+	if types.IsNil(w) {
+		return fmt.Errorf("unable to process nil writer")
+	}
+	if identity == nil {
+		return fmt.Errorf("unable to process without container key")
+	}
+	if len(peersPublicKey) == 0 {
+		return fmt.Errorf("unable to process empty public keys")
+	}
+
+	// Load sealed container
+	in, err := Load(r)
+	if err != nil {
+		return fmt.Errorf("unable to load sealed container: %w", err)
+	}
+
+	// Check headers
+	if err := checkSealedHeaders(in.Headers); err != nil {
+		return err
+	}
+	if in.Headers.SealVersion < sealVersionDetached && len(in.Headers.PasswordRecipients) > 0 {
+		return ErrRewrapUnsupported
+	}
+
+	// Check ephemeral container public encryption key
+	if len(in.Headers.EncryptionPublicKey) != publicKeySize {
+		return fmt.Errorf("invalid container public size")
+	}
+	var publicKey [publicKeySize]byte
+	copy(publicKey[:], in.Headers.EncryptionPublicKey[:publicKeySize])
+
+	// Decrypt payload key
+	payloadKeyRaw, err := tryIdentities(&publicKey, []*memguard.LockedBuffer{identity}, in.Headers.Recipients)
+	if err != nil {
+		return fmt.Errorf("unable to unseal container: error occurred during recipient key tests: %w", err)
+	}
+	defer memguard.WipeBytes(payloadKeyRaw)
+	if len(payloadKeyRaw) != encryptionKeySize {
+		return fmt.Errorf("unable to unseal container: invalid encryption key size")
+	}
+	var payloadKey [encryptionKeySize]byte
+	copy(payloadKey[:], payloadKeyRaw)
+	defer memguard.WipeBytes(payloadKey[:])
+
+	// Upgrade previous formats
+	if in.Headers.SealVersion < sealVersionDetached {
+		return reseal(w, in, payloadKeyRaw, peersPublicKey)
+	}
+
+	// Check payload key with the encrypted signing public key
+	var pubSigNonce [24]byte
+	copy(pubSigNonce[:], "harp_container_psigk_box")
+	if _, ok := secretbox.Open(nil, in.Headers.ContainerBox, &pubSigNonce, &payloadKey); !ok {
+		return fmt.Errorf("invalid container key")
+	}
+
+	// Generate ephemeral encryption key
+	encPub, encPriv, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("unable to generate ephemeral encryption keypair")
+	}
+	defer memguard.WipeBytes(encPriv[:])
+
+	// Prepare headers
+	headers, ok := proto.Clone(in.Headers).(*containerv1.Header)
+	if !ok {
+		return fmt.Errorf("unable to copy container headers")
+	}
+	headers.EncryptionPublicKey = encPub[:]
+	headers.Recipients = []*containerv1.Recipient{}
+
+	// Process recipients
+	for _, peerPublicKey := range peersPublicKey {
+		// Ignore nil key
+		if peerPublicKey == nil {
+			continue
+		}
+		if extra25519.IsEdLowOrder(peerPublicKey[:]) {
+			return fmt.Errorf("unable to process with low order public key")
+		}
+
+		// Pack recipient using its public key
+		r, errPack := packRecipient(&payloadKey, encPriv, peerPublicKey)
+		if errPack != nil {
+			return fmt.Errorf("unable to pack container recipient (%X): %w", *peerPublicKey, errPack)
+		}
+
+		// Append to container
+		headers.Recipients = append(headers.Recipients, r)
+	}
+	if len(headers.Recipients) == 0 {
+		return fmt.Errorf("unable to process without recipients")
+	}
+
+	// Write sealed container
+	if err := Dump(w, &containerv1.Container{
+		Headers: headers,
+		Raw:     in.Raw,
+	}); err != nil {
+		return fmt.Errorf("unable to write sealed container: %w", err)
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func reseal(w io.Writer, in *containerv1.Container, payloadKey []byte, peersPublicKey []*[32]byte) error {
+	// Decrypt encoded content
+	content, err := openContent(in, payloadKey)
+	if err != nil {
+		return fmt.Errorf("unable to unseal container: %w", err)
+	}
+	defer memguard.WipeBytes(content)
+
+	// Encrypt the encoded content for new recipients
+	out, err := sealContent(content, len(content), in.Headers.ContentEncoding, sealVersionDetached, peersPublicKey, nil, in.Headers.SignerKeyId)
+	if err != nil {
+		return fmt.Errorf("unable to seal container: %w", err)
+	}
+
+	// Write sealed container
+	if err := Dump(w, out); err != nil {
+		return fmt.Errorf("unable to write sealed container: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,269 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/nacl/box"
+	"google.golang.org/protobuf/proto"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+)
+
+func TestRewrap(t *testing.T) {
+	oldPublicKey, oldPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-old-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	newPublicKey, newPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-new-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x01},
+	}
+	sealed, err := Seal(input, oldPublicKey)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	var in bytes.Buffer
+	if err := Dump(&in, sealed); err != nil {
+		t.Fatalf("unable to dump container: %v", err)
+	}
+
+	// Rewrap to new recipient
+	var out bytes.Buffer
+	if err := Rewrap(bytes.NewReader(in.Bytes()), &out, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...)), newPublicKey); err != nil {
+		t.Fatalf("unable to rewrap container: %v", err)
+	}
+	rewrapped, err := Load(&out)
+	if err != nil {
+		t.Fatalf("unable to load rewrapped container: %v", err)
+	}
+
+	// Encrypted payload is copied unchanged
+	if !bytes.Equal(rewrapped.Raw, sealed.Raw) {
+		t.Error("rewrapped payload must not be encrypted again")
+	}
+
+	// Old identity is not a recipient anymore
+	if _, err := Unseal(rewrapped, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...))); err == nil {
+		t.Error("expected error with previous identity")
+	}
+
+	// New identity unseals the original content
+	unsealed, err := Unseal(rewrapped, memguard.NewBufferFromBytes(append([]byte{}, newPrivateKey[:]...)))
+	if err != nil {
+		t.Fatalf("unable to unseal rewrapped container: %v", err)
+	}
+	if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+		t.Errorf("Rewrap()\n-got/+want\ndiff %s", diff)
+	}
+
+	// Invalid identity
+	if err := Rewrap(bytes.NewReader(in.Bytes()), &out, memguard.NewBufferFromBytes(append([]byte{}, newPrivateKey[:]...)), newPublicKey); err == nil {
+		t.Error("expected error with non recipient identity")
+	}
+
+	// No recipients
+	if err := Rewrap(bytes.NewReader(in.Bytes()), &out, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...))); err == nil {
+		t.Error("expected error without recipients")
+	}
+}
+
+func TestRewrap_PreserveHeaders(t *testing.T) {
+	oldPublicKey, oldPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-old-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	newPublicKey, newPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-new-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: bytes.Repeat([]byte("harp"), 1024),
+	}
+
+	rewrap := func(t *testing.T, in []byte) *containerv1.Container {
+		t.Helper()
+
+		var out bytes.Buffer
+		if err := Rewrap(bytes.NewReader(in), &out, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...)), newPublicKey); err != nil {
+			t.Fatalf("unable to rewrap container: %v", err)
+		}
+		rewrapped, err := Load(&out)
+		if err != nil {
+			t.Fatalf("unable to load rewrapped container: %v", err)
+		}
+
+		return rewrapped
+	}
+
+	t.Run("password and compression", func(t *testing.T) {
+		sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+			WithRecipients(oldPublicKey),
+			WithPasswordKDF(1, 64, 1),
+			WithCompression(gzip.BestSpeed),
+		)
+		if err != nil {
+			t.Fatalf("unable to seal container: %v", err)
+		}
+		var in bytes.Buffer
+		if err := Dump(&in, sealed); err != nil {
+			t.Fatalf("unable to dump container: %v", err)
+		}
+
+		rewrapped := rewrap(t, in.Bytes())
+		if rewrapped.Headers.ContentEncoding != contentEncodingGzip {
+			t.Errorf("content encoding must be preserved, got %q", rewrapped.Headers.ContentEncoding)
+		}
+		if !bytes.Equal(rewrapped.Raw, sealed.Raw) {
+			t.Error("rewrapped payload must not be encrypted again")
+		}
+
+		// Password recipient is still valid
+		unsealed, err := UnsealWithPassword(rewrapped, memguard.NewBufferFromBytes([]byte("correct horse battery staple")))
+		if err != nil {
+			t.Fatalf("unable to unseal rewrapped container with password: %v", err)
+		}
+		if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+			t.Errorf("Rewrap()\n-got/+want\ndiff %s", diff)
+		}
+
+		// New identity
+		unsealed, err = Unseal(rewrapped, memguard.NewBufferFromBytes(append([]byte{}, newPrivateKey[:]...)))
+		if err != nil {
+			t.Fatalf("unable to unseal rewrapped container: %v", err)
+		}
+		if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+			t.Errorf("Rewrap()\n-got/+want\ndiff %s", diff)
+		}
+	})
+
+	t.Run("signer key identifier", func(t *testing.T) {
+		signerPub, signerPriv, err := ed25519.GenerateKey(bytes.NewReader(bytes.Repeat([]byte{0x01}, 32)))
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+		var in bytes.Buffer
+		if _, err := SealAndSign(&in, input, signerPriv, oldPublicKey); err != nil {
+			t.Fatalf("unable to seal container: %v", err)
+		}
+		kid, err := SignerKeyID(signerPub)
+		if err != nil {
+			t.Fatalf("%v", err)
+		}
+
+		rewrapped := rewrap(t, in.Bytes())
+		if !bytes.Equal(rewrapped.Headers.SignerKeyId, kid) {
+			t.Error("signer key identifier must be preserved")
+		}
+		if _, err := Unseal(rewrapped, memguard.NewBufferFromBytes(append([]byte{}, newPrivateKey[:]...))); err != nil {
+			t.Fatalf("unable to unseal rewrapped container: %v", err)
+		}
+	})
+}
+
+func TestRewrap_LegacyFormat(t *testing.T) {
+	oldPublicKey, oldPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-old-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	newPublicKey, newPrivateKey, err := box.GenerateKey(bytes.NewReader([]byte("rewrap-new-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x01},
+	}
+	content, err := proto.Marshal(input)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	for _, version := range []uint32{0, sealVersionInitial, sealVersionCompressed} {
+		sealed, err := sealContent(content, len(content), "", version, []*[32]byte{oldPublicKey}, nil, []byte("signer"))
+		if err != nil {
+			t.Fatalf("unable to seal container: %v", err)
+		}
+		var in bytes.Buffer
+		if err := Dump(&in, sealed); err != nil {
+			t.Fatalf("unable to dump container: %v", err)
+		}
+
+		// Container is upgraded
+		var out bytes.Buffer
+		if err := Rewrap(&in, &out, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...)), newPublicKey); err != nil {
+			t.Fatalf("unable to rewrap container: %v", err)
+		}
+		rewrapped, err := Load(&out)
+		if err != nil {
+			t.Fatalf("unable to load rewrapped container: %v", err)
+		}
+		if rewrapped.Headers.SealVersion != sealVersionDetached {
+			t.Errorf("expected upgraded format, got %d", rewrapped.Headers.SealVersion)
+		}
+		if !bytes.Equal(rewrapped.Headers.SignerKeyId, []byte("signer")) {
+			t.Error("signer key identifier must be preserved")
+		}
+		unsealed, err := Unseal(rewrapped, memguard.NewBufferFromBytes(append([]byte{}, newPrivateKey[:]...)))
+		if err != nil {
+			t.Fatalf("unable to unseal rewrapped container: %v", err)
+		}
+		if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+			t.Errorf("Rewrap()\n-got/+want\ndiff %s", diff)
+		}
+	}
+
+	// Password recipients can't be carried over
+	sealed, err := SealWithPassword(input, memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+		WithRecipients(oldPublicKey),
+		WithPasswordKDF(1, 64, 1),
+	)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	sealed.Headers.SealVersion = sealVersionCompressed
+	var in bytes.Buffer
+	if err := Dump(&in, sealed); err != nil {
+		t.Fatalf("unable to dump container: %v", err)
+	}
+	err = Rewrap(&in, &bytes.Buffer{}, memguard.NewBufferFromBytes(append([]byte{}, oldPrivateKey[:]...)), newPublicKey)
+	if !errors.Is(err, ErrRewrapUnsupported) {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blake2b"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/mac"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+var (
+	// ErrSignerMismatch is raised when the container signer key identifier
+	// doesn't match the verification public key.
+	ErrSignerMismatch = errors.New("container signer key identifier mismatch")
+	// ErrInvalidContainerSignature is raised when the detached signature
+	// verification fails.
+	ErrInvalidContainerSignature = errors.New("invalid container detached signature")
+)
+
+// SignerKeyID returns the signer public key identifier stored in the sealed
+// container headers.
+func SignerKeyID(publicKey ed25519.PublicKey) ([]byte, error) {
+	// Check parameters
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid signer public key length")
+	}
+
+	// Hash the public key
+	h, err := mac.Blake2bMAC([]byte("harp container signer key identifier"), publicKey, mac.Blake2bMaxSize)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate signer identifier: %w", err)
+	}
+
+	// Return 32 bytes truncated hash.
+	return h[0:32], nil
+}
+
+// SealAndSign seals the given container for the given recipients, writes the
+// sealed container to the given writer and returns a detached Ed25519
+// signature of the written bytes.
+//
+// The signer public key identifier is stored in the sealed container headers
+// so that the signature can be verified without decrypting the container.
+func SealAndSign(w io.Writer, container *containerv1.Container, signer ed25519.PrivateKey, peersPublicKey ...*[32]byte) ([]byte, error) {
+	// Check parameters
+	if types.IsNil(w) {
+		return nil, fmt.Errorf("unable to process nil writer")
+	}
+	if len(signer) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid signer private key length")
+	}
+	if len(peersPublicKey) == 0 {
+		return nil, fmt.Errorf("unable to process empty public keys")
+	}
+
+	// Compute signer identifier
+	pub, ok := signer.Public().(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unable to extract signer public key")
+	}
+	kid, err := SignerKeyID(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Seal the container
+	sealed, err := seal(container, peersPublicKey, nil, kid, compression{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Serialize sealed container
+	var buf bytes.Buffer
+	if err := Dump(&buf, sealed); err != nil {
+		return nil, fmt.Errorf("unable to serialize sealed container: %w", err)
+	}
+
+	// Sign the serialized container
+	sig := ed25519.Sign(signer, detachedSignatureProtected(buf.Bytes()))
+
+	// Write sealed container
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return nil, fmt.Errorf("unable to write sealed container: %w", err)
+	}
+
+	// No error
+	return sig, nil
+}
+
+// VerifyContainerSignature verifies the detached signature of a serialized
+// sealed container and returns the loaded container. The container is not
+// unsealed.
+func VerifyContainerSignature(r io.Reader, signature []byte, publicKey ed25519.PublicKey) (*containerv1.Container, error) {
+	// Check parameters
+	if types.IsNil(r) {
+		return nil, fmt.Errorf("unable to process nil reader")
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return nil, ErrInvalidContainerSignature
+	}
+
+	// Compute expected signer identifier
+	kid, err := SignerKeyID(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Drain input reader
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read container: %w", err)
+	}
+
+	// Validate signature
+	if !ed25519.Verify(publicKey, detachedSignatureProtected(raw), signature) {
+		return nil, ErrInvalidContainerSignature
+	}
+
+	// Load container
+	c, err := Load(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load container: %w", err)
+	}
+
+	// Check signer identifier
+	if !security.SecureCompare(kid, c.Headers.SignerKeyId) {
+		return nil, ErrSignerMismatch
+	}
+
+	// No error
+	return c, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func detachedSignatureProtected(raw []byte) []byte {
+	// Prepare protected content
+	protected := bytes.Buffer{}
+	protected.Write([]byte("harp container detached signature"))
+	protected.WriteByte(0x00)
+	contentHash := blake2b.Sum512(raw)
+	protected.Write(contentHash[:])
+
+	return protected.Bytes()
+}
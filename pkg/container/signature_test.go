@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/awnumar/memguard"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/nacl/box"
+
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
+)
+
+func TestSealAndSign(t *testing.T) {
+	publicKey, privateKey, err := box.GenerateKey(bytes.NewReader([]byte("signature-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	signerPub, signerPriv, err := ed25519.GenerateKey(bytes.NewReader([]byte("signer-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	otherPub, _, err := ed25519.GenerateKey(bytes.NewReader([]byte("other-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	input := &containerv1.Container{
+		Headers: &containerv1.Header{
+			ContentType: "application/vnd.harp.v1.Bundle",
+		},
+		Raw: []byte{0x00, 0x01},
+	}
+
+	var out bytes.Buffer
+	sig, err := SealAndSign(&out, input, signerPriv, publicKey)
+	if err != nil {
+		t.Fatalf("unable to seal and sign container: %v", err)
+	}
+
+	// Verify without decryption
+	sealed, err := VerifyContainerSignature(bytes.NewReader(out.Bytes()), sig, signerPub)
+	if err != nil {
+		t.Fatalf("unable to verify container signature: %v", err)
+	}
+	kid, err := SignerKeyID(signerPub)
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	if !bytes.Equal(kid, sealed.Headers.SignerKeyId) {
+		t.Errorf("unexpected signer key identifier")
+	}
+
+	// Decryption is independent
+	unsealed, err := Unseal(sealed, memguard.NewBufferFromBytes(privateKey[:]))
+	if err != nil {
+		t.Fatalf("unable to unseal container: %v", err)
+	}
+	if diff := cmp.Diff(unsealed, input, ignoreOpts...); diff != "" {
+		t.Errorf("SealAndSign/Unseal()\n-got/+want\ndiff %s", diff)
+	}
+
+	// Wrong public key
+	if _, err := VerifyContainerSignature(bytes.NewReader(out.Bytes()), sig, otherPub); !errors.Is(err, ErrInvalidContainerSignature) {
+		t.Errorf("expected invalid signature error, got %v", err)
+	}
+
+	// Tampered content
+	tampered := append([]byte{}, out.Bytes()...)
+	tampered[len(tampered)-1] ^= 0xFF
+	if _, err := VerifyContainerSignature(bytes.NewReader(tampered), sig, signerPub); !errors.Is(err, ErrInvalidContainerSignature) {
+		t.Errorf("expected invalid signature error, got %v", err)
+	}
+
+	// Truncated signature
+	if _, err := VerifyContainerSignature(bytes.NewReader(out.Bytes()), sig[:10], signerPub); !errors.Is(err, ErrInvalidContainerSignature) {
+		t.Errorf("expected invalid signature error, got %v", err)
+	}
+}
+
+func TestVerifyContainerSignature_SignerMismatch(t *testing.T) {
+	publicKey, _, err := box.GenerateKey(bytes.NewReader([]byte("mismatch-deterministic-generation-for-tests")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	signerPub, signerPriv, err := ed25519.GenerateKey(bytes.NewReader([]byte("mismatch-signer-generation-for-tests-0001")))
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+
+	// Sealed without signer identifier, signed afterwards
+	sealed, err := Seal(&containerv1.Container{Headers: &containerv1.Header{}, Raw: []byte{0x00}}, publicKey)
+	if err != nil {
+		t.Fatalf("unable to seal container: %v", err)
+	}
+	var out bytes.Buffer
+	if err := Dump(&out, sealed); err != nil {
+		t.Fatalf("%v", err)
+	}
+	sig := ed25519.Sign(signerPriv, detachedSignatureProtected(out.Bytes()))
+
+	if _, err := VerifyContainerSignature(bytes.NewReader(out.Bytes()), sig, signerPub); !errors.Is(err, ErrSignerMismatch) {
+		t.Errorf("expected signer mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/convert"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// Rule describes a declarative secret path naming policy.
+type Rule struct {
+	// ID is the rule identifier reported by violations.
+	ID string `json:"id"`
+	// Description is an optional human readable rule description.
+	Description string `json:"description,omitempty"`
+	// Path is an optional glob used to restrict the rule to matching packages,
+	// '*' matches a single segment and '**' matches recursively.
+	Path string `json:"path,omitempty"`
+	// Segments enforces path segment values.
+	Segments []SegmentRule `json:"segments,omitempty"`
+	// ForbiddenKeys is a list of regular expressions secret keys must not
+	// match.
+	ForbiddenKeys []string `json:"forbiddenKeys,omitempty"`
+	// RequiredLabels is the list of labels that must be assigned to the
+	// package.
+	RequiredLabels []string `json:"requiredLabels,omitempty"`
+}
+
+// SegmentRule describes a required path segment.
+type SegmentRule struct {
+	// Index is the zero based segment position.
+	Index int `json:"index"`
+	// Pattern is a regular expression the whole segment value must match.
+	Pattern string `json:"pattern"`
+}
+
+// Violation describes a rule violation.
+type Violation struct {
+	RuleID  string `json:"ruleId"`
+	Path    string `json:"path"`
+	Key     string `json:"key,omitempty"`
+	Message string `json:"message"`
+}
+
+// LoadRules reads a YAML rule list from the given reader.
+//
+//	rules:
+//	  - id: CSO-APP-001
+//	    path: "app/**"
+//	    segments:
+//	      - index: 1
+//	        pattern: "production|staging"
+//	    forbiddenKeys: ["(?i)^pass$"]
+//	    requiredLabels: ["owner"]
+func LoadRules(r io.Reader) ([]Rule, error) {
+	// Check arguments
+	if types.IsNil(r) {
+		return nil, errors.New("reader is nil")
+	}
+
+	// Convert YAML to JSON
+	jsonReader, err := convert.YAMLtoJSON(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse input as YAML: %w", err)
+	}
+
+	// Decode rules
+	var def struct {
+		Rules []Rule `json:"rules"`
+	}
+	dec := json.NewDecoder(jsonReader)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&def); err != nil {
+		return nil, fmt.Errorf("unable to decode rules: %w", err)
+	}
+
+	// Validate rules
+	ids := map[string]struct{}{}
+	for i := range def.Rules {
+		if _, err := compileRule(&def.Rules[i]); err != nil {
+			return nil, fmt.Errorf("invalid rule #%d: %w", i, err)
+		}
+		if _, ok := ids[def.Rules[i].ID]; ok {
+			return nil, fmt.Errorf("invalid rule #%d: duplicate rule id '%s'", i, def.Rules[i].ID)
+		}
+		ids[def.Rules[i].ID] = struct{}{}
+	}
+
+	// No error
+	return def.Rules, nil
+}
+
+// Lint evaluates the given rules against all bundle packages. Violations are
+// returned in package then rule order. An invalid rule is reported as a
+// violation without path.
+func Lint(b *bundlev1.Bundle, rules []Rule) []Violation {
+	violations := []Violation{}
+
+	// Check arguments
+	if b == nil {
+		return violations
+	}
+
+	// Compile rules
+	compiled := make([]*compiledRule, 0, len(rules))
+	for i := range rules {
+		cr, err := compileRule(&rules[i])
+		if err != nil {
+			violations = append(violations, Violation{
+				RuleID:  rules[i].ID,
+				Message: fmt.Sprintf("invalid rule: %v", err),
+			})
+			continue
+		}
+		compiled = append(compiled, cr)
+	}
+
+	// Evaluate each package
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		for _, cr := range compiled {
+			violations = append(violations, cr.evaluate(p)...)
+		}
+	}
+
+	return violations
+}
+
+// -----------------------------------------------------------------------------
+
+type compiledRule struct {
+	id             string
+	path           glob.Glob
+	segments       []compiledSegment
+	forbiddenKeys  []*regexp.Regexp
+	requiredLabels []string
+}
+
+type compiledSegment struct {
+	index   int
+	pattern *regexp.Regexp
+}
+
+func compileRule(r *Rule) (*compiledRule, error) {
+	// Check arguments
+	if r.ID == "" {
+		return nil, errors.New("rule id is required")
+	}
+
+	cr := &compiledRule{
+		id:             r.ID,
+		requiredLabels: r.RequiredLabels,
+	}
+
+	// Compile path matcher
+	if r.Path != "" {
+		g, err := glob.Compile(r.Path, '/')
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile path glob '%s': %w", r.Path, err)
+		}
+		cr.path = g
+	}
+
+	// Compile segment patterns
+	for _, s := range r.Segments {
+		if s.Index < 0 {
+			return nil, fmt.Errorf("invalid segment index %d", s.Index)
+		}
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", s.Pattern))
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile segment pattern '%s': %w", s.Pattern, err)
+		}
+		cr.segments = append(cr.segments, compiledSegment{index: s.Index, pattern: re})
+	}
+
+	// Compile key patterns
+	for _, k := range r.ForbiddenKeys {
+		re, err := regexp.Compile(k)
+		if err != nil {
+			return nil, fmt.Errorf("unable to compile forbidden key pattern '%s': %w", k, err)
+		}
+		cr.forbiddenKeys = append(cr.forbiddenKeys, re)
+	}
+
+	// No error
+	return cr, nil
+}
+
+func (cr *compiledRule) evaluate(p *bundlev1.Package) []Violation {
+	violations := []Violation{}
+
+	// Check rule scope
+	if cr.path != nil && !cr.path.Match(p.Name) {
+		return violations
+	}
+
+	// Check segments
+	parts := strings.Split(p.Name, "/")
+	for _, s := range cr.segments {
+		switch {
+		case s.index >= len(parts):
+			violations = append(violations, Violation{
+				RuleID:  cr.id,
+				Path:    p.Name,
+				Message: fmt.Sprintf("segment %d is missing", s.index),
+			})
+		case !s.pattern.MatchString(parts[s.index]):
+			violations = append(violations, Violation{
+				RuleID:  cr.id,
+				Path:    p.Name,
+				Message: fmt.Sprintf("segment %d value '%s' does not match '%s'", s.index, parts[s.index], s.pattern.String()),
+			})
+		}
+	}
+
+	// Check labels
+	for _, l := range cr.requiredLabels {
+		if _, ok := p.Labels[l]; !ok {
+			violations = append(violations, Violation{
+				RuleID:  cr.id,
+				Path:    p.Name,
+				Message: fmt.Sprintf("label '%s' is required", l),
+			})
+		}
+	}
+
+	// Check secret keys
+	if p.Secrets != nil {
+		for _, kv := range p.Secrets.Data {
+			if kv == nil {
+				continue
+			}
+			for _, re := range cr.forbiddenKeys {
+				if re.MatchString(kv.Key) {
+					violations = append(violations, Violation{
+						RuleID:  cr.id,
+						Path:    p.Name,
+						Key:     kv.Key,
+						Message: fmt.Sprintf("secret key matches forbidden pattern '%s'", re.String()),
+					})
+					break
+				}
+			}
+		}
+	}
+
+	return violations
+}
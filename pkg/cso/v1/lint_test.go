@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/assert"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func testLintBundle() *bundlev1.Bundle {
+	return &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{
+			{
+				Name:   "app/production/security/harp/v1.0.0/server/database/credentials",
+				Labels: map[string]string{"owner": "security"},
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "user"}, {Key: "password"}},
+				},
+			},
+			{
+				Name: "app/prod/security/harp/v1.0.0/server/database/credentials",
+				Secrets: &bundlev1.SecretChain{
+					Data: []*bundlev1.KV{{Key: "PASS"}},
+				},
+			},
+			{
+				Name: "infra/aws/essp-dev/us-east-1/rds/adminconsole/accountRoot",
+			},
+		},
+	}
+}
+
+func TestLint(t *testing.T) {
+	rules := []Rule{
+		{
+			ID:   "STAGE",
+			Path: "app/**",
+			Segments: []SegmentRule{
+				{Index: 1, Pattern: "production|staging"},
+				{Index: 10, Pattern: ".*"},
+			},
+		},
+		{
+			ID:             "OWNER",
+			Path:           "app/**",
+			RequiredLabels: []string{"owner"},
+		},
+		{
+			ID:            "KEYS",
+			ForbiddenKeys: []string{"(?i)^pass$", "^PA"},
+		},
+	}
+
+	got := Lint(testLintBundle(), rules)
+	want := []Violation{
+		{RuleID: "STAGE", Path: "app/production/security/harp/v1.0.0/server/database/credentials", Message: "segment 10 is missing"},
+		{RuleID: "STAGE", Path: "app/prod/security/harp/v1.0.0/server/database/credentials", Message: "segment 1 value 'prod' does not match '^(?:production|staging)$'"},
+		{RuleID: "STAGE", Path: "app/prod/security/harp/v1.0.0/server/database/credentials", Message: "segment 10 is missing"},
+		{RuleID: "OWNER", Path: "app/prod/security/harp/v1.0.0/server/database/credentials", Message: "label 'owner' is required"},
+		{RuleID: "KEYS", Path: "app/prod/security/harp/v1.0.0/server/database/credentials", Key: "PASS", Message: "secret key matches forbidden pattern '(?i)^pass$'"},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("%q. Lint()\n %s", "violations", diff)
+	}
+}
+
+func TestLint_InvalidRule(t *testing.T) {
+	got := Lint(testLintBundle(), []Rule{
+		{ID: "INVALID", ForbiddenKeys: []string{"("}},
+	})
+	assert.Len(t, got, 1)
+	assert.Equal(t, "INVALID", got[0].RuleID)
+	assert.Empty(t, got[0].Path)
+
+	assert.Empty(t, Lint(nil, []Rule{{ID: "ANY"}}))
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(`
+rules:
+  - id: CSO-001
+    path: "app/**"
+    segments:
+      - index: 1
+        pattern: "production|staging"
+    forbiddenKeys: ["(?i)^pass$"]
+    requiredLabels: ["owner"]
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, []Rule{
+		{
+			ID:             "CSO-001",
+			Path:           "app/**",
+			Segments:       []SegmentRule{{Index: 1, Pattern: "production|staging"}},
+			ForbiddenKeys:  []string{"(?i)^pass$"},
+			RequiredLabels: []string{"owner"},
+		},
+	}, rules)
+
+	invalids := []string{
+		"rules:\n  - path: app/**\n",
+		"rules:\n  - id: A\n  - id: A\n",
+		"rules:\n  - id: A\n    unknown: true\n",
+		"rules:\n  - id: A\n    segments:\n      - index: -1\n",
+		"rules:\n  - id: A\n    forbiddenKeys: ['(']\n",
+		"rules:\n  - id: A\n    path: '['\n",
+	}
+	for _, in := range invalids {
+		_, err := LoadRules(strings.NewReader(in))
+		assert.Error(t, err, in)
+	}
+
+	_, err = LoadRules(nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,144 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	kmstypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
+
+	harpkms "github.com/elastic/harp/pkg/kms"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption/envelope"
+)
+
+func init() {
+	harpkms.RegisterProvider("aws", FromKey)
+}
+
+// Client describes the AWS KMS operations used by the transformer, it is
+// implemented by the AWS SDK `kms.Client`.
+type Client interface {
+	Encrypt(ctx context.Context, params *kms.EncryptInput, optFns ...func(*kms.Options)) (*kms.EncryptOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+}
+
+// FromKey returns an AWS KMS envelope encryption transformer using the
+// default AWS SDK credential chain.
+// aws:<key-arn>
+func FromKey(keyID string) (value.Transformer, error) {
+	// Remove the prefix
+	keyID = strings.TrimPrefix(keyID, "aws:")
+	if keyID == "" {
+		return nil, errors.New("kms: aws key identifier must not be blank")
+	}
+
+	// Use the key region when the identifier is an ARN
+	// arn:aws:kms:<region>:<account>:key/<id>
+	opts := []func(*config.LoadOptions) error{}
+	if parts := strings.Split(keyID, ":"); len(parts) == 6 && parts[0] == "arn" {
+		opts = append(opts, config.WithRegion(parts[3]))
+	}
+
+	// Load AWS SDK configuration
+	cfg, err := config.LoadDefaultConfig(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to load aws configuration: %w", err)
+	}
+
+	// Delegate to transformer
+	return Transformer(kms.NewFromConfig(cfg), keyID)
+}
+
+// Transformer returns an AWS KMS envelope encryption transformer.
+//
+// Each value is encrypted locally with AES-256-GCM using a data key generated
+// by KMS, the encrypted data key is stored alongside the ciphertext and
+// decrypted by KMS on `From`.
+func Transformer(client Client, keyID string) (value.Transformer, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("kms: aws client must not be nil")
+	}
+	if keyID == "" {
+		return nil, errors.New("kms: aws key identifier must not be blank")
+	}
+
+	// Wrap the transformer with envelope
+	return envelope.Transformer(&service{
+		client: client,
+		keyID:  keyID,
+	}, harpkms.DataEncryption)
+}
+
+// -----------------------------------------------------------------------------
+
+type service struct {
+	client Client
+	keyID  string
+}
+
+func (s *service) GenerateDataKey(ctx context.Context) (cleartext, encrypted []byte, err error) {
+	// Generate a data key
+	out, err := s.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(s.keyID),
+		KeySpec: kmstypes.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("kms: unable to generate data key with '%s' key: %w", s.keyID, err)
+	}
+
+	// No error
+	return out.Plaintext, out.CiphertextBlob, nil
+}
+
+func (s *service) Encrypt(ctx context.Context, cleartext []byte) ([]byte, error) {
+	// Encrypt with KMS
+	out, err := s.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:     aws.String(s.keyID),
+		Plaintext: cleartext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to encrypt with '%s' key: %w", s.keyID, err)
+	}
+
+	// No error
+	return out.CiphertextBlob, nil
+}
+
+func (s *service) Decrypt(ctx context.Context, encrypted []byte) ([]byte, error) {
+	// Decrypt with KMS
+	out, err := s.client.Decrypt(ctx, &kms.DecryptInput{
+		KeyId:          aws.String(s.keyID),
+		CiphertextBlob: encrypted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to decrypt with '%s' key: %w", s.keyID, err)
+	}
+
+	// No error
+	return out.Plaintext, nil
+}
@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aws
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKeyARN = "arn:aws:kms:us-east-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+
+type fakeClient struct {
+	keys      map[string][]byte
+	generated int
+}
+
+func (c *fakeClient) wrap(keyID string, plaintext []byte) ([]byte, error) {
+	if keyID != testKeyARN {
+		return nil, errors.New("unknown key")
+	}
+	blob := fmt.Sprintf("blob-%d", len(c.keys))
+	c.keys[blob] = append([]byte{}, plaintext...)
+	return []byte(blob), nil
+}
+
+func (c *fakeClient) Encrypt(_ context.Context, params *kms.EncryptInput, _ ...func(*kms.Options)) (*kms.EncryptOutput, error) {
+	blob, err := c.wrap(aws.ToString(params.KeyId), params.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.EncryptOutput{CiphertextBlob: blob}, nil
+}
+
+func (c *fakeClient) Decrypt(_ context.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	if aws.ToString(params.KeyId) != testKeyARN {
+		return nil, errors.New("unknown key")
+	}
+	plaintext, ok := c.keys[string(params.CiphertextBlob)]
+	if !ok {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return &kms.DecryptOutput{Plaintext: append([]byte{}, plaintext...)}, nil
+}
+
+func (c *fakeClient) GenerateDataKey(_ context.Context, params *kms.GenerateDataKeyInput, _ ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	c.generated++
+	plaintext := make([]byte, 32)
+	if _, err := rand.Read(plaintext); err != nil {
+		return nil, err
+	}
+	blob, err := c.wrap(aws.ToString(params.KeyId), plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return &kms.GenerateDataKeyOutput{Plaintext: plaintext, CiphertextBlob: blob}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func Test_Transformer(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{keys: map[string][]byte{}}
+
+	underTest, err := Transformer(client, testKeyARN)
+	assert.NoError(t, err)
+
+	// Encrypt using a generated data key
+	encrypted, err := underTest.To(ctx, []byte("cleartext"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.generated)
+	assert.NotContains(t, string(encrypted), "cleartext")
+
+	// Decrypt the data key with KMS
+	out, err := underTest.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cleartext"), out)
+
+	// Another key can't decrypt the value
+	other, err := Transformer(client, "arn:aws:kms:us-east-1:123456789012:key/other")
+	assert.NoError(t, err)
+	_, err = other.From(ctx, encrypted)
+	assert.Error(t, err)
+
+	// Generation errors are reported
+	_, err = other.To(ctx, []byte("cleartext"))
+	assert.Error(t, err)
+}
+
+func Test_Transformer_InvalidArguments(t *testing.T) {
+	_, err := Transformer(nil, testKeyARN)
+	assert.Error(t, err)
+
+	var client *fakeClient
+	_, err = Transformer(client, testKeyARN)
+	assert.Error(t, err)
+
+	_, err = Transformer(&fakeClient{}, "")
+	assert.Error(t, err)
+
+	_, err = FromKey("aws:")
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
+
+	harpkms "github.com/elastic/harp/pkg/kms"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption/envelope"
+)
+
+func init() {
+	harpkms.RegisterProvider("azure", FromKey)
+}
+
+// Client describes the Azure Key Vault operations used by the transformer, it
+// is implemented by the Azure SDK `crypto.Client`.
+type Client interface {
+	WrapKey(ctx context.Context, alg crypto.KeyWrapAlgorithm, key []byte, options *crypto.WrapKeyOptions) (crypto.WrapKeyResponse, error)
+	UnwrapKey(ctx context.Context, alg crypto.KeyWrapAlgorithm, encryptedKey []byte, options *crypto.UnwrapKeyOptions) (crypto.UnwrapKeyResponse, error)
+}
+
+// FromKey returns an Azure Key Vault envelope encryption transformer using the
+// default Azure credential chain.
+// azure:<vault url>/<key>
+//
+// Managed HSM keys (`*.managedhsm.azure.net`) are used with the `A256KW`
+// symmetric wrap algorithm, vault keys with `RSA-OAEP-256`.
+func FromKey(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "azure:")
+
+	// Parse key URL
+	keyURL, alg, err := parseKeyURL(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Load Azure credentials
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to load azure credentials: %w", err)
+	}
+
+	// Create Key Vault client
+	client, err := crypto.NewClient(keyURL, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to initialize azure client: %w", err)
+	}
+
+	// Delegate to transformer
+	return Transformer(client, keyURL, alg)
+}
+
+// Transformer returns an Azure Key Vault envelope encryption transformer.
+//
+// Each value is encrypted locally with AES-256-GCM using a random data key,
+// the data key is wrapped by Key Vault and stored alongside the ciphertext.
+func Transformer(client Client, keyURL string, alg crypto.KeyWrapAlgorithm) (value.Transformer, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("kms: azure client must not be nil")
+	}
+	if alg == "" {
+		return nil, errors.New("kms: azure key wrap algorithm must not be blank")
+	}
+
+	// Wrap the transformer with envelope
+	return envelope.Transformer(&service{
+		client: client,
+		keyURL: keyURL,
+		alg:    alg,
+	}, harpkms.DataEncryption)
+}
+
+// -----------------------------------------------------------------------------
+
+func parseKeyURL(key string) (string, crypto.KeyWrapAlgorithm, error) {
+	// Parse URL
+	u, err := url.Parse(key)
+	if err != nil {
+		return "", "", fmt.Errorf("kms: unable to parse azure key url: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return "", "", errors.New("kms: azure key url must be an https url")
+	}
+
+	// Normalize key path as /keys/<name>[/<version>]
+	p := strings.Trim(u.Path, "/")
+	if p == "" {
+		return "", "", errors.New("kms: azure key name must not be blank")
+	}
+	if !strings.HasPrefix(p, "keys/") {
+		p = "keys/" + p
+	}
+	u.Path = "/" + p
+
+	// Select wrap algorithm
+	alg := crypto.RSAOAEP256
+	if strings.HasSuffix(u.Hostname(), ".managedhsm.azure.net") {
+		alg = crypto.AES256
+	}
+
+	// No error
+	return u.String(), alg, nil
+}
+
+type service struct {
+	client Client
+	keyURL string
+	alg    crypto.KeyWrapAlgorithm
+}
+
+func (s *service) Encrypt(ctx context.Context, cleartext []byte) ([]byte, error) {
+	// Wrap with Key Vault
+	out, err := s.client.WrapKey(ctx, s.alg, cleartext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to wrap data key with '%s' key: %w", s.keyURL, err)
+	}
+
+	// No error
+	return out.Result, nil
+}
+
+func (s *service) Decrypt(ctx context.Context, encrypted []byte) ([]byte, error) {
+	// Unwrap with Key Vault
+	out, err := s.client.UnwrapKey(ctx, s.alg, encrypted, nil)
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to unwrap data key with '%s' key: %w", s.keyURL, err)
+	}
+
+	// No error
+	return out.Result, nil
+}
@@ -0,0 +1,133 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package azure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azkeys/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	alg  crypto.KeyWrapAlgorithm
+	keys map[string][]byte
+}
+
+func (c *fakeClient) WrapKey(_ context.Context, alg crypto.KeyWrapAlgorithm, key []byte, _ *crypto.WrapKeyOptions) (crypto.WrapKeyResponse, error) {
+	if alg != c.alg {
+		return crypto.WrapKeyResponse{}, errors.New("unsupported algorithm")
+	}
+	blob := fmt.Sprintf("blob-%d", len(c.keys))
+	c.keys[blob] = append([]byte{}, key...)
+	return crypto.WrapKeyResponse{KeyOperationResult: crypto.KeyOperationResult{Result: []byte(blob)}}, nil
+}
+
+func (c *fakeClient) UnwrapKey(_ context.Context, alg crypto.KeyWrapAlgorithm, encryptedKey []byte, _ *crypto.UnwrapKeyOptions) (crypto.UnwrapKeyResponse, error) {
+	if alg != c.alg {
+		return crypto.UnwrapKeyResponse{}, errors.New("unsupported algorithm")
+	}
+	key, ok := c.keys[string(encryptedKey)]
+	if !ok {
+		return crypto.UnwrapKeyResponse{}, errors.New("invalid ciphertext")
+	}
+	return crypto.UnwrapKeyResponse{KeyOperationResult: crypto.KeyOperationResult{Result: append([]byte{}, key...)}}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func Test_Transformer(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{alg: crypto.RSAOAEP256, keys: map[string][]byte{}}
+
+	underTest, err := Transformer(client, "https://harp.vault.azure.net/keys/bundle", crypto.RSAOAEP256)
+	assert.NoError(t, err)
+
+	encrypted, err := underTest.To(ctx, []byte("cleartext"))
+	assert.NoError(t, err)
+	assert.Len(t, client.keys, 1)
+
+	out, err := underTest.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cleartext"), out)
+
+	// Wrap errors are reported
+	other, err := Transformer(client, "https://harp.vault.azure.net/keys/bundle", crypto.AES256)
+	assert.NoError(t, err)
+	_, err = other.To(ctx, []byte("cleartext"))
+	assert.Error(t, err)
+	_, err = other.From(ctx, encrypted)
+	assert.Error(t, err)
+}
+
+func Test_Transformer_InvalidArguments(t *testing.T) {
+	_, err := Transformer(nil, "https://harp.vault.azure.net/keys/bundle", crypto.RSAOAEP256)
+	assert.Error(t, err)
+
+	_, err = Transformer(&fakeClient{}, "https://harp.vault.azure.net/keys/bundle", "")
+	assert.Error(t, err)
+}
+
+func Test_parseKeyURL(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     string
+		wantURL string
+		wantAlg crypto.KeyWrapAlgorithm
+		wantErr bool
+	}{
+		{name: "blank", key: "", wantErr: true},
+		{name: "not https", key: "http://harp.vault.azure.net/bundle", wantErr: true},
+		{name: "no key", key: "https://harp.vault.azure.net/", wantErr: true},
+		{name: "invalid", key: "https://%%%", wantErr: true},
+		{
+			name:    "vault key",
+			key:     "https://harp.vault.azure.net/bundle",
+			wantURL: "https://harp.vault.azure.net/keys/bundle",
+			wantAlg: crypto.RSAOAEP256,
+		},
+		{
+			name:    "versioned key",
+			key:     "https://harp.vault.azure.net/keys/bundle/0123456789",
+			wantURL: "https://harp.vault.azure.net/keys/bundle/0123456789",
+			wantAlg: crypto.RSAOAEP256,
+		},
+		{
+			name:    "managed hsm key",
+			key:     "https://harp.managedhsm.azure.net/bundle",
+			wantURL: "https://harp.managedhsm.azure.net/keys/bundle",
+			wantAlg: crypto.AES256,
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			gotURL, gotAlg, err := parseKeyURL(testCase.key)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantURL, gotURL)
+			assert.Equal(t, testCase.wantAlg, gotAlg)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"github.com/googleapis/gax-go/v2"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+
+	harpkms "github.com/elastic/harp/pkg/kms"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption/envelope"
+)
+
+func init() {
+	harpkms.RegisterProvider("gcp", FromKey)
+}
+
+// Client describes the GCP KMS operations used by the transformer, it is
+// implemented by the GCP SDK `kms.KeyManagementClient`.
+type Client interface {
+	Encrypt(ctx context.Context, req *kmspb.EncryptRequest, opts ...gax.CallOption) (*kmspb.EncryptResponse, error)
+	Decrypt(ctx context.Context, req *kmspb.DecryptRequest, opts ...gax.CallOption) (*kmspb.DecryptResponse, error)
+}
+
+// FromKey returns a GCP KMS envelope encryption transformer using the
+// application default credentials. All transformers share the same KMS client
+// so that gRPC connections are not leaked per key reference.
+// gcp:projects/<project>/locations/<location>/keyRings/<ring>/cryptoKeys/<key>
+func FromKey(resource string) (value.Transformer, error) {
+	// Remove the prefix
+	resource = strings.TrimPrefix(resource, "gcp:")
+	if resource == "" {
+		return nil, errors.New("kms: gcp key resource must not be blank")
+	}
+
+	// Retrieve KMS client
+	client, err := sharedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to transformer
+	return Transformer(client, resource)
+}
+
+// Transformer returns a GCP KMS envelope encryption transformer.
+//
+// Each value is encrypted locally with AES-256-GCM using a random data key,
+// the data key is wrapped by KMS and stored alongside the ciphertext.
+func Transformer(client Client, resource string) (value.Transformer, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("kms: gcp client must not be nil")
+	}
+	if resource == "" {
+		return nil, errors.New("kms: gcp key resource must not be blank")
+	}
+
+	// Wrap the transformer with envelope
+	return envelope.Transformer(&service{
+		client:   client,
+		resource: resource,
+	}, harpkms.DataEncryption)
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	defaultClientMu sync.Mutex
+	defaultClient   *kms.KeyManagementClient
+)
+
+// sharedClient returns the process wide KMS client, it is created on first use
+// and creation is retried on the next call if it fails.
+func sharedClient() (*kms.KeyManagementClient, error) {
+	defaultClientMu.Lock()
+	defer defaultClientMu.Unlock()
+
+	if defaultClient == nil {
+		client, err := kms.NewKeyManagementClient(context.Background())
+		if err != nil {
+			return nil, fmt.Errorf("kms: unable to initialize gcp client: %w", err)
+		}
+		defaultClient = client
+	}
+
+	// No error
+	return defaultClient, nil
+}
+
+type service struct {
+	client   Client
+	resource string
+}
+
+func (s *service) Encrypt(ctx context.Context, cleartext []byte) ([]byte, error) {
+	// Wrap with KMS
+	out, err := s.client.Encrypt(ctx, &kmspb.EncryptRequest{
+		Name:      s.resource,
+		Plaintext: cleartext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to encrypt with '%s' key: %w", s.resource, err)
+	}
+
+	// No error
+	return out.Ciphertext, nil
+}
+
+func (s *service) Decrypt(ctx context.Context, encrypted []byte) ([]byte, error) {
+	// Unwrap with KMS
+	out, err := s.client.Decrypt(ctx, &kmspb.DecryptRequest{
+		Name:       s.resource,
+		Ciphertext: encrypted,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms: unable to decrypt with '%s' key: %w", s.resource, err)
+	}
+
+	// No error
+	return out.Plaintext, nil
+}
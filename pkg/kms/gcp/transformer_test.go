@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package gcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/googleapis/gax-go/v2"
+	"github.com/stretchr/testify/assert"
+	kmspb "google.golang.org/genproto/googleapis/cloud/kms/v1"
+)
+
+const testResource = "projects/harp/locations/global/keyRings/harp/cryptoKeys/bundle"
+
+type fakeClient struct {
+	keys map[string][]byte
+}
+
+func (c *fakeClient) Encrypt(_ context.Context, req *kmspb.EncryptRequest, _ ...gax.CallOption) (*kmspb.EncryptResponse, error) {
+	if req.Name != testResource {
+		return nil, errors.New("unknown key")
+	}
+	blob := fmt.Sprintf("blob-%d", len(c.keys))
+	c.keys[blob] = append([]byte{}, req.Plaintext...)
+	return &kmspb.EncryptResponse{Name: req.Name, Ciphertext: []byte(blob)}, nil
+}
+
+func (c *fakeClient) Decrypt(_ context.Context, req *kmspb.DecryptRequest, _ ...gax.CallOption) (*kmspb.DecryptResponse, error) {
+	if req.Name != testResource {
+		return nil, errors.New("unknown key")
+	}
+	plaintext, ok := c.keys[string(req.Ciphertext)]
+	if !ok {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return &kmspb.DecryptResponse{Plaintext: append([]byte{}, plaintext...)}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func Test_Transformer(t *testing.T) {
+	ctx := context.Background()
+	client := &fakeClient{keys: map[string][]byte{}}
+
+	underTest, err := Transformer(client, testResource)
+	assert.NoError(t, err)
+
+	encrypted, err := underTest.To(ctx, []byte("cleartext"))
+	assert.NoError(t, err)
+	assert.Len(t, client.keys, 1)
+
+	out, err := underTest.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cleartext"), out)
+
+	// Another key can't decrypt the value
+	other, err := Transformer(client, "projects/harp/locations/global/keyRings/harp/cryptoKeys/other")
+	assert.NoError(t, err)
+	_, err = other.From(ctx, encrypted)
+	assert.Error(t, err)
+}
+
+func Test_Transformer_InvalidArguments(t *testing.T) {
+	_, err := Transformer(nil, testResource)
+	assert.Error(t, err)
+
+	_, err = Transformer(&fakeClient{}, "")
+	assert.Error(t, err)
+
+	_, err = FromKey("gcp:")
+	assert.Error(t, err)
+}
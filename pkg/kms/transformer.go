@@ -0,0 +1,76 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kms
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+	"github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+)
+
+// DataEncryption is the local data encryption used by all KMS providers to
+// encrypt values with the data encryption key, so that the produced format is
+// identical across providers.
+var DataEncryption encryption.TransformerFactoryFunc = aead.AESGCM
+
+var providers map[string]encryption.TransformerFactoryFunc
+
+func init() {
+	encryption.Register("kms", FromKey)
+}
+
+// RegisterProvider registers a KMS provider with the given name.
+func RegisterProvider(name string, factory encryption.TransformerFactoryFunc) {
+	// Lazy initialization
+	if providers == nil {
+		providers = map[string]encryption.TransformerFactoryFunc{}
+	}
+
+	// Check if not already registered
+	if _, ok := providers[name]; ok {
+		panic(fmt.Errorf("kms provider already registered for '%s' name", name))
+	}
+
+	// Register the provider
+	providers[name] = factory
+}
+
+// FromKey returns the KMS envelope encryption transformer matching the
+// `kms:<provider>:<key>` key.
+func FromKey(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "kms:")
+
+	// Split provider / key
+	parts := strings.SplitN(key, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, fmt.Errorf("kms: key format error, expected kms:<provider>:<key>")
+	}
+
+	// Select provider
+	factory, ok := providers[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("kms: no provider registered for '%s'", parts[0])
+	}
+
+	// Delegate to provider
+	return factory(parts[1])
+}
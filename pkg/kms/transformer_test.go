@@ -0,0 +1,52 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/identity"
+)
+
+func Test_FromKey(t *testing.T) {
+	RegisterProvider("test", func(key string) (value.Transformer, error) {
+		assert.Equal(t, "arn:test:key", key)
+		return identity.Transformer(), nil
+	})
+
+	underTest, err := FromKey("kms:test:arn:test:key")
+	assert.NoError(t, err)
+	out, err := underTest.To(context.Background(), []byte("test"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), out)
+
+	// Duplicate providers are rejected
+	assert.Panics(t, func() {
+		RegisterProvider("test", nil)
+	})
+
+	// Invalid keys
+	for _, key := range []string{"kms:", "kms:test", "kms:test:", "kms:unknown:key"} {
+		_, err := FromKey(key)
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,220 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	api "github.com/hashicorp/consul/api"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/kv"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// MaxTxnOperations is the maximum operation count accepted by Consul in a
+// single KV transaction.
+const MaxTxnOperations = 64
+
+// TxnClient describes the Consul KV client operations used for transactional
+// bundle imports. It is implemented by `*api.KV`.
+type TxnClient interface {
+	Client
+	Txn(txn api.KVTxnOps, q *api.QueryOptions) (bool, *api.KVTxnResponse, *api.QueryMeta, error)
+}
+
+// Import writes the given bundle packages into Consul KV under the given
+// prefix.
+//
+// Consul KV values are opaque bytes, each package is stored as a single key
+// named `<prefix>/<package name>` holding the JSON object of the unpacked
+// package secrets (`{"<secret key>": <secret value>, ...}`). This is the same
+// encoding used by `harp to consul` without secret expansion.
+//
+// Keys are written using Consul KV transactions, a transaction can't hold more
+// than MaxTxnOperations operations so that bundles with more packages are
+// written by batches, each batch is applied atomically. Locked packages are
+// not supported and must be unlocked before the import.
+func Import(ctx context.Context, client TxnClient, b *bundlev1.Bundle, prefix string) error {
+	// Check arguments
+	if types.IsNil(client) {
+		return errors.New("consul: unable to import with nil client")
+	}
+	if b == nil {
+		return errors.New("consul: unable to import a nil bundle")
+	}
+
+	// Prepare operations
+	ops := api.KVTxnOps{}
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		if p.Secrets == nil {
+			return fmt.Errorf("consul: package '%s' has no secret chain", p.Name)
+		}
+		if p.Secrets.Locked != nil {
+			return fmt.Errorf("consul: package '%s' is locked", p.Name)
+		}
+
+		// Unpack secrets
+		secrets, err := bundle.AsSecretMap(p)
+		if err != nil {
+			return fmt.Errorf("consul: unable to unpack '%s' secrets: %w", p.Name, err)
+		}
+
+		// Encode as json
+		payload, err := json.Marshal(secrets)
+		if err != nil {
+			return fmt.Errorf("consul: unable to encode '%s' secrets as JSON: %w", p.Name, err)
+		}
+
+		ops = append(ops, &api.KVTxnOp{
+			Verb:  api.KVSet,
+			Key:   packageKey(prefix, p.Name),
+			Value: payload,
+		})
+	}
+
+	// Sort operations to get a stable batching
+	sort.SliceStable(ops, func(i, j int) bool {
+		return ops[i].Key < ops[j].Key
+	})
+
+	// Apply by batches
+	for start := 0; start < len(ops); start += MaxTxnOperations {
+		end := start + MaxTxnOperations
+		if end > len(ops) {
+			end = len(ops)
+		}
+
+		// Check cancellation
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Delegate to client
+		ok, resp, _, err := client.Txn(ops[start:end], (&api.QueryOptions{}).WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("consul: unable to execute transaction: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("consul: transaction has been rolled back: %s", txnErrors(ops[start:end], resp))
+		}
+	}
+
+	// No error
+	return nil
+}
+
+// Export reads the packages stored under the given prefix by Import and
+// returns them as a bundle. Packages are sorted by name, and secrets by key.
+func Export(ctx context.Context, client Client, prefix string) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("consul: unable to export with nil client")
+	}
+
+	// List keys from prefix
+	basePath := packageKey(prefix, "")
+	if basePath != "" {
+		basePath += "/"
+	}
+	items, _, err := client.List(basePath, (&api.QueryOptions{
+		RequireConsistent: true,
+	}).WithContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("consul: unable to list keys from '%s': %w", prefix, err)
+	}
+
+	res := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{},
+	}
+	for _, item := range items {
+		// Skip folders
+		if item == nil || strings.HasSuffix(item.Key, "/") {
+			continue
+		}
+
+		// Decode package secrets
+		var secrets bundle.KV
+		if err := json.Unmarshal(item.Value, &secrets); err != nil {
+			return nil, fmt.Errorf("consul: unable to decode '%s' value as a JSON object: %w", item.Key, err)
+		}
+
+		// Pack secrets
+		data, err := bundle.FromSecretMap(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("consul: unable to pack '%s' secrets: %w", item.Key, err)
+		}
+		sort.SliceStable(data, func(i, j int) bool {
+			return data[i].Key < data[j].Key
+		})
+
+		res.Packages = append(res.Packages, &bundlev1.Package{
+			Name: strings.TrimPrefix(item.Key, basePath),
+			Secrets: &bundlev1.SecretChain{
+				Data: data,
+			},
+		})
+	}
+	if len(res.Packages) == 0 {
+		return nil, kv.ErrKeyNotFound
+	}
+
+	// Sort packages
+	sort.SliceStable(res.Packages, func(i, j int) bool {
+		return res.Packages[i].Name < res.Packages[j].Name
+	})
+
+	// No error
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func packageKey(prefix, name string) string {
+	return strings.Trim(path.Join("/", prefix, name), "/")
+}
+
+func txnErrors(ops api.KVTxnOps, resp *api.KVTxnResponse) string {
+	if resp == nil || len(resp.Errors) == 0 {
+		return "no error details"
+	}
+
+	msgs := []string{}
+	for _, e := range resp.Errors {
+		if e == nil {
+			continue
+		}
+		if e.OpIndex >= 0 && e.OpIndex < len(ops) {
+			msgs = append(msgs, fmt.Sprintf("'%s': %s", ops[e.OpIndex].Key, e.What))
+			continue
+		}
+		msgs = append(msgs, e.What)
+	}
+
+	return strings.Join(msgs, ", ")
+}
@@ -0,0 +1,168 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/kv"
+)
+
+var _ TxnClient = (*api.KV)(nil)
+
+// memoryClient is an in-memory transactional Consul KV client.
+type memoryClient struct {
+	Client
+	data     map[string][]byte
+	txnCount int
+	rollback bool
+}
+
+func (c *memoryClient) Txn(ops api.KVTxnOps, _ *api.QueryOptions) (bool, *api.KVTxnResponse, *api.QueryMeta, error) {
+	c.txnCount++
+	if len(ops) > MaxTxnOperations {
+		return false, nil, nil, errors.New("too many operations")
+	}
+	if c.rollback {
+		return false, &api.KVTxnResponse{
+			Errors: api.TxnErrors{{OpIndex: 0, What: "permission denied"}},
+		}, nil, nil
+	}
+	for _, op := range ops {
+		c.data[op.Key] = op.Value
+	}
+	return true, &api.KVTxnResponse{}, nil, nil
+}
+
+func (c *memoryClient) List(prefix string, _ *api.QueryOptions) (api.KVPairs, *api.QueryMeta, error) {
+	res := api.KVPairs{}
+	for k, v := range c.data {
+		if strings.HasPrefix(k, prefix) {
+			res = append(res, &api.KVPair{Key: k, Value: v})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Key < res[j].Key
+	})
+	return res, &api.QueryMeta{}, nil
+}
+
+func testBundle(t *testing.T, count int) *bundlev1.Bundle {
+	t.Helper()
+
+	input := map[string]bundle.KV{}
+	for i := 0; i < count; i++ {
+		input[fmt.Sprintf("app/production/service-%03d", i)] = bundle.KV{
+			"user":     fmt.Sprintf("user-%d", i),
+			"password": "foo",
+		}
+	}
+
+	b, err := bundle.FromMap(input)
+	require.NoError(t, err)
+
+	return b
+}
+
+// -----------------------------------------------------------------------------
+
+func TestImport_Export(t *testing.T) {
+	client := &memoryClient{data: map[string][]byte{}}
+	in := testBundle(t, 2)
+
+	err := Import(context.Background(), client, in, "/harp/")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.txnCount)
+	assert.JSONEq(t, `{"password":"foo","user":"user-0"}`, string(client.data["harp/app/production/service-000"]))
+
+	// Foreign key with the same prefix
+	client.data["harpoon/other"] = []byte("{}")
+
+	out, err := Export(context.Background(), client, "harp")
+	assert.NoError(t, err)
+	require.Len(t, out.Packages, 2)
+	assert.Equal(t, "app/production/service-000", out.Packages[0].Name)
+	assert.Equal(t, "app/production/service-001", out.Packages[1].Name)
+
+	secrets, err := bundle.AsSecretMap(out.Packages[1])
+	assert.NoError(t, err)
+	assert.Equal(t, bundle.KV{"user": "user-1", "password": "foo"}, secrets)
+}
+
+func TestImport_Batches(t *testing.T) {
+	client := &memoryClient{data: map[string][]byte{}}
+
+	err := Import(context.Background(), client, testBundle(t, MaxTxnOperations+1), "")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, client.txnCount)
+	assert.Len(t, client.data, MaxTxnOperations+1)
+}
+
+func TestImport_Rollback(t *testing.T) {
+	client := &memoryClient{data: map[string][]byte{}, rollback: true}
+
+	err := Import(context.Background(), client, testBundle(t, 1), "harp")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "'harp/app/production/service-000': permission denied")
+	assert.Empty(t, client.data)
+}
+
+func TestImport_Locked(t *testing.T) {
+	client := &memoryClient{data: map[string][]byte{}}
+	b := testBundle(t, 1)
+	b.Packages[0].Secrets.Locked = wrapperspb.Bytes([]byte("locked"))
+
+	err := Import(context.Background(), client, b, "harp")
+	assert.Error(t, err)
+	assert.Equal(t, 0, client.txnCount)
+}
+
+func TestImport_Canceled(t *testing.T) {
+	client := &memoryClient{data: map[string][]byte{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Import(ctx, client, testBundle(t, 1), "harp")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, client.txnCount)
+}
+
+func TestExport_Errors(t *testing.T) {
+	_, err := Export(context.Background(), nil, "harp")
+	assert.Error(t, err)
+
+	client := &memoryClient{data: map[string][]byte{}}
+	_, err = Export(context.Background(), client, "harp")
+	assert.ErrorIs(t, err, kv.ErrKeyNotFound)
+
+	client.data["harp/invalid"] = []byte("raw")
+	_, err = Export(context.Background(), client, "harp")
+	assert.Error(t, err)
+}
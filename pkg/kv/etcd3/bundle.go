@@ -0,0 +1,300 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package etcd3
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/kv"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// PackageTTLAnnotation is the package annotation holding the package lease TTL
+// as a duration string (`30m`, `24h`, ...).
+const PackageTTLAnnotation = "harp.elastic.co/v1/package#ttl"
+
+// Client describes the etcd client operations used by bundle import and
+// export. It is implemented by `*clientv3.Client`.
+type Client interface {
+	Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error)
+	Txn(ctx context.Context) clientv3.Txn
+	Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error)
+	Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error)
+	TimeToLive(ctx context.Context, id clientv3.LeaseID, opts ...clientv3.LeaseOption) (*clientv3.LeaseTimeToLiveResponse, error)
+}
+
+// Import writes the given bundle packages into etcd.
+//
+// Each package is stored as a single key named `<prefix>/<package name>`
+// holding the JSON object of the unpacked package secrets. Packages having a
+// TTL, from the `harp.elastic.co/v1/package#ttl` annotation or the WithTTL
+// option, are attached to a lease so that they are deleted by etcd when the
+// lease expires. Packages sharing the same TTL share the same lease.
+//
+// Keys are written in a single transaction, or by batches of transactions when
+// the bundle has more packages than the transaction operation limit. Granted
+// leases are returned by package name so that the caller can renew them using
+// `KeepAlive`; they are revoked when the import fails.
+func Import(ctx context.Context, client Client, b *bundlev1.Bundle, opts ...Option) (map[string]clientv3.LeaseID, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("etcd3: unable to import with nil client")
+	}
+	if b == nil {
+		return nil, errors.New("etcd3: unable to import a nil bundle")
+	}
+
+	// Apply options
+	dopts := &options{
+		maxTxnOps: DefaultMaxTxnOperations,
+	}
+	for _, o := range opts {
+		if err := o(dopts); err != nil {
+			return nil, err
+		}
+	}
+
+	type entry struct {
+		name    string
+		key     string
+		payload []byte
+		ttl     time.Duration
+	}
+
+	// Prepare entries
+	entries := []entry{}
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		if p.Secrets == nil {
+			return nil, fmt.Errorf("etcd3: package '%s' has no secret chain", p.Name)
+		}
+		if p.Secrets.Locked != nil {
+			return nil, fmt.Errorf("etcd3: package '%s' is locked", p.Name)
+		}
+
+		// Resolve package ttl
+		ttl, err := packageTTL(p, dopts.ttl)
+		if err != nil {
+			return nil, err
+		}
+
+		// Unpack secrets
+		secrets, err := bundle.AsSecretMap(p)
+		if err != nil {
+			return nil, fmt.Errorf("etcd3: unable to unpack '%s' secrets: %w", p.Name, err)
+		}
+
+		// Encode as json
+		payload, err := json.Marshal(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("etcd3: unable to encode '%s' secrets as JSON: %w", p.Name, err)
+		}
+
+		entries = append(entries, entry{
+			name:    p.Name,
+			key:     packageKey(dopts.prefix, p.Name),
+			payload: payload,
+			ttl:     ttl,
+		})
+	}
+
+	// Sort entries to get a stable batching
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	// Grant leases
+	leases := map[time.Duration]clientv3.LeaseID{}
+	revoke := func() {
+		for _, id := range leases {
+			// Use a detached context, the given one could be canceled. Errors
+			// are ignored, leases expire anyway.
+			_, _ = client.Revoke(context.Background(), id)
+		}
+	}
+	res := map[string]clientv3.LeaseID{}
+	for _, e := range entries {
+		if e.ttl == 0 {
+			continue
+		}
+		id, ok := leases[e.ttl]
+		if !ok {
+			resp, err := client.Grant(ctx, ttlSeconds(e.ttl))
+			if err != nil {
+				revoke()
+				return nil, fmt.Errorf("etcd3: unable to grant a %s lease: %w", e.ttl, err)
+			}
+			id = resp.ID
+			leases[e.ttl] = id
+		}
+		res[e.name] = id
+	}
+
+	// Apply by batches
+	for start := 0; start < len(entries); start += dopts.maxTxnOps {
+		end := start + dopts.maxTxnOps
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		// Prepare operations
+		ops := []clientv3.Op{}
+		for _, e := range entries[start:end] {
+			putOpts := []clientv3.OpOption{}
+			if id, ok := res[e.name]; ok {
+				putOpts = append(putOpts, clientv3.WithLease(id))
+			}
+			ops = append(ops, clientv3.OpPut(e.key, string(e.payload), putOpts...))
+		}
+
+		// Commit transaction
+		if _, err := client.Txn(ctx).Then(ops...).Commit(); err != nil {
+			revoke()
+			return nil, fmt.Errorf("etcd3: unable to commit transaction: %w", err)
+		}
+	}
+
+	// No error
+	return res, nil
+}
+
+// Export reads the packages stored under the given prefix by Import and
+// returns them as a bundle. The remaining lease TTL of a package is exposed
+// using the `harp.elastic.co/v1/package#ttl` annotation.
+func Export(ctx context.Context, client Client, prefix string) (*bundlev1.Bundle, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("etcd3: unable to export with nil client")
+	}
+
+	// Retrieve keys from prefix
+	basePath := packageKey(prefix, "")
+	if basePath != "" {
+		basePath += "/"
+	}
+	resp, err := client.Get(ctx, basePath, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return nil, fmt.Errorf("etcd3: unable to retrieve keys from '%s': %w", prefix, err)
+	}
+	if resp == nil {
+		return nil, fmt.Errorf("etcd3: got nil response for '%s'", prefix)
+	}
+
+	res := &bundlev1.Bundle{
+		Packages: []*bundlev1.Package{},
+	}
+	ttls := map[clientv3.LeaseID]int64{}
+	for _, item := range resp.Kvs {
+		// Resolve lease
+		var ttl int64
+		if item.Lease != 0 {
+			id := clientv3.LeaseID(item.Lease)
+			if _, ok := ttls[id]; !ok {
+				lease, err := client.TimeToLive(ctx, id)
+				if err != nil {
+					return nil, fmt.Errorf("etcd3: unable to retrieve '%s' lease: %w", item.Key, err)
+				}
+				ttls[id] = lease.TTL
+			}
+			ttl = ttls[id]
+
+			// Skip expired keys
+			if ttl <= 0 {
+				continue
+			}
+		}
+
+		// Decode package secrets
+		var secrets bundle.KV
+		if err := json.Unmarshal(item.Value, &secrets); err != nil {
+			return nil, fmt.Errorf("etcd3: unable to decode '%s' value as a JSON object: %w", item.Key, err)
+		}
+
+		// Pack secrets
+		data, err := bundle.FromSecretMap(secrets)
+		if err != nil {
+			return nil, fmt.Errorf("etcd3: unable to pack '%s' secrets: %w", item.Key, err)
+		}
+		sort.SliceStable(data, func(i, j int) bool {
+			return data[i].Key < data[j].Key
+		})
+
+		p := &bundlev1.Package{
+			Name: strings.TrimPrefix(string(item.Key), basePath),
+			Secrets: &bundlev1.SecretChain{
+				Data: data,
+			},
+		}
+		if ttl > 0 {
+			p.Annotations = map[string]string{
+				PackageTTLAnnotation: (time.Duration(ttl) * time.Second).String(),
+			}
+		}
+
+		res.Packages = append(res.Packages, p)
+	}
+	if len(res.Packages) == 0 {
+		return nil, kv.ErrKeyNotFound
+	}
+
+	// No error
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func packageKey(prefix, name string) string {
+	return strings.Trim(path.Join("/", prefix, name), "/")
+}
+
+func packageTTL(p *bundlev1.Package, defaultTTL time.Duration) (time.Duration, error) {
+	raw, ok := p.Annotations[PackageTTLAnnotation]
+	if !ok {
+		return defaultTTL, nil
+	}
+
+	// Parse duration
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("etcd3: invalid '%s' package ttl annotation: %w", p.Name, err)
+	}
+	if ttl < time.Second {
+		return 0, fmt.Errorf("etcd3: '%s' package ttl must be greater than or equal to 1s", p.Name)
+	}
+
+	// No error
+	return ttl, nil
+}
+
+// ttlSeconds returns the lease TTL in seconds, rounded up.
+func ttlSeconds(ttl time.Duration) int64 {
+	return int64((ttl + time.Second - 1) / time.Second)
+}
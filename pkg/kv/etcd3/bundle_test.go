@@ -0,0 +1,242 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package etcd3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/kv"
+)
+
+var _ Client = (*clientv3.Client)(nil)
+
+type memoryEntry struct {
+	value []byte
+	lease clientv3.LeaseID
+}
+
+// memoryClient is an in-memory etcd client.
+type memoryClient struct {
+	data      map[string]memoryEntry
+	leases    map[clientv3.LeaseID]int64
+	revoked   []clientv3.LeaseID
+	nextLease clientv3.LeaseID
+	txnCount  int
+	failTxn   bool
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{
+		data:   map[string]memoryEntry{},
+		leases: map[clientv3.LeaseID]int64{},
+	}
+}
+
+func (c *memoryClient) Get(_ context.Context, key string, _ ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	keys := []string{}
+	for k := range c.data {
+		if strings.HasPrefix(k, key) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	resp := &clientv3.GetResponse{}
+	for _, k := range keys {
+		resp.Kvs = append(resp.Kvs, &mvccpb.KeyValue{
+			Key:   []byte(k),
+			Value: c.data[k].value,
+			Lease: int64(c.data[k].lease),
+		})
+	}
+	return resp, nil
+}
+
+func (c *memoryClient) Txn(_ context.Context) clientv3.Txn {
+	return &memoryTxn{client: c}
+}
+
+func (c *memoryClient) Grant(_ context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	c.nextLease++
+	c.leases[c.nextLease] = ttl
+	return &clientv3.LeaseGrantResponse{ID: c.nextLease, TTL: ttl}, nil
+}
+
+func (c *memoryClient) Revoke(_ context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	c.revoked = append(c.revoked, id)
+	delete(c.leases, id)
+	return &clientv3.LeaseRevokeResponse{}, nil
+}
+
+func (c *memoryClient) TimeToLive(_ context.Context, id clientv3.LeaseID, _ ...clientv3.LeaseOption) (*clientv3.LeaseTimeToLiveResponse, error) {
+	ttl, ok := c.leases[id]
+	if !ok {
+		ttl = -1
+	}
+	return &clientv3.LeaseTimeToLiveResponse{ID: id, TTL: ttl}, nil
+}
+
+type memoryTxn struct {
+	client *memoryClient
+	ops    []clientv3.Op
+}
+
+func (t *memoryTxn) If(_ ...clientv3.Cmp) clientv3.Txn  { return t }
+func (t *memoryTxn) Else(_ ...clientv3.Op) clientv3.Txn { return t }
+
+func (t *memoryTxn) Then(ops ...clientv3.Op) clientv3.Txn {
+	t.ops = append(t.ops, ops...)
+	return t
+}
+
+func (t *memoryTxn) Commit() (*clientv3.TxnResponse, error) {
+	t.client.txnCount++
+	if t.client.failTxn {
+		return nil, errors.New("etcdserver: too many operations in txn request")
+	}
+	for _, op := range t.ops {
+		// The lease is not exposed by the operation API.
+		lease := reflect.ValueOf(op).FieldByName("leaseID").Int()
+		t.client.data[string(op.KeyBytes())] = memoryEntry{
+			value: op.ValueBytes(),
+			lease: clientv3.LeaseID(lease),
+		}
+	}
+	return &clientv3.TxnResponse{Succeeded: true}, nil
+}
+
+func testBundle(t *testing.T, count int) *bundlev1.Bundle {
+	t.Helper()
+
+	input := map[string]bundle.KV{}
+	for i := 0; i < count; i++ {
+		input[fmt.Sprintf("app/production/service-%03d", i)] = bundle.KV{
+			"user":     fmt.Sprintf("user-%d", i),
+			"password": "foo",
+		}
+	}
+
+	b, err := bundle.FromMap(input)
+	require.NoError(t, err)
+	sort.Slice(b.Packages, func(i, j int) bool {
+		return b.Packages[i].Name < b.Packages[j].Name
+	})
+
+	return b
+}
+
+// -----------------------------------------------------------------------------
+
+func TestImport_Export(t *testing.T) {
+	client := newMemoryClient()
+	in := testBundle(t, 3)
+	in.Packages[1].Annotations = map[string]string{PackageTTLAnnotation: "1h"}
+
+	leases, err := Import(context.Background(), client, in, WithPrefix("/harp/"), WithTTL(90*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, client.txnCount)
+	assert.Len(t, leases, 3)
+	assert.Equal(t, leases["app/production/service-000"], leases["app/production/service-002"])
+	assert.NotEqual(t, leases["app/production/service-000"], leases["app/production/service-001"])
+	assert.Equal(t, map[clientv3.LeaseID]int64{1: 90, 2: 3600}, client.leases)
+	assert.JSONEq(t, `{"password":"foo","user":"user-0"}`, string(client.data["harp/app/production/service-000"].value))
+
+	// Expire a lease
+	delete(client.leases, leases["app/production/service-000"])
+
+	out, err := Export(context.Background(), client, "harp")
+	assert.NoError(t, err)
+	require.Len(t, out.Packages, 1)
+	assert.Equal(t, "app/production/service-001", out.Packages[0].Name)
+	assert.Equal(t, "1h0m0s", out.Packages[0].Annotations[PackageTTLAnnotation])
+
+	secrets, err := bundle.AsSecretMap(out.Packages[0])
+	assert.NoError(t, err)
+	assert.Equal(t, bundle.KV{"user": "user-1", "password": "foo"}, secrets)
+}
+
+func TestImport_WithoutTTL(t *testing.T) {
+	client := newMemoryClient()
+
+	leases, err := Import(context.Background(), client, testBundle(t, 5), WithMaxTxnOperations(2))
+	assert.NoError(t, err)
+	assert.Empty(t, leases)
+	assert.Equal(t, 3, client.txnCount)
+	assert.Len(t, client.data, 5)
+
+	out, err := Export(context.Background(), client, "")
+	assert.NoError(t, err)
+	require.Len(t, out.Packages, 5)
+	assert.Empty(t, out.Packages[0].Annotations)
+}
+
+func TestImport_RevokeOnFailure(t *testing.T) {
+	client := newMemoryClient()
+	client.failTxn = true
+
+	_, err := Import(context.Background(), client, testBundle(t, 1), WithTTL(time.Minute))
+	assert.Error(t, err)
+	assert.Equal(t, []clientv3.LeaseID{1}, client.revoked)
+	assert.Empty(t, client.data)
+}
+
+func TestImport_InvalidTTL(t *testing.T) {
+	client := newMemoryClient()
+
+	_, err := Import(context.Background(), client, testBundle(t, 1), WithTTL(time.Millisecond))
+	assert.Error(t, err)
+
+	b := testBundle(t, 1)
+	b.Packages[0].Annotations = map[string]string{PackageTTLAnnotation: "forever"}
+	_, err = Import(context.Background(), client, b)
+	assert.Error(t, err)
+	assert.Equal(t, 0, client.txnCount)
+}
+
+func TestExport_Errors(t *testing.T) {
+	_, err := Export(context.Background(), nil, "harp")
+	assert.Error(t, err)
+
+	client := newMemoryClient()
+	_, err = Export(context.Background(), client, "harp")
+	assert.ErrorIs(t, err, kv.ErrKeyNotFound)
+
+	client.data["harp/invalid"] = memoryEntry{value: []byte("raw")}
+	_, err = Export(context.Background(), client, "harp")
+	assert.Error(t, err)
+}
+
+func Test_ttlSeconds(t *testing.T) {
+	assert.Equal(t, int64(1), ttlSeconds(time.Second))
+	assert.Equal(t, int64(2), ttlSeconds(1500*time.Millisecond))
+	assert.Equal(t, int64(3600), ttlSeconds(time.Hour))
+}
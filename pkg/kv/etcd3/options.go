@@ -0,0 +1,71 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package etcd3
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultMaxTxnOperations is the default etcd server limit of operations in a
+// single transaction (`--max-txn-ops`).
+const DefaultMaxTxnOperations = 128
+
+type options struct {
+	prefix    string
+	ttl       time.Duration
+	maxTxnOps int
+}
+
+// Option defines the functional pattern for bundle operation settings.
+type Option func(*options) error
+
+// WithPrefix add a prefix to package keys.
+func WithPrefix(value string) Option {
+	return func(opts *options) error {
+		opts.prefix = value
+		// No error
+		return nil
+	}
+}
+
+// WithTTL attaches a lease with the given TTL to all imported packages. The
+// package annotation `harp.elastic.co/v1/package#ttl` overrides this value.
+func WithTTL(value time.Duration) Option {
+	return func(opts *options) error {
+		if value < time.Second {
+			return errors.New("etcd3: ttl must be greater than or equal to 1s")
+		}
+		opts.ttl = value
+		// No error
+		return nil
+	}
+}
+
+// WithMaxTxnOperations sets the maximum operation count of a transaction, it
+// must match the etcd server `--max-txn-ops` setting.
+func WithMaxTxnOperations(value int) Option {
+	return func(opts *options) error {
+		if value <= 0 {
+			return errors.New("etcd3: max transaction operations must be strictly positive")
+		}
+		opts.maxTxnOps = value
+		// No error
+		return nil
+	}
+}
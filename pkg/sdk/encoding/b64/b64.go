@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package b64
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPadding is raised when the input contains padding characters.
+	ErrPadding = errors.New("b64: padding is not allowed")
+	// ErrInvalidCharacter is raised when the input contains a character out
+	// of the base64url alphabet.
+	ErrInvalidCharacter = errors.New("b64: invalid character")
+	// ErrNonCanonical is raised when the input has an invalid length or non
+	// zero trailing bits.
+	ErrNonCanonical = errors.New("b64: non canonical encoding")
+	// ErrTooLarge is raised when the decoded content would exceed the size
+	// limit.
+	ErrTooLarge = errors.New("b64: decoded content exceeds the size limit")
+)
+
+// strictURLEncoding rejects non canonical trailing bits, it is shared to
+// avoid an allocation per decoding.
+var strictURLEncoding = base64.RawURLEncoding.Strict()
+
+// DecodeURLNoPad decodes the given unpadded base64url (RFC 4648 section 5)
+// string. The decoded content must not exceed maxLen bytes.
+func DecodeURLNoPad(s string, maxLen int) ([]byte, error) {
+	return AppendDecodeURLNoPad(nil, []byte(s), maxLen)
+}
+
+// AppendDecodeURLNoPad appends the decoded unpadded base64url content of src
+// to dst and returns the extended buffer. dst is only grown when its capacity
+// is too small. The decoded content must not exceed maxLen bytes.
+func AppendDecodeURLNoPad(dst, src []byte, maxLen int) ([]byte, error) {
+	// Check alphabet
+	for i, c := range src {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_':
+		case c == '=':
+			return nil, ErrPadding
+		default:
+			return nil, fmt.Errorf("%w %q at offset %d", ErrInvalidCharacter, c, i)
+		}
+	}
+
+	// Check decoded size
+	size := base64.RawURLEncoding.DecodedLen(len(src))
+	if size > maxLen {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the %d bytes limit", ErrTooLarge, size, maxLen)
+	}
+
+	// Grow destination buffer
+	offset := len(dst)
+	if dst == nil || cap(dst)-offset < size {
+		grown := make([]byte, offset, offset+size)
+		copy(grown, dst)
+		dst = grown
+	}
+
+	// Decode content
+	n, err := strictURLEncoding.Decode(dst[offset:offset+size], src)
+	if err != nil {
+		return nil, ErrNonCanonical
+	}
+
+	// No error
+	return dst[:offset+n], nil
+}
@@ -0,0 +1,142 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package b64
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeURLNoPad(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		maxLen   int
+		expected []byte
+		wantErr  error
+	}{
+		{
+			name:     "empty",
+			input:    "",
+			maxLen:   0,
+			expected: []byte{},
+		},
+		{
+			name:     "valid",
+			input:    "_-8",
+			maxLen:   2,
+			expected: []byte{0xff, 0xef},
+		},
+		{
+			name:     "valid full block",
+			input:    "aGFycA",
+			maxLen:   64,
+			expected: []byte("harp"),
+		},
+		{
+			name:    "padded",
+			input:   "aGFycA==",
+			maxLen:  64,
+			wantErr: ErrPadding,
+		},
+		{
+			name:    "oversized",
+			input:   "aGFycA",
+			maxLen:  3,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "negative limit",
+			input:   "aGFycA",
+			maxLen:  -1,
+			wantErr: ErrTooLarge,
+		},
+		{
+			name:    "standard alphabet",
+			input:   "/+8",
+			maxLen:  64,
+			wantErr: ErrInvalidCharacter,
+		},
+		{
+			name:    "whitespace",
+			input:   "aGFy cA",
+			maxLen:  64,
+			wantErr: ErrInvalidCharacter,
+		},
+		{
+			name:    "newline",
+			input:   "aGFy\ncA",
+			maxLen:  64,
+			wantErr: ErrInvalidCharacter,
+		},
+		{
+			name:    "non canonical trailing bits",
+			input:   "aGFycB",
+			maxLen:  64,
+			wantErr: ErrNonCanonical,
+		},
+		{
+			name:    "truncated",
+			input:   "aGFyc",
+			maxLen:  64,
+			wantErr: ErrNonCanonical,
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := DecodeURLNoPad(testCase.input, testCase.maxLen)
+			if testCase.wantErr != nil {
+				assert.True(t, errors.Is(err, testCase.wantErr), "expected %v, got %v", testCase.wantErr, err)
+				assert.Nil(t, out)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, out)
+		})
+	}
+}
+
+func TestAppendDecodeURLNoPad(t *testing.T) {
+	// Reuse buffer capacity
+	buf := make([]byte, 0, 64)
+	buf = append(buf, "prefix:"...)
+	out, err := AppendDecodeURLNoPad(buf, []byte("aGFycA"), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "prefix:harp", string(out))
+	assert.Equal(t, &buf[:1][0], &out[0])
+
+	// Grow buffer
+	small := []byte("a:")
+	out, err = AppendDecodeURLNoPad(small, []byte("aGFycA"), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "a:harp", string(out))
+}
+
+func TestDecodeURLNoPad_RoundTrip(t *testing.T) {
+	for size := 0; size < 64; size++ {
+		in := bytes.Repeat([]byte{0xa5}, size)
+		out, err := DecodeURLNoPad(base64.RawURLEncoding.EncodeToString(in), size)
+		assert.NoError(t, err)
+		assert.Equal(t, in, out)
+	}
+}
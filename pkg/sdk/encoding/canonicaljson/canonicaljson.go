@@ -0,0 +1,314 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package canonicaljson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidJSON is raised when the input is not a single valid JSON value.
+	ErrInvalidJSON = errors.New("canonicaljson: invalid JSON input")
+	// ErrDuplicateKey is raised when an object has duplicated member names.
+	ErrDuplicateKey = errors.New("canonicaljson: duplicate object key")
+	// ErrInvalidNumber is raised when a number can't be represented as a
+	// finite IEEE-754 double.
+	ErrInvalidNumber = errors.New("canonicaljson: number is not a finite IEEE-754 double")
+	// ErrInexactInteger is raised when an integer can't be represented exactly
+	// as an IEEE-754 double, such values must be encoded as strings.
+	ErrInexactInteger = errors.New("canonicaljson: integer can't be represented exactly as an IEEE-754 double")
+)
+
+// Marshal returns the canonical JSON encoding of the given value. The value is
+// first encoded with `encoding/json` so that json tags and Marshaler
+// implementations are honored.
+func Marshal(v interface{}) ([]byte, error) {
+	// Encode as JSON
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("canonicaljson: unable to encode value: %w", err)
+	}
+
+	// Delegate to transformer
+	return Transform(raw)
+}
+
+// Transform returns the canonical form of the given JSON document.
+func Transform(in []byte) ([]byte, error) {
+	// Check arguments
+	if !utf8.Valid(in) {
+		return nil, fmt.Errorf("%w: invalid UTF-8 sequence", ErrInvalidJSON)
+	}
+
+	// Prepare decoder
+	dec := json.NewDecoder(bytes.NewReader(in))
+	dec.UseNumber()
+
+	// Serialize the value
+	var out bytes.Buffer
+	if err := writeValue(&out, dec); err != nil {
+		return nil, err
+	}
+
+	// Ensure there is no trailing content
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%w: trailing content after the JSON value", ErrInvalidJSON)
+	}
+
+	// No error
+	return out.Bytes(), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func writeValue(out *bytes.Buffer, dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			return writeObject(out, dec)
+		case '[':
+			return writeArray(out, dec)
+		default:
+			return fmt.Errorf("%w: unexpected delimiter '%s'", ErrInvalidJSON, v)
+		}
+	case nil:
+		out.WriteString("null")
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		s, err := formatNumber(v)
+		if err != nil {
+			return err
+		}
+		out.WriteString(s)
+	case string:
+		writeString(out, v)
+	default:
+		return fmt.Errorf("%w: unexpected token %T", ErrInvalidJSON, tok)
+	}
+
+	// No error
+	return nil
+}
+
+func writeObject(out *bytes.Buffer, dec *json.Decoder) error {
+	members := map[string][]byte{}
+	for dec.More() {
+		// Decode member name
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("%w: object key must be a string", ErrInvalidJSON)
+		}
+		if _, ok := members[key]; ok {
+			return fmt.Errorf("%w: '%s'", ErrDuplicateKey, key)
+		}
+
+		// Serialize member value
+		var value bytes.Buffer
+		if err := writeValue(&value, dec); err != nil {
+			return err
+		}
+		members[key] = value.Bytes()
+	}
+
+	// Consume closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	// Sort member names by UTF-16 code units
+	keys := make([]string, 0, len(members))
+	for k := range members {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return lessUTF16(keys[i], keys[j])
+	})
+
+	// Serialize members
+	out.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		writeString(out, k)
+		out.WriteByte(':')
+		out.Write(members[k])
+	}
+	out.WriteByte('}')
+
+	// No error
+	return nil
+}
+
+func writeArray(out *bytes.Buffer, dec *json.Decoder) error {
+	out.WriteByte('[')
+	for i := 0; dec.More(); i++ {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		if err := writeValue(out, dec); err != nil {
+			return err
+		}
+	}
+	out.WriteByte(']')
+
+	// Consume closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidJSON, err)
+	}
+
+	// No error
+	return nil
+}
+
+// writeString serializes the string with the minimal JSON escaping
+// (RFC 8785 section 3.2.2.2).
+func writeString(out *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	out.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '"':
+			out.WriteString(`\"`)
+		case c == '\\':
+			out.WriteString(`\\`)
+		case c == '\b':
+			out.WriteString(`\b`)
+		case c == '\f':
+			out.WriteString(`\f`)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c < 0x20:
+			out.WriteString(`\u00`)
+			out.WriteByte(hex[c>>4])
+			out.WriteByte(hex[c&0xF])
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte('"')
+}
+
+// formatNumber serializes the number as ECMAScript Number.prototype.toString
+// does (RFC 8785 section 3.2.2.3).
+func formatNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) || math.IsNaN(f) {
+		return "", fmt.Errorf("%w: '%s'", ErrInvalidNumber, n)
+	}
+
+	// Reject integers silently rounded by the double conversion
+	if !strings.ContainsAny(string(n), ".eE") {
+		i, ok := new(big.Int).SetString(string(n), 10)
+		if !ok {
+			return "", fmt.Errorf("%w: '%s'", ErrInvalidNumber, n)
+		}
+		if exact, _ := new(big.Float).SetFloat64(f).Int(nil); i.Cmp(exact) != 0 {
+			return "", fmt.Errorf("%w: '%s'", ErrInexactInteger, n)
+		}
+	}
+
+	return formatFloat(f), nil
+}
+
+func formatFloat(f float64) string {
+	// Negative zero is serialized as zero
+	if f == 0 {
+		return "0"
+	}
+
+	sign := ""
+	if f < 0 {
+		sign = "-"
+		f = -f
+	}
+
+	// Extract the shortest round-trip decimal digits and exponent, the value
+	// is 0.<digits> * 10^n.
+	parts := strings.SplitN(strconv.FormatFloat(f, 'e', -1, 64), "e", 2)
+	digits := strings.Replace(parts[0], ".", "", 1)
+	e, _ := strconv.Atoi(parts[1])
+	k, n := len(digits), e+1
+
+	switch {
+	case k <= n && n <= 21:
+		return sign + digits + strings.Repeat("0", n-k)
+	case 0 < n && n <= 21:
+		return sign + digits[:n] + "." + digits[n:]
+	case -6 < n && n <= 0:
+		return sign + "0." + strings.Repeat("0", -n) + digits
+	}
+
+	// Exponential notation
+	expSign := "+"
+	if n-1 < 0 {
+		expSign = "-"
+	}
+	exponent := expSign + strconv.Itoa(abs(n-1))
+	if k == 1 {
+		return sign + digits + "e" + exponent
+	}
+
+	return sign + digits[:1] + "." + digits[1:] + "e" + exponent
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// lessUTF16 compares strings using their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
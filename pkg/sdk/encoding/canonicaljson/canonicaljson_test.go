@@ -0,0 +1,185 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package canonicaljson
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RFC 8785 appendix B.
+func TestFormatFloat_Vectors(t *testing.T) {
+	testCases := []struct {
+		bits     string
+		expected string
+	}{
+		{"0000000000000000", "0"},
+		{"8000000000000000", "0"},
+		{"0000000000000001", "5e-324"},
+		{"8000000000000001", "-5e-324"},
+		{"7fefffffffffffff", "1.7976931348623157e+308"},
+		{"ffefffffffffffff", "-1.7976931348623157e+308"},
+		{"4340000000000000", "9007199254740992"},
+		{"c340000000000000", "-9007199254740992"},
+		{"4430000000000000", "295147905179352830000"},
+		{"44b52d02c7e14af5", "9.999999999999997e+22"},
+		{"44b52d02c7e14af6", "1e+23"},
+		{"44b52d02c7e14af7", "1.0000000000000001e+23"},
+		{"444b1ae4d6e2ef4e", "999999999999999700000"},
+		{"444b1ae4d6e2ef4f", "999999999999999900000"},
+		{"444b1ae4d6e2ef50", "1e+21"},
+		{"3eb0c6f7a0b5ed8c", "9.999999999999997e-7"},
+		{"3eb0c6f7a0b5ed8d", "0.000001"},
+		{"41b3de4355555553", "333333333.3333332"},
+		{"41b3de4355555554", "333333333.33333325"},
+		{"41b3de4355555555", "333333333.3333333"},
+		{"41b3de4355555556", "333333333.3333334"},
+		{"41b3de4355555557", "333333333.33333343"},
+		{"becbf647612f3696", "-0.0000033333333333333333"},
+		{"43143ff3c1cb0959", "1424953923781206.2"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.bits, func(t *testing.T) {
+			bits, err := strconv.ParseUint(testCase.bits, 16, 64)
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, formatFloat(math.Float64frombits(bits)))
+		})
+	}
+}
+
+func TestTransform(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			// RFC 8785 section 3.2.2
+			name: "rfc8785 values",
+			input: `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			expected: `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			// RFC 8785 section 3.2.3
+			name: "rfc8785 sorting",
+			input: `{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			expected: "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001f600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{
+			name:     "nested",
+			input:    ` { "b" : [ { "d" : 1 , "c" : "<&>" } ] , "a" : { } , "e" : [ ] } `,
+			expected: `{"a":{},"b":[{"c":"<&>","d":1}],"e":[]}`,
+		},
+		{
+			name:     "scalar",
+			input:    `-0.0`,
+			expected: `0`,
+		},
+		{
+			name:  "duplicate key",
+			input: `{"a":1,"a":2}`,
+			err:   ErrDuplicateKey,
+		},
+		{
+			name:  "number overflow",
+			input: `[1e400]`,
+			err:   ErrInvalidNumber,
+		},
+		{
+			name:     "exact integer",
+			input:    `[9007199254740992, -9007199254740991, 100000000000000000000]`,
+			expected: `[9007199254740992,-9007199254740991,100000000000000000000]`,
+		},
+		{
+			name:  "inexact integer",
+			input: `[9007199254740993]`,
+			err:   ErrInexactInteger,
+		},
+		{
+			name:  "trailing content",
+			input: `{} {}`,
+			err:   ErrInvalidJSON,
+		},
+		{
+			name:  "syntax error",
+			input: `{"a":}`,
+			err:   ErrInvalidJSON,
+		},
+		{
+			name:  "empty",
+			input: ``,
+			err:   ErrInvalidJSON,
+		},
+		{
+			name:  "invalid utf-8",
+			input: "\"\xff\"",
+			err:   ErrInvalidJSON,
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := Transform([]byte(testCase.input))
+			if testCase.err != nil {
+				assert.True(t, errors.Is(err, testCase.err), "expected %v, got %v", testCase.err, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, string(out))
+
+			// Canonical form is a fixed point
+			again, err := Transform(out)
+			assert.NoError(t, err)
+			assert.Equal(t, out, again)
+		})
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	type payload struct {
+		Subject string  `json:"sub"`
+		Amount  float64 `json:"amount"`
+		Admin   bool    `json:"admin,omitempty"`
+	}
+
+	out, err := Marshal(payload{Subject: "<user>", Amount: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":10,"sub":"<user>"}`, string(out))
+
+	_, err = Marshal(make(chan int))
+	assert.Error(t, err)
+}
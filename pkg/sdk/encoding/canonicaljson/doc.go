@@ -0,0 +1,29 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package canonicaljson implements the JSON Canonicalization Scheme (JCS)
+// defined by RFC 8785.
+//
+// The canonical form has no whitespace, object members sorted by their UTF-16
+// code units, strings with the minimal escaping, and numbers serialized as
+// ECMAScript does for IEEE-754 doubles. Two semantically identical JSON values
+// have the same canonical byte form, so that it can be signed or used as
+// authenticated data whatever the system which produced it.
+//
+// JCS numbers are IEEE-754 doubles, integers beyond 2^53 lose precision; use
+// strings to transport them.
+package canonicaljson
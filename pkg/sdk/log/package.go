@@ -44,6 +44,15 @@ func SetLoggerFactory(instance LoggerFactory) {
 	defaultFactory = instance
 }
 
+// SetLogger defines the default package logger from the given zap logger, a nil
+// logger restores the no-op logger.
+func SetLogger(l *zap.Logger) {
+	if l == nil {
+		l = zap.NewNop()
+	}
+	SetLoggerFactory(NewFactory(l))
+}
+
 // -----------------------------------------------------------------------------
 
 // Bg delegates a no-context logger
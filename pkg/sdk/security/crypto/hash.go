@@ -0,0 +1,130 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/kdf"
+)
+
+const (
+	argon2idTime    = 3
+	argon2idMemory  = 64 * 1024
+	argon2idThreads = 4
+	argon2idKeyLen  = 32
+	argon2idSaltLen = 16
+	argon2idMaxHash = 64
+)
+
+// -----------------------------------------------------------------------------
+
+// Bcrypt returns the bcrypt hash of the given password using the given cost.
+func Bcrypt(cost int, password string) (string, error) {
+	// Check arguments
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return "", fmt.Errorf("invalid bcrypt cost %d, it must be between %d and %d", cost, bcrypt.MinCost, bcrypt.MaxCost)
+	}
+
+	// Compute hash
+	h, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", fmt.Errorf("unable to compute bcrypt hash: %w", err)
+	}
+
+	// No error
+	return string(h), nil
+}
+
+// Argon2id returns the argon2id hash of the given password encoded using the
+// PHC string format with a random salt.
+func Argon2id(password string) (string, error) {
+	// Generate salt
+	salt := make([]byte, argon2idSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("unable to generate argon2id salt: %w", err)
+	}
+
+	// Compute hash
+	h := argon2.IDKey([]byte(password), salt, argon2idTime, argon2idMemory, argon2idThreads, argon2idKeyLen)
+
+	// No error
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, argon2idMemory, argon2idTime, argon2idThreads,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(h),
+	), nil
+}
+
+// VerifyArgon2id checks the given password against the given PHC encoded
+// argon2id hash.
+func VerifyArgon2id(encoded, password string) (bool, error) {
+	// Split PHC string
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, errors.New("unable to decode argon2id hash: invalid format")
+	}
+
+	// Decode parameters
+	var (
+		version int
+		params  kdf.Parameters
+	)
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return false, fmt.Errorf("unable to decode argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return false, fmt.Errorf("unable to decode argon2id parameters: %w", err)
+	}
+	if err := kdf.Default().Validate(params); err != nil {
+		return false, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+
+	// Decode salt and hash
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, fmt.Errorf("unable to decode argon2id salt: %w", err)
+	}
+	expected, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return false, fmt.Errorf("unable to decode argon2id hash: %w", err)
+	}
+	if len(expected) == 0 || len(expected) > argon2idMaxHash {
+		return false, fmt.Errorf("unable to decode argon2id hash: hash must be between 1 and %d bytes long", argon2idMaxHash)
+	}
+
+	// Compute hash
+	h, err := kdf.Default().Derive([]byte(password), salt, params, len(expected))
+	if err != nil {
+		return false, fmt.Errorf("unable to compute argon2id hash: %w", err)
+	}
+
+	// No error
+	return subtle.ConstantTimeCompare(h, expected) == 1, nil
+}
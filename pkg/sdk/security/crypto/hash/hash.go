@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hash
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	stdhash "hash"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// SHA256 is the SHA-2 256 bits algorithm name.
+	SHA256 = "sha256"
+	// SHA512 is the SHA-2 512 bits algorithm name.
+	SHA512 = "sha512"
+	// SHA3_256 is the SHA-3 256 bits algorithm name.
+	SHA3_256 = "sha3-256"
+	// SHA3_512 is the SHA-3 512 bits algorithm name.
+	SHA3_512 = "sha3-512"
+	// Blake2b256 is the Blake2b 256 bits algorithm name.
+	Blake2b256 = "blake2b-256"
+	// Blake2b512 is the Blake2b 512 bits algorithm name.
+	Blake2b512 = "blake2b-512"
+	// Blake2s256 is the Blake2s 256 bits algorithm name.
+	Blake2s256 = "blake2s-256"
+	// Blake2s is an alias of Blake2s256.
+	Blake2s = "blake2s"
+)
+
+// ErrUnknownAlgorithm is raised when the requested algorithm is not registered.
+var ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+
+// FactoryFunc returns a new hash function instance.
+type FactoryFunc func() stdhash.Hash
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]FactoryFunc{}
+)
+
+func init() {
+	Register(SHA256, sha256.New)
+	Register(SHA512, sha512.New)
+	Register(SHA3_256, sha3.New256)
+	Register(SHA3_512, sha3.New512)
+	Register(Blake2b256, mustKeyless(blake2b.New256))
+	Register(Blake2b512, mustKeyless(blake2b.New512))
+	Register(Blake2s256, mustKeyless(blake2s.New256))
+	Register(Blake2s, mustKeyless(blake2s.New256))
+}
+
+// Register a hash algorithm with the given name. Names are case-insensitive.
+func Register(name string, factory FactoryFunc) {
+	name = strings.ToLower(name)
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	// Check if not already registered
+	if _, ok := registry[name]; ok {
+		panic(fmt.Errorf("hash algorithm already registered for '%s'", name))
+	}
+	if factory == nil {
+		panic(fmt.Errorf("hash algorithm factory for '%s' is nil", name))
+	}
+
+	// Register the algorithm
+	registry[name] = factory
+}
+
+// Algorithms returns the sorted registered algorithm names.
+func Algorithms() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// New returns a hash function instance for the given algorithm.
+func New(algo string) (stdhash.Hash, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(algo)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownAlgorithm, algo)
+	}
+
+	return factory(), nil
+}
+
+// Sum returns the digest of the given data using the given algorithm.
+func Sum(algo string, data []byte) ([]byte, error) {
+	h, err := New(algo)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash data
+	h.Write(data)
+
+	// No error
+	return h.Sum(nil), nil
+}
+
+// Verify recomputes the data digest and compares it to the expected one in
+// constant time.
+func Verify(algo string, data, expected []byte) (bool, error) {
+	actual, err := Sum(algo, data)
+	if err != nil {
+		return false, err
+	}
+
+	return subtle.ConstantTimeCompare(actual, expected) == 1, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func mustKeyless(f func([]byte) (stdhash.Hash, error)) FactoryFunc {
+	return func() stdhash.Hash {
+		h, err := f(nil)
+		if err != nil {
+			// Can't fail without key
+			panic(err)
+		}
+		return h
+	}
+}
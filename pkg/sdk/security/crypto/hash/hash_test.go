@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hash
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSum(t *testing.T) {
+	testCases := []struct {
+		algo string
+		want string
+	}{
+		{algo: SHA256, want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{algo: "SHA256", want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"},
+		{algo: SHA512, want: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f"},
+		{algo: SHA3_256, want: "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532"},
+		{algo: Blake2b512, want: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{algo: Blake2s, want: "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"},
+		{algo: Blake2s256, want: "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.algo, func(t *testing.T) {
+			got, err := Sum(testCase.algo, []byte("abc"))
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, hex.EncodeToString(got))
+		})
+	}
+
+	got, err := Sum(Blake2b256, []byte("abc"))
+	assert.NoError(t, err)
+	assert.Len(t, got, 32)
+
+	_, err = Sum("md5", []byte("abc"))
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+}
+
+func TestVerify(t *testing.T) {
+	digest, err := Sum(SHA3_256, []byte("payload"))
+	assert.NoError(t, err)
+
+	ok, err := Verify(SHA3_256, []byte("payload"), digest)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = Verify(SHA3_256, []byte("tampered"), digest)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = Verify(SHA3_256, []byte("payload"), digest[:16])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = Verify("unknown", []byte("payload"), digest)
+	assert.Error(t, err)
+}
+
+func TestRegister(t *testing.T) {
+	assert.Contains(t, Algorithms(), Blake2b512)
+
+	// Duplicate registration
+	assert.Panics(t, func() {
+		Register("SHA256", nil)
+	})
+	assert.Panics(t, func() {
+		Register("test-nil", nil)
+	})
+}
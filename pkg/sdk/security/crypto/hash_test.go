@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package crypto
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBcrypt(t *testing.T) {
+	h, err := Bcrypt(bcrypt.MinCost, "foo")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(h, "$2a$04$"))
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(h), []byte("foo")))
+
+	_, err = Bcrypt(bcrypt.MaxCost+1, "foo")
+	assert.Error(t, err)
+}
+
+func TestArgon2id(t *testing.T) {
+	h, err := Argon2id("foo")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(h, "$argon2id$v=19$m=65536,t=3,p=4$"))
+
+	// Random salt
+	h2, err := Argon2id("foo")
+	assert.NoError(t, err)
+	assert.NotEqual(t, h, h2)
+
+	ok, err := VerifyArgon2id(h, "foo")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyArgon2id(h, "bar")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = VerifyArgon2id("$argon2i$v=19$m=65536,t=3,p=4$c2FsdA$aGFzaA", "foo")
+	assert.Error(t, err)
+}
+
+func TestVerifyArgon2id_InvalidParameters(t *testing.T) {
+	longHash := strings.Repeat("A", 88)
+	for _, encoded := range []string{
+		"$argon2id$v=19$m=65536,t=0,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=3,p=0$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=16,t=3,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=4294967295,t=3,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=65536,t=4294967295,p=4$c2FsdA$aGFzaA",
+		"$argon2id$v=19$m=64,t=1,p=1$c2FsdA$" + longHash,
+	} {
+		ok, err := VerifyArgon2id(encoded, "foo")
+		assert.Error(t, err, encoded)
+		assert.False(t, ok)
+	}
+}
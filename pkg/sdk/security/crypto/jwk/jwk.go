@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jwk
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	jose "gopkg.in/square/go-jose.v2"
+
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// ErrUnsupportedKey is raised when the key type can't be converted.
+var ErrUnsupportedKey = errors.New("jwk: unsupported key type")
+
+// ToJWK encodes the given key as a JWK JSON document. Supported keys are
+// Ed25519 (OKP), ECDSA (EC) and symmetric keys as []byte (oct). The RFC 7638
+// thumbprint is used as key identifier when kid is blank.
+func ToJWK(key interface{}, kid string) ([]byte, error) {
+	// Check arguments
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	// Compute key identifier
+	if kid == "" {
+		var err error
+		if kid, err = Thumbprint(key); err != nil {
+			return nil, err
+		}
+	}
+
+	// Wrap key
+	k := jose.JSONWebKey{Key: key, KeyID: kid}
+
+	// Delegate to go-jose
+	out, err := k.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("jwk: unable to encode key: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
+
+// FromJWK decodes the given JWK JSON document and returns the key and its
+// identifier.
+func FromJWK(data []byte) (key interface{}, kid string, err error) {
+	var k jose.JSONWebKey
+
+	// Decode JWK
+	if err := k.UnmarshalJSON(data); err != nil {
+		return nil, "", fmt.Errorf("jwk: unable to decode key: %w", err)
+	}
+
+	// Check key type
+	if err := checkKey(k.Key); err != nil {
+		return nil, "", err
+	}
+
+	// No error
+	return k.Key, k.KeyID, nil
+}
+
+// Thumbprint returns the base64url encoded RFC 7638 SHA-256 thumbprint of the
+// given key. Private keys share the thumbprint of their public key.
+func Thumbprint(key interface{}) (string, error) {
+	// Check arguments
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+
+	var digest []byte
+	switch k := key.(type) {
+	case []byte:
+		// Required members in lexicographic order
+		input, err := json.Marshal(struct {
+			K   string `json:"k"`
+			Kty string `json:"kty"`
+		}{
+			K:   base64.RawURLEncoding.EncodeToString(k),
+			Kty: "oct",
+		})
+		if err != nil {
+			return "", fmt.Errorf("jwk: unable to prepare thumbprint input: %w", err)
+		}
+		sum := sha256.Sum256(input)
+		digest = sum[:]
+	default:
+		var err error
+		wrapper := jose.JSONWebKey{Key: key}
+		if digest, err = wrapper.Thumbprint(crypto.SHA256); err != nil {
+			return "", fmt.Errorf("jwk: unable to compute thumbprint: %w", err)
+		}
+	}
+
+	// No error
+	return base64.RawURLEncoding.EncodeToString(digest), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func checkKey(key interface{}) error {
+	if types.IsNil(key) {
+		return errors.New("jwk: key is nil")
+	}
+
+	switch k := key.(type) {
+	case ed25519.PublicKey, ed25519.PrivateKey, *ecdsa.PublicKey, *ecdsa.PrivateKey:
+		return nil
+	case []byte:
+		if len(k) == 0 {
+			return errors.New("jwk: symmetric key is empty")
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+}
@@ -0,0 +1,111 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jwk
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 8037 - Appendix A.3
+const (
+	rfc8037PublicKey  = "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"
+	rfc8037Thumbprint = "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k"
+)
+
+func TestThumbprint_RFC8037(t *testing.T) {
+	raw, err := base64.RawURLEncoding.DecodeString(rfc8037PublicKey)
+	assert.NoError(t, err)
+
+	kid, err := Thumbprint(ed25519.PublicKey(raw))
+	assert.NoError(t, err)
+	assert.Equal(t, rfc8037Thumbprint, kid)
+
+	// Default key identifier
+	out, err := ToJWK(ed25519.PublicKey(raw), "")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kty":"OKP","crv":"Ed25519","x":"`+rfc8037PublicKey+`","kid":"`+rfc8037Thumbprint+`"}`, string(out))
+}
+
+func TestRoundTrip(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ecPriv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		key  interface{}
+		kty  string
+	}{
+		{name: "ed25519 private", key: edPriv, kty: "OKP"},
+		{name: "ed25519 public", key: edPriv.Public(), kty: "OKP"},
+		{name: "ecdsa private", key: ecPriv, kty: "EC"},
+		{name: "ecdsa public", key: &ecPriv.PublicKey, kty: "EC"},
+		{name: "symmetric", key: []byte("0123456789abcdef0123456789abcdef"), kty: "oct"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := ToJWK(testCase.key, "my-key")
+			assert.NoError(t, err)
+
+			var fields map[string]interface{}
+			assert.NoError(t, json.Unmarshal(out, &fields))
+			assert.Equal(t, testCase.kty, fields["kty"])
+
+			key, kid, err := FromJWK(out)
+			assert.NoError(t, err)
+			assert.Equal(t, "my-key", kid)
+			assert.Equal(t, testCase.key, key)
+		})
+	}
+
+	// Private and public keys share the same thumbprint
+	privKid, err := Thumbprint(edPriv)
+	assert.NoError(t, err)
+	pubKid, err := Thumbprint(edPriv.Public())
+	assert.NoError(t, err)
+	assert.Equal(t, pubKid, privKid)
+}
+
+func TestUnsupported(t *testing.T) {
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	_, err = ToJWK(rsaPriv, "")
+	assert.True(t, errors.Is(err, ErrUnsupportedKey))
+	_, err = ToJWK(nil, "")
+	assert.Error(t, err)
+	_, err = ToJWK([]byte{}, "")
+	assert.Error(t, err)
+
+	_, _, err = FromJWK([]byte(`{"kty":"RSA","n":"AQAB","e":"AQAB"}`))
+	assert.Error(t, err)
+	_, _, err = FromJWK([]byte(`{`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kdf
+
+import (
+	"fmt"
+	"math"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2idID            byte = 0x00
+	maxArgon2idIterations      = 64
+)
+
+func init() {
+	Register(argon2idKDF{})
+}
+
+type argon2idKDF struct{}
+
+func (argon2idKDF) Name() string { return Argon2id }
+
+func (argon2idKDF) ID() byte { return argon2idID }
+
+func (argon2idKDF) DefaultParameters() Parameters {
+	return Parameters{
+		Iterations:  3,
+		Memory:      64 * 1024,
+		Parallelism: 4,
+	}
+}
+
+func (argon2idKDF) Validate(p Parameters) error {
+	switch {
+	case p.Iterations < 1 || p.Iterations > maxArgon2idIterations:
+		return fmt.Errorf("%w: argon2id iterations must be between 1 and %d", ErrInvalidParameters, maxArgon2idIterations)
+	case p.Parallelism < 1 || p.Parallelism > math.MaxUint8:
+		return fmt.Errorf("%w: argon2id parallelism must be between 1 and %d", ErrInvalidParameters, math.MaxUint8)
+	case p.Memory < 8*p.Parallelism || p.Memory > MaxMemory:
+		return fmt.Errorf("%w: argon2id memory must be between %d and %d KiB", ErrInvalidParameters, 8*p.Parallelism, MaxMemory)
+	}
+
+	// No error
+	return nil
+}
+
+func (a argon2idKDF) Derive(password, salt []byte, p Parameters, keyLen int) ([]byte, error) {
+	// Check arguments
+	if err := a.Validate(p); err != nil {
+		return nil, err
+	}
+
+	// No error
+	return argon2.IDKey(password, salt, p.Iterations, p.Memory, uint8(p.Parallelism), uint32(keyLen)), nil
+}
@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kdf
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Registry(t *testing.T) {
+	for _, name := range []string{Argon2id, Scrypt, PBKDF2SHA256} {
+		a, err := Get(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, a.Name())
+
+		b, err := GetByID(a.ID())
+		assert.NoError(t, err)
+		assert.Equal(t, a, b)
+
+		assert.NoError(t, a.Validate(a.DefaultParameters()))
+	}
+
+	assert.Equal(t, Argon2id, Default().Name())
+
+	_, err := Get("bcrypt")
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+	_, err = GetByID(0xFF)
+	assert.True(t, errors.Is(err, ErrUnknownAlgorithm))
+
+	assert.Panics(t, func() {
+		Register(scryptKDF{})
+	})
+}
+
+func Test_Derive(t *testing.T) {
+	testCases := []struct {
+		name     string
+		alg      string
+		password string
+		salt     string
+		params   Parameters
+		keyLen   int
+		expected string
+	}{
+		{
+			// RFC 7914 - section 12 (N=1024, r=8, p=16)
+			name:     "scrypt",
+			alg:      Scrypt,
+			password: "password",
+			salt:     "NaCl",
+			params:   Parameters{Iterations: 1024, Memory: 1024, Parallelism: 16},
+			keyLen:   64,
+			expected: "fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640",
+		},
+		{
+			// hashlib.pbkdf2_hmac reference value
+			name:     "pbkdf2-sha256",
+			alg:      PBKDF2SHA256,
+			password: "passwd",
+			salt:     "salt",
+			params:   Parameters{Iterations: 1000},
+			keyLen:   32,
+			expected: "fa9c158e678bfe94189b0b1a707a0921672fdc9f11fa978805969df312c2c8ef",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			a, err := Get(testCase.alg)
+			assert.NoError(t, err)
+
+			out, err := a.Derive([]byte(testCase.password), []byte(testCase.salt), testCase.params, testCase.keyLen)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expected, hex.EncodeToString(out))
+		})
+	}
+}
+
+func Test_Validate(t *testing.T) {
+	testCases := []struct {
+		name   string
+		alg    string
+		params Parameters
+	}{
+		{name: "argon2id zero iterations", alg: Argon2id, params: Parameters{Iterations: 0, Memory: 64, Parallelism: 1}},
+		{name: "argon2id low memory", alg: Argon2id, params: Parameters{Iterations: 1, Memory: 4, Parallelism: 1}},
+		{name: "argon2id high memory", alg: Argon2id, params: Parameters{Iterations: 1, Memory: MaxMemory + 1, Parallelism: 1}},
+		{name: "argon2id zero parallelism", alg: Argon2id, params: Parameters{Iterations: 1, Memory: 64, Parallelism: 0}},
+		{name: "scrypt cost not power of 2", alg: Scrypt, params: Parameters{Iterations: 1000, Memory: 1000, Parallelism: 1}},
+		{name: "scrypt invalid block size", alg: Scrypt, params: Parameters{Iterations: 1024, Memory: 100, Parallelism: 1}},
+		{name: "scrypt high memory", alg: Scrypt, params: Parameters{Iterations: 1024, Memory: 2 * MaxMemory, Parallelism: 1}},
+		{name: "scrypt parallel blocks", alg: Scrypt, params: Parameters{Iterations: 2, Memory: MaxMemory, Parallelism: 16}},
+		{name: "scrypt working buffers", alg: Scrypt, params: Parameters{Iterations: 1024, Memory: MaxMemory, Parallelism: 1}},
+		{name: "scrypt zero parallelism", alg: Scrypt, params: Parameters{Iterations: 1024, Memory: 1024, Parallelism: 0}},
+		{name: "pbkdf2 low iterations", alg: PBKDF2SHA256, params: Parameters{Iterations: 1}},
+		{name: "pbkdf2 memory", alg: PBKDF2SHA256, params: Parameters{Iterations: 1000, Memory: 64}},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			a, err := Get(testCase.alg)
+			assert.NoError(t, err)
+
+			err = a.Validate(testCase.params)
+			assert.True(t, errors.Is(err, ErrInvalidParameters))
+
+			_, err = a.Derive([]byte("password"), []byte("salt"), testCase.params, 32)
+			assert.True(t, errors.Is(err, ErrInvalidParameters))
+		})
+	}
+}
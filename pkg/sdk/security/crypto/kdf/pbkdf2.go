@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kdf
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pbkdf2SHA256ID      byte = 0x02
+	minPBKDF2Iterations      = 1000
+	maxPBKDF2Iterations      = 10000000
+)
+
+func init() {
+	Register(pbkdf2SHA256KDF{})
+}
+
+type pbkdf2SHA256KDF struct{}
+
+func (pbkdf2SHA256KDF) Name() string { return PBKDF2SHA256 }
+
+func (pbkdf2SHA256KDF) ID() byte { return pbkdf2SHA256ID }
+
+func (pbkdf2SHA256KDF) DefaultParameters() Parameters {
+	return Parameters{
+		Iterations: 600000,
+	}
+}
+
+func (pbkdf2SHA256KDF) Validate(p Parameters) error {
+	switch {
+	case p.Iterations < minPBKDF2Iterations || p.Iterations > maxPBKDF2Iterations:
+		return fmt.Errorf("%w: pbkdf2 iterations must be between %d and %d", ErrInvalidParameters, minPBKDF2Iterations, maxPBKDF2Iterations)
+	case p.Memory != 0 || p.Parallelism != 0:
+		return fmt.Errorf("%w: pbkdf2 doesn't support memory and parallelism costs", ErrInvalidParameters)
+	}
+
+	// No error
+	return nil
+}
+
+func (a pbkdf2SHA256KDF) Derive(password, salt []byte, p Parameters, keyLen int) ([]byte, error) {
+	// Check arguments
+	if err := a.Validate(p); err != nil {
+		return nil, err
+	}
+
+	// No error
+	return pbkdf2.Key(password, salt, int(p.Iterations), keyLen, sha256.New), nil
+}
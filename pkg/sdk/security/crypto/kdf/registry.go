@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kdf
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// Argon2id is the default password based key derivation function.
+	Argon2id = "argon2id"
+	// Scrypt is the RFC 7914 password based key derivation function.
+	Scrypt = "scrypt"
+	// PBKDF2SHA256 is the RFC 8018 PBKDF2 function using HMAC-SHA256.
+	PBKDF2SHA256 = "pbkdf2-sha256"
+
+	// MaxMemory is the maximum memory cost in KiB accepted by the built-in
+	// algorithms (1 GiB).
+	MaxMemory uint32 = 1024 * 1024
+)
+
+var (
+	// ErrUnknownAlgorithm is raised when the requested algorithm is not
+	// registered.
+	ErrUnknownAlgorithm = errors.New("kdf: unknown algorithm")
+	// ErrInvalidParameters is raised when the derivation parameters are not
+	// acceptable for the algorithm.
+	ErrInvalidParameters = errors.New("kdf: invalid parameters")
+)
+
+// Parameters describes the derivation cost parameters, their meaning depends
+// on the algorithm.
+type Parameters struct {
+	// Iterations is the time cost (Argon2id passes, scrypt N, PBKDF2 rounds).
+	Iterations uint32
+	// Memory is the memory cost in KiB (Argon2id memory, scrypt 128*r*N/1024),
+	// it must be zero for PBKDF2.
+	Memory uint32
+	// Parallelism is the parallelism degree (Argon2id lanes, scrypt p), it
+	// must be zero for PBKDF2.
+	Parallelism uint32
+}
+
+// Algorithm describes a password based key derivation function.
+type Algorithm interface {
+	// Name returns the algorithm name used in textual headers.
+	Name() string
+	// ID returns the algorithm identifier used in binary headers.
+	ID() byte
+	// DefaultParameters returns the recommended derivation parameters.
+	DefaultParameters() Parameters
+	// Validate checks the given parameters, it must be called before deriving
+	// a key from untrusted parameters.
+	Validate(p Parameters) error
+	// Derive a key of the given length from the password and salt.
+	Derive(password, salt []byte, p Parameters, keyLen int) ([]byte, error)
+}
+
+// -----------------------------------------------------------------------------
+
+var (
+	byName map[string]Algorithm
+	byID   map[byte]Algorithm
+)
+
+// Register an algorithm, it panics if the name or the identifier is already
+// registered.
+func Register(a Algorithm) {
+	// Lazy initialization
+	if byName == nil {
+		byName = map[string]Algorithm{}
+		byID = map[byte]Algorithm{}
+	}
+
+	// Check if not already registered
+	if _, ok := byName[a.Name()]; ok {
+		panic(fmt.Errorf("kdf algorithm already registered for '%s' name", a.Name()))
+	}
+	if _, ok := byID[a.ID()]; ok {
+		panic(fmt.Errorf("kdf algorithm already registered for '%d' identifier", a.ID()))
+	}
+
+	// Register the algorithm
+	byName[a.Name()] = a
+	byID[a.ID()] = a
+}
+
+// Get returns the algorithm registered with the given name.
+func Get(name string) (Algorithm, error) {
+	a, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownAlgorithm, name)
+	}
+
+	// No error
+	return a, nil
+}
+
+// GetByID returns the algorithm registered with the given identifier.
+func GetByID(id byte) (Algorithm, error) {
+	a, ok := byID[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: identifier %d", ErrUnknownAlgorithm, id)
+	}
+
+	// No error
+	return a, nil
+}
+
+// Default returns the default algorithm (Argon2id).
+func Default() Algorithm {
+	return byName[Argon2id]
+}
@@ -0,0 +1,94 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package kdf
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	scryptID             byte = 0x01
+	maxScryptCost             = 1 << 20
+	maxScryptParallelism      = 16
+)
+
+func init() {
+	Register(scryptKDF{})
+}
+
+// scryptKDF maps the scrypt cost parameters as N = Iterations,
+// r = Memory * 8 / N and p = Parallelism.
+type scryptKDF struct{}
+
+func (scryptKDF) Name() string { return Scrypt }
+
+func (scryptKDF) ID() byte { return scryptID }
+
+func (scryptKDF) DefaultParameters() Parameters {
+	// N = 2^15, r = 8, p = 1
+	return Parameters{
+		Iterations:  32768,
+		Memory:      32 * 1024,
+		Parallelism: 1,
+	}
+}
+
+func (scryptKDF) Validate(p Parameters) error {
+	switch {
+	case p.Iterations < 2 || p.Iterations > maxScryptCost || p.Iterations&(p.Iterations-1) != 0:
+		return fmt.Errorf("%w: scrypt cost must be a power of 2 between 2 and %d", ErrInvalidParameters, maxScryptCost)
+	case p.Parallelism < 1 || p.Parallelism > maxScryptParallelism:
+		return fmt.Errorf("%w: scrypt parallelism must be between 1 and %d", ErrInvalidParameters, maxScryptParallelism)
+	case uint64(p.Memory)*8 < uint64(p.Iterations) || (uint64(p.Memory)*8)%uint64(p.Iterations) != 0:
+		return fmt.Errorf("%w: scrypt memory must be a multiple of %d KiB", ErrInvalidParameters, p.Iterations/8)
+	case scryptAllocation(p) > uint64(MaxMemory)*1024:
+		return fmt.Errorf("%w: scrypt memory must not exceed %d KiB", ErrInvalidParameters, MaxMemory)
+	}
+
+	// No error
+	return nil
+}
+
+// scryptAllocation returns the number of bytes allocated by scrypt, the
+// parallel blocks (p * 128 * r) and the working buffers (256 * r) are counted
+// on top of the memory cost (N * 128 * r).
+func scryptAllocation(p Parameters) uint64 {
+	r := uint64(p.Memory) * 8 / uint64(p.Iterations)
+	return 128*r*(uint64(p.Iterations)+uint64(p.Parallelism)) + 256*r
+}
+
+func (a scryptKDF) Derive(password, salt []byte, p Parameters, keyLen int) ([]byte, error) {
+	// Check arguments
+	if err := a.Validate(p); err != nil {
+		return nil, err
+	}
+
+	// Compute block size
+	r := int(uint64(p.Memory) * 8 / uint64(p.Iterations))
+
+	// Delegate to scrypt
+	dk, err := scrypt.Key(password, salt, int(p.Iterations), r, int(p.Parallelism), keyLen)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidParameters, err)
+	}
+
+	// No error
+	return dk, nil
+}
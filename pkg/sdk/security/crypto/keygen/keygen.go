@@ -0,0 +1,256 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keygen
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"golang.org/x/crypto/curve25519"
+
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+// ErrUnknownSpec is raised when the key specification is not supported.
+var ErrUnknownSpec = errors.New("keygen: unknown key specification")
+
+// KeyPair describes a generated asymmetric key pair.
+type KeyPair struct {
+	// Spec is the normalized key specification (i.e. rsa:4096).
+	Spec string
+	// Public is the raw public key (ed25519.PublicKey, *ecdsa.PublicKey,
+	// *rsa.PublicKey or []byte for x25519).
+	Public interface{}
+	// Private is the raw private key (ed25519.PrivateKey, *ecdsa.PrivateKey,
+	// *rsa.PrivateKey or []byte for x25519).
+	Private interface{}
+	// PublicPEM is the PKIX encoded public key.
+	PublicPEM []byte
+	// PrivatePEM is the PKCS8 encoded private key.
+	PrivatePEM []byte
+}
+
+type generatorFunc func(r io.Reader) (pub, priv interface{}, err error)
+
+var generators = map[string]generatorFunc{
+	"ed25519":    generateEd25519,
+	"ecdsa:p256": generateECDSA(elliptic.P256()),
+	"ecdsa:p384": generateECDSA(elliptic.P384()),
+	"ecdsa:p521": generateECDSA(elliptic.P521()),
+	"rsa:2048":   generateRSA(2048),
+	"rsa:3072":   generateRSA(3072),
+	"rsa:4096":   generateRSA(4096),
+	"x25519":     generateX25519,
+}
+
+// Specs returns the sorted list of supported key specifications.
+func Specs() []string {
+	res := make([]string, 0, len(generators))
+	for k := range generators {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Generate a key pair according to the given specification.
+//
+// Supported specifications are ed25519, ecdsa:p256, ecdsa:p384, ecdsa:p521,
+// rsa:2048, rsa:3072, rsa:4096 and x25519. The "ecdsa" and "rsa" shortcuts
+// resolve to ecdsa:p256 and rsa:2048.
+func Generate(spec string) (*KeyPair, error) {
+	return GenerateFrom(rand.Reader, spec)
+}
+
+// GenerateFrom generates a key pair using the given random source.
+func GenerateFrom(r io.Reader, spec string) (*KeyPair, error) {
+	// Check arguments
+	if r == nil {
+		return nil, errors.New("keygen: random source is nil")
+	}
+
+	// Resolve generator
+	spec = normalize(spec)
+	gen, ok := generators[spec]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s', supported values are %s", ErrUnknownSpec, spec, strings.Join(Specs(), ", "))
+	}
+
+	// Generate key material
+	pub, priv, err := gen(r)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: unable to generate '%s' key pair: %w", spec, err)
+	}
+
+	// Encode keys
+	pubDer, privDer, err := marshal(pub, priv)
+	if err != nil {
+		return nil, fmt.Errorf("keygen: unable to encode '%s' key pair: %w", spec, err)
+	}
+
+	// No error
+	return &KeyPair{
+		Spec:       spec,
+		Public:     pub,
+		Private:    priv,
+		PublicPEM:  pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDer}),
+		PrivatePEM: pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDer}),
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func normalize(spec string) string {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	switch spec {
+	case "ecdsa", "ec", "ec:p256":
+		return "ecdsa:p256"
+	case "ec:p384":
+		return "ecdsa:p384"
+	case "ec:p521":
+		return "ecdsa:p521"
+	case "rsa":
+		return "rsa:2048"
+	}
+	return spec
+}
+
+func generateEd25519(r io.Reader) (pub, priv interface{}, err error) {
+	// Share the PASETO v4.public key generation path
+	pk, sk, err := v4.GenerateKeyPair(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// No error
+	return pk, sk, nil
+}
+
+func generateECDSA(curve elliptic.Curve) generatorFunc {
+	return func(r io.Reader) (pub, priv interface{}, err error) {
+		key, err := ecdsa.GenerateKey(curve, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &key.PublicKey, key, nil
+	}
+}
+
+func generateRSA(bits int) generatorFunc {
+	return func(r io.Reader) (pub, priv interface{}, err error) {
+		key, err := rsa.GenerateKey(r, bits)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &key.PublicKey, key, nil
+	}
+}
+
+func generateX25519(r io.Reader) (pub, priv interface{}, err error) {
+	// Read scalar
+	sk := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(r, sk); err != nil {
+		return nil, nil, err
+	}
+
+	// Compute public point
+	pk, err := curve25519.X25519(sk, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// No error
+	return pk, sk, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// RFC 8410 - Algorithm Identifiers for Ed25519, Ed448, X25519, and X448
+var oidX25519 = asn1.ObjectIdentifier{1, 3, 101, 110}
+
+type pkixPublicKey struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type pkcs8PrivateKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+func marshal(pub, priv interface{}) (pubDer, privDer []byte, err error) {
+	// X25519 is not supported by crypto/x509
+	if sk, ok := priv.([]byte); ok {
+		pk, _ := pub.([]byte)
+		return marshalX25519(pk, sk)
+	}
+
+	// Delegate to crypto/x509
+	pubDer, err = x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode public key: %w", err)
+	}
+	privDer, err = x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode private key: %w", err)
+	}
+
+	// No error
+	return pubDer, privDer, nil
+}
+
+func marshalX25519(pk, sk []byte) (pubDer, privDer []byte, err error) {
+	algo := pkix.AlgorithmIdentifier{Algorithm: oidX25519}
+
+	// Encode public key
+	pubDer, err = asn1.Marshal(pkixPublicKey{
+		Algorithm: algo,
+		PublicKey: asn1.BitString{Bytes: pk, BitLength: 8 * len(pk)},
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode public key: %w", err)
+	}
+
+	// CurvePrivateKey ::= OCTET STRING
+	curveKey, err := asn1.Marshal(sk)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode private key: %w", err)
+	}
+	privDer, err = asn1.Marshal(pkcs8PrivateKey{
+		Algorithm:  algo,
+		PrivateKey: curveKey,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to encode private key: %w", err)
+	}
+
+	// No error
+	return pubDer, privDer, nil
+}
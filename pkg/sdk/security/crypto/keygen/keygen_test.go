@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keygen
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/curve25519"
+)
+
+func TestGenerate(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		wantSpec string
+	}{
+		{spec: "ed25519", wantSpec: "ed25519"},
+		{spec: "ecdsa", wantSpec: "ecdsa:p256"},
+		{spec: "ecdsa:p384", wantSpec: "ecdsa:p384"},
+		{spec: "EC:P521", wantSpec: "ecdsa:p521"},
+		{spec: "rsa", wantSpec: "rsa:2048"},
+		{spec: "rsa:3072", wantSpec: "rsa:3072"},
+		{spec: "x25519", wantSpec: "x25519"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.spec, func(t *testing.T) {
+			kp, err := Generate(testCase.spec)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantSpec, kp.Spec)
+
+			pubBlock, rest := pem.Decode(kp.PublicPEM)
+			assert.Empty(t, rest)
+			assert.Equal(t, "PUBLIC KEY", pubBlock.Type)
+			privBlock, rest := pem.Decode(kp.PrivatePEM)
+			assert.Empty(t, rest)
+			assert.Equal(t, "PRIVATE KEY", privBlock.Type)
+
+			if testCase.wantSpec == "x25519" {
+				assertX25519(t, kp, pubBlock.Bytes, privBlock.Bytes)
+				return
+			}
+
+			pub, err := x509.ParsePKIXPublicKey(pubBlock.Bytes)
+			assert.NoError(t, err)
+			assert.Equal(t, kp.Public, pub)
+			priv, err := x509.ParsePKCS8PrivateKey(privBlock.Bytes)
+			assert.NoError(t, err)
+			// Compare with Equal, precomputed RSA values are not canonical.
+			assert.True(t, kp.Private.(interface {
+				Equal(crypto.PrivateKey) bool
+			}).Equal(priv))
+
+			switch k := kp.Private.(type) {
+			case ed25519.PrivateKey:
+				assert.Equal(t, k.Public(), kp.Public)
+			case *ecdsa.PrivateKey:
+				assert.Equal(t, &k.PublicKey, kp.Public)
+			case *rsa.PrivateKey:
+				assert.Equal(t, &k.PublicKey, kp.Public)
+			default:
+				t.Fatalf("unexpected private key type %T", k)
+			}
+		})
+	}
+}
+
+func assertX25519(t *testing.T, kp *KeyPair, pubDer, privDer []byte) {
+	t.Helper()
+
+	var pub pkixPublicKey
+	_, err := asn1.Unmarshal(pubDer, &pub)
+	assert.NoError(t, err)
+	assert.True(t, pub.Algorithm.Algorithm.Equal(oidX25519))
+	assert.Equal(t, kp.Public, pub.PublicKey.Bytes)
+
+	var priv pkcs8PrivateKey
+	_, err = asn1.Unmarshal(privDer, &priv)
+	assert.NoError(t, err)
+	var sk []byte
+	_, err = asn1.Unmarshal(priv.PrivateKey, &sk)
+	assert.NoError(t, err)
+	assert.Equal(t, kp.Private, sk)
+
+	pk, err := curve25519.X25519(sk, curve25519.Basepoint)
+	assert.NoError(t, err)
+	assert.Equal(t, kp.Public, pk)
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	_, err := Generate("dsa:1024")
+	assert.True(t, errors.Is(err, ErrUnknownSpec))
+
+	_, err = GenerateFrom(nil, "ed25519")
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mac
+
+import (
+	"fmt"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// Blake2bMaxSize is the maximum BLAKE2b MAC size in bytes.
+	Blake2bMaxSize = blake2b.Size
+	// Blake2bMaxKeySize is the maximum BLAKE2b key size in bytes.
+	Blake2bMaxKeySize = blake2b.Size
+)
+
+// Blake2bMAC returns the keyed BLAKE2b digest of data truncated to size bytes.
+// The key can be empty to compute an unkeyed digest. The output size is part
+// of the BLAKE2b parameter block, so a 32 bytes MAC is not a truncation of a
+// 64 bytes MAC.
+func Blake2bMAC(key, data []byte, size int) ([]byte, error) {
+	// Check arguments
+	if size < 1 || size > Blake2bMaxSize {
+		return nil, fmt.Errorf("mac: invalid BLAKE2b size %d, it must be between 1 and %d", size, Blake2bMaxSize)
+	}
+	if len(key) > Blake2bMaxKeySize {
+		return nil, fmt.Errorf("mac: invalid BLAKE2b key length %d, it must not exceed %d", len(key), Blake2bMaxKeySize)
+	}
+
+	// Initialize hash
+	h, err := blake2b.New(size, key)
+	if err != nil {
+		return nil, fmt.Errorf("mac: unable to initialize BLAKE2b: %w", err)
+	}
+
+	// Authenticate content
+	h.Write(data)
+
+	// No error
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,91 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package mac
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlake2bMAC_Vectors(t *testing.T) {
+	// 00 01 02 ... 3f
+	kat := make([]byte, 64)
+	for i := range kat {
+		kat[i] = byte(i)
+	}
+
+	testCases := []struct {
+		name   string
+		key    []byte
+		data   []byte
+		size   int
+		expect string
+	}{
+		{
+			// RFC 7693 - Appendix A
+			name:   "rfc7693 abc",
+			data:   []byte("abc"),
+			size:   64,
+			expect: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		},
+		{
+			// BLAKE2 reference blake2b-kat.txt - keyed, empty input
+			name:   "keyed kat 0",
+			key:    kat,
+			data:   []byte{},
+			size:   64,
+			expect: "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2ff4233499391653df7aefcbc13fc51568",
+		},
+		{
+			// BLAKE2 reference blake2b-kat.txt - keyed, 1 byte input
+			name:   "keyed kat 1",
+			key:    kat,
+			data:   []byte{0x00},
+			size:   64,
+			expect: "961f6dd1e4dd30f63901690c512e78e4b45e4742ed197c3c5e45c549fd25f2e4187b0bc9fe30492b16b0d0bc4ef9b0f34c7003fac09a5ef1532e69430234cebd",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := Blake2bMAC(testCase.key, testCase.data, testCase.size)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expect, hex.EncodeToString(out))
+		})
+	}
+}
+
+func TestBlake2bMAC_Size(t *testing.T) {
+	out, err := Blake2bMAC([]byte("key"), []byte("data"), 32)
+	assert.NoError(t, err)
+	assert.Len(t, out, 32)
+
+	// Output size is bound to the digest
+	long, err := Blake2bMAC([]byte("key"), []byte("data"), 64)
+	assert.NoError(t, err)
+	assert.NotEqual(t, long[:32], out)
+
+	for _, size := range []int{-1, 0, 65} {
+		_, err := Blake2bMAC(nil, []byte("data"), size)
+		assert.Error(t, err)
+	}
+	_, err = Blake2bMAC(make([]byte, 65), []byte("data"), 32)
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // required by RFC 6238
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	totpSecretLen = 20
+	totpPeriod    = 30
+	totpDigits    = 6
+	totpModulo    = 1000000
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// -----------------------------------------------------------------------------
+
+// TOTPSecret generates a random base32 encoded TOTP shared secret.
+func TOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("unable to generate TOTP secret: %w", err)
+	}
+
+	// No error
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPNow returns the current RFC 6238 code (SHA1, 30s period, 6 digits) for
+// the given base32 encoded secret.
+func TOTPNow(secret string) (string, error) {
+	return totpAt(secret, time.Now())
+}
+
+// -----------------------------------------------------------------------------
+
+func totpAt(secret string, t time.Time) (string, error) {
+	// Decode secret
+	key, err := totpEncoding.DecodeString(strings.TrimRight(strings.ToUpper(strings.ReplaceAll(secret, " ", "")), "="))
+	if err != nil {
+		return "", fmt.Errorf("unable to decode TOTP secret: %w", err)
+	}
+	if len(key) == 0 {
+		return "", fmt.Errorf("unable to use an empty TOTP secret")
+	}
+
+	// Compute time counter
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(t.Unix()/totpPeriod))
+
+	// Compute HMAC
+	h := hmac.New(sha1.New, key)
+	h.Write(counter[:])
+	sum := h.Sum(nil)
+
+	// Dynamic truncation (RFC 4226 - 5.3)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	// No error
+	return fmt.Sprintf("%0*d", totpDigits, code%totpModulo), nil
+}
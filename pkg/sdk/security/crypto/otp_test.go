@@ -0,0 +1,61 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package crypto
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 - Appendix B (SHA1)
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	tests := []struct {
+		time int64
+		want string
+	}{
+		{time: 59, want: "287082"},
+		{time: 1111111109, want: "081804"},
+		{time: 1234567890, want: "005924"},
+		{time: 20000000000, want: "353130"},
+	}
+	for _, tc := range tests {
+		got, err := totpAt(secret, time.Unix(tc.time, 0))
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, got)
+	}
+
+	// Invalid secrets
+	_, err := TOTPNow("")
+	assert.Error(t, err)
+	_, err = TOTPNow("not-base32!")
+	assert.Error(t, err)
+}
+
+func TestTOTPSecret(t *testing.T) {
+	secret, err := TOTPSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := TOTPNow(secret)
+	assert.NoError(t, err)
+	assert.Len(t, code, 6)
+}
@@ -0,0 +1,210 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyset
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+const (
+	maxKeySetBodySize = 1 << 20
+	maxCacheLifetime  = 24 * time.Hour
+)
+
+// FetchOption is used to configure the keyset fetcher.
+type FetchOption func(*Fetcher)
+
+// WithHTTPClient sets the HTTP client used to retrieve the key sets.
+func WithHTTPClient(client *http.Client) FetchOption {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+// WithKeyringOptions sets the options applied to the produced keyrings.
+func WithKeyringOptions(opts ...v4.KeyringOption) FetchOption {
+	return func(f *Fetcher) {
+		f.keyringOpts = opts
+	}
+}
+
+// Fetcher retrieves remote JWKS documents as verification keyrings. Responses
+// are cached according to their `Cache-Control` header and revalidated with
+// their `ETag`.
+type Fetcher struct {
+	client      *http.Client
+	keyringOpts []v4.KeyringOption
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	keyring   *v4.Keyring
+	etag      string
+	expiresAt time.Time
+}
+
+// NewFetcher returns a keyset fetcher instance.
+func NewFetcher(opts ...FetchOption) *Fetcher {
+	f := &Fetcher{
+		client:  &http.Client{Timeout: 10 * time.Second},
+		now:     time.Now,
+		entries: map[string]*cacheEntry{},
+	}
+	for _, o := range opts {
+		o(f)
+	}
+
+	return f
+}
+
+var defaultFetcher = NewFetcher()
+
+// FetchJWKS retrieves the JWKS document published at the given URL as a
+// verification keyring using a process wide cache.
+func FetchJWKS(url string) (*v4.Keyring, error) {
+	return defaultFetcher.Fetch(context.Background(), url)
+}
+
+// Fetch retrieves the JWKS document published at the given URL. A cached
+// keyring is returned while it is fresh, the document is then revalidated.
+// Cached keyrings are shared between callers and must not be modified.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (*v4.Keyring, error) {
+	// Check arguments
+	if url == "" {
+		return nil, errors.New("keyset: url is blank")
+	}
+
+	// Check cache
+	f.mu.Lock()
+	entry, ok := f.entries[url]
+	f.mu.Unlock()
+	if ok && f.now().Before(entry.expiresAt) {
+		return entry.keyring, nil
+	}
+
+	// Prepare request
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("keyset: unable to prepare request: %w", err)
+	}
+	req.Header.Set("Accept", "application/jwk-set+json, application/json")
+	req.Header.Set("User-Agent", "harp-keyset")
+	if ok && entry.etag != "" {
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	// Send request
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("keyset: unable to retrieve key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && ok:
+		// Refresh cached entry
+		f.store(url, entry.keyring, entry.etag, resp.Header)
+		return entry.keyring, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("keyset: unexpected status code %d", resp.StatusCode)
+	default:
+	}
+
+	// Decode response
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxKeySetBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("keyset: unable to read key set: %w", err)
+	}
+	kr, err := ParseJWKS(body, f.keyringOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	f.store(url, kr, resp.Header.Get("ETag"), resp.Header)
+
+	// No error
+	return kr, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func (f *Fetcher) store(url string, kr *v4.Keyring, etag string, h http.Header) {
+	maxAge, cacheable := cacheLifetime(h.Get("Cache-Control"))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !cacheable {
+		delete(f.entries, url)
+		return
+	}
+
+	f.entries[url] = &cacheEntry{
+		keyring:   kr,
+		etag:      etag,
+		expiresAt: f.now().Add(maxAge),
+	}
+}
+
+// cacheLifetime returns the response freshness lifetime from the given
+// Cache-Control directives, capped to maxCacheLifetime. Responses without
+// max-age must be revalidated before reuse, `no-store` responses are not
+// cached.
+func cacheLifetime(cc string) (time.Duration, bool) {
+	var (
+		maxAge  time.Duration
+		noCache bool
+	)
+	for _, directive := range strings.Split(cc, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			return 0, false
+		case directive == "no-cache":
+			noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(directive, "max-age="), 10, 64)
+			if err == nil && seconds > 0 {
+				maxAge = maxCacheLifetime
+				if seconds < int64(maxCacheLifetime/time.Second) {
+					maxAge = time.Duration(seconds) * time.Second
+				}
+			}
+		default:
+		}
+	}
+	if noCache {
+		return 0, true
+	}
+
+	return maxAge, true
+}
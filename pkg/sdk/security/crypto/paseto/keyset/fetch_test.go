@@ -0,0 +1,127 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyset
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetcher_Fetch(t *testing.T) {
+	ctx := context.Background()
+	signer, kids := testKeyring(t, 1)
+	doc, err := PublicJWKS(signer)
+	assert.NoError(t, err)
+
+	var requests, revalidations int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidations, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write(doc)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	underTest := NewFetcher(WithHTTPClient(srv.Client()))
+	underTest.now = func() time.Time { return now }
+
+	kr, err := underTest.Fetch(ctx, srv.URL)
+	assert.NoError(t, err)
+	token, err := signer.Sign(kids[0], []byte("{}"), "", "")
+	assert.NoError(t, err)
+	_, err = kr.Verify(token, "")
+	assert.NoError(t, err)
+
+	// Fresh entry is served from cache
+	kr2, err := underTest.Fetch(ctx, srv.URL)
+	assert.NoError(t, err)
+	assert.Same(t, kr, kr2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	// Stale entry is revalidated
+	now = now.Add(2 * time.Minute)
+	kr3, err := underTest.Fetch(ctx, srv.URL)
+	assert.NoError(t, err)
+	assert.Same(t, kr, kr3)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&revalidations))
+
+	// Revalidation refreshes the entry lifetime
+	_, err = underTest.Fetch(ctx, srv.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestFetcher_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invalid":
+			_, _ = w.Write([]byte(`{"keys":[{"kty":"oct","k":"AAAA"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	underTest := NewFetcher(WithHTTPClient(srv.Client()))
+
+	_, err := underTest.Fetch(ctx, "")
+	assert.Error(t, err)
+	_, err = underTest.Fetch(ctx, srv.URL+"/missing")
+	assert.Error(t, err)
+	_, err = underTest.Fetch(ctx, srv.URL+"/invalid")
+	assert.ErrorIs(t, err, ErrInvalidKeySet)
+}
+
+func TestCacheLifetime(t *testing.T) {
+	testCases := []struct {
+		header    string
+		maxAge    time.Duration
+		cacheable bool
+	}{
+		{header: "", maxAge: 0, cacheable: true},
+		{header: "max-age=300", maxAge: 5 * time.Minute, cacheable: true},
+		{header: "public, Max-Age=60", maxAge: time.Minute, cacheable: true},
+		{header: "max-age=foo", maxAge: 0, cacheable: true},
+		{header: "max-age=999999999999", maxAge: maxCacheLifetime, cacheable: true},
+		{header: "no-cache, max-age=60", maxAge: 0, cacheable: true},
+		{header: "no-cache, no-store", maxAge: 0, cacheable: false},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.header, func(t *testing.T) {
+			maxAge, cacheable := cacheLifetime(testCase.header)
+			assert.Equal(t, testCase.maxAge, maxAge)
+			assert.Equal(t, testCase.cacheable, cacheable)
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyset
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	jose "gopkg.in/square/go-jose.v2"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+const (
+	keyAlgorithm = "EdDSA"
+	keyUse       = "sig"
+)
+
+// ErrInvalidKeySet is raised when the JWKS document can't be used as a PASETO
+// v4 public keyset.
+var ErrInvalidKeySet = errors.New("keyset: invalid key set")
+
+// PublicJWKS exports all public verification keys of the given keyring as a
+// JWKS document. Keys are sorted by identifier so that the document is stable
+// for a given keyring content.
+func PublicJWKS(kr *v4.Keyring) ([]byte, error) {
+	// Check arguments
+	if kr == nil {
+		return nil, errors.New("keyset: keyring is nil")
+	}
+
+	// Sort key identifiers
+	keys := kr.PublicKeys()
+	kids := make([]string, 0, len(keys))
+	for kid := range keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	// Prepare key set
+	set := jose.JSONWebKeySet{
+		Keys: make([]jose.JSONWebKey, 0, len(kids)),
+	}
+	for _, kid := range kids {
+		set.Keys = append(set.Keys, jose.JSONWebKey{
+			Key:       keys[kid],
+			KeyID:     kid,
+			Algorithm: keyAlgorithm,
+			Use:       keyUse,
+		})
+	}
+
+	// Encode key set
+	out, err := json.Marshal(set)
+	if err != nil {
+		return nil, fmt.Errorf("keyset: unable to encode key set: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
+
+// ParseJWKS decodes the given JWKS document as a verification keyring. Only
+// Ed25519 public keys are accepted and their identifier must match their
+// `k4.pid` PASERK identifier.
+func ParseJWKS(data []byte, opts ...v4.KeyringOption) (*v4.Keyring, error) {
+	// Decode key set
+	var set jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeySet, err)
+	}
+
+	kr := v4.NewKeyring(opts...)
+	for i := range set.Keys {
+		k := &set.Keys[i]
+
+		// Check key type
+		pk, ok := k.Key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: key '%s' is not an Ed25519 public key", ErrInvalidKeySet, k.KeyID)
+		}
+		if k.Use != "" && k.Use != keyUse {
+			return nil, fmt.Errorf("%w: key '%s' is not a signature key", ErrInvalidKeySet, k.KeyID)
+		}
+
+		// Check key identifier
+		kid, err := paserk.PublicID(pk)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeySet, err)
+		}
+		if kid != k.KeyID {
+			return nil, fmt.Errorf("%w: key identifier '%s' doesn't match the key", ErrInvalidKeySet, k.KeyID)
+		}
+
+		// Register the key
+		if _, err := kr.AddPublicKey(pk); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKeySet, err)
+		}
+	}
+
+	// No error
+	return kr, nil
+}
@@ -0,0 +1,103 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyset
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+func testKeyring(t *testing.T, count int) (*v4.Keyring, []string) {
+	t.Helper()
+
+	kr := v4.NewKeyring()
+	kids := []string{}
+	for i := 0; i < count; i++ {
+		_, sk, err := ed25519.GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+		kid, err := kr.AddSecretKey(sk)
+		assert.NoError(t, err)
+		kids = append(kids, kid)
+	}
+
+	return kr, kids
+}
+
+func TestPublicJWKS(t *testing.T) {
+	_, err := PublicJWKS(nil)
+	assert.Error(t, err)
+
+	signer, kids := testKeyring(t, 2)
+
+	doc, err := PublicJWKS(signer)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(doc), `"d"`)
+
+	// Output is stable
+	doc2, err := PublicJWKS(signer)
+	assert.NoError(t, err)
+	assert.Equal(t, doc, doc2)
+
+	// Tokens signed with any key can be verified
+	verifier, err := ParseJWKS(doc)
+	assert.NoError(t, err)
+	for _, kid := range kids {
+		token, err := signer.Sign(kid, []byte("{}"), "", "")
+		assert.NoError(t, err)
+		_, err = verifier.Verify(token, "")
+		assert.NoError(t, err)
+	}
+
+	// Removed keys are not exported
+	signer.Remove(kids[0])
+	doc, err = PublicJWKS(signer)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(doc), kids[0])
+	assert.Contains(t, string(doc), kids[1])
+}
+
+func TestParseJWKS_Invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		doc  string
+	}{
+		{name: "not json", doc: "{"},
+		{name: "symmetric key", doc: `{"keys":[{"kty":"oct","k":"AAAA","kid":"foo"}]}`},
+		{name: "encryption key", doc: `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI","kid":"k4.pid.yh4-bJYjOYAG6CWy0zsfPmpKylxS7uAWrxqVmBN2KAiJ","use":"enc"}]}`},
+		{name: "kid mismatch", doc: `{"keys":[{"kty":"OKP","crv":"Ed25519","x":"Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI","kid":"k4.pid.foo"}]}`},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			kr, err := ParseJWKS([]byte(testCase.doc))
+			assert.True(t, errors.Is(err, ErrInvalidKeySet))
+			assert.Nil(t, kr)
+		})
+	}
+
+	// Valid key
+	kr, err := ParseJWKS([]byte(`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI","kid":"k4.pid.yh4-bJYjOYAG6CWy0zsfPmpKylxS7uAWrxqVmBN2KAiJ","use":"sig"}]}`))
+	assert.NoError(t, err)
+	assert.Len(t, kr.PublicKeys(), 1)
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package paseto provides the primitives shared by all PASETO versions.
+package paseto
+
+import "encoding/binary"
+
+// PreAuthenticationEncoding returns the PASETO pre-authentication encoding of
+// the given pieces. Each piece is prefixed with its 64-bit little-endian
+// length, the whole encoding is prefixed with the piece count.
+//
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Common.md#authentication-padding
+func PreAuthenticationEncoding(pieces ...[]byte) []byte {
+	// Compute final size
+	size := 8
+	for i := range pieces {
+		size += 8 + len(pieces[i])
+	}
+
+	// Encode piece count
+	output := make([]byte, 8, size)
+	binary.LittleEndian.PutUint64(output, uint64(len(pieces)))
+
+	// For each element
+	var length [8]byte
+	for i := range pieces {
+		// Encode size
+		binary.LittleEndian.PutUint64(length[:], uint64(len(pieces[i])))
+		output = append(output, length[:]...)
+
+		// Encode data
+		output = append(output, pieces[i]...)
+	}
+
+	return output
+}
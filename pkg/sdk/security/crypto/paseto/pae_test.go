@@ -0,0 +1,77 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Common.md#pae-definition
+func Test_PreAuthenticationEncoding(t *testing.T) {
+	testCases := []struct {
+		name   string
+		pieces [][]byte
+		want   string
+	}{
+		{
+			name:   "empty list",
+			pieces: [][]byte{},
+			want:   "0000000000000000",
+		},
+		{
+			name:   "single empty piece",
+			pieces: [][]byte{[]byte("")},
+			want:   "0100000000000000" + "0000000000000000",
+		},
+		{
+			name:   "two empty pieces",
+			pieces: [][]byte{[]byte(""), []byte("")},
+			want:   "0200000000000000" + "0000000000000000" + "0000000000000000",
+		},
+		{
+			name:   "single piece",
+			pieces: [][]byte{[]byte("Paragon")},
+			want:   "0100000000000000" + "0700000000000000" + hex.EncodeToString([]byte("Paragon")),
+		},
+		{
+			name:   "multi pieces",
+			pieces: [][]byte{[]byte("Paragon"), []byte("Initiative")},
+			want:   "0200000000000000" + "0700000000000000" + hex.EncodeToString([]byte("Paragon")) + "0a00000000000000" + hex.EncodeToString([]byte("Initiative")),
+		},
+		{
+			name:   "piece looking like an encoding",
+			pieces: [][]byte{[]byte("\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00")},
+			want:   "0100000000000000" + "1000000000000000" + "00000000000000000000000000000000",
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got := PreAuthenticationEncoding(testCase.pieces...)
+			assert.Equal(t, testCase.want, hex.EncodeToString(got))
+		})
+	}
+}
+
+func Test_PreAuthenticationEncoding_NoPieces(t *testing.T) {
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0}, PreAuthenticationEncoding())
+}
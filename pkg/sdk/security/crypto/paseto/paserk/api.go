@@ -0,0 +1,53 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import "errors"
+
+var (
+	// ErrInvalidFormat is raised when the given value is not a PASERK.
+	ErrInvalidFormat = errors.New("paserk: invalid format")
+	// ErrUnsupportedVersion is raised when the PASERK version is not supported.
+	ErrUnsupportedVersion = errors.New("paserk: unsupported version")
+	// ErrUnexpectedType is raised when the PASERK type doesn't match the
+	// expected one.
+	ErrUnexpectedType = errors.New("paserk: unexpected type")
+	// ErrInvalidKey is raised when the key material is invalid.
+	ErrInvalidKey = errors.New("paserk: invalid key")
+	// ErrAuthenticationFailed is raised when the wrapped key authentication
+	// failed (wrong password, wrong wrapping key or tampered data).
+	ErrAuthenticationFailed = errors.New("paserk: authentication failed")
+)
+
+// LocalKeyLength is the v4 symmetric key size.
+const LocalKeyLength = 32
+
+const (
+	versionPrefix    = "k4."
+	localPrefix      = "k4.local."
+	publicPrefix     = "k4.public."
+	secretPrefix     = "k4.secret."
+	lidPrefix        = "k4.lid."
+	pidPrefix        = "k4.pid."
+	sidPrefix        = "k4.sid."
+	localPwPrefix    = "k4.local-pw."
+	secretPwPrefix   = "k4.secret-pw."
+	localWrapPrefix  = "k4.local-wrap.pie."
+	secretWrapPrefix = "k4.secret-wrap.pie."
+	idLength         = 33
+)
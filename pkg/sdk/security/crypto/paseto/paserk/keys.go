@@ -0,0 +1,209 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/b64"
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/mac"
+)
+
+// EncodeLocal serializes the given symmetric key as a `k4.local` PASERK.
+func EncodeLocal(key []byte) (string, error) {
+	// Check arguments
+	if len(key) != LocalKeyLength {
+		return "", fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// No error
+	return encode(localPrefix, key), nil
+}
+
+// DecodeLocal deserializes the given `k4.local` PASERK as a symmetric key.
+// A key reference (`env://`, `keyring://`) to the PASERK is resolved first.
+func DecodeLocal(s string) ([]byte, error) {
+	// Decode key material
+	key, err := decode(localPrefix, s, LocalKeyLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != LocalKeyLength {
+		return nil, fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// No error
+	return key, nil
+}
+
+// EncodePublic serializes the given Ed25519 public key as a `k4.public` PASERK.
+func EncodePublic(pk ed25519.PublicKey) (string, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("%w: public key must be %d bytes long", ErrInvalidKey, ed25519.PublicKeySize)
+	}
+
+	// No error
+	return encode(publicPrefix, pk), nil
+}
+
+// DecodePublic deserializes the given `k4.public` PASERK as an Ed25519 public key.
+// A key reference (`env://`, `keyring://`) to the PASERK is resolved first.
+func DecodePublic(s string) (ed25519.PublicKey, error) {
+	// Decode key material
+	pk, err := decode(publicPrefix, s, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: public key must be %d bytes long", ErrInvalidKey, ed25519.PublicKeySize)
+	}
+
+	// No error
+	return ed25519.PublicKey(pk), nil
+}
+
+// EncodeSecret serializes the given Ed25519 private key as a `k4.secret` PASERK.
+func EncodeSecret(sk ed25519.PrivateKey) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// No error
+	return encode(secretPrefix, sk), nil
+}
+
+// DecodeSecret deserializes the given `k4.secret` PASERK as an Ed25519 private key.
+// A key reference (`env://`, `keyring://`) to the PASERK is resolved first.
+func DecodeSecret(s string) (ed25519.PrivateKey, error) {
+	// Decode key material
+	raw, err := decode(secretPrefix, s, ed25519.PrivateKeySize)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// Ensure public key consistency
+	sk := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+	if !security.SecureCompare(sk, raw) {
+		return nil, fmt.Errorf("%w: secret key public part mismatch", ErrInvalidKey)
+	}
+
+	// No error
+	return sk, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// LocalID returns the `k4.lid` identifier of the given symmetric key.
+func LocalID(key []byte) (string, error) {
+	p, err := EncodeLocal(key)
+	if err != nil {
+		return "", err
+	}
+
+	return id(lidPrefix, p)
+}
+
+// PublicID returns the `k4.pid` identifier of the given Ed25519 public key.
+func PublicID(pk ed25519.PublicKey) (string, error) {
+	p, err := EncodePublic(pk)
+	if err != nil {
+		return "", err
+	}
+
+	return id(pidPrefix, p)
+}
+
+// SecretID returns the `k4.sid` identifier of the given Ed25519 private key.
+func SecretID(sk ed25519.PrivateKey) (string, error) {
+	p, err := EncodeSecret(sk)
+	if err != nil {
+		return "", err
+	}
+
+	return id(sidPrefix, p)
+}
+
+// -----------------------------------------------------------------------------
+
+func encode(h string, raw []byte) string {
+	return h + base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decode checks the PASERK header and decodes the key material, which must not
+// exceed maxLen bytes. Key references (`env://`, `keyring://`) are resolved
+// before decoding.
+func decode(h, s string, maxLen int) ([]byte, error) {
+	// Resolve key reference
+	if keyprovider.IsReference(s) {
+		v, err := keyprovider.Resolve(context.Background(), s)
+		if err != nil {
+			return nil, fmt.Errorf("paserk: %w", err)
+		}
+		s = v
+	}
+
+	// Check version
+	if !strings.HasPrefix(s, versionPrefix) {
+		if strings.HasPrefix(s, "k") && strings.Contains(s, ".") {
+			return nil, ErrUnsupportedVersion
+		}
+		return nil, ErrInvalidFormat
+	}
+
+	// Check type
+	if !strings.HasPrefix(s, h) {
+		return nil, fmt.Errorf("%w: expected '%s'", ErrUnexpectedType, strings.TrimSuffix(h, "."))
+	}
+
+	// Decode key material
+	raw, err := b64.DecodeURLNoPad(s[len(h):], maxLen)
+	switch {
+	case err == nil:
+	case errors.Is(err, b64.ErrTooLarge):
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	default:
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// No error
+	return raw, nil
+}
+
+// https://github.com/paseto-standard/paserk/blob/master/operations/ID.md
+func id(h, p string) (string, error) {
+	// Compute identifier
+	sum, err := mac.Blake2bMAC(nil, []byte(h+p), idLength)
+	if err != nil {
+		return "", fmt.Errorf("paserk: unable to compute identifier: %w", err)
+	}
+
+	// No error
+	return encode(h, sum), nil
+}
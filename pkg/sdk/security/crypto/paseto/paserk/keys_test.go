@@ -0,0 +1,163 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+)
+
+var (
+	localKeyHex  = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	publicKeyHex = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	secretKeyHex = "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+)
+
+func mustDecodeHex(t *testing.T, value string) []byte {
+	t.Helper()
+	out, err := hex.DecodeString(value)
+	assert.NoError(t, err)
+	return out
+}
+
+func Test_Local(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+
+	p, err := EncodeLocal(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.local.cHFyc3R1dnd4eXp7fH1-f4CBgoOEhYaHiImKi4yNjo8", p)
+
+	out, err := DecodeLocal(p)
+	assert.NoError(t, err)
+	assert.Equal(t, key, out)
+
+	lid, err := LocalID(key)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.lid.iVtYQDjr5gEijCSjJC3fQaJm7nCeQSeaty0Jixy8dbsk", lid)
+
+	_, err = EncodeLocal(key[:16])
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func Test_Public(t *testing.T) {
+	pk := ed25519.PublicKey(mustDecodeHex(t, publicKeyHex))
+
+	p, err := EncodePublic(pk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.public.Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI", p)
+
+	out, err := DecodePublic(p)
+	assert.NoError(t, err)
+	assert.Equal(t, pk, out)
+
+	pid, err := PublicID(pk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.pid.yh4-bJYjOYAG6CWy0zsfPmpKylxS7uAWrxqVmBN2KAiJ", pid)
+}
+
+func Test_Secret(t *testing.T) {
+	sk := ed25519.PrivateKey(mustDecodeHex(t, secretKeyHex))
+
+	p, err := EncodeSecret(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.secret.tMv7Q99M4hByfZU-SnEzB_oZu32fhQQUONnhG5QqN3Qeudu7vAR8A_1wYE4AcfCYfhayi3VyJcEfAEFdDiCxog", p)
+
+	out, err := DecodeSecret(p)
+	assert.NoError(t, err)
+	assert.Equal(t, sk, out)
+
+	sid, err := SecretID(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.sid.9gZFsAQuXhu9lif2pV3rCDjOewsMF4qb4RHGhc0zUklt", sid)
+
+	// Tamper the public part
+	tampered := append([]byte{}, sk...)
+	tampered[63] ^= 0x01
+	_, err = DecodeSecret(encode(secretPrefix, tampered))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func Test_Decode_Invalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{
+			name:    "blank",
+			value:   "",
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "cross version",
+			value:   "k3.local.cHFyc3R1dnd4eXp7fH1-f4CBgoOEhYaHiImKi4yNjo8",
+			wantErr: ErrUnsupportedVersion,
+		},
+		{
+			name:    "cross type",
+			value:   "k4.public.Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI",
+			wantErr: ErrUnexpectedType,
+		},
+		{
+			name:    "padded",
+			value:   "k4.local.cHFyc3R1dnd4eXp7fH1-f4CBgoOEhYaHiImKi4yNjo8=",
+			wantErr: ErrInvalidFormat,
+		},
+		{
+			name:    "invalid length",
+			value:   "k4.local.cHFyc3R1dnd4eXp7fH1-f4CBgoOEhYaHiImK",
+			wantErr: ErrInvalidKey,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := DecodeLocal(testCase.value)
+			assert.True(t, errors.Is(err, testCase.wantErr), "unexpected error %v", err)
+		})
+	}
+}
+
+func Test_Decode_KeyReference(t *testing.T) {
+	t.Setenv("HARP_TEST_PASERK_LOCAL", "k4.local.cHFyc3R1dnd4eXp7fH1-f4CBgoOEhYaHiImKi4yNjo8")
+	t.Setenv("HARP_TEST_PASERK_INVALID", "k4.local.AAAA")
+
+	key, err := DecodeLocal("env://HARP_TEST_PASERK_LOCAL")
+	assert.NoError(t, err)
+	assert.Equal(t, mustDecodeHex(t, localKeyHex), key)
+
+	// Unset variable
+	_, err = DecodeLocal("env://HARP_TEST_PASERK_UNSET")
+	assert.True(t, errors.Is(err, keyprovider.ErrNotSet))
+
+	// Malformed key
+	_, err = DecodeLocal("env://HARP_TEST_PASERK_INVALID")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	assert.False(t, errors.Is(err, keyprovider.ErrNotSet))
+
+	// Type mismatch
+	_, err = DecodePublic("env://HARP_TEST_PASERK_LOCAL")
+	assert.True(t, errors.Is(err, ErrUnexpectedType))
+}
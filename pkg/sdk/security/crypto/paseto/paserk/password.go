@@ -0,0 +1,494 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/kdf"
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+)
+
+const (
+	pwSaltLength   = 16
+	pwNonceLength  = 24
+	pwTagLength    = 32
+	pwParamsLength = 16
+	pwHeaderLength = pwSaltLength + pwParamsLength + pwNonceLength
+	pwMaxLength    = pwHeaderLength + ed25519.PrivateKeySize + pwTagLength
+
+	// DefaultMemory is the default Argon2id memory cost in bytes.
+	DefaultMemory uint64 = 64 * 1024 * 1024
+	// DefaultIterations is the default Argon2id time cost.
+	DefaultIterations uint32 = 2
+	// DefaultParallelism is the default Argon2id parallelism degree.
+	DefaultParallelism uint32 = 1
+)
+
+// Argon2Parameters describes the Argon2id parameters used for password based
+// key wrapping.
+type Argon2Parameters struct {
+	// Memory cost in bytes.
+	Memory uint64
+	// Iterations count.
+	Iterations uint32
+	// Parallelism degree.
+	Parallelism uint32
+}
+
+type options struct {
+	params       Argon2Parameters
+	kdf          string
+	kdfParams    kdf.Parameters
+	randomSource io.Reader
+}
+
+// Option represents functional pattern builder for optional parameters.
+type Option func(*options)
+
+// WithMemory sets the Argon2id memory cost in bytes.
+func WithMemory(value uint64) Option {
+	return func(o *options) {
+		o.params.Memory = value
+	}
+}
+
+// WithIterations sets the Argon2id time cost.
+func WithIterations(value uint32) Option {
+	return func(o *options) {
+		o.params.Iterations = value
+	}
+}
+
+// WithParallelism sets the Argon2id parallelism degree.
+func WithParallelism(value uint32) Option {
+	return func(o *options) {
+		o.params.Parallelism = value
+	}
+}
+
+// WithKDF selects the password based key derivation function by its registry
+// name, zero parameters select the algorithm defaults. Argon2id keeps the
+// standard PASERK layout, other algorithms record their identifier in the
+// parameters header and are only understood by this implementation.
+func WithKDF(name string, params kdf.Parameters) Option {
+	return func(o *options) {
+		o.kdf = name
+		o.kdfParams = params
+
+		// Argon2id parameters are kept in the PASERK representation
+		if name == kdf.Argon2id && params != (kdf.Parameters{}) {
+			o.params = Argon2Parameters{
+				Memory:      uint64(params.Memory) * 1024,
+				Iterations:  params.Iterations,
+				Parallelism: params.Parallelism,
+			}
+		}
+	}
+}
+
+// WithRandom provides the random source used for salt and nonce generation.
+func WithRandom(random io.Reader) Option {
+	return func(o *options) {
+		o.randomSource = random
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// WrapLocalPw encrypts the given symmetric key with the given password as a
+// `k4.local-pw` PASERK.
+func WrapLocalPw(key, password []byte, opts ...Option) (string, error) {
+	// Check arguments
+	if len(key) != LocalKeyLength {
+		return "", fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// Delegate to wrapper
+	return pbkwWrap(localPwPrefix, key, password, opts...)
+}
+
+// UnwrapLocalPw decrypts the given `k4.local-pw` PASERK with the given
+// password.
+func UnwrapLocalPw(s string, password []byte) ([]byte, error) {
+	// Delegate to unwrapper
+	key, err := pbkwUnwrap(localPwPrefix, s, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != LocalKeyLength {
+		return nil, fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// No error
+	return key, nil
+}
+
+// WrapSecretPw encrypts the given Ed25519 private key with the given password
+// as a `k4.secret-pw` PASERK.
+func WrapSecretPw(sk ed25519.PrivateKey, password []byte, opts ...Option) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// Delegate to wrapper
+	return pbkwWrap(secretPwPrefix, sk, password, opts...)
+}
+
+// UnwrapSecretPw decrypts the given `k4.secret-pw` PASERK with the given
+// password.
+func UnwrapSecretPw(s string, password []byte) (ed25519.PrivateKey, error) {
+	// Delegate to unwrapper
+	raw, err := pbkwUnwrap(secretPwPrefix, s, password)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// No error
+	return ed25519.PrivateKey(raw), nil
+}
+
+// PwParameters extracts the Argon2id parameters embedded in the given
+// `k4.local-pw` or `k4.secret-pw` PASERK without decrypting it.
+func PwParameters(s string) (*Argon2Parameters, error) {
+	// Extract derivation
+	alg, params, err := PwKDF(s)
+	if err != nil {
+		return nil, err
+	}
+	if alg != kdf.Argon2id {
+		return nil, fmt.Errorf("%w: wrapped key is derived with '%s'", ErrInvalidFormat, alg)
+	}
+
+	// No error
+	return &Argon2Parameters{
+		Memory:      uint64(params.Memory) * 1024,
+		Iterations:  params.Iterations,
+		Parallelism: params.Parallelism,
+	}, nil
+}
+
+// PwKDF extracts the key derivation algorithm name and parameters embedded in
+// the given `k4.local-pw` or `k4.secret-pw` PASERK without decrypting it.
+func PwKDF(s string) (string, *kdf.Parameters, error) {
+	// Select header
+	var h string
+	switch {
+	case strings.HasPrefix(s, localPwPrefix):
+		h = localPwPrefix
+	case strings.HasPrefix(s, secretPwPrefix):
+		h = secretPwPrefix
+	default:
+		return "", nil, fmt.Errorf("%w: expected password wrapped key", ErrUnexpectedType)
+	}
+
+	// Decode content
+	raw, err := decode(h, s, pwMaxLength)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(raw) < pwHeaderLength+pwTagLength {
+		return "", nil, fmt.Errorf("%w: wrapped key is too short", ErrInvalidFormat)
+	}
+
+	// Decode parameters
+	alg, params, err := decodeParams(raw[pwSaltLength : pwSaltLength+pwParamsLength])
+	if err != nil {
+		return "", nil, err
+	}
+
+	// No error
+	return alg.Name(), &params, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// https://github.com/paseto-standard/paserk/blob/master/operations/PBKW.md
+func pbkwWrap(h string, ptk, password []byte, opts ...Option) (string, error) {
+	// Prepare defaults
+	dopts := &options{
+		params: Argon2Parameters{
+			Memory:      DefaultMemory,
+			Iterations:  DefaultIterations,
+			Parallelism: DefaultParallelism,
+		},
+		randomSource: rand.Reader,
+	}
+
+	// Apply optional parameters
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Resolve key derivation
+	alg, params, err := dopts.derivation()
+	if err != nil {
+		return "", err
+	}
+
+	// Generate salt and nonce
+	var rnd [pwSaltLength + pwNonceLength]byte
+	if _, err := io.ReadFull(dopts.randomSource, rnd[:]); err != nil {
+		return "", fmt.Errorf("paserk: unable to generate random seed: %w", err)
+	}
+	s, n := rnd[:pwSaltLength], rnd[pwSaltLength:]
+
+	// Derive keys
+	ek, ak, err := pbkwKeys(password, s, alg, params)
+	if err != nil {
+		return "", err
+	}
+	defer secret.Wipe(ek)
+	defer secret.Wipe(ak)
+
+	// Encrypt the key
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n)
+	if err != nil {
+		return "", fmt.Errorf("paserk: unable to initialize XChaCha20 cipher: %w", err)
+	}
+	edk := make([]byte, len(ptk))
+	ciph.XORKeyStream(edk, ptk)
+
+	// Serialize content
+	// s || mem || time || para || n || edk
+	body := append([]byte{}, s...)
+	body = append(body, encodeParams(alg, params)...)
+	body = append(body, n...)
+	body = append(body, edk...)
+
+	// Authenticate
+	t, err := pbkwTag(ak, h, body)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return h + base64.RawURLEncoding.EncodeToString(append(body, t...)), nil
+}
+
+func pbkwUnwrap(h, s string, password []byte) ([]byte, error) {
+	// Decode content
+	raw, err := decode(h, s, pwMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < pwHeaderLength+pwTagLength {
+		return nil, fmt.Errorf("%w: wrapped key is too short", ErrInvalidFormat)
+	}
+
+	// Extract components
+	body := raw[:len(raw)-pwTagLength]
+	t := raw[len(raw)-pwTagLength:]
+	salt := body[:pwSaltLength]
+	n := body[pwSaltLength+pwParamsLength : pwHeaderLength]
+	edk := body[pwHeaderLength:]
+
+	// Decode and validate parameters
+	alg, params, err := decodeParams(body[pwSaltLength : pwSaltLength+pwParamsLength])
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive keys
+	ek, ak, err := pbkwKeys(password, salt, alg, params)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(ek)
+	defer secret.Wipe(ak)
+
+	// Authenticate
+	t2, err := pbkwTag(ak, h, body)
+	if err != nil {
+		return nil, err
+	}
+	if !security.SecureCompare(t, t2) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	// Decrypt the key
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n)
+	if err != nil {
+		return nil, fmt.Errorf("paserk: unable to initialize XChaCha20 cipher: %w", err)
+	}
+	ptk := make([]byte, len(edk))
+	ciph.XORKeyStream(ptk, edk)
+
+	// No error
+	return ptk, nil
+}
+
+func pbkwKeys(password, salt []byte, alg kdf.Algorithm, params kdf.Parameters) (ek, ak []byte, err error) {
+	// Derive pre-key
+	k, err := alg.Derive(password, salt, params, 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+	defer secret.Wipe(k)
+
+	// Derive encryption key
+	ekh := blake2b.Sum256(append([]byte{0xFF}, k...))
+
+	// Derive authentication key
+	akh := blake2b.Sum256(append([]byte{0xFE}, k...))
+
+	return ekh[:], akh[:], nil
+}
+
+func pbkwTag(ak []byte, h string, body []byte) ([]byte, error) {
+	// Initialize MAC
+	mac, err := blake2b.New256(ak)
+	if err != nil {
+		return nil, fmt.Errorf("paserk: unable to initialize MAC: %w", err)
+	}
+
+	// Authenticate header and content
+	mac.Write([]byte(h))
+	mac.Write(body)
+
+	// No error
+	return mac.Sum(nil), nil
+}
+
+// derivation resolves the key derivation algorithm and parameters.
+func (o *options) derivation() (kdf.Algorithm, kdf.Parameters, error) {
+	// Argon2id uses the PASERK parameters
+	if o.kdf == "" || o.kdf == kdf.Argon2id {
+		if err := validateParams(&o.params); err != nil {
+			return nil, kdf.Parameters{}, err
+		}
+		return kdf.Default(), kdf.Parameters{
+			Iterations:  o.params.Iterations,
+			Memory:      uint32(o.params.Memory / 1024),
+			Parallelism: o.params.Parallelism,
+		}, nil
+	}
+
+	// Resolve algorithm
+	alg, err := kdf.Get(o.kdf)
+	if err != nil {
+		return nil, kdf.Parameters{}, err
+	}
+
+	// Apply algorithm defaults
+	params := o.kdfParams
+	if params == (kdf.Parameters{}) {
+		params = alg.DefaultParameters()
+	}
+	if err := alg.Validate(params); err != nil {
+		return nil, kdf.Parameters{}, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// No error
+	return alg, params, nil
+}
+
+func validateParams(params *Argon2Parameters) error {
+	switch {
+	case params.Memory%1024 != 0:
+		return fmt.Errorf("%w: argon2id memory must be a multiple of 1024 bytes", ErrInvalidFormat)
+	case params.Memory/1024 > uint64(kdf.MaxMemory):
+		return fmt.Errorf("%w: argon2id memory is too high", ErrInvalidFormat)
+	}
+
+	// Delegate to registry
+	if err := kdf.Default().Validate(kdf.Parameters{
+		Iterations:  params.Iterations,
+		Memory:      uint32(params.Memory / 1024),
+		Parallelism: params.Parallelism,
+	}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// No error
+	return nil
+}
+
+// encodeParams serializes the derivation parameters. Argon2id uses the PASERK
+// layout (mem || time || para) with the memory cost in bytes, its most
+// significant byte is always zero. Other algorithms use
+// (id || 0x000000 || mem || time || para) with the memory cost in KiB.
+func encodeParams(alg kdf.Algorithm, params kdf.Parameters) []byte {
+	out := make([]byte, pwParamsLength)
+	if alg.Name() == kdf.Argon2id {
+		binary.BigEndian.PutUint64(out[0:8], uint64(params.Memory)*1024)
+	} else {
+		out[0] = alg.ID()
+		binary.BigEndian.PutUint32(out[4:8], params.Memory)
+	}
+	binary.BigEndian.PutUint32(out[8:12], params.Iterations)
+	binary.BigEndian.PutUint32(out[12:16], params.Parallelism)
+	return out
+}
+
+func decodeParams(raw []byte) (kdf.Algorithm, kdf.Parameters, error) {
+	// Dispatch on the embedded algorithm identifier
+	alg, err := kdf.GetByID(raw[0])
+	if err != nil {
+		return nil, kdf.Parameters{}, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// Argon2id standard layout
+	if alg.Name() == kdf.Argon2id {
+		params := &Argon2Parameters{
+			Memory:      binary.BigEndian.Uint64(raw[0:8]),
+			Iterations:  binary.BigEndian.Uint32(raw[8:12]),
+			Parallelism: binary.BigEndian.Uint32(raw[12:16]),
+		}
+		if err := validateParams(params); err != nil {
+			return nil, kdf.Parameters{}, err
+		}
+		return alg, kdf.Parameters{
+			Iterations:  params.Iterations,
+			Memory:      uint32(params.Memory / 1024),
+			Parallelism: params.Parallelism,
+		}, nil
+	}
+
+	// Check reserved bytes
+	if raw[1] != 0 || raw[2] != 0 || raw[3] != 0 {
+		return nil, kdf.Parameters{}, fmt.Errorf("%w: invalid key derivation parameters", ErrInvalidFormat)
+	}
+
+	// Validate parameters before derivation
+	params := kdf.Parameters{
+		Memory:      binary.BigEndian.Uint32(raw[4:8]),
+		Iterations:  binary.BigEndian.Uint32(raw[8:12]),
+		Parallelism: binary.BigEndian.Uint32(raw[12:16]),
+	}
+	if err := alg.Validate(params); err != nil {
+		return nil, kdf.Parameters{}, fmt.Errorf("%w: %v", ErrInvalidFormat, err)
+	}
+
+	// No error
+	return alg, params, nil
+}
@@ -0,0 +1,226 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/kdf"
+)
+
+var testPwOptions = []Option{
+	WithMemory(64 * 1024),
+	WithIterations(1),
+	WithParallelism(1),
+}
+
+func Test_LocalPw(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+	password := []byte("correct horse battery staple")
+
+	wrapped, err := WrapLocalPw(key, password, testPwOptions...)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "k4.local-pw."))
+
+	out, err := UnwrapLocalPw(wrapped, password)
+	assert.NoError(t, err)
+	assert.Equal(t, key, out)
+
+	// Parameters
+	params, err := PwParameters(wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, &Argon2Parameters{Memory: 64 * 1024, Iterations: 1, Parallelism: 1}, params)
+
+	// Wrong password
+	_, err = UnwrapLocalPw(wrapped, []byte("wrong"))
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+	// Wrong type
+	_, err = UnwrapSecretPw(wrapped, password)
+	assert.True(t, errors.Is(err, ErrUnexpectedType))
+}
+
+func Test_SecretPw(t *testing.T) {
+	sk := ed25519.PrivateKey(mustDecodeHex(t, secretKeyHex))
+	password := []byte("correct horse battery staple")
+
+	wrapped, err := WrapSecretPw(sk, password, testPwOptions...)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "k4.secret-pw."))
+
+	out, err := UnwrapSecretPw(wrapped, password)
+	assert.NoError(t, err)
+	assert.Equal(t, sk, out)
+}
+
+func Test_Pw_Tampered(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+	password := []byte("correct horse battery staple")
+
+	wrapped, err := WrapLocalPw(key, password, testPwOptions...)
+	assert.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(wrapped, localPwPrefix))
+	assert.NoError(t, err)
+
+	// Flip one encrypted key bit
+	raw[pwHeaderLength] ^= 0x01
+	_, err = UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(raw), password)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+	// Truncated
+	_, err = UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(raw[:pwHeaderLength]), password)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func Test_Pw_InvalidParameters(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+
+	_, err := WrapLocalPw(key, []byte("test"), WithIterations(0))
+	assert.Error(t, err)
+
+	_, err = WrapLocalPw(key, []byte("test"), WithMemory(1000))
+	assert.Error(t, err)
+
+	_, err = WrapLocalPw(key, []byte("test"), WithParallelism(0))
+	assert.Error(t, err)
+
+	_, err = WrapLocalPw(key, []byte("test"), WithIterations(1024))
+	assert.Error(t, err)
+
+	_, err = WrapLocalPw(key, []byte("test"), WithMemory(4 << 40))
+	assert.Error(t, err)
+}
+
+func Test_Pw_ForgedParameters(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+	password := []byte("correct horse battery staple")
+
+	wrapped, err := WrapLocalPw(key, password, testPwOptions...)
+	assert.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(wrapped, localPwPrefix))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		memory     uint64
+		iterations uint32
+	}{
+		{name: "memory", memory: 4 << 40, iterations: 1},
+		{name: "iterations", memory: 64 * 1024, iterations: 1 << 30},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			forged := append([]byte{}, raw...)
+			binary.BigEndian.PutUint64(forged[pwSaltLength:], testCase.memory)
+			binary.BigEndian.PutUint32(forged[pwSaltLength+8:], testCase.iterations)
+
+			// Rejected before derivation
+			_, err := UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(forged), password)
+			assert.True(t, errors.Is(err, ErrInvalidFormat))
+		})
+	}
+}
+
+func Test_Pw_KDF(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+	password := []byte("correct horse battery staple")
+
+	testCases := []struct {
+		name   string
+		alg    string
+		params kdf.Parameters
+	}{
+		{name: "argon2id", alg: kdf.Argon2id, params: kdf.Parameters{Iterations: 1, Memory: 64, Parallelism: 1}},
+		{name: "scrypt", alg: kdf.Scrypt, params: kdf.Parameters{Iterations: 1024, Memory: 128, Parallelism: 1}},
+		{name: "pbkdf2-sha256", alg: kdf.PBKDF2SHA256, params: kdf.Parameters{Iterations: 1000}},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			wrapped, err := WrapLocalPw(key, password, WithKDF(testCase.alg, testCase.params))
+			assert.NoError(t, err)
+
+			// Embedded algorithm
+			alg, params, err := PwKDF(wrapped)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.alg, alg)
+			assert.Equal(t, &testCase.params, params)
+
+			out, err := UnwrapLocalPw(wrapped, password)
+			assert.NoError(t, err)
+			assert.Equal(t, key, out)
+
+			// Wrong password
+			_, err = UnwrapLocalPw(wrapped, []byte("wrong"))
+			assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+		})
+	}
+}
+
+func Test_Pw_KDF_Invalid(t *testing.T) {
+	key := mustDecodeHex(t, localKeyHex)
+	password := []byte("correct horse battery staple")
+
+	// Unknown algorithm
+	_, err := WrapLocalPw(key, password, WithKDF("bcrypt", kdf.Parameters{}))
+	assert.True(t, errors.Is(err, kdf.ErrUnknownAlgorithm))
+
+	// Invalid parameters
+	_, err = WrapLocalPw(key, password, WithKDF(kdf.Scrypt, kdf.Parameters{Iterations: 1000, Memory: 128, Parallelism: 1}))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	wrapped, err := WrapLocalPw(key, password, WithKDF(kdf.PBKDF2SHA256, kdf.Parameters{Iterations: 1000}))
+	assert.NoError(t, err)
+
+	// Not an Argon2id wrapped key
+	_, err = PwParameters(wrapped)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(wrapped, localPwPrefix))
+	assert.NoError(t, err)
+
+	// Unknown algorithm identifier
+	raw[pwSaltLength] = 0xFF
+	_, err = UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(raw), password)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	// Forged scrypt parameters are rejected before derivation
+	forged := append([]byte{}, raw...)
+	forged[pwSaltLength] = 0x01
+	binary.BigEndian.PutUint32(forged[pwSaltLength+4:], kdf.MaxMemory)
+	binary.BigEndian.PutUint32(forged[pwSaltLength+8:], 2)
+	binary.BigEndian.PutUint32(forged[pwSaltLength+12:], 16)
+	_, err = UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(forged), password)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	// Tampered parameters are rejected before derivation
+	raw[pwSaltLength] = 0x02
+	raw[pwSaltLength+8] = 0xFF
+	_, err = UnwrapLocalPw(localPwPrefix+base64.RawURLEncoding.EncodeToString(raw), password)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
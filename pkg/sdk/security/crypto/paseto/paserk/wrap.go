@@ -0,0 +1,227 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/mac"
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+)
+
+const (
+	pieNonceLength = 32
+	pieTagLength   = 32
+	pieKDFLength   = 56
+	pieMaxLength   = pieTagLength + pieNonceLength + ed25519.PrivateKeySize
+)
+
+// WrapLocal encrypts the given symmetric key with the given wrapping key as a
+// `k4.local-wrap.pie` PASERK.
+func WrapLocal(wrappingKey, key []byte) (string, error) {
+	// Check arguments
+	if len(key) != LocalKeyLength {
+		return "", fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// Delegate to wrapper
+	return pieWrap(rand.Reader, localWrapPrefix, wrappingKey, key)
+}
+
+// UnwrapLocal decrypts the given `k4.local-wrap.pie` PASERK with the given
+// wrapping key.
+func UnwrapLocal(wrappingKey []byte, s string) ([]byte, error) {
+	// Delegate to unwrapper
+	key, err := pieUnwrap(localWrapPrefix, wrappingKey, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != LocalKeyLength {
+		return nil, fmt.Errorf("%w: local key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// No error
+	return key, nil
+}
+
+// WrapSecret encrypts the given Ed25519 private key with the given wrapping
+// key as a `k4.secret-wrap.pie` PASERK.
+func WrapSecret(wrappingKey []byte, sk ed25519.PrivateKey) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// Delegate to wrapper
+	return pieWrap(rand.Reader, secretWrapPrefix, wrappingKey, sk)
+}
+
+// UnwrapSecret decrypts the given `k4.secret-wrap.pie` PASERK with the given
+// wrapping key.
+func UnwrapSecret(wrappingKey []byte, s string) (ed25519.PrivateKey, error) {
+	// Delegate to unwrapper
+	raw, err := pieUnwrap(secretWrapPrefix, wrappingKey, s)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: secret key must be %d bytes long", ErrInvalidKey, ed25519.PrivateKeySize)
+	}
+
+	// No error
+	return ed25519.PrivateKey(raw), nil
+}
+
+// -----------------------------------------------------------------------------
+
+// https://github.com/paseto-standard/paserk/blob/master/operations/Wrap/pie.md
+func pieWrap(r io.Reader, h string, wk, ptk []byte) (string, error) {
+	// Check wrapping key
+	if len(wk) != LocalKeyLength {
+		return "", fmt.Errorf("%w: wrapping key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// Generate nonce
+	var n [pieNonceLength]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return "", fmt.Errorf("paserk: unable to generate random seed: %w", err)
+	}
+
+	// Derive keys
+	ek, n2, ak, err := pieKeys(wk, n[:])
+	if err != nil {
+		return "", err
+	}
+	defer secret.Wipe(ek)
+	defer secret.Wipe(ak)
+
+	// Encrypt the key
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return "", fmt.Errorf("paserk: unable to initialize XChaCha20 cipher: %w", err)
+	}
+	c := make([]byte, len(ptk))
+	ciph.XORKeyStream(c, ptk)
+
+	// Authenticate
+	t, err := pieTag(ak, h, n[:], c)
+	if err != nil {
+		return "", err
+	}
+
+	// Serialize content
+	// t || n || c
+	body := append([]byte{}, t...)
+	body = append(body, n[:]...)
+	body = append(body, c...)
+
+	// No error
+	return h + base64.RawURLEncoding.EncodeToString(body), nil
+}
+
+func pieUnwrap(h string, wk []byte, s string) ([]byte, error) {
+	// Check wrapping key
+	if len(wk) != LocalKeyLength {
+		return nil, fmt.Errorf("%w: wrapping key must be %d bytes long", ErrInvalidKey, LocalKeyLength)
+	}
+
+	// Decode content
+	raw, err := decode(h, s, pieMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) <= pieTagLength+pieNonceLength {
+		return nil, fmt.Errorf("%w: wrapped key is too short", ErrInvalidFormat)
+	}
+
+	// Extract components
+	t := raw[:pieTagLength]
+	n := raw[pieTagLength : pieTagLength+pieNonceLength]
+	c := raw[pieTagLength+pieNonceLength:]
+
+	// Derive keys
+	ek, n2, ak, err := pieKeys(wk, n)
+	if err != nil {
+		return nil, err
+	}
+	defer secret.Wipe(ek)
+	defer secret.Wipe(ak)
+
+	// Authenticate before decryption
+	t2, err := pieTag(ak, h, n, c)
+	if err != nil {
+		return nil, err
+	}
+	if !security.SecureCompare(t, t2) {
+		return nil, ErrAuthenticationFailed
+	}
+
+	// Decrypt the key
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return nil, fmt.Errorf("paserk: unable to initialize XChaCha20 cipher: %w", err)
+	}
+	ptk := make([]byte, len(c))
+	ciph.XORKeyStream(ptk, c)
+
+	// No error
+	return ptk, nil
+}
+
+func pieKeys(wk, n []byte) (ek, n2, ak []byte, err error) {
+	// Derive encryption key and nonce
+	encKDF, err := blake2b.New(pieKDFLength, wk)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("paserk: unable to initialize encryption kdf: %w", err)
+	}
+	encKDF.Write([]byte{0x80})
+	encKDF.Write(n)
+	x := encKDF.Sum(nil)
+
+	// Derive authentication key
+	authKDF, err := blake2b.New256(wk)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("paserk: unable to initialize authentication kdf: %w", err)
+	}
+	authKDF.Write([]byte{0x81})
+	authKDF.Write(n)
+
+	// No error
+	return x[:LocalKeyLength], x[LocalKeyLength:], authKDF.Sum(nil), nil
+}
+
+func pieTag(ak []byte, h string, n, c []byte) ([]byte, error) {
+	// Authenticate header and content
+	content := append([]byte(h), n...)
+	content = append(content, c...)
+	t, err := mac.Blake2bMAC(ak, content, 32)
+	if err != nil {
+		return nil, fmt.Errorf("paserk: unable to compute MAC: %w", err)
+	}
+
+	// No error
+	return t, nil
+}
@@ -0,0 +1,122 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paserk
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var wrappingKeyHex = "a0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebf"
+
+func Test_LocalWrap(t *testing.T) {
+	wk := mustDecodeHex(t, wrappingKeyHex)
+	key := mustDecodeHex(t, localKeyHex)
+
+	wrapped, err := WrapLocal(wk, key)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "k4.local-wrap.pie."))
+
+	out, err := UnwrapLocal(wk, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, key, out)
+
+	// Wrong wrapping key
+	_, err = UnwrapLocal(key, wrapped)
+	assert.True(t, errors.Is(err, ErrAuthenticationFailed))
+
+	// Wrong type
+	_, err = UnwrapSecret(wk, wrapped)
+	assert.True(t, errors.Is(err, ErrUnexpectedType))
+}
+
+func Test_SecretWrap(t *testing.T) {
+	wk := mustDecodeHex(t, wrappingKeyHex)
+	sk := ed25519.PrivateKey(mustDecodeHex(t, secretKeyHex))
+
+	wrapped, err := WrapSecret(wk, sk)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(wrapped, "k4.secret-wrap.pie."))
+
+	out, err := UnwrapSecret(wk, wrapped)
+	assert.NoError(t, err)
+	assert.Equal(t, sk, out)
+}
+
+func Test_Wrap_Tampered(t *testing.T) {
+	wk := mustDecodeHex(t, wrappingKeyHex)
+	key := mustDecodeHex(t, localKeyHex)
+
+	wrapped, err := WrapLocal(wk, key)
+	assert.NoError(t, err)
+
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(wrapped, localWrapPrefix))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		body    func() []byte
+		wantErr error
+	}{
+		{
+			name: "tag",
+			body: func() []byte {
+				out := append([]byte{}, raw...)
+				out[0] ^= 0x01
+				return out
+			},
+			wantErr: ErrAuthenticationFailed,
+		},
+		{
+			name: "ciphertext",
+			body: func() []byte {
+				out := append([]byte{}, raw...)
+				out[len(out)-1] ^= 0x01
+				return out
+			},
+			wantErr: ErrAuthenticationFailed,
+		},
+		{
+			name: "truncated key",
+			body: func() []byte {
+				return raw[:len(raw)-1]
+			},
+			wantErr: ErrAuthenticationFailed,
+		},
+		{
+			name: "truncated header",
+			body: func() []byte {
+				return raw[:pieTagLength+pieNonceLength]
+			},
+			wantErr: ErrInvalidFormat,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := UnwrapLocal(wk, localWrapPrefix+base64.RawURLEncoding.EncodeToString(testCase.body()))
+			assert.True(t, errors.Is(err, testCase.wantErr), "unexpected error %v", err)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+const (
+	// DefaultRedisKeyPrefix is the default Redis key prefix of consumed token
+	// identifiers.
+	DefaultRedisKeyPrefix = "harp:paseto:jti:"
+	// DefaultRedisTimeout is the default Redis command timeout.
+	DefaultRedisTimeout = 5 * time.Second
+)
+
+// RedisOption represents functional pattern builder for optional parameters.
+type RedisOption func(*redisOptions)
+
+type redisOptions struct {
+	keyPrefix string
+	timeout   time.Duration
+	clock     func() time.Time
+}
+
+// WithKeyPrefix sets the Redis key prefix of consumed token identifiers.
+func WithKeyPrefix(prefix string) RedisOption {
+	return func(opts *redisOptions) {
+		opts.keyPrefix = prefix
+	}
+}
+
+// WithTimeout sets the Redis command timeout.
+func WithTimeout(timeout time.Duration) RedisOption {
+	return func(opts *redisOptions) {
+		opts.timeout = timeout
+	}
+}
+
+// NewRedisGuard returns a replay guard storing the consumed token identifiers
+// in Redis. Identifiers are stored with the token expiration as TTL, and are
+// marked using `SET NX` so that concurrent uses of the same token from
+// different replicas are detected.
+func NewRedisGuard(client redis.Cmdable, opts ...RedisOption) (v4.ReplayGuard, error) {
+	// Check arguments
+	if types.IsNil(client) {
+		return nil, errors.New("replay: redis client must not be nil")
+	}
+
+	// Apply options
+	dopts := &redisOptions{
+		keyPrefix: DefaultRedisKeyPrefix,
+		timeout:   DefaultRedisTimeout,
+		clock:     time.Now,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// No error
+	return &redisGuard{
+		client: client,
+		opts:   dopts,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type redisGuard struct {
+	client redis.Cmdable
+	opts   *redisOptions
+}
+
+func (g *redisGuard) Seen(jti string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.opts.timeout)
+	defer cancel()
+
+	// Check key existence
+	count, err := g.client.Exists(ctx, g.opts.keyPrefix+jti).Result()
+	if err != nil {
+		return false, fmt.Errorf("replay: unable to check '%s' token identifier: %w", jti, err)
+	}
+
+	// No error
+	return count > 0, nil
+}
+
+func (g *redisGuard) Mark(jti string, exp time.Time) error {
+	// Compute retention
+	var ttl time.Duration
+	if !exp.IsZero() {
+		ttl = exp.Sub(g.opts.clock())
+		if ttl <= 0 {
+			// Already expired, nothing to retain.
+			return nil
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), g.opts.timeout)
+	defer cancel()
+
+	// Set only if not exists
+	ok, err := g.client.SetNX(ctx, g.opts.keyPrefix+jti, 1, ttl).Result()
+	if err != nil {
+		return fmt.Errorf("replay: unable to mark '%s' token identifier: %w", jti, err)
+	}
+	if !ok {
+		return v4.ErrReplayed
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package replay
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+)
+
+func testRedisGuard(t *testing.T, opts ...RedisOption) (*miniredis.Miniredis, v4.ReplayGuard) {
+	t.Helper()
+
+	srv := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: srv.Addr()})
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	g, err := NewRedisGuard(client, opts...)
+	require.NoError(t, err)
+
+	return srv, g
+}
+
+// -----------------------------------------------------------------------------
+
+func TestNewRedisGuard_NilClient(t *testing.T) {
+	_, err := NewRedisGuard(nil)
+	assert.Error(t, err)
+
+	var client *redis.Client
+	_, err = NewRedisGuard(client)
+	assert.Error(t, err)
+}
+
+func TestRedisGuard_Mark(t *testing.T) {
+	srv, g := testRedisGuard(t, WithKeyPrefix("test:"))
+
+	seen, err := g.Seen("reset-1")
+	assert.NoError(t, err)
+	assert.False(t, seen)
+
+	assert.NoError(t, g.Mark("reset-1", time.Now().Add(time.Hour)))
+	assert.True(t, errors.Is(g.Mark("reset-1", time.Now().Add(time.Hour)), v4.ErrReplayed))
+
+	seen, err = g.Seen("reset-1")
+	assert.NoError(t, err)
+	assert.True(t, seen)
+
+	// Retention follows the token expiration
+	assert.True(t, srv.Exists("test:reset-1"))
+	assert.InDelta(t, time.Hour.Seconds(), srv.TTL("test:reset-1").Seconds(), 5)
+	srv.FastForward(2 * time.Hour)
+
+	seen, err = g.Seen("reset-1")
+	assert.NoError(t, err)
+	assert.False(t, seen)
+}
+
+func TestRedisGuard_NoExpiration(t *testing.T) {
+	srv, g := testRedisGuard(t)
+
+	assert.NoError(t, g.Mark("forever", time.Time{}))
+	assert.True(t, srv.Exists(DefaultRedisKeyPrefix+"forever"))
+	assert.Equal(t, time.Duration(0), srv.TTL(DefaultRedisKeyPrefix+"forever"))
+
+	// Already expired
+	assert.NoError(t, g.Mark("expired", time.Now().Add(-time.Minute)))
+	assert.False(t, srv.Exists(DefaultRedisKeyPrefix+"expired"))
+}
+
+func TestRedisGuard_Unavailable(t *testing.T) {
+	srv, g := testRedisGuard(t, WithTimeout(time.Second))
+	srv.Close()
+
+	_, err := g.Seen("reset-1")
+	assert.Error(t, err)
+	assert.Error(t, g.Mark("reset-1", time.Time{}))
+}
+
+func TestRedisGuard_Parser(t *testing.T) {
+	_, g := testRedisGuard(t)
+
+	key := make([]byte, v4.KeyLength)
+	_, err := rand.Read(key)
+	require.NoError(t, err)
+
+	m, err := v4.NewClaims(time.Hour).Set("jti", hex.EncodeToString(key[:8])).Bytes()
+	require.NoError(t, err)
+	token, err := v4.Encrypt(rand.Reader, key, m, "", "")
+	require.NoError(t, err)
+
+	// Two replicas sharing the same guard
+	p1 := v4.NewParser(v4.WithExpiration(), v4.WithReplayGuard(g))
+	p2 := v4.NewParser(v4.WithExpiration(), v4.WithReplayGuard(g))
+
+	_, err = p1.ParseLocal(key, token, nil, nil)
+	assert.NoError(t, err)
+	_, err = p2.ParseLocal(key, token, nil, nil)
+	assert.True(t, errors.Is(err, v4.ErrReplayed))
+}
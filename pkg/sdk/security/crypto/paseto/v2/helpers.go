@@ -0,0 +1,272 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v2
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20poly1305"
+
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+const (
+	// KeyLength is the requested encryption key size.
+	KeyLength      = 32
+	nonceLength    = chacha20poly1305.NonceSizeX
+	tagLength      = chacha20poly1305.Overhead
+	v2LocalPrefix  = "v2.local."
+	v2PublicPrefix = "v2.public."
+)
+
+// PASETO v2 symmetric encryption primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version2.md#encrypt
+//
+// Deprecated: PASETO v2 is only supported to decrypt legacy tokens, use
+// v4.Encrypt to produce new tokens.
+func Encrypt(r io.Reader, key, m []byte, f string) ([]byte, error) {
+	// Create random seed
+	var b [nonceLength]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	}
+
+	// Delegate to primitive
+	return encrypt(key, b[:], m, f)
+}
+
+// PASETO v2 symmetric decryption primitive
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version2.md#decrypt
+func Decrypt(key, input []byte, f string) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, errors.New("paseto: key is nil")
+	}
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+	}
+	if input == nil {
+		return nil, errors.New("paseto: input is nil")
+	}
+
+	// Check token header
+	if !bytes.HasPrefix(input, []byte(v2LocalPrefix)) {
+		return nil, errors.New("paseto: invalid token")
+	}
+
+	// Trim prefix
+	input = input[len(v2LocalPrefix):]
+
+	// Check footer usage
+	input, err := checkFooter(input, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode token
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(input)))
+	if _, err := base64.RawURLEncoding.Strict().Decode(raw, input); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	}
+	if len(raw) < nonceLength+tagLength {
+		return nil, errors.New("paseto: invalid token body, too short")
+	}
+
+	// Extract components
+	n := raw[:nonceLength]
+	c := raw[nonceLength:]
+
+	// Compute additional data
+	preAuth := paseto.PreAuthenticationEncoding([]byte(v2LocalPrefix), n, []byte(f))
+
+	// Prepare XChaCha20-Poly1305 AEAD
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize XChaCha20-Poly1305 cipher: %w", err)
+	}
+
+	// Decrypt and authenticate the payload
+	m, err := aead.Open(nil, n, c, preAuth)
+	if err != nil {
+		return nil, errors.New("paseto: invalid pre-authentication header")
+	}
+
+	// No error
+	return m, nil
+}
+
+// PASETO v2 public signature primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version2.md#sign
+//
+// Deprecated: PASETO v2 is only supported to verify legacy tokens, use
+// v4.Sign to produce new tokens.
+func Sign(m []byte, sk ed25519.PrivateKey, f string) ([]byte, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("paseto: invalid private key length, it must be %d bytes long", ed25519.PrivateKeySize)
+	}
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding([]byte(v2PublicPrefix), m, []byte(f))
+
+	// Sign protected content
+	sig := ed25519.Sign(sk, m2)
+
+	// Prepare content
+	body := append([]byte{}, m...)
+	body = append(body, sig...)
+
+	// No error
+	return assemble(v2PublicPrefix, body, f), nil
+}
+
+// PASETO v2 signature verification primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version2.md#verify
+func Verify(sm []byte, pk ed25519.PublicKey, f string) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("paseto: invalid public key length, it must be %d bytes long", ed25519.PublicKeySize)
+	}
+
+	// Check token header
+	if !bytes.HasPrefix(sm, []byte(v2PublicPrefix)) {
+		return nil, errors.New("paseto: invalid token")
+	}
+
+	// Trim prefix
+	sm = sm[len(v2PublicPrefix):]
+
+	// Check footer usage
+	sm, err := checkFooter(sm, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode token
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(sm)))
+	if _, err := base64.RawURLEncoding.Strict().Decode(raw, sm); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	}
+	if len(raw) < ed25519.SignatureSize {
+		return nil, errors.New("paseto: invalid token body, too short")
+	}
+
+	// Extract components
+	m := raw[:len(raw)-ed25519.SignatureSize]
+	s := raw[len(raw)-ed25519.SignatureSize:]
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding([]byte(v2PublicPrefix), m, []byte(f))
+
+	// Check signature
+	if !ed25519.Verify(pk, m2, s) {
+		return nil, errors.New("paseto: invalid token signature")
+	}
+
+	// No error
+	return m, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func encrypt(key, b, m []byte, f string) ([]byte, error) {
+	// Check arguments
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+	}
+	if len(b) != nonceLength {
+		return nil, fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
+	}
+
+	// Derive the nonce from the random seed and the message
+	nonceHash, err := blake2b.New(nonceLength, b)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize nonce hash: %w", err)
+	}
+	nonceHash.Write(m)
+	n := nonceHash.Sum(nil)
+
+	// Compute additional data
+	preAuth := paseto.PreAuthenticationEncoding([]byte(v2LocalPrefix), n, []byte(f))
+
+	// Prepare XChaCha20-Poly1305 AEAD
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize XChaCha20-Poly1305 cipher: %w", err)
+	}
+
+	// Serialize final token
+	// h || base64url(n || c)
+	body := append([]byte{}, n...)
+	body = aead.Seal(body, n, m, preAuth)
+
+	// No error
+	return assemble(v2LocalPrefix, body, f), nil
+}
+
+func assemble(h string, body []byte, f string) []byte {
+	// Encode body as RawURLBase64
+	encodedBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(body)))
+	base64.RawURLEncoding.Encode(encodedBody, body)
+
+	// Assemble final token
+	final := append([]byte(h), encodedBody...)
+	if f != "" {
+		// Encode footer as RawURLBase64
+		encodedFooter := make([]byte, base64.RawURLEncoding.EncodedLen(len(f)))
+		base64.RawURLEncoding.Encode(encodedFooter, []byte(f))
+
+		// Assemble body and footer
+		final = append(final, append([]byte("."), encodedFooter...)...)
+	}
+
+	return final
+}
+
+func checkFooter(input []byte, f string) ([]byte, error) {
+	if f == "" {
+		return input, nil
+	}
+
+	// Split the footer and the body
+	parts := bytes.SplitN(input, []byte("."), 2)
+	if len(parts) != 2 {
+		return nil, errors.New("paseto: invalid token, footer is missing but expected")
+	}
+
+	// Decode footer
+	footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(parts[1])))
+	if _, err := base64.RawURLEncoding.Strict().Decode(footer, parts[1]); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
+	}
+
+	// Compare footer
+	if !security.SecureCompare([]byte(f), footer) {
+		return nil, errors.New("paseto: invalid token, footer mismatch")
+	}
+
+	// Continue without footer
+	return parts[0], nil
+}
@@ -0,0 +1,272 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v2
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// https://github.com/paseto-standard/test-vectors/blob/master/v2.json
+func Test_Paseto_LocalVector(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expectFail bool
+		key        string
+		nonce      string
+		token      string
+		payload    string
+		footer     string
+	}{
+		{
+			name:       "2-E-1",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "000000000000000000000000000000000000000000000000",
+			token:      "v2.local.97TTOvgwIxNGvV80XKiGZg_kD3tsXM_-qB4dZGHOeN1cTkgQ4PnW8888l802W8d9AvEGnoNBY3BnqHORy8a5cC8aKpbA0En8XELw2yDk2f1sVODyfnDbi6rEGMY3pSfCbLWMM2oHJxvlEl2XbQ",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "",
+		},
+		{
+			name:       "2-E-2",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "000000000000000000000000000000000000000000000000",
+			token:      "v2.local.CH50H-HM5tzdK4kOmQ8KbIvrzJfjYUGuu5Vy9ARSFHy9owVDMYg3-8rwtJZQjN9ABHb2njzFkvpr5cOYuRyt7CRXnHt42L5yZ7siD-4l-FoNsC7J2OlvLlIwlG06mzQVunrFNb7Z3_CHM0PK5w",
+			payload:    "{\"data\":\"this is a secret message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "",
+		},
+		{
+			name:       "2-E-3",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.5K4SCXNhItIhyNuVIZcwrdtaDKiyF81-eWHScuE0idiVqCo72bbjo07W05mqQkhLZdVbxEa5I_u5sgVk1QLkcWEcOSlLHwNpCkvmGGlbCdNExn6Qclw3qTKIIl5-O5xRBN076fSDPo5xUCPpBA",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "",
+		},
+		{
+			name:       "2-E-4",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.pvFdDeNtXxknVPsbBCZF6MGedVhPm40SneExdClOxa9HNR8wFv7cu1cB0B4WxDdT6oUc2toyLR6jA6sc-EUM5ll1EkeY47yYk6q8m1RCpqTIzUrIu3B6h232h62DPbIxtjGvNRAwsLK7LcV8oQ",
+			payload:    "{\"data\":\"this is a secret message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "",
+		},
+		{
+			name:       "2-E-5",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.5K4SCXNhItIhyNuVIZcwrdtaDKiyF81-eWHScuE0idiVqCo72bbjo07W05mqQkhLZdVbxEa5I_u5sgVk1QLkcWEcOSlLHwNpCkvmGGlbCdNExn6Qclw3qTKIIl5-zSLIrxZqOLwcFLYbVK1SrQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-E-6",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.pvFdDeNtXxknVPsbBCZF6MGedVhPm40SneExdClOxa9HNR8wFv7cu1cB0B4WxDdT6oUc2toyLR6jA6sc-EUM5ll1EkeY47yYk6q8m1RCpqTIzUrIu3B6h232h62DnMXKdHn_Smp6L_NfaEnZ-A.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a secret message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-E-7",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.5K4SCXNhItIhyNuVIZcwrdtaDKiyF81-eWHScuE0idiVqCo72bbjo07W05mqQkhLZdVbxEa5I_u5sgVk1QLkcWEcOSlLHwNpCkvmGGlbCdNExn6Qclw3qTKIIl5-zSLIrxZqOLwcFLYbVK1SrQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-E-8",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.pvFdDeNtXxknVPsbBCZF6MGedVhPm40SneExdClOxa9HNR8wFv7cu1cB0B4WxDdT6oUc2toyLR6jA6sc-EUM5ll1EkeY47yYk6q8m1RCpqTIzUrIu3B6h232h62DnMXKdHn_Smp6L_NfaEnZ-A.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a secret message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-E-9",
+			expectFail: false,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "45742c976d684ff84ebdc0de59809a97cda2f64c84fda19b",
+			token:      "v2.local.pvFdDeNtXxknVPsbBCZF6MGedVhPm40SneExdClOxa9HNR8wFv7cu1cB0B4WxDdT6oUc2toyLR6jA6sc-EUM5ll1EkeY47yYk6q8m1RCpqTIzUrIu3B6h232h62DoOJbyKBGPZG50XDZ6mbPtw.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24",
+			payload:    "{\"data\":\"this is a secret message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "arbitrary-string-that-isn't-json",
+		},
+		{
+			name:       "2-F-2",
+			expectFail: true,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "df654812bac492663825520ba2f6e67cf5ca5bdc13d4e7507a98cc4c2fcc3ad8",
+			token:      "v2.public.eyJpbnZhbGlkIjoidGhpcyBzaG91bGQgbmV2ZXIgZGVjb2RlIn1kgrdAMxcO3wFKXJrLa1cq-DB6V_b25KQ1hV_jpOS-uYBmsg8EMS4j6kl2g83iRsh73knLGr7Ik1AEOvUgyw0P.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-F-3",
+			expectFail: true,
+			key:        "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			nonce:      "26f7553354482a1d91d4784627854b8da6b8042a7966523c2b404e8dbbe7f7f2",
+			token:      "v1.local.vXWMCh8nxf_RMqrLREJVOWyu01yRzb-miB6mkG1zQ8LS4_W5nQdTOpexZq482ReJ0sv5uFfAWRGpJaONiMqFaAAo-dsbWG2vo63xUmwFGxHNhu9plfFav2SaGDERFGn7IQ20gNQl87eOLaxf2GDsWdfu5hrFaQ.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24",
+			payload:    "",
+			footer:     "arbitrary-string-that-isn't-json",
+		},
+	}
+
+	// For each testcase
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			// Decode input
+			key, err := hex.DecodeString(testCase.key)
+			assert.NoError(t, err)
+			n, err := hex.DecodeString(testCase.nonce)
+			assert.NoError(t, err)
+
+			if !testCase.expectFail {
+				// Encrypt
+				token, err := encrypt(key, n, []byte(testCase.payload), testCase.footer)
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.token, string(token))
+			}
+
+			// Decrypt
+			message, err := Decrypt(key, []byte(testCase.token), testCase.footer)
+			if (err != nil) != testCase.expectFail {
+				t.Errorf("error during the decrypt call, error = %v, wantErr %v", err, testCase.expectFail)
+				return
+			}
+			if !testCase.expectFail {
+				assert.Equal(t, testCase.payload, string(message))
+			}
+		})
+	}
+}
+
+// https://github.com/paseto-standard/test-vectors/blob/master/v2.json
+func Test_Paseto_PublicVector(t *testing.T) {
+	testCases := []struct {
+		name       string
+		expectFail bool
+		publicKey  string
+		secretKey  string
+		token      string
+		payload    string
+		footer     string
+	}{
+		{
+			name:       "2-S-1",
+			expectFail: false,
+			publicKey:  "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			secretKey:  "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			token:      "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAxOS0wMS0wMVQwMDowMDowMCswMDowMCJ9HQr8URrGntTu7Dz9J2IF23d1M7-9lH9xiqdGyJNvzp4angPW5Esc7C5huy_M8I8_DjJK2ZXC2SUYuOFM-Q_5Cw",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "",
+		},
+		{
+			name:       "2-S-2",
+			expectFail: false,
+			publicKey:  "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			secretKey:  "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			token:      "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAxOS0wMS0wMVQwMDowMDowMCswMDowMCJ9flsZsx_gYCR0N_Ec2QxJFFpvQAs7h9HtKwbVK2n1MJ3Rz-hwe8KUqjnd8FAnIJZ601tp7lGkguU63oGbomhoBw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-S-3",
+			expectFail: false,
+			publicKey:  "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			secretKey:  "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			token:      "v2.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAxOS0wMS0wMVQwMDowMDowMCswMDowMCJ9flsZsx_gYCR0N_Ec2QxJFFpvQAs7h9HtKwbVK2n1MJ3Rz-hwe8KUqjnd8FAnIJZ601tp7lGkguU63oGbomhoBw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9",
+			payload:    "{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}",
+			footer:     "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		},
+		{
+			name:       "2-F-1",
+			expectFail: true,
+			publicKey:  "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			secretKey:  "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2",
+			token:      "v2.local.pN9Y9kTFKnCskKr7B13IoceBabSTMS0LkUg3SeAqONg6EJsq9h-CLWdWaA_rMZX4MhGsOQn5I0EsIgYeOA2NPJZU0uulsahH-k871PBq.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24",
+			payload:    "",
+			footer:     "arbitrary-string-that-isn't-json",
+		},
+	}
+
+	// For each testcase
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			// Decode input
+			pk, err := hex.DecodeString(testCase.publicKey)
+			assert.NoError(t, err)
+			sk, err := hex.DecodeString(testCase.secretKey)
+			assert.NoError(t, err)
+
+			if !testCase.expectFail {
+				// Sign (Ed25519 is deterministic)
+				token, err := Sign([]byte(testCase.payload), ed25519.PrivateKey(sk), testCase.footer)
+				assert.NoError(t, err)
+				assert.Equal(t, testCase.token, string(token))
+			}
+
+			// Verify
+			message, err := Verify([]byte(testCase.token), ed25519.PublicKey(pk), testCase.footer)
+			if (err != nil) != testCase.expectFail {
+				t.Errorf("error during the verify call, error = %v, wantErr %v", err, testCase.expectFail)
+				return
+			}
+			if !testCase.expectFail {
+				assert.Equal(t, testCase.payload, string(message))
+			}
+		})
+	}
+}
+
+func Test_Paseto_Local_EncryptDecrypt(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2019-01-01T00:00:00+00:00\"}")
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+
+	token, err := Encrypt(rand.Reader, key, m, f)
+	assert.NoError(t, err)
+
+	p, err := Decrypt(key, token, f)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Tampered footer
+	_, err = Decrypt(key, token, f[:len(f)-1])
+	assert.Error(t, err)
+
+	// Tampered body
+	token[len(v2LocalPrefix)+1] ^= 0x01
+	_, err = Decrypt(key, token, f)
+	assert.Error(t, err)
+}
@@ -0,0 +1,355 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+const (
+	// KeyLength is the requested encryption key size.
+	KeyLength               = 32
+	nonceLength             = 32
+	macLength               = 48
+	encryptionKDFLength     = 48
+	authenticationKeyLength = 48
+	signatureLength         = 96
+	scalarLength            = 48
+	v3LocalPrefix           = "v3.local."
+	v3PublicPrefix          = "v3.public."
+)
+
+// PASETO v3 symmetric encryption primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#encrypt
+func Encrypt(r io.Reader, key, m []byte, f, i string) ([]byte, error) {
+	// Create random seed
+	var n [nonceLength]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	}
+
+	// Delegate to primitive
+	return encrypt(key, n[:], m, f, i)
+}
+
+// PASETO v3 symmetric decryption primitive
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#decrypt
+func Decrypt(key, input []byte, f, i string) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, errors.New("paseto: key is nil")
+	}
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+	}
+	if input == nil {
+		return nil, errors.New("paseto: input is nil")
+	}
+
+	// Check token header
+	if !bytes.HasPrefix(input, []byte(v3LocalPrefix)) {
+		return nil, errors.New("paseto: invalid token")
+	}
+
+	// Trim prefix
+	input = input[len(v3LocalPrefix):]
+
+	// Check footer usage
+	input, err := checkFooter(input, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode token
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(input)))
+	if _, err := base64.RawURLEncoding.Strict().Decode(raw, input); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	}
+	if len(raw) < nonceLength+macLength {
+		return nil, errors.New("paseto: invalid token body, too short")
+	}
+
+	// Extract components
+	n := raw[:nonceLength]
+	t := raw[len(raw)-macLength:]
+	c := raw[nonceLength : len(raw)-macLength]
+
+	// Derive keys from seed and secret key
+	ek, n2, ak, err := kdf(key, n)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	}
+
+	// Compute MAC
+	t2, err := mac(ak, v3LocalPrefix, n, c, f, i)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute MAC: %w", err)
+	}
+
+	// Time-constant compare MAC
+	if !security.SecureCompare(t, t2) {
+		return nil, errors.New("paseto: invalid pre-authentication header")
+	}
+
+	// Prepare AES-256-CTR stream cipher
+	block, err := aes.NewCipher(ek)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize AES cipher: %w", err)
+	}
+	ciph := cipher.NewCTR(block, n2)
+
+	// Decrypt the payload
+	m := make([]byte, len(c))
+	ciph.XORKeyStream(m, c)
+
+	// No error
+	return m, nil
+}
+
+// PASETO v3 public signature primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#sign
+func Sign(m []byte, sk *ecdsa.PrivateKey, f, i string) ([]byte, error) {
+	// Check arguments
+	if sk == nil {
+		return nil, errors.New("paseto: private key is nil")
+	}
+	if sk.Curve != elliptic.P384() {
+		return nil, errors.New("paseto: invalid private key curve, it must be P-384")
+	}
+
+	// Compress public key point
+	pk := elliptic.MarshalCompressed(sk.Curve, sk.X, sk.Y)
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding(pk, []byte(v3PublicPrefix), m, []byte(f), []byte(i))
+
+	// Sign protected content
+	digest := sha512.Sum384(m2)
+	r, s, err := ecdsa.Sign(rand.Reader, sk, digest[:])
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to sign content: %w", err)
+	}
+
+	// Serialize signature as r || s
+	sig := make([]byte, signatureLength)
+	r.FillBytes(sig[:scalarLength])
+	s.FillBytes(sig[scalarLength:])
+
+	// Prepare content
+	body := append([]byte{}, m...)
+	body = append(body, sig...)
+
+	// No error
+	return assemble(v3PublicPrefix, body, f), nil
+}
+
+// PASETO v3 signature verification primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#verify
+func Verify(sm []byte, pk *ecdsa.PublicKey, f, i string) ([]byte, error) {
+	// Check arguments
+	if pk == nil {
+		return nil, errors.New("paseto: public key is nil")
+	}
+	if pk.Curve != elliptic.P384() {
+		return nil, errors.New("paseto: invalid public key curve, it must be P-384")
+	}
+
+	// Check token header
+	if !bytes.HasPrefix(sm, []byte(v3PublicPrefix)) {
+		return nil, errors.New("paseto: invalid token")
+	}
+
+	// Trim prefix
+	sm = sm[len(v3PublicPrefix):]
+
+	// Check footer usage
+	sm, err := checkFooter(sm, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode token
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(sm)))
+	if _, err := base64.RawURLEncoding.Strict().Decode(raw, sm); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	}
+	if len(raw) < signatureLength {
+		return nil, errors.New("paseto: invalid token body, too short")
+	}
+
+	// Extract components
+	m := raw[:len(raw)-signatureLength]
+	sig := raw[len(raw)-signatureLength:]
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding(elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y), []byte(v3PublicPrefix), m, []byte(f), []byte(i))
+
+	// Check signature
+	digest := sha512.Sum384(m2)
+	r := new(big.Int).SetBytes(sig[:scalarLength])
+	s := new(big.Int).SetBytes(sig[scalarLength:])
+	if !ecdsa.Verify(pk, digest[:], r, s) {
+		return nil, errors.New("paseto: invalid token signature")
+	}
+
+	// No error
+	return m, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func encrypt(key, n, m []byte, f, i string) ([]byte, error) {
+	// Check arguments
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+	}
+	if len(n) != nonceLength {
+		return nil, fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
+	}
+
+	// Derive keys from seed and secret key
+	ek, n2, ak, err := kdf(key, n)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	}
+
+	// Prepare AES-256-CTR stream cipher
+	block, err := aes.NewCipher(ek)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize AES cipher: %w", err)
+	}
+	ciph := cipher.NewCTR(block, n2)
+
+	// Encrypt the payload
+	c := make([]byte, len(m))
+	ciph.XORKeyStream(c, m)
+
+	// Compute MAC
+	t, err := mac(ak, v3LocalPrefix, n, c, f, i)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute MAC: %w", err)
+	}
+
+	// Serialize final token
+	// h || base64url(n || c || t)
+	body := append([]byte{}, n...)
+	body = append(body, c...)
+	body = append(body, t...)
+
+	// No error
+	return assemble(v3LocalPrefix, body, f), nil
+}
+
+func assemble(h string, body []byte, f string) []byte {
+	// Encode body as RawURLBase64
+	encodedBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(body)))
+	base64.RawURLEncoding.Encode(encodedBody, body)
+
+	// Assemble final token
+	final := append([]byte(h), encodedBody...)
+	if f != "" {
+		// Encode footer as RawURLBase64
+		encodedFooter := make([]byte, base64.RawURLEncoding.EncodedLen(len(f)))
+		base64.RawURLEncoding.Encode(encodedFooter, []byte(f))
+
+		// Assemble body and footer
+		final = append(final, append([]byte("."), encodedFooter...)...)
+	}
+
+	return final
+}
+
+func checkFooter(input []byte, f string) ([]byte, error) {
+	if f == "" {
+		return input, nil
+	}
+
+	// Split the footer and the body
+	parts := bytes.SplitN(input, []byte("."), 2)
+	if len(parts) != 2 {
+		return nil, errors.New("paseto: invalid token, footer is missing but expected")
+	}
+
+	// Decode footer
+	footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(parts[1])))
+	if _, err := base64.RawURLEncoding.Strict().Decode(footer, parts[1]); err != nil {
+		return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
+	}
+
+	// Compare footer
+	if !security.SecureCompare([]byte(f), footer) {
+		return nil, errors.New("paseto: invalid token, footer mismatch")
+	}
+
+	// Continue without footer
+	return parts[0], nil
+}
+
+func kdf(key, n []byte) (ek, n2, ak []byte, err error) {
+	// Derive encryption key
+	encKDF := hkdf.New(sha512.New384, key, nil, append([]byte("paseto-encryption-key"), n...))
+	tmp := make([]byte, encryptionKDFLength)
+	if _, err := io.ReadFull(encKDF, tmp); err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to derive encryption key: %w", err)
+	}
+
+	// Split encryption key (Ek) and nonce (n2)
+	ek = tmp[:KeyLength]
+	n2 = tmp[KeyLength:]
+
+	// Derive authentication key
+	authKDF := hkdf.New(sha512.New384, key, nil, append([]byte("paseto-auth-key-for-aead"), n...))
+	ak = make([]byte, authenticationKeyLength)
+	if _, err := io.ReadFull(authKDF, ak); err != nil {
+		return nil, nil, nil, fmt.Errorf("unable to derive authentication key: %w", err)
+	}
+
+	// No error
+	return ek, n2, ak, nil
+}
+
+func mac(ak []byte, h string, n, c []byte, f, i string) ([]byte, error) {
+	// Compute pre-authentication message
+	preAuth := paseto.PreAuthenticationEncoding([]byte(h), n, c, []byte(f), []byte(i))
+
+	// Compute MAC
+	mac := hmac.New(sha512.New384, ak)
+
+	// Hash pre-authentication content
+	mac.Write(preAuth)
+
+	// No error
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,104 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+// Footer and implicit assertion must be separate PAE pieces, moving bytes
+// from one to the other must invalidate the token.
+func Test_Paseto_FooterImplicitConfusion(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+	m := []byte(`{"data":"this is a signed message"}`)
+	digest := sha512.Sum512(m)
+
+	// Replace the token footer segment with the given footer.
+	forgeFooter := func(token []byte, f string) []byte {
+		idx := bytes.LastIndexByte(token, '.')
+		return append(append([]byte{}, token[:idx+1]...), base64.RawURLEncoding.EncodeToString([]byte(f))...)
+	}
+
+	t.Run("pae", func(t *testing.T) {
+		assert.NotEqual(t,
+			paseto.PreAuthenticationEncoding([]byte(v4LocalPrefix), m, []byte("ab"), []byte("c")),
+			paseto.PreAuthenticationEncoding([]byte(v4LocalPrefix), m, []byte("a"), []byte("bc")),
+		)
+	})
+
+	t.Run("local", func(t *testing.T) {
+		token, err := Encrypt(rand.Reader, key, m, "ab", "c")
+		assert.NoError(t, err)
+
+		_, err = Decrypt(key, token, "ab", "c")
+		assert.NoError(t, err)
+
+		forged := forgeFooter(token, "a")
+		_, err = Decrypt(key, forged, "a", "bc")
+		assert.True(t, errors.Is(err, ErrInvalidMAC), "unexpected error %v", err)
+	})
+
+	t.Run("public", func(t *testing.T) {
+		token, err := Sign(m, sk, "ab", "c")
+		assert.NoError(t, err)
+
+		_, err = Verify(token, pk, "ab", "c")
+		assert.NoError(t, err)
+
+		forged := forgeFooter(token, "a")
+		_, err = Verify(forged, pk, "a", "bc")
+		assert.True(t, errors.Is(err, ErrInvalidSignature), "unexpected error %v", err)
+	})
+
+	t.Run("public pre-hashed", func(t *testing.T) {
+		token, err := SignPreHashed(digest[:], sk, "ab", "c")
+		assert.NoError(t, err)
+
+		forged := forgeFooter(token, "a")
+		_, err = Verify(forged, pk, "a", "bc")
+		assert.True(t, errors.Is(err, ErrInvalidSignature), "unexpected error %v", err)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		er, err := EncryptStream(rand.Reader, key, bytes.NewReader(m), "ab", "c")
+		assert.NoError(t, err)
+		c, err := io.ReadAll(er)
+		assert.NoError(t, err)
+
+		dr, err := DecryptStream(key, bytes.NewReader(c), "a", "bc")
+		if err == nil {
+			_, err = io.ReadAll(dr)
+		}
+		assert.True(t, errors.Is(err, ErrInvalidMAC), "unexpected error %v", err)
+	})
+}
@@ -0,0 +1,261 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"fmt"
+	"sort"
+	"strings"
+
+	"filippo.io/edwards25519"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+// minBatchSize is the batch size under which sequential verification is
+// faster than the multi-scalar multiplication.
+const minBatchSize = 4
+
+// BatchResult is the verification result of a batch token.
+type BatchResult struct {
+	// Payload is the verified token payload.
+	Payload []byte
+	// Err is the verification error, nil when the token is valid.
+	Err error
+}
+
+// ErrBatchVerification is raised when at least one batch token is invalid.
+type ErrBatchVerification struct {
+	// Invalid lists the indexes of the invalid tokens.
+	Invalid []int
+}
+
+func (e *ErrBatchVerification) Error() string {
+	indexes := make([]string, len(e.Invalid))
+	for i, idx := range e.Invalid {
+		indexes[i] = fmt.Sprintf("%d", idx)
+	}
+	return fmt.Sprintf("paseto: %d invalid token(s) in batch at index [%s]", len(e.Invalid), strings.Join(indexes, ", "))
+}
+
+// Unwrap returns the ErrInvalidSignature sentinel error.
+func (e *ErrBatchVerification) Unwrap() error {
+	return ErrInvalidSignature
+}
+
+// VerifyBatch verifies the given v4.public tokens signed by the same key.
+// Results are returned in the token order, an ErrBatchVerification listing
+// the invalid token indexes is returned when at least one token is invalid.
+//
+// Signatures are checked together with a randomized Ed25519 batch equation.
+// When the batch equation fails, or the batch is too small to be beneficial,
+// tokens are verified sequentially to identify the invalid ones.
+//
+// The batch equation is cofactorless and signatures with a small order
+// component in R or in the public key are delegated to the sequential path, so
+// that the batch accepts exactly the tokens accepted by Verify.
+func VerifyBatch(tokens [][]byte, pk ed25519.PublicKey, f, i string) ([]BatchResult, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PublicKeySize, len(pk))
+	}
+
+	results := make([]BatchResult, len(tokens))
+	limits := DefaultLimits()
+
+	// Decode batch candidates
+	candidates := make([]*batchEntry, 0, len(tokens))
+	for idx, token := range tokens {
+		e, err := newBatchEntry(token, pk, f, i, limits)
+		if err != nil {
+			results[idx].Err = err
+			continue
+		}
+		if e == nil {
+			// Not batchable, verify it directly
+			results[idx].Payload, results[idx].Err = verifyWithLimits(context.Background(), token, pk, f, i, limits)
+			continue
+		}
+		e.index = idx
+		candidates = append(candidates, e)
+	}
+
+	// Check all signatures at once
+	if len(candidates) < minBatchSize || !verifyBatchEquation(candidates, pk) {
+		// Sequential verification to identify invalid tokens
+		for _, e := range candidates {
+			if !ed25519.Verify(pk, e.message, e.signature) {
+				results[e.index].Err = ErrInvalidSignature
+				continue
+			}
+			results[e.index].Payload = e.payload
+		}
+	} else {
+		for _, e := range candidates {
+			results[e.index].Payload = e.payload
+		}
+	}
+
+	// Collect invalid tokens
+	invalid := []int{}
+	for idx := range results {
+		if results[idx].Err != nil {
+			invalid = append(invalid, idx)
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Ints(invalid)
+		return results, &ErrBatchVerification{Invalid: invalid}
+	}
+
+	// No error
+	return results, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type batchEntry struct {
+	index     int
+	payload   []byte
+	message   []byte
+	signature []byte
+	r         *edwards25519.Point
+	s         *edwards25519.Scalar
+	k         *edwards25519.Scalar
+}
+
+// newBatchEntry decodes the token and prepares the batch equation terms. A nil
+// entry is returned for tokens which must be verified by the generic path.
+func newBatchEntry(token []byte, pk ed25519.PublicKey, f, i string, l Limits) (*batchEntry, error) {
+	// Pre-hashed tokens use a dedicated verifier
+	if !bytes.HasPrefix(token, []byte(v4PublicPrefix)) {
+		return nil, nil
+	}
+
+	// Decode token
+	raw, footer, err := parseToken(token, v4PublicPrefix, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < ed25519.SignatureSize {
+		return nil, ErrMalformedToken{Segment: segmentPayload, Reason: "payload is too short"}
+	}
+	if !ConstantTimeFooterEqual([]byte(f), footer) {
+		return nil, ErrFooterMismatch
+	}
+
+	// Split payload and signature
+	m := raw[:len(raw)-ed25519.SignatureSize]
+	sig := raw[len(raw)-ed25519.SignatureSize:]
+	msg := paseto.PreAuthenticationEncoding([]byte(v4PublicPrefix), m, []byte(f), []byte(i))
+	e := &batchEntry{
+		payload:   m,
+		message:   msg,
+		signature: sig,
+	}
+
+	// Decode signature components, non canonical encodings and points with a
+	// small order component are delegated to the sequential path.
+	r, err := new(edwards25519.Point).SetBytes(sig[:32])
+	if err != nil || !bytes.Equal(r.Bytes(), sig[:32]) || !isTorsionFree(r) {
+		return nil, nil
+	}
+	s, err := new(edwards25519.Scalar).SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return nil, nil
+	}
+
+	// k = SHA512(R || A || M)
+	h := sha512.New()
+	h.Write(sig[:32])
+	h.Write(pk)
+	h.Write(msg)
+	k, err := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute signature challenge: %w", err)
+	}
+
+	e.r, e.s, e.k = r, s, k
+	return e, nil
+}
+
+// verifyBatchEquation checks [Σzᵢsᵢ]B - Σ[zᵢ]Rᵢ - [Σzᵢkᵢ]A = 0 with random
+// 128-bit zᵢ coefficients. All points are torsion free, so that the equation
+// holds only if every signature satisfies the cofactorless equation checked by
+// ed25519.Verify.
+func verifyBatchEquation(entries []*batchEntry, pk ed25519.PublicKey) bool {
+	// Decode public key
+	a, err := new(edwards25519.Point).SetBytes(pk)
+	if err != nil || !isTorsionFree(a) {
+		return false
+	}
+
+	bCoeff := edwards25519.NewScalar()
+	aCoeff := edwards25519.NewScalar()
+	scalars := make([]*edwards25519.Scalar, 0, len(entries)+2)
+	points := make([]*edwards25519.Point, 0, len(entries)+2)
+
+	for _, e := range entries {
+		// Draw a random coefficient
+		var buf [32]byte
+		if _, err := rand.Read(buf[:16]); err != nil {
+			return false
+		}
+		z, err := new(edwards25519.Scalar).SetCanonicalBytes(buf[:])
+		if err != nil {
+			return false
+		}
+
+		// Accumulate terms
+		bCoeff.MultiplyAdd(z, e.s, bCoeff)
+		aCoeff.MultiplyAdd(z, e.k, aCoeff)
+		scalars = append(scalars, new(edwards25519.Scalar).Negate(z))
+		points = append(points, e.r)
+	}
+
+	scalars = append(scalars, bCoeff, new(edwards25519.Scalar).Negate(aCoeff))
+	points = append(points, edwards25519.NewGeneratorPoint(), a)
+
+	// Compute the combination
+	check := new(edwards25519.Point).VarTimeMultiScalarMult(scalars, points)
+
+	return check.Equal(edwards25519.NewIdentityPoint()) == 1
+}
+
+// minusOne is the L-1 scalar.
+var minusOne = new(edwards25519.Scalar).Negate(scalarOne())
+
+func scalarOne() *edwards25519.Scalar {
+	var buf [32]byte
+	buf[0] = 1
+	s, _ := new(edwards25519.Scalar).SetCanonicalBytes(buf[:])
+	return s
+}
+
+// isTorsionFree reports whether the point belongs to the prime order subgroup,
+// [L]P = [L-1]P + P must be the identity.
+func isTorsionFree(p *edwards25519.Point) bool {
+	q := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(minusOne, p, edwards25519.NewScalar())
+	q.Add(q, p)
+	return q.Equal(edwards25519.NewIdentityPoint()) == 1
+}
@@ -0,0 +1,238 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"testing"
+
+	"filippo.io/edwards25519"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+func batchTokens(t testing.TB, sk ed25519.PrivateKey, count int, f, i string) [][]byte {
+	tokens := make([][]byte, count)
+	for idx := range tokens {
+		token, err := Sign([]byte(fmt.Sprintf(`{"data":"message %d"}`, idx)), sk, f, i)
+		assert.NoError(t, err)
+		tokens[idx] = token
+	}
+	return tokens
+}
+
+func Test_VerifyBatch(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := `{"kid":"batch"}`
+	i := `{"purpose":"test"}`
+
+	for _, count := range []int{0, 1, minBatchSize - 1, minBatchSize, 32} {
+		size := count
+		t.Run(fmt.Sprintf("valid-%d", size), func(t *testing.T) {
+			tokens := batchTokens(t, sk, size, f, i)
+
+			results, err := VerifyBatch(tokens, pk, f, i)
+			assert.NoError(t, err)
+			assert.Len(t, results, size)
+			for idx, r := range results {
+				assert.NoError(t, r.Err)
+				assert.Equal(t, []byte(fmt.Sprintf(`{"data":"message %d"}`, idx)), r.Payload)
+			}
+		})
+	}
+}
+
+func Test_VerifyBatch_Invalid(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := `{"kid":"batch"}`
+	i := `{"purpose":"test"}`
+
+	tokens := batchTokens(t, sk, 16, f, i)
+
+	// Tampered signature
+	tampered := append([]byte{}, tokens[3]...)
+	pos := len(tampered) - len(f)*4/3 - 4
+	if tampered[pos] == 'A' {
+		tampered[pos] = 'B'
+	} else {
+		tampered[pos] = 'A'
+	}
+	tokens[3] = tampered
+
+	// Signed with another footer
+	other, err := Sign([]byte("other"), sk, `{"kid":"other"}`, i)
+	assert.NoError(t, err)
+	tokens[7] = other
+
+	// Malformed token
+	tokens[11] = []byte("v4.public.")
+
+	// Signed by another key
+	_, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	foreign, err := Sign([]byte("foreign"), sk2, f, i)
+	assert.NoError(t, err)
+	tokens[14] = foreign
+
+	results, err := VerifyBatch(tokens, pk, f, i)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	var batchErr *ErrBatchVerification
+	assert.True(t, errors.As(err, &batchErr))
+	assert.Equal(t, []int{3, 7, 11, 14}, batchErr.Invalid)
+
+	assert.Len(t, results, len(tokens))
+	for idx, r := range results {
+		switch idx {
+		case 3, 7, 11, 14:
+			assert.Error(t, r.Err)
+			assert.Nil(t, r.Payload)
+		default:
+			assert.NoError(t, r.Err)
+			assert.NotEmpty(t, r.Payload)
+		}
+	}
+	assert.True(t, errors.Is(results[7].Err, ErrFooterMismatch))
+	assert.True(t, errors.Is(results[14].Err, ErrInvalidSignature))
+}
+
+// mixedOrderToken signs the payload with a nonce point biased by a point of
+// order 8. The signature satisfies the cofactored Ed25519 equation only.
+func mixedOrderToken(t testing.TB, sk ed25519.PrivateKey, m []byte, f, i string) []byte {
+	// Small order point
+	rawT, err := hex.DecodeString("c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a")
+	assert.NoError(t, err)
+	torsion, err := new(edwards25519.Point).SetBytes(rawT)
+	assert.NoError(t, err)
+
+	// Secret scalar
+	digest := sha512.Sum512(sk.Seed())
+	a, err := new(edwards25519.Scalar).SetBytesWithClamping(digest[:32])
+	assert.NoError(t, err)
+
+	// R = [r]B + T
+	var seed [64]byte
+	_, err = rand.Read(seed[:])
+	assert.NoError(t, err)
+	r, err := new(edwards25519.Scalar).SetUniformBytes(seed[:])
+	assert.NoError(t, err)
+	R := new(edwards25519.Point).ScalarBaseMult(r)
+	R.Add(R, torsion)
+
+	// s = r + H(R || A || M) * a
+	msg := paseto.PreAuthenticationEncoding([]byte(v4PublicPrefix), m, []byte(f), []byte(i))
+	h := sha512.New()
+	h.Write(R.Bytes())
+	h.Write(sk.Public().(ed25519.PublicKey))
+	h.Write(msg)
+	k, err := new(edwards25519.Scalar).SetUniformBytes(h.Sum(nil))
+	assert.NoError(t, err)
+	S := new(edwards25519.Scalar).MultiplyAdd(k, a, r)
+
+	body := append(append(append([]byte{}, m...), R.Bytes()...), S.Bytes()...)
+	token := v4PublicPrefix + base64.RawURLEncoding.EncodeToString(body)
+	if f != "" {
+		token += "." + base64.RawURLEncoding.EncodeToString([]byte(f))
+	}
+	return []byte(token)
+}
+
+func Test_VerifyBatch_MixedOrder(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := `{"kid":"batch"}`
+	i := `{"purpose":"test"}`
+
+	forged := mixedOrderToken(t, sk, []byte(`{"data":"forged"}`), f, i)
+
+	// Rejected by the sequential path
+	_, err = Verify(forged, pk, f, i)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// Rejected by the batch path whatever the batch size
+	for _, count := range []int{1, minBatchSize, 32} {
+		tokens := batchTokens(t, sk, count, f, i)
+		tokens[0] = forged
+
+		_, err = VerifyBatch(tokens, pk, f, i)
+		var batchErr *ErrBatchVerification
+		if assert.True(t, errors.As(err, &batchErr), "batch size %d", count) {
+			assert.Equal(t, []int{0}, batchErr.Invalid)
+		}
+	}
+}
+
+func Test_VerifyBatch_InvalidKey(t *testing.T) {
+	results, err := VerifyBatch([][]byte{[]byte("v4.public.")}, ed25519.PublicKey{}, "", "")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidKeyLength))
+	assert.Nil(t, results)
+}
+
+// -----------------------------------------------------------------------------
+
+func benchmarkBatch(b *testing.B) (ed25519.PublicKey, [][]byte, string, string) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(b, err)
+
+	f := `{"kid":"batch"}`
+	i := `{"purpose":"benchmark"}`
+
+	return pk, batchTokens(b, sk, 64, f, i), f, i
+}
+
+func Benchmark_Paseto_VerifyBatch(b *testing.B) {
+	pk, tokens, f, i := benchmarkBatch(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		if _, err := VerifyBatch(tokens, pk, f, i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func Benchmark_Paseto_VerifyLoop(b *testing.B) {
+	pk, tokens, f, i := benchmarkBatch(b)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		for _, token := range tokens {
+			if _, err := Verify(token, pk, f, i); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"time"
+)
+
+const (
+	bindingAudienceKey = "aud"
+	bindingValueKey    = "bnd"
+)
+
+// Binding ties tokens to an audience and a per-request binding value (TLS
+// channel binding, HTTP request hash, client nonce, etc.) through the implicit
+// assertion.
+//
+// The implicit assertion is authenticated by the token MAC or signature but is
+// not stored in the token, so the verifier must reconstruct it from its own
+// context. This provides the following replay-resistance properties:
+//
+//   - a token minted for service A fails verification at service B, even when
+//     the payload is identical and both services trust the same key, because
+//     B rebuilds the assertion with its own audience;
+//   - a token replayed within the same service for another request fails
+//     because the binding value differs;
+//   - the binding value never travels in the token, so an eavesdropper can't
+//     learn it from captured tokens.
+//
+// It doesn't protect against a replay of the same request to the same service
+// with the same binding value, a `jti` replay cache is required for that.
+type Binding struct {
+	audience string
+	value    string
+}
+
+// NewBinding returns a binding for the given audience and binding value, both
+// must not be blank.
+func NewBinding(audience, value string) (*Binding, error) {
+	// Check arguments
+	if audience == "" {
+		return nil, errors.New("paseto: binding audience must not be blank")
+	}
+	if value == "" {
+		return nil, errors.New("paseto: binding value must not be blank")
+	}
+
+	// No error
+	return &Binding{
+		audience: audience,
+		value:    value,
+	}, nil
+}
+
+// ImplicitAssertion returns the canonical implicit assertion to use when
+// issuing the token.
+func (b *Binding) ImplicitAssertion() (string, error) {
+	return NewImplicitAssertion().
+		SetString(bindingAudienceKey, b.audience).
+		SetString(bindingValueKey, b.value).
+		Encode()
+}
+
+// Parser returns a parser verifying tokens issued with this binding. The
+// `aud` claim is also checked when present in the payload.
+func (b *Binding) Parser(opts ...ParserOption) *BoundParser {
+	return &BoundParser{
+		binding: b,
+		parser:  NewParser(append(opts, WithValidator(optionalAudienceValidator(b.audience)))...),
+	}
+}
+
+// BoundParser verifies tokens bound to an audience and a binding value.
+type BoundParser struct {
+	binding *Binding
+	parser  *Parser
+}
+
+// ParseLocal decrypts the given v4.local token with the binding implicit
+// assertion and validates its claims.
+func (p *BoundParser) ParseLocal(key, token, footer []byte) (*Token, error) {
+	// Rebuild implicit assertion
+	i, err := p.binding.ImplicitAssertion()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to parser
+	return p.parser.ParseLocal(key, token, footer, []byte(i))
+}
+
+// ParsePublic verifies the given v4.public token with the binding implicit
+// assertion and validates its claims.
+func (p *BoundParser) ParsePublic(pk ed25519.PublicKey, token, footer []byte) (*Token, error) {
+	// Rebuild implicit assertion
+	i, err := p.binding.ImplicitAssertion()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to parser
+	return p.parser.ParsePublic(pk, token, footer, []byte(i))
+}
+
+// -----------------------------------------------------------------------------
+
+func optionalAudienceValidator(expected string) ClaimsValidator {
+	check := AudienceValidator(expected)
+	return func(claims *RegisteredClaims, now time.Time) error {
+		if claims.Audience == "" {
+			return nil
+		}
+		return check(claims, now)
+	}
+}
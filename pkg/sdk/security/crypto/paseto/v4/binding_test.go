@@ -0,0 +1,99 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Binding_Public(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(rand.Reader)
+	assert.NoError(t, err)
+
+	// Issue a token for service A
+	bindingA, err := NewBinding("service-a", "request-1")
+	assert.NoError(t, err)
+	i, err := bindingA.ImplicitAssertion()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"aud":"service-a","bnd":"request-1"}`, i)
+
+	m, err := NewClaims(time.Minute).Bytes()
+	assert.NoError(t, err)
+	token, err := NewPublicToken().SetPayload(m).SetImplicitAssertion(i).Sign(sk)
+	assert.NoError(t, err)
+
+	// Accepted by service A for the same request
+	tok, err := bindingA.Parser(WithExpiration()).ParsePublic(pk, token, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, tok.Payload)
+
+	// Rejected by service B
+	bindingB, err := NewBinding("service-b", "request-1")
+	assert.NoError(t, err)
+	_, err = bindingB.Parser().ParsePublic(pk, token, nil)
+	assert.Error(t, err)
+
+	// Rejected for another request
+	otherRequest, err := NewBinding("service-a", "request-2")
+	assert.NoError(t, err)
+	_, err = otherRequest.Parser().ParsePublic(pk, token, nil)
+	assert.Error(t, err)
+
+	// Rejected by unbound verification
+	_, err = Verify(token, pk, "", "")
+	assert.Error(t, err)
+}
+
+func Test_Binding_Local(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	binding, err := NewBinding("service-a", "nonce")
+	assert.NoError(t, err)
+	i, err := binding.ImplicitAssertion()
+	assert.NoError(t, err)
+
+	// Payload audience mismatch is detected
+	m, err := NewClaims(time.Minute).Set("aud", "service-b").Bytes()
+	assert.NoError(t, err)
+	token, err := NewLocalToken().SetPayload(m).SetImplicitAssertion(i).Encrypt(rand.Reader, key)
+	assert.NoError(t, err)
+	_, err = binding.Parser().ParseLocal(key, token, nil)
+	assert.True(t, errors.Is(err, ErrInvalidAudience))
+
+	// Matching payload audience
+	m, err = NewClaims(time.Minute).Set("aud", "service-a").Bytes()
+	assert.NoError(t, err)
+	token, err = NewLocalToken().SetPayload(m).SetImplicitAssertion(i).Encrypt(rand.Reader, key)
+	assert.NoError(t, err)
+	tok, err := binding.Parser().ParseLocal(key, token, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "service-a", tok.Claims.Audience)
+}
+
+func Test_NewBinding_Invalid(t *testing.T) {
+	_, err := NewBinding("", "value")
+	assert.Error(t, err)
+	_, err = NewBinding("aud", "")
+	assert.Error(t, err)
+}
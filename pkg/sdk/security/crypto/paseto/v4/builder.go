@@ -0,0 +1,255 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/canonicaljson"
+)
+
+// PayloadValidator describes the payload validation function contract. It is
+// used to enforce a payload schema before token issuance or after token
+// decoding.
+type PayloadValidator func(payload []byte) error
+
+// tokenParts holds the token components shared by local and public builders.
+type tokenParts struct {
+	payload   []byte
+	footer    string
+	implicit  string
+	validator PayloadValidator
+	err       error
+}
+
+func (tp *tokenParts) setPayloadJSON(v interface{}) {
+	out, err := canonicaljson.Marshal(v)
+	if err != nil {
+		tp.err = fmt.Errorf("paseto: unable to encode payload as JSON: %w", err)
+		return
+	}
+	tp.payload = out
+}
+
+func (tp *tokenParts) setFooterJSON(v interface{}) {
+	out, err := canonicaljson.Marshal(v)
+	if err != nil {
+		tp.err = fmt.Errorf("paseto: unable to encode footer as JSON: %w", err)
+		return
+	}
+	tp.footer = string(out)
+}
+
+func (tp *tokenParts) setImplicitAssertion(a *ImplicitAssertion) {
+	out, err := a.encodeOrBlank()
+	if err != nil {
+		tp.err = err
+		return
+	}
+	tp.implicit = out
+}
+
+func (tp *tokenParts) validate() error {
+	if tp.err != nil {
+		return tp.err
+	}
+	if tp.payload == nil {
+		return errors.New("paseto: token payload is not set")
+	}
+	if tp.validator != nil {
+		if err := tp.validator(tp.payload); err != nil {
+			return fmt.Errorf("paseto: payload validation failed: %w", err)
+		}
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+// LocalToken is a v4.local token builder.
+type LocalToken struct {
+	tokenParts
+}
+
+// NewLocalToken returns a v4.local token builder.
+func NewLocalToken() *LocalToken {
+	return &LocalToken{}
+}
+
+// SetPayload sets the raw token payload.
+func (t *LocalToken) SetPayload(m []byte) *LocalToken {
+	t.payload = m
+	return t
+}
+
+// SetPayloadJSON sets the token payload as the RFC 8785 canonical JSON
+// encoding of the given value.
+func (t *LocalToken) SetPayloadJSON(v interface{}) *LocalToken {
+	t.setPayloadJSON(v)
+	return t
+}
+
+// SetFooter sets the token footer. The footer is authenticated and
+// transmitted in cleartext with the token.
+func (t *LocalToken) SetFooter(f string) *LocalToken {
+	t.footer = f
+	return t
+}
+
+// SetFooterJSON sets the token footer as the RFC 8785 canonical JSON encoding
+// of the given value.
+func (t *LocalToken) SetFooterJSON(v interface{}) *LocalToken {
+	t.setFooterJSON(v)
+	return t
+}
+
+// SetImplicitAssertion sets the token implicit assertion. The implicit
+// assertion is authenticated but never transmitted with the token.
+func (t *LocalToken) SetImplicitAssertion(i string) *LocalToken {
+	t.implicit = i
+	return t
+}
+
+// SetImplicitAssertionValues sets the token implicit assertion as the
+// canonical encoding of the given structured assertion.
+func (t *LocalToken) SetImplicitAssertionValues(a *ImplicitAssertion) *LocalToken {
+	t.setImplicitAssertion(a)
+	return t
+}
+
+// SetPayloadValidator sets the validator applied to the encoded payload
+// before the cryptographic operation.
+func (t *LocalToken) SetPayloadValidator(v PayloadValidator) *LocalToken {
+	t.validator = v
+	return t
+}
+
+// Encrypt builds the v4.local token using the given random source and key.
+func (t *LocalToken) Encrypt(r io.Reader, key []byte) ([]byte, error) {
+	// Check arguments
+	if r == nil {
+		return nil, errors.New("paseto: random source is nil")
+	}
+
+	// Delegate to nonce source variant
+	return t.EncryptWithNonceSource(ReaderNonceSource(r), key)
+}
+
+// EncryptWithNonceSource builds the v4.local token using the given nonce
+// source and key.
+func (t *LocalToken) EncryptWithNonceSource(src NonceSource, key []byte) ([]byte, error) {
+	// Check builder state
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	// Delegate to primitive
+	token, err := EncryptWithNonceSource(src, key, t.payload, t.footer, t.implicit)
+	if err != nil {
+		return nil, err
+	}
+
+	logIssued("local", len(t.payload), t.footer != "")
+
+	// No error
+	return token, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// PublicToken is a v4.public token builder.
+type PublicToken struct {
+	tokenParts
+}
+
+// NewPublicToken returns a v4.public token builder.
+func NewPublicToken() *PublicToken {
+	return &PublicToken{}
+}
+
+// SetPayload sets the raw token payload.
+func (t *PublicToken) SetPayload(m []byte) *PublicToken {
+	t.payload = m
+	return t
+}
+
+// SetPayloadJSON sets the token payload as the RFC 8785 canonical JSON
+// encoding of the given value.
+func (t *PublicToken) SetPayloadJSON(v interface{}) *PublicToken {
+	t.setPayloadJSON(v)
+	return t
+}
+
+// SetFooter sets the token footer. The footer is authenticated and
+// transmitted in cleartext with the token.
+func (t *PublicToken) SetFooter(f string) *PublicToken {
+	t.footer = f
+	return t
+}
+
+// SetFooterJSON sets the token footer as the RFC 8785 canonical JSON encoding
+// of the given value.
+func (t *PublicToken) SetFooterJSON(v interface{}) *PublicToken {
+	t.setFooterJSON(v)
+	return t
+}
+
+// SetImplicitAssertion sets the token implicit assertion. The implicit
+// assertion is authenticated but never transmitted with the token.
+func (t *PublicToken) SetImplicitAssertion(i string) *PublicToken {
+	t.implicit = i
+	return t
+}
+
+// SetImplicitAssertionValues sets the token implicit assertion as the
+// canonical encoding of the given structured assertion.
+func (t *PublicToken) SetImplicitAssertionValues(a *ImplicitAssertion) *PublicToken {
+	t.setImplicitAssertion(a)
+	return t
+}
+
+// SetPayloadValidator sets the validator applied to the encoded payload
+// before the cryptographic operation.
+func (t *PublicToken) SetPayloadValidator(v PayloadValidator) *PublicToken {
+	t.validator = v
+	return t
+}
+
+// Sign builds the v4.public token using the given private key.
+func (t *PublicToken) Sign(sk ed25519.PrivateKey) ([]byte, error) {
+	// Check builder state
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+
+	// Delegate to primitive
+	token, err := Sign(t.payload, sk, t.footer, t.implicit)
+	if err != nil {
+		return nil, err
+	}
+
+	logIssued("public", len(t.payload), t.footer != "")
+
+	// No error
+	return token, nil
+}
@@ -0,0 +1,164 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LocalToken_Builder(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	token, err := NewLocalToken().
+		SetPayloadJSON(map[string]string{"data": "this is a secret message"}).
+		SetFooterJSON(map[string]string{"kid": "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}).
+		SetImplicitAssertion(`{"test-vector":"4-E-7"}`).
+		Encrypt(rand.Reader, key)
+	assert.NoError(t, err)
+
+	m, err := Decrypt(key, token, `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`, `{"test-vector":"4-E-7"}`)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"data":"this is a secret message"}`, string(m))
+
+	// Swapped footer and implicit assertion must fail
+	_, err = Decrypt(key, token, `{"test-vector":"4-E-7"}`, `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`)
+	assert.Error(t, err)
+
+	// Missing payload
+	_, err = NewLocalToken().Encrypt(rand.Reader, key)
+	assert.Error(t, err)
+
+	// Invalid payload
+	_, err = NewLocalToken().SetPayloadJSON(make(chan int)).Encrypt(rand.Reader, key)
+	assert.Error(t, err)
+}
+
+func Test_PublicToken_Builder(t *testing.T) {
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(seed)
+
+	// 4-S-3
+	token, err := NewPublicToken().
+		SetPayload([]byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")).
+		SetFooter("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}").
+		SetImplicitAssertion("{\"test-vector\":\"4-S-3\"}").
+		Sign(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", string(token))
+
+	// Invalid footer
+	_, err = NewPublicToken().SetPayload([]byte("{}")).SetFooterJSON(make(chan int)).Sign(sk)
+	assert.Error(t, err)
+}
+
+func Test_Builder_PayloadValidator(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	// Reject payloads with fields out of the allowed set
+	errForbiddenField := errors.New("forbidden field")
+	allowed := map[string]bool{"sub": true, "exp": true}
+	validator := func(payload []byte) error {
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal(payload, &fields); err != nil {
+			return err
+		}
+		for k := range fields {
+			if !allowed[k] {
+				return errForbiddenField
+			}
+		}
+		return nil
+	}
+
+	t.Run("local", func(t *testing.T) {
+		_, err := NewLocalToken().
+			SetPayloadJSON(map[string]string{"sub": "user", "email": "user@example.com"}).
+			SetPayloadValidator(validator).
+			Encrypt(rand.Reader, key)
+		assert.True(t, errors.Is(err, errForbiddenField), "unexpected error %v", err)
+
+		token, err := NewLocalToken().
+			SetPayloadJSON(map[string]string{"sub": "user"}).
+			SetPayloadValidator(validator).
+			Encrypt(rand.Reader, key)
+		assert.NoError(t, err)
+
+		_, err = NewParser(WithPayloadValidator(validator)).ParseLocal(key, token, nil, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("public", func(t *testing.T) {
+		_, err := NewPublicToken().
+			SetPayloadJSON(map[string]string{"sub": "user", "email": "user@example.com"}).
+			SetPayloadValidator(validator).
+			Sign(sk)
+		assert.True(t, errors.Is(err, errForbiddenField), "unexpected error %v", err)
+
+		// Issued without validator, rejected by the parser
+		token, err := NewPublicToken().
+			SetPayloadJSON(map[string]string{"sub": "user", "email": "user@example.com"}).
+			Sign(sk)
+		assert.NoError(t, err)
+
+		_, err = NewParser(WithPayloadValidator(validator)).ParsePublic(pk, token, nil, nil)
+		assert.True(t, errors.Is(err, errForbiddenField), "unexpected error %v", err)
+	})
+}
+
+func Test_Builder_CanonicalJSON(t *testing.T) {
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	type footer struct {
+		Kid string `json:"kid"`
+		Env string `json:"env"`
+	}
+
+	// Struct field order
+	t1, err := NewPublicToken().
+		SetPayloadJSON(map[string]interface{}{"sub": "user", "amount": 10.50}).
+		SetFooterJSON(footer{Kid: "key-1", Env: "production"}).
+		Sign(sk)
+	assert.NoError(t, err)
+
+	// Same values reordered by a third party system
+	t2, err := NewPublicToken().
+		SetPayloadJSON(json.RawMessage(`{ "amount": 1.05e1, "sub": "user" }`)).
+		SetFooterJSON(json.RawMessage(`{"env":"production","kid":"key-1"}`)).
+		Sign(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, string(t1), string(t2))
+
+	m, err := Verify(t1, pk, `{"env":"production","kid":"key-1"}`, "")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"amount":10.5,"sub":"user"}`, string(m))
+}
@@ -0,0 +1,222 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/canonicaljson"
+	"github.com/elastic/harp/pkg/sdk/security"
+)
+
+var (
+	// ErrExpired is raised when the token expiration date is in the past.
+	ErrExpired = errors.New("paseto: token has expired")
+	// ErrNotYetValid is raised when the token not-before date is in the future.
+	ErrNotYetValid = errors.New("paseto: token is not yet valid")
+	// ErrIssuedInFuture is raised when the token issuance date is in the future.
+	ErrIssuedInFuture = errors.New("paseto: token is issued in the future")
+	// ErrMissingClaim is raised when a required claim is not present.
+	ErrMissingClaim = errors.New("paseto: required claim is missing")
+	// ErrInvalidIssuer is raised when the token issuer doesn't match.
+	ErrInvalidIssuer = errors.New("paseto: invalid issuer claim")
+	// ErrInvalidSubject is raised when the token subject doesn't match.
+	ErrInvalidSubject = errors.New("paseto: invalid subject claim")
+	// ErrInvalidAudience is raised when the token audience doesn't match.
+	ErrInvalidAudience = errors.New("paseto: invalid audience claim")
+	// ErrInvalidTokenID is raised when the token identifier doesn't match.
+	ErrInvalidTokenID = errors.New("paseto: invalid token identifier claim")
+)
+
+// RegisteredClaims represents the PASETO registered claims.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/02-Implementation-Guide/04-Claims.md
+type RegisteredClaims struct {
+	Issuer     string     `json:"iss,omitempty"`
+	Subject    string     `json:"sub,omitempty"`
+	Audience   string     `json:"aud,omitempty"`
+	Expiration *time.Time `json:"exp,omitempty"`
+	NotBefore  *time.Time `json:"nbf,omitempty"`
+	IssuedAt   *time.Time `json:"iat,omitempty"`
+	TokenID    string     `json:"jti,omitempty"`
+}
+
+// ClaimsOption represents functional pattern builder for optional parameters.
+type ClaimsOption func(*claimsOptions)
+
+type claimsOptions struct {
+	clock func() time.Time
+}
+
+// WithIssuanceClock sets the time source used to stamp the time claims.
+func WithIssuanceClock(clock func() time.Time) ClaimsOption {
+	return func(opts *claimsOptions) {
+		opts.clock = clock
+	}
+}
+
+// Claims is a token payload builder.
+type Claims struct {
+	values map[string]interface{}
+}
+
+// NewClaims returns a payload builder stamping `iat` and `nbf` to the
+// current time and `exp` to the current time plus the given ttl, all
+// formatted as RFC3339 UTC dates.
+//
+//	payload, err := NewClaims(time.Hour).Set("sub", "user").Bytes()
+func NewClaims(ttl time.Duration, opts ...ClaimsOption) *Claims {
+	// Apply options
+	dopts := &claimsOptions{
+		clock: time.Now,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Stamp time claims
+	now := dopts.clock().UTC().Truncate(time.Second)
+	return &Claims{
+		values: map[string]interface{}{
+			"iat": now.Format(time.RFC3339),
+			"nbf": now.Format(time.RFC3339),
+			"exp": now.Add(ttl).Format(time.RFC3339),
+		},
+	}
+}
+
+// Set assigns the given claim value, existing values are replaced.
+func (c *Claims) Set(key string, value interface{}) *Claims {
+	c.values[key] = value
+	return c
+}
+
+// Get returns the claim value and its presence status.
+func (c *Claims) Get(key string) (interface{}, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// MarshalJSON implements json.Marshaler. Claims are encoded as RFC 8785
+// canonical JSON.
+func (c *Claims) MarshalJSON() ([]byte, error) {
+	return canonicaljson.Marshal(c.values)
+}
+
+// Bytes returns the canonical JSON encoded payload to use with Encrypt or
+// Sign.
+func (c *Claims) Bytes() ([]byte, error) {
+	out, err := c.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to encode claims: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
+
+// ClaimsValidator describes the claims validation function contract.
+type ClaimsValidator func(claims *RegisteredClaims, now time.Time) error
+
+// -----------------------------------------------------------------------------
+
+// ExpirationValidator returns a validator used to check that the token is
+// not expired. The `exp` claim is required.
+func ExpirationValidator() ClaimsValidator {
+	return func(claims *RegisteredClaims, now time.Time) error {
+		if claims.Expiration == nil {
+			return fmt.Errorf("%w: exp", ErrMissingClaim)
+		}
+		if now.After(*claims.Expiration) {
+			return ErrExpired
+		}
+
+		// No error
+		return nil
+	}
+}
+
+// NotBeforeValidator returns a validator used to check that the token is
+// already valid. The `nbf` claim is required.
+func NotBeforeValidator() ClaimsValidator {
+	return func(claims *RegisteredClaims, now time.Time) error {
+		if claims.NotBefore == nil {
+			return fmt.Errorf("%w: nbf", ErrMissingClaim)
+		}
+		if now.Before(*claims.NotBefore) {
+			return ErrNotYetValid
+		}
+
+		// No error
+		return nil
+	}
+}
+
+// IssuedAtValidator returns a validator used to check that the token has not
+// been issued in the future. The `iat` claim is required.
+func IssuedAtValidator() ClaimsValidator {
+	return func(claims *RegisteredClaims, now time.Time) error {
+		if claims.IssuedAt == nil {
+			return fmt.Errorf("%w: iat", ErrMissingClaim)
+		}
+		if now.Before(*claims.IssuedAt) {
+			return ErrIssuedInFuture
+		}
+
+		// No error
+		return nil
+	}
+}
+
+// IssuerValidator returns a validator used to check the token issuer.
+func IssuerValidator(expected string) ClaimsValidator {
+	return stringClaimValidator("iss", expected, ErrInvalidIssuer, func(c *RegisteredClaims) string { return c.Issuer })
+}
+
+// SubjectValidator returns a validator used to check the token subject.
+func SubjectValidator(expected string) ClaimsValidator {
+	return stringClaimValidator("sub", expected, ErrInvalidSubject, func(c *RegisteredClaims) string { return c.Subject })
+}
+
+// AudienceValidator returns a validator used to check the token audience.
+func AudienceValidator(expected string) ClaimsValidator {
+	return stringClaimValidator("aud", expected, ErrInvalidAudience, func(c *RegisteredClaims) string { return c.Audience })
+}
+
+// TokenIDValidator returns a validator used to check the token identifier.
+func TokenIDValidator(expected string) ClaimsValidator {
+	return stringClaimValidator("jti", expected, ErrInvalidTokenID, func(c *RegisteredClaims) string { return c.TokenID })
+}
+
+// -----------------------------------------------------------------------------
+
+func stringClaimValidator(name, expected string, mismatchErr error, getter func(*RegisteredClaims) string) ClaimsValidator {
+	return func(claims *RegisteredClaims, _ time.Time) error {
+		value := getter(claims)
+		if value == "" {
+			return fmt.Errorf("%w: %s", ErrMissingClaim, name)
+		}
+		if !security.SecureCompareString(value, expected) {
+			return mismatchErr
+		}
+
+		// No error
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewClaims(t *testing.T) {
+	clock := fixedClock("2022-01-01T10:00:00.123456+02:00")
+
+	c := NewClaims(time.Hour, WithIssuanceClock(clock)).Set("sub", "user").Set("roles", []string{"admin"})
+	out, err := c.Bytes()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"iat":"2022-01-01T08:00:00Z","nbf":"2022-01-01T08:00:00Z","exp":"2022-01-01T09:00:00Z","sub":"user","roles":["admin"]}`, string(out))
+
+	// Registered claims are decodable
+	var rc RegisteredClaims
+	assert.NoError(t, json.Unmarshal(out, &rc))
+	assert.Equal(t, "user", rc.Subject)
+	assert.Equal(t, time.Hour, rc.Expiration.Sub(*rc.IssuedAt))
+
+	// Time claims can be overridden
+	c.Set("exp", "2022-01-02T00:00:00Z")
+	v, ok := c.Get("exp")
+	assert.True(t, ok)
+	assert.Equal(t, "2022-01-02T00:00:00Z", v)
+	_, ok = c.Get("jti")
+	assert.False(t, ok)
+
+	// Unsupported values
+	_, err = NewClaims(time.Hour).Set("invalid", func() {}).Bytes()
+	assert.Error(t, err)
+}
+
+func Test_NewClaims_Sign(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	issued := fixedClock("2022-01-01T00:00:00Z")
+	m, err := NewClaims(5*time.Minute, WithIssuanceClock(issued)).Set("aud", "svc").Bytes()
+	assert.NoError(t, err)
+	token, err := Sign(m, sk, "", "")
+	assert.NoError(t, err)
+
+	// Valid during the ttl
+	p := NewParser(WithClock(fixedClock("2022-01-01T00:04:00Z")), WithExpiration(), WithNotBefore(), WithIssuedAt(), WithAudience("svc"))
+	_, err = p.ParsePublic(pk, token, nil, nil)
+	assert.NoError(t, err)
+
+	// Expired after
+	p = NewParser(WithClock(fixedClock("2022-01-01T00:06:00Z")), WithExpiration())
+	_, err = p.ParsePublic(pk, token, nil, nil)
+	assert.ErrorIs(t, err, ErrExpired)
+}
@@ -0,0 +1,31 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build paseto_testing
+// +build paseto_testing
+
+package v4
+
+// EncryptDeterministic encrypts the given message using the given nonce.
+//
+// This function is only available with the `paseto_testing` build tag, it is
+// designed for deterministic tests and test vectors. Nonce reuse with the same
+// key breaks the confidentiality of all messages.
+func EncryptDeterministic(key, n, m []byte, f, i string) ([]byte, error) {
+	// Delegate to primitive
+	return encrypt(key, n, m, f, i)
+}
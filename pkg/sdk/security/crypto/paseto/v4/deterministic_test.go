@@ -0,0 +1,45 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build paseto_testing
+// +build paseto_testing
+
+package v4
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Local_EncryptDeterministic(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	n := make([]byte, nonceLength)
+
+	token1, err := EncryptDeterministic(key, n, []byte("test"), "", "")
+	assert.NoError(t, err)
+	token2, err := Encrypt(bytes.NewReader(n), key, []byte("test"), "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, token1, token2)
+
+	// Invalid nonce
+	_, err = EncryptDeterministic(key, n[:16], []byte("test"), "", "")
+	assert.Error(t, err)
+}
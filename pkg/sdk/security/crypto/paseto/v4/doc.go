@@ -0,0 +1,33 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package v4 implements PASETO v4 local (XChaCha20 + BLAKE2b) and public
+// (Ed25519) tokens.
+//
+// The recommended entry points are the LocalToken and PublicToken builders
+// which name each token component explicitly, preventing footer and implicit
+// assertion transposition:
+//
+//	token, err := v4.NewLocalToken().
+//		SetPayloadJSON(claims).
+//		SetFooter(`{"kid":"..."}`).
+//		SetImplicitAssertion(`{"tenant":"..."}`).
+//		Encrypt(rand.Reader, key)
+//
+// Low-level primitives (Encrypt, Decrypt, Sign, Verify) are kept for
+// advanced usages.
+package v4
@@ -0,0 +1,41 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import "errors"
+
+var (
+	// ErrInvalidKeyLength is raised when the given key doesn't have the
+	// expected size.
+	ErrInvalidKeyLength = errors.New("paseto: invalid key length")
+	// ErrInvalidTokenFormat is raised when the token can't be parsed.
+	ErrInvalidTokenFormat = errors.New("paseto: invalid token format")
+	// ErrInvalidSignature is raised when the public token signature is not
+	// valid.
+	ErrInvalidSignature = errors.New("paseto: invalid token signature")
+	// ErrInvalidMAC is raised when the local token authentication tag is not
+	// valid.
+	ErrInvalidMAC = errors.New("paseto: invalid token authentication tag")
+	// ErrFooterMismatch is raised when the token footer doesn't match the
+	// expected one.
+	ErrFooterMismatch = errors.New("paseto: invalid token, footer mismatch")
+	// ErrFooterTooLarge is raised when the token footer exceeds the size limit.
+	ErrFooterTooLarge = errors.New("paseto: token footer is too large")
+	// ErrTokenTooLarge is raised when the token exceeds the size limit.
+	ErrTokenTooLarge = errors.New("paseto: token is too large")
+)
@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_TypedErrors(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+	otherKey := make([]byte, KeyLength)
+	otherPk, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+
+	localToken, err := Encrypt(rand.Reader, key, m, f, "")
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, f, "")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		fn      func() error
+		wantErr error
+	}{
+		{
+			name: "encrypt key length",
+			fn: func() error {
+				_, err := Encrypt(rand.Reader, key[:16], m, f, "")
+				return err
+			},
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name: "decrypt key length",
+			fn: func() error {
+				_, err := Decrypt(key[:16], localToken, f, "")
+				return err
+			},
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name: "sign key length",
+			fn: func() error {
+				_, err := Sign(m, sk[:16], f, "")
+				return err
+			},
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name: "verify key length",
+			fn: func() error {
+				_, err := Verify(publicToken, pk[:16], f, "")
+				return err
+			},
+			wantErr: ErrInvalidKeyLength,
+		},
+		{
+			name: "decrypt invalid header",
+			fn: func() error {
+				_, err := Decrypt(key, publicToken, f, "")
+				return err
+			},
+			wantErr: ErrInvalidTokenFormat,
+		},
+		{
+			name: "verify malformed token",
+			fn: func() error {
+				_, err := Verify(append(publicToken, '='), pk, f, "")
+				return err
+			},
+			wantErr: ErrInvalidTokenFormat,
+		},
+		{
+			name: "decrypt invalid mac",
+			fn: func() error {
+				_, err := Decrypt(otherKey, localToken, f, "")
+				return err
+			},
+			wantErr: ErrInvalidMAC,
+		},
+		{
+			name: "decrypt invalid implicit assertion",
+			fn: func() error {
+				_, err := Decrypt(key, localToken, f, "implicit")
+				return err
+			},
+			wantErr: ErrInvalidMAC,
+		},
+		{
+			name: "verify invalid signature",
+			fn: func() error {
+				_, err := Verify(publicToken, otherPk, f, "")
+				return err
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "decrypt footer mismatch",
+			fn: func() error {
+				_, err := Decrypt(key, localToken, "", "")
+				return err
+			},
+			wantErr: ErrFooterMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.fn()
+			assert.True(t, errors.Is(err, testCase.wantErr), "unexpected error %v", err)
+		})
+	}
+}
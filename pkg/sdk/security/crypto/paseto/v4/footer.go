@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// ErrMissingKeyID is raised when the token footer doesn't contain a key
+// identifier.
+var ErrMissingKeyID = errors.New("paseto: key identifier not found in footer")
+
+// ExtractFooter returns the decoded footer of the given v4.local or v4.public
+// token. A nil footer is returned when the token has no footer. The default
+// size limits are enforced before decoding.
+//
+// WARNING: The footer is extracted from the token structure without any
+// cryptographic verification. It MUST be considered as unauthenticated until
+// the token is successfully decrypted or verified with the same footer value.
+func ExtractFooter(token []byte) ([]byte, error) {
+	return extractFooter(token, DefaultLimits())
+}
+
+// ExtractKeyID returns the `kid` value of the JSON footer of the given token.
+//
+// WARNING: The key identifier is unauthenticated, it must only be used to
+// select a key candidate and MUST be followed by a full token verification.
+func ExtractKeyID(token []byte) (string, error) {
+	// Extract footer
+	footer, err := ExtractFooter(token)
+	if err != nil {
+		return "", err
+	}
+
+	// Delegate to footer decoder
+	return keyIDFromFooter(footer)
+}
+
+// -----------------------------------------------------------------------------
+
+func extractFooter(token []byte, l Limits) ([]byte, error) {
+	// Select token header
+	h := v4LocalPrefix
+	switch {
+	case bytes.HasPrefix(token, []byte(v4PublicPrefix)):
+		h = v4PublicPrefix
+	case bytes.HasPrefix(token, []byte(v4PublicPreHashedPrefix)):
+		h = v4PublicPreHashedPrefix
+	}
+
+	// Parse token structure
+	_, footerSegment, err := splitToken(token, h, l)
+	if err != nil {
+		return nil, err
+	}
+	if footerSegment == nil {
+		return nil, nil
+	}
+
+	// Decode footer only
+	return decodeSegmentTo(nil, segmentFooter, footerSegment, l.maxFooterLength())
+}
+
+func keyIDFromFooter(footer []byte) (string, error) {
+	// Check arguments
+	if len(footer) == 0 {
+		return "", ErrMissingKeyID
+	}
+
+	// Decode footer as JSON
+	var claims struct {
+		KeyID string `json:"kid"`
+	}
+	if err := json.Unmarshal(footer, &claims); err != nil {
+		return "", fmt.Errorf("paseto: unable to decode footer as JSON: %w", err)
+	}
+	if claims.KeyID == "" {
+		return "", ErrMissingKeyID
+	}
+
+	// No error
+	return claims.KeyID, nil
+}
@@ -0,0 +1,139 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/b64"
+)
+
+const (
+	segmentVersion = iota
+	segmentPurpose
+	segmentPayload
+	segmentFooter
+	segmentTrailing
+)
+
+// ErrMalformedToken is raised when the token structure or segment encoding is
+// invalid. Segment is the zero based index of the offending dot separated
+// token segment.
+type ErrMalformedToken struct {
+	Segment int
+	Reason  string
+}
+
+func (e ErrMalformedToken) Error() string {
+	return fmt.Sprintf("paseto: malformed token, segment %d: %s", e.Segment, e.Reason)
+}
+
+// Unwrap returns the ErrInvalidTokenFormat sentinel error.
+func (e ErrMalformedToken) Unwrap() error {
+	return ErrInvalidTokenFormat
+}
+
+// parseToken checks the token header and structure, then decodes the payload
+// and the optional footer segments. PASETO mandates unpadded base64url
+// encoding with canonical trailing bits.
+func parseToken(token []byte, h string, l Limits) (payload, footer []byte, err error) {
+	// Split token segments
+	payloadSegment, footerSegment, err := splitToken(token, h, l)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode payload
+	payload, err = decodeSegmentTo(nil, segmentPayload, payloadSegment, l.maxPayloadLength())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode footer
+	if footerSegment != nil {
+		footer, err = decodeSegmentTo(nil, segmentFooter, footerSegment, l.maxFooterLength())
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// No error
+	return payload, footer, nil
+}
+
+// splitToken checks the token header and size limits, then returns the
+// encoded payload and footer segments without copying them. The footer segment
+// is nil when the token has no footer.
+func splitToken(token []byte, h string, l Limits) (payload, footer []byte, err error) {
+	// Check token size
+	if err := l.checkToken(token); err != nil {
+		return nil, nil, err
+	}
+
+	// Check token header
+	if !bytes.HasPrefix(token, []byte(h)) {
+		return nil, nil, fmt.Errorf("%w: invalid header", ErrInvalidTokenFormat)
+	}
+
+	// Locate footer separator
+	body := token[len(h):]
+	idx := bytes.IndexByte(body, '.')
+	if idx < 0 {
+		return body, nil, nil
+	}
+
+	// Check trailing segments
+	payload, footer = body[:idx], body[idx+1:]
+	if bytes.IndexByte(footer, '.') >= 0 {
+		return nil, nil, ErrMalformedToken{Segment: segmentTrailing, Reason: "unexpected trailing segment"}
+	}
+
+	// Check footer size
+	if err := l.checkFooter(footer); err != nil {
+		return nil, nil, err
+	}
+
+	// No error
+	return payload, footer, nil
+}
+
+// decodeSegmentTo appends the decoded segment to dst and returns the extended
+// buffer. The decoded segment must not exceed maxLen bytes.
+func decodeSegmentTo(dst []byte, index int, segment []byte, maxLen int) ([]byte, error) {
+	// Check segment content
+	if len(segment) == 0 {
+		return nil, ErrMalformedToken{Segment: index, Reason: "segment is empty"}
+	}
+
+	// Decode segment
+	out, err := b64.AppendDecodeURLNoPad(dst, segment, maxLen)
+	switch {
+	case err == nil:
+	case errors.Is(err, b64.ErrTooLarge) && index == segmentFooter:
+		return nil, fmt.Errorf("%w: %v", ErrFooterTooLarge, err)
+	case errors.Is(err, b64.ErrTooLarge):
+		return nil, fmt.Errorf("%w: %v", ErrTokenTooLarge, err)
+	default:
+		return nil, ErrMalformedToken{Segment: index, Reason: err.Error()}
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,137 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_MalformedToken(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	pk, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+
+	// 4-E-5 / 4-S-2
+	localBody := "32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ"
+	publicBody := "eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw"
+	footer := "eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+
+	// Ensure valid tokens are accepted
+	_, err = Decrypt(key, []byte(v4LocalPrefix+localBody+"."+footer), f, "")
+	assert.NoError(t, err)
+	_, err = Verify([]byte(v4PublicPrefix+publicBody+"."+footer), ed25519.PublicKey(pk), f, "")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		body        func(string) string
+		wantSegment int
+	}{
+		{
+			name:        "4 segments",
+			body:        func(b string) string { return b + "." + footer + "." + footer },
+			wantSegment: segmentTrailing,
+		},
+		{
+			name:        "trailing dot",
+			body:        func(b string) string { return b + "." + footer + "." },
+			wantSegment: segmentTrailing,
+		},
+		{
+			name:        "empty footer",
+			body:        func(b string) string { return b + "." },
+			wantSegment: segmentFooter,
+		},
+		{
+			name:        "padded footer",
+			body:        func(b string) string { return b + "." + footer + "==" },
+			wantSegment: segmentFooter,
+		},
+		{
+			name:        "padded payload",
+			body:        func(b string) string { return b + "=." + footer },
+			wantSegment: segmentPayload,
+		},
+		{
+			name:        "embedded newline",
+			body:        func(b string) string { return b + "\n." + footer },
+			wantSegment: segmentPayload,
+		},
+		{
+			name:        "embedded space in footer",
+			body:        func(b string) string { return b + "." + footer[:10] + " " + footer[10:] },
+			wantSegment: segmentFooter,
+		},
+		{
+			name:        "standard base64 alphabet",
+			body:        func(b string) string { return b + "." + footer[:10] + "+" + footer[11:] },
+			wantSegment: segmentFooter,
+		},
+		{
+			name:        "non canonical trailing bits",
+			body:        func(b string) string { return b[:len(b)-1] + string(b[len(b)-1]+1) + "." + footer },
+			wantSegment: segmentPayload,
+		},
+		{
+			name:        "empty payload",
+			body:        func(b string) string { return "." + footer },
+			wantSegment: segmentPayload,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			var mErr ErrMalformedToken
+
+			localToken := v4LocalPrefix + testCase.body(localBody)
+			_, err := Decrypt(key, []byte(localToken), f, "")
+			assert.True(t, errors.As(err, &mErr), "unexpected error %v", err)
+			assert.Equal(t, testCase.wantSegment, mErr.Segment)
+
+			publicToken := v4PublicPrefix + testCase.body(publicBody)
+			_, err = Verify([]byte(publicToken), ed25519.PublicKey(pk), f, "")
+			assert.True(t, errors.As(err, &mErr), "unexpected error %v", err)
+			assert.Equal(t, testCase.wantSegment, mErr.Segment)
+		})
+	}
+}
+
+func Test_Paseto_TruncatedToken(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	pk, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+
+	var mErr ErrMalformedToken
+
+	_, err = Decrypt(key, []byte(v4LocalPrefix+"AAAA"), "", "")
+	assert.True(t, errors.As(err, &mErr))
+	assert.Equal(t, segmentPayload, mErr.Segment)
+
+	_, err = Verify([]byte(v4PublicPrefix+"AAAA"), ed25519.PublicKey(pk), "", "")
+	assert.True(t, errors.As(err, &mErr))
+	assert.Equal(t, segmentPayload, mErr.Segment)
+}
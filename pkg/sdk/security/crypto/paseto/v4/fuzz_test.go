@@ -0,0 +1,148 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+)
+
+// Seeds are taken from the official test vectors.
+// https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+var (
+	fuzzLocalKey  = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	fuzzPublicKey = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	fuzzSecretKey = "b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+
+	fuzzLocalSeeds = [][3]string{
+		{"v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvSwscFlAl1pk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XJ5hOb_4v9RmDkneN0S92dx0OW4pgy7omxgf3S8c3LlQg", "", ""},                                                                                                                                                               // 4-E-1
+		{"v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAQAr68PS4AXe7If_ZgesdkUMvS2csCgglvpk5HC0e8kApeaqMfGo_7OpBnwJOAbY9V7WU6abu74MmcUE8YWAiaArVI8XIemu9chy3WVKvRBfg6t8wwYHK0ArLxxfZP73W_vfwt5A", "", ""},                                                                                                                                                               // 4-E-2
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6-tyebyWG6Ov7kKvBdkrrAJ837lKP3iDag2hzUPHuMKA", "", ""},                                                                                                                                                               // 4-E-3
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4gt6TiLm55vIH8c_lGxxZpE3AWlH4WTR0v45nsWoU3gQ", "", ""},                                                                                                                                                               // 4-E-4
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", ""},                            // 4-E-5
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6pWSA5HX2wjb3P-xLQg5K5feUCX4P2fpVK3ZLWFbMSxQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", ""},                            // 4-E-6
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t40KCCWLA7GYL9KFHzKlwY9_RnIfRrMQpueydLEAZGGcA.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", "{\"test-vector\":\"4-E-7\"}"}, // 4-E-7
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t5uvqQbMGlLLNYBc7A6_x7oqnpUK5WLvj24eE4DVPDZjw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", "{\"test-vector\":\"4-E-8\"}"}, // 4-E-8
+		{"v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t6tybdlmnMwcDMw0YxA_gFSE_IUWl78aMtOepFYSWYfQA.YXJiaXRyYXJ5LXN0cmluZy10aGF0LWlzbid0LWpzb24", "arbitrary-string-that-isn't-json", "{\"test-vector\":\"4-E-9\"}"},                                                        // 4-E-9
+	}
+	fuzzPublicSeeds = [][3]string{
+		{"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9bg_XBBzds8lTZShVlwwKSgeKpLT3yukTw6JUz3W4h_ExsQV-P0V54zemZDcAxFaSeef1QlXEFtkqxT1ciiQEDA", "", ""},                                                                                                                                                               // 4-S-1
+		{"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9v3Jt8mx_TdM2ceTGoqwrh4yDFn0XsHvvV_D0DtwQxVrJEBMl0F2caAdgnpKlt4p7xBnx1HcO-SPo8FPp214HDw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", ""},                            // 4-S-2
+		{"v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9", "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}", "{\"test-vector\":\"4-S-3\"}"}, // 4-S-3
+	}
+	fuzzMalformedSeeds = [][3]string{
+		{"", "", ""},
+		{"v4.local.", "", ""},
+		{"v4.local..", "", ""},
+		{"v4.local.AAAA.", "", ""},
+		{"v4.local.=", "", ""},
+		{"v4.public.", "", ""},
+		{"v4.public...", "", ""},
+		{"v4.public-ph.", "", ""},
+		{"v3.local.AAAA", "", ""},
+	}
+)
+
+// FuzzDecrypt feeds arbitrary tokens, footers and implicit assertions to the
+// local token decryption.
+//
+//	go test -run='^$' -fuzz='^FuzzDecrypt$' ./pkg/sdk/security/crypto/paseto/v4
+func FuzzDecrypt(f *testing.F) {
+	for _, s := range append(fuzzLocalSeeds, fuzzMalformedSeeds...) {
+		f.Add(s[0], s[1], s[2])
+	}
+
+	key, err := hex.DecodeString(fuzzLocalKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, token, footer, implicit string) {
+		m, err := Decrypt(key, []byte(token), footer, implicit)
+		if err != nil {
+			if m != nil {
+				t.Fatal("message must be nil on error")
+			}
+			return
+		}
+
+		// Only well-formed tokens succeed: re-encrypting the message with the
+		// token nonce must produce the exact same token.
+		raw, _, err := parseToken([]byte(token), v4LocalPrefix, DefaultLimits())
+		if err != nil {
+			t.Fatalf("decrypted token must be parseable: %v", err)
+		}
+		expected, err := encrypt(key, raw[:nonceLength], m, footer, implicit)
+		if err != nil {
+			t.Fatalf("unable to re-encrypt the message: %v", err)
+		}
+		if !bytes.Equal(expected, []byte(token)) {
+			t.Fatalf("decrypted token is not canonical\n got: %q\nwant: %q", token, expected)
+		}
+	})
+}
+
+// FuzzVerify feeds arbitrary tokens, footers and implicit assertions to the
+// public token verification.
+//
+//	go test -run='^$' -fuzz='^FuzzVerify$' ./pkg/sdk/security/crypto/paseto/v4
+func FuzzVerify(f *testing.F) {
+	for _, s := range append(fuzzPublicSeeds, fuzzMalformedSeeds...) {
+		f.Add(s[0], s[1], s[2])
+	}
+
+	pk, err := hex.DecodeString(fuzzPublicKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+	sk, err := hex.DecodeString(fuzzSecretKey)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	f.Fuzz(func(t *testing.T, token, footer, implicit string) {
+		m, err := Verify([]byte(token), ed25519.PublicKey(pk), footer, implicit)
+		if err != nil {
+			if m != nil {
+				t.Fatal("message must be nil on error")
+			}
+			return
+		}
+
+		// Pre-hashed tokens are produced by another primitive
+		if !bytes.HasPrefix([]byte(token), []byte(v4PublicPrefix)) {
+			return
+		}
+
+		// Only well-formed tokens succeed: ed25519 signatures are
+		// deterministic, signing the message must produce the exact same
+		// token.
+		expected, err := Sign(m, ed25519.PrivateKey(sk), footer, implicit)
+		if err != nil {
+			t.Fatalf("unable to sign the message: %v", err)
+		}
+		if !bytes.Equal(expected, []byte(token)) {
+			t.Fatalf("verified token is not canonical\n got: %q\nwant: %q", token, expected)
+		}
+	})
+}
@@ -19,9 +19,10 @@ package v4
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
+	"crypto/subtle"
 	"encoding/base64"
-	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
@@ -29,7 +30,9 @@ import (
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/chacha20"
 
-	"github.com/elastic/harp/pkg/sdk/security"
+	blake2bmac "github.com/elastic/harp/pkg/sdk/security/crypto/mac"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+	"github.com/elastic/harp/pkg/sdk/security/secret"
 )
 
 const (
@@ -45,78 +48,135 @@ const (
 
 // PASETO v4 symmetric encryption primitive.
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#encrypt
+//
+// The given reader is the nonce source, it must be a CSPRNG such as
+// `crypto/rand.Reader` in production. An error is raised when the reader can't
+// provide enough bytes to build a full nonce. Use EncryptWithNonceSource to
+// plug a custom NonceSource.
 func Encrypt(r io.Reader, key, m []byte, f, i string) ([]byte, error) {
-	// Create random seed
-	var n [nonceLength]byte
-	if _, err := io.ReadFull(r, n[:]); err != nil {
-		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	// Check arguments
+	if r == nil {
+		return nil, errors.New("paseto: random source is nil")
 	}
 
-	// Delegate to primitive
-	return encrypt(key, n[:], m, f, i)
+	// Delegate to nonce source variant
+	return EncryptWithNonceSource(ReaderNonceSource(r), key, m, f, i)
 }
 
 // PASETO v4 symmetric decryption primitive
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#decrypt
 func Decrypt(key, input []byte, f, i string) ([]byte, error) {
+	return DecryptContext(context.Background(), key, input, f, i)
+}
+
+// DecryptContext is the context aware Decrypt variant, the context is checked
+// before the cryptographic operations.
+func DecryptContext(ctx context.Context, key, input []byte, f, i string) ([]byte, error) {
+	return decrypt(ctx, key, input, f, i, DefaultLimits())
+}
+
+// PASETO v4 public signature primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#sign
+func Sign(m []byte, sk ed25519.PrivateKey, f, i string) ([]byte, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PrivateKeySize, len(sk))
+	}
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding([]byte(v4PublicPrefix), m, []byte(f), []byte(i))
+
+	// Sign protected content
+	sig := ed25519.Sign(sk, m2)
+
+	// Prepare content
+	body := append([]byte{}, m...)
+	body = append(body, sig...)
+
+	// Encode body as RawURLBase64
+	encodedBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(body)))
+	base64.RawURLEncoding.Encode(encodedBody, body)
+
+	// Assemble final token
+	final := append([]byte(v4PublicPrefix), encodedBody...)
+	if f != "" {
+		// Encode footer as RawURLBase64
+		encodedFooter := make([]byte, base64.RawURLEncoding.EncodedLen(len(f)))
+		base64.RawURLEncoding.Encode(encodedFooter, []byte(f))
+
+		// Assemble body and footer
+		final = append(final, append([]byte("."), encodedFooter...)...)
+	}
+
+	// No error
+	return final, nil
+}
+
+// PASETO v4 signature verification primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#verify
+//
+// `v4.public-ph.` tokens are dispatched to the pre-hashed verifier, the
+// returned value is then the signed SHA-512 document digest.
+func Verify(sm []byte, pk ed25519.PublicKey, f, i string) ([]byte, error) {
+	return VerifyContext(context.Background(), sm, pk, f, i)
+}
+
+// VerifyContext is the context aware Verify variant, the context is checked
+// before the cryptographic operations.
+func VerifyContext(ctx context.Context, sm []byte, pk ed25519.PublicKey, f, i string) ([]byte, error) {
+	return verifyWithLimits(ctx, sm, pk, f, i, DefaultLimits())
+}
+
+// ConstantTimeFooterEqual compares the given footers in constant time. Footer
+// length is not considered as a secret.
+func ConstantTimeFooterEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// -----------------------------------------------------------------------------
+
+func decrypt(ctx context.Context, key, input []byte, f, i string, l Limits) ([]byte, error) {
 	// Check arguments
 	if key == nil {
 		return nil, errors.New("paseto: key is nil")
 	}
 	if len(key) != KeyLength {
-		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, KeyLength, len(key))
 	}
 	if input == nil {
 		return nil, errors.New("paseto: input is nil")
 	}
 
-	// Check token header
-	if !bytes.HasPrefix(input, []byte(v4LocalPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	// Parse token
+	raw, footer, err := parseToken(input, v4LocalPrefix, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < nonceLength+macLength {
+		return nil, ErrMalformedToken{Segment: segmentPayload, Reason: "payload is too short"}
 	}
 
-	// Trim prefix
-	input = input[len(v4LocalPrefix):]
-
-	// Check footer usage
-	if f != "" {
-		// Split the footer and the body
-		parts := bytes.SplitN(input, []byte("."), 2)
-		if len(parts) != 2 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
-		}
-
-		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(parts[1])))
-		if _, err := base64.RawURLEncoding.Decode(footer, parts[1]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
-		}
-
-		// Compare footer
-		if !security.SecureCompare([]byte(f), footer) {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
-		}
-
-		// Continue without footer
-		input = parts[0]
+	// Compare footer
+	if !ConstantTimeFooterEqual([]byte(f), footer) {
+		return nil, ErrFooterMismatch
 	}
 
-	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(input)))
-	if _, err := base64.RawURLEncoding.Decode(raw, input); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	// Check cancellation
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
 	// Extract components
 	n := raw[:nonceLength]
 	t := raw[len(raw)-macLength:]
-	c := raw[macLength : len(raw)-macLength]
+	c := raw[nonceLength : len(raw)-macLength]
 
 	// Derive keys from seed and secret key
 	ek, n2, ak, err := kdf(key, n)
 	if err != nil {
 		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
 	}
+	defer wipe(ek, n2, ak)
 
 	// Compute MAC
 	t2, err := mac(ak, v4LocalPrefix, n, c, f, i)
@@ -125,8 +185,8 @@ func Decrypt(key, input []byte, f, i string) ([]byte, error) {
 	}
 
 	// Time-constant compare MAC
-	if !security.SecureCompare(t, t2) {
-		return nil, errors.New("paseto: invalid pre-authentication header")
+	if subtle.ConstantTimeCompare(t, t2) != 1 {
+		return nil, ErrInvalidMAC
 	}
 
 	// Prepare XChaCha20 stream cipher
@@ -143,116 +203,61 @@ func Decrypt(key, input []byte, f, i string) ([]byte, error) {
 	return m, nil
 }
 
-// PASETO v4 public signature primitive.
-// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#sign
-func Sign(m []byte, sk ed25519.PrivateKey, f, i string) ([]byte, error) {
-	// Compute protected content
-	m2, err := pae([]byte(v4PublicPrefix), m, []byte(f), []byte(i))
-	if err != nil {
-		return nil, fmt.Errorf("unable to prepare protected content: %w", err)
+func verifyWithLimits(ctx context.Context, sm []byte, pk ed25519.PublicKey, f, i string, l Limits) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PublicKeySize, len(pk))
 	}
 
-	// Sign protected content
-	sig := ed25519.Sign(sk, m2)
-
-	// Prepare content
-	body := append([]byte{}, m...)
-	body = append(body, sig...)
-
-	// Encode body as RawURLBase64
-	encodedBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(body)))
-	base64.RawURLEncoding.Encode(encodedBody, body)
-
-	// Assemble final token
-	final := append([]byte(v4PublicPrefix), encodedBody...)
-	if f != "" {
-		// Encode footer as RawURLBase64
-		encodedFooter := make([]byte, base64.RawURLEncoding.EncodedLen(len(f)))
-		base64.RawURLEncoding.Encode(encodedFooter, []byte(f))
-
-		// Assemble body and footer
-		final = append(final, append([]byte("."), encodedFooter...)...)
+	// Check cancellation
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// No error
-	return final, nil
-}
-
-// PASETO v4 signature verification primitive.
-// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#verify
-func Verify(sm []byte, pk ed25519.PublicKey, f, i string) ([]byte, error) {
-	// Check token header
-	if !bytes.HasPrefix(sm, []byte(v4PublicPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	// Dispatch pre-hashed tokens
+	if bytes.HasPrefix(sm, []byte(v4PublicPreHashedPrefix)) {
+		return verifyPreHashed(sm, pk, f, i, l)
 	}
 
-	// Trim prefix
-	sm = sm[len(v4PublicPrefix):]
-
-	// Check footer usage
-	if f != "" {
-		// Split the footer and the body
-		parts := bytes.SplitN(sm, []byte("."), 2)
-		if len(parts) != 2 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
-		}
-
-		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(parts[1])))
-		if _, err := base64.RawURLEncoding.Decode(footer, parts[1]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
-		}
-
-		// Compare footer
-		if !security.SecureCompare([]byte(f), footer) {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
+	// Get a scratch buffer
+	buf := scratchPool.Get().(*[]byte)
+	defer func() {
+		if cap(*buf) <= maxPooledBufferSize {
+			scratchPool.Put(buf)
 		}
+	}()
 
-		// Continue without footer
-		sm = parts[0]
-	}
-
-	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(sm)))
-	if _, err := base64.RawURLEncoding.Decode(raw, sm); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
-	}
-
-	// Extract components
-	m := raw[:len(raw)-ed25519.SignatureSize]
-	s := raw[len(raw)-ed25519.SignatureSize:]
-
-	// Compute protected content
-	m2, err := pae([]byte(v4PublicPrefix), m, []byte(f), []byte(i))
+	// Delegate to the allocation free path
+	m, scratch, err := verify(*buf, sm, pk, f, i, l)
+	*buf = scratch[:0]
 	if err != nil {
-		return nil, fmt.Errorf("unable to prepare protected content: %w", err)
-	}
-
-	// Check signature
-	if !ed25519.Verify(pk, m2, s) {
-		return nil, errors.New("paseto: invalid token signature")
+		return nil, err
 	}
 
-	// No error
-	return m, nil
+	// Copy the message out of the scratch buffer
+	return append([]byte{}, m...), nil
 }
 
-// -----------------------------------------------------------------------------
-
 func encrypt(key, n, m []byte, f, i string) ([]byte, error) {
 	// Check arguments
 	if len(key) != KeyLength {
-		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, KeyLength, len(key))
 	}
 	if len(n) != nonceLength {
 		return nil, fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
 	}
 
+	// Check nonce reuse
+	if err := trackNonce(key, n); err != nil {
+		return nil, err
+	}
+
 	// Derive keys from seed and secret key
 	ek, n2, ak, err := kdf(key, n)
 	if err != nil {
 		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
 	}
+	defer wipe(ek, n2, ak)
 
 	// Prepare XChaCha20 stream cipher (nonce > 24bytes => XChacha)
 	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
@@ -295,6 +300,13 @@ func encrypt(key, n, m []byte, f, i string) ([]byte, error) {
 	return final, nil
 }
 
+// wipe clears the given intermediate key buffers.
+func wipe(buffers ...[]byte) {
+	for _, b := range buffers {
+		secret.Wipe(b)
+	}
+}
+
 func kdf(key, n []byte) (ek, n2, ak []byte, err error) {
 	// Derive encryption key
 	encKDF, err := blake2b.New(encryptionKDFLength, key)
@@ -328,47 +340,8 @@ func kdf(key, n []byte) (ek, n2, ak []byte, err error) {
 
 func mac(ak []byte, h string, n, c []byte, f, i string) ([]byte, error) {
 	// Compute pre-authentication message
-	preAuth, err := pae([]byte(h), n, c, []byte(f), []byte(i))
-	if err != nil {
-		return nil, fmt.Errorf("unable to compute pre-authentication content: %w", err)
-	}
+	preAuth := paseto.PreAuthenticationEncoding([]byte(h), n, c, []byte(f), []byte(i))
 
 	// Compute MAC
-	mac, err := blake2b.New(macLength, ak)
-	if err != nil {
-		return nil, fmt.Errorf("unable to in initialize MAC kdf: %w", err)
-	}
-
-	// Hash pre-authentication content
-	mac.Write(preAuth)
-
-	// No error
-	return mac.Sum(nil), nil
-}
-
-// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Common.md#authentication-padding
-func pae(pieces ...[]byte) ([]byte, error) {
-	output := &bytes.Buffer{}
-
-	// Encode piece count
-	count := len(pieces)
-	if err := binary.Write(output, binary.LittleEndian, uint64(count)); err != nil {
-		return nil, err
-	}
-
-	// For each element
-	for i := range pieces {
-		// Encode size
-		if err := binary.Write(output, binary.LittleEndian, uint64(len(pieces[i]))); err != nil {
-			return nil, err
-		}
-
-		// Encode data
-		if _, err := output.Write(pieces[i]); err != nil {
-			return nil, err
-		}
-	}
-
-	// No error
-	return output.Bytes(), nil
+	return blake2bmac.Blake2bMAC(ak, preAuth, macLength)
 }
@@ -18,10 +18,16 @@
 package v4
 
 import (
+	"bytes"
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -233,6 +239,22 @@ func Test_Paseto_PublicVector(t *testing.T) {
 			pk := sk.Public().(ed25519.PublicKey)
 			assert.Equal(t, publicKey, []byte(pk))
 
+			// PEM encoded keys produce the same raw keys
+			pemSk, err := ParsePrivateKeyPEM([]byte(testCase.secretKeyPem))
+			assert.NoError(t, err)
+			assert.Equal(t, sk, pemSk)
+			pemPk, err := ParsePublicKeyPEM([]byte(testCase.publicKeyPem))
+			assert.NoError(t, err)
+			assert.Equal(t, pk, pemPk)
+
+			// PEM encoders are the inverse operations
+			encodedSk, err := EncodePrivateKeyPEM(sk)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.secretKeyPem, strings.TrimSpace(string(encodedSk)))
+			encodedPk, err := EncodePublicKeyPEM(pk)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.publicKeyPem, strings.TrimSpace(string(encodedPk)))
+
 			// Sign
 			token, err := Sign([]byte(testCase.payload), sk, testCase.footer, testCase.implicitAssertion)
 			if (err != nil) != testCase.expectFail {
@@ -275,6 +297,119 @@ func Test_Paseto_Local_EncryptDecrypt(t *testing.T) {
 	assert.Equal(t, m, p)
 }
 
+func Test_Paseto_Local_ShortRandomSource(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	_, err = Encrypt(bytes.NewReader(make([]byte, nonceLength-1)), key, []byte("test"), "", "")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+
+	_, err = Encrypt(bytes.NewReader(nil), key, []byte("test"), "", "")
+	assert.Error(t, err)
+
+	_, err = Encrypt(nil, key, []byte("test"), "", "")
+	assert.Error(t, err)
+}
+
+func Test_Paseto_FooterMismatch(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+
+	localToken, err := Encrypt(rand.Reader, key, m, f, "")
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, f, "")
+	assert.NoError(t, err)
+
+	for _, expected := range []string{
+		"",
+		"{",
+		f[:len(f)-1],
+		f + "}",
+		"{\"kid\":\"aVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}",
+		f + f,
+	} {
+		_, err = Decrypt(key, localToken, expected, "")
+		assert.True(t, errors.Is(err, ErrFooterMismatch), "unexpected error %v for footer %q", err, expected)
+
+		_, err = Verify(publicToken, pk, expected, "")
+		assert.True(t, errors.Is(err, ErrFooterMismatch), "unexpected error %v for footer %q", err, expected)
+	}
+
+	// Footer expected but not present
+	localToken, err = Encrypt(rand.Reader, key, m, "", "")
+	assert.NoError(t, err)
+	_, err = Decrypt(key, localToken, f, "")
+	assert.True(t, errors.Is(err, ErrFooterMismatch))
+}
+
+func Test_Paseto_Context(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	localToken, err := Encrypt(rand.Reader, key, m, "", "")
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, "", "")
+	assert.NoError(t, err)
+
+	// Active context
+	out, err := DecryptContext(context.Background(), key, localToken, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, m, out)
+	out, err = VerifyContext(context.Background(), publicToken, pk, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, m, out)
+
+	// Cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = DecryptContext(ctx, key, localToken, "", "")
+	assert.True(t, errors.Is(err, context.Canceled))
+	_, err = VerifyContext(ctx, publicToken, pk, "", "")
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// Expired context
+	ctx, cancel = context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+	_, err = DecryptContext(ctx, key, localToken, "", "")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	_, err = VerifyContext(ctx, publicToken, pk, "", "")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func Test_ConstantTimeFooterEqual(t *testing.T) {
+	assert.True(t, ConstantTimeFooterEqual([]byte("footer"), []byte("footer")))
+	assert.True(t, ConstantTimeFooterEqual(nil, []byte{}))
+	assert.False(t, ConstantTimeFooterEqual([]byte("footer"), []byte("footex")))
+	assert.False(t, ConstantTimeFooterEqual([]byte("footer"), []byte("foot")))
+}
+
+func Test_Paseto_Verify_Allocations(t *testing.T) {
+	pk, err := hex.DecodeString("1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+
+	token := []byte("v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9")
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+	i := "{\"test-vector\":\"4-S-3\"}"
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Verify(token, pk, f, i); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// Only the returned message copy is allowed
+	assert.LessOrEqual(t, allocs, float64(1), "Verify allocations regressed")
+}
+
 // -----------------------------------------------------------------------------
 
 func benchmarkEncrypt(key, m []byte, f, i string, b *testing.B) {
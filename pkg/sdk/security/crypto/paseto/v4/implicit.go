@@ -0,0 +1,131 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/canonicaljson"
+)
+
+// maxSafeInteger is the largest integer exactly represented by all JSON
+// implementations (2^53-1).
+const maxSafeInteger = 1<<53 - 1
+
+// ErrUnsafeInteger is raised when an implicit assertion integer can't be
+// encoded without precision loss.
+var ErrUnsafeInteger = errors.New("paseto: integer value exceeds 2^53-1")
+
+// ImplicitAssertion builds a structured implicit assertion (tenant, resource,
+// etc.). Values are encoded as RFC 8785 canonical JSON so that the issuer and
+// the verifier produce the same assertion whatever the insertion order.
+type ImplicitAssertion struct {
+	values map[string]interface{}
+	err    error
+}
+
+// NewImplicitAssertion returns an empty implicit assertion builder.
+func NewImplicitAssertion() *ImplicitAssertion {
+	return &ImplicitAssertion{
+		values: map[string]interface{}{},
+	}
+}
+
+// SetString sets a string value.
+func (a *ImplicitAssertion) SetString(key, value string) *ImplicitAssertion {
+	a.values[key] = value
+	return a
+}
+
+// SetInt sets an integer value. Values outside of the [-(2^53-1), 2^53-1] range
+// can't be encoded without precision loss, they are reported by Encode and must
+// be set as strings.
+func (a *ImplicitAssertion) SetInt(key string, value int64) *ImplicitAssertion {
+	if value > maxSafeInteger || value < -maxSafeInteger {
+		a.err = fmt.Errorf("%w: implicit assertion '%s'", ErrUnsafeInteger, key)
+		return a
+	}
+	a.values[key] = value
+	return a
+}
+
+// SetBool sets a boolean value.
+func (a *ImplicitAssertion) SetBool(key string, value bool) *ImplicitAssertion {
+	a.values[key] = value
+	return a
+}
+
+// Encode returns the RFC 8785 canonical JSON encoding of the assertion. An empty
+// assertion is encoded as a blank string.
+func (a *ImplicitAssertion) Encode() (string, error) {
+	// Check arguments
+	if a.err != nil {
+		return "", a.err
+	}
+	if len(a.values) == 0 {
+		return "", nil
+	}
+
+	// Encode as RFC 8785 canonical JSON
+	out, err := canonicaljson.Marshal(a.values)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to encode implicit assertion: %w", err)
+	}
+
+	// No error
+	return string(out), nil
+}
+
+// DecryptWithImplicitAssertion decrypts the given v4.local token bound to the
+// given implicit assertion values, a mismatch on any value fails with
+// ErrInvalidMAC.
+func DecryptWithImplicitAssertion(key, input []byte, f string, a *ImplicitAssertion) ([]byte, error) {
+	// Encode implicit assertion
+	i, err := a.encodeOrBlank()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to decryption
+	return Decrypt(key, input, f, i)
+}
+
+// VerifyWithImplicitAssertion verifies the given v4.public token bound to the
+// given implicit assertion values, a mismatch on any value fails with
+// ErrInvalidSignature.
+func VerifyWithImplicitAssertion(sm []byte, pk ed25519.PublicKey, f string, a *ImplicitAssertion) ([]byte, error) {
+	// Encode implicit assertion
+	i, err := a.encodeOrBlank()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to verification
+	return Verify(sm, pk, f, i)
+}
+
+// encodeOrBlank encodes the assertion, a nil assertion is encoded as a blank
+// string as SetImplicitAssertionValues does.
+func (a *ImplicitAssertion) encodeOrBlank() (string, error) {
+	if a == nil {
+		return "", nil
+	}
+	return a.Encode()
+}
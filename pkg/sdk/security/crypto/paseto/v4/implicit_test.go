@@ -0,0 +1,156 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/canonicaljson"
+)
+
+func Test_ImplicitAssertion_Encode(t *testing.T) {
+	a1, err := NewImplicitAssertion().
+		SetString("tenant", "acme").
+		SetString("resource", "db/credentials").
+		SetInt("version", 2).
+		SetBool("admin", false).
+		Encode()
+	assert.NoError(t, err)
+
+	a2, err := NewImplicitAssertion().
+		SetBool("admin", false).
+		SetInt("version", 2).
+		SetString("resource", "db/credentials").
+		SetString("tenant", "acme").
+		Encode()
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"admin":false,"resource":"db/credentials","tenant":"acme","version":2}`, a1)
+	assert.Equal(t, a1, a2)
+
+	empty, err := NewImplicitAssertion().Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, "", empty)
+}
+
+func Test_ImplicitAssertion_UnsafeInteger(t *testing.T) {
+	// Largest safe integer
+	out, err := NewImplicitAssertion().SetInt("tenant", 9007199254740991).Encode()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"tenant":9007199254740991}`, out)
+
+	// Values above 2^53-1 would collide once rounded
+	for _, v := range []int64{9007199254740992, 9007199254740993, -9007199254740993} {
+		_, err = NewImplicitAssertion().SetInt("tenant", v).Encode()
+		assert.True(t, errors.Is(err, ErrUnsafeInteger), "expected unsafe integer error for %d", v)
+	}
+
+	// Payload and footer builders reject rounded integers
+	_, err = NewLocalToken().SetPayloadJSON(map[string]int64{"tenant": 9007199254740993}).Encrypt(rand.Reader, make([]byte, KeyLength))
+	assert.True(t, errors.Is(err, canonicaljson.ErrInexactInteger))
+	_, err = NewLocalToken().SetPayload([]byte("{}")).SetFooterJSON(map[string]int64{"tenant": 9007199254740993}).Encrypt(rand.Reader, make([]byte, KeyLength))
+	assert.True(t, errors.Is(err, canonicaljson.ErrInexactInteger))
+}
+
+func Test_ImplicitAssertion_Builder(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	m := []byte(`{"data":"this is a signed message"}`)
+	issued := NewImplicitAssertion().SetString("tenant", "acme").SetString("resource", "db")
+
+	localToken, err := NewLocalToken().SetPayload(m).SetImplicitAssertionValues(issued).Encrypt(rand.Reader, key)
+	assert.NoError(t, err)
+	publicToken, err := NewPublicToken().SetPayload(m).SetImplicitAssertionValues(issued).Sign(sk)
+	assert.NoError(t, err)
+
+	// Same values in a different order
+	expected, err := NewImplicitAssertion().SetString("resource", "db").SetString("tenant", "acme").Encode()
+	assert.NoError(t, err)
+
+	_, err = Decrypt(key, localToken, "", expected)
+	assert.NoError(t, err)
+	_, err = Verify(publicToken, pk, "", expected)
+	assert.NoError(t, err)
+
+	// Any field mismatch fails
+	mismatch, err := NewImplicitAssertion().SetString("resource", "db").SetString("tenant", "other").Encode()
+	assert.NoError(t, err)
+
+	_, err = Decrypt(key, localToken, "", mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidMAC))
+	_, err = Verify(publicToken, pk, "", mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+}
+
+func Test_ImplicitAssertion_VerifySide(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	m := []byte(`{"sub":"user"}`)
+	issued := NewImplicitAssertion().SetString("tenant", "acme").SetInt("version", 2)
+
+	localToken, err := NewLocalToken().SetPayload(m).SetImplicitAssertionValues(issued).Encrypt(rand.Reader, key)
+	assert.NoError(t, err)
+	publicToken, err := NewPublicToken().SetPayload(m).SetImplicitAssertionValues(issued).Sign(sk)
+	assert.NoError(t, err)
+
+	// Same values in a different order
+	expected := NewImplicitAssertion().SetInt("version", 2).SetString("tenant", "acme")
+	out, err := DecryptWithImplicitAssertion(key, localToken, "", expected)
+	assert.NoError(t, err)
+	assert.Equal(t, m, out)
+	out, err = VerifyWithImplicitAssertion(publicToken, pk, "", expected)
+	assert.NoError(t, err)
+	assert.Equal(t, m, out)
+
+	p := NewParser()
+	_, err = p.ParseLocalWithImplicitAssertion(key, localToken, nil, expected)
+	assert.NoError(t, err)
+	_, err = p.ParsePublicWithImplicitAssertion(pk, publicToken, nil, expected)
+	assert.NoError(t, err)
+
+	// Any field mismatch fails
+	mismatch := NewImplicitAssertion().SetInt("version", 3).SetString("tenant", "acme")
+	_, err = DecryptWithImplicitAssertion(key, localToken, "", mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidMAC))
+	_, err = VerifyWithImplicitAssertion(publicToken, pk, "", mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+	_, err = p.ParseLocalWithImplicitAssertion(key, localToken, nil, mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidMAC))
+	_, err = p.ParsePublicWithImplicitAssertion(pk, publicToken, nil, mismatch)
+	assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+	// Missing assertion fails
+	_, err = DecryptWithImplicitAssertion(key, localToken, "", nil)
+	assert.True(t, errors.Is(err, ErrInvalidMAC))
+
+	// Encoding errors are reported
+	_, err = VerifyWithImplicitAssertion(publicToken, pk, "", NewImplicitAssertion().SetInt("tenant", 1<<60))
+	assert.True(t, errors.Is(err, ErrUnsafeInteger))
+}
@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+)
+
+// TokenInfo describes the structure of a token.
+//
+// WARNING: All fields are extracted without any cryptographic verification and
+// MUST be considered as unauthenticated. They are only suitable for debugging
+// purposes.
+type TokenInfo struct {
+	// Version is the token protocol version (unauthenticated).
+	Version string
+	// Purpose is the token purpose, `local`, `public` or `public-ph`
+	// (unauthenticated).
+	Purpose string
+	// HasFooter is true when the token has a footer segment
+	// (unauthenticated).
+	HasFooter bool
+	// Footer is the decoded footer content (unauthenticated).
+	Footer []byte
+	// PayloadLength is the approximate payload length in bytes computed from
+	// the encoded payload size without decryption (unauthenticated).
+	PayloadLength int
+	// Truncated is true when the token has no payload segment.
+	Truncated bool
+}
+
+// Inspect returns the token structure information without any key. The token
+// is never decrypted nor verified.
+func Inspect(token []byte) (*TokenInfo, error) {
+	// Check token size
+	l := DefaultLimits()
+	if err := l.checkToken(token); err != nil {
+		return nil, err
+	}
+
+	// Split version, purpose and remaining segments
+	parts := bytes.SplitN(token, []byte("."), 3)
+	if len(parts) < 2 || string(parts[0]) != "v4" {
+		return nil, fmt.Errorf("%w: invalid header", ErrInvalidTokenFormat)
+	}
+
+	// Resolve payload overhead from purpose
+	info := &TokenInfo{
+		Version: string(parts[0]),
+		Purpose: string(parts[1]),
+	}
+	var overhead int
+	switch info.Purpose {
+	case "local":
+		overhead = nonceLength + macLength
+	case "public", "public-ph":
+		overhead = ed25519.SignatureSize
+	default:
+		return nil, ErrMalformedToken{Segment: segmentPurpose, Reason: fmt.Sprintf("unknown purpose %q", info.Purpose)}
+	}
+
+	// Handle truncated tokens (`v4.local` or `v4.local.`)
+	if len(parts) == 2 || len(parts[2]) == 0 {
+		info.Truncated = true
+		return info, nil
+	}
+
+	// Split payload and footer segments
+	payloadSegment, footerSegment, err := splitToken(token, fmt.Sprintf("%s.%s.", parts[0], parts[1]), l)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute approximate payload length
+	if size := base64.RawURLEncoding.DecodedLen(len(payloadSegment)) - overhead; size > 0 {
+		info.PayloadLength = size
+	}
+
+	// Decode footer
+	if footerSegment != nil {
+		info.HasFooter = true
+		info.Footer, err = decodeSegmentTo(nil, segmentFooter, footerSegment, l.maxFooterLength())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// No error
+	return info, nil
+}
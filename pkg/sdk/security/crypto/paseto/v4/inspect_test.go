@@ -0,0 +1,116 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Inspect(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+
+	localToken, err := Encrypt(rand.Reader, key, m, f, "")
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, "", "")
+	assert.NoError(t, err)
+	digest := sha512.Sum512(m)
+	preHashedToken, err := SignPreHashed(digest[:], sk, f, "")
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		token   []byte
+		want    *TokenInfo
+		wantErr bool
+	}{
+		{
+			name:  "local",
+			token: localToken,
+			want:  &TokenInfo{Version: "v4", Purpose: "local", HasFooter: true, Footer: []byte(f), PayloadLength: len(m)},
+		},
+		{
+			name:  "public",
+			token: publicToken,
+			want:  &TokenInfo{Version: "v4", Purpose: "public", PayloadLength: len(m)},
+		},
+		{
+			name:  "public pre-hashed",
+			token: preHashedToken,
+			want:  &TokenInfo{Version: "v4", Purpose: "public-ph", HasFooter: true, Footer: []byte(f), PayloadLength: sha512.Size},
+		},
+		{
+			name:  "truncated without separator",
+			token: []byte("v4.local"),
+			want:  &TokenInfo{Version: "v4", Purpose: "local", Truncated: true},
+		},
+		{
+			name:  "truncated with separator",
+			token: []byte("v4.public."),
+			want:  &TokenInfo{Version: "v4", Purpose: "public", Truncated: true},
+		},
+		{
+			name:  "short body",
+			token: []byte("v4.local.AAAA"),
+			want:  &TokenInfo{Version: "v4", Purpose: "local"},
+		},
+		{
+			name:    "invalid version",
+			token:   []byte("v3.local.AAAA"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid purpose",
+			token:   []byte("v4.secret.AAAA"),
+			wantErr: true,
+		},
+		{
+			name:    "invalid footer",
+			token:   []byte("v4.local.AAAA.AA=="),
+			wantErr: true,
+		},
+		{
+			name:    "blank",
+			token:   []byte(""),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := Inspect(testCase.token)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/elastic/harp/pkg/sdk/encoding/canonicaljson"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+)
+
+// ErrUnknownKeyID is raised when the key identifier doesn't match any keyring
+// entry.
+var ErrUnknownKeyID = errors.New("paseto: unknown key identifier")
+
+// Keyring holds multiple local and public keys indexed by their PASERK
+// identifiers (`k4.lid` for local keys, `k4.pid` for public keys).
+//
+// Issued tokens have the key identifier stamped in the `kid` footer claim so
+// that the keyring can select the key to use for decryption or verification.
+// This allows key rotation by adding a new key, issuing tokens with it and
+// keeping old keys for verification until all old tokens expire.
+type Keyring struct {
+	mu         sync.RWMutex
+	localKeys  map[string][]byte
+	publicKeys map[string]ed25519.PublicKey
+	secretKeys map[string]ed25519.PrivateKey
+	limits     Limits
+	nonces     NonceSource
+}
+
+// KeyringOption represents functional pattern builder for optional parameters.
+type KeyringOption func(*Keyring)
+
+// WithKeyringLimits overrides the token size limits enforced before decoding.
+func WithKeyringLimits(l Limits) KeyringOption {
+	return func(kr *Keyring) {
+		kr.limits = l
+	}
+}
+
+// WithKeyringNonceSource overrides the nonce source used for local token
+// encryption.
+func WithKeyringNonceSource(src NonceSource) KeyringOption {
+	return func(kr *Keyring) {
+		kr.nonces = src
+	}
+}
+
+// NewKeyring returns an empty keyring instance.
+func NewKeyring(opts ...KeyringOption) *Keyring {
+	// Prepare defaults
+	kr := &Keyring{
+		localKeys:  map[string][]byte{},
+		publicKeys: map[string]ed25519.PublicKey{},
+		secretKeys: map[string]ed25519.PrivateKey{},
+		limits:     DefaultLimits(),
+		nonces:     DefaultNonceSource(),
+	}
+
+	// Apply optional parameters
+	for _, o := range opts {
+		o(kr)
+	}
+
+	return kr
+}
+
+// AddLocalKey registers the given symmetric key and returns its `k4.lid`
+// identifier.
+func (kr *Keyring) AddLocalKey(key []byte) (string, error) {
+	// Compute key identifier
+	kid, err := paserk.LocalID(key)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to compute local key identifier: %w", err)
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	// Register the key
+	kr.localKeys[kid] = append([]byte{}, key...)
+
+	// No error
+	return kid, nil
+}
+
+// AddPublicKey registers the given verification key and returns its `k4.pid`
+// identifier.
+func (kr *Keyring) AddPublicKey(pk ed25519.PublicKey) (string, error) {
+	// Compute key identifier
+	kid, err := paserk.PublicID(pk)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to compute public key identifier: %w", err)
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	// Register the key
+	kr.publicKeys[kid] = append(ed25519.PublicKey{}, pk...)
+
+	// No error
+	return kid, nil
+}
+
+// AddSecretKey registers the given signing key and its verification key. The
+// `k4.pid` identifier of the public key is returned so that verifiers can
+// select the key from the token footer.
+func (kr *Keyring) AddSecretKey(sk ed25519.PrivateKey) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PrivateKeySize, len(sk))
+	}
+
+	// Register public key
+	kid, err := kr.AddPublicKey(sk.Public().(ed25519.PublicKey))
+	if err != nil {
+		return "", err
+	}
+
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	// Register the key
+	kr.secretKeys[kid] = append(ed25519.PrivateKey{}, sk...)
+
+	// No error
+	return kid, nil
+}
+
+// AddKey registers the given `k4.local`, `k4.public` or `k4.secret` PASERK and
+// returns its identifier. The value can also be a key reference (`env://`,
+// `keyring://`) to the PASERK.
+func (kr *Keyring) AddKey(s string) (string, error) {
+	// Resolve key reference
+	if keyprovider.IsReference(s) {
+		v, err := keyprovider.Resolve(context.Background(), s)
+		if err != nil {
+			return "", fmt.Errorf("paseto: %w", err)
+		}
+		s = v
+	}
+
+	// Dispatch on the PASERK type
+	switch {
+	case strings.HasPrefix(s, "k4.local."):
+		key, err := paserk.DecodeLocal(s)
+		if err != nil {
+			return "", err
+		}
+		return kr.AddLocalKey(key)
+	case strings.HasPrefix(s, "k4.public."):
+		pk, err := paserk.DecodePublic(s)
+		if err != nil {
+			return "", err
+		}
+		return kr.AddPublicKey(pk)
+	case strings.HasPrefix(s, "k4.secret."):
+		sk, err := paserk.DecodeSecret(s)
+		if err != nil {
+			return "", err
+		}
+		return kr.AddSecretKey(sk)
+	default:
+		return "", fmt.Errorf("%w: expected k4.local, k4.public or k4.secret key", paserk.ErrUnexpectedType)
+	}
+}
+
+// PublicKeys returns a copy of the registered verification keys indexed by
+// their `k4.pid` identifiers.
+func (kr *Keyring) PublicKeys() map[string]ed25519.PublicKey {
+	kr.mu.RLock()
+	defer kr.mu.RUnlock()
+
+	out := make(map[string]ed25519.PublicKey, len(kr.publicKeys))
+	for kid, pk := range kr.publicKeys {
+		out[kid] = append(ed25519.PublicKey{}, pk...)
+	}
+
+	return out
+}
+
+// Remove the key matching the given identifier.
+func (kr *Keyring) Remove(kid string) {
+	kr.mu.Lock()
+	defer kr.mu.Unlock()
+
+	delete(kr.localKeys, kid)
+	delete(kr.publicKeys, kid)
+	delete(kr.secretKeys, kid)
+}
+
+// -----------------------------------------------------------------------------
+
+// Encrypt the given message with the local key matching the given identifier.
+// The key identifier is stamped in the footer `kid` claim, the given footer
+// must be blank or a JSON object.
+func (kr *Keyring) Encrypt(kid string, m []byte, f, i string) ([]byte, error) {
+	// Resolve key
+	kr.mu.RLock()
+	key, ok := kr.localKeys[kid]
+	kr.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	// Stamp key identifier
+	footer, err := stampKeyID(f, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to primitive
+	return EncryptWithNonceSource(kr.nonces, key, m, footer, i)
+}
+
+// Decrypt the given v4.local token with the key matching its footer `kid`
+// claim.
+func (kr *Keyring) Decrypt(token []byte, i string) ([]byte, error) {
+	// Extract key identifier
+	kid, footer, err := extractKeyIDAndFooter(token, kr.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve key
+	kr.mu.RLock()
+	key, ok := kr.localKeys[kid]
+	kr.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	// Delegate to primitive, footer is authenticated here.
+	return decrypt(context.Background(), key, token, string(footer), i, kr.limits)
+}
+
+// Sign the given message with the secret key matching the given identifier.
+// The key identifier is stamped in the footer `kid` claim, the given footer
+// must be blank or a JSON object.
+func (kr *Keyring) Sign(kid string, m []byte, f, i string) ([]byte, error) {
+	// Resolve key
+	kr.mu.RLock()
+	sk, ok := kr.secretKeys[kid]
+	kr.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	// Stamp key identifier
+	footer, err := stampKeyID(f, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to primitive
+	return Sign(m, sk, footer, i)
+}
+
+// Verify the given v4.public token with the public key matching its footer
+// `kid` claim.
+func (kr *Keyring) Verify(token []byte, i string) ([]byte, error) {
+	// Extract key identifier
+	kid, footer, err := extractKeyIDAndFooter(token, kr.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resolve key
+	kr.mu.RLock()
+	pk, ok := kr.publicKeys[kid]
+	kr.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnknownKeyID
+	}
+
+	// Delegate to primitive, footer is authenticated here.
+	return verifyWithLimits(context.Background(), token, pk, string(footer), i, kr.limits)
+}
+
+// -----------------------------------------------------------------------------
+
+func stampKeyID(f, kid string) (string, error) {
+	// Decode footer as JSON object
+	claims := map[string]json.RawMessage{}
+	if f != "" {
+		if err := json.Unmarshal([]byte(f), &claims); err != nil {
+			return "", fmt.Errorf("paseto: footer must be a JSON object to stamp the key identifier: %w", err)
+		}
+	}
+
+	// Set key identifier
+	rawKid, err := json.Marshal(kid)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to encode key identifier: %w", err)
+	}
+	claims["kid"] = rawKid
+
+	// Encode footer
+	out, err := canonicaljson.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to encode footer: %w", err)
+	}
+
+	// No error
+	return string(out), nil
+}
+
+func extractKeyIDAndFooter(token []byte, l Limits) (string, []byte, error) {
+	// Extract footer
+	footer, err := extractFooter(token, l)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Extract key identifier
+	kid, err := keyIDFromFooter(footer)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// No error
+	return kid, footer, nil
+}
@@ -0,0 +1,172 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+)
+
+func Test_Keyring_Local(t *testing.T) {
+	key1, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	key2 := make([]byte, KeyLength)
+	_, err = rand.Read(key2)
+	assert.NoError(t, err)
+
+	kr := NewKeyring()
+	kid1, err := kr.AddLocalKey(key1)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.lid.iVtYQDjr5gEijCSjJC3fQaJm7nCeQSeaty0Jixy8dbsk", kid1)
+	kid2, err := kr.AddLocalKey(key2)
+	assert.NoError(t, err)
+
+	m := []byte(`{"data":"this is a secret message"}`)
+	i := `{"test-vector":"keyring"}`
+
+	// Issue with old key, then with the new one
+	token1, err := kr.Encrypt(kid1, m, `{"purpose":"test"}`, i)
+	assert.NoError(t, err)
+	token2, err := kr.Encrypt(kid2, m, "", i)
+	assert.NoError(t, err)
+
+	// Both tokens are accepted
+	p, err := kr.Decrypt(token1, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	p, err = kr.Decrypt(token2, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Footer claims are preserved
+	footer, err := ExtractFooter(token1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"kid":"`+kid1+`","purpose":"test"}`, string(footer))
+
+	// Retire the old key
+	kr.Remove(kid1)
+	_, err = kr.Decrypt(token1, i)
+	assert.True(t, errors.Is(err, ErrUnknownKeyID))
+
+	// Unknown key for issuance
+	_, err = kr.Encrypt(kid1, m, "", i)
+	assert.True(t, errors.Is(err, ErrUnknownKeyID))
+
+	// Token without kid
+	token3, err := Encrypt(rand.Reader, key2, m, "", i)
+	assert.NoError(t, err)
+	_, err = kr.Decrypt(token3, i)
+	assert.True(t, errors.Is(err, ErrMissingKeyID))
+
+	// Non JSON footer
+	_, err = kr.Encrypt(kid2, m, "not-a-json", i)
+	assert.Error(t, err)
+}
+
+func Test_Keyring_Public(t *testing.T) {
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(seed)
+
+	signer := NewKeyring()
+	kid, err := signer.AddSecretKey(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.pid.yh4-bJYjOYAG6CWy0zsfPmpKylxS7uAWrxqVmBN2KAiJ", kid)
+
+	m := []byte(`{"data":"this is a signed message"}`)
+	token, err := signer.Sign(kid, m, "", "")
+	assert.NoError(t, err)
+
+	// Verifier only knows the public key
+	verifier := NewKeyring()
+	_, err = verifier.Verify(token, "")
+	assert.True(t, errors.Is(err, ErrUnknownKeyID))
+
+	_, err = verifier.AddPublicKey(sk.Public().(ed25519.PublicKey))
+	assert.NoError(t, err)
+	p, err := verifier.Verify(token, "")
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Verifier can't sign
+	_, err = verifier.Sign(kid, m, "", "")
+	assert.True(t, errors.Is(err, ErrUnknownKeyID))
+
+	// Public keys are exported as copies
+	keys := verifier.PublicKeys()
+	assert.Len(t, keys, 1)
+	assert.Equal(t, sk.Public(), keys[kid])
+	keys[kid][0] ^= 0xff
+	_, err = verifier.Verify(token, "")
+	assert.NoError(t, err)
+}
+
+func Test_Keyring_AddKey(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(rand.Reader)
+	assert.NoError(t, err)
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	localKey, err := paserk.EncodeLocal(key)
+	assert.NoError(t, err)
+	secretKey, err := paserk.EncodeSecret(sk)
+	assert.NoError(t, err)
+	publicKey, err := paserk.EncodePublic(pk)
+	assert.NoError(t, err)
+	t.Setenv("HARP_TEST_KEYRING_LOCAL", localKey)
+	t.Setenv("HARP_TEST_KEYRING_SECRET", secretKey)
+
+	kr := NewKeyring()
+
+	// Key references
+	lid, err := kr.AddKey("env://HARP_TEST_KEYRING_LOCAL")
+	assert.NoError(t, err)
+	expectedLid, err := paserk.LocalID(key)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedLid, lid)
+
+	pid, err := kr.AddKey("env://HARP_TEST_KEYRING_SECRET")
+	assert.NoError(t, err)
+
+	// Literal key
+	pid2, err := kr.AddKey(publicKey)
+	assert.NoError(t, err)
+	assert.Equal(t, pid, pid2)
+
+	// Usable keys
+	token, err := kr.Sign(pid, []byte("test"), "", "")
+	assert.NoError(t, err)
+	_, err = kr.Verify(token, "")
+	assert.NoError(t, err)
+
+	// Errors
+	_, err = kr.AddKey("env://HARP_TEST_KEYRING_UNSET")
+	assert.True(t, errors.Is(err, keyprovider.ErrNotSet))
+	_, err = kr.AddKey("k4.local-pw.AAAA")
+	assert.True(t, errors.Is(err, paserk.ErrUnexpectedType))
+	_, err = kr.AddKey("k4.local.AAAA")
+	assert.True(t, errors.Is(err, paserk.ErrInvalidKey))
+}
@@ -0,0 +1,176 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+)
+
+const (
+	privateKeyPEMType = "PRIVATE KEY"
+	publicKeyPEMType  = "PUBLIC KEY"
+)
+
+// GenerateLocalKey returns a random v4.local symmetric key read from the given
+// random source.
+func GenerateLocalKey(r io.Reader) ([]byte, error) {
+	// Check arguments
+	if r == nil {
+		return nil, errors.New("paseto: random source is nil")
+	}
+
+	// Read key material
+	key := make([]byte, KeyLength)
+	if _, err := io.ReadFull(r, key); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate local key: %w", err)
+	}
+
+	// No error
+	return key, nil
+}
+
+// GenerateKeyPair returns a random v4.public Ed25519 key pair generated from
+// the given random source.
+func GenerateKeyPair(r io.Reader) (ed25519.PublicKey, ed25519.PrivateKey, error) {
+	// Check arguments
+	if r == nil {
+		return nil, nil, errors.New("paseto: random source is nil")
+	}
+
+	// Generate key pair
+	pk, sk, err := ed25519.GenerateKey(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("paseto: unable to generate key pair: %w", err)
+	}
+
+	// No error
+	return pk, sk, nil
+}
+
+// ParsePrivateKeyPEM decodes a PKCS8 PEM encoded Ed25519 private key. The data
+// can also be a key reference (`env://`, `keyring://`) to the PEM content.
+func ParsePrivateKeyPEM(data []byte) (ed25519.PrivateKey, error) {
+	// Decode PEM block
+	der, err := decodePEM(data, privateKeyPEMType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse PKCS8 content
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to parse private key: %w", err)
+	}
+	sk, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("paseto: %T is not an Ed25519 private key", key)
+	}
+
+	// No error
+	return sk, nil
+}
+
+// ParsePublicKeyPEM decodes a SPKI PEM encoded Ed25519 public key. The data
+// can also be a key reference (`env://`, `keyring://`) to the PEM content.
+func ParsePublicKeyPEM(data []byte) (ed25519.PublicKey, error) {
+	// Decode PEM block
+	der, err := decodePEM(data, publicKeyPEMType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse SPKI content
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to parse public key: %w", err)
+	}
+	pk, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("paseto: %T is not an Ed25519 public key", key)
+	}
+
+	// No error
+	return pk, nil
+}
+
+// EncodePrivateKeyPEM encodes the given Ed25519 private key as a PKCS8 PEM
+// block.
+func EncodePrivateKeyPEM(sk ed25519.PrivateKey) ([]byte, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PrivateKeySize, len(sk))
+	}
+
+	// Encode as PKCS8
+	der, err := x509.MarshalPKCS8PrivateKey(sk)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to encode private key: %w", err)
+	}
+
+	// No error
+	return pem.EncodeToMemory(&pem.Block{Type: privateKeyPEMType, Bytes: der}), nil
+}
+
+// EncodePublicKeyPEM encodes the given Ed25519 public key as a SPKI PEM block.
+func EncodePublicKeyPEM(pk ed25519.PublicKey) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PublicKeySize, len(pk))
+	}
+
+	// Encode as SPKI
+	der, err := x509.MarshalPKIXPublicKey(pk)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to encode public key: %w", err)
+	}
+
+	// No error
+	return pem.EncodeToMemory(&pem.Block{Type: publicKeyPEMType, Bytes: der}), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func decodePEM(data []byte, blockType string) ([]byte, error) {
+	// Resolve key reference
+	if ref := strings.TrimSpace(string(data)); keyprovider.IsReference(ref) {
+		v, err := keyprovider.Resolve(context.Background(), ref)
+		if err != nil {
+			return nil, fmt.Errorf("paseto: %w", err)
+		}
+		data = []byte(v)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("paseto: unable to decode PEM content")
+	}
+	if block.Type != blockType {
+		return nil, fmt.Errorf("paseto: unexpected PEM block type '%s', expected '%s'", block.Type, blockType)
+	}
+
+	return block.Bytes, nil
+}
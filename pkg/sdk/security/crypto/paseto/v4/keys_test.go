@@ -0,0 +1,155 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
+)
+
+func Test_GenerateLocalKey(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.Len(t, key, KeyLength)
+
+	// PASERK compatible
+	_, err = paserk.EncodeLocal(key)
+	assert.NoError(t, err)
+
+	// Usable for encryption
+	token, err := Encrypt(rand.Reader, key, []byte("test"), "", "")
+	assert.NoError(t, err)
+	m, err := Decrypt(key, token, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), m)
+
+	// Short random source
+	_, err = GenerateLocalKey(bytes.NewReader(make([]byte, KeyLength-1)))
+	assert.Error(t, err)
+
+	// Nil random source
+	_, err = GenerateLocalKey(nil)
+	assert.Error(t, err)
+}
+
+func Test_GenerateKeyPair(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(rand.Reader)
+	assert.NoError(t, err)
+	assert.Len(t, pk, ed25519.PublicKeySize)
+	assert.Len(t, sk, ed25519.PrivateKeySize)
+
+	// PASERK compatible
+	_, err = paserk.EncodePublic(pk)
+	assert.NoError(t, err)
+	_, err = paserk.EncodeSecret(sk)
+	assert.NoError(t, err)
+
+	// Usable for signature
+	token, err := Sign([]byte("test"), sk, "", "")
+	assert.NoError(t, err)
+	m, err := Verify(token, pk, "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), m)
+
+	// Short random source
+	_, _, err = GenerateKeyPair(bytes.NewReader(make([]byte, ed25519.SeedSize-1)))
+	assert.Error(t, err)
+
+	// Nil random source
+	_, _, err = GenerateKeyPair(nil)
+	assert.Error(t, err)
+}
+
+func Test_KeyPEM_Errors(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(rand.Reader)
+	assert.NoError(t, err)
+
+	pkPem, err := EncodePublicKeyPEM(pk)
+	assert.NoError(t, err)
+	skPem, err := EncodePrivateKeyPEM(sk)
+	assert.NoError(t, err)
+
+	// Block types are checked
+	_, err = ParsePrivateKeyPEM(pkPem)
+	assert.Error(t, err)
+	_, err = ParsePublicKeyPEM(skPem)
+	assert.Error(t, err)
+
+	// Non PEM content
+	_, err = ParsePrivateKeyPEM([]byte("not a pem"))
+	assert.Error(t, err)
+	_, err = ParsePublicKeyPEM(nil)
+	assert.Error(t, err)
+
+	// Invalid key lengths
+	_, err = EncodePrivateKeyPEM(sk[:32])
+	assert.Error(t, err)
+	_, err = EncodePublicKeyPEM(pk[:16])
+	assert.Error(t, err)
+
+	// Other key types are rejected
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	ecDer, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	assert.NoError(t, err)
+	ecPem := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: ecDer})
+	_, err = ParsePublicKeyPEM(ecPem)
+	assert.Error(t, err)
+}
+
+func Test_KeyPEM_KeyReference(t *testing.T) {
+	pk, sk, err := GenerateKeyPair(rand.Reader)
+	assert.NoError(t, err)
+
+	skPEM, err := EncodePrivateKeyPEM(sk)
+	assert.NoError(t, err)
+	pkPEM, err := EncodePublicKeyPEM(pk)
+	assert.NoError(t, err)
+	t.Setenv("HARP_TEST_PASETO_SK", string(skPEM))
+	t.Setenv("HARP_TEST_PASETO_PK", string(pkPEM))
+	t.Setenv("HARP_TEST_PASETO_INVALID", "not a pem")
+
+	outSk, err := ParsePrivateKeyPEM([]byte("env://HARP_TEST_PASETO_SK"))
+	assert.NoError(t, err)
+	assert.Equal(t, sk, outSk)
+
+	outPk, err := ParsePublicKeyPEM([]byte("env://HARP_TEST_PASETO_PK\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, pk, outPk)
+
+	// Unset variable
+	_, err = ParsePrivateKeyPEM([]byte("env://HARP_TEST_PASETO_UNSET"))
+	assert.True(t, errors.Is(err, keyprovider.ErrNotSet))
+
+	// Malformed key
+	_, err = ParsePrivateKeyPEM([]byte("env://HARP_TEST_PASETO_INVALID"))
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, keyprovider.ErrNotSet))
+}
@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"encoding/base64"
+	"fmt"
+)
+
+const (
+	// DefaultMaxFooterLength is the default maximum decoded footer size.
+	DefaultMaxFooterLength = 8 << 10
+	// DefaultMaxTokenLength is the default maximum encoded token size.
+	DefaultMaxTokenLength = 1 << 20
+)
+
+// Limits defines the token size limits enforced before any decoding to prevent
+// unbounded allocations with hostile tokens. Zero values fall back to the
+// default limits.
+type Limits struct {
+	// MaxFooterLength is the maximum decoded footer size in bytes.
+	MaxFooterLength int
+	// MaxTokenLength is the maximum encoded token size in bytes.
+	MaxTokenLength int
+}
+
+// DefaultLimits returns the limits used by Decrypt, Verify and ExtractFooter.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxFooterLength: DefaultMaxFooterLength,
+		MaxTokenLength:  DefaultMaxTokenLength,
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func (l Limits) maxTokenLength() int {
+	if l.MaxTokenLength <= 0 {
+		return DefaultMaxTokenLength
+	}
+	return l.MaxTokenLength
+}
+
+func (l Limits) maxFooterLength() int {
+	if l.MaxFooterLength <= 0 {
+		return DefaultMaxFooterLength
+	}
+	return l.MaxFooterLength
+}
+
+// maxPayloadLength returns the decoded size of a payload filling the whole
+// token.
+func (l Limits) maxPayloadLength() int {
+	return base64.RawURLEncoding.DecodedLen(l.maxTokenLength())
+}
+
+func (l Limits) checkToken(token []byte) error {
+	maxLength := l.maxTokenLength()
+	if len(token) > maxLength {
+		return fmt.Errorf("%w: %d bytes exceeds the %d bytes limit", ErrTokenTooLarge, len(token), maxLength)
+	}
+
+	// No error
+	return nil
+}
+
+func (l Limits) checkFooter(segment []byte) error {
+	maxLength := l.maxFooterLength()
+	if size := base64.RawURLEncoding.DecodedLen(len(segment)); size > maxLength {
+		return fmt.Errorf("%w: %d bytes exceeds the %d bytes limit", ErrFooterTooLarge, size, maxLength)
+	}
+
+	// No error
+	return nil
+}
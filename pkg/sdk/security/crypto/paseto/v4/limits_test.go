@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Limits(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(key)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	largeFooter := string(bytes.Repeat([]byte("a"), DefaultMaxFooterLength+1))
+
+	localToken, err := Encrypt(rand.Reader, key, m, largeFooter, "")
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, largeFooter, "")
+	assert.NoError(t, err)
+
+	t.Run("footer too large", func(t *testing.T) {
+		_, err := Decrypt(key, localToken, largeFooter, "")
+		assert.True(t, errors.Is(err, ErrFooterTooLarge), "unexpected error %v", err)
+
+		_, err = Verify(publicToken, pk, largeFooter, "")
+		assert.True(t, errors.Is(err, ErrFooterTooLarge), "unexpected error %v", err)
+
+		_, err = ExtractFooter(localToken)
+		assert.True(t, errors.Is(err, ErrFooterTooLarge), "unexpected error %v", err)
+	})
+
+	t.Run("token too large", func(t *testing.T) {
+		token := append([]byte(v4LocalPrefix), bytes.Repeat([]byte("A"), DefaultMaxTokenLength)...)
+
+		_, err := Decrypt(key, token, "", "")
+		assert.True(t, errors.Is(err, ErrTokenTooLarge), "unexpected error %v", err)
+
+		_, err = ExtractFooter(token)
+		assert.True(t, errors.Is(err, ErrTokenTooLarge), "unexpected error %v", err)
+	})
+
+	t.Run("parser overridden limits", func(t *testing.T) {
+		p := NewParser(WithLimits(Limits{MaxFooterLength: 2 * DefaultMaxFooterLength}))
+
+		_, err := p.ParseLocal(key, localToken, []byte(largeFooter), nil)
+		assert.NoError(t, err)
+
+		_, err = p.ParsePublic(pk, publicToken, []byte(largeFooter), nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("keyring overridden limits", func(t *testing.T) {
+		kr := NewKeyring(WithKeyringLimits(Limits{MaxTokenLength: 64}))
+		kid, err := kr.AddLocalKey(key)
+		assert.NoError(t, err)
+
+		token, err := kr.Encrypt(kid, m, "", "")
+		assert.NoError(t, err)
+
+		_, err = kr.Decrypt(token, "")
+		assert.True(t, errors.Is(err, ErrTokenTooLarge), "unexpected error %v", err)
+	})
+}
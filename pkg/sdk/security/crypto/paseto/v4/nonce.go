@@ -0,0 +1,89 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NonceSource provides the v4.local encryption nonces. Implementations can be
+// backed by a hardware RNG, or wrap another source to record that a fresh
+// nonce has been drawn for compliance purpose.
+//
+// Implementations must never log or expose the nonce value.
+type NonceSource interface {
+	// Nonce fills the given buffer with fresh random bytes. An error must be
+	// raised if the buffer can't be filled completely.
+	Nonce(n []byte) error
+}
+
+// NonceSourceFunc adapts a function to the NonceSource interface.
+type NonceSourceFunc func(n []byte) error
+
+// Nonce implements NonceSource.
+func (f NonceSourceFunc) Nonce(n []byte) error {
+	return f(n)
+}
+
+// DefaultNonceSource returns the nonce source backed by `crypto/rand`.
+func DefaultNonceSource() NonceSource {
+	return ReaderNonceSource(rand.Reader)
+}
+
+// ReaderNonceSource returns a nonce source reading from the given reader. The
+// reader must be a CSPRNG in production.
+func ReaderNonceSource(r io.Reader) NonceSource {
+	return &readerNonceSource{r: r}
+}
+
+// EncryptWithNonceSource is the Encrypt variant drawing the nonce from the
+// given nonce source.
+func EncryptWithNonceSource(src NonceSource, key, m []byte, f, i string) ([]byte, error) {
+	// Check arguments
+	if src == nil {
+		return nil, errors.New("paseto: nonce source is nil")
+	}
+
+	// Draw nonce
+	var n [nonceLength]byte
+	if err := src.Nonce(n[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed, %d bytes are required: %w", nonceLength, err)
+	}
+
+	// Delegate to primitive
+	return encrypt(key, n[:], m, f, i)
+}
+
+// -----------------------------------------------------------------------------
+
+type readerNonceSource struct {
+	r io.Reader
+}
+
+func (s *readerNonceSource) Nonce(n []byte) error {
+	// Check arguments
+	if s.r == nil {
+		return errors.New("random source is nil")
+	}
+
+	_, err := io.ReadFull(s.r, n)
+	return err
+}
@@ -0,0 +1,78 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EncryptWithNonceSource(t *testing.T) {
+	key := make([]byte, KeyLength)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+
+	// Logging wrapper counting drawn nonces
+	drawn := 0
+	src := NonceSourceFunc(func(n []byte) error {
+		drawn++
+		return DefaultNonceSource().Nonce(n)
+	})
+
+	token, err := EncryptWithNonceSource(src, key, []byte("test"), "footer", "implicit")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, drawn)
+
+	m, err := Decrypt(key, token, "footer", "implicit")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("test"), m)
+
+	// Builder and keyring use the given source
+	_, err = NewLocalToken().SetPayload([]byte("test")).EncryptWithNonceSource(src, key)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, drawn)
+
+	kr := NewKeyring(WithKeyringNonceSource(src))
+	kid, err := kr.AddLocalKey(key)
+	assert.NoError(t, err)
+	_, err = kr.Encrypt(kid, []byte("test"), "", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, drawn)
+}
+
+func Test_EncryptWithNonceSource_Errors(t *testing.T) {
+	key := make([]byte, KeyLength)
+
+	_, err := EncryptWithNonceSource(nil, key, []byte("test"), "", "")
+	assert.Error(t, err)
+
+	failure := errors.New("hardware rng failure")
+	_, err = EncryptWithNonceSource(NonceSourceFunc(func(n []byte) error { return failure }), key, []byte("test"), "", "")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, failure))
+
+	_, err = EncryptWithNonceSource(ReaderNonceSource(bytes.NewReader(nil)), key, []byte("test"), "", "")
+	assert.Error(t, err)
+
+	_, err = EncryptWithNonceSource(ReaderNonceSource(nil), key, []byte("test"), "", "")
+	assert.Error(t, err)
+}
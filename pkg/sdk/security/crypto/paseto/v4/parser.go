@@ -0,0 +1,221 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Token represents an authenticated PASETO token.
+type Token struct {
+	// Claims holds the decoded registered claims.
+	Claims RegisteredClaims
+	// Payload holds the raw authenticated payload.
+	Payload []byte
+	// Footer holds the raw authenticated footer.
+	Footer []byte
+}
+
+// ParserOption represents functional pattern builder for optional parameters.
+type ParserOption func(*Parser)
+
+// WithClock sets the time source used by time based validators.
+func WithClock(clock func() time.Time) ParserOption {
+	return func(p *Parser) {
+		p.clock = clock
+	}
+}
+
+// WithExpiration enables the `exp` claim validation.
+func WithExpiration() ParserOption {
+	return WithValidator(ExpirationValidator())
+}
+
+// WithNotBefore enables the `nbf` claim validation.
+func WithNotBefore() ParserOption {
+	return WithValidator(NotBeforeValidator())
+}
+
+// WithIssuedAt enables the `iat` claim validation.
+func WithIssuedAt() ParserOption {
+	return WithValidator(IssuedAtValidator())
+}
+
+// WithIssuer enables the `iss` claim validation.
+func WithIssuer(expected string) ParserOption {
+	return WithValidator(IssuerValidator(expected))
+}
+
+// WithSubject enables the `sub` claim validation.
+func WithSubject(expected string) ParserOption {
+	return WithValidator(SubjectValidator(expected))
+}
+
+// WithAudience enables the `aud` claim validation.
+func WithAudience(expected string) ParserOption {
+	return WithValidator(AudienceValidator(expected))
+}
+
+// WithTokenID enables the `jti` claim validation.
+func WithTokenID(expected string) ParserOption {
+	return WithValidator(TokenIDValidator(expected))
+}
+
+// WithLimits overrides the token size limits enforced before decoding.
+func WithLimits(l Limits) ParserOption {
+	return func(p *Parser) {
+		p.limits = l
+	}
+}
+
+// WithPayloadValidator sets the validator applied to the raw payload after
+// decryption or verification and before claims decoding.
+func WithPayloadValidator(v PayloadValidator) ParserOption {
+	return func(p *Parser) {
+		p.payloadValidator = v
+	}
+}
+
+// WithValidator registers a custom claims validator.
+func WithValidator(v ClaimsValidator) ParserOption {
+	return func(p *Parser) {
+		p.validators = append(p.validators, v)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Parser decodes and validates PASETO v4 tokens.
+type Parser struct {
+	clock            func() time.Time
+	validators       []ClaimsValidator
+	payloadValidator PayloadValidator
+	replayGuard      ReplayGuard
+	limits           Limits
+}
+
+// NewParser returns a token parser instance with the given validation
+// options.
+func NewParser(opts ...ParserOption) *Parser {
+	// Prepare defaults
+	p := &Parser{
+		clock:      time.Now,
+		validators: []ClaimsValidator{},
+		limits:     DefaultLimits(),
+	}
+
+	// Apply optional parameters
+	for _, o := range opts {
+		o(p)
+	}
+
+	return p
+}
+
+// ParseLocal decrypts the given v4.local token and validates its claims.
+func (p *Parser) ParseLocal(key, token, footer, implicit []byte) (*Token, error) {
+	// Decrypt the token
+	m, err := decrypt(context.Background(), key, token, string(footer), string(implicit), p.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate claims
+	return p.validate(m, footer)
+}
+
+// ParsePublic verifies the given v4.public token and validates its claims.
+func (p *Parser) ParsePublic(pk ed25519.PublicKey, token, footer, implicit []byte) (*Token, error) {
+	// Verify the token
+	m, err := verifyWithLimits(context.Background(), token, pk, string(footer), string(implicit), p.limits)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate claims
+	return p.validate(m, footer)
+}
+
+// ParseLocalWithImplicitAssertion decrypts the given v4.local token bound to
+// the given implicit assertion values and validates its claims.
+func (p *Parser) ParseLocalWithImplicitAssertion(key, token, footer []byte, a *ImplicitAssertion) (*Token, error) {
+	// Encode implicit assertion
+	i, err := a.encodeOrBlank()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to parser
+	return p.ParseLocal(key, token, footer, []byte(i))
+}
+
+// ParsePublicWithImplicitAssertion verifies the given v4.public token bound to
+// the given implicit assertion values and validates its claims.
+func (p *Parser) ParsePublicWithImplicitAssertion(pk ed25519.PublicKey, token, footer []byte, a *ImplicitAssertion) (*Token, error) {
+	// Encode implicit assertion
+	i, err := a.encodeOrBlank()
+	if err != nil {
+		return nil, err
+	}
+
+	// Delegate to parser
+	return p.ParsePublic(pk, token, footer, []byte(i))
+}
+
+// -----------------------------------------------------------------------------
+
+func (p *Parser) validate(m, footer []byte) (*Token, error) {
+	// Validate payload
+	if p.payloadValidator != nil {
+		if err := p.payloadValidator(m); err != nil {
+			return nil, fmt.Errorf("paseto: payload validation failed: %w", err)
+		}
+	}
+
+	// Decode claims
+	var claims RegisteredClaims
+	if err := json.Unmarshal(m, &claims); err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode token claims: %w", err)
+	}
+
+	// Apply all validators
+	now := p.clock()
+	for _, v := range p.validators {
+		if err := v(&claims, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Consume one-time token
+	if p.replayGuard != nil {
+		if err := checkReplay(p.replayGuard, &claims); err != nil {
+			return nil, err
+		}
+	}
+
+	// No error
+	return &Token{
+		Claims:  claims,
+		Payload: m,
+		Footer:  footer,
+	}, nil
+}
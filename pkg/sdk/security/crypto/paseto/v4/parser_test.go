@@ -0,0 +1,153 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedClock(value string) func() time.Time {
+	return func() time.Time {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+}
+
+func Test_Parser_Local(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	m, err := NewClaims(365*24*time.Hour, WithIssuanceClock(fixedClock("2021-01-01T00:00:00Z"))).
+		Set("iss", "harp").
+		Set("sub", "user").
+		Set("aud", "svc").
+		Set("jti", "123").
+		Bytes()
+	assert.NoError(t, err)
+	f := []byte(`{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`)
+	i := []byte(`{"test-vector":"4-E-7"}`)
+
+	token, err := Encrypt(rand.Reader, key, m, string(f), string(i))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		opts    []ParserOption
+		wantErr error
+	}{
+		{
+			name: "no validator",
+		},
+		{
+			name: "valid",
+			opts: []ParserOption{
+				WithClock(fixedClock("2021-06-01T00:00:00Z")),
+				WithExpiration(), WithNotBefore(), WithIssuedAt(),
+				WithIssuer("harp"), WithSubject("user"), WithAudience("svc"), WithTokenID("123"),
+			},
+		},
+		{
+			name:    "expired",
+			opts:    []ParserOption{WithClock(fixedClock("2022-06-01T00:00:00Z")), WithExpiration()},
+			wantErr: ErrExpired,
+		},
+		{
+			name:    "not yet valid",
+			opts:    []ParserOption{WithClock(fixedClock("2020-06-01T00:00:00Z")), WithNotBefore()},
+			wantErr: ErrNotYetValid,
+		},
+		{
+			name:    "issued in future",
+			opts:    []ParserOption{WithClock(fixedClock("2020-06-01T00:00:00Z")), WithIssuedAt()},
+			wantErr: ErrIssuedInFuture,
+		},
+		{
+			name:    "invalid audience",
+			opts:    []ParserOption{WithAudience("other")},
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:    "invalid issuer",
+			opts:    []ParserOption{WithIssuer("other")},
+			wantErr: ErrInvalidIssuer,
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			tok, err := NewParser(testCase.opts...).ParseLocal(key, token, f, i)
+			if testCase.wantErr != nil {
+				assert.True(t, errors.Is(err, testCase.wantErr), "unexpected error %v", err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, m, tok.Payload)
+			assert.Equal(t, f, tok.Footer)
+			assert.Equal(t, "harp", tok.Claims.Issuer)
+			assert.Equal(t, "svc", tok.Claims.Audience)
+		})
+	}
+}
+
+func Test_Parser_Public(t *testing.T) {
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	t.Run("vector", func(t *testing.T) {
+		// 4-S-3
+		token := []byte("v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9")
+		f := []byte(`{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`)
+		i := []byte(`{"test-vector":"4-S-3"}`)
+
+		tok, err := NewParser(WithClock(fixedClock("2021-12-31T23:59:59Z")), WithExpiration()).ParsePublic(pk, token, f, i)
+		assert.NoError(t, err)
+		assert.NotNil(t, tok.Claims.Expiration)
+
+		_, err = NewParser(WithClock(fixedClock("2022-01-01T00:00:01Z")), WithExpiration()).ParsePublic(pk, token, f, i)
+		assert.True(t, errors.Is(err, ErrExpired))
+	})
+
+	t.Run("missing claim", func(t *testing.T) {
+		token, err := Sign([]byte(`{"data":"test"}`), sk, "", "")
+		assert.NoError(t, err)
+
+		_, err = NewParser(WithExpiration()).ParsePublic(pk, token, nil, nil)
+		assert.True(t, errors.Is(err, ErrMissingClaim))
+	})
+
+	t.Run("invalid payload", func(t *testing.T) {
+		token, err := Sign([]byte(`not-a-json`), sk, "", "")
+		assert.NoError(t, err)
+
+		_, err = NewParser().ParsePublic(pk, token, nil, nil)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,226 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+
+	"filippo.io/edwards25519"
+
+	"github.com/elastic/harp/pkg/sdk/security"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+// Pre-hashed signature is a harp specific extension, the produced token uses
+// a distinct header and can't be verified by other PASETO implementations.
+//
+// The token body is the caller provided SHA-512 digest followed by an
+// Ed25519ph (RFC 8032 section 5.1) signature of PAE(h, d, f, i).
+//
+// Security tradeoffs:
+//   - Ed25519ph is not collision resilient, the signature is only as strong as
+//     SHA-512 collision resistance, while pure Ed25519 is not affected.
+//   - The signed document is not part of the token, the verifier MUST compare
+//     the returned digest with the digest of the document it received.
+const (
+	v4PublicPreHashedPrefix = "v4.public-ph."
+	preHashLength           = sha512.Size
+)
+
+// dom2 is the Ed25519ph domain separator with an empty context.
+// https://datatracker.ietf.org/doc/html/rfc8032#section-5.1
+var dom2 = []byte("SigEd25519 no Ed25519 collisions\x01\x00")
+
+// SignPreHashed signs the given SHA-512 document digest as a `v4.public-ph.`
+// token using Ed25519ph. The digest must be exactly 64 bytes long.
+func SignPreHashed(digest []byte, sk ed25519.PrivateKey, f, i string) ([]byte, error) {
+	// Check arguments
+	if len(digest) != preHashLength {
+		return nil, fmt.Errorf("paseto: invalid digest length, expected a %d bytes SHA-512 digest, got %d", preHashLength, len(digest))
+	}
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PrivateKeySize, len(sk))
+	}
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding([]byte(v4PublicPreHashedPrefix), digest, []byte(f), []byte(i))
+	ph := sha512.Sum512(m2)
+
+	// Sign protected content
+	sig, err := ed25519phSign(sk, ph[:])
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to sign content: %w", err)
+	}
+
+	// Prepare content
+	body := append([]byte{}, digest...)
+	body = append(body, sig...)
+
+	// Encode body as RawURLBase64
+	encodedBody := make([]byte, base64.RawURLEncoding.EncodedLen(len(body)))
+	base64.RawURLEncoding.Encode(encodedBody, body)
+
+	// Assemble final token
+	final := append([]byte(v4PublicPreHashedPrefix), encodedBody...)
+	if f != "" {
+		// Encode footer as RawURLBase64
+		encodedFooter := make([]byte, base64.RawURLEncoding.EncodedLen(len(f)))
+		base64.RawURLEncoding.Encode(encodedFooter, []byte(f))
+
+		// Assemble body and footer
+		final = append(final, append([]byte("."), encodedFooter...)...)
+	}
+
+	// No error
+	return final, nil
+}
+
+// VerifyPreHashed verifies the given `v4.public-ph.` token and checks that it
+// has been issued for the given SHA-512 document digest.
+func VerifyPreHashed(sm []byte, pk ed25519.PublicKey, digest []byte, f, i string) error {
+	// Verify the token
+	d, err := verifyPreHashed(sm, pk, f, i, DefaultLimits())
+	if err != nil {
+		return err
+	}
+
+	// Compare digest
+	if !security.SecureCompare(d, digest) {
+		return fmt.Errorf("%w: document digest mismatch", ErrInvalidSignature)
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func verifyPreHashed(sm []byte, pk ed25519.PublicKey, f, i string, l Limits) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, ed25519.PublicKeySize, len(pk))
+	}
+
+	// Parse token
+	raw, footer, err := parseToken(sm, v4PublicPreHashedPrefix, l)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != preHashLength+ed25519.SignatureSize {
+		return nil, ErrMalformedToken{Segment: segmentPayload, Reason: "invalid pre-hashed payload length"}
+	}
+
+	// Compare footer
+	if !ConstantTimeFooterEqual([]byte(f), footer) {
+		return nil, ErrFooterMismatch
+	}
+
+	// Extract components
+	d := raw[:preHashLength]
+	sig := raw[preHashLength:]
+
+	// Compute protected content
+	m2 := paseto.PreAuthenticationEncoding([]byte(v4PublicPreHashedPrefix), d, []byte(f), []byte(i))
+	ph := sha512.Sum512(m2)
+
+	// Check signature
+	if !ed25519phVerify(pk, ph[:], sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	// No error
+	return d, nil
+}
+
+// https://datatracker.ietf.org/doc/html/rfc8032#section-5.1.6
+func ed25519phSign(sk ed25519.PrivateKey, ph []byte) ([]byte, error) {
+	// Expand the private key
+	h := sha512.Sum512(sk.Seed())
+	s, err := edwards25519.NewScalar().SetBytesWithClamping(h[:32])
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the deterministic nonce
+	nonceHash := sha512.New()
+	nonceHash.Write(dom2)
+	nonceHash.Write(h[32:])
+	nonceHash.Write(ph)
+	r, err := edwards25519.NewScalar().SetUniformBytes(nonceHash.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+	R := (&edwards25519.Point{}).ScalarBaseMult(r)
+
+	// Compute the challenge
+	k, err := ed25519phChallenge(R.Bytes(), sk[32:], ph)
+	if err != nil {
+		return nil, err
+	}
+
+	// S = r + k * s
+	S := edwards25519.NewScalar().MultiplyAdd(k, s, r)
+
+	// No error
+	return append(R.Bytes(), S.Bytes()...), nil
+}
+
+// https://datatracker.ietf.org/doc/html/rfc8032#section-5.1.7
+func ed25519phVerify(pk ed25519.PublicKey, ph, sig []byte) bool {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize || len(sig) != ed25519.SignatureSize {
+		return false
+	}
+
+	// Decode the public key point
+	A, err := (&edwards25519.Point{}).SetBytes(pk)
+	if err != nil {
+		return false
+	}
+
+	// Decode the signature scalar, it must be canonical
+	S, err := edwards25519.NewScalar().SetCanonicalBytes(sig[32:])
+	if err != nil {
+		return false
+	}
+
+	// Compute the challenge
+	k, err := ed25519phChallenge(sig[:32], pk, ph)
+	if err != nil {
+		return false
+	}
+
+	// R = [S]B - [k]A
+	minusA := (&edwards25519.Point{}).Negate(A)
+	R := (&edwards25519.Point{}).VarTimeDoubleScalarBaseMult(k, minusA, S)
+
+	return bytes.Equal(sig[:32], R.Bytes())
+}
+
+func ed25519phChallenge(R, A, ph []byte) (*edwards25519.Scalar, error) {
+	kh := sha512.New()
+	kh.Write(dom2)
+	kh.Write(R)
+	kh.Write(A)
+	kh.Write(ph)
+	return edwards25519.NewScalar().SetUniformBytes(kh.Sum(nil))
+}
@@ -0,0 +1,114 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// https://datatracker.ietf.org/doc/html/rfc8032#section-7.3
+func Test_Ed25519ph_Vector(t *testing.T) {
+	seed, err := hex.DecodeString("833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42")
+	assert.NoError(t, err)
+	expected, err := hex.DecodeString("98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406")
+	assert.NoError(t, err)
+
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+	assert.Equal(t, "ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf", hex.EncodeToString(pk))
+
+	ph := sha512.Sum512([]byte("abc"))
+	sig, err := ed25519phSign(sk, ph[:])
+	assert.NoError(t, err)
+	assert.Equal(t, expected, sig)
+	assert.True(t, ed25519phVerify(pk, ph[:], sig))
+
+	// Pure Ed25519 must not accept the pre-hashed signature
+	assert.False(t, ed25519.Verify(pk, ph[:], sig))
+
+	// Tampered signature
+	sig[0] ^= 0x01
+	assert.False(t, ed25519phVerify(pk, ph[:], sig))
+}
+
+func Test_Paseto_PreHashed(t *testing.T) {
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	sk := ed25519.NewKeyFromSeed(seed)
+	pk := sk.Public().(ed25519.PublicKey)
+
+	digest := sha512.Sum512([]byte("a very large document"))
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+	i := "{\"test\":\"ph\"}"
+
+	token, err := SignPreHashed(digest[:], sk, f, i)
+	assert.NoError(t, err)
+	assert.Contains(t, string(token), v4PublicPreHashedPrefix)
+
+	t.Run("verify dispatch", func(t *testing.T) {
+		d, err := Verify(token, pk, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, digest[:], d)
+	})
+
+	t.Run("verify digest", func(t *testing.T) {
+		assert.NoError(t, VerifyPreHashed(token, pk, digest[:], f, i))
+
+		other := sha512.Sum512([]byte("another document"))
+		err := VerifyPreHashed(token, pk, other[:], f, i)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("implicit assertion mismatch", func(t *testing.T) {
+		_, err := Verify(token, pk, f, "")
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("header confusion", func(t *testing.T) {
+		// Replace the header to present the token as a standard public token
+		confused := append([]byte(v4PublicPrefix), token[len(v4PublicPreHashedPrefix):]...)
+		_, err := Verify(confused, pk, f, i)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+
+		// Standard token presented as pre-hashed
+		std, err := Sign(digest[:], sk, f, i)
+		assert.NoError(t, err)
+		confused = append([]byte(v4PublicPreHashedPrefix), std[len(v4PublicPrefix):]...)
+		_, err = Verify(confused, pk, f, i)
+		assert.True(t, errors.Is(err, ErrInvalidSignature))
+	})
+
+	t.Run("invalid digest length", func(t *testing.T) {
+		_, err := SignPreHashed(digest[:32], sk, f, i)
+		assert.Error(t, err)
+		_, err = SignPreHashed(nil, sk, f, i)
+		assert.Error(t, err)
+	})
+
+	t.Run("extract footer", func(t *testing.T) {
+		footer, err := ExtractFooter(token)
+		assert.NoError(t, err)
+		assert.Equal(t, f, string(footer))
+	})
+}
@@ -0,0 +1,149 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrReplayed is raised when a one-time token is used more than once.
+var ErrReplayed = errors.New("paseto: token has already been used")
+
+// ReplayGuard records the consumed token identifiers (`jti` claim) so that a
+// one-time token can only be used once.
+type ReplayGuard interface {
+	// Seen returns true when the given token identifier has already been
+	// consumed.
+	Seen(jti string) (bool, error)
+	// Mark records the given token identifier as consumed until the given
+	// expiration date, a zero date means no expiration. ErrReplayed must be
+	// returned when the identifier is already marked so that concurrent uses
+	// of the same token are detected.
+	Mark(jti string, exp time.Time) error
+}
+
+// WithReplayGuard enables the replay protection using the given guard. The
+// `jti` claim becomes required, and is marked as consumed once all other
+// validations succeed. ErrReplayed is raised when the token is used again.
+//
+// Identifiers are retained until the token expiration, combine it with
+// WithExpiration so that expired tokens can't be replayed once forgotten.
+func WithReplayGuard(g ReplayGuard) ParserOption {
+	return func(p *Parser) {
+		p.replayGuard = g
+	}
+}
+
+// NewMemoryReplayGuard returns an in-memory replay guard. Expired identifiers
+// are pruned when the guard grows. It is not shared across
+// processes, use a distributed guard when the verifiers are replicated.
+func NewMemoryReplayGuard() ReplayGuard {
+	return &memoryReplayGuard{
+		clock:     time.Now,
+		seen:      map[string]time.Time{},
+		pruneSize: minReplayPruneSize,
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+const minReplayPruneSize = 1024
+
+func checkReplay(g ReplayGuard, claims *RegisteredClaims) error {
+	// Check arguments
+	if claims.TokenID == "" {
+		return fmt.Errorf("%w: jti", ErrMissingClaim)
+	}
+
+	// Check if the token has already been consumed
+	seen, err := g.Seen(claims.TokenID)
+	if err != nil {
+		return fmt.Errorf("paseto: unable to check token replay: %w", err)
+	}
+	if seen {
+		return ErrReplayed
+	}
+
+	// Mark as consumed
+	var exp time.Time
+	if claims.Expiration != nil {
+		exp = *claims.Expiration
+	}
+	if err := g.Mark(claims.TokenID, exp); err != nil {
+		if errors.Is(err, ErrReplayed) {
+			return ErrReplayed
+		}
+		return fmt.Errorf("paseto: unable to mark token as used: %w", err)
+	}
+
+	// No error
+	return nil
+}
+
+type memoryReplayGuard struct {
+	sync.Mutex
+	clock     func() time.Time
+	seen      map[string]time.Time
+	pruneSize int
+}
+
+func (g *memoryReplayGuard) Seen(jti string) (bool, error) {
+	g.Lock()
+	defer g.Unlock()
+
+	exp, ok := g.seen[jti]
+	if !ok {
+		return false, nil
+	}
+
+	// No error
+	return exp.IsZero() || g.clock().Before(exp), nil
+}
+
+func (g *memoryReplayGuard) Mark(jti string, exp time.Time) error {
+	g.Lock()
+	defer g.Unlock()
+
+	now := g.clock()
+
+	// Prune expired identifiers, the next pruning is scheduled when the
+	// remaining entry count doubles.
+	if len(g.seen) >= g.pruneSize {
+		for k, v := range g.seen {
+			if !v.IsZero() && !now.Before(v) {
+				delete(g.seen, k)
+			}
+		}
+		g.pruneSize = 2 * len(g.seen)
+		if g.pruneSize < minReplayPruneSize {
+			g.pruneSize = minReplayPruneSize
+		}
+	}
+
+	// Check concurrent usage
+	if v, ok := g.seen[jti]; ok && (v.IsZero() || now.Before(v)) {
+		return ErrReplayed
+	}
+	g.seen[jti] = exp
+
+	// No error
+	return nil
+}
@@ -0,0 +1,141 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parser_ReplayGuard(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	issue := func(jti string) []byte {
+		c := NewClaims(time.Hour)
+		if jti != "" {
+			c.Set("jti", jti)
+		}
+		m, err := c.Bytes()
+		assert.NoError(t, err)
+		token, err := Encrypt(rand.Reader, key, m, "", "")
+		assert.NoError(t, err)
+		return token
+	}
+
+	p := NewParser(
+		WithExpiration(),
+		WithReplayGuard(NewMemoryReplayGuard()),
+	)
+
+	// First use
+	token := issue("reset-1")
+	_, err = p.ParseLocal(key, token, nil, nil)
+	assert.NoError(t, err)
+
+	// Second use
+	_, err = p.ParseLocal(key, token, nil, nil)
+	assert.True(t, errors.Is(err, ErrReplayed))
+
+	// Another token with the same identifier
+	_, err = p.ParseLocal(key, issue("reset-1"), nil, nil)
+	assert.True(t, errors.Is(err, ErrReplayed))
+
+	// Missing identifier
+	_, err = p.ParseLocal(key, issue(""), nil, nil)
+	assert.True(t, errors.Is(err, ErrMissingClaim))
+
+	// Rejected tokens are not consumed
+	expired := NewParser(
+		WithClock(func() time.Time { return time.Now().Add(2 * time.Hour) }),
+		WithExpiration(),
+		WithReplayGuard(NewMemoryReplayGuard()),
+	)
+	token = issue("reset-2")
+	_, err = expired.ParseLocal(key, token, nil, nil)
+	assert.True(t, errors.Is(err, ErrExpired))
+	_, err = p.ParseLocal(key, token, nil, nil)
+	assert.NoError(t, err)
+}
+
+func Test_MemoryReplayGuard(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewMemoryReplayGuard().(*memoryReplayGuard)
+	g.clock = func() time.Time { return now }
+
+	assert.NoError(t, g.Mark("a", now.Add(time.Minute)))
+	assert.NoError(t, g.Mark("forever", time.Time{}))
+	assert.Equal(t, ErrReplayed, g.Mark("a", now.Add(time.Minute)))
+
+	seen, err := g.Seen("a")
+	assert.NoError(t, err)
+	assert.True(t, seen)
+
+	// Expired identifiers are forgotten
+	now = now.Add(2 * time.Minute)
+	seen, err = g.Seen("a")
+	assert.NoError(t, err)
+	assert.False(t, seen)
+	assert.NoError(t, g.Mark("a", now.Add(time.Minute)))
+
+	seen, err = g.Seen("forever")
+	assert.NoError(t, err)
+	assert.True(t, seen)
+}
+
+func Test_MemoryReplayGuard_Prune(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	g := NewMemoryReplayGuard().(*memoryReplayGuard)
+	g.clock = func() time.Time { return now }
+
+	for i := 0; i < minReplayPruneSize; i++ {
+		assert.NoError(t, g.Mark(hex.EncodeToString([]byte{byte(i >> 8), byte(i)}), now.Add(time.Second)))
+	}
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, g.Mark("last", time.Time{}))
+	assert.Len(t, g.seen, 1)
+}
+
+func Test_MemoryReplayGuard_Concurrent(t *testing.T) {
+	g := NewMemoryReplayGuard()
+
+	var (
+		wg       sync.WaitGroup
+		accepted int32
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := checkReplay(g, &RegisteredClaims{TokenID: "once"}); err == nil {
+				atomic.AddInt32(&accepted, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), accepted)
+}
@@ -0,0 +1,315 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+)
+
+// Streaming encryption is a harp specific extension, the produced content is
+// not a PASETO token and can't be decoded by other PASETO implementations.
+//
+// Stream layout:
+//
+//	h || n || frame_0 || ... || frame_k
+//	frame = flag (1 byte) || len(c) (uint32 BE) || c || t
+//
+// Each frame is authenticated independently using the same key derivation as
+// `v4.local` tokens with t = BLAKE2b-256(Ak, PAE(h, n, counter, flag, c, f, i)).
+// The counter prevents frame reordering and the final flag prevents
+// truncation.
+const (
+	v4StreamPrefix   = "v4.stream."
+	streamChunkSize  = 64 * 1024
+	streamFrameFinal = 0x01
+	streamFrameData  = 0x00
+	streamFrameHead  = 5
+)
+
+// EncryptStream returns a reader producing the encrypted form of the given
+// plaintext reader. The content is processed by chunks so that the whole
+// message is never buffered in memory.
+func EncryptStream(r io.Reader, key []byte, plaintext io.Reader, f, i string) (io.Reader, error) {
+	// Check arguments
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, KeyLength, len(key))
+	}
+	if plaintext == nil {
+		return nil, errors.New("paseto: plaintext reader is nil")
+	}
+
+	// Create random seed
+	var n [nonceLength]byte
+	if _, err := io.ReadFull(r, n[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	}
+
+	// Derive keys from seed and secret key
+	ek, n2, ak, err := kdf(key, n[:])
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	}
+
+	// Prepare XChaCha20 stream cipher
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+	}
+
+	// Prepare stream header
+	out := &bytes.Buffer{}
+	out.WriteString(v4StreamPrefix)
+	out.Write(n[:])
+
+	// No error
+	return &encryptReader{
+		src:  bufio.NewReaderSize(plaintext, streamChunkSize),
+		ciph: ciph,
+		ak:   ak,
+		n:    n[:],
+		f:    f,
+		i:    i,
+		out:  out,
+	}, nil
+}
+
+// DecryptStream returns a reader producing the plaintext of the given stream.
+// Each chunk is authenticated before being released to the caller.
+func DecryptStream(key []byte, ciphertext io.Reader, f, i string) (io.Reader, error) {
+	// Check arguments
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidKeyLength, KeyLength, len(key))
+	}
+	if ciphertext == nil {
+		return nil, errors.New("paseto: ciphertext reader is nil")
+	}
+
+	// Read stream header
+	var h [len(v4StreamPrefix) + nonceLength]byte
+	if _, err := io.ReadFull(ciphertext, h[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to read stream header: %w", err)
+	}
+	if !bytes.HasPrefix(h[:], []byte(v4StreamPrefix)) {
+		return nil, errors.New("paseto: invalid stream header")
+	}
+	n := h[len(v4StreamPrefix):]
+
+	// Derive keys from seed and secret key
+	ek, n2, ak, err := kdf(key, n)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	}
+
+	// Prepare XChaCha20 stream cipher
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+	}
+
+	// No error
+	return &decryptReader{
+		src:  ciphertext,
+		ciph: ciph,
+		ak:   ak,
+		n:    append([]byte{}, n...),
+		f:    f,
+		i:    i,
+		out:  &bytes.Buffer{},
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type encryptReader struct {
+	src     *bufio.Reader
+	ciph    *chacha20.Cipher
+	ak      []byte
+	n       []byte
+	f, i    string
+	counter uint64
+	out     *bytes.Buffer
+	done    bool
+	err     error
+}
+
+func (er *encryptReader) Read(p []byte) (int, error) {
+	for er.out.Len() == 0 {
+		if er.err != nil {
+			return 0, er.err
+		}
+		if er.done {
+			return 0, io.EOF
+		}
+		er.err = er.nextFrame()
+	}
+
+	return er.out.Read(p)
+}
+
+func (er *encryptReader) nextFrame() error {
+	// Read next chunk
+	m := make([]byte, streamChunkSize)
+	l, err := io.ReadFull(er.src, m)
+	switch {
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		er.done = true
+	case err != nil:
+		return fmt.Errorf("paseto: unable to read plaintext: %w", err)
+	default:
+		// Check if there is remaining data
+		if _, errPeek := er.src.Peek(1); errors.Is(errPeek, io.EOF) {
+			er.done = true
+		} else if errPeek != nil {
+			return fmt.Errorf("paseto: unable to read plaintext: %w", errPeek)
+		}
+	}
+
+	// Encrypt chunk
+	flag := byte(streamFrameData)
+	if er.done {
+		flag = streamFrameFinal
+	}
+	c := make([]byte, l)
+	er.ciph.XORKeyStream(c, m[:l])
+	secret.Wipe(m)
+
+	// Authenticate frame
+	t, err := frameMAC(er.ak, er.n, er.counter, flag, c, er.f, er.i)
+	if err != nil {
+		return err
+	}
+	er.counter++
+
+	// Serialize frame
+	var head [streamFrameHead]byte
+	head[0] = flag
+	binary.BigEndian.PutUint32(head[1:], uint32(l))
+	er.out.Write(head[:])
+	er.out.Write(c)
+	er.out.Write(t)
+
+	// No error
+	return nil
+}
+
+type decryptReader struct {
+	src     io.Reader
+	ciph    *chacha20.Cipher
+	ak      []byte
+	n       []byte
+	f, i    string
+	counter uint64
+	out     *bytes.Buffer
+	done    bool
+	err     error
+}
+
+func (dr *decryptReader) Read(p []byte) (int, error) {
+	for dr.out.Len() == 0 {
+		if dr.err != nil {
+			return 0, dr.err
+		}
+		if dr.done {
+			return 0, io.EOF
+		}
+		dr.err = dr.nextFrame()
+	}
+
+	return dr.out.Read(p)
+}
+
+func (dr *decryptReader) nextFrame() error {
+	// Read frame header
+	var head [streamFrameHead]byte
+	if _, err := io.ReadFull(dr.src, head[:]); err != nil {
+		return fmt.Errorf("paseto: invalid stream, unable to read frame header: %w", err)
+	}
+	flag := head[0]
+	if flag != streamFrameData && flag != streamFrameFinal {
+		return errors.New("paseto: invalid stream, unknown frame type")
+	}
+	l := binary.BigEndian.Uint32(head[1:])
+	if l > streamChunkSize {
+		return errors.New("paseto: invalid stream, frame is too large")
+	}
+
+	// Read frame content
+	frame := make([]byte, int(l)+macLength)
+	if _, err := io.ReadFull(dr.src, frame); err != nil {
+		return fmt.Errorf("paseto: invalid stream, unable to read frame: %w", err)
+	}
+	c, t := frame[:l], frame[l:]
+
+	// Authenticate frame
+	t2, err := frameMAC(dr.ak, dr.n, dr.counter, flag, c, dr.f, dr.i)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(t, t2) != 1 {
+		return fmt.Errorf("%w: invalid stream frame", ErrInvalidMAC)
+	}
+	dr.counter++
+
+	// Ensure no trailing data after final frame
+	if flag == streamFrameFinal {
+		var extra [1]byte
+		if n, _ := dr.src.Read(extra[:]); n > 0 {
+			return errors.New("paseto: invalid stream, trailing data after final frame")
+		}
+		dr.done = true
+	}
+
+	// Decrypt chunk
+	m := make([]byte, len(c))
+	dr.ciph.XORKeyStream(m, c)
+	dr.out.Write(m)
+	secret.Wipe(m)
+
+	// No error
+	return nil
+}
+
+func frameMAC(ak, n []byte, counter uint64, flag byte, c []byte, f, i string) ([]byte, error) {
+	var ctr [8]byte
+	binary.LittleEndian.PutUint64(ctr[:], counter)
+
+	// Compute pre-authentication message
+	preAuth := paseto.PreAuthenticationEncoding([]byte(v4StreamPrefix), n, ctr[:], []byte{flag}, c, []byte(f), []byte(i))
+
+	// Compute MAC
+	mac, err := blake2b.New(macLength, ak)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to in initialize MAC kdf: %w", err)
+	}
+	mac.Write(preAuth)
+
+	// No error
+	return mac.Sum(nil), nil
+}
@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Stream_EncryptDecrypt(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	f := "{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}"
+	i := "{\"test-vector\":\"4-E-7\"}"
+
+	for _, size := range []int{0, 1, streamChunkSize - 1, streamChunkSize, streamChunkSize + 1, 3*streamChunkSize + 17} {
+		m := make([]byte, size)
+		_, err := io.ReadFull(rand.Reader, m)
+		assert.NoError(t, err)
+
+		// Encrypt
+		er, err := EncryptStream(rand.Reader, key, bytes.NewReader(m), f, i)
+		assert.NoError(t, err)
+		c, err := io.ReadAll(er)
+		assert.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(c, []byte(v4StreamPrefix)))
+
+		// Decrypt
+		dr, err := DecryptStream(key, bytes.NewReader(c), f, i)
+		assert.NoError(t, err)
+		p, err := io.ReadAll(dr)
+		assert.NoError(t, err)
+		assert.Equal(t, m, p)
+	}
+}
+
+func Test_Paseto_Stream_Tampered(t *testing.T) {
+	key, err := hex.DecodeString("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f")
+	assert.NoError(t, err)
+
+	m := make([]byte, 2*streamChunkSize+10)
+	er, err := EncryptStream(rand.Reader, key, bytes.NewReader(m), "", "")
+	assert.NoError(t, err)
+	c, err := io.ReadAll(er)
+	assert.NoError(t, err)
+
+	frameLength := streamFrameHead + streamChunkSize + macLength
+	headerLength := len(v4StreamPrefix) + nonceLength
+
+	testCases := []struct {
+		name   string
+		stream func() []byte
+		f      string
+	}{
+		{
+			name: "flipped bit",
+			stream: func() []byte {
+				out := append([]byte{}, c...)
+				out[headerLength+streamFrameHead+1] ^= 0x01
+				return out
+			},
+		},
+		{
+			name: "truncated",
+			stream: func() []byte {
+				return c[:headerLength+frameLength]
+			},
+		},
+		{
+			name: "reordered",
+			stream: func() []byte {
+				out := append([]byte{}, c[:headerLength]...)
+				out = append(out, c[headerLength+frameLength:headerLength+2*frameLength]...)
+				out = append(out, c[headerLength:headerLength+frameLength]...)
+				out = append(out, c[headerLength+2*frameLength:]...)
+				return out
+			},
+		},
+		{
+			name: "trailing data",
+			stream: func() []byte {
+				return append(append([]byte{}, c...), 0x00)
+			},
+		},
+		{
+			name: "footer mismatch",
+			stream: func() []byte {
+				return c
+			},
+			f: "footer",
+		},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			dr, err := DecryptStream(key, bytes.NewReader(testCase.stream()), testCase.f, "")
+			assert.NoError(t, err)
+			_, err = io.ReadAll(dr)
+			assert.Error(t, err)
+		})
+	}
+
+	// Invalid header
+	_, err = DecryptStream(key, bytes.NewReader([]byte("v4.local.AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA")), "", "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,129 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ErrNonceReuse is raised when a nonce is used twice with the same key.
+var ErrNonceReuse = errors.New("paseto: nonce reuse detected")
+
+// NonceTracker detects repeated nonces with the same local key. It is a
+// safety net for custom nonce sources and deterministic encryption, the
+// v4.local confidentiality collapses when a nonce is repeated.
+type NonceTracker interface {
+	// Track records the nonce used with the given key. ErrNonceReuse must be
+	// returned when the nonce has already been used with this key.
+	Track(key, n []byte) error
+}
+
+// SetNonceTracker installs the process-wide nonce tracker consulted before
+// each local token encryption. A nil tracker disables the detection, which is
+// the default for performance reasons.
+func SetNonceTracker(t NonceTracker) {
+	nonceTracker.Store(trackerHolder{tracker: t})
+}
+
+// NewRecentNonceTracker returns a nonce tracker remembering the last `size`
+// nonces. Nonces are not stored as is, only their keyed BLAKE2b fingerprint
+// is kept.
+func NewRecentNonceTracker(size int) NonceTracker {
+	if size <= 0 {
+		size = defaultTrackerSize
+	}
+
+	return &recentNonceTracker{
+		seen: make(map[[blake2b.Size256]byte]struct{}, size),
+		ring: make([][blake2b.Size256]byte, 0, size),
+		size: size,
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+const defaultTrackerSize = 1 << 16
+
+var nonceTracker atomic.Value
+
+type trackerHolder struct {
+	tracker NonceTracker
+}
+
+// trackNonce consults the installed nonce tracker if any.
+func trackNonce(key, n []byte) error {
+	h, ok := nonceTracker.Load().(trackerHolder)
+	if !ok || h.tracker == nil {
+		return nil
+	}
+
+	// Delegate to tracker
+	if err := h.tracker.Track(key, n); err != nil {
+		return fmt.Errorf("paseto: nonce rejected by tracker: %w", err)
+	}
+
+	// No error
+	return nil
+}
+
+type recentNonceTracker struct {
+	mu   sync.Mutex
+	seen map[[blake2b.Size256]byte]struct{}
+	ring [][blake2b.Size256]byte
+	next int
+	size int
+}
+
+func (t *recentNonceTracker) Track(key, n []byte) error {
+	// Compute nonce fingerprint
+	h, err := blake2b.New256(key)
+	if err != nil {
+		return fmt.Errorf("unable to initialize nonce fingerprint: %w", err)
+	}
+	h.Write([]byte("paseto-nonce-tracker"))
+	h.Write(n)
+
+	var fp [blake2b.Size256]byte
+	copy(fp[:], h.Sum(nil))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Check reuse
+	if _, ok := t.seen[fp]; ok {
+		return ErrNonceReuse
+	}
+
+	// Evict the oldest fingerprint when full
+	if len(t.ring) < t.size {
+		t.ring = append(t.ring, fp)
+	} else {
+		delete(t.seen, t.ring[t.next])
+		t.ring[t.next] = fp
+		t.next = (t.next + 1) % t.size
+	}
+	t.seen[fp] = struct{}{}
+
+	// No error
+	return nil
+}
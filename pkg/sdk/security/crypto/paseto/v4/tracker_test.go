@@ -0,0 +1,88 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NonceTracker(t *testing.T) {
+	SetNonceTracker(NewRecentNonceTracker(16))
+	t.Cleanup(func() {
+		SetNonceTracker(nil)
+	})
+
+	key := make([]byte, KeyLength)
+	_, err := rand.Read(key)
+	assert.NoError(t, err)
+	otherKey := make([]byte, KeyLength)
+	_, err = rand.Read(otherKey)
+	assert.NoError(t, err)
+
+	// Broken source always returning the same nonce
+	fixed := NonceSourceFunc(func(n []byte) error {
+		for idx := range n {
+			n[idx] = 0x42
+		}
+		return nil
+	})
+
+	_, err = EncryptWithNonceSource(fixed, key, []byte("first"), "", "")
+	assert.NoError(t, err)
+
+	_, err = EncryptWithNonceSource(fixed, key, []byte("second"), "", "")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrNonceReuse))
+
+	// Same nonce under another key is accepted
+	_, err = EncryptWithNonceSource(fixed, otherKey, []byte("first"), "", "")
+	assert.NoError(t, err)
+
+	// Random nonces are accepted
+	for idx := 0; idx < 32; idx++ {
+		_, err = Encrypt(rand.Reader, key, []byte("test"), "", "")
+		assert.NoError(t, err)
+	}
+
+	// Disabled tracker
+	SetNonceTracker(nil)
+	_, err = EncryptWithNonceSource(fixed, key, []byte("second"), "", "")
+	assert.NoError(t, err)
+}
+
+func Test_RecentNonceTracker_Eviction(t *testing.T) {
+	underTest := NewRecentNonceTracker(2)
+	key := make([]byte, KeyLength)
+
+	n1 := []byte("nonce-1")
+	n2 := []byte("nonce-2")
+	n3 := []byte("nonce-3")
+
+	assert.NoError(t, underTest.Track(key, n1))
+	assert.NoError(t, underTest.Track(key, n2))
+	assert.True(t, errors.Is(underTest.Track(key, n1), ErrNonceReuse))
+
+	// n1 is evicted by n3
+	assert.NoError(t, underTest.Track(key, n3))
+	assert.NoError(t, underTest.Track(key, n1))
+	assert.True(t, errors.Is(underTest.Track(key, n3), ErrNonceReuse))
+}
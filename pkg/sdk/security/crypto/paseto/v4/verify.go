@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/binary"
+	"sync"
+)
+
+const (
+	// scratchBufferSize covers a 4KiB token with its PAE encoding.
+	scratchBufferSize = 8 << 10
+	// maxPooledBufferSize prevents oversized buffers from being retained.
+	maxPooledBufferSize = 64 << 10
+)
+
+var scratchPool = sync.Pool{
+	New: func() interface{} {
+		buf := make([]byte, 0, scratchBufferSize)
+		return &buf
+	},
+}
+
+// verify is the allocation free signature verification path. All
+// intermediate values are written to the given scratch buffer, the returned
+// message is a sub-slice of the returned scratch buffer.
+func verify(scratch, sm []byte, pk ed25519.PublicKey, f, i string, l Limits) (m, out []byte, err error) {
+	// Split token
+	payloadSegment, footerSegment, err := splitToken(sm, v4PublicPrefix, l)
+	if err != nil {
+		return nil, scratch, err
+	}
+
+	// Decode payload
+	out, err = decodeSegmentTo(scratch[:0], segmentPayload, payloadSegment, l.maxPayloadLength())
+	if err != nil {
+		return nil, scratch, err
+	}
+	scratch = out
+	rawLen := len(scratch)
+	if rawLen < ed25519.SignatureSize {
+		return nil, scratch, ErrMalformedToken{Segment: segmentPayload, Reason: "payload is too short"}
+	}
+
+	// Decode footer
+	if footerSegment != nil {
+		out, err = decodeSegmentTo(scratch, segmentFooter, footerSegment, l.maxFooterLength())
+		if err != nil {
+			return nil, scratch, err
+		}
+		scratch = out
+	}
+
+	// Compare footer
+	if !constantTimeFooterEqualString(f, scratch[rawLen:]) {
+		return nil, scratch, ErrFooterMismatch
+	}
+
+	// Compute protected content
+	mLen := rawLen - ed25519.SignatureSize
+	scratch = scratch[:rawLen]
+	scratch = appendPAECount(scratch, 4)
+	scratch = appendPAEString(scratch, v4PublicPrefix)
+	scratch = appendPAEBytes(scratch, scratch[:mLen])
+	scratch = appendPAEString(scratch, f)
+	scratch = appendPAEString(scratch, i)
+
+	// Check signature
+	if !ed25519.Verify(pk, scratch[rawLen:], scratch[mLen:rawLen]) {
+		return nil, scratch, ErrInvalidSignature
+	}
+
+	// No error
+	return scratch[:mLen], scratch, nil
+}
+
+// constantTimeFooterEqualString is the string variant of
+// ConstantTimeFooterEqual, it prevents a string to bytes conversion.
+func constantTimeFooterEqualString(a string, b []byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	var v byte
+	for i := 0; i < len(a); i++ {
+		v |= a[i] ^ b[i]
+	}
+
+	return subtle.ConstantTimeByteEq(v, 0) == 1
+}
+
+// appendPAE* helpers build the paseto.PreAuthenticationEncoding output in
+// place, string pieces are appended without conversion.
+func appendPAECount(dst []byte, count int) []byte {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], uint64(count))
+	return append(dst, tmp[:]...)
+}
+
+func appendPAEBytes(dst, piece []byte) []byte {
+	dst = appendPAECount(dst, len(piece))
+	return append(dst, piece...)
+}
+
+func appendPAEString(dst []byte, piece string) []byte {
+	dst = appendPAECount(dst, len(piece))
+	return append(dst, piece...)
+}
@@ -0,0 +1,39 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto"
+)
+
+func Test_AppendPAE(t *testing.T) {
+	m := []byte("message")
+	f := "footer"
+
+	out := appendPAECount(nil, 4)
+	out = appendPAEString(out, v4PublicPrefix)
+	out = appendPAEBytes(out, m)
+	out = appendPAEString(out, f)
+	out = appendPAEString(out, "")
+
+	assert.Equal(t, paseto.PreAuthenticationEncoding([]byte(v4PublicPrefix), m, []byte(f), []byte("")), out)
+}
@@ -0,0 +1,53 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package wasm
+
+import (
+	"fmt"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+	v4 "github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4"
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+)
+
+// Verify checks the given `v4.public` token signature using the `k4.public`
+// PASERK encoded public key and returns the signed payload.
+func Verify(token, publicKey, footer, implicit string) ([]byte, error) {
+	// Decode public key
+	pk, err := paserk.DecodePublic(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode public key: %w", err)
+	}
+
+	// Delegate to primitive
+	return v4.Verify([]byte(token), pk, footer, implicit)
+}
+
+// Decrypt decrypts the given `v4.local` token using the `k4.local` PASERK
+// encoded symmetric key and returns the payload.
+func Decrypt(token, localKey, footer, implicit string) ([]byte, error) {
+	// Decode symmetric key
+	key, err := paserk.DecodeLocal(localKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode local key: %w", err)
+	}
+	defer secret.Wipe(key)
+
+	// Delegate to primitive
+	return v4.Decrypt(key, []byte(token), footer, implicit)
+}
@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package wasm
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/paseto/paserk"
+)
+
+// https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+const (
+	vectorLocalKey     = "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"
+	vectorLocalToken   = "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WkwMsYXw6FSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t4x-RMNXtQNbz7FvFZ_G-lFpk5RG3EOrwDL6CgDqcerSQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+	vectorLocalFooter  = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+	vectorLocalPayload = `{"data":"this is a secret message","exp":"2022-01-01T00:00:00+00:00"}`
+
+	vectorPublicKey      = "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2"
+	vectorPublicToken    = "v4.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9NPWciuD3d0o5eXJXG5pJy-DiVEoyPYWs1YSTwWHNJq6DZD3je5gf-0M4JR9ipdUSJbIovzmBECeaWmaqcaP0DQ.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+	vectorPublicFooter   = `{"kid":"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}`
+	vectorPublicImplicit = `{"test-vector":"4-S-3"}`
+	vectorPublicPayload  = `{"data":"this is a signed message","exp":"2022-01-01T00:00:00+00:00"}`
+)
+
+func testKeys(t *testing.T) (localKey, publicKey string) {
+	t.Helper()
+
+	raw, err := hex.DecodeString(vectorLocalKey)
+	assert.NoError(t, err)
+	localKey, err = paserk.EncodeLocal(raw)
+	assert.NoError(t, err)
+
+	raw, err = hex.DecodeString(vectorPublicKey)
+	assert.NoError(t, err)
+	publicKey, err = paserk.EncodePublic(ed25519.PublicKey(raw))
+	assert.NoError(t, err)
+
+	return localKey, publicKey
+}
+
+func TestVerify(t *testing.T) {
+	_, publicKey := testKeys(t)
+
+	payload, err := Verify(vectorPublicToken, publicKey, vectorPublicFooter, vectorPublicImplicit)
+	assert.NoError(t, err)
+	assert.Equal(t, vectorPublicPayload, string(payload))
+
+	// Implicit assertion is authenticated
+	_, err = Verify(vectorPublicToken, publicKey, vectorPublicFooter, "")
+	assert.Error(t, err)
+
+	// Key must be a public PASERK
+	_, err = Verify(vectorPublicToken, vectorPublicKey, vectorPublicFooter, vectorPublicImplicit)
+	assert.Error(t, err)
+}
+
+func TestDecrypt(t *testing.T) {
+	localKey, _ := testKeys(t)
+
+	payload, err := Decrypt(vectorLocalToken, localKey, vectorLocalFooter, "")
+	assert.NoError(t, err)
+	assert.Equal(t, vectorLocalPayload, string(payload))
+
+	// Footer is authenticated
+	_, err = Decrypt(vectorLocalToken, localKey, "", "")
+	assert.Error(t, err)
+
+	// Key must be a local PASERK
+	_, err = Decrypt(vectorLocalToken, vectorLocalKey, vectorLocalFooter, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package wasm exposes PASETO v4 token verification and decryption to
+// WebAssembly hosts.
+//
+// The package only depends on the v4 and paserk packages which build with
+// `GOOS=js GOARCH=wasm` and TinyGo. Keys are exchanged as PASERK strings
+// (`k4.public.` / `k4.local.`) so that no raw key material encoding has to be
+// agreed on with the host.
+//
+// The JavaScript bindings are registered by `cmd/harp-wasm`:
+//
+//	GOOS=js GOARCH=wasm go build -o harp.wasm ./cmd/harp-wasm
+//	tinygo build -o harp.wasm -target wasm ./cmd/harp-wasm
+package wasm
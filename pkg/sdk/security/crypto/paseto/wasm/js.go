@@ -0,0 +1,77 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build js && wasm
+// +build js,wasm
+
+package wasm
+
+import (
+	"syscall/js"
+)
+
+// Register exposes the `harp` object in the JavaScript global scope.
+//
+//	harp.pasetoV4Verify(token, publicKey, footer, implicit)
+//	harp.pasetoV4Decrypt(token, localKey, footer, implicit)
+//
+// Both functions return an object holding either the `payload` string or
+// the `error` message. Footer and implicit assertion are optional.
+func Register() {
+	js.Global().Set("harp", js.ValueOf(map[string]interface{}{
+		"pasetoV4Verify":  js.FuncOf(wrap(Verify)),
+		"pasetoV4Decrypt": js.FuncOf(wrap(Decrypt)),
+	}))
+}
+
+// -----------------------------------------------------------------------------
+
+type primitive func(token, key, footer, implicit string) ([]byte, error)
+
+func wrap(fn primitive) func(js.Value, []js.Value) interface{} {
+	return func(_ js.Value, args []js.Value) interface{} {
+		// Check arguments
+		if len(args) < 2 {
+			return result(nil, "token and key are required")
+		}
+
+		// Extract optional arguments
+		var footer, implicit string
+		if len(args) > 2 && args[2].Type() == js.TypeString {
+			footer = args[2].String()
+		}
+		if len(args) > 3 && args[3].Type() == js.TypeString {
+			implicit = args[3].String()
+		}
+
+		// Delegate to primitive
+		payload, err := fn(args[0].String(), args[1].String(), footer, implicit)
+		if err != nil {
+			return result(nil, err.Error())
+		}
+
+		// No error
+		return result(payload, "")
+	}
+}
+
+func result(payload []byte, err string) interface{} {
+	if err != "" {
+		return map[string]interface{}{"error": err}
+	}
+	return map[string]interface{}{"payload": string(payload)}
+}
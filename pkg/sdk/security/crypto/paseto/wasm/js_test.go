@@ -0,0 +1,53 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build js && wasm
+// +build js,wasm
+
+package wasm
+
+import (
+	"syscall/js"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	localKey, publicKey := testKeys(t)
+
+	Register()
+	harp := js.Global().Get("harp")
+
+	// Verify
+	res := harp.Call("pasetoV4Verify", vectorPublicToken, publicKey, vectorPublicFooter, vectorPublicImplicit)
+	assert.True(t, res.Get("error").IsUndefined())
+	assert.Equal(t, vectorPublicPayload, res.Get("payload").String())
+
+	// Decrypt, the implicit assertion is optional
+	res = harp.Call("pasetoV4Decrypt", vectorLocalToken, localKey, vectorLocalFooter)
+	assert.True(t, res.Get("error").IsUndefined())
+	assert.Equal(t, vectorLocalPayload, res.Get("payload").String())
+
+	// Errors are returned as messages
+	res = harp.Call("pasetoV4Decrypt", vectorLocalToken, localKey)
+	assert.True(t, res.Get("payload").IsUndefined())
+	assert.NotEmpty(t, res.Get("error").String())
+
+	res = harp.Call("pasetoV4Verify", vectorPublicToken)
+	assert.Equal(t, "token and key are required", res.Get("error").String())
+}
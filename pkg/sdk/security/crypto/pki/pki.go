@@ -0,0 +1,298 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pki
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+
+	"go.step.sm/crypto/pemutil"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/keygen"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+const (
+	// DefaultKeySpec is the key specification used when no key is supplied.
+	DefaultKeySpec = "ecdsa:p256"
+	// DefaultCAValidity is the default certificate authority validity.
+	DefaultCAValidity = 365 * 24 * time.Hour
+	// DefaultValidity is the default leaf certificate validity.
+	DefaultValidity = 24 * time.Hour
+)
+
+// Request describes the certificate to generate.
+type Request struct {
+	CommonName     string
+	Organization   []string
+	DNSNames       []string
+	IPAddresses    []net.IP
+	EmailAddresses []string
+	// KeyUsage defaults to DigitalSignature|CertSign|CRLSign for a CA and
+	// DigitalSignature|KeyEncipherment for a leaf certificate.
+	KeyUsage x509.KeyUsage
+	// ExtKeyUsage defaults to ServerAuth for a leaf certificate.
+	ExtKeyUsage []x509.ExtKeyUsage
+	// NotBefore defaults to the current time.
+	NotBefore time.Time
+	// Validity defaults to DefaultCAValidity or DefaultValidity.
+	Validity time.Duration
+	// KeySpec is the keygen specification used to generate the certificate key
+	// when Key is not set.
+	KeySpec string
+	// Key is the fixed certificate key. The generated certificate is only
+	// reproducible when Key and NotBefore are set and the key uses a
+	// deterministic signature scheme (ed25519, rsa).
+	Key crypto.Signer
+}
+
+// Certificate is a PEM encoded certificate and private key pair.
+type Certificate struct {
+	// Cert is the PEM encoded certificate.
+	Cert string
+	// Key is the PEM encoded PKCS8 private key.
+	Key string
+
+	certificate *x509.Certificate
+	signer      crypto.Signer
+}
+
+// Certificate returns the parsed certificate.
+func (c *Certificate) Certificate() *x509.Certificate {
+	return c.certificate
+}
+
+// Signer returns the certificate private key.
+func (c *Certificate) Signer() crypto.Signer {
+	return c.signer
+}
+
+// ParseCertificate decodes the given PEM encoded certificate and private key.
+func ParseCertificate(certPEM, keyPEM string) (*Certificate, error) {
+	// Decode certificate
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("pki: unable to decode certificate PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to parse certificate: %w", err)
+	}
+
+	// Decode private key
+	signer, err := ParsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return &Certificate{
+		Cert:        certPEM,
+		Key:         keyPEM,
+		certificate: cert,
+		signer:      signer,
+	}, nil
+}
+
+// ParsePrivateKey decodes the given PEM encoded private key.
+func ParsePrivateKey(keyPEM string) (crypto.Signer, error) {
+	key, err := pemutil.Parse([]byte(keyPEM))
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("pki: key type %T can't be used to sign certificates", key)
+	}
+
+	// No error
+	return signer, nil
+}
+
+// GenerateCA generates a self-signed certificate authority.
+func GenerateCA(req *Request) (*Certificate, error) {
+	return generate(req, nil, true)
+}
+
+// GenerateSelfSigned generates a self-signed leaf certificate.
+func GenerateSelfSigned(req *Request) (*Certificate, error) {
+	return generate(req, nil, false)
+}
+
+// GenerateSigned generates a leaf certificate signed by the given certificate
+// authority.
+func GenerateSigned(ca *Certificate, req *Request) (*Certificate, error) {
+	// Check arguments
+	if ca == nil || ca.certificate == nil || types.IsNil(ca.signer) {
+		return nil, errors.New("pki: certificate authority must have a certificate and a private key")
+	}
+	if !ca.certificate.IsCA {
+		return nil, errors.New("pki: the given certificate is not a certificate authority")
+	}
+
+	// Delegate to implementation
+	return generate(req, ca, false)
+}
+
+// -----------------------------------------------------------------------------
+
+//nolint:gocyclo // flat defaulting
+func generate(req *Request, parent *Certificate, isCA bool) (*Certificate, error) {
+	// Check arguments
+	if req == nil {
+		return nil, errors.New("pki: certificate request is nil")
+	}
+
+	// Resolve certificate key
+	fixedKey := !types.IsNil(req.Key)
+	signer := req.Key
+	if !fixedKey {
+		spec := req.KeySpec
+		if spec == "" {
+			spec = DefaultKeySpec
+		}
+		kp, err := keygen.Generate(spec)
+		if err != nil {
+			return nil, fmt.Errorf("pki: unable to generate certificate key: %w", err)
+		}
+		var ok bool
+		if signer, ok = kp.Private.(crypto.Signer); !ok {
+			return nil, fmt.Errorf("pki: key specification '%s' can't be used to sign certificates", spec)
+		}
+	}
+
+	// Apply defaults
+	notBefore := req.NotBefore
+	if notBefore.IsZero() {
+		notBefore = time.Now()
+	}
+	validity := req.Validity
+	if validity <= 0 {
+		validity = DefaultValidity
+		if isCA {
+			validity = DefaultCAValidity
+		}
+	}
+	keyUsage := req.KeyUsage
+	if keyUsage == 0 {
+		keyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		if isCA {
+			keyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		}
+	}
+	extKeyUsage := req.ExtKeyUsage
+	if len(extKeyUsage) == 0 && !isCA {
+		extKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	// Encode public key
+	pubDer, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to encode public key: %w", err)
+	}
+
+	// Prepare serial number
+	serial, err := serialNumber(fixedKey, pubDer, req.CommonName, notBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare template
+	keyID := sha256.Sum256(pubDer)
+	tmpl := &x509.Certificate{
+		SerialNumber: serial,
+		Subject: pkix.Name{
+			CommonName:   req.CommonName,
+			Organization: req.Organization,
+		},
+		DNSNames:              req.DNSNames,
+		IPAddresses:           req.IPAddresses,
+		EmailAddresses:        req.EmailAddresses,
+		NotBefore:             notBefore.UTC(),
+		NotAfter:              notBefore.Add(validity).UTC(),
+		KeyUsage:              keyUsage,
+		ExtKeyUsage:           extKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+		SubjectKeyId:          keyID[:20],
+	}
+
+	// Select issuer
+	issuer, issuerKey := tmpl, signer
+	if parent != nil {
+		issuer, issuerKey = parent.certificate, parent.signer
+	}
+
+	// Sign certificate
+	certDer, err := x509.CreateCertificate(rand.Reader, tmpl, issuer, signer.Public(), issuerKey)
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to sign certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(certDer)
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to parse generated certificate: %w", err)
+	}
+
+	// Encode private key
+	keyDer, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, fmt.Errorf("pki: unable to encode private key: %w", err)
+	}
+
+	// No error
+	return &Certificate{
+		Cert:        string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDer})),
+		Key:         string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDer})),
+		certificate: cert,
+		signer:      signer,
+	}, nil
+}
+
+// serialNumber returns a random 128bit serial number, or a serial number
+// derived from the certificate identity when the key is fixed so that the
+// output stays reproducible.
+func serialNumber(fixedKey bool, pubDer []byte, cn string, notBefore time.Time) (*big.Int, error) {
+	if !fixedKey {
+		serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return nil, fmt.Errorf("pki: unable to generate serial number: %w", err)
+		}
+		return serial, nil
+	}
+
+	// Derive from identity
+	h := sha256.New()
+	h.Write(pubDer)
+	h.Write([]byte(cn))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(notBefore.Unix()))
+	h.Write(ts[:])
+
+	// No error
+	return new(big.Int).SetBytes(h.Sum(nil)[:16]), nil
+}
@@ -0,0 +1,100 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pki
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSigned(t *testing.T) {
+	ca, err := GenerateCA(&Request{CommonName: "Harp Test CA"})
+	assert.NoError(t, err)
+	assert.True(t, ca.Certificate().IsCA)
+	assert.Equal(t, x509.KeyUsageDigitalSignature|x509.KeyUsageCertSign|x509.KeyUsageCRLSign, ca.Certificate().KeyUsage)
+
+	leaf, err := GenerateSigned(ca, &Request{
+		CommonName:  "api.internal",
+		DNSNames:    []string{"api.internal", "api"},
+		IPAddresses: []net.IP{net.ParseIP("10.0.0.1")},
+		Validity:    time.Hour,
+	})
+	assert.NoError(t, err)
+
+	cert := leaf.Certificate()
+	assert.False(t, cert.IsCA)
+	assert.Equal(t, []string{"api.internal", "api"}, cert.DNSNames)
+	assert.Equal(t, time.Hour, cert.NotAfter.Sub(cert.NotBefore))
+	assert.Equal(t, ca.Certificate().SubjectKeyId, cert.AuthorityKeyId)
+
+	// Verify chain
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Certificate())
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName: "api",
+		Roots:   roots,
+	})
+	assert.NoError(t, err)
+
+	// PEM round trip
+	parsed, err := ParseCertificate(leaf.Cert, leaf.Key)
+	assert.NoError(t, err)
+	assert.Equal(t, cert.Raw, parsed.Certificate().Raw)
+	assert.Equal(t, leaf.Signer().Public(), parsed.Signer().Public())
+}
+
+func TestGenerateSigned_NotCA(t *testing.T) {
+	leaf, err := GenerateSelfSigned(&Request{CommonName: "leaf"})
+	assert.NoError(t, err)
+
+	_, err = GenerateSigned(leaf, &Request{CommonName: "other"})
+	assert.Error(t, err)
+	_, err = GenerateSigned(nil, &Request{CommonName: "other"})
+	assert.Error(t, err)
+	_, err = GenerateCA(nil)
+	assert.Error(t, err)
+	_, err = GenerateCA(&Request{KeySpec: "x25519"})
+	assert.Error(t, err)
+}
+
+func TestGenerateSelfSigned_Deterministic(t *testing.T) {
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	req := &Request{
+		CommonName: "fixed",
+		NotBefore:  time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Key:        key,
+	}
+
+	c1, err := GenerateSelfSigned(req)
+	assert.NoError(t, err)
+	c2, err := GenerateSelfSigned(req)
+	assert.NoError(t, err)
+	assert.Equal(t, c1.Cert, c2.Cert)
+	assert.Equal(t, c1.Key, c2.Key)
+
+	// Random key produces different certificates
+	req.Key = nil
+	c3, err := GenerateSelfSigned(req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c1.Cert, c3.Cert)
+}
@@ -0,0 +1,153 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ssh
+
+import (
+	"crypto"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.step.sm/crypto/pemutil"
+	gossh "golang.org/x/crypto/ssh"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/keygen"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// HostKey describes a generated SSH host key.
+type HostKey struct {
+	// Algorithm is the SSH key type (i.e. ssh-ed25519).
+	Algorithm string
+	// Private is the raw private key.
+	Private crypto.PrivateKey
+	// Public is the raw public key.
+	Public crypto.PublicKey
+	// PrivatePEM is the OpenSSH encoded private key.
+	PrivatePEM string
+	// AuthorizedKey is the public key formatted as an authorized_keys line.
+	AuthorizedKey string
+	// Fingerprint is the SHA256 public key fingerprint.
+	Fingerprint string
+}
+
+// GenerateHostKey generates a SSH host key for the given algorithm.
+//
+// Supported algorithms are ed25519, ecdsa (P-256), ecdsa:p384 and ecdsa:p521.
+func GenerateHostKey(algo string) (*HostKey, error) {
+	// Check algorithm
+	spec := strings.ToLower(strings.TrimSpace(algo))
+	switch spec {
+	case "", "ed25519":
+		spec = "ed25519"
+	case "ecdsa", "ecdsa:p256", "ecdsa:p384", "ecdsa:p521":
+	default:
+		return nil, fmt.Errorf("ssh: unsupported host key algorithm '%s'", algo)
+	}
+
+	// Delegate to key generator
+	kp, err := keygen.Generate(spec)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: unable to generate host key: %w", err)
+	}
+
+	// Encode private key
+	block, err := pemutil.SerializeOpenSSHPrivateKey(kp.Private)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: unable to encode private key: %w", err)
+	}
+
+	// Encode public key
+	pub, err := gossh.NewPublicKey(kp.Public)
+	if err != nil {
+		return nil, fmt.Errorf("ssh: unable to convert public key: %w", err)
+	}
+
+	// No error
+	return &HostKey{
+		Algorithm:     pub.Type(),
+		Private:       kp.Private,
+		Public:        kp.Public,
+		PrivatePEM:    string(pem.EncodeToMemory(block)),
+		AuthorizedKey: authorizedLine(pub, ""),
+		Fingerprint:   gossh.FingerprintSHA256(pub),
+	}, nil
+}
+
+// PublicKeyAuthorizedLine returns the authorized_keys line of the given public
+// key. The key can be a raw public key, a ssh.PublicKey or a HostKey.
+func PublicKeyAuthorizedLine(pub interface{}, comment string) (string, error) {
+	// Convert key
+	pk, err := toPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return authorizedLine(pk, comment), nil
+}
+
+// Fingerprint returns the SHA256 fingerprint (SHA256:<base64>) of the given
+// public key. The key can be a raw public key, a ssh.PublicKey or a HostKey.
+func Fingerprint(pub interface{}) (string, error) {
+	// Convert key
+	pk, err := toPublicKey(pub)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return gossh.FingerprintSHA256(pk), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func toPublicKey(pub interface{}) (gossh.PublicKey, error) {
+	// Check arguments
+	if types.IsNil(pub) {
+		return nil, errors.New("ssh: public key is nil")
+	}
+
+	switch k := pub.(type) {
+	case gossh.PublicKey:
+		return k, nil
+	case *HostKey:
+		return toPublicKey(k.Public)
+	case string:
+		pk, _, _, _, err := gossh.ParseAuthorizedKey([]byte(k))
+		if err != nil {
+			return nil, fmt.Errorf("ssh: unable to parse authorized key: %w", err)
+		}
+		return pk, nil
+	default:
+		pk, err := gossh.NewPublicKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("ssh: unable to convert public key: %w", err)
+		}
+		return pk, nil
+	}
+}
+
+func authorizedLine(pub gossh.PublicKey, comment string) string {
+	line := strings.TrimSuffix(string(gossh.MarshalAuthorizedKey(pub)), "\n")
+	if comment = strings.TrimSpace(comment); comment != "" {
+		line = fmt.Sprintf("%s %s", line, comment)
+	}
+	return line
+}
@@ -0,0 +1,86 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package ssh
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+func TestGenerateHostKey(t *testing.T) {
+	testCases := []struct {
+		algo     string
+		wantType string
+	}{
+		{algo: "ed25519", wantType: "ssh-ed25519"},
+		{algo: "ecdsa", wantType: "ecdsa-sha2-nistp256"},
+		{algo: "ecdsa:p384", wantType: "ecdsa-sha2-nistp384"},
+		{algo: "ecdsa:p521", wantType: "ecdsa-sha2-nistp521"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.algo, func(t *testing.T) {
+			hk, err := GenerateHostKey(testCase.algo)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantType, hk.Algorithm)
+			assert.True(t, strings.HasPrefix(hk.Fingerprint, "SHA256:"))
+			assert.True(t, strings.HasPrefix(hk.AuthorizedKey, testCase.wantType+" "))
+
+			// Private key must be parseable
+			signer, err := gossh.ParsePrivateKey([]byte(hk.PrivatePEM))
+			assert.NoError(t, err)
+			assert.Equal(t, hk.Fingerprint, gossh.FingerprintSHA256(signer.PublicKey()))
+
+			// Fingerprint from authorized line
+			fp, err := Fingerprint(hk.AuthorizedKey)
+			assert.NoError(t, err)
+			assert.Equal(t, hk.Fingerprint, fp)
+		})
+	}
+}
+
+func TestGenerateHostKey_Unsupported(t *testing.T) {
+	_, err := GenerateHostKey("rsa:2048")
+	assert.Error(t, err)
+}
+
+func TestPublicKeyAuthorizedLine(t *testing.T) {
+	hk, err := GenerateHostKey("ed25519")
+	assert.NoError(t, err)
+
+	line, err := PublicKeyAuthorizedLine(hk, " deploy@fleet ")
+	assert.NoError(t, err)
+	assert.Equal(t, hk.AuthorizedKey+" deploy@fleet", line)
+
+	pk, comment, _, _, err := gossh.ParseAuthorizedKey([]byte(line))
+	assert.NoError(t, err)
+	assert.Equal(t, "deploy@fleet", comment)
+	assert.Equal(t, hk.Fingerprint, gossh.FingerprintSHA256(pk))
+
+	line, err = PublicKeyAuthorizedLine(hk.Public, "")
+	assert.NoError(t, err)
+	assert.Equal(t, hk.AuthorizedKey, line)
+
+	_, err = PublicKeyAuthorizedLine(nil, "")
+	assert.Error(t, err)
+	_, err = Fingerprint("ssh-ed25519 invalid")
+	assert.Error(t, err)
+}
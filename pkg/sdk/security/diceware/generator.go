@@ -19,6 +19,7 @@ package diceware
 
 import (
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/sethvargo/go-diceware/diceware"
@@ -39,6 +40,21 @@ const (
 	MasterWordCount = 24
 )
 
+// wordListSize is the EFF large wordlist size used by the generator.
+const wordListSize = 7776
+
+// Entropy returns the entropy in bits of a passphrase with the given word count.
+func Entropy(count int) float64 {
+	if count < MinWordCount {
+		count = MinWordCount
+	}
+	if count > MaxWordCount {
+		count = MaxWordCount
+	}
+
+	return float64(count) * math.Log2(wordListSize)
+}
+
 // Diceware generates a passphrase using english words
 func Diceware(count int) (string, error) {
 	// Check parameters
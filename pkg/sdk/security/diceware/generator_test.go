@@ -18,6 +18,7 @@
 package diceware
 
 import (
+	"math"
 	"strings"
 	"testing"
 
@@ -148,3 +149,15 @@ func TestDiceware_Fuzz(t *testing.T) {
 		Diceware(wordCount)
 	}
 }
+
+func TestEntropy(t *testing.T) {
+	if got, want := Entropy(6), 6*math.Log2(7776); math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected %f bits, got %f", want, got)
+	}
+	if Entropy(1) != Entropy(MinWordCount) {
+		t.Error("word count lower bound must be applied")
+	}
+	if Entropy(100) != Entropy(MaxWordCount) {
+		t.Error("word count upper bound must be applied")
+	}
+}
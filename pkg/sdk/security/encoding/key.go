@@ -0,0 +1,156 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+)
+
+// ErrInvalidKeyEncoding is raised when the key can't be decoded. The error
+// never contains the key content.
+var ErrInvalidKeyEncoding = errors.New("encoding: invalid key encoding")
+
+// DecodeKeyHex decodes the given hex encoded key of expectedLen bytes. The
+// character decoding runs in constant time and the output buffer is wiped
+// when an error occurs.
+func DecodeKeyHex(s string, expectedLen int) ([]byte, error) {
+	// Check arguments
+	if expectedLen <= 0 {
+		return nil, fmt.Errorf("encoding: invalid expected key length %d", expectedLen)
+	}
+	if len(s) != 2*expectedLen {
+		return nil, fmt.Errorf("%w: expected %d hex characters, got %d", ErrInvalidKeyEncoding, 2*expectedLen, len(s))
+	}
+
+	// Decode all characters, errors are accumulated
+	out := make([]byte, expectedLen)
+	invalid := 0
+	for i := 0; i < expectedLen; i++ {
+		hi, hiInvalid := hexNibble(s[2*i])
+		lo, loInvalid := hexNibble(s[2*i+1])
+		out[i] = byte(hi<<4 | lo)
+		invalid |= hiInvalid | loInvalid
+	}
+	if invalid != 0 {
+		secret.Wipe(out)
+		return nil, fmt.Errorf("%w: invalid hex character", ErrInvalidKeyEncoding)
+	}
+
+	// No error
+	return out, nil
+}
+
+// DecodeKeyBase64URL decodes the given base64url encoded key of expectedLen
+// bytes, padding is optional. The character decoding runs in constant time
+// and the output buffer is wiped when an error occurs.
+func DecodeKeyBase64URL(s string, expectedLen int) ([]byte, error) {
+	// Check arguments
+	if expectedLen <= 0 {
+		return nil, fmt.Errorf("encoding: invalid expected key length %d", expectedLen)
+	}
+
+	// Padding is not secret
+	if len(s) == base64.URLEncoding.EncodedLen(expectedLen) {
+		s = strings.TrimRight(s, "=")
+	}
+	if len(s) != base64.RawURLEncoding.EncodedLen(expectedLen) {
+		return nil, fmt.Errorf("%w: expected %d base64url characters, got %d", ErrInvalidKeyEncoding, base64.RawURLEncoding.EncodedLen(expectedLen), len(s))
+	}
+
+	// Decode all characters, errors are accumulated
+	out := make([]byte, expectedLen)
+	invalid, o := 0, 0
+	for i := 0; i < len(s); i += 4 {
+		// Decode sextets of the current group
+		var acc, n int
+		for j := i; j < i+4 && j < len(s); j++ {
+			v := base64URLSextet(s[j])
+			invalid |= v >> 8
+			acc = acc<<6 | (v & 0x3f)
+			n++
+		}
+
+		// Emit bytes according to group length
+		switch n {
+		case 4:
+			out[o], out[o+1], out[o+2] = byte(acc>>16), byte(acc>>8), byte(acc)
+			o += 3
+		case 3:
+			invalid |= acc & 0x3
+			out[o], out[o+1] = byte(acc>>10), byte(acc>>2)
+			o += 2
+		case 2:
+			invalid |= acc & 0xf
+			out[o] = byte(acc >> 4)
+			o++
+		}
+	}
+	if invalid != 0 {
+		secret.Wipe(out)
+		return nil, fmt.Errorf("%w: invalid base64url content", ErrInvalidKeyEncoding)
+	}
+
+	// No error
+	return out, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// hexNibble decodes an hex character without data dependent branches. The
+// invalid flag is non-zero when the character is not an hex digit.
+func hexNibble(c byte) (value, invalid int) {
+	ch := int(c)
+
+	// '0'-'9'
+	num := ch ^ 0x30
+	numMask := ((num - 10) >> 8) & 0xff
+
+	// 'a'-'f' and 'A'-'F'
+	alpha := (ch & ^0x20) - 55
+	alphaMask := (((alpha - 10) ^ (alpha - 16)) >> 8) & 0xff
+
+	value = (num & numMask) | (alpha & alphaMask)
+	invalid = ((numMask | alphaMask) - 1) >> 8 & 1
+
+	return value & 0xf, invalid
+}
+
+// base64URLSextet decodes a base64url character without data dependent
+// branches. The result has bit 8 set when the character is invalid.
+func base64URLSextet(c byte) int {
+	src := int(c)
+	ret := -1
+
+	// 'A'-'Z' => 0-25
+	ret += (((0x40 - src) & (src - 0x5b)) >> 8) & (src - 64)
+	// 'a'-'z' => 26-51
+	ret += (((0x60 - src) & (src - 0x7b)) >> 8) & (src - 70)
+	// '0'-'9' => 52-61
+	ret += (((0x2f - src) & (src - 0x3a)) >> 8) & (src + 5)
+	// '-' => 62
+	ret += (((0x2c - src) & (src - 0x2e)) >> 8) & 63
+	// '_' => 63
+	ret += (((0x5e - src) & (src - 0x60)) >> 8) & 64
+
+	return ret & 0x1ff
+}
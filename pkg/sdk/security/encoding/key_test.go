@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeKeyHex(t *testing.T) {
+	for size := 1; size <= 64; size++ {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		assert.NoError(t, err)
+
+		out, err := DecodeKeyHex(hex.EncodeToString(key), size)
+		assert.NoError(t, err)
+		assert.Equal(t, key, out)
+
+		out, err = DecodeKeyHex(strings.ToUpper(hex.EncodeToString(key)), size)
+		assert.NoError(t, err)
+		assert.Equal(t, key, out)
+	}
+}
+
+func TestDecodeKeyHex_Invalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		size  int
+	}{
+		{name: "blank", input: "", size: 4},
+		{name: "too short", input: "0011", size: 4},
+		{name: "too long", input: "0011223344", size: 4},
+		{name: "invalid character", input: "001122g3", size: 4},
+		{name: "separator", input: "00:11:22", size: 4},
+		{name: "invalid size", input: "", size: 0},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := DecodeKeyHex(testCase.input, testCase.size)
+			assert.Error(t, err)
+			assert.Nil(t, out)
+		})
+	}
+
+	// Every non hex character is rejected
+	for c := 0; c < 256; c++ {
+		if strings.ContainsRune("0123456789abcdefABCDEF", rune(c)) {
+			continue
+		}
+		_, err := DecodeKeyHex(string([]byte{'0', byte(c)}), 1)
+		assert.True(t, errors.Is(err, ErrInvalidKeyEncoding), "character %q", c)
+	}
+}
+
+func TestDecodeKeyBase64URL(t *testing.T) {
+	for size := 1; size <= 64; size++ {
+		key := make([]byte, size)
+		_, err := rand.Read(key)
+		assert.NoError(t, err)
+
+		out, err := DecodeKeyBase64URL(base64.RawURLEncoding.EncodeToString(key), size)
+		assert.NoError(t, err)
+		assert.Equal(t, key, out)
+
+		// Padding is accepted
+		out, err = DecodeKeyBase64URL(base64.URLEncoding.EncodeToString(key), size)
+		assert.NoError(t, err)
+		assert.Equal(t, key, out)
+	}
+}
+
+func TestDecodeKeyBase64URL_Invalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		size  int
+	}{
+		{name: "blank", input: "", size: 4},
+		{name: "too short", input: "AAE", size: 4},
+		{name: "standard alphabet", input: "ab+/", size: 3},
+		{name: "non canonical trailing bits", input: "AAB", size: 2},
+		{name: "non canonical trailing bits 2", input: "AB", size: 1},
+		{name: "misplaced padding", input: "AA=A", size: 3},
+		{name: "invalid size", input: "AAAA", size: -1},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := DecodeKeyBase64URL(testCase.input, testCase.size)
+			assert.Error(t, err)
+			assert.Nil(t, out)
+		})
+	}
+
+	// Alphabet is strictly checked
+	alphabet := "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	for c := 0; c < 256; c++ {
+		_, err := DecodeKeyBase64URL(string([]byte{'A', 'A', 'A', byte(c)}), 3)
+		assert.Equal(t, strings.ContainsRune(alphabet, rune(c)), err == nil, "character %q", c)
+	}
+}
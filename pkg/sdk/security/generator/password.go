@@ -0,0 +1,128 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package generator
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/elastic/harp/pkg/sdk/security/diceware"
+	"github.com/elastic/harp/pkg/sdk/security/password"
+)
+
+const (
+	// ProfileStrong generates 32 characters passwords with digits and symbols.
+	ProfileStrong = "strong"
+	// ProfileParanoid generates 64 characters passwords with digits and symbols.
+	ProfileParanoid = "paranoid"
+	// ProfilePIN generates 6 digits numeric codes.
+	ProfilePIN = "pin"
+	// ProfileMemorableDiceware generates 6 words diceware passphrases using the
+	// EFF large wordlist.
+	ProfileMemorableDiceware = "memorable-diceware"
+)
+
+// Secret is a generated credential.
+type Secret struct {
+	// Value is the generated credential.
+	Value string
+	// Entropy is the estimated entropy in bits of the generation profile.
+	Entropy float64
+}
+
+type profile struct {
+	minEntropy float64
+	entropy    func() float64
+	generate   func() (string, error)
+}
+
+var (
+	pinProfile = &password.Profile{Length: 6, NumDigits: 6, NumSymbol: 0, NoUpper: true, AllowRepeat: true}
+
+	memorableWordCount = 6
+
+	profiles = map[string]profile{
+		ProfileStrong: {
+			minEntropy: 128,
+			entropy:    password.ProfileStrong.Entropy,
+			generate:   password.Strong,
+		},
+		ProfileParanoid: {
+			minEntropy: 256,
+			entropy:    password.ProfileParanoid.Entropy,
+			generate:   password.Paranoid,
+		},
+		ProfilePIN: {
+			minEntropy: 19,
+			entropy: func() float64 {
+				return pinProfile.Entropy()
+			},
+			generate: func() (string, error) {
+				return password.FromProfile(pinProfile)
+			},
+		},
+		ProfileMemorableDiceware: {
+			minEntropy: 64,
+			entropy: func() float64 {
+				return diceware.Entropy(memorableWordCount)
+			},
+			generate: func() (string, error) {
+				return diceware.Diceware(memorableWordCount)
+			},
+		},
+	}
+)
+
+// Profiles returns the sorted list of supported profile names.
+func Profiles() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Password generates a credential using the given named profile. Credentials
+// are generated using `crypto/rand`.
+func Password(name string) (*Secret, error) {
+	// Resolve profile
+	p, ok := profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown password profile '%s'", name)
+	}
+
+	// Check profile strength
+	entropy := p.entropy()
+	if entropy < p.minEntropy {
+		return nil, fmt.Errorf("password profile '%s' entropy %.1f bits is below %.1f bits", name, entropy, p.minEntropy)
+	}
+
+	// Generate credential
+	value, err := p.generate()
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate '%s' password: %w", name, err)
+	}
+
+	// No error
+	return &Secret{
+		Value:   value,
+		Entropy: entropy,
+	}, nil
+}
@@ -0,0 +1,62 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package generator
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassword(t *testing.T) {
+	testCases := []struct {
+		profile    string
+		expect     *regexp.Regexp
+		minEntropy float64
+	}{
+		{profile: ProfileStrong, expect: regexp.MustCompile(`^.{32}$`), minEntropy: 128},
+		{profile: ProfileParanoid, expect: regexp.MustCompile(`^.{64}$`), minEntropy: 256},
+		{profile: ProfilePIN, expect: regexp.MustCompile(`^[0-9]{6}$`), minEntropy: 19},
+		{profile: ProfileMemorableDiceware, expect: regexp.MustCompile(`^[a-z]+(-[a-z]+){5}$`), minEntropy: 77},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.profile, func(t *testing.T) {
+			got, err := Password(testCase.profile)
+			assert.NoError(t, err)
+			assert.Regexp(t, testCase.expect, got.Value)
+			assert.GreaterOrEqual(t, got.Entropy, testCase.minEntropy)
+
+			// Generated values are random
+			other, err := Password(testCase.profile)
+			assert.NoError(t, err)
+			if testCase.profile != ProfilePIN {
+				assert.NotEqual(t, got.Value, other.Value)
+			}
+		})
+	}
+}
+
+func TestPassword_UnknownProfile(t *testing.T) {
+	got, err := Password("weak")
+	assert.Error(t, err)
+	assert.Nil(t, got)
+	assert.Equal(t, "memorable-diceware,paranoid,pin,strong", strings.Join(Profiles(), ","))
+}
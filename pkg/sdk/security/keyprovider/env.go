@@ -0,0 +1,50 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyprovider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+func init() {
+	Register("env", ProviderFunc(envProvider))
+}
+
+var envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func envProvider(_ context.Context, name string) (string, error) {
+	// Check variable name
+	if !envNameRegexp.MatchString(name) {
+		return "", fmt.Errorf("%w: invalid environment variable name '%s'", ErrMalformedReference, name)
+	}
+
+	// Lookup the variable
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable '%s' is not defined", ErrNotSet, name)
+	}
+	if v == "" {
+		return "", fmt.Errorf("%w: environment variable '%s' is blank", ErrNotSet, name)
+	}
+
+	// No error
+	return v, nil
+}
@@ -0,0 +1,51 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyprovider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register("keyring", ProviderFunc(keyringProvider))
+}
+
+// keyringLookup is the OS specific keyring lookup implementation.
+var keyringLookup = lookupKeyring
+
+func keyringProvider(ctx context.Context, path string) (string, error) {
+	// Split service and account
+	parts := strings.SplitN(path, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("%w: keyring reference must be 'keyring://<service>/<account>'", ErrMalformedReference)
+	}
+
+	// Delegate to the OS keyring
+	v, err := keyringLookup(ctx, parts[0], parts[1])
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return "", fmt.Errorf("%w: keyring entry '%s/%s' is blank", ErrNotSet, parts[0], parts[1])
+	}
+
+	// No error
+	return v, nil
+}
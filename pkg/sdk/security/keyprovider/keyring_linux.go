@@ -0,0 +1,45 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+//go:build linux
+// +build linux
+
+package keyprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// lookupKeyring reads the secret from the Secret Service (GNOME Keyring,
+// KWallet) using `secret-tool`.
+func lookupKeyring(ctx context.Context, service, account string) (string, error) {
+	out, err := exec.CommandContext(ctx, "secret-tool", "lookup", "service", service, "account", account).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) == 0 {
+			return "", fmt.Errorf("%w: keyring item '%s/%s' not found", ErrNotSet, service, account)
+		}
+		return "", fmt.Errorf("unable to query the keyring: %w", err)
+	}
+
+	// No error
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
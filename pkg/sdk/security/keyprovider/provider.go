@@ -0,0 +1,187 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrNotSet is raised when the referenced key doesn't exist (unset
+	// environment variable, missing keyring entry).
+	ErrNotSet = errors.New("keyprovider: referenced key is not set")
+	// ErrMalformedReference is raised when the key reference can't be parsed.
+	ErrMalformedReference = errors.New("keyprovider: malformed key reference")
+	// ErrUnsupportedScheme is raised when no provider is registered for the
+	// reference scheme.
+	ErrUnsupportedScheme = errors.New("keyprovider: unsupported reference scheme")
+)
+
+const schemeSeparator = "://"
+
+// Provider describes the key provider contract.
+type Provider interface {
+	// Resolve returns the key value referenced by the given path. ErrNotSet
+	// must be returned when the referenced key doesn't exist.
+	Resolve(ctx context.Context, path string) (string, error)
+}
+
+// ProviderFunc adapts a function to the Provider interface.
+type ProviderFunc func(ctx context.Context, path string) (string, error)
+
+// Resolve implements Provider.
+func (f ProviderFunc) Resolve(ctx context.Context, path string) (string, error) {
+	return f(ctx, path)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// Register a key provider for the given scheme.
+func Register(scheme string, p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+
+	// Check if not already registered
+	if _, ok := providers[scheme]; ok {
+		panic(fmt.Errorf("key provider already registered for '%s' scheme", scheme))
+	}
+
+	// Register the provider
+	providers[scheme] = p
+}
+
+// IsReference returns true if the given value uses a registered reference
+// scheme.
+func IsReference(value string) bool {
+	scheme, _, ok := split(value)
+	if !ok {
+		return false
+	}
+
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+
+	_, ok = providers[scheme]
+	return ok
+}
+
+// Resolve returns the key value referenced by the given reference. Values
+// which are not references are returned as is.
+func Resolve(ctx context.Context, value string) (string, error) {
+	scheme, path, ok := split(value)
+	if !ok {
+		return value, nil
+	}
+
+	// Resolve provider
+	providersMu.RLock()
+	p, ok := providers[scheme]
+	providersMu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrUnsupportedScheme, scheme)
+	}
+
+	// Check path
+	if path == "" {
+		return "", fmt.Errorf("%w: blank path for '%s' scheme", ErrMalformedReference, scheme)
+	}
+
+	// Delegate to provider
+	out, err := p.Resolve(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("unable to resolve '%s' key reference: %w", scheme, err)
+	}
+
+	// No error
+	return out, nil
+}
+
+// Expand replaces all key references found in the given `:` or `,` separated
+// key expression by their values.
+//
+//	aes-gcm:env://HARP_KEY
+//	age:keyring://harp/identity,age1...
+func Expand(ctx context.Context, expr string) (string, error) {
+	// Fast path
+	if !strings.Contains(expr, schemeSeparator) {
+		return expr, nil
+	}
+
+	var (
+		sb  strings.Builder
+		pos int
+	)
+	for pos < len(expr) {
+		// Find the next segment boundary
+		end := pos + segmentEnd(expr[pos:])
+
+		// Resolve the segment
+		segment := expr[pos:end]
+		if IsReference(segment) {
+			v, err := Resolve(ctx, segment)
+			if err != nil {
+				return "", err
+			}
+			segment = v
+		}
+		sb.WriteString(segment)
+
+		// Copy separator
+		if end < len(expr) {
+			sb.WriteByte(expr[end])
+		}
+		pos = end + 1
+	}
+
+	// No error
+	return sb.String(), nil
+}
+
+// -----------------------------------------------------------------------------
+
+// split extracts the scheme and path of the given reference.
+func split(value string) (scheme, path string, ok bool) {
+	idx := strings.Index(value, schemeSeparator)
+	if idx <= 0 {
+		return "", "", false
+	}
+
+	return value[:idx], value[idx+len(schemeSeparator):], true
+}
+
+// segmentEnd returns the index of the next segment separator, references are
+// consumed until the next separator following the scheme.
+func segmentEnd(s string) int {
+	start := 0
+	if idx := strings.Index(s, schemeSeparator); idx >= 0 && !strings.ContainsAny(s[:idx], ":,") {
+		start = idx + len(schemeSeparator)
+	}
+
+	if idx := strings.IndexAny(s[start:], ":,"); idx >= 0 {
+		return start + idx
+	}
+
+	return len(s)
+}
@@ -0,0 +1,132 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package keyprovider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpand(t *testing.T) {
+	t.Setenv("HARP_TEST_KEY", "TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	t.Setenv("HARP_TEST_BLANK", "")
+
+	keyringLookup = func(_ context.Context, service, account string) (string, error) {
+		if service == "harp" && account == "identity" {
+			return "AGE-SECRET-KEY-1", nil
+		}
+		return "", ErrNotSet
+	}
+	t.Cleanup(func() {
+		keyringLookup = lookupKeyring
+	})
+
+	testCases := []struct {
+		name    string
+		expr    string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "literal",
+			expr: "aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+			want: "aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		},
+		{
+			name: "env",
+			expr: "aes-gcm:env://HARP_TEST_KEY",
+			want: "aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		},
+		{
+			name: "bare env",
+			expr: "env://HARP_TEST_KEY",
+			want: "TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		},
+		{
+			name: "nested segment",
+			expr: "paseto:local:env://HARP_TEST_KEY",
+			want: "paseto:local:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		},
+		{
+			name: "keyring",
+			expr: "age:keyring://harp/identity,age1recipient",
+			want: "age:AGE-SECRET-KEY-1,age1recipient",
+		},
+		{
+			name: "unregistered scheme",
+			expr: "jwe:vault://secret",
+			want: "jwe:vault://secret",
+		},
+		{
+			name:    "env not set",
+			expr:    "aes-gcm:env://HARP_TEST_UNDEFINED",
+			wantErr: ErrNotSet,
+		},
+		{
+			name:    "env blank",
+			expr:    "aes-gcm:env://HARP_TEST_BLANK",
+			wantErr: ErrNotSet,
+		},
+		{
+			name:    "env invalid name",
+			expr:    "aes-gcm:env://HARP-KEY",
+			wantErr: ErrMalformedReference,
+		},
+		{
+			name:    "env blank name",
+			expr:    "aes-gcm:env://",
+			wantErr: ErrMalformedReference,
+		},
+		{
+			name:    "keyring missing account",
+			expr:    "aes-gcm:keyring://harp",
+			wantErr: ErrMalformedReference,
+		},
+		{
+			name:    "keyring not found",
+			expr:    "aes-gcm:keyring://harp/unknown",
+			wantErr: ErrNotSet,
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := Expand(context.Background(), testCase.expr)
+			if testCase.wantErr != nil {
+				assert.Error(t, err)
+				assert.True(t, errors.Is(err, testCase.wantErr), err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestResolve_UnsupportedScheme(t *testing.T) {
+	_, err := Resolve(context.Background(), "vault://secret")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUnsupportedScheme))
+
+	v, err := Resolve(context.Background(), "literal")
+	assert.NoError(t, err)
+	assert.Equal(t, "literal", v)
+}
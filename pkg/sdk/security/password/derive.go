@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// DefaultDerivedPasswordLen defines the derived password length used when
+	// not specified.
+	DefaultDerivedPasswordLen = 32
+	// DefaultDerivedPasswordCharset defines the character set used to derive
+	// passwords when not specified.
+	DefaultDerivedPasswordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789~!@#$%^&*()_+`-={}|[]\\:\"<>?,./"
+
+	minDerivationSeedLen  = 32
+	maxDerivedPasswordLen = 256
+	derivationSalt        = "harp password derivation"
+)
+
+// Derive deterministically derives a password from the given seed and labels
+// using HKDF-SHA256. The same seed, labels, length and charset always produce
+// the same password, the seed must be kept secret as anyone knowing it can
+// recompute all derived passwords.
+func Derive(seed []byte, length int, charset string, labels ...string) (string, error) {
+	// Check parameters
+	if len(seed) < minDerivationSeedLen {
+		return "", fmt.Errorf("password derivation seed must be at least %d bytes long", minDerivationSeedLen)
+	}
+	if length <= 0 || length > maxDerivedPasswordLen {
+		return "", fmt.Errorf("derived password length must be between 1 and %d", maxDerivedPasswordLen)
+	}
+	if len(charset) < 2 || len(charset) > 256 {
+		return "", errors.New("derived password charset must contain between 2 and 256 characters")
+	}
+	for i := 0; i < len(charset); i++ {
+		if charset[i] > unicode.MaxASCII {
+			return "", errors.New("derived password charset must only contain ASCII characters")
+		}
+	}
+	if len(labels) == 0 {
+		return "", errors.New("at least one label is required to derive a password")
+	}
+
+	// Prepare derivation info (length prefixed to prevent label collisions)
+	info := encodeInfo(append([]string{fmt.Sprintf("%d", length), charset}, labels...))
+
+	// Use rejection sampling to prevent modulo bias
+	kdf := hkdf.New(sha256.New, seed, []byte(derivationSalt), info)
+	limit := 256 - (256 % len(charset))
+	out := make([]byte, 0, length)
+	var b [1]byte
+	for len(out) < length {
+		if _, err := io.ReadFull(kdf, b[:]); err != nil {
+			return "", fmt.Errorf("unable to derive password: %w", err)
+		}
+		if int(b[0]) >= limit {
+			continue
+		}
+		out = append(out, charset[int(b[0])%len(charset)])
+	}
+
+	// No error
+	return string(out), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func encodeInfo(parts []string) []byte {
+	info := []byte{}
+	for _, p := range parts {
+		var l [8]byte
+		binary.LittleEndian.PutUint64(l[:], uint64(len(p)))
+		info = append(info, l[:]...)
+		info = append(info, p...)
+	}
+	return info
+}
@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDerive(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+
+	// Deterministic
+	p1, err := Derive(seed, DefaultDerivedPasswordLen, DefaultDerivedPasswordCharset, "service", "prod")
+	assert.NoError(t, err)
+	assert.Len(t, p1, DefaultDerivedPasswordLen)
+	p2, err := Derive(seed, DefaultDerivedPasswordLen, DefaultDerivedPasswordCharset, "service", "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, p1, p2)
+
+	// Labels, seed and parameters are bound
+	p3, err := Derive(seed, DefaultDerivedPasswordLen, DefaultDerivedPasswordCharset, "service", "staging")
+	assert.NoError(t, err)
+	assert.NotEqual(t, p1, p3)
+	p4, err := Derive(seed, DefaultDerivedPasswordLen, DefaultDerivedPasswordCharset, "servicep", "rod")
+	assert.NoError(t, err)
+	assert.NotEqual(t, p1, p4)
+	p5, err := Derive([]byte("fedcba9876543210fedcba9876543210"), DefaultDerivedPasswordLen, DefaultDerivedPasswordCharset, "service", "prod")
+	assert.NoError(t, err)
+	assert.NotEqual(t, p1, p5)
+	p6, err := Derive(seed, DefaultDerivedPasswordLen+1, DefaultDerivedPasswordCharset, "service", "prod")
+	assert.NoError(t, err)
+	assert.False(t, strings.HasPrefix(p6, p1))
+
+	// Charset is honored
+	p7, err := Derive(seed, 64, "0123456789", "service", "prod")
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[0-9]{64}$", p7)
+}
+
+func TestDerive_Invalid(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+
+	tests := []struct {
+		name    string
+		seed    []byte
+		length  int
+		charset string
+		labels  []string
+	}{
+		{name: "short seed", seed: []byte("foo"), length: 32, charset: "ab", labels: []string{"foo"}},
+		{name: "zero length", seed: seed, length: 0, charset: "ab", labels: []string{"foo"}},
+		{name: "too long", seed: seed, length: 1024, charset: "ab", labels: []string{"foo"}},
+		{name: "short charset", seed: seed, length: 32, charset: "a", labels: []string{"foo"}},
+		{name: "non ascii charset", seed: seed, length: 32, charset: "aé", labels: []string{"foo"}},
+		{name: "no label", seed: seed, length: 32, charset: "ab"},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Derive(testCase.seed, testCase.length, testCase.charset, testCase.labels...)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -18,6 +18,7 @@
 package password
 
 import (
+	"math"
 	"testing"
 
 	fuzz "github.com/google/gofuzz"
@@ -152,3 +153,29 @@ func TestFromProfile_Fuzz(t *testing.T) {
 		FromProfile(&p)
 	}
 }
+
+func TestProfile_Entropy(t *testing.T) {
+	// Digits only
+	pin := &Profile{Length: 6, NumDigits: 6, NoUpper: true}
+	if got, want := pin.Entropy(), 6*math.Log2(10); math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected %f bits, got %f", want, got)
+	}
+
+	// Letters only
+	letters := &Profile{Length: 10}
+	if got, want := letters.Entropy(), 10*math.Log2(52); math.Abs(got-want) > 1e-9 {
+		t.Errorf("expected %f bits, got %f", want, got)
+	}
+
+	// Class positions increase the entropy
+	if ProfileStrong.Entropy() <= ProfileNoSymbol.Entropy() {
+		t.Error("strong profile must have a higher entropy than no symbol profile")
+	}
+
+	// Invalid profiles
+	for _, p := range []*Profile{nil, {}, {Length: 4, NumDigits: 5}} {
+		if got := p.Entropy(); got != 0 {
+			t.Errorf("expected 0 bits, got %f", got)
+		}
+	}
+}
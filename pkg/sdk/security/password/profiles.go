@@ -17,6 +17,12 @@
 
 package password
 
+import (
+	"math"
+
+	"github.com/sethvargo/go-password/password"
+)
+
 // Profile holds password generation settings
 type Profile struct {
 	// Password total legnth.
@@ -49,3 +55,38 @@ var (
 	// Sample output: +75DRm71GEK?Bb03KGU!3_=7^9[N8`-`
 	ProfileStrong = &Profile{Length: 32, NumDigits: 10, NumSymbol: 10, NoUpper: false, AllowRepeat: true}
 )
+
+// Entropy returns the estimated entropy in bits of passwords generated with the
+// given profile. The estimation assumes character repetition, it is an upper
+// bound for profiles disallowing it.
+func (p *Profile) Entropy() float64 {
+	// Check receiver
+	if p == nil || p.Length <= 0 {
+		return 0
+	}
+
+	// Compute class sizes
+	letters := p.Length - p.NumDigits - p.NumSymbol
+	if letters < 0 {
+		return 0
+	}
+	letterSet := len(password.LowerLetters)
+	if !p.NoUpper {
+		letterSet += len(password.UpperLetters)
+	}
+
+	// Per class character choices
+	bits := float64(letters)*math.Log2(float64(letterSet)) +
+		float64(p.NumDigits)*math.Log2(float64(len(password.Digits))) +
+		float64(p.NumSymbol)*math.Log2(float64(len(password.Symbols)))
+
+	// Class positions (multinomial coefficient)
+	bits += log2Factorial(p.Length) - log2Factorial(letters) - log2Factorial(p.NumDigits) - log2Factorial(p.NumSymbol)
+
+	return bits
+}
+
+func log2Factorial(n int) float64 {
+	lg, _ := math.Lgamma(float64(n) + 1)
+	return lg / math.Ln2
+}
@@ -0,0 +1,101 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secret
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Bytes holds sensitive bytes until they are explicitly destroyed. A finalizer
+// wipes the content when the value is garbage collected without having been
+// destroyed.
+type Bytes struct {
+	mu        sync.RWMutex
+	b         []byte
+	destroyed bool
+}
+
+// NewBytes wraps the given byte slice, the caller must not retain it.
+func NewBytes(b []byte) *Bytes {
+	s := &Bytes{b: b}
+	runtime.SetFinalizer(s, (*Bytes).Destroy)
+	return s
+}
+
+// Copy returns a container holding a copy of the given byte slice and wipes
+// the source.
+func Copy(b []byte) *Bytes {
+	out := append([]byte{}, b...)
+	Wipe(b)
+	return NewBytes(out)
+}
+
+// Bytes returns the protected content. The returned slice is wiped when the
+// container is destroyed, it must not be retained by the caller. It returns
+// nil once destroyed.
+func (s *Bytes) Bytes() []byte {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.destroyed {
+		return nil
+	}
+	return s.b
+}
+
+// Len returns the content length.
+func (s *Bytes) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.b)
+}
+
+// IsDestroyed returns true when the content has been wiped.
+func (s *Bytes) IsDestroyed() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.destroyed
+}
+
+// Destroy wipes the content, it is safe to call it multiple times.
+func (s *Bytes) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.destroyed {
+		return
+	}
+
+	Wipe(s.b)
+	s.b = nil
+	s.destroyed = true
+	runtime.SetFinalizer(s, nil)
+}
+
+// String implements fmt.Stringer to prevent content leaks in logs.
+func (s *Bytes) String() string {
+	return "[REDACTED]"
+}
+
+// GoString implements fmt.GoStringer to prevent content leaks in logs.
+func (s *Bytes) GoString() string {
+	return s.String()
+}
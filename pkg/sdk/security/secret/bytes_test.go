@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secret
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWipe(t *testing.T) {
+	b := []byte("secret")
+	Wipe(b)
+	assert.Equal(t, make([]byte, 6), b)
+
+	assert.NotPanics(t, func() {
+		Wipe(nil)
+	})
+}
+
+func TestBytes(t *testing.T) {
+	raw := []byte("secret")
+	s := NewBytes(raw)
+	assert.Equal(t, []byte("secret"), s.Bytes())
+	assert.Equal(t, 6, s.Len())
+	assert.False(t, s.IsDestroyed())
+
+	// Content is not leaked by formatters
+	assert.Equal(t, "[REDACTED]", fmt.Sprintf("%s %v %#v", s, s, s)[:10])
+	assert.NotContains(t, fmt.Sprintf("%s %v %#v", s, s, s), "secret")
+
+	// Destroy wipes the wrapped slice
+	s.Destroy()
+	assert.True(t, s.IsDestroyed())
+	assert.Nil(t, s.Bytes())
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, make([]byte, 6), raw)
+
+	// Idempotent
+	assert.NotPanics(t, s.Destroy)
+}
+
+func TestCopy(t *testing.T) {
+	src := []byte("secret")
+	s := Copy(src)
+	defer s.Destroy()
+
+	assert.Equal(t, make([]byte, 6), src)
+	assert.Equal(t, []byte("secret"), s.Bytes())
+}
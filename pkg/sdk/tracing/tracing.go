@@ -0,0 +1,82 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/elastic/harp"
+
+type contextKey struct{}
+
+// EndFunc completes an operation span, the given error is recorded and the
+// given attributes are added to the span before ending it.
+type EndFunc func(err error, attrs ...attribute.KeyValue)
+
+// WithTracerProvider returns a context enabling tracing of the operations
+// started with it.
+func WithTracerProvider(ctx context.Context, tp trace.TracerProvider) context.Context {
+	return context.WithValue(ctx, contextKey{}, tp)
+}
+
+// TracerProvider returns the tracer provider attached to the context.
+func TracerProvider(ctx context.Context) (trace.TracerProvider, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	tp, ok := ctx.Value(contextKey{}).(trace.TracerProvider)
+	return tp, ok && tp != nil
+}
+
+// Start an operation span. The context is returned untouched and the end
+// function is a no-op when no tracer provider is attached to the context.
+//
+// Attributes must never contain secret material.
+func Start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, EndFunc) {
+	tp, ok := TracerProvider(ctx)
+	if !ok {
+		return ctx, noopEnd
+	}
+
+	// Start span
+	start := time.Now()
+	ctx, span := tp.Tracer(instrumentationName).Start(ctx, operation,
+		trace.WithAttributes(append([]attribute.KeyValue{attribute.String("harp.operation", operation)}, attrs...)...),
+	)
+
+	return ctx, func(err error, attrs ...attribute.KeyValue) {
+		span.SetAttributes(attrs...)
+		span.SetAttributes(attribute.Int64("harp.duration_ms", time.Since(start).Milliseconds()))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func noopEnd(error, ...attribute.KeyValue) {}
@@ -0,0 +1,77 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart_Disabled(t *testing.T) {
+	ctx := context.Background()
+
+	got, end := Start(ctx, "container.seal", attribute.Int("harp.container.input_size", 12))
+	assert.Equal(t, ctx, got)
+	assert.NotPanics(t, func() {
+		end(errors.New("test"))
+	})
+
+	_, ok := TracerProvider(ctx)
+	assert.False(t, ok)
+}
+
+func TestStart(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx := WithTracerProvider(context.Background(), tp)
+
+	// Successful operation
+	_, end := Start(ctx, "vault.pull", attribute.Int("harp.vault.paths", 2))
+	end(nil, attribute.Int("harp.bundle.packages", 5))
+
+	// Failed operation
+	_, end = Start(ctx, "vault.push")
+	end(errors.New("permission denied"))
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	assert.Equal(t, "vault.pull", spans[0].Name())
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, kv := range spans[0].Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	assert.Equal(t, "vault.pull", attrs["harp.operation"].AsString())
+	assert.Equal(t, int64(2), attrs["harp.vault.paths"].AsInt64())
+	assert.Equal(t, int64(5), attrs["harp.bundle.packages"].AsInt64())
+	assert.Contains(t, attrs, attribute.Key("harp.duration_ms"))
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+
+	assert.Equal(t, "vault.push", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "permission denied", spans[1].Status().Description)
+}
@@ -0,0 +1,175 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+const (
+	// MaxDecompressedSize is the maximum size of a decompressed value.
+	MaxDecompressedSize = 64 * 1024 * 1024
+
+	algorithmGzip   byte = 0x01
+	algorithmBrotli byte = 0x02
+)
+
+// magic identifies compressed values, the algorithm identifier byte follows.
+var magic = []byte{0x00, 'h', 'c', 'z'}
+
+func init() {
+	encryption.Register("compress", Transformer)
+}
+
+// Transformer returns a compression value transformer, `To` compresses the
+// value and `From` decompresses it.
+//
+// The key is expressed as `compress:<algorithm>[:<level>]` with `gzip` (level
+// 1-9) or `brotli` (level 0-11) algorithms. Compressed values are prefixed by
+// a small header identifying the algorithm, values without this header are
+// returned as is by `From`.
+func Transformer(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "compress:")
+
+	// Extract optional level
+	parts := strings.SplitN(key, ":", 2)
+	algorithm := strings.ToLower(parts[0])
+	level, hasLevel := 0, len(parts) == 2
+	if hasLevel {
+		var err error
+		level, err = strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("compress: unable to parse compression level: %w", err)
+		}
+	}
+
+	switch algorithm {
+	case "gzip":
+		if !hasLevel {
+			level = gzip.DefaultCompression
+		}
+		if level != gzip.DefaultCompression && (level < gzip.BestSpeed || level > gzip.BestCompression) {
+			return nil, fmt.Errorf("compress: invalid gzip compression level %d", level)
+		}
+		return &compressionTransformer{algorithm: algorithmGzip, level: level}, nil
+	case "brotli":
+		if !hasLevel {
+			level = brotli.DefaultCompression
+		}
+		if level < brotli.BestSpeed || level > brotli.BestCompression {
+			return nil, fmt.Errorf("compress: invalid brotli compression level %d", level)
+		}
+		return &compressionTransformer{algorithm: algorithmBrotli, level: level}, nil
+	default:
+	}
+
+	// Unsupported algorithm
+	return nil, fmt.Errorf("compress: unsupported compression algorithm '%s'", algorithm)
+}
+
+// -----------------------------------------------------------------------------
+
+type compressionTransformer struct {
+	algorithm byte
+	level     int
+}
+
+func (d *compressionTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	// Prepare header
+	var out bytes.Buffer
+	out.Write(magic)
+	out.WriteByte(d.algorithm)
+
+	// Prepare compressor
+	var (
+		w   io.WriteCloser
+		err error
+	)
+	switch d.algorithm {
+	case algorithmGzip:
+		w, err = gzip.NewWriterLevel(&out, d.level)
+	case algorithmBrotli:
+		w = brotli.NewWriterLevel(&out, d.level)
+	default:
+		err = errors.New("unknown algorithm")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("compress: unable to initialize compressor: %w", err)
+	}
+
+	// Compress the input
+	if _, err := w.Write(input); err != nil {
+		return nil, fmt.Errorf("compress: unable to compress value: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("compress: unable to finalize compression: %w", err)
+	}
+
+	// No error
+	return out.Bytes(), nil
+}
+
+func (d *compressionTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	// Pass through uncompressed values
+	if len(input) <= len(magic) || !bytes.HasPrefix(input, magic) {
+		return input, nil
+	}
+
+	// Prepare decompressor according to header
+	var (
+		r   io.Reader
+		err error
+	)
+	payload := bytes.NewReader(input[len(magic)+1:])
+	switch input[len(magic)] {
+	case algorithmGzip:
+		r, err = gzip.NewReader(payload)
+	case algorithmBrotli:
+		r = brotli.NewReader(payload)
+	default:
+		err = errors.New("unknown algorithm")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("compress: unable to initialize decompressor: %w", err)
+	}
+
+	// Decompress the input
+	out, err := io.ReadAll(io.LimitReader(r, MaxDecompressedSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("compress: unable to decompress value: %w", err)
+	}
+	if len(out) > MaxDecompressedSize {
+		return nil, fmt.Errorf("compress: decompressed value exceeds %d bytes", MaxDecompressedSize)
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package compression
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Transformer_InvalidKey(t *testing.T) {
+	keys := []string{
+		"",
+		"compress:",
+		"compress:lz4",
+		"compress:gzip:foo",
+		"compress:gzip:0",
+		"compress:gzip:10",
+		"compress:brotli:-1",
+		"compress:brotli:12",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(fmt.Sprintf("key `%s`", key), func(t *testing.T) {
+			underTest, err := Transformer(key)
+			assert.Error(t, err)
+			assert.Nil(t, underTest)
+		})
+	}
+}
+
+func Test_Transformer(t *testing.T) {
+	input := bytes.Repeat([]byte(`{"user":"admin","password":"secret"}`), 100)
+
+	keys := []string{
+		"compress:gzip",
+		"compress:gzip:9",
+		"compress:brotli",
+		"compress:brotli:0",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(key, func(t *testing.T) {
+			ctx := context.Background()
+
+			underTest, err := Transformer(key)
+			assert.NoError(t, err)
+
+			compressed, err := underTest.To(ctx, input)
+			assert.NoError(t, err)
+			assert.Less(t, len(compressed), len(input)/5)
+			assert.True(t, bytes.HasPrefix(compressed, magic))
+
+			out, err := underTest.From(ctx, compressed)
+			assert.NoError(t, err)
+			assert.Equal(t, input, out)
+		})
+	}
+}
+
+func Test_Transformer_From(t *testing.T) {
+	ctx := context.Background()
+
+	gz, err := Transformer("compress:gzip")
+	assert.NoError(t, err)
+	br, err := Transformer("compress:brotli")
+	assert.NoError(t, err)
+
+	// Uncompressed values are passed through
+	out, err := gz.From(ctx, []byte("uncompressed"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("uncompressed"), out)
+
+	// Algorithm is selected from the header
+	compressed, err := br.To(ctx, []byte("brotli"))
+	assert.NoError(t, err)
+	out, err = gz.From(ctx, compressed)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("brotli"), out)
+
+	// Unknown algorithm
+	_, err = gz.From(ctx, append(append([]byte{}, magic...), 0xff, 0x00))
+	assert.Error(t, err)
+
+	// Corrupted payload
+	_, err = gz.From(ctx, append(append([]byte{}, magic...), algorithmGzip, 0x00))
+	assert.Error(t, err)
+}
+
+func Test_Transformer_DecompressionBomb(t *testing.T) {
+	ctx := context.Background()
+
+	underTest, err := Transformer("compress:gzip:9")
+	assert.NoError(t, err)
+
+	compressed, err := underTest.To(ctx, make([]byte, MaxDecompressedSize+1))
+	assert.NoError(t, err)
+
+	_, err = underTest.From(ctx, compressed)
+	assert.Error(t, err)
+}
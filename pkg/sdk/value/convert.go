@@ -0,0 +1,63 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package value
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// ErrInvalidSourceInput is raised when the input can't be reverted by the
+// source transformer (wrong key, wrong scheme, or corrupted value).
+var ErrInvalidSourceInput = errors.New("value: input is not valid for the source transformer")
+
+// Convert reverts the input with the source transformer and applies the
+// target transformer to the result. It is used to migrate a value from one
+// encryption scheme to another.
+func Convert(in []byte, from, to Transformer) ([]byte, error) {
+	return ConvertContext(context.Background(), in, from, to)
+}
+
+// ConvertContext is the context aware Convert variant.
+func ConvertContext(ctx context.Context, in []byte, from, to Transformer) ([]byte, error) {
+	// Check arguments
+	if types.IsNil(from) {
+		return nil, errors.New("value: source transformer is nil")
+	}
+	if types.IsNil(to) {
+		return nil, errors.New("value: target transformer is nil")
+	}
+
+	// Revert the source transformation
+	plain, err := from.From(ctx, in)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSourceInput, err)
+	}
+
+	// Apply the target transformation
+	out, err := to.To(ctx, plain)
+	if err != nil {
+		return nil, fmt.Errorf("value: unable to apply the target transformer: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
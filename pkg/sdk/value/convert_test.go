@@ -0,0 +1,74 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package value_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+	"github.com/elastic/harp/pkg/sdk/value/mock"
+
+	// Register encryption transformers
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/paseto"
+)
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+	msg := []byte("msg")
+
+	from, err := encryption.FromKey("aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	assert.NoError(t, err)
+	to, err := encryption.FromKey("paseto:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=")
+	assert.NoError(t, err)
+
+	encrypted, err := from.To(ctx, msg)
+	assert.NoError(t, err)
+
+	// Migrate value to the target scheme
+	converted, err := value.Convert(encrypted, from, to)
+	assert.NoError(t, err)
+
+	decrypted, err := to.From(ctx, converted)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// Input not encrypted with the source transformer
+	_, err = value.Convert(converted, from, to)
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, value.ErrInvalidSourceInput))
+}
+
+func TestConvert_Errors(t *testing.T) {
+	_, err := value.Convert([]byte("msg"), nil, mock.Transformer(nil))
+	assert.Error(t, err)
+
+	_, err = value.Convert([]byte("msg"), mock.Transformer(nil), nil)
+	assert.Error(t, err)
+
+	failure := errors.New("test")
+	_, err = value.Convert([]byte("msg"), mock.Transformer(nil), mock.Transformer(failure))
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, failure))
+	assert.False(t, errors.Is(err, value.ErrInvalidSourceInput))
+}
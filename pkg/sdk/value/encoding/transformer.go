@@ -0,0 +1,93 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+func init() {
+	encryption.Register("encode", Transformer)
+}
+
+// Transformer returns an encoding value transformer, `To` encodes the value
+// and `From` decodes it.
+//
+// Supported encodings are `encode:base64`, `encode:base64url` and `encode:hex`.
+func Transformer(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "encode:")
+
+	switch strings.ToLower(key) {
+	case "base64":
+		return &base64Transformer{encoding: base64.StdEncoding}, nil
+	case "base64url":
+		return &base64Transformer{encoding: base64.URLEncoding}, nil
+	case "hex":
+		return &hexTransformer{}, nil
+	default:
+	}
+
+	// Unsupported encoding
+	return nil, fmt.Errorf("encode: unsupported encoding '%s'", key)
+}
+
+// -----------------------------------------------------------------------------
+
+type base64Transformer struct {
+	encoding *base64.Encoding
+}
+
+func (d *base64Transformer) To(_ context.Context, input []byte) ([]byte, error) {
+	out := make([]byte, d.encoding.EncodedLen(len(input)))
+	d.encoding.Encode(out, input)
+	return out, nil
+}
+
+func (d *base64Transformer) From(_ context.Context, input []byte) ([]byte, error) {
+	out := make([]byte, d.encoding.DecodedLen(len(input)))
+	n, err := d.encoding.Decode(out, input)
+	if err != nil {
+		return nil, fmt.Errorf("encode: unable to decode value: %w", err)
+	}
+	return out[:n], nil
+}
+
+type hexTransformer struct{}
+
+func (d *hexTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	out := make([]byte, hex.EncodedLen(len(input)))
+	hex.Encode(out, input)
+	return out, nil
+}
+
+func (d *hexTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	out := make([]byte, hex.DecodedLen(len(input)))
+	n, err := hex.Decode(out, input)
+	if err != nil {
+		return nil, fmt.Errorf("encode: unable to decode value: %w", err)
+	}
+	return out[:n], nil
+}
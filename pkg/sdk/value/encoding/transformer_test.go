@@ -0,0 +1,73 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encoding
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Transformer_InvalidKey(t *testing.T) {
+	keys := []string{
+		"",
+		"encode:",
+		"encode:base32",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(fmt.Sprintf("key `%s`", key), func(t *testing.T) {
+			underTest, err := Transformer(key)
+			assert.Error(t, err)
+			assert.Nil(t, underTest)
+		})
+	}
+}
+
+func Test_Transformer(t *testing.T) {
+	testCases := []struct {
+		key     string
+		encoded string
+	}{
+		{key: "encode:base64", encoded: "/+8="},
+		{key: "encode:base64url", encoded: "_-8="},
+		{key: "encode:hex", encoded: "ffef"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.key, func(t *testing.T) {
+			ctx := context.Background()
+
+			underTest, err := Transformer(testCase.key)
+			assert.NoError(t, err)
+
+			out, err := underTest.To(ctx, []byte{0xff, 0xef})
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.encoded, string(out))
+
+			in, err := underTest.From(ctx, out)
+			assert.NoError(t, err)
+			assert.Equal(t, []byte{0xff, 0xef}, in)
+
+			_, err = underTest.From(ctx, []byte("%%%"))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -35,6 +35,7 @@ var (
 	aesgcmPrefix     = "aes-gcm"
 	aespmacsivPrefix = "aes-pmac-siv"
 	aessivPrefix     = "aes-siv"
+	aessivdetPrefix  = "aes-siv-det"
 	chachaPrefix     = "chacha"
 	xchachaPrefix    = "xchacha"
 )
@@ -43,6 +44,7 @@ func init() {
 	encryption.Register(aesgcmPrefix, AESGCM)
 	encryption.Register(aespmacsivPrefix, AESPMACSIV)
 	encryption.Register(aessivPrefix, AESSIV)
+	encryption.Register(aessivdetPrefix, DeterministicAESSIV)
 	encryption.Register(chachaPrefix, Chacha20Poly1305)
 	encryption.Register(xchachaPrefix, XChacha20Poly1305)
 }
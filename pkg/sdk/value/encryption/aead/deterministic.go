@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package aead
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	miscreant "github.com/miscreant/miscreant.go"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+)
+
+// DeterministicAESSIV returns a deterministic AES-SIV (RFC 5297) value
+// transformer instance.
+//
+// The key is expressed as `aes-siv-det:<key>[:<associated data>]` with a
+// base64url encoded 64 bytes key and optional base64url encoded associated
+// data.
+//
+// No nonce is used, encrypting the same value with the same key and associated
+// data always produces the same ciphertext. This is intended for searchable or
+// joinable identifiers only: the encryption doesn't provide semantic security,
+// an observer learns which values are equal, use `aes-siv` for other values.
+func DeterministicAESSIV(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "aes-siv-det:")
+
+	// Extract optional associated data
+	var ad []byte
+	parts := strings.SplitN(key, ":", 2)
+	if len(parts) == 2 {
+		var err error
+		ad, err = base64.URLEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("aes: unable to decode associated data: %w", err)
+		}
+	}
+
+	// Decode key
+	k, err := base64.URLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("aes: unable to decode key: %w", err)
+	}
+	if l := len(k); l != 64 {
+		return nil, fmt.Errorf("aes: invalid secret key length (%d)", l)
+	}
+
+	// Initialize SIV cipher
+	ciph, err := miscreant.NewAESCMACSIV(k)
+	if err != nil {
+		return nil, fmt.Errorf("aes: unable to initialize aes-siv: %w", err)
+	}
+
+	// Return transformer
+	return &sivTransformer{
+		cipher: ciph,
+		ad:     ad,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type sivTransformer struct {
+	cipher *miscreant.Cipher
+	ad     []byte
+}
+
+func (t *sivTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	// Check input size
+	if len(input) > 64*1024*1024 {
+		return nil, errors.New("value too large")
+	}
+
+	// Encrypt
+	out, err := t.cipher.Seal(nil, input, t.ad)
+	if err != nil {
+		return nil, fmt.Errorf("aes: unable to encrypt value: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
+
+func (t *sivTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	// Check input size
+	if len(input) < t.cipher.Overhead() {
+		return nil, errors.New("ciphered text too short")
+	}
+
+	// Decrypt
+	out, err := t.cipher.Open(nil, input, t.ad)
+	if err != nil {
+		return nil, errors.New("failed to decrypt given message")
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,130 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package age
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+func init() {
+	encryption.Register("age", Transformer)
+}
+
+// Transformer returns an age encryption transformer.
+//
+// The key is expressed as `age:<key>[,<key>...]` where each key is either an
+// X25519 recipient (`age1...`) or an X25519 identity (`AGE-SECRET-KEY-1...`).
+// Values are encrypted to all recipients and to the recipients of the given
+// identities, so that several operators can decrypt them. Decryption requires
+// at least one identity.
+func Transformer(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "age:")
+
+	var (
+		recipients []age.Recipient
+		identities []age.Identity
+	)
+
+	// Parse keys
+	for _, k := range strings.Split(key, ",") {
+		k = strings.TrimSpace(k)
+		switch {
+		case strings.HasPrefix(k, "age1"):
+			r, err := age.ParseX25519Recipient(k)
+			if err != nil {
+				return nil, fmt.Errorf("age: unable to parse recipient: %w", err)
+			}
+			recipients = append(recipients, r)
+		case strings.HasPrefix(strings.ToUpper(k), "AGE-SECRET-KEY-1"):
+			id, err := age.ParseX25519Identity(k)
+			if err != nil {
+				return nil, fmt.Errorf("age: unable to parse identity: %w", err)
+			}
+			identities = append(identities, id)
+			recipients = append(recipients, id.Recipient())
+		default:
+			return nil, errors.New("age: key must be an X25519 recipient or identity")
+		}
+	}
+
+	// Return decorator constructor
+	return &ageTransformer{
+		recipients: recipients,
+		identities: identities,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type ageTransformer struct {
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+func (d *ageTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	// Prepare encryption
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, d.recipients...)
+	if err != nil {
+		return nil, fmt.Errorf("age: unable to initialize encryption: %w", err)
+	}
+
+	// Encrypt the input
+	if _, err := w.Write(input); err != nil {
+		return nil, fmt.Errorf("age: unable to encrypt value: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("age: unable to finalize encryption: %w", err)
+	}
+
+	// No error
+	return out.Bytes(), nil
+}
+
+func (d *ageTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	// Check identities
+	if len(d.identities) == 0 {
+		return nil, errors.New("age: an identity is required to decrypt the value")
+	}
+
+	// Prepare decryption
+	r, err := age.Decrypt(bytes.NewReader(input), d.identities...)
+	if err != nil {
+		return nil, fmt.Errorf("age: unable to initialize decryption: %w", err)
+	}
+
+	// Decrypt the input
+	out, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("age: unable to decrypt value: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
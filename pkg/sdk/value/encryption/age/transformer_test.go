@@ -0,0 +1,126 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package age
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const (
+	aliceIdentity  = "AGE-SECRET-KEY-1WTKGJ6Y90NKHH2Z0FFPDW2SEAN8QMS3DR5X79FJPMCYCM5DND9JQLCNKPY"
+	aliceRecipient = "age1z7zs4dkxu8zfn9t0z8g8va3ezhke5t8emhltpayfan9txmfmy3uq9vjzux"
+	bobIdentity    = "AGE-SECRET-KEY-175S9E3S9RTF5279A2D3MHRD5JG8EELJU479TRY30H3AMKQ9V9VNQ9CT40G"
+	bobRecipient   = "age17fq0ynavla09mzdjqxt5h3fgfzu2kcdtfg0vay97d2vmzf8sj9uqxvmkel"
+)
+
+func Test_Transformer_InvalidKey(t *testing.T) {
+	keys := []string{
+		"",
+		"age:",
+		"age:foo",
+		"age:age1foo",
+		"age:AGE-SECRET-KEY-1foo",
+		"age:" + aliceRecipient + ",123456",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(fmt.Sprintf("key `%s`", key), func(t *testing.T) {
+			underTest, err := Transformer(key)
+			if err == nil {
+				t.Fatalf("Transformer should raise an error with key `%s`", key)
+			}
+			if underTest != nil {
+				t.Fatalf("Transformer instance should be nil")
+			}
+		})
+	}
+}
+
+func Test_Transformer_MultipleRecipients(t *testing.T) {
+	ctx := context.Background()
+	plainText := []byte("cool-protected-data")
+
+	// Encrypt for alice and bob without any identity
+	encrypter, err := Transformer(fmt.Sprintf("age:%s,%s", aliceRecipient, bobRecipient))
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+	encrypted, err := encrypter.To(ctx, plainText)
+	if err != nil {
+		t.Fatalf("unable to encrypt value: %v", err)
+	}
+
+	// Recipients only can't decrypt
+	if _, err := encrypter.From(ctx, encrypted); err == nil {
+		t.Fatal("decryption without identity should raise an error")
+	}
+
+	// Each identity decrypts the value
+	for _, id := range []string{aliceIdentity, bobIdentity} {
+		underTest, err := Transformer("age:" + id)
+		if err != nil {
+			t.Fatalf("unable to initialize transformer: %v", err)
+		}
+		got, err := underTest.From(ctx, encrypted)
+		if err != nil {
+			t.Fatalf("unable to decrypt value: %v", err)
+		}
+		if diff := cmp.Diff(got, plainText); diff != "" {
+			t.Errorf("Age.From():\n-got/+want\ndiff %s", diff)
+		}
+	}
+}
+
+func Test_Transformer_RoundTrip(t *testing.T) {
+	ctx := context.Background()
+
+	// Identity only key encrypts to its own recipient
+	underTest, err := Transformer("age:" + aliceIdentity)
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+	encrypted, err := underTest.To(ctx, []byte("test"))
+	if err != nil {
+		t.Fatalf("unable to encrypt value: %v", err)
+	}
+	got, err := underTest.From(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("unable to decrypt value: %v", err)
+	}
+	if diff := cmp.Diff(got, []byte("test")); diff != "" {
+		t.Errorf("Age.To():\n-got/+want\ndiff %s", diff)
+	}
+
+	// Other identities can't decrypt the value
+	other, err := Transformer("age:" + bobIdentity)
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+	if _, err := other.From(ctx, encrypted); err == nil {
+		t.Fatal("decryption with a foreign identity should raise an error")
+	}
+
+	// Invalid payload
+	if _, err := underTest.From(ctx, []byte("bad-encryption-payload")); err == nil {
+		t.Fatal("decryption of an invalid payload should raise an error")
+	}
+}
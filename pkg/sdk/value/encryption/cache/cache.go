@@ -0,0 +1,272 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/elastic/harp/pkg/sdk/security/secret"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/sdk/value"
+)
+
+const (
+	// DefaultTTL is the default cache entry lifetime.
+	DefaultTTL = 5 * time.Minute
+	// DefaultMaxEntries is the default cache capacity.
+	DefaultMaxEntries = 4096
+)
+
+const (
+	directionTo   byte = 0x01
+	directionFrom byte = 0x02
+)
+
+// Option is used to configure the cache.
+type Option func(*options)
+
+type options struct {
+	ttl          time.Duration
+	maxEntries   int
+	encryptCache bool
+	now          func() time.Time
+}
+
+// WithTTL sets the cache entry lifetime.
+func WithTTL(ttl time.Duration) Option {
+	return func(opts *options) {
+		opts.ttl = ttl
+	}
+}
+
+// WithMaxEntries sets the maximum number of cached entries, the least recently
+// used entry is evicted when the limit is reached.
+func WithMaxEntries(n int) Option {
+	return func(opts *options) {
+		opts.maxEntries = n
+	}
+}
+
+// WithEncryptCache enables the encryption results memoization. It must only be
+// enabled for deterministic transformers (convergent encryption), caching a
+// probabilistic encryption would return the same ciphertext for the same
+// plaintext.
+func WithEncryptCache(enabled bool) Option {
+	return func(opts *options) {
+		opts.encryptCache = enabled
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// Cache is a bounded LRU cache wrapping a value transformer. Decryption results
+// are memoized by ciphertext, encryption results are memoized only when
+// explicitly enabled.
+//
+// Cached values are held in wipeable containers and are destroyed on eviction,
+// expiration and Purge. Callers always receive a copy.
+type Cache struct {
+	inner value.Transformer
+	opts  options
+
+	mu      sync.Mutex
+	hashKey []byte
+	lru     *list.List
+	entries map[[32]byte]*list.Element
+}
+
+type entry struct {
+	id        [32]byte
+	value     *secret.Bytes
+	expiresAt time.Time
+}
+
+// Wrap returns a caching transformer wrapping the given one.
+func Wrap(t value.Transformer, opts ...Option) (*Cache, error) {
+	// Check arguments
+	if types.IsNil(t) {
+		return nil, errors.New("cache: unable to wrap a nil transformer")
+	}
+
+	// Apply options
+	dopts := options{
+		ttl:        DefaultTTL,
+		maxEntries: DefaultMaxEntries,
+		now:        time.Now,
+	}
+	for _, o := range opts {
+		o(&dopts)
+	}
+	if dopts.ttl <= 0 {
+		return nil, fmt.Errorf("cache: ttl must be positive, got %s", dopts.ttl)
+	}
+	if dopts.maxEntries <= 0 {
+		return nil, fmt.Errorf("cache: max entries must be positive, got %d", dopts.maxEntries)
+	}
+
+	// Entry identifiers are keyed to prevent offline guessing of cached inputs
+	hashKey := make([]byte, 32)
+	if _, err := rand.Read(hashKey); err != nil {
+		return nil, fmt.Errorf("cache: unable to generate identifier key: %w", err)
+	}
+
+	// No error
+	return &Cache{
+		inner:   t,
+		opts:    dopts,
+		hashKey: hashKey,
+		lru:     list.New(),
+		entries: map[[32]byte]*list.Element{},
+	}, nil
+}
+
+// To applies the wrapped transformer, results are memoized only when the
+// encryption cache is enabled.
+func (c *Cache) To(ctx context.Context, input []byte) ([]byte, error) {
+	if !c.opts.encryptCache {
+		return c.inner.To(ctx, input)
+	}
+
+	return c.apply(ctx, directionTo, input, c.inner.To)
+}
+
+// From applies the wrapped transformer and memoizes its result.
+func (c *Cache) From(ctx context.Context, input []byte) ([]byte, error) {
+	return c.apply(ctx, directionFrom, input, c.inner.From)
+}
+
+// Len returns the number of cached entries, including expired ones not yet
+// collected.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// Purge removes and wipes all cached entries.
+func (c *Cache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for e := c.lru.Front(); e != nil; e = c.lru.Front() {
+		c.remove(e)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type transformFunc func(context.Context, []byte) ([]byte, error)
+
+func (c *Cache) apply(ctx context.Context, direction byte, input []byte, fn transformFunc) ([]byte, error) {
+	// Compute entry identifier
+	id, err := c.identifier(direction, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lookup cached value
+	if out, ok := c.get(id); ok {
+		return out, nil
+	}
+
+	// Delegate to wrapped transformer
+	out, err := fn(ctx, input)
+	if err != nil {
+		// Errors are never cached
+		return nil, err
+	}
+
+	// Store a copy
+	c.add(id, out)
+
+	// No error
+	return out, nil
+}
+
+func (c *Cache) identifier(direction byte, input []byte) ([32]byte, error) {
+	var id [32]byte
+
+	h, err := blake2b.New256(c.hashKey)
+	if err != nil {
+		return id, fmt.Errorf("cache: unable to initialize identifier hash: %w", err)
+	}
+	h.Write([]byte{direction})
+	h.Write(input)
+	copy(id[:], h.Sum(nil))
+
+	// No error
+	return id, nil
+}
+
+func (c *Cache) get(id [32]byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	// Check expiration
+	ent, _ := e.Value.(*entry)
+	if !c.opts.now().Before(ent.expiresAt) {
+		c.remove(e)
+		return nil, false
+	}
+
+	// Mark as recently used
+	c.lru.MoveToFront(e)
+
+	return append([]byte{}, ent.value.Bytes()...), true
+}
+
+func (c *Cache) add(id [32]byte, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Replace existing entry
+	if e, ok := c.entries[id]; ok {
+		c.remove(e)
+	}
+
+	// Evict least recently used entries
+	for c.lru.Len() >= c.opts.maxEntries {
+		c.remove(c.lru.Back())
+	}
+
+	c.entries[id] = c.lru.PushFront(&entry{
+		id:        id,
+		value:     secret.NewBytes(append([]byte{}, value...)),
+		expiresAt: c.opts.now().Add(c.opts.ttl),
+	})
+}
+
+func (c *Cache) remove(e *list.Element) {
+	ent, _ := c.lru.Remove(e).(*entry)
+	delete(c.entries, ent.id)
+	ent.value.Destroy()
+}
@@ -0,0 +1,217 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+)
+
+type countingTransformer struct {
+	to, from int
+	err      error
+}
+
+func (c *countingTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	c.to++
+	return append([]byte("enc:"), input...), c.err
+}
+
+func (c *countingTransformer) From(_ context.Context, input []byte) ([]byte, error) {
+	c.from++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return append([]byte("dec:"), input...), nil
+}
+
+// -----------------------------------------------------------------------------
+
+func TestWrap_Invalid(t *testing.T) {
+	_, err := Wrap(nil)
+	assert.Error(t, err)
+
+	_, err = Wrap(&countingTransformer{}, WithTTL(0))
+	assert.Error(t, err)
+
+	_, err = Wrap(&countingTransformer{}, WithMaxEntries(0))
+	assert.Error(t, err)
+}
+
+func TestCache_From(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTransformer{}
+
+	underTest, err := Wrap(inner)
+	assert.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		out, err := underTest.From(ctx, []byte("foo"))
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("dec:foo"), out)
+
+		// Returned values are copies
+		out[0] = 'X'
+	}
+	assert.Equal(t, 1, inner.from)
+	assert.Equal(t, 1, underTest.Len())
+
+	// Encryption is not cached by default
+	_, err = underTest.To(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	_, err = underTest.To(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inner.to)
+}
+
+func TestCache_EncryptCache(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTransformer{}
+
+	underTest, err := Wrap(inner, WithEncryptCache(true))
+	assert.NoError(t, err)
+
+	enc, err := underTest.To(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	dec, err := underTest.From(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	_, err = underTest.To(ctx, []byte("foo"))
+	assert.NoError(t, err)
+
+	// Directions don't collide
+	assert.Equal(t, []byte("enc:foo"), enc)
+	assert.Equal(t, []byte("dec:foo"), dec)
+	assert.Equal(t, 1, inner.to)
+	assert.Equal(t, 1, inner.from)
+}
+
+func TestCache_Errors(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTransformer{err: errors.New("test")}
+
+	underTest, err := Wrap(inner)
+	assert.NoError(t, err)
+
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.Error(t, err)
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.Error(t, err)
+	assert.Equal(t, 2, inner.from)
+	assert.Equal(t, 0, underTest.Len())
+}
+
+func TestCache_Eviction(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTransformer{}
+
+	underTest, err := Wrap(inner, WithMaxEntries(2))
+	assert.NoError(t, err)
+
+	for _, in := range []string{"a", "b", "a", "c"} {
+		_, err := underTest.From(ctx, []byte(in))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, underTest.Len())
+	assert.Equal(t, 3, inner.from)
+
+	// "b" is the least recently used one
+	_, err = underTest.From(ctx, []byte("a"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, inner.from)
+	_, err = underTest.From(ctx, []byte("b"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, inner.from)
+}
+
+func TestCache_Expiration(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTransformer{}
+	now := time.Now()
+
+	underTest, err := Wrap(inner, WithTTL(time.Minute))
+	assert.NoError(t, err)
+	underTest.opts.now = func() time.Time { return now }
+
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inner.from)
+
+	now = now.Add(time.Minute)
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inner.from)
+}
+
+func TestCache_Purge(t *testing.T) {
+	ctx := context.Background()
+
+	underTest, err := Wrap(&countingTransformer{})
+	assert.NoError(t, err)
+
+	_, err = underTest.From(ctx, []byte("foo"))
+	assert.NoError(t, err)
+
+	ent, _ := underTest.lru.Front().Value.(*entry)
+	underTest.Purge()
+	assert.Equal(t, 0, underTest.Len())
+	assert.True(t, ent.value.IsDestroyed())
+}
+
+func TestTransformer(t *testing.T) {
+	keys := []string{
+		"",
+		"cache:",
+		"cache:1m",
+		"cache:1m|",
+		"cache:foo|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"cache:1m:foo|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"cache:1m:10:foo|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"cache:1m:10:encrypt:foo|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"cache:-1m|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"cache:|unknown:foo",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(fmt.Sprintf("key `%s`", key), func(t *testing.T) {
+			underTest, err := Transformer(key)
+			assert.Error(t, err)
+			assert.Nil(t, underTest)
+		})
+	}
+
+	// Valid key
+	ctx := context.Background()
+	underTest, err := Transformer("cache:1m:16|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	assert.NoError(t, err)
+
+	encrypted, err := underTest.To(ctx, []byte("foo"))
+	assert.NoError(t, err)
+	decrypted, err := underTest.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), decrypted)
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+func init() {
+	encryption.Register("cache", Transformer)
+}
+
+// Transformer returns the inner transformer wrapped by a bounded in-memory
+// cache. It is designed to avoid a network round-trip per value for remote
+// transformers such as `vault:transit` or `kms`.
+//
+// The key is expressed as `cache:<ttl>:<max entries>[:encrypt]|<inner key>`
+// where ttl is a Go duration and max entries the cache capacity, both may be
+// empty to use the defaults (`cache:10m:|vault:transit:transit/harp`). The
+// `encrypt` flag also memoizes encryption results and must only be set for
+// deterministic inner transformers.
+//
+// Entries are bound to the transformer instance, the inner key and its
+// derivation context are never shared between caches.
+func Transformer(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "cache:")
+
+	// Split cache parameters and inner transformer key
+	parts := strings.SplitN(key, "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errors.New("cache: inner transformer key is required")
+	}
+	params, inner := parts[0], parts[1]
+
+	// Parse parameters
+	opts, err := parseOptions(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build inner transformer
+	t, err := encryption.FromKey(inner)
+	if err != nil {
+		return nil, fmt.Errorf("cache: unable to initialize inner transformer: %w", err)
+	}
+
+	// Delegate to constructor
+	return Wrap(t, opts...)
+}
+
+// -----------------------------------------------------------------------------
+
+func parseOptions(params string) ([]Option, error) {
+	opts := []Option{}
+	if params == "" {
+		return opts, nil
+	}
+
+	fields := strings.Split(params, ":")
+	if len(fields) > 3 {
+		return nil, fmt.Errorf("cache: invalid parameter count, expected at most 3, got %d", len(fields))
+	}
+
+	// TTL
+	if fields[0] != "" {
+		ttl, err := time.ParseDuration(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("cache: unable to parse ttl: %w", err)
+		}
+		opts = append(opts, WithTTL(ttl))
+	}
+
+	// Max entries
+	if len(fields) > 1 && fields[1] != "" {
+		n, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("cache: unable to parse max entries: %w", err)
+		}
+		opts = append(opts, WithMaxEntries(n))
+	}
+
+	// Encryption cache flag
+	if len(fields) > 2 {
+		if fields[2] != "encrypt" {
+			return nil, fmt.Errorf("cache: unknown flag '%s'", fields[2])
+		}
+		opts = append(opts, WithEncryptCache(true))
+	}
+
+	// No error
+	return opts, nil
+}
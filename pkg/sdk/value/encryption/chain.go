@@ -0,0 +1,125 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package encryption
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+)
+
+const (
+	chainSeparator = "|"
+	encryptPrefix  = "encrypt:"
+	hkdfPrefix     = "hkdf:"
+	cachePrefix    = "cache:"
+)
+
+// fromChain builds a transformer chain from the given `|` separated
+// transformer keys.
+func fromChain(keyValue string) (value.Transformer, error) {
+	var (
+		stages []value.Transformer
+		names  []string
+	)
+
+	// Split stages
+	keys := strings.Split(keyValue, chainSeparator)
+	for i := 0; i < len(keys); i++ {
+		key := strings.TrimSpace(keys[i])
+
+		// Key derivation and caching apply to the next stage
+		for tail := key; isWrapperStage(tail) && i+1 < len(keys); {
+			i++
+			tail = strings.TrimSpace(keys[i])
+			key = fmt.Sprintf("%s%s%s", key, chainSeparator, tail)
+		}
+
+		// Remove the optional stage type
+		key = strings.TrimPrefix(key, encryptPrefix)
+
+		if key == "" {
+			return nil, fmt.Errorf("unable to initialize transformer stage %d: blank stage", len(stages)+1)
+		}
+
+		// Stage name doesn't expose key material
+		name := strings.SplitN(key, ":", 2)[0]
+
+		// Build the stage
+		t, err := fromKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize transformer stage %d (%s): %w", len(stages)+1, name, err)
+		}
+
+		stages = append(stages, t)
+		names = append(names, name)
+	}
+
+	// No error
+	return &chainTransformer{
+		stages: stages,
+		names:  names,
+	}, nil
+}
+
+// isWrapperStage returns true when the stage wraps the next one.
+func isWrapperStage(key string) bool {
+	key = strings.TrimPrefix(key, encryptPrefix)
+	return strings.HasPrefix(key, hkdfPrefix) || strings.HasPrefix(key, cachePrefix)
+}
+
+// -----------------------------------------------------------------------------
+
+type chainTransformer struct {
+	stages []value.Transformer
+	names  []string
+}
+
+func (c *chainTransformer) To(ctx context.Context, input []byte) ([]byte, error) {
+	var err error
+
+	// Apply stages from left to right
+	out := input
+	for i, t := range c.stages {
+		out, err = t.To(ctx, out)
+		if err != nil {
+			return nil, fmt.Errorf("unable to apply transformer stage %d (%s): %w", i+1, c.names[i], err)
+		}
+	}
+
+	// No error
+	return out, nil
+}
+
+func (c *chainTransformer) From(ctx context.Context, input []byte) ([]byte, error) {
+	var err error
+
+	// Revert stages from right to left
+	out := input
+	for i := len(c.stages) - 1; i >= 0; i-- {
+		out, err = c.stages[i].From(ctx, out)
+		if err != nil {
+			return nil, fmt.Errorf("unable to revert transformer stage %d (%s): %w", i+1, c.names[i], err)
+		}
+	}
+
+	// No error
+	return out, nil
+}
@@ -26,3 +26,9 @@ type Service interface {
 	Decrypt(ctx context.Context, encrypted []byte) ([]byte, error)
 	Encrypt(ctx context.Context, cleartext []byte) ([]byte, error)
 }
+
+// DataKeyGenerator is implemented by envelope services able to generate the
+// data encryption key remotely, the key is then never generated locally.
+type DataKeyGenerator interface {
+	GenerateDataKey(ctx context.Context) (cleartext, encrypted []byte, err error)
+}
@@ -23,6 +23,7 @@ import (
 	"encoding/base64"
 	"fmt"
 
+	"github.com/awnumar/memguard"
 	"golang.org/x/crypto/cryptobyte"
 
 	"github.com/elastic/harp/pkg/sdk/value"
@@ -45,17 +46,12 @@ type envelopeTransformer struct {
 }
 
 func (t *envelopeTransformer) To(ctx context.Context, input []byte) ([]byte, error) {
-	// Generate a random 32 byte length key
-	newKey := make([]byte, 32)
-	if _, err := rand.Read(newKey); err != nil {
-		return nil, fmt.Errorf("envelope: unable to generate dek key: %w", err)
-	}
-
-	// Encrypt DEK with envelope service
-	encKey, err := t.envelopeService.Encrypt(ctx, newKey)
+	// Generate data encryption key
+	newKey, encKey, err := t.dataKey(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("envelope: unable to encrypt dek: %w", err)
+		return nil, err
 	}
+	defer memguard.WipeBytes(newKey)
 
 	// Build a transformer using key
 	transformer, err := t.transformerFactoryFunc(base64.URLEncoding.EncodeToString(newKey))
@@ -107,3 +103,33 @@ func (t *envelopeTransformer) From(ctx context.Context, input []byte) ([]byte, e
 	// Delegate to transformer
 	return transformer.From(ctx, payload)
 }
+
+// -----------------------------------------------------------------------------
+
+func (t *envelopeTransformer) dataKey(ctx context.Context) (cleartext, encrypted []byte, err error) {
+	// Delegate to the envelope service when supported
+	if g, ok := t.envelopeService.(DataKeyGenerator); ok {
+		cleartext, encrypted, err = g.GenerateDataKey(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("envelope: unable to generate dek: %w", err)
+		}
+
+		// No error
+		return cleartext, encrypted, nil
+	}
+
+	// Generate a random 32 byte length key
+	cleartext = make([]byte, 32)
+	if _, err := rand.Read(cleartext); err != nil {
+		return nil, nil, fmt.Errorf("envelope: unable to generate dek key: %w", err)
+	}
+
+	// Encrypt DEK with envelope service
+	encrypted, err = t.envelopeService.Encrypt(ctx, cleartext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("envelope: unable to encrypt dek: %w", err)
+	}
+
+	// No error
+	return cleartext, encrypted, nil
+}
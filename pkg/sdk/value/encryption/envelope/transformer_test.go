@@ -18,8 +18,10 @@
 package envelope
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -39,8 +41,52 @@ func (s *testEnvelopeService) Decrypt(_ context.Context, data []byte) ([]byte, e
 	return base64.URLEncoding.DecodeString(string(data))
 }
 
+type testDataKeyService struct {
+	testEnvelopeService
+	generated int
+}
+
+func (s *testDataKeyService) Encrypt(_ context.Context, _ []byte) ([]byte, error) {
+	return nil, errors.New("local key encryption must not be used")
+}
+
+func (s *testDataKeyService) GenerateDataKey(ctx context.Context) (cleartext, encrypted []byte, err error) {
+	s.generated++
+	cleartext = bytes.Repeat([]byte{0x01}, 32)
+	encrypted, err = s.testEnvelopeService.Encrypt(ctx, cleartext)
+	return cleartext, encrypted, err
+}
+
 // -----------------------------------------------------------------------------
 
+func Test_Envelope_DataKeyGenerator(t *testing.T) {
+	ctx := context.Background()
+	envelopeService := &testDataKeyService{}
+
+	underTest, err := Transformer(envelopeService, secretbox.Transformer)
+	if err != nil {
+		t.Fatalf("error during transformer initialization, error = %v", err)
+	}
+
+	// Encrypt with a remotely generated key
+	encrypted, err := underTest.To(ctx, []byte("test"))
+	if err != nil {
+		t.Fatalf("error during the To() call, error = %v", err)
+	}
+	if envelopeService.generated != 1 {
+		t.Fatalf("data key should be generated by the envelope service")
+	}
+
+	// Decrypt
+	got, err := underTest.From(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("error during the From() call, error = %v", err)
+	}
+	if diff := cmp.Diff(got, []byte("test")); diff != "" {
+		t.Errorf("Envelope.From():\n-got/+want\ndiff %s", diff)
+	}
+}
+
 func Test_Envelope_From(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -22,6 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fernet/fernet-go"
 
@@ -33,11 +34,39 @@ func init() {
 	encryption.Register("fernet", Transformer)
 }
 
-// Transformer returns a fernet encryption transformer
+// Transformer returns a fernet encryption transformer.
+//
+// The key is expressed as `fernet:<key>`, or `fernet:<ttl>:<key>` to reject
+// tokens older than the given duration on decryption (`fernet:15m:<key>`).
+// TTL is not enforced when omitted.
 func Transformer(key string) (value.Transformer, error) {
 	// Remove the prefix
 	key = strings.TrimPrefix(key, "fernet:")
 
+	// Extract optional TTL
+	var ttl time.Duration
+	if idx := strings.Index(key, ":"); idx >= 0 {
+		var err error
+		ttl, err = time.ParseDuration(key[:idx])
+		if err != nil {
+			return nil, fmt.Errorf("fernet: unable to parse token TTL: %w", err)
+		}
+		key = key[idx+1:]
+	}
+
+	// Delegate to constructor
+	return TransformerWithTTL(key, ttl)
+}
+
+// TransformerWithTTL returns a fernet encryption transformer which rejects
+// tokens issued more than ttl ago, or too far in the future, on decryption.
+// A zero ttl disables token age verification.
+func TransformerWithTTL(key string, ttl time.Duration) (value.Transformer, error) {
+	// Check arguments
+	if ttl < 0 {
+		return nil, fmt.Errorf("fernet: token TTL must be positive, got %s", ttl)
+	}
+
 	// Check given keys
 	k, err := fernet.DecodeKey(key)
 	if err != nil {
@@ -47,6 +76,7 @@ func Transformer(key string) (value.Transformer, error) {
 	// Return decorator constructor
 	return &fernetTransformer{
 		key: k,
+		ttl: ttl,
 	}, nil
 }
 
@@ -54,6 +84,7 @@ func Transformer(key string) (value.Transformer, error) {
 
 type fernetTransformer struct {
 	key *fernet.Key
+	ttl time.Duration
 }
 
 func (d *fernetTransformer) To(_ context.Context, input []byte) ([]byte, error) {
@@ -68,8 +99,9 @@ func (d *fernetTransformer) To(_ context.Context, input []byte) ([]byte, error)
 }
 
 func (d *fernetTransformer) From(_ context.Context, input []byte) ([]byte, error) {
-	// Encrypt value
-	out := fernet.VerifyAndDecrypt(input, 0, []*fernet.Key{d.key})
+	// Verify and decrypt value, the HMAC and the token age are checked
+	// before decryption
+	out := fernet.VerifyAndDecrypt(input, d.ttl, []*fernet.Key{d.key})
 	if out == nil {
 		return nil, errors.New("fernet: unable to decrypt value")
 	}
@@ -19,8 +19,16 @@ package fernet
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/fernet/fernet-go"
 	"github.com/golang/mock/gomock"
@@ -32,6 +40,9 @@ func Test_Transformer_Fernet_InvalidKey(t *testing.T) {
 		"",
 		"foo",
 		"123456",
+		"fernet:1x:cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4=",
+		"fernet:-1h:cw_0x689RpI-jtRR7oE8h_eQsKImvJapLeSbXpwF4e4=",
+		"fernet:1h:foo",
 	}
 	for _, k := range keys {
 		key := k
@@ -182,3 +193,92 @@ func Test_Transformer_Fernet_To(t *testing.T) {
 		})
 	}
 }
+
+func Test_Transformer_Fernet_TTL(t *testing.T) {
+	// Generate a random fernet key
+	k := &fernet.Key{}
+	if err := k.Generate(); err != nil {
+		t.Fatalf("unable to generate fernet key: %v", err)
+	}
+
+	ctx := context.Background()
+	plainText := []byte("cool-protected-data")
+
+	// Initialize transformers
+	withTTL, err := Transformer(fmt.Sprintf("fernet:1h:%s", k.Encode()))
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+	withoutTTL, err := Transformer(fmt.Sprintf("fernet:%s", k.Encode()))
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+
+	// Fresh tokens are accepted
+	fresh := issueToken(t, k, 0x80, time.Now(), plainText)
+	if _, err := withTTL.From(ctx, fresh); err != nil {
+		t.Errorf("fresh token should be accepted, error = %v", err)
+	}
+
+	// Expired tokens are rejected only when a TTL is set
+	expired := issueToken(t, k, 0x80, time.Now().Add(-2*time.Hour), plainText)
+	if _, err := withTTL.From(ctx, expired); err == nil {
+		t.Error("expired token should be rejected")
+	}
+	got, err := withoutTTL.From(ctx, expired)
+	if err != nil {
+		t.Errorf("expired token should be accepted without TTL, error = %v", err)
+	}
+	if diff := cmp.Diff(got, plainText); diff != "" {
+		t.Errorf("Fernet.From():\n-got/+want\ndiff %s", diff)
+	}
+
+	// Tokens from the future are rejected
+	future := issueToken(t, k, 0x80, time.Now().Add(time.Hour), plainText)
+	if _, err := withTTL.From(ctx, future); err == nil {
+		t.Error("token issued in the future should be rejected")
+	}
+
+	// Unknown versions are rejected
+	badVersion := issueToken(t, k, 0x81, time.Now(), plainText)
+	if _, err := withoutTTL.From(ctx, badVersion); err == nil {
+		t.Error("token with an unknown version should be rejected")
+	}
+}
+
+// issueToken assembles a fernet token with an arbitrary version and timestamp.
+// https://github.com/fernet/spec/blob/master/Spec.md
+func issueToken(t *testing.T, k *fernet.Key, version byte, ts time.Time, msg []byte) []byte {
+	t.Helper()
+
+	// Prepare header
+	// Version || Timestamp || IV
+	tok := make([]byte, 1+8+aes.BlockSize)
+	tok[0] = version
+	binary.BigEndian.PutUint64(tok[1:], uint64(ts.Unix()))
+	if _, err := rand.Read(tok[9:]); err != nil {
+		t.Fatalf("unable to generate IV: %v", err)
+	}
+
+	// PKCS#7 padding
+	padLen := aes.BlockSize - len(msg)%aes.BlockSize
+	padded := append([]byte{}, msg...)
+	for i := 0; i < padLen; i++ {
+		padded = append(padded, byte(padLen))
+	}
+
+	// Encrypt with AES-128-CBC
+	block, err := aes.NewCipher(k[16:])
+	if err != nil {
+		t.Fatalf("unable to initialize block cipher: %v", err)
+	}
+	cipher.NewCBCEncrypter(block, tok[9:]).CryptBlocks(padded, padded)
+	tok = append(tok, padded...)
+
+	// Authenticate with HMAC-SHA256
+	h := hmac.New(sha256.New, k[:16])
+	h.Write(tok)
+	tok = h.Sum(tok)
+
+	return []byte(base64.URLEncoding.EncodeToString(tok))
+}
@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hkdf
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/awnumar/memguard"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/elastic/harp/pkg/sdk/value"
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+)
+
+func init() {
+	encryption.Register("hkdf", Transformer)
+}
+
+// Transformer returns the inner encryption transformer initialized with a
+// subkey derived from the given master key using HKDF-SHA256.
+//
+// The key is expressed as `hkdf:<salt>:<info>|<inner key>` where salt is
+// base64url encoded (may be empty), info is a context label and the inner
+// key is a transformer key holding a base64url encoded master key
+// (`hkdf:c2FsdA==:production|aes-gcm:<master key>`). The master key position
+// is declared by the inner transformer with encryption.RegisterKeySegment, so
+// that trailing parameters are preserved (`aes-siv-det:<master key>:<ad>`).
+// The derived subkey has the same length as the master key.
+//
+// Salt and info are not stored in the produced ciphertext, they become part
+// of the decryption context: the exact same values are required to decrypt,
+// and values encrypted for one info label can't be decrypted with another.
+func Transformer(key string) (value.Transformer, error) {
+	// Remove the prefix
+	key = strings.TrimPrefix(key, "hkdf:")
+
+	// Split derivation parameters and inner transformer key
+	parts := strings.SplitN(key, "|", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errors.New("hkdf: inner transformer key is required")
+	}
+	params, inner := parts[0], parts[1]
+
+	// Extract salt and info
+	var salt []byte
+	saltInfo := strings.SplitN(params, ":", 2)
+	if saltInfo[0] != "" {
+		var err error
+		salt, err = base64.URLEncoding.DecodeString(saltInfo[0])
+		if err != nil {
+			return nil, fmt.Errorf("hkdf: unable to decode salt: %w", err)
+		}
+	}
+	var info string
+	if len(saltInfo) == 2 {
+		info = saltInfo[1]
+	}
+
+	// Extract master key from inner key
+	head, encodedKey, tail, err := encryption.SplitKey(inner)
+	if err != nil {
+		return nil, fmt.Errorf("hkdf: unable to extract master key: %w", err)
+	}
+	masterKey, err := base64.URLEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("hkdf: unable to decode master key: %w", err)
+	}
+	defer memguard.WipeBytes(masterKey)
+	if len(masterKey) == 0 {
+		return nil, errors.New("hkdf: master key must not be empty")
+	}
+
+	// Derive subkey
+	subKey := make([]byte, len(masterKey))
+	defer memguard.WipeBytes(subKey)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, masterKey, salt, []byte(info)), subKey); err != nil {
+		return nil, fmt.Errorf("hkdf: unable to derive subkey: %w", err)
+	}
+
+	// Build inner transformer with the derived subkey
+	t, err := encryption.FromKey(fmt.Sprintf("%s%s%s", head, base64.URLEncoding.EncodeToString(subKey), tail))
+	if err != nil {
+		return nil, fmt.Errorf("hkdf: unable to initialize inner transformer: %w", err)
+	}
+
+	// No error
+	return t, nil
+}
@@ -0,0 +1,169 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package hkdf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/elastic/harp/pkg/sdk/value/encryption"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/paseto"
+)
+
+const masterKey = "TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8="
+
+func Test_Transformer_InvalidKey(t *testing.T) {
+	keys := []string{
+		"",
+		"hkdf:",
+		"hkdf:c2FsdA==:production",
+		"hkdf:c2FsdA==:production|",
+		"hkdf:c2FsdA==:production|aes-gcm",
+		"hkdf:c2FsdA==:production|aes-gcm:",
+		"hkdf:c2FsdA==:production|aes-gcm:foo",
+		"hkdf:%%%:production|aes-gcm:" + masterKey,
+		"hkdf:c2FsdA==:production|unknown:" + masterKey,
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(fmt.Sprintf("key `%s`", key), func(t *testing.T) {
+			underTest, err := Transformer(key)
+			if err == nil {
+				t.Fatalf("Transformer should raise an error with key `%s`", key)
+			}
+			if underTest != nil {
+				t.Fatalf("Transformer instance should be nil")
+			}
+		})
+	}
+}
+
+func Test_Transformer_Derivation(t *testing.T) {
+	ctx := context.Background()
+
+	// Derive the expected subkey
+	mk, err := base64.URLEncoding.DecodeString(masterKey)
+	assert.NoError(t, err)
+	subKey := make([]byte, len(mk))
+	_, err = io.ReadFull(hkdf.New(sha256.New, mk, []byte("salt"), []byte("production")), subKey)
+	assert.NoError(t, err)
+
+	// Encrypt with derived key
+	underTest, err := encryption.FromKey("hkdf:c2FsdA==:production|aes-gcm:" + masterKey)
+	assert.NoError(t, err)
+	encrypted, err := underTest.To(ctx, []byte("cleartext"))
+	assert.NoError(t, err)
+
+	// Subkey decrypts the value
+	direct, err := encryption.FromKey("aes-gcm:" + base64.URLEncoding.EncodeToString(subKey))
+	assert.NoError(t, err)
+	out, err := direct.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cleartext"), out)
+
+	// Master key doesn't decrypt the value
+	master, err := encryption.FromKey("aes-gcm:" + masterKey)
+	assert.NoError(t, err)
+	_, err = master.From(ctx, encrypted)
+	assert.Error(t, err)
+
+	// Another context label doesn't decrypt the value
+	other, err := encryption.FromKey("hkdf:c2FsdA==:staging|aes-gcm:" + masterKey)
+	assert.NoError(t, err)
+	_, err = other.From(ctx, encrypted)
+	assert.Error(t, err)
+
+	// Empty salt is accepted
+	noSalt, err := encryption.FromKey("hkdf::production|aes-gcm:" + masterKey)
+	assert.NoError(t, err)
+	_, err = noSalt.From(ctx, encrypted)
+	assert.Error(t, err)
+}
+
+func Test_Transformer_KeyParameters(t *testing.T) {
+	ctx := context.Background()
+
+	derive := func(t *testing.T, master string) string {
+		t.Helper()
+
+		mk, err := base64.URLEncoding.DecodeString(master)
+		assert.NoError(t, err)
+		subKey := make([]byte, len(mk))
+		_, err = io.ReadFull(hkdf.New(sha256.New, mk, nil, []byte("production")), subKey)
+		assert.NoError(t, err)
+
+		return base64.URLEncoding.EncodeToString(subKey)
+	}
+
+	t.Run("trailing associated data", func(t *testing.T) {
+		sivKey := "Brfled4G7okhpCb6T2HMWKgDo1vyqrEdWWVIXfcFUysHaOacXkER5z9GHRuz89scK2TSE962nAFUcScAkihP9w=="
+		ad := base64.URLEncoding.EncodeToString([]byte("users.email"))
+
+		underTest, err := encryption.FromKey("hkdf::production|aes-siv-det:" + sivKey + ":" + ad)
+		assert.NoError(t, err)
+		encrypted, err := underTest.To(ctx, []byte("cleartext"))
+		assert.NoError(t, err)
+
+		// Deterministic encryption with the subkey and the associated data
+		direct, err := encryption.FromKey("aes-siv-det:" + derive(t, sivKey) + ":" + ad)
+		assert.NoError(t, err)
+		expected, err := direct.To(ctx, []byte("cleartext"))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, encrypted)
+
+		// Associated data is still authenticated
+		other, err := encryption.FromKey("hkdf::production|aes-siv-det:" + sivKey + ":" + base64.URLEncoding.EncodeToString([]byte("users.name")))
+		assert.NoError(t, err)
+		_, err = other.From(ctx, encrypted)
+		assert.Error(t, err)
+	})
+
+	t.Run("stage type", func(t *testing.T) {
+		underTest, err := encryption.FromKey("hkdf::production|encrypt:aes-gcm:" + masterKey)
+		assert.NoError(t, err)
+		encrypted, err := underTest.To(ctx, []byte("cleartext"))
+		assert.NoError(t, err)
+
+		direct, err := encryption.FromKey("aes-gcm:" + derive(t, masterKey))
+		assert.NoError(t, err)
+		out, err := direct.From(ctx, encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("cleartext"), out)
+	})
+
+	t.Run("leading parameters", func(t *testing.T) {
+		underTest, err := encryption.FromKey("hkdf::production|paseto:local:" + masterKey)
+		assert.NoError(t, err)
+		encrypted, err := underTest.To(ctx, []byte("cleartext"))
+		assert.NoError(t, err)
+
+		direct, err := encryption.FromKey("paseto:local:" + derive(t, masterKey))
+		assert.NoError(t, err)
+		out, err := direct.From(ctx, encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("cleartext"), out)
+	})
+}
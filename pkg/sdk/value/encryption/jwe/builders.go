@@ -18,6 +18,7 @@
 package jwe
 
 import (
+	"crypto/ecdsa"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -39,10 +40,13 @@ var (
 	PBES2_HS256_A128KW KeyAlgorithm = "pbes2-hs256-a128kw"
 	PBES2_HS384_A192KW KeyAlgorithm = "pbes2-hs384-a192kw"
 	PBES2_HS512_A256KW KeyAlgorithm = "pbes2-hs512-a256kw"
+	DIRECT             KeyAlgorithm = "dir"
+	ECDH_ES            KeyAlgorithm = "ecdh-es"
 )
 
 func init() {
 	encryption.Register("jwe", FromKey)
+	encryption.RegisterKeySegment("jwe", 1)
 }
 
 // FromKey returns an encryption transformer instance according to the given key format.
@@ -63,6 +67,10 @@ func FromKey(key string) (value.Transformer, error) {
 		return Transformer(PBES2_HS384_A192KW, strings.TrimPrefix(key, "pbes2-hs384-a192kw:"))
 	case strings.HasPrefix(key, "pbes2-hs512-a256kw:"):
 		return Transformer(PBES2_HS512_A256KW, strings.TrimPrefix(key, "pbes2-hs512-a256kw:"))
+	case strings.HasPrefix(key, "dir:"):
+		return Transformer(DIRECT, strings.TrimPrefix(key, "dir:"))
+	case strings.HasPrefix(key, "ecdh-es:"):
+		return Transformer(ECDH_ES, strings.TrimPrefix(key, "ecdh-es:"))
 	default:
 	}
 
@@ -114,9 +122,52 @@ func Transformer(algorithm KeyAlgorithm, key string) (value.Transformer, error)
 		return transformer(key, jose.PBES2_HS384_A192KW, jose.A192GCM)
 	case PBES2_HS512_A256KW:
 		return transformer(key, jose.PBES2_HS512_A256KW, jose.A256GCM)
+	case DIRECT:
+		// Try to decode the key
+		k, err := base64.URLEncoding.DecodeString(key)
+		if err != nil {
+			return nil, fmt.Errorf("jwe: unable to decode key: %w", err)
+		}
+		if len(k) != 32 {
+			return nil, errors.New("jwe: direct encryption key must be 32 bytes long")
+		}
+		return transformer(k, jose.DIRECT, jose.A256GCM)
+	case ECDH_ES:
+		return ecdhTransformer(key)
 	default:
 	}
 
 	// Unsupported encryption scheme.
 	return nil, fmt.Errorf("unsupported jwe algorithm '%s'", algorithm)
 }
+
+// -----------------------------------------------------------------------------
+
+// ecdhTransformer builds an ECDH-ES transformer from a base64url encoded EC
+// JWK. A public key only allows encryption, a private key allows encryption
+// and decryption.
+func ecdhTransformer(key string) (value.Transformer, error) {
+	// Try to decode the key
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+	if err != nil {
+		return nil, fmt.Errorf("jwe: unable to decode key: %w", err)
+	}
+
+	// Parse JWK
+	var jwk jose.JSONWebKey
+	if err := jwk.UnmarshalJSON(raw); err != nil {
+		return nil, fmt.Errorf("jwe: unable to parse JWK: %w", err)
+	}
+
+	// Check key type
+	switch k := jwk.Key.(type) {
+	case *ecdsa.PublicKey:
+		return asymmetricTransformer(k, nil, jose.ECDH_ES, jose.A256GCM)
+	case *ecdsa.PrivateKey:
+		return asymmetricTransformer(&k.PublicKey, k, jose.ECDH_ES, jose.A256GCM)
+	default:
+	}
+
+	// Unsupported key type
+	return nil, errors.New("jwe: ECDH-ES requires an EC key")
+}
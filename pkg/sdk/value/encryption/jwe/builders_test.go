@@ -19,10 +19,17 @@ package jwe
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
 )
 
 func Test_Transformer_JWE_InvalidKey(t *testing.T) {
@@ -37,6 +44,9 @@ func Test_Transformer_JWE_InvalidKey(t *testing.T) {
 		"a192kw:",
 		"a256kw:",
 		"a512kw:TkxS6qSV6DBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+		"dir:abSOB6OHnFK1CHIm60OXsA==",
+		"ecdh-es:",
+		"ecdh-es:e30",
 	}
 	for _, k := range keys {
 		key := k
@@ -60,6 +70,7 @@ func Test_FromKey(t *testing.T) {
 		"pbes2-hs256-a128kw:stalemate-parkway-hardened-jeep-shrink-dimmer-platter-pretense",
 		"pbes2-hs384-a192kw:stalemate-parkway-hardened-jeep-shrink-dimmer-platter-pretense",
 		"pbes2-hs512-a256kw:stalemate-parkway-hardened-jeep-shrink-dimmer-platter-pretense",
+		"dir:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
 	}
 	for _, k := range keys {
 		key := k
@@ -92,3 +103,46 @@ func Test_Transformer_InvalidAlgorithm(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, tr)
 }
+
+func Test_FromKey_ECDH_ES(t *testing.T) {
+	ctx := context.Background()
+
+	// Generate recipient key
+	pk, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	encodeJWK := func(key interface{}) string {
+		raw, err := jose.JSONWebKey{Key: key}.MarshalJSON()
+		assert.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	// Encrypt with the public key only
+	encrypter, err := FromKey(fmt.Sprintf("jwe:ecdh-es:%s", encodeJWK(&pk.PublicKey)))
+	assert.NoError(t, err)
+	encrypted, err := encrypter.To(ctx, []byte("cleartext"))
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(string(encrypted), "."), 5)
+
+	// Public key can't decrypt
+	_, err = encrypter.From(ctx, encrypted)
+	assert.Error(t, err)
+
+	// Decrypt with the private key
+	decrypter, err := FromKey(fmt.Sprintf("jwe:ecdh-es:%s", encodeJWK(pk)))
+	assert.NoError(t, err)
+	out, err := decrypter.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cleartext"), out)
+
+	// Check protected header
+	jwe, err := jose.ParseEncrypted(string(encrypted))
+	assert.NoError(t, err)
+	assert.Equal(t, string(jose.ECDH_ES), jwe.Header.Algorithm)
+
+	// Non EC keys are rejected
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = FromKey(fmt.Sprintf("jwe:ecdh-es:%s", encodeJWK(edKey)))
+	assert.Error(t, err)
+}
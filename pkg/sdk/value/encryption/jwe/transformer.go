@@ -48,19 +48,43 @@ func transformer(key interface{}, keyAlgorithm jose.KeyAlgorithm, contentEncrypt
 	}, nil
 }
 
+// asymmetricTransformer returns a JWE encryption transformer encrypting for the
+// given recipient key. The decryption key is optional, the transformer is
+// encryption only when it's nil.
+func asymmetricTransformer(recipientKey, decryptionKey interface{}, keyAlgorithm jose.KeyAlgorithm, contentEncryption jose.ContentEncryption) (value.Transformer, error) {
+	if types.IsNil(recipientKey) {
+		return nil, fmt.Errorf("jwe: recipient key must not be nil")
+	}
+
+	// Return decorator constructor
+	return &jweTransformer{
+		key:               decryptionKey,
+		recipientKey:      recipientKey,
+		keyAlgorithm:      keyAlgorithm,
+		contentEncryption: contentEncryption,
+	}, nil
+}
+
 // -----------------------------------------------------------------------------
 
 type jweTransformer struct {
 	key               interface{}
+	recipientKey      interface{}
 	keyAlgorithm      jose.KeyAlgorithm
 	contentEncryption jose.ContentEncryption
 }
 
 func (d *jweTransformer) To(_ context.Context, input []byte) ([]byte, error) {
+	// Select encryption key
+	key := d.key
+	if !types.IsNil(d.recipientKey) {
+		key = d.recipientKey
+	}
+
 	// Prepare JOSE recipient
 	recipient := jose.Recipient{
 		Algorithm:  d.keyAlgorithm,
-		Key:        d.key,
+		Key:        key,
 		PBES2Count: PBKDF2Iterations,
 	}
 
@@ -96,6 +120,11 @@ func (d *jweTransformer) From(_ context.Context, input []byte) ([]byte, error) {
 		return nil, fmt.Errorf("unable to parse JWE token")
 	}
 
+	// Check decryption key
+	if types.IsNil(d.key) {
+		return nil, fmt.Errorf("jwe: decryption key is required to decrypt JWE token")
+	}
+
 	// Try to decrypt with given passphrase
 	payload, errDecrypt := jwe.Decrypt(d.key)
 	if errDecrypt != nil {
@@ -31,12 +31,26 @@ import (
 
 func init() {
 	encryption.Register("paseto", Transformer)
+	encryption.RegisterKeySegment("paseto", 1)
 }
 
+// Transformer returns a PASETO v4.local encryption transformer.
+//
+// The key is expressed as `paseto:local:<base64url key>`, the legacy
+// `paseto:<base64url key>` form is still accepted for existing keys.
 func Transformer(key string) (value.Transformer, error) {
 	// Remove the prefix
 	key = strings.TrimPrefix(key, "paseto:")
 
+	// Check key purpose
+	if idx := strings.Index(key, ":"); idx >= 0 {
+		purpose := key[:idx]
+		if purpose != "local" {
+			return nil, fmt.Errorf("paseto: unsupported key purpose '%s', only 'local' is supported", purpose)
+		}
+		key = key[idx+1:]
+	}
+
 	// Decode key
 	k, err := base64.URLEncoding.DecodeString(key)
 	if err != nil {
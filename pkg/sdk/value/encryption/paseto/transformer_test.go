@@ -20,6 +20,7 @@ package paseto
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -31,6 +32,8 @@ func Test_Transformer_InvalidKey(t *testing.T) {
 		"",
 		"foo",
 		"123456",
+		"paseto:public:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=",
+		"paseto:local:123456",
 	}
 	for _, k := range keys {
 		key := k
@@ -103,6 +106,25 @@ func Test_Transformer_From(t *testing.T) {
 	}
 }
 
+func Test_Transformer_From_LocalKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+
+	// Initialize transformer
+	underTest, err := Transformer("paseto:local:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=")
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+
+	// Do the call
+	got, err := underTest.From(ctx, []byte("v4.local.tMe_MuiltiVR4NlnbtCiXP7w3v2rkE3iLpOJG4Gyfxc3UTHIbHzKIrRu0e8Mb_Q93kXTm99GU5AjquJalAG8qTp7fxs"))
+	if err != nil {
+		t.Fatalf("unable to decrypt value: %v", err)
+	}
+	if diff := cmp.Diff(got, []byte("test")); diff != "" {
+		t.Errorf("Paseto.From():\n-got/+want\ndiff %s", diff)
+	}
+}
+
 func Test_Transformer_To(t *testing.T) {
 	// Prepare testcases
 	testCases := []struct {
@@ -157,3 +179,32 @@ func Test_Transformer_To(t *testing.T) {
 		})
 	}
 }
+
+func Test_Transformer_LegacyKey(t *testing.T) {
+	ctx := context.Background()
+
+	legacy, err := Transformer("paseto:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=")
+	if err != nil {
+		t.Fatalf("unable to initialize legacy transformer: %v", err)
+	}
+	underTest, err := Transformer("paseto:local:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=")
+	if err != nil {
+		t.Fatalf("unable to initialize transformer: %v", err)
+	}
+
+	// Legacy and prefixed keys must be interoperable
+	encrypted, err := legacy.To(ctx, []byte("test"))
+	if err != nil {
+		t.Fatalf("unable to encrypt with legacy key: %v", err)
+	}
+	if !strings.HasPrefix(string(encrypted), "v4.local.") {
+		t.Fatalf("encrypted value must be a v4.local token, got `%s`", encrypted)
+	}
+	got, err := underTest.From(ctx, encrypted)
+	if err != nil {
+		t.Fatalf("unable to decrypt with prefixed key: %v", err)
+	}
+	if diff := cmp.Diff(got, []byte("test")); diff != "" {
+		t.Errorf("Paseto.From():\n-got/+want\ndiff %s", diff)
+	}
+}
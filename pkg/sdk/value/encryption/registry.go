@@ -19,6 +19,7 @@ package encryption
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/elastic/harp/pkg/sdk/value"
 )
@@ -43,3 +44,44 @@ func Register(prefix string, factory TransformerFactoryFunc) {
 	// Register the transformer
 	registry[prefix] = factory
 }
+
+var keySegments map[string]int
+
+// RegisterKeySegment declares the position of the key material in the `:`
+// separated parameters of transformer keys using the given prefix. The key
+// material is expected to be the first parameter by default.
+func RegisterKeySegment(prefix string, index int) {
+	// Lazy initialization
+	if keySegments == nil {
+		keySegments = map[string]int{}
+	}
+
+	// Register the key position
+	keySegments[prefix] = index
+}
+
+// SplitKey splits the given transformer key around its key material, so that
+// `head + key + tail` returns the original transformer key.
+//
+//	aes-siv-det:<key>:<ad> => "aes-siv-det:", "<key>", ":<ad>"
+func SplitKey(keyValue string) (head, key, tail string, err error) {
+	// Extract prefix
+	parts := strings.Split(strings.TrimPrefix(keyValue, encryptPrefix), ":")
+	if len(parts) < 2 {
+		return "", "", "", fmt.Errorf("transformer key must contain key material")
+	}
+	prefix := strings.ToLower(strings.TrimSpace(parts[0]))
+
+	// Resolve key position
+	idx := keySegments[prefix] + 1
+	if idx >= len(parts) {
+		return "", "", "", fmt.Errorf("'%s' transformer key must contain key material at position %d", prefix, idx-1)
+	}
+
+	// No error
+	head = strings.Join(parts[:idx], ":") + ":"
+	if idx+1 < len(parts) {
+		tail = ":" + strings.Join(parts[idx+1:], ":")
+	}
+	return head, parts[idx], tail, nil
+}
@@ -18,24 +18,70 @@
 package encryption
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
 	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/sdk/value"
 )
 
 // FromKey returns the value transformer that match the value format.
+//
+// Transformers can be chained using `|` as separator, `To` applies the stages
+// from left to right and `From` in the reverse order.
+//
+//	compress:gzip|encrypt:aes-gcm:<key>|encode:base64
+//
+// Key material can be referenced instead of being inlined, references are
+// resolved by the keyprovider package.
+//
+//	aes-gcm:env://HARP_KEY
+//	paseto:local:keyring://harp/paseto
 func FromKey(keyValue string) (value.Transformer, error) {
+	// Check arguments
+	if keyValue == "" {
+		return nil, fmt.Errorf("unable to select a value transformer with blank value")
+	}
+
+	// Check transformer chain
+	if strings.Contains(keyValue, chainSeparator) {
+		return fromChain(keyValue)
+	}
+
+	// Delegate to single transformer builder
+	return fromKey(keyValue)
+}
+
+// Must is used to panic when a transformer initialization failed.
+func Must(t value.Transformer, err error) value.Transformer {
+	if err != nil {
+		panic(err)
+	}
+	if types.IsNil(t) {
+		panic(errors.New("transformer is nil with a nil error"))
+	}
+
+	return t
+}
+
+// -----------------------------------------------------------------------------
+
+func fromKey(keyValue string) (value.Transformer, error) {
 	var (
 		transformer value.Transformer
 		err         error
 	)
 
-	// Check arguments
-	if keyValue == "" {
-		return nil, fmt.Errorf("unable to select a value transformer with blank value")
+	// Remove the optional stage type
+	keyValue = strings.TrimPrefix(keyValue, encryptPrefix)
+
+	// Resolve key references
+	keyValue, err = keyprovider.Expand(context.Background(), keyValue)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve key reference: %w", err)
 	}
 
 	// Extract prefix
@@ -65,15 +111,3 @@ func FromKey(keyValue string) (value.Transformer, error) {
 	// No error
 	return transformer, nil
 }
-
-// Must is used to panic when a transformer initialization failed.
-func Must(t value.Transformer, err error) value.Transformer {
-	if err != nil {
-		panic(err)
-	}
-	if types.IsNil(t) {
-		panic(errors.New("transformer is nil with a nil error"))
-	}
-
-	return t
-}
@@ -19,18 +19,25 @@ package encryption_test
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/elastic/harp/pkg/sdk/security/keyprovider"
 	"github.com/elastic/harp/pkg/sdk/value"
 	"github.com/elastic/harp/pkg/sdk/value/encryption"
 
 	// Register encryption transformers
+	_ "github.com/elastic/harp/pkg/sdk/value/compression"
+	_ "github.com/elastic/harp/pkg/sdk/value/encoding"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/aead"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/age"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/cache"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/fernet"
+	_ "github.com/elastic/harp/pkg/sdk/value/encryption/hkdf"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/jwe"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/paseto"
 	_ "github.com/elastic/harp/pkg/sdk/value/encryption/secretbox"
@@ -138,6 +145,27 @@ func TestFromKey(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "age",
+			args: args{
+				keyValue: "age:AGE-SECRET-KEY-1WTKGJ6Y90NKHH2Z0FFPDW2SEAN8QMS3DR5X79FJPMCYCM5DND9JQLCNKPY,age17fq0ynavla09mzdjqxt5h3fgfzu2kcdtfg0vay97d2vmzf8sj9uqxvmkel",
+			},
+			wantErr: false,
+		},
+		{
+			name: "compressed chain",
+			args: args{
+				keyValue: "compress:gzip|encrypt:aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=|encode:base64",
+			},
+			wantErr: false,
+		},
+		{
+			name: "paseto local",
+			args: args{
+				keyValue: "paseto:local:kP1yHnBcOhjowNFXSCyycSuXdUqTlbuE6ES5tTp-I_o=",
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -179,6 +207,138 @@ func TestFromKey(t *testing.T) {
 	}
 }
 
+func TestFromKey_Chain(t *testing.T) {
+	ctx := context.Background()
+	msg := []byte("msg")
+
+	// Encrypt then encode
+	underTest, err := encryption.FromKey("encrypt:aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=|encode:base64")
+	assert.NoError(t, err)
+	encrypted, err := underTest.To(ctx, msg)
+	assert.NoError(t, err)
+
+	// Last stage is applied last
+	raw, err := base64.StdEncoding.DecodeString(string(encrypted))
+	assert.NoError(t, err)
+	single, err := encryption.FromKey("aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	assert.NoError(t, err)
+	decrypted, err := single.From(ctx, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// From reverses the chain
+	decrypted, err = underTest.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// Stage syntax is accepted without chain
+	stage, err := encryption.FromKey("encrypt:aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	assert.NoError(t, err)
+	decrypted, err = stage.From(ctx, raw)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// Failing stage is reported
+	_, err = underTest.From(ctx, []byte("bm90LWVuY3J5cHRlZA=="))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "stage 1 (aes-gcm)")
+
+	// Key derivation applies to the next stage
+	derived, err := encryption.FromKey("hkdf::production|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=|encode:hex")
+	assert.NoError(t, err)
+	encrypted, err = derived.To(ctx, msg)
+	assert.NoError(t, err)
+	decrypted, err = derived.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+
+	// Caching applies to the next stage, including a key derivation
+	cached, err := encryption.FromKey("cache:1m:|hkdf::production|aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=|encode:hex")
+	assert.NoError(t, err)
+	decrypted, err = cached.From(ctx, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, msg, decrypted)
+}
+
+func TestFromKey_InvalidChain(t *testing.T) {
+	keys := []string{
+		"aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=|",
+		"|encode:base64",
+		"encode:base64|encode:base32",
+		"encode:base64|unknown:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=",
+	}
+	for _, k := range keys {
+		key := k
+		t.Run(key, func(t *testing.T) {
+			underTest, err := encryption.FromKey(key)
+			assert.Error(t, err)
+			assert.Nil(t, underTest)
+			assert.NotContains(t, err.Error(), "TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+		})
+	}
+}
+
+func TestFromKey_DeterministicAESSIV(t *testing.T) {
+	ctx := context.Background()
+	key := "aes-siv-det:Brfled4G7okhpCb6T2HMWKgDo1vyqrEdWWVIXfcFUysHaOacXkER5z9GHRuz89scK2TSE962nAFUcScAkihP9w=="
+
+	underTest, err := encryption.FromKey(key)
+	assert.NoError(t, err)
+
+	// Same plaintext produces the same ciphertext
+	c1, err := underTest.To(ctx, []byte("user@example.com"))
+	assert.NoError(t, err)
+	c2, err := underTest.To(ctx, []byte("user@example.com"))
+	assert.NoError(t, err)
+	assert.Equal(t, c1, c2)
+
+	out, err := underTest.From(ctx, c1)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("user@example.com"), out)
+
+	// Associated data is authenticated
+	withAD, err := encryption.FromKey(key + ":dXNlcnM=")
+	assert.NoError(t, err)
+	c3, err := withAD.To(ctx, []byte("user@example.com"))
+	assert.NoError(t, err)
+	assert.NotEqual(t, c1, c3)
+	_, err = withAD.From(ctx, c1)
+	assert.Error(t, err)
+
+	// Invalid keys
+	for _, k := range []string{"aes-siv-det:", "aes-siv-det:Brfled4G7okhpCb6T2HMWKgDo1vyqrEdWWVIXfcFUys=", key + ":%%%"} {
+		_, err := encryption.FromKey(k)
+		assert.Error(t, err)
+	}
+}
+
+func TestFromKey_KeyReference(t *testing.T) {
+	t.Setenv("HARP_TEST_AES_KEY", "TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	t.Setenv("HARP_TEST_INVALID_KEY", "not-a-key")
+
+	underTest, err := encryption.FromKey("aes-gcm:env://HARP_TEST_AES_KEY")
+	assert.NoError(t, err)
+
+	literal, err := encryption.FromKey("aes-gcm:TkxS6qSV6eDBjn29JmU2ieMPnuCZNn3JelI1CDNqAQ8=")
+	assert.NoError(t, err)
+	encrypted, err := underTest.To(context.Background(), []byte("msg"))
+	assert.NoError(t, err)
+	decrypted, err := literal.From(context.Background(), encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("msg"), decrypted)
+
+	// Variable not set
+	_, err = encryption.FromKey("aes-gcm:env://HARP_TEST_UNDEFINED_KEY")
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, keyprovider.ErrNotSet))
+
+	// Malformed key
+	_, err = encryption.FromKey("aes-gcm:env://HARP_TEST_INVALID_KEY")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, keyprovider.ErrNotSet))
+	assert.Contains(t, err.Error(), "unable to initialize value transformer")
+}
+
 func TestMust(t *testing.T) {
 	assert.Panics(t, func() {
 		encryption.Must(mock.Transformer(nil), errors.New("test"))
@@ -188,3 +348,33 @@ func TestMust(t *testing.T) {
 		encryption.Must(nil, nil)
 	})
 }
+
+func TestSplitKey(t *testing.T) {
+	testCases := []struct {
+		key                         string
+		wantHead, wantKey, wantTail string
+		wantErr                     bool
+	}{
+		{key: "aes-gcm:key", wantHead: "aes-gcm:", wantKey: "key"},
+		{key: "aes-siv-det:key:ad", wantHead: "aes-siv-det:", wantKey: "key", wantTail: ":ad"},
+		{key: "paseto:local:key", wantHead: "paseto:local:", wantKey: "key"},
+		{key: "jwe:a256kw:key", wantHead: "jwe:a256kw:", wantKey: "key"},
+		{key: "encrypt:aes-gcm:key", wantHead: "aes-gcm:", wantKey: "key"},
+		{key: "aes-gcm", wantErr: true},
+		{key: "jwe:a256kw", wantErr: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.key, func(t *testing.T) {
+			head, key, tail, err := encryption.SplitKey(testCase.key)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.wantHead, head)
+			assert.Equal(t, testCase.wantKey, key)
+			assert.Equal(t, testCase.wantTail, tail)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/log"
+)
+
+// AuditEvent describes a secret access attempt. It never contains the secret
+// value.
+type AuditEvent struct {
+	// Time is the request reception timestamp.
+	Time time.Time
+	// Client is the mTLS client certificate common name.
+	Client string
+	// Method is the full gRPC method name.
+	Method string
+	// Namespace, Path and Key identify the requested secret.
+	Namespace string
+	Path      string
+	Key       string
+	// Allowed is true when the secret has been returned to the client.
+	Allowed bool
+	// Reason describes why the request has been denied.
+	Reason string
+}
+
+// AuditSink receives secret access audit events.
+type AuditSink interface {
+	Write(ctx context.Context, evt *AuditEvent) error
+}
+
+// AuditSinkFunc is an AuditSink implemented by a function.
+type AuditSinkFunc func(ctx context.Context, evt *AuditEvent) error
+
+// Write calls f(ctx, evt).
+func (f AuditSinkFunc) Write(ctx context.Context, evt *AuditEvent) error {
+	return f(ctx, evt)
+}
+
+// LogAuditSink returns an audit sink writing events as structured logs.
+func LogAuditSink() AuditSink {
+	return AuditSinkFunc(func(ctx context.Context, evt *AuditEvent) error {
+		log.For(ctx).Info("secret access",
+			zap.Time("time", evt.Time),
+			zap.String("client", evt.Client),
+			zap.String("method", evt.Method),
+			zap.String("namespace", evt.Namespace),
+			zap.String("path", evt.Path),
+			zap.String("key", evt.Key),
+			zap.Bool("allowed", evt.Allowed),
+			zap.String("reason", evt.Reason),
+		)
+		return nil
+	})
+}
@@ -0,0 +1,152 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// InterceptorOption is used to configure the access control interceptor.
+type InterceptorOption func(*interceptorOptions)
+
+type interceptorOptions struct {
+	rateLimit float64
+	rateBurst int
+	auditSink AuditSink
+	now       func() time.Time
+}
+
+// WithRateLimit enables per-client rate limiting, each client identified by its
+// certificate common name is allowed r requests per second with the given
+// burst size.
+func WithRateLimit(r float64, burst int) InterceptorOption {
+	return func(opts *interceptorOptions) {
+		opts.rateLimit = r
+		opts.rateBurst = burst
+	}
+}
+
+// WithAuditSink sets the sink receiving GetSecret audit events.
+func WithAuditSink(sink AuditSink) InterceptorOption {
+	return func(opts *interceptorOptions) {
+		opts.auditSink = sink
+	}
+}
+
+// UnaryInterceptor returns a gRPC interceptor enforcing rate limits and
+// emitting audit events for every GetSecret call.
+func UnaryInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
+	// Default options
+	dopts := &interceptorOptions{
+		now: time.Now,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	// Prepare rate limiter
+	var l *limiter
+	if dopts.rateLimit > 0 {
+		burst := dopts.rateBurst
+		if burst < 1 {
+			burst = 1
+		}
+		l = newLimiter(dopts.rateLimit, burst)
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Identify client
+		client := clientIdentity(ctx)
+
+		// Prepare audit event
+		var evt *AuditEvent
+		if r, ok := req.(*bundlev1.GetSecretRequest); ok && !types.IsNil(dopts.auditSink) {
+			evt = &AuditEvent{
+				Time:      dopts.now().UTC(),
+				Client:    client,
+				Method:    info.FullMethod,
+				Namespace: r.Namespace,
+				Path:      r.Path,
+				Key:       r.Key,
+			}
+		}
+
+		// Enforce rate limit
+		var (
+			res interface{}
+			err error
+		)
+		if l != nil && !l.Allow(client) {
+			err = status.Error(codes.ResourceExhausted, "rate limit exceeded")
+		} else {
+			res, err = handler(ctx, req)
+		}
+
+		// Write audit event
+		if evt != nil {
+			if r, ok := res.(*bundlev1.GetSecretResponse); ok && r != nil {
+				// Record the served package name
+				evt.Path = r.Path
+			}
+			evt.Allowed = err == nil
+			if err != nil {
+				evt.Reason = status.Code(err).String()
+			}
+			if errAudit := dopts.auditSink.Write(ctx, evt); errAudit != nil {
+				log.For(ctx).Error("unable to write audit event", zap.Error(errAudit))
+				// Don't return secrets that have not been audited.
+				return nil, status.Error(codes.Unavailable, "unable to audit request")
+			}
+		}
+
+		return res, err
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func clientIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+
+	// Use the verified leaf certificate
+	if len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+		return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	}
+
+	return ""
+}
@@ -0,0 +1,115 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+)
+
+func clientContext(cn string) context.Context {
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				VerifiedChains: [][]*x509.Certificate{
+					{{Subject: pkix.Name{CommonName: cn}}},
+				},
+			},
+		},
+	})
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	srv := testServer(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/harp.bundle.v1.BundleAPI/GetSecret"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.GetSecret(ctx, req.(*bundlev1.GetSecretRequest))
+	}
+
+	events := []*AuditEvent{}
+	underTest := UnaryInterceptor(
+		WithRateLimit(0.001, 2),
+		WithAuditSink(AuditSinkFunc(func(_ context.Context, evt *AuditEvent) error {
+			events = append(events, evt)
+			return nil
+		})),
+	)
+
+	req := &bundlev1.GetSecretRequest{Path: "app/production/db", Key: "password"}
+
+	// Within burst
+	res, err := underTest(clientContext("alice"), req, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("foo"), res.(*bundlev1.GetSecretResponse).Content)
+	_, err = underTest(clientContext("alice"), &bundlev1.GetSecretRequest{Path: "app/staging/db"}, info, handler)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// Burst exhausted
+	_, err = underTest(clientContext("alice"), req, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Buckets are per client
+	_, err = underTest(clientContext("bob"), req, info, handler)
+	assert.NoError(t, err)
+
+	// Audit trail
+	if len(events) != 4 {
+		t.Fatalf("expected 4 audit events, got %d", len(events))
+	}
+	assert.Equal(t, "alice", events[0].Client)
+	assert.Equal(t, "app/production/db", events[0].Path)
+	assert.Equal(t, "password", events[0].Key)
+	assert.Equal(t, info.FullMethod, events[0].Method)
+	assert.True(t, events[0].Allowed)
+	assert.False(t, events[0].Time.IsZero())
+	assert.False(t, events[1].Allowed)
+	assert.Equal(t, "NotFound", events[1].Reason)
+	assert.False(t, events[2].Allowed)
+	assert.Equal(t, "ResourceExhausted", events[2].Reason)
+	assert.Equal(t, "bob", events[3].Client)
+	assert.True(t, events[3].Allowed)
+}
+
+func TestUnaryInterceptor_AuditFailure(t *testing.T) {
+	srv := testServer(t)
+	info := &grpc.UnaryServerInfo{FullMethod: "/harp.bundle.v1.BundleAPI/GetSecret"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.GetSecret(ctx, req.(*bundlev1.GetSecretRequest))
+	}
+
+	underTest := UnaryInterceptor(WithAuditSink(AuditSinkFunc(func(_ context.Context, _ *AuditEvent) error {
+		return errors.New("sink unavailable")
+	})))
+
+	res, err := underTest(clientContext("alice"), &bundlev1.GetSecretRequest{Path: "app/production/db", Key: "password"}, info, handler)
+	assert.Nil(t, res)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
@@ -0,0 +1,54 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"sync"
+
+	"golang.org/x/time/rate"
+)
+
+// limiter maintains a token bucket per client identity.
+type limiter struct {
+	sync.Mutex
+
+	limit   rate.Limit
+	burst   int
+	buckets map[string]*rate.Limiter
+}
+
+func newLimiter(r float64, burst int) *limiter {
+	return &limiter{
+		limit:   rate.Limit(r),
+		burst:   burst,
+		buckets: map[string]*rate.Limiter{},
+	}
+}
+
+// Allow consumes a token from the client bucket.
+func (l *limiter) Allow(client string) bool {
+	l.Lock()
+	b, ok := l.buckets[client]
+	if !ok {
+		b = rate.NewLimiter(l.limit, l.burst)
+		l.buckets[client] = b
+	}
+	l.Unlock()
+
+	return b.Allow()
+}
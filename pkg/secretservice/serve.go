@@ -0,0 +1,90 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/sdk/tlsconfig"
+	"github.com/elastic/harp/pkg/sdk/types"
+)
+
+// TLSConfig returns a mutual TLS server configuration, clients must present a
+// certificate signed by the given client CA.
+func TLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	// Check arguments
+	if clientCAFile == "" {
+		return nil, errors.New("client CA is required for mutual TLS")
+	}
+
+	// Delegate to tlsconfig
+	return tlsconfig.Server(&tlsconfig.Options{
+		CertFile:           certFile,
+		KeyFile:            keyFile,
+		CAFile:             clientCAFile,
+		ExclusiveRootPools: true,
+		ClientAuth:         tls.RequireAndVerifyClientCert,
+		MinVersion:         tls.VersionTLS12,
+	})
+}
+
+// Serve starts a gRPC server exposing the given BundleAPI implementation on the
+// given listener until the context is cancelled.
+func Serve(ctx context.Context, lis net.Listener, tlsConfig *tls.Config, srv bundlev1.BundleAPIServer, opts ...grpc.ServerOption) error {
+	// Check arguments
+	if types.IsNil(lis) {
+		return errors.New("unable to serve with a nil listener")
+	}
+	if tlsConfig == nil {
+		return errors.New("unable to serve without TLS configuration")
+	}
+	if types.IsNil(srv) {
+		return errors.New("unable to serve a nil server implementation")
+	}
+
+	// Prepare gRPC server
+	gs := grpc.NewServer(append([]grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, opts...)...)
+	bundlev1.RegisterBundleAPIServer(gs, srv)
+
+	// Stop on context cancellation
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			gs.GracefulStop()
+		case <-done:
+		}
+	}()
+
+	// Start serving
+	if err := gs.Serve(lis); err != nil {
+		return fmt.Errorf("unable to serve gRPC requests: %w", err)
+	}
+
+	// No error
+	return nil
+}
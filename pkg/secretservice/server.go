@@ -0,0 +1,171 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/bundle/secret"
+)
+
+// New returns a BundleAPI server implementation serving secrets from the given
+// bundle. Secret values are only kept in memory.
+func New(b *bundlev1.Bundle) (bundlev1.BundleAPIServer, error) {
+	// Check arguments
+	if b == nil {
+		return nil, errors.New("unable to serve a nil bundle")
+	}
+
+	// Index packages
+	names := make([]string, 0, len(b.Packages))
+	packages := make(map[string]*bundlev1.Package, len(b.Packages))
+	for _, p := range b.Packages {
+		if p == nil {
+			continue
+		}
+		if _, ok := packages[p.Name]; ok {
+			continue
+		}
+		names = append(names, p.Name)
+		packages[p.Name] = p
+	}
+	sort.Strings(names)
+
+	return &server{
+		bundle:   b,
+		names:    names,
+		packages: packages,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type server struct {
+	bundlev1.UnimplementedBundleAPIServer
+
+	bundle   *bundlev1.Bundle
+	names    []string
+	packages map[string]*bundlev1.Package
+}
+
+func (s *server) GetSecret(ctx context.Context, req *bundlev1.GetSecretRequest) (*bundlev1.GetSecretResponse, error) {
+	// Check arguments
+	if req == nil || req.Path == "" {
+		return nil, status.Error(codes.InvalidArgument, "secret path is required")
+	}
+
+	// Lookup package
+	found, ok := s.packages[req.Path]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "secret path '%s' not found", req.Path)
+	}
+
+	// Prepare response
+	res := &bundlev1.GetSecretResponse{
+		Namespace: req.Namespace,
+		Path:      found.Name,
+		Key:       req.Key,
+	}
+
+	// Complete package
+	if req.Key == "" {
+		secrets, err := bundle.Read(s.bundle, found.Name)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to read secret path '%s'", req.Path)
+		}
+		res.Content, err = json.Marshal(secrets)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "unable to encode secret path '%s'", req.Path)
+		}
+
+		return res, nil
+	}
+
+	// Lookup secret key
+	if found.Secrets != nil {
+		for _, kv := range found.Secrets.Data {
+			if kv == nil || kv.Key != req.Key {
+				continue
+			}
+
+			content, err := encodeValue(kv.Value)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "unable to decode secret key '%s' of path '%s'", req.Key, req.Path)
+			}
+			res.Content = content
+
+			return res, nil
+		}
+	}
+
+	// Key not found
+	return nil, status.Errorf(codes.NotFound, "secret key '%s' not found in path '%s'", req.Key, req.Path)
+}
+
+func (s *server) ListPackages(ctx context.Context, req *bundlev1.ListPackagesRequest) (*bundlev1.ListPackagesResponse, error) {
+	// Check arguments
+	if req == nil {
+		return nil, status.Error(codes.InvalidArgument, "request is required")
+	}
+
+	// Filter package names
+	res := &bundlev1.ListPackagesResponse{
+		Namespace: req.Namespace,
+		Names:     []string{},
+	}
+	for _, name := range s.names {
+		if strings.HasPrefix(name, req.Prefix) {
+			res.Names = append(res.Names, name)
+		}
+	}
+
+	// No error
+	return res, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func encodeValue(packed []byte) ([]byte, error) {
+	// Unpack secret value
+	var value interface{}
+	if err := secret.Unpack(packed, &value); err != nil {
+		return nil, fmt.Errorf("unable to unpack secret value: %w", err)
+	}
+
+	// Raw values are returned as-is
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	default:
+	}
+
+	// Encode as JSON
+	return json.Marshal(value)
+}
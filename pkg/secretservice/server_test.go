@@ -0,0 +1,158 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package secretservice
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+)
+
+func testServer(t *testing.T) bundlev1.BundleAPIServer {
+	t.Helper()
+
+	b, err := bundle.FromMap(map[string]bundle.KV{
+		"app/production/db": {
+			"user":     "admin",
+			"password": "foo",
+			"port":     5432,
+		},
+		"app/production/api": {
+			"token": "bar",
+		},
+		"infra/aws/account": {
+			"id": "123456",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unable to prepare bundle: %v", err)
+	}
+
+	s, err := New(b)
+	if err != nil {
+		t.Fatalf("unable to prepare server: %v", err)
+	}
+
+	return s
+}
+
+func TestNew(t *testing.T) {
+	s, err := New(nil)
+	assert.Error(t, err)
+	assert.Nil(t, s)
+}
+
+func TestServer_GetSecret(t *testing.T) {
+	ctx := context.Background()
+	underTest := testServer(t)
+
+	// Single key
+	res, err := underTest.GetSecret(ctx, &bundlev1.GetSecretRequest{Path: "app/production/db", Key: "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, "app/production/db", res.Path)
+	assert.Equal(t, "password", res.Key)
+	assert.Equal(t, []byte("foo"), res.Content)
+
+	// Non string value
+	res, err = underTest.GetSecret(ctx, &bundlev1.GetSecretRequest{Path: "app/production/db", Key: "port"})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("5432"), res.Content)
+
+	// Complete package
+	res, err = underTest.GetSecret(ctx, &bundlev1.GetSecretRequest{Path: "app/production/api"})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"token":"bar"}`, string(res.Content))
+}
+
+func TestServer_GetSecret_Errors(t *testing.T) {
+	ctx := context.Background()
+	underTest := testServer(t)
+
+	testCases := []struct {
+		name string
+		req  *bundlev1.GetSecretRequest
+		code codes.Code
+	}{
+		{name: "nil", req: nil, code: codes.InvalidArgument},
+		{name: "blank path", req: &bundlev1.GetSecretRequest{}, code: codes.InvalidArgument},
+		{name: "unknown path", req: &bundlev1.GetSecretRequest{Path: "app/staging/db"}, code: codes.NotFound},
+		{name: "path case mismatch", req: &bundlev1.GetSecretRequest{Path: "APP/PRODUCTION/DB", Key: "password"}, code: codes.NotFound},
+		{name: "unknown key", req: &bundlev1.GetSecretRequest{Path: "app/production/db", Key: "secret"}, code: codes.NotFound},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			res, err := underTest.GetSecret(ctx, testCase.req)
+			assert.Nil(t, res)
+			assert.Equal(t, testCase.code, status.Code(err))
+			assert.NotContains(t, err.Error(), "foo")
+		})
+	}
+}
+
+func TestServer_GetSecret_CaseSensitive(t *testing.T) {
+	ctx := context.Background()
+
+	b, err := bundle.FromMap(map[string]bundle.KV{
+		"app/prod/db": {"password": "lower"},
+		"APP/PROD/DB": {"password": "upper"},
+	})
+	if err != nil {
+		t.Fatalf("unable to prepare bundle: %v", err)
+	}
+	underTest, err := New(b)
+	if err != nil {
+		t.Fatalf("unable to prepare server: %v", err)
+	}
+
+	for path, expected := range map[string]string{
+		"app/prod/db": "lower",
+		"APP/PROD/DB": "upper",
+	} {
+		res, err := underTest.GetSecret(ctx, &bundlev1.GetSecretRequest{Path: path, Key: "password"})
+		assert.NoError(t, err)
+		assert.Equal(t, path, res.Path)
+		assert.Equal(t, []byte(expected), res.Content)
+	}
+}
+
+func TestServer_ListPackages(t *testing.T) {
+	ctx := context.Background()
+	underTest := testServer(t)
+
+	res, err := underTest.ListPackages(ctx, &bundlev1.ListPackagesRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app/production/api", "app/production/db", "infra/aws/account"}, res.Names)
+
+	res, err = underTest.ListPackages(ctx, &bundlev1.ListPackagesRequest{Prefix: "app/"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"app/production/api", "app/production/db"}, res.Names)
+
+	res, err = underTest.ListPackages(ctx, &bundlev1.ListPackagesRequest{Prefix: "unknown/"})
+	assert.NoError(t, err)
+	assert.Empty(t, res.Names)
+
+	_, err = underTest.ListPackages(ctx, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,97 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/bundle"
+	csov1 "github.com/elastic/harp/pkg/cso/v1"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// CSOLintTask implements bundle path naming policy linting task.
+type CSOLintTask struct {
+	ContainerReader tasks.ReaderProvider
+	RulesReader     tasks.ReaderProvider
+	OutputWriter    tasks.WriterProvider
+}
+
+// Run the task.
+func (t *CSOLintTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ContainerReader) {
+		return errors.New("unable to run task with a nil containerReader provider")
+	}
+	if types.IsNil(t.RulesReader) {
+		return errors.New("unable to run task with a nil rulesReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Create rules reader
+	rulesReader, err := t.RulesReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to initialize rules reader: %w", err)
+	}
+
+	// Parse rules
+	rules, err := csov1.LoadRules(rulesReader)
+	if err != nil {
+		return fmt.Errorf("unable to parse rules file: %w", err)
+	}
+
+	// Create input reader
+	reader, err := t.ContainerReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to initialize bundle reader: %w", err)
+	}
+
+	// Load bundle
+	b, err := bundle.FromContainerReader(reader)
+	if err != nil {
+		return fmt.Errorf("unable to load bundle content: %w", err)
+	}
+
+	// Evaluate rules
+	violations := csov1.Lint(b, rules)
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to initialize output writer: %w", err)
+	}
+
+	// Dump violations as JSON
+	if err := json.NewEncoder(writer).Encode(violations); err != nil {
+		return fmt.Errorf("unable to encode violations: %w", err)
+	}
+
+	// Fail on violations
+	if len(violations) > 0 {
+		return fmt.Errorf("bundle has %d rule violation(s)", len(violations))
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,135 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	csov1 "github.com/elastic/harp/pkg/cso/v1"
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+func TestCSOLintTask_Run(t *testing.T) {
+	type fields struct {
+		ContainerReader tasks.ReaderProvider
+		RulesReader     tasks.ReaderProvider
+		OutputWriter    tasks.WriterProvider
+	}
+	tests := []struct {
+		name           string
+		fields         fields
+		capture        bool
+		wantErr        bool
+		wantViolations int
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name: "nil rulesReader",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "nil outputWriter",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				RulesReader:     cmdutil.FileReader("../../../test/fixtures/cso/rules.yaml"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "rulesReader error",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				RulesReader:     cmdutil.FileReader("non-existent.yaml"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+			},
+			wantErr: true,
+		},
+		{
+			name: "containerReader - not a bundle",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.json"),
+				RulesReader:     cmdutil.FileReader("../../../test/fixtures/cso/rules.yaml"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+			},
+			wantErr: true,
+		},
+		// ---------------------------------------------------------------------
+		{
+			name: "compliant",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				RulesReader:     cmdutil.FileReader("../../../test/fixtures/cso/compliant.yaml"),
+			},
+			capture: true,
+			wantErr: false,
+		},
+		{
+			name: "rule violation",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				RulesReader:     cmdutil.FileReader("../../../test/fixtures/cso/rules.yaml"),
+			},
+			capture:        true,
+			wantErr:        true,
+			wantViolations: 1,
+		},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if testCase.capture {
+				testCase.fields.OutputWriter = func(_ context.Context) (io.Writer, error) {
+					return &out, nil
+				}
+			}
+
+			tr := &CSOLintTask{
+				ContainerReader: testCase.fields.ContainerReader,
+				RulesReader:     testCase.fields.RulesReader,
+				OutputWriter:    testCase.fields.OutputWriter,
+			}
+			if err := tr.Run(context.Background()); (err != nil) != testCase.wantErr {
+				t.Errorf("CSOLintTask.Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+			if !testCase.capture {
+				return
+			}
+
+			var violations []csov1.Violation
+			if err := json.Unmarshal(out.Bytes(), &violations); err != nil {
+				t.Fatalf("unable to decode violations: %v", err)
+			}
+			if len(violations) != testCase.wantViolations {
+				t.Errorf("expected %d violation(s), got %d", testCase.wantViolations, len(violations))
+			}
+		})
+	}
+}
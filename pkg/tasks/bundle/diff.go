@@ -25,6 +25,7 @@ import (
 
 	"sigs.k8s.io/yaml"
 
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle"
 	"github.com/elastic/harp/pkg/bundle/compare"
 	"github.com/elastic/harp/pkg/sdk/types"
@@ -37,6 +38,7 @@ type DiffTask struct {
 	DestinationReader tasks.ReaderProvider
 	OutputWriter      tasks.WriterProvider
 	GeneratePatch     bool
+	SummaryOnly       bool
 }
 
 // Run the task.
@@ -76,6 +78,11 @@ func (t *DiffTask) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to load destination bundle content: %w", err)
 	}
 
+	// Value-level summary without secret values
+	if t.SummaryOnly {
+		return t.summary(ctx, bSrc, bDst)
+	}
+
 	// Calculate diff
 	report, err := compare.Diff(bSrc, bDst)
 	if err != nil {
@@ -113,3 +120,27 @@ func (t *DiffTask) Run(ctx context.Context) error {
 	// No error
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+
+func (t *DiffTask) summary(ctx context.Context, bSrc, bDst *bundlev1.Bundle) error {
+	// Calculate diff
+	res, err := bundle.Diff(bSrc, bDst)
+	if err != nil {
+		return fmt.Errorf("unable to calculate bundle difference: %w", err)
+	}
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open output writer: %w", err)
+	}
+
+	// Encode as JSON
+	if err := json.NewEncoder(writer).Encode(res); err != nil {
+		return fmt.Errorf("unable to marshal JSON diff summary: %w", err)
+	}
+
+	// No error
+	return nil
+}
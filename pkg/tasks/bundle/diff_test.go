@@ -33,6 +33,7 @@ func TestDiffTask_Run(t *testing.T) {
 		DestinationReader tasks.ReaderProvider
 		OutputWriter      tasks.WriterProvider
 		GeneratePatch     bool
+		SummaryOnly       bool
 	}
 	type args struct {
 		ctx context.Context
@@ -167,6 +168,16 @@ func TestDiffTask_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "bundle diff - summary",
+			fields: fields{
+				SourceReader:      cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				DestinationReader: cmdutil.FileReader("../../../test/fixtures/bundles/empty.bundle"),
+				OutputWriter:      cmdutil.DiscardWriter(),
+				SummaryOnly:       true,
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -175,6 +186,7 @@ func TestDiffTask_Run(t *testing.T) {
 				DestinationReader: tt.fields.DestinationReader,
 				OutputWriter:      tt.fields.OutputWriter,
 				GeneratePatch:     tt.fields.GeneratePatch,
+				SummaryOnly:       tt.fields.SummaryOnly,
 			}
 			if err := tr.Run(tt.args.ctx); (err != nil) != tt.wantErr {
 				t.Errorf("DiffTask.Run() error = %v, wantErr %v", err, tt.wantErr)
@@ -40,6 +40,8 @@ type FilterTask struct {
 	KeepPaths       []string
 	ExcludePaths    []string
 	JMESPath        string
+	PathGlob        string
+	Labels          map[string]string
 }
 
 // Run the task.
@@ -87,6 +89,13 @@ func (t *FilterTask) Run(ctx context.Context) error {
 		}
 	}
 
+	if t.PathGlob != "" || len(t.Labels) > 0 {
+		b.Packages, errFilter = t.selectorFilter(b.Packages, bundle.Selector{PathGlob: t.PathGlob, Labels: t.Labels}, t.ReverseLogic)
+		if errFilter != nil {
+			return fmt.Errorf("unable to filter bundle packages: %w", errFilter)
+		}
+	}
+
 	// Create output writer
 	writer, err := t.OutputWriter(ctx)
 	if err != nil {
@@ -193,3 +202,29 @@ func (t *FilterTask) jmespathFilter(in []*bundlev1.Package, filter string, rever
 	// No error
 	return pkgs, nil
 }
+
+func (t *FilterTask) selectorFilter(in []*bundlev1.Package, sel bundle.Selector, reverseLogic bool) ([]*bundlev1.Package, error) {
+	// Check Arguments
+	if len(in) == 0 {
+		return in, nil
+	}
+
+	pkgs := []*bundlev1.Package{}
+
+	// Initialize selector
+	s, err := sel.Specification()
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply package filtering
+	for _, p := range in {
+		matched := s.IsSatisfiedBy(p)
+		if matched && !reverseLogic || !matched && reverseLogic {
+			pkgs = append(pkgs, p)
+		}
+	}
+
+	// No error
+	return pkgs, nil
+}
@@ -60,6 +60,8 @@ func TestFilterTask_Run(t *testing.T) {
 		KeepPaths       []string
 		ExcludePaths    []string
 		JMESPath        string
+		PathGlob        string
+		Labels          map[string]string
 	}
 	type args struct {
 		ctx context.Context
@@ -145,6 +147,15 @@ func TestFilterTask_Run(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "glob - invalid",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				PathGlob:        "app/[",
+			},
+			wantErr: true,
+		},
 		// ---------------------------------------------------------------------
 		{
 			name: "valid - noop",
@@ -181,6 +192,16 @@ func TestFilterTask_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid - glob and labels",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				PathGlob:        "app/**",
+				Labels:          map[string]string{"okta": "true"},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -191,6 +212,8 @@ func TestFilterTask_Run(t *testing.T) {
 				KeepPaths:       tt.fields.KeepPaths,
 				ExcludePaths:    tt.fields.ExcludePaths,
 				JMESPath:        tt.fields.JMESPath,
+				PathGlob:        tt.fields.PathGlob,
+				Labels:          tt.fields.Labels,
 			}
 			if err := tr.Run(tt.args.ctx); (err != nil) != tt.wantErr {
 				t.Errorf("FilterTask.Run() error = %v, wantErr %v", err, tt.wantErr)
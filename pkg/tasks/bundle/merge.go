@@ -0,0 +1,87 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// MergeTask implements secret container merge task.
+type MergeTask struct {
+	ContainerReaders []tasks.ReaderProvider
+	OutputWriter     tasks.WriterProvider
+	Strategy         bundle.MergeStrategy
+}
+
+// Run the task.
+func (t *MergeTask) Run(ctx context.Context) error {
+	// Check arguments
+	if len(t.ContainerReaders) == 0 {
+		return errors.New("unable to run task without containerReader providers")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Merge containers in order
+	merged := &bundlev1.Bundle{}
+	for i, rp := range t.ContainerReaders {
+		if types.IsNil(rp) {
+			return fmt.Errorf("unable to run task with a nil containerReader provider (#%d)", i)
+		}
+
+		// Retrieve the container reader
+		reader, err := rp(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to open container reader (#%d): %w", i, err)
+		}
+
+		// Load bundle
+		b, err := bundle.FromContainerReader(reader)
+		if err != nil {
+			return fmt.Errorf("unable to load bundle content (#%d): %w", i, err)
+		}
+
+		// Merge with previous ones
+		merged, err = bundle.Merge(merged, b, t.Strategy)
+		if err != nil {
+			return fmt.Errorf("unable to merge bundle (#%d): %w", i, err)
+		}
+	}
+
+	// Retrieve the output writer
+	outputWriter, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve output writer: %w", err)
+	}
+
+	// Dump all content
+	if err = bundle.ToContainerWriter(outputWriter, merged); err != nil {
+		return fmt.Errorf("unable to dump bundle content: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,83 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// MoveTask implements secret container package move task.
+type MoveTask struct {
+	ContainerReader tasks.ReaderProvider
+	OutputWriter    tasks.WriterProvider
+	From            string
+	To              string
+	Overwrite       bool
+}
+
+// Run the task.
+func (t *MoveTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ContainerReader) {
+		return errors.New("unable to run task with a nil containerReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Retrieve the container reader
+	containerReader, err := t.ContainerReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve container reader: %w", err)
+	}
+
+	// Load bundle
+	b, err := bundle.FromContainerReader(containerReader)
+	if err != nil {
+		return fmt.Errorf("unable to load bundle content: %w", err)
+	}
+
+	// Move packages
+	opts := []bundle.MoveOption{}
+	if t.Overwrite {
+		opts = append(opts, bundle.WithOverwrite())
+	}
+	if err := bundle.Move(b, t.From, t.To, opts...); err != nil {
+		return fmt.Errorf("unable to move packages: %w", err)
+	}
+
+	// Retrieve the output writer
+	outputWriter, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve output writer: %w", err)
+	}
+
+	// Dump all content
+	if err = bundle.ToContainerWriter(outputWriter, b); err != nil {
+		return fmt.Errorf("unable to dump bundle content: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"context"
+	"testing"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+func TestMoveTask_Run(t *testing.T) {
+	tests := []struct {
+		name    string
+		reader  tasks.ReaderProvider
+		writer  tasks.WriterProvider
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name:    "nil outputWriter",
+			reader:  cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			wantErr: true,
+		},
+		{
+			name:    "containerReader error",
+			reader:  cmdutil.FileReader("non-existent.bundle"),
+			writer:  cmdutil.DiscardWriter(),
+			wantErr: true,
+		},
+		{
+			name:    "no matching package",
+			reader:  cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			writer:  cmdutil.DiscardWriter(),
+			from:    "non/existent/*",
+			to:      "other/*",
+			wantErr: true,
+		},
+		// ---------------------------------------------------------------------
+		{
+			name:    "valid",
+			reader:  cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			writer:  cmdutil.DiscardWriter(),
+			from:    "app/*",
+			to:      "legacy/app/*",
+			wantErr: false,
+		},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			tr := &MoveTask{
+				ContainerReader: testCase.reader,
+				OutputWriter:    testCase.writer,
+				From:            testCase.from,
+				To:              testCase.to,
+			}
+			if err := tr.Run(context.Background()); (err != nil) != testCase.wantErr {
+				t.Errorf("MoveTask.Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
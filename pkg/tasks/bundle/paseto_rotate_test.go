@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/bundle/keyring"
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+func TestPasetoRotateKeyTask_Run(t *testing.T) {
+	tests := []struct {
+		name      string
+		reader    tasks.ReaderProvider
+		writer    tasks.WriterProvider
+		path      string
+		retention time.Duration
+		wantErr   bool
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name:    "nil outputWriter",
+			reader:  cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			wantErr: true,
+		},
+		{
+			name:    "containerReader error",
+			reader:  cmdutil.FileReader("non-existent.bundle"),
+			writer:  cmdutil.DiscardWriter(),
+			wantErr: true,
+		},
+		{
+			name:    "blank package path",
+			reader:  cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			writer:  cmdutil.DiscardWriter(),
+			wantErr: true,
+		},
+		{
+			name:      "negative retention",
+			reader:    cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			writer:    cmdutil.DiscardWriter(),
+			path:      "app/security/paseto",
+			retention: -time.Hour,
+			wantErr:   true,
+		},
+		// ---------------------------------------------------------------------
+		{
+			name:      "valid",
+			reader:    cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+			writer:    cmdutil.DiscardWriter(),
+			path:      "app/security/paseto",
+			retention: time.Hour,
+			wantErr:   false,
+		},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			tr := &PasetoRotateKeyTask{
+				ContainerReader: testCase.reader,
+				OutputWriter:    testCase.writer,
+				PackagePath:     testCase.path,
+				Retention:       testCase.retention,
+			}
+			if err := tr.Run(context.Background()); (err != nil) != testCase.wantErr {
+				t.Errorf("PasetoRotateKeyTask.Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasetoRotateKeyTask_Output(t *testing.T) {
+	var out bytes.Buffer
+	tr := &PasetoRotateKeyTask{
+		ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+		OutputWriter: func(_ context.Context) (io.Writer, error) {
+			return &out, nil
+		},
+		PackagePath: "app/security/paseto",
+		Retention:   time.Hour,
+	}
+	assert.NoError(t, tr.Run(context.Background()))
+
+	b, err := bundle.FromContainerReader(&out)
+	assert.NoError(t, err)
+	entries, err := keyring.Entries(b, "app/security/paseto")
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, keyring.StatusActive, entries[0].Status)
+}
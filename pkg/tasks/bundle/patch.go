@@ -19,11 +19,15 @@ package bundle
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 
+	"go.uber.org/zap"
+
 	"github.com/elastic/harp/pkg/bundle"
 	"github.com/elastic/harp/pkg/bundle/patch"
+	"github.com/elastic/harp/pkg/sdk/log"
 	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/tasks"
 )
@@ -34,6 +38,7 @@ type PatchTask struct {
 	ContainerReader tasks.ReaderProvider
 	OutputWriter    tasks.WriterProvider
 	Values          map[string]interface{}
+	DryRun          bool
 }
 
 // Run the task.
@@ -74,17 +79,40 @@ func (t *PatchTask) Run(ctx context.Context) error {
 	}
 
 	// Apply the patch speicification to generate an output bundle
-	patchedBundle, err := patch.Apply(spec, b, t.Values)
+	report := &patch.Report{}
+	patchedBundle, err := patch.Apply(spec, b, t.Values, patch.WithReport(report))
 	if err != nil {
 		return fmt.Errorf("unable to generate output bundle from patch: %w", err)
 	}
 
+	// Display rule statistics
+	for _, r := range report.Rules {
+		log.For(ctx).Info("Patch rule executed", zap.Int("rule", r.Index), zap.Int("applied", r.Applied), zap.Int("skipped", r.Skipped))
+	}
+
 	// Retrieve the container reader
 	outputWriter, err := t.OutputWriter(ctx)
 	if err != nil {
 		return fmt.Errorf("unable to retrieve output writer: %w", err)
 	}
 
+	// Report changes without producing the patched bundle
+	if t.DryRun {
+		// Calculate diff
+		res, errDiff := bundle.Diff(b, patchedBundle)
+		if errDiff != nil {
+			return fmt.Errorf("unable to calculate patch changes: %w", errDiff)
+		}
+
+		// Encode as JSON
+		if err = json.NewEncoder(outputWriter).Encode(res); err != nil {
+			return fmt.Errorf("unable to marshal JSON patch changes: %w", err)
+		}
+
+		// No error
+		return nil
+	}
+
 	// Dump all content
 	if err = bundle.ToContainerWriter(outputWriter, patchedBundle); err != nil {
 		return fmt.Errorf("unable to dump bundle content: %w", err)
@@ -33,6 +33,7 @@ func TestPatchTask_Run(t *testing.T) {
 		ContainerReader tasks.ReaderProvider
 		OutputWriter    tasks.WriterProvider
 		Values          map[string]interface{}
+		DryRun          bool
 	}
 	type args struct {
 		ctx context.Context
@@ -132,6 +133,16 @@ func TestPatchTask_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid - dry run",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				PatchReader:     cmdutil.FileReader("../../../test/fixtures/patch/valid/key-rewriter.yaml"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				DryRun:          true,
+			},
+			wantErr: false,
+		},
 		{
 			name: "empty patch",
 			fields: fields{
@@ -149,6 +160,7 @@ func TestPatchTask_Run(t *testing.T) {
 				ContainerReader: tt.fields.ContainerReader,
 				OutputWriter:    tt.fields.OutputWriter,
 				Values:          tt.fields.Values,
+				DryRun:          tt.fields.DryRun,
 			}
 			if err := tr.Run(tt.args.ctx); (err != nil) != tt.wantErr {
 				t.Errorf("PatchTask.Run() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,84 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/awnumar/memguard"
+
+	"github.com/elastic/harp/pkg/container"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// RewrapTask implements sealed container recipient rotation task.
+type RewrapTask struct {
+	ContainerReader tasks.ReaderProvider
+	OutputWriter    tasks.WriterProvider
+	ContainerKey    *memguard.LockedBuffer
+	PeerPublicKeys  []*[32]byte
+}
+
+// Run the task.
+func (t *RewrapTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ContainerReader) {
+		return errors.New("unable to run task with a nil containerReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+	if t.ContainerKey == nil {
+		return errors.New("unable to run task with a nil container key")
+	}
+	if len(t.PeerPublicKeys) == 0 {
+		return errors.New("at least one public key must be provided")
+	}
+
+	// Create input reader
+	reader, err := t.ContainerReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open input container reader: %w", err)
+	}
+
+	// Decode container key
+	privateKeyRaw, err := base64.RawURLEncoding.DecodeString(t.ContainerKey.String())
+	if err != nil {
+		return fmt.Errorf("unable to decode container key: %w", err)
+	}
+	identity := memguard.NewBufferFromBytes(privateKeyRaw)
+	defer identity.Destroy()
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open output container: %w", err)
+	}
+
+	// Rewrap the container
+	if err := container.Rewrap(reader, writer, identity, t.PeerPublicKeys...); err != nil {
+		return fmt.Errorf("unable to rewrap container: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,117 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"context"
+	"testing"
+
+	"github.com/awnumar/memguard"
+
+	"github.com/elastic/harp/pkg/sdk/cmdutil"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+func TestRewrapTask_Run(t *testing.T) {
+	peerPublicKeys := []*[32]byte{
+		{
+			0x97, 0x75, 0x9e, 0x17, 0x35, 0x8a, 0x5b, 0xae, 0x6b, 0x5a, 0xfc, 0xde, 0x97, 0x40, 0x84, 0x7f,
+			0xad, 0x59, 0xe6, 0x0a, 0x25, 0x81, 0xbe, 0xcd, 0xc6, 0xa0, 0x37, 0x0e, 0x0b, 0x66, 0x1d, 0x49,
+		},
+	}
+
+	type fields struct {
+		ContainerReader tasks.ReaderProvider
+		OutputWriter    tasks.WriterProvider
+		ContainerKey    *memguard.LockedBuffer
+		PeerPublicKeys  []*[32]byte
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		wantErr bool
+	}{
+		{
+			name:    "nil",
+			wantErr: true,
+		},
+		{
+			name: "nil containerKey",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.sealed"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				PeerPublicKeys:  peerPublicKeys,
+			},
+			wantErr: true,
+		},
+		{
+			name: "no public keys",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.sealed"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				ContainerKey:    memguard.NewBufferFromBytes([]byte("MiVGh4KOmdzZbej17BZGChkCPZ9uK9uBWdPNU0GlBNg")),
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid container key",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.sealed"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				ContainerKey:    memguard.NewBuffer(32),
+				PeerPublicKeys:  peerPublicKeys,
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsealed container",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				ContainerKey:    memguard.NewBufferFromBytes([]byte("MiVGh4KOmdzZbej17BZGChkCPZ9uK9uBWdPNU0GlBNg")),
+				PeerPublicKeys:  peerPublicKeys,
+			},
+			wantErr: true,
+		},
+		// ---------------------------------------------------------------------
+		{
+			name: "valid",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.sealed"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				ContainerKey:    memguard.NewBufferFromBytes([]byte("MiVGh4KOmdzZbej17BZGChkCPZ9uK9uBWdPNU0GlBNg")),
+				PeerPublicKeys:  peerPublicKeys,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			tr := &RewrapTask{
+				ContainerReader: testCase.fields.ContainerReader,
+				OutputWriter:    testCase.fields.OutputWriter,
+				ContainerKey:    testCase.fields.ContainerKey,
+				PeerPublicKeys:  testCase.fields.PeerPublicKeys,
+			}
+			if err := tr.Run(context.Background()); (err != nil) != testCase.wantErr {
+				t.Errorf("RewrapTask.Run() error = %v, wantErr %v", err, testCase.wantErr)
+			}
+		})
+	}
+}
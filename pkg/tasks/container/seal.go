@@ -18,6 +18,7 @@
 package container
 
 import (
+	"compress/gzip"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -25,8 +26,12 @@ import (
 	"fmt"
 
 	"github.com/awnumar/memguard"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/protobuf/proto"
 
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
 	"github.com/elastic/harp/pkg/container"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/tasks"
 )
@@ -41,6 +46,8 @@ type SealTask struct {
 	DCKDTarget               string
 	JSONOutput               bool
 	DisableContainerIdentity bool
+	Password                 *memguard.LockedBuffer
+	Compress                 bool
 }
 
 // Run the task.
@@ -56,8 +63,8 @@ func (t *SealTask) Run(ctx context.Context) error {
 	if types.IsNil(t.OutputWriter) {
 		return errors.New("unable to run task with a nil outputWriter provider")
 	}
-	if len(t.PeerPublicKeys) == 0 {
-		return errors.New("at least one public key must be provided for recovery")
+	if len(t.PeerPublicKeys) == 0 && t.Password == nil {
+		return errors.New("at least one public key or a password must be provided for recovery")
 	}
 
 	// Create input reader
@@ -94,10 +101,29 @@ func (t *SealTask) Run(ctx context.Context) error {
 	}
 
 	// Seal the container
-	sealedContainer, err := container.Seal(in, t.PeerPublicKeys...)
+	_, end := tracing.Start(ctx, "container.seal",
+		attribute.Int("harp.container.recipients", len(t.PeerPublicKeys)),
+		attribute.Bool("harp.container.password", t.Password != nil),
+		attribute.Int("harp.container.input_size", len(in.Raw)),
+	)
+	opts := []container.SealOption{container.WithRecipients(t.PeerPublicKeys...)}
+	if t.Compress {
+		opts = append(opts, container.WithCompression(gzip.BestCompression))
+	}
+	var sealedContainer *containerv1.Container
+	if t.Password != nil {
+		sealedContainer, err = container.SealWithPassword(in, t.Password, opts...)
+	} else {
+		sealedContainer, err = container.SealWithOptions(in, opts...)
+	}
 	if err != nil {
+		end(err)
 		return fmt.Errorf("unable to seal container: %w", err)
 	}
+	end(nil, attribute.Int("harp.container.output_size", len(sealedContainer.Raw)))
+
+	// Size accounting
+	uncompressedSize, compressedSize := proto.Size(in), container.SealedContentSize(sealedContainer)
 
 	// Open output file
 	writer, err := t.SealedContainerWriter(ctx)
@@ -116,19 +142,35 @@ func (t *SealTask) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to retrieve output writer: %w", err)
 	}
 
-	if !t.DisableContainerIdentity {
-		// Display as json
-		if t.JSONOutput {
-			if err := json.NewEncoder(outputWriter).Encode(map[string]interface{}{
-				"container_key": containerKey,
-			}); err != nil {
+	// Display as json
+	if t.JSONOutput {
+		res := map[string]interface{}{}
+		if !t.DisableContainerIdentity {
+			res["container_key"] = containerKey
+		}
+		if t.Compress {
+			res["uncompressed_size"] = uncompressedSize
+			res["compressed_size"] = compressedSize
+		}
+		if len(res) > 0 {
+			if err := json.NewEncoder(outputWriter).Encode(res); err != nil {
 				return fmt.Errorf("unable to display as json: %w", err)
 			}
-		} else {
-			// Display container key
-			if _, err := fmt.Fprintf(outputWriter, "Container key : %s\n", containerKey); err != nil {
-				return fmt.Errorf("unable to display result: %w", err)
-			}
+		}
+		return nil
+	}
+
+	// Display container key
+	if !t.DisableContainerIdentity {
+		if _, err := fmt.Fprintf(outputWriter, "Container key : %s\n", containerKey); err != nil {
+			return fmt.Errorf("unable to display result: %w", err)
+		}
+	}
+
+	// Display sizes
+	if t.Compress {
+		if _, err := fmt.Fprintf(outputWriter, "Uncompressed size : %d bytes\nCompressed size : %d bytes\n", uncompressedSize, compressedSize); err != nil {
+			return fmt.Errorf("unable to display result: %w", err)
 		}
 	}
 
@@ -39,6 +39,8 @@ func TestSealTask_Run(t *testing.T) {
 		DCKDTarget               string
 		JSONOutput               bool
 		DisableContainerIdentity bool
+		Password                 *memguard.LockedBuffer
+		Compress                 bool
 	}
 	type args struct {
 		ctx context.Context
@@ -293,6 +295,29 @@ func TestSealTask_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid - password only",
+			fields: fields{
+				ContainerReader:          cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				SealedContainerWriter:    cmdutil.DiscardWriter(),
+				OutputWriter:             cmdutil.DiscardWriter(),
+				DisableContainerIdentity: true,
+				Password:                 memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid - compressed json output",
+			fields: fields{
+				ContainerReader:       cmdutil.FileReader("../../../test/fixtures/bundles/complete.bundle"),
+				SealedContainerWriter: cmdutil.DiscardWriter(),
+				OutputWriter:          cmdutil.DiscardWriter(),
+				JSONOutput:            true,
+				Password:              memguard.NewBufferFromBytes([]byte("correct horse battery staple")),
+				Compress:              true,
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -305,6 +330,8 @@ func TestSealTask_Run(t *testing.T) {
 				DCKDTarget:               tt.fields.DCKDTarget,
 				JSONOutput:               tt.fields.JSONOutput,
 				DisableContainerIdentity: tt.fields.DisableContainerIdentity,
+				Password:                 tt.fields.Password,
+				Compress:                 tt.fields.Compress,
 			}
 			if err := tr.Run(tt.args.ctx); (err != nil) != tt.wantErr {
 				t.Errorf("SealTask.Run() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,119 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/awnumar/memguard"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/container"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/secretservice"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// ServeTask implements sealed container secret serving task.
+type ServeTask struct {
+	ContainerReader tasks.ReaderProvider
+	ContainerKey    *memguard.LockedBuffer
+	// Password is used to unseal password sealed containers, container key is
+	// ignored when specified.
+	Password      *memguard.LockedBuffer
+	ListenAddress string
+	TLSCertFile   string
+	TLSKeyFile    string
+	ClientCAFile  string
+	// RateLimit is the allowed requests per second per client, 0 disables
+	// rate limiting.
+	RateLimit float64
+	RateBurst int
+}
+
+// Run the task.
+func (t *ServeTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ContainerReader) {
+		return errors.New("unable to run task with a nil containerReader provider")
+	}
+	if t.ContainerKey == nil && t.Password == nil {
+		return errors.New("unable to run task with a nil container key")
+	}
+	if t.ListenAddress == "" {
+		return errors.New("unable to run task with a blank listen address")
+	}
+
+	// Prepare TLS configuration
+	tlsConfig, err := secretservice.TLSConfig(t.TLSCertFile, t.TLSKeyFile, t.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("unable to prepare TLS configuration: %w", err)
+	}
+
+	// Create input reader
+	reader, err := t.ContainerReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open input container reader: %w", err)
+	}
+
+	// Load input container
+	in, err := container.Load(reader)
+	if err != nil {
+		return fmt.Errorf("unable to read input container: %w", err)
+	}
+
+	// Unseal the container in memory
+	out, err := unseal(ctx, in, t.Password, t.ContainerKey)
+	if err != nil {
+		return fmt.Errorf("unable to unseal container: %w", err)
+	}
+
+	// Decode bundle
+	b, err := bundle.FromContainer(out)
+	if err != nil {
+		return fmt.Errorf("unable to decode bundle: %w", err)
+	}
+
+	// Prepare server
+	srv, err := secretservice.New(b)
+	if err != nil {
+		return fmt.Errorf("unable to initialize secret service: %w", err)
+	}
+
+	// Listen
+	lis, err := net.Listen("tcp", t.ListenAddress)
+	if err != nil {
+		return fmt.Errorf("unable to listen on '%s': %w", t.ListenAddress, err)
+	}
+
+	log.For(ctx).Info("Serving secrets ...", zap.String("address", lis.Addr().String()), zap.Int("packages", len(b.Packages)))
+
+	// Delegate to server
+	return secretservice.Serve(ctx, lis, tlsConfig, srv, grpc.UnaryInterceptor(
+		secretservice.UnaryInterceptor(
+			secretservice.WithRateLimit(t.RateLimit, t.RateBurst),
+			secretservice.WithAuditSink(secretservice.LogAuditSink()),
+		),
+	))
+}
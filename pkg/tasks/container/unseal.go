@@ -24,8 +24,11 @@ import (
 	"fmt"
 
 	"github.com/awnumar/memguard"
+	"go.opentelemetry.io/otel/attribute"
 
+	containerv1 "github.com/elastic/harp/api/gen/go/harp/container/v1"
 	"github.com/elastic/harp/pkg/container"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/tasks"
 )
@@ -35,6 +38,12 @@ type UnsealTask struct {
 	ContainerReader tasks.ReaderProvider
 	OutputWriter    tasks.WriterProvider
 	ContainerKey    *memguard.LockedBuffer
+	// ContainerKeys are additional container keys tried when ContainerKey
+	// doesn't match any container recipient.
+	ContainerKeys []*memguard.LockedBuffer
+	// Password is used to unseal password sealed containers, container keys
+	// are ignored when specified.
+	Password *memguard.LockedBuffer
 }
 
 // Run the task.
@@ -46,7 +55,7 @@ func (t *UnsealTask) Run(ctx context.Context) error {
 	if types.IsNil(t.OutputWriter) {
 		return errors.New("unable to run task with a nil outputWriter provider")
 	}
-	if t.ContainerKey == nil {
+	if t.ContainerKey == nil && t.Password == nil {
 		return errors.New("unable to run task with a nil container key")
 	}
 
@@ -62,15 +71,8 @@ func (t *UnsealTask) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to read input container: %w", err)
 	}
 
-	// Decode container key
-	privateKeyRaw, err := base64.RawURLEncoding.DecodeString(t.ContainerKey.String())
-	if err != nil {
-		return fmt.Errorf("unable to decode container key: %w", err)
-	}
-	defer memguard.WipeBytes(privateKeyRaw)
-
 	// Unseal the bundle
-	out, err := container.Unseal(in, memguard.NewBufferFromBytes(privateKeyRaw))
+	out, err := t.unseal(ctx, in)
 	if err != nil {
 		return fmt.Errorf("unable to unseal bundle content: %w", err)
 	}
@@ -89,3 +91,57 @@ func (t *UnsealTask) Run(ctx context.Context) error {
 	// No error
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+
+func (t *UnsealTask) unseal(ctx context.Context, in *containerv1.Container) (*containerv1.Container, error) {
+	return unseal(ctx, in, t.Password, append([]*memguard.LockedBuffer{t.ContainerKey}, t.ContainerKeys...)...)
+}
+
+// unseal the given container using the password if any, or else by trying all
+// given encoded container keys.
+func unseal(ctx context.Context, in *containerv1.Container, password *memguard.LockedBuffer, containerKeys ...*memguard.LockedBuffer) (*containerv1.Container, error) {
+	_, end := tracing.Start(ctx, "container.unseal",
+		attribute.Bool("harp.container.password", password != nil),
+		attribute.Int("harp.container.input_size", len(in.GetRaw())),
+	)
+
+	// Delegate to implementation
+	out, err := open(in, password, containerKeys...)
+	if err != nil {
+		end(err)
+		return nil, err
+	}
+	end(nil, attribute.Int("harp.container.output_size", len(out.Raw)))
+
+	// No error
+	return out, nil
+}
+
+func open(in *containerv1.Container, password *memguard.LockedBuffer, containerKeys ...*memguard.LockedBuffer) (*containerv1.Container, error) {
+	// Password sealed container
+	if password != nil {
+		return container.UnsealWithPassword(in, password)
+	}
+
+	// Decode container keys
+	identities := []*memguard.LockedBuffer{}
+	for _, k := range containerKeys {
+		if k == nil {
+			continue
+		}
+
+		privateKeyRaw, err := base64.RawURLEncoding.DecodeString(k.String())
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode container key: %w", err)
+		}
+
+		identity := memguard.NewBufferFromBytes(privateKeyRaw)
+		defer identity.Destroy()
+
+		identities = append(identities, identity)
+	}
+
+	// Delegate to container
+	return container.Unseal(in, identities...)
+}
@@ -33,6 +33,7 @@ func TestUnsealTask_Run(t *testing.T) {
 		ContainerReader tasks.ReaderProvider
 		OutputWriter    tasks.WriterProvider
 		ContainerKey    *memguard.LockedBuffer
+		ContainerKeys   []*memguard.LockedBuffer
 	}
 	type args struct {
 		ctx context.Context
@@ -139,6 +140,18 @@ func TestUnsealTask_Run(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid - with additional container keys",
+			fields: fields{
+				ContainerReader: cmdutil.FileReader("../../../test/fixtures/bundles/complete.sealed"),
+				OutputWriter:    cmdutil.DiscardWriter(),
+				ContainerKey:    memguard.NewBufferFromBytes([]byte("1eW0ML-4wKvQ2Y8bn0Y5aqV0MG0m5iUN-4s8wPKmbDk")),
+				ContainerKeys: []*memguard.LockedBuffer{
+					memguard.NewBufferFromBytes([]byte("MiVGh4KOmdzZbej17BZGChkCPZ9uK9uBWdPNU0GlBNg")),
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -146,6 +159,7 @@ func TestUnsealTask_Run(t *testing.T) {
 				ContainerReader: tt.fields.ContainerReader,
 				OutputWriter:    tt.fields.OutputWriter,
 				ContainerKey:    tt.fields.ContainerKey,
+				ContainerKeys:   tt.fields.ContainerKeys,
 			}
 			if err := tr.Run(tt.args.ctx); (err != nil) != tt.wantErr {
 				t.Errorf("UnsealTask.Run() error = %v, wantErr %v", err, tt.wantErr)
@@ -0,0 +1,79 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package from
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// EnvFileTask implements secret-container creation from a dotenv or CSV file.
+type EnvFileTask struct {
+	FileReader   tasks.ReaderProvider
+	OutputWriter tasks.WriterProvider
+	PackagePath  string
+	CSV          bool
+}
+
+// Run the task.
+func (t *EnvFileTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.FileReader) {
+		return errors.New("unable to run task with a nil fileReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Create input reader
+	reader, err := t.FileReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read input reader: %w", err)
+	}
+
+	// Build the container
+	var b *bundlev1.Bundle
+	if t.CSV {
+		b, err = bundle.FromCSV(reader, t.PackagePath)
+	} else {
+		b, err = bundle.FromDotEnv(reader, t.PackagePath)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create container from input file: %w", err)
+	}
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open output writer: %w", err)
+	}
+
+	// Dump bundle
+	if err = bundle.ToContainerWriter(writer, b); err != nil {
+		return fmt.Errorf("unable to produce exported bundle: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package from
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// KubernetesTask implements secret-container creation from Kubernetes Secret
+// manifests.
+type KubernetesTask struct {
+	ManifestReader tasks.ReaderProvider
+	OutputWriter   tasks.WriterProvider
+}
+
+// Run the task.
+func (t *KubernetesTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ManifestReader) {
+		return errors.New("unable to run task with a nil manifestReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Create input reader
+	reader, err := t.ManifestReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to read input reader: %w", err)
+	}
+
+	// Build the container from manifests
+	b, err := bundle.FromKubernetes(reader)
+	if err != nil {
+		return fmt.Errorf("unable to create container from Kubernetes manifests: %w", err)
+	}
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open output writer: %w", err)
+	}
+
+	// Dump bundle
+	if err = bundle.ToContainerWriter(writer, b); err != nil {
+		return fmt.Errorf("unable to produce exported bundle: %w", err)
+	}
+
+	// No error
+	return nil
+}
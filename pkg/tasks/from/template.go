@@ -22,10 +22,13 @@ import (
 	"fmt"
 	"io"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle"
 	"github.com/elastic/harp/pkg/bundle/template"
 	"github.com/elastic/harp/pkg/bundle/template/visitor/secretbuilder"
+	"github.com/elastic/harp/pkg/sdk/tracing"
 	"github.com/elastic/harp/pkg/tasks"
 	"github.com/elastic/harp/pkg/template/engine"
 )
@@ -68,9 +71,12 @@ func (t *BundleTemplateTask) Run(ctx context.Context) error {
 	v := secretbuilder.New(b, t.TemplateContext)
 
 	// Execute the template to generate an output bundle
+	_, end := tracing.Start(ctx, "bundle.render")
 	if err = template.Execute(spec, v); err != nil {
+		end(err)
 		return fmt.Errorf("unable to generate output bundle from template: %w", err)
 	}
+	end(nil, attribute.Int("harp.bundle.packages", len(b.Packages)))
 
 	// Create output writer
 	writer, err = t.OutputWriter(ctx)
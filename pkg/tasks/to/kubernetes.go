@@ -0,0 +1,136 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package to
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/sdk/types"
+	"github.com/elastic/harp/pkg/tasks"
+)
+
+// KubernetesTask implements secret-container publication process to Kubernetes
+// Secret manifests.
+type KubernetesTask struct {
+	ContainerReader   tasks.ReaderProvider
+	OutputWriter      tasks.WriterProvider
+	CertificateReader tasks.ReaderProvider
+	Namespace         string
+	NameTemplate      string
+	PropagateLabels   bool
+}
+
+// Run the task.
+func (t *KubernetesTask) Run(ctx context.Context) error {
+	// Check arguments
+	if types.IsNil(t.ContainerReader) {
+		return errors.New("unable to run task with a nil containerReader provider")
+	}
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	opts := bundle.KubernetesOptions{
+		Namespace:       t.Namespace,
+		NameTemplate:    t.NameTemplate,
+		PropagateLabels: t.PropagateLabels,
+	}
+
+	// Load sealed-secrets controller certificate
+	if !types.IsNil(t.CertificateReader) {
+		certReader, err := t.CertificateReader(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to open certificate reader: %w", err)
+		}
+
+		opts.SealingKey, err = sealingKey(certReader)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Create the reader
+	reader, err := t.ContainerReader(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open input bundle reader: %w", err)
+	}
+
+	// Extract bundle from container
+	b, err := bundle.FromContainerReader(reader)
+	if err != nil {
+		return fmt.Errorf("unable to load bundle: %w", err)
+	}
+
+	// Render manifests
+	out, err := bundle.ToKubernetes(b, opts)
+	if err != nil {
+		return fmt.Errorf("unable to render Kubernetes manifests: %w", err)
+	}
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open writer: %w", err)
+	}
+
+	// Write manifests
+	if _, err := writer.Write(out); err != nil {
+		return fmt.Errorf("unable to write Kubernetes manifests: %w", err)
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func sealingKey(r io.Reader) (*rsa.PublicKey, error) {
+	// Read certificate
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read certificate: %w", err)
+	}
+
+	// Decode PEM
+	block, _ := pem.Decode(raw)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("unable to decode certificate, a PEM encoded certificate is expected")
+	}
+
+	// Parse certificate
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse certificate: %w", err)
+	}
+
+	// Extract RSA public key
+	pk, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported certificate public key type %T, an RSA key is expected", cert.PublicKey)
+	}
+
+	// No error
+	return pk, nil
+}
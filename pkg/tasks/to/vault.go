@@ -19,12 +19,18 @@ package to
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/hashicorp/vault/api"
+	"go.uber.org/zap"
 
+	bundlev1 "github.com/elastic/harp/api/gen/go/harp/bundle/v1"
 	"github.com/elastic/harp/pkg/bundle"
+	"github.com/elastic/harp/pkg/bundle/plan"
 	bundlevault "github.com/elastic/harp/pkg/bundle/vault"
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/types"
 	"github.com/elastic/harp/pkg/tasks"
 	"github.com/elastic/harp/pkg/vault"
 )
@@ -37,6 +43,10 @@ type VaultTask struct {
 	AsVaultMetadata bool
 	VaultNamespace  string
 	MaxWorkerCount  int64
+	CheckAndSet     bool
+	CreateOnly      bool
+	DryRun          bool
+	OutputWriter    tasks.WriterProvider
 }
 
 // Run the task.
@@ -69,16 +79,71 @@ func (t *VaultTask) Run(ctx context.Context) error {
 		return fmt.Errorf("unable to load bundle: %w", err)
 	}
 
-	// Process push operation
-	if err := bundlevault.Push(ctx, b, client,
+	// Display the change plan only
+	if t.DryRun {
+		return t.plan(ctx, client, b)
+	}
+
+	// Prepare options
+	opts := []bundlevault.Option{
 		bundlevault.WithPrefix(t.BackendPrefix),
 		bundlevault.WithSecretMetadata(t.PushMetadata),
 		bundlevault.WithVaultMetadata(t.AsVaultMetadata),
 		bundlevault.WithMaxWorkerCount(t.MaxWorkerCount),
-	); err != nil {
+	}
+
+	// Check-and-set writes are reported by path
+	if t.CheckAndSet || t.CreateOnly {
+		opts = append(opts,
+			bundlevault.WithCheckAndSet(t.CheckAndSet),
+			bundlevault.WithCreateOnly(t.CreateOnly),
+		)
+
+		report, err := bundlevault.Import(ctx, client, b, opts...)
+		if err != nil {
+			return fmt.Errorf("error occurs during vault export (prefix: '%s'): %w", t.BackendPrefix, err)
+		}
+		for _, res := range report.Failed() {
+			log.For(ctx).Error("unable to write secret", zap.String("path", res.Path), zap.Error(res.Err))
+		}
+
+		return report.Err()
+	}
+
+	// Process push operation
+	if err := bundlevault.Push(ctx, b, client, opts...); err != nil {
 		return fmt.Errorf("error occurs during vault export (prefix: '%s'): %w", t.BackendPrefix, err)
 	}
 
 	// No error
 	return nil
 }
+
+// -----------------------------------------------------------------------------
+
+func (t *VaultTask) plan(ctx context.Context, client *api.Client, b *bundlev1.Bundle) error {
+	// Check arguments
+	if types.IsNil(t.OutputWriter) {
+		return errors.New("unable to run task with a nil outputWriter provider")
+	}
+
+	// Compute the plan
+	p, err := plan.New(plan.VaultState(client)).Bundle(ctx, b, t.BackendPrefix)
+	if err != nil {
+		return fmt.Errorf("unable to compute vault export plan (prefix: '%s'): %w", t.BackendPrefix, err)
+	}
+
+	// Create output writer
+	writer, err := t.OutputWriter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to open output writer: %w", err)
+	}
+
+	// Display the plan
+	if err := p.Render(writer); err != nil {
+		return fmt.Errorf("unable to display vault export plan: %w", err)
+	}
+
+	// No error
+	return nil
+}
@@ -72,10 +72,24 @@ func RenderContextWithData(templateContext Context, input string, data interface
 	// Retrieve delimiters
 	leftDelim, rightDelim := templateContext.Delims()
 
+	// Prepare function map
+	funcs := FuncMap(templateContext.SecretReaders())
+	funcs["passwordFor"] = PasswordFor(templateContext.PasswordSeed())
+	if templateContext.Sandbox() {
+		funcs = Sandbox(funcs)
+	}
+	funcs["include"] = (&includer{
+		fsys:       templateContext.Partials(),
+		leftDelim:  leftDelim,
+		rightDelim: rightDelim,
+		strictMode: templateContext.StrictMode(),
+		funcs:      funcs,
+	}).include
+
 	// Prepare the template
 	t, err := template.New(templateContext.Name()).
 		Delims(leftDelim, rightDelim).
-		Funcs(FuncMap(templateContext.SecretReaders())).
+		Funcs(funcs).
 		Parse(input)
 	if err != nil {
 		return "", fmt.Errorf("unable to compile attribute template '%s': %w", input, err)
@@ -17,14 +17,19 @@
 
 package engine
 
+import "io/fs"
+
 // Context describes engine rendering context contract.
 type Context interface {
 	Name() string
 	StrictMode() bool
+	Sandbox() bool
 	Delims() (string, string)
 	SecretReaders() []SecretReaderFunc
+	PasswordSeed() []byte
 	Values() Values
 	Files() Files
+	Partials() fs.FS
 }
 
 // -----------------------------------------------------------------------------
@@ -46,6 +51,14 @@ func WithStrictMode(value bool) ContextOption {
 	}
 }
 
+// WithSandbox enable or disable sandbox mode, functions giving access to the
+// host environment or to secret backends are disabled in sandbox mode.
+func WithSandbox(value bool) ContextOption {
+	return func(ctx *context) {
+		ctx.sandbox = value
+	}
+}
+
 // WithDelims defines used delimiters for rendering engine.
 func WithDelims(left, right string) ContextOption {
 	return func(ctx *context) {
@@ -64,6 +77,14 @@ func WithSecretReaders(values ...SecretReaderFunc) ContextOption {
 	}
 }
 
+// WithPasswordSeed defines the secret seed used by `passwordFor` template
+// function to derive passwords.
+func WithPasswordSeed(value []byte) ContextOption {
+	return func(ctx *context) {
+		ctx.passwordSeed = value
+	}
+}
+
 // WithValues defines template values injected via CLI.
 func WithValues(values Values) ContextOption {
 	return func(ctx *context) {
@@ -78,6 +99,14 @@ func WithFiles(files Files) ContextOption {
 	}
 }
 
+// WithPartials defines the filesystem used to resolve partials included with
+// `include` template function.
+func WithPartials(fsys fs.FS) ContextOption {
+	return func(ctx *context) {
+		ctx.partials = fsys
+	}
+}
+
 // NewContext returns a template rendering context.
 func NewContext(opts ...ContextOption) Context {
 	defaultContext := &context{
@@ -103,11 +132,14 @@ func NewContext(opts ...ContextOption) Context {
 type context struct {
 	name          string
 	strictMode    bool
+	sandbox       bool
 	delimLeft     string
 	delimRight    string
 	secretReaders []SecretReaderFunc
+	passwordSeed  []byte
 	values        Values
 	files         Files
+	partials      fs.FS
 }
 
 // Name returns template name
@@ -120,6 +152,11 @@ func (ctx *context) StrictMode() bool {
 	return ctx.strictMode
 }
 
+// Sandbox returns sandbox mode status of template engine.
+func (ctx *context) Sandbox() bool {
+	return ctx.sandbox
+}
+
 // Delims returns left and right delimiters used to compile the template.
 func (ctx *context) Delims() (left, right string) {
 	return ctx.delimLeft, ctx.delimRight
@@ -130,6 +167,11 @@ func (ctx *context) SecretReaders() []SecretReaderFunc {
 	return ctx.secretReaders
 }
 
+// PasswordSeed returns the seed used by `passwordFor` template function.
+func (ctx *context) PasswordSeed() []byte {
+	return ctx.passwordSeed
+}
+
 // Values returns binded values from rendering context.
 func (ctx *context) Values() Values {
 	return ctx.values
@@ -139,3 +181,8 @@ func (ctx *context) Values() Values {
 func (ctx *context) Files() Files {
 	return ctx.files
 }
+
+// Partials returns the filesystem used to resolve included partials.
+func (ctx *context) Partials() fs.FS {
+	return ctx.partials
+}
@@ -19,7 +19,9 @@ package engine
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"strconv"
 	"strings"
@@ -30,7 +32,10 @@ import (
 
 	"github.com/elastic/harp/pkg/sdk/security/crypto"
 	"github.com/elastic/harp/pkg/sdk/security/crypto/bech32"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/hash"
+	"github.com/elastic/harp/pkg/sdk/security/crypto/ssh"
 	"github.com/elastic/harp/pkg/sdk/security/diceware"
+	"github.com/elastic/harp/pkg/sdk/security/generator"
 	"github.com/elastic/harp/pkg/sdk/security/password"
 	"github.com/elastic/harp/pkg/template/engine/internal/codec"
 )
@@ -46,6 +51,8 @@ func FuncMap(secretReaders []SecretReaderFunc) template.FuncMap {
 		"paranoidPassword": password.Paranoid,
 		"noSymbolPassword": password.NoSymbol,
 		"strongPassword":   password.Strong,
+		"passwordFor":      PasswordFor(nil),
+		"generatePassword": generatePassword,
 		// Diceware
 		"customDiceware":   diceware.Diceware,
 		"basicDiceware":    diceware.Basic,
@@ -68,8 +75,24 @@ func FuncMap(secretReaders []SecretReaderFunc) template.FuncMap {
 		"cryptoKey":  crypto.Key,
 		"cryptoPair": crypto.Keypair,
 		"keyToBytes": crypto.KeyToBytes,
+		"bcrypt":     crypto.Bcrypt,
+		"argon2id":   crypto.Argon2id,
+		"totpSecret": crypto.TOTPSecret,
+		"totpNow":    crypto.TOTPNow,
+		"hash":       hashSum,
+		// SSH
+		"sshHostKey":       ssh.GenerateHostKey,
+		"sshAuthorizedKey": ssh.PublicKeyAuthorizedLine,
+		"sshFingerprint":   ssh.Fingerprint,
+		// X.509
+		"genCA":             genCA,
+		"genSelfSignedCert": genSelfSignedCert,
+		"genSignedCert":     genSignedCert,
+		// Partials
+		"include": (&includer{}).include,
 		// Secret
-		"secret": SecretReaders(secretReaders),
+		"secret":   SecretReaders(secretReaders),
+		"secretOr": SecretOrDefault(secretReaders),
 		// JWT/JWE
 		"encryptJwe": crypto.EncryptJWE,
 		"decryptJwe": crypto.DecryptJWE,
@@ -112,3 +135,61 @@ func FuncMap(secretReaders []SecretReaderFunc) template.FuncMap {
 
 	return f
 }
+
+// SandboxedFuncs lists template functions giving access to the host
+// environment or to secret backends.
+var SandboxedFuncs = []string{
+	"env",
+	"expandenv",
+	"getHostByName",
+	"secret",
+	"secretOr",
+}
+
+// Sandbox returns a copy of the given function map where all sandboxed
+// functions are replaced by a function raising a rendering error.
+func Sandbox(funcs template.FuncMap) template.FuncMap {
+	out := template.FuncMap{}
+	for k, v := range funcs {
+		out[k] = v
+	}
+
+	for _, name := range SandboxedFuncs {
+		if _, ok := out[name]; !ok {
+			continue
+		}
+		out[name] = disabledFunc(name)
+	}
+
+	return out
+}
+
+// -----------------------------------------------------------------------------
+
+func disabledFunc(name string) func(...interface{}) (interface{}, error) {
+	return func(...interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("template function '%s' is disabled in sandbox mode", name)
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// hashSum returns the hex encoded digest of the given value.
+func hashSum(algo, value string) (string, error) {
+	digest, err := hash.Sum(algo, []byte(value))
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(digest), nil
+}
+
+// generatePassword returns a credential generated with the given named profile.
+func generatePassword(profile string) (string, error) {
+	s, err := generator.Password(profile)
+	if err != nil {
+		return "", err
+	}
+
+	return s.Value, nil
+}
@@ -18,6 +18,7 @@
 package engine
 
 import (
+	"regexp"
 	"strings"
 	"testing"
 	"text/template"
@@ -115,3 +116,123 @@ func TestFuncs(t *testing.T) {
 		assert.Equal(t, tt.expect, b.String(), tt.tpl)
 	}
 }
+
+func TestFuncs_Credentials(t *testing.T) {
+	tests := []struct {
+		tpl    string
+		expect *regexp.Regexp
+	}{{
+		tpl:    `{{ "foo" | bcrypt 4 }}`,
+		expect: regexp.MustCompile(`^\$2a\$04\$.{53}$`),
+	}, {
+		tpl:    `{{ "foo" | argon2id }}`,
+		expect: regexp.MustCompile(`^\$argon2id\$v=19\$m=65536,t=3,p=4\$[A-Za-z0-9+/]{22}\$[A-Za-z0-9+/]{43}$`),
+	}, {
+		tpl:    `{{ derivePassword 1 "long" "password" "user" "example.com" }}`,
+		expect: regexp.MustCompile(`^ZedaFaxcZaso9\*$`),
+	}, {
+		tpl:    `{{ totpSecret }}`,
+		expect: regexp.MustCompile(`^[A-Z2-7]{32}$`),
+	}, {
+		tpl:    `{{ totpSecret | totpNow }}`,
+		expect: regexp.MustCompile(`^[0-9]{6}$`),
+	}, {
+		tpl:    `{{ generatePassword "pin" }}`,
+		expect: regexp.MustCompile(`^[0-9]{6}$`),
+	}, {
+		tpl:    `{{ "abc" | hash "sha3-256" }}`,
+		expect: regexp.MustCompile(`^3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532$`),
+	}, {
+		tpl:    `{{ $k := sshHostKey "ed25519" }}{{ sshAuthorizedKey $k "root@bastion" }}`,
+		expect: regexp.MustCompile(`^ssh-ed25519 [A-Za-z0-9+/=]+ root@bastion$`),
+	}, {
+		tpl:    `{{ sshHostKey "ecdsa" | sshFingerprint }}`,
+		expect: regexp.MustCompile(`^SHA256:[A-Za-z0-9+/]{43}$`),
+	}}
+
+	for _, tt := range tests {
+		var b strings.Builder
+		err := template.Must(template.New("test").Funcs(FuncMap(nil)).Parse(tt.tpl)).Execute(&b, nil)
+		assert.NoError(t, err)
+		assert.Regexp(t, tt.expect, b.String(), tt.tpl)
+	}
+
+	// Errors are propagated
+	var b strings.Builder
+	err := template.Must(template.New("test").Funcs(FuncMap(nil)).Parse(`{{ "foo" | bcrypt 64 }}`)).Execute(&b, nil)
+	assert.Error(t, err)
+}
+
+func TestPasswordFor(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+
+	render := func(tpl string) (string, error) {
+		return RenderContext(NewContext(WithPasswordSeed(seed)), tpl)
+	}
+
+	p1, err := render(`{{ passwordFor "service" "prod" }}`)
+	assert.NoError(t, err)
+	assert.Len(t, p1, 32)
+	p2, err := render(`{{ passwordFor "service" "prod" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, p1, p2)
+
+	p3, err := render(`{{ passwordFor "service" "prod" 16 "0123456789abcdef" }}`)
+	assert.NoError(t, err)
+	assert.Regexp(t, "^[0-9a-f]{16}$", p3)
+
+	// Invalid arguments
+	_, err = render(`{{ passwordFor "service" "prod" "16" }}`)
+	assert.Error(t, err)
+	_, err = render(`{{ passwordFor "service" "prod" 16 "0123456789abcdef" "foo" }}`)
+	assert.Error(t, err)
+
+	// Seed is required
+	_, err = RenderContext(NewContext(), `{{ passwordFor "service" "prod" }}`)
+	assert.Error(t, err)
+}
+
+func TestSandbox(t *testing.T) {
+	t.Setenv("HARP_SANDBOX_TEST", "foo")
+	reader := func(path string) (map[string]interface{}, error) {
+		return map[string]interface{}{"user": "foo"}, nil
+	}
+
+	// Secrets are accessible by default
+	out, err := RenderContext(NewContext(WithSecretReaders(reader)), `{{ secret "app/production/db" "user" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", out)
+	out, err = RenderContext(NewContext(WithSecretReaders(reader)), `{{ secretOr "admin" "app/production/db" "user" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", out)
+
+	// Environment is accessible by default
+	out, err = RenderContext(NewContext(), `{{ env "HARP_SANDBOX_TEST" }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", out)
+
+	// Sandboxed functions raise a rendering error
+	for _, tpl := range []string{
+		`{{ env "HARP_SANDBOX_TEST" }}`,
+		`{{ expandenv "${HARP_SANDBOX_TEST}" }}`,
+		`{{ getHostByName "localhost" }}`,
+		`{{ secret "app/production/db" "user" }}`,
+		`{{ secretOr "admin" "app/production/db" "user" }}`,
+	} {
+		_, err := RenderContext(NewContext(WithSandbox(true), WithSecretReaders(reader)), tpl)
+		assert.Error(t, err, tpl)
+		assert.Contains(t, err.Error(), "disabled in sandbox mode", tpl)
+	}
+
+	// Other functions are still available
+	out, err = RenderContext(NewContext(WithSandbox(true)), `{{ "foo" | upper }}`)
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", out)
+
+	// Input function map is not modified
+	funcs := FuncMap(nil)
+	_ = Sandbox(funcs)
+	env, ok := funcs["env"].(func(string) string)
+	assert.True(t, ok)
+	assert.Equal(t, "foo", env("HARP_SANDBOX_TEST"))
+}
@@ -0,0 +1,100 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// maxIncludeDepth defines the maximum partial nesting level.
+const maxIncludeDepth = 32
+
+// includer resolves and renders partials for the `include` template function.
+type includer struct {
+	fsys       fs.FS
+	leftDelim  string
+	rightDelim string
+	strictMode bool
+	funcs      template.FuncMap
+	stack      []string
+}
+
+// include renders the named partial using the given data.
+//
+// {{ include "partials/header.tmpl" . }}
+func (i *includer) include(name string, data interface{}) (string, error) {
+	// Check arguments
+	if i.fsys == nil {
+		return "", fmt.Errorf("unable to include partial '%s': no partial loader configured", name)
+	}
+
+	// Normalize partial name
+	name = strings.TrimPrefix(path.Clean(name), "./")
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("unable to include partial '%s': invalid partial path", name)
+	}
+
+	// Detect include cycles
+	for _, n := range i.stack {
+		if n == name {
+			return "", fmt.Errorf("unable to include partial '%s': include cycle detected (%s -> %s)", name, strings.Join(i.stack, " -> "), name)
+		}
+	}
+	if len(i.stack) >= maxIncludeDepth {
+		return "", fmt.Errorf("unable to include partial '%s': maximum include depth (%d) reached", name, maxIncludeDepth)
+	}
+
+	// Load partial
+	body, err := fs.ReadFile(i.fsys, name)
+	if err != nil {
+		return "", fmt.Errorf("unable to load partial '%s': %w", name, err)
+	}
+
+	// Compile partial
+	t, err := template.New(name).
+		Delims(i.leftDelim, i.rightDelim).
+		Funcs(i.funcs).
+		Parse(string(body))
+	if err != nil {
+		return "", fmt.Errorf("unable to compile partial '%s': %w", name, err)
+	}
+	if i.strictMode {
+		t.Option("missingkey=error")
+	} else {
+		t.Option("missingkey=zero")
+	}
+
+	// Render partial
+	i.stack = append(i.stack, name)
+	defer func() {
+		i.stack = i.stack[:len(i.stack)-1]
+	}()
+
+	var out bytes.Buffer
+	if err := t.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("unable to render partial '%s': %w", name, err)
+	}
+
+	// No error
+	return out.String(), nil
+}
@@ -0,0 +1,108 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInclude(t *testing.T) {
+	partials := fstest.MapFS{
+		"partials/header.tmpl":  {Data: []byte(`# {{ .name | upper }}`)},
+		"partials/nested.tmpl":  {Data: []byte(`{{ include "partials/header.tmpl" . }} - nested`)},
+		"partials/cycle-a.tmpl": {Data: []byte(`{{ include "partials/cycle-b.tmpl" . }}`)},
+		"partials/cycle-b.tmpl": {Data: []byte(`{{ include "partials/cycle-a.tmpl" . }}`)},
+		"partials/broken.tmpl":  {Data: []byte("line 1\n{{ .missing.value }}")},
+		"partials/invalid.tmpl": {Data: []byte("{{ if }}")},
+	}
+
+	tests := []struct {
+		name      string
+		tpl       string
+		expect    string
+		errorPart []string
+	}{
+		{
+			name:   "simple",
+			tpl:    `{{ include "partials/header.tmpl" .Values }}`,
+			expect: "# FOO",
+		},
+		{
+			name:   "nested",
+			tpl:    `{{ include "./partials/nested.tmpl" .Values }}`,
+			expect: "# FOO - nested",
+		},
+		{
+			name:   "pipeline",
+			tpl:    `{{ include "partials/header.tmpl" .Values | lower }}`,
+			expect: "# foo",
+		},
+		{
+			name:      "not found",
+			tpl:       `{{ include "partials/unknown.tmpl" . }}`,
+			errorPart: []string{"partials/unknown.tmpl"},
+		},
+		{
+			name:      "cycle",
+			tpl:       `{{ include "partials/cycle-a.tmpl" . }}`,
+			errorPart: []string{"include cycle detected", "partials/cycle-a.tmpl -> partials/cycle-b.tmpl -> partials/cycle-a.tmpl"},
+		},
+		{
+			name:      "execution error",
+			tpl:       `{{ include "partials/broken.tmpl" .Values }}`,
+			errorPart: []string{"partials/broken.tmpl:2:"},
+		},
+		{
+			name:      "compilation error",
+			tpl:       `{{ include "partials/invalid.tmpl" .Values }}`,
+			errorPart: []string{"partials/invalid.tmpl:1:"},
+		},
+		{
+			name:      "invalid path",
+			tpl:       `{{ include "../secret.tmpl" .Values }}`,
+			errorPart: []string{"invalid partial path"},
+		},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			out, err := RenderContext(NewContext(
+				WithPartials(partials),
+				WithValues(Values{"name": "foo"}),
+			), testCase.tpl)
+			if len(testCase.errorPart) > 0 {
+				assert.Error(t, err)
+				for _, part := range testCase.errorPart {
+					assert.Contains(t, err.Error(), part)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expect, out)
+		})
+	}
+}
+
+func TestInclude_NoLoader(t *testing.T) {
+	_, err := RenderContext(NewContext(), `{{ include "partials/header.tmpl" . }}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no partial loader configured")
+}
@@ -0,0 +1,72 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"fmt"
+
+	"github.com/elastic/harp/pkg/sdk/security/password"
+)
+
+// PasswordFor returns the `passwordFor` template function which derives
+// deterministic passwords from the given seed.
+//
+// {{ passwordFor "service" "env" }}
+// {{ passwordFor "service" "env" 64 }}
+// {{ passwordFor "service" "env" 16 "0123456789abcdef" }}
+func PasswordFor(seed []byte) func(string, string, ...interface{}) (string, error) {
+	return func(service, env string, opts ...interface{}) (string, error) {
+		// Check arguments
+		if len(seed) == 0 {
+			return "", fmt.Errorf("unable to derive password for '%s/%s': no password seed configured", service, env)
+		}
+		if len(opts) > 2 {
+			return "", fmt.Errorf("passwordFor accepts a length and a charset as optional arguments, got %d", len(opts))
+		}
+
+		length := password.DefaultDerivedPasswordLen
+		charset := password.DefaultDerivedPasswordCharset
+
+		// Optional length
+		if len(opts) > 0 {
+			l, ok := opts[0].(int)
+			if !ok {
+				return "", fmt.Errorf("passwordFor length must be an integer, got %T", opts[0])
+			}
+			length = l
+		}
+
+		// Optional charset
+		if len(opts) > 1 {
+			c, ok := opts[1].(string)
+			if !ok {
+				return "", fmt.Errorf("passwordFor charset must be a string, got %T", opts[1])
+			}
+			charset = c
+		}
+
+		// Delegate to implementation
+		p, err := password.Derive(seed, length, charset, service, env)
+		if err != nil {
+			return "", fmt.Errorf("unable to derive password for '%s/%s': %w", service, env, err)
+		}
+
+		// No error
+		return p, nil
+	}
+}
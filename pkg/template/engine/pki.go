@@ -0,0 +1,201 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/elastic/harp/pkg/sdk/security/crypto/pki"
+)
+
+var keyUsages = map[string]x509.KeyUsage{
+	"digitalSignature":  x509.KeyUsageDigitalSignature,
+	"contentCommitment": x509.KeyUsageContentCommitment,
+	"keyEncipherment":   x509.KeyUsageKeyEncipherment,
+	"dataEncipherment":  x509.KeyUsageDataEncipherment,
+	"keyAgreement":      x509.KeyUsageKeyAgreement,
+	"certSign":          x509.KeyUsageCertSign,
+	"crlSign":           x509.KeyUsageCRLSign,
+}
+
+var extKeyUsages = map[string]x509.ExtKeyUsage{
+	"any":             x509.ExtKeyUsageAny,
+	"serverAuth":      x509.ExtKeyUsageServerAuth,
+	"clientAuth":      x509.ExtKeyUsageClientAuth,
+	"codeSigning":     x509.ExtKeyUsageCodeSigning,
+	"emailProtection": x509.ExtKeyUsageEmailProtection,
+	"timeStamping":    x509.ExtKeyUsageTimeStamping,
+	"ocspSigning":     x509.ExtKeyUsageOCSPSigning,
+}
+
+// genCA returns a certificate authority built from the given parameters.
+//
+// {{ $ca := genCA (dict "cn" "Internal CA" "validity" "720h") }}
+func genCA(params map[string]interface{}) (*pki.Certificate, error) {
+	req, err := certificateRequest(params)
+	if err != nil {
+		return nil, fmt.Errorf("genCA: %w", err)
+	}
+
+	return pki.GenerateCA(req)
+}
+
+// genSelfSignedCert returns a self-signed certificate built from the given
+// parameters.
+//
+// {{ $cert := genSelfSignedCert (dict "cn" "localhost" "dns" (list "localhost")) }}
+func genSelfSignedCert(params map[string]interface{}) (*pki.Certificate, error) {
+	req, err := certificateRequest(params)
+	if err != nil {
+		return nil, fmt.Errorf("genSelfSignedCert: %w", err)
+	}
+
+	return pki.GenerateSelfSigned(req)
+}
+
+// genSignedCert returns a certificate signed by the given certificate
+// authority.
+//
+// {{ $cert := genSignedCert $ca (dict "cn" "api" "dns" (list "api.internal") "ips" (list "10.0.0.1")) }}
+// {{ $cert.Cert }}{{ $cert.Key }}
+func genSignedCert(ca *pki.Certificate, params map[string]interface{}) (*pki.Certificate, error) {
+	req, err := certificateRequest(params)
+	if err != nil {
+		return nil, fmt.Errorf("genSignedCert: %w", err)
+	}
+
+	return pki.GenerateSigned(ca, req)
+}
+
+// -----------------------------------------------------------------------------
+
+// certificateRequest converts template parameters to a certificate request.
+//
+// Supported parameters are cn, org, dns, ips, emails (string lists),
+// keyUsage, extKeyUsage (usage name lists), validity (Go duration),
+// notBefore (RFC3339), keyType (keygen specification) and key (PEM encoded
+// private key).
+//
+//nolint:gocyclo // flat parameter mapping
+func certificateRequest(params map[string]interface{}) (*pki.Request, error) {
+	req := &pki.Request{}
+
+	for k, v := range params {
+		var err error
+		switch k {
+		case "cn":
+			req.CommonName, err = toString(k, v)
+		case "org":
+			req.Organization, err = toStrings(k, v)
+		case "dns":
+			req.DNSNames, err = toStrings(k, v)
+		case "emails":
+			req.EmailAddresses, err = toStrings(k, v)
+		case "ips":
+			var ips []string
+			if ips, err = toStrings(k, v); err == nil {
+				for _, raw := range ips {
+					ip := net.ParseIP(raw)
+					if ip == nil {
+						return nil, fmt.Errorf("invalid IP address '%s'", raw)
+					}
+					req.IPAddresses = append(req.IPAddresses, ip)
+				}
+			}
+		case "keyUsage":
+			var names []string
+			if names, err = toStrings(k, v); err == nil {
+				for _, name := range names {
+					u, ok := keyUsages[name]
+					if !ok {
+						return nil, fmt.Errorf("unknown key usage '%s'", name)
+					}
+					req.KeyUsage |= u
+				}
+			}
+		case "extKeyUsage":
+			var names []string
+			if names, err = toStrings(k, v); err == nil {
+				for _, name := range names {
+					u, ok := extKeyUsages[name]
+					if !ok {
+						return nil, fmt.Errorf("unknown extended key usage '%s'", name)
+					}
+					req.ExtKeyUsage = append(req.ExtKeyUsage, u)
+				}
+			}
+		case "validity":
+			var raw string
+			if raw, err = toString(k, v); err == nil {
+				req.Validity, err = time.ParseDuration(raw)
+			}
+		case "notBefore":
+			var raw string
+			if raw, err = toString(k, v); err == nil {
+				req.NotBefore, err = time.Parse(time.RFC3339, raw)
+			}
+		case "keyType":
+			req.KeySpec, err = toString(k, v)
+		case "key":
+			var raw string
+			if raw, err = toString(k, v); err == nil {
+				req.Key, err = pki.ParsePrivateKey(raw)
+			}
+		default:
+			return nil, fmt.Errorf("unknown certificate parameter '%s'", k)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%s' parameter: %w", k, err)
+		}
+	}
+
+	// No error
+	return req, nil
+}
+
+func toString(name string, v interface{}) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string, got %T", name, v)
+	}
+	return s, nil
+}
+
+func toStrings(name string, v interface{}) ([]string, error) {
+	switch values := v.(type) {
+	case string:
+		return []string{values}, nil
+	case []string:
+		return values, nil
+	case []interface{}:
+		res := make([]string, 0, len(values))
+		for _, item := range values {
+			s, err := toString(name, item)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, s)
+		}
+		return res, nil
+	default:
+		return nil, fmt.Errorf("%s must be a string list, got %T", name, v)
+	}
+}
@@ -0,0 +1,85 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenSignedCert(t *testing.T) {
+	tpl := `{{ $ca := genCA (dict "cn" "Internal CA" "org" (list "Elastic")) -}}
+{{ $cert := genSignedCert $ca (dict "cn" "api" "dns" (list "api.internal") "ips" (list "10.0.0.1") "validity" "2h" "extKeyUsage" (list "serverAuth" "clientAuth")) -}}
+{{ $ca.Cert }}{{ $cert.Cert }}{{ $cert.Key }}`
+
+	var b strings.Builder
+	err := template.Must(template.New("test").Funcs(FuncMap(nil)).Parse(tpl)).Execute(&b, nil)
+	assert.NoError(t, err)
+
+	// Decode output
+	rest := []byte(b.String())
+	blocks := []*pem.Block{}
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, block)
+	}
+	assert.Len(t, blocks, 3)
+	assert.Equal(t, "PRIVATE KEY", blocks[2].Type)
+
+	ca, err := x509.ParseCertificate(blocks[0].Bytes)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Elastic"}, ca.Subject.Organization)
+	cert, err := x509.ParseCertificate(blocks[1].Bytes)
+	assert.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:   "api.internal",
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", cert.IPAddresses[0].String())
+}
+
+func TestCertificateRequest_Invalid(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"unknown": "value"},
+		{"cn": 1},
+		{"ips": []interface{}{"not-an-ip"}},
+		{"keyUsage": []interface{}{"everything"}},
+		{"extKeyUsage": "serverAuth2"},
+		{"validity": "1 day"},
+		{"notBefore": "yesterday"},
+		{"key": "not a key"},
+	}
+	for _, params := range tests {
+		_, err := genSelfSignedCert(params)
+		assert.Error(t, err, params)
+	}
+}
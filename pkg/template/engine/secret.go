@@ -25,21 +25,73 @@ import (
 type SecretReaderFunc func(path string) (map[string]interface{}, error)
 
 // SecretReaders uses given secret reader funcs to resolve secret path.
-func SecretReaders(secretReaders []SecretReaderFunc) func(string) (map[string]interface{}, error) {
-	return func(secretPath string) (map[string]interface{}, error) {
-		// For all secret readers
-		for _, sr := range secretReaders {
-			value, err := sr(secretPath)
-			if err != nil {
-				// Check next secret reader
-				continue
-			}
-
-			// No error
-			return value, nil
+//
+// When a key is given, only the matching secret value is returned, an error is
+// raised when the secret path or the key can't be resolved so that the
+// template rendering fails instead of producing an empty value.
+func SecretReaders(secretReaders []SecretReaderFunc) func(string, ...string) (interface{}, error) {
+	return func(secretPath string, keys ...string) (interface{}, error) {
+		// Check arguments
+		if len(keys) > 1 {
+			return nil, fmt.Errorf("only one secret key could be retrieved from '%s', got %d", secretPath, len(keys))
+		}
+
+		// Resolve secret path
+		secrets, err := readSecret(secretReaders, secretPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			return secrets, nil
+		}
+
+		// Lookup secret key
+		value, ok := secrets[keys[0]]
+		if !ok {
+			return nil, fmt.Errorf("no secret key '%s' found for '%s'", keys[0], secretPath)
+		}
+
+		// No error
+		return value, nil
+	}
+}
+
+// SecretOrDefault uses given secret reader funcs to resolve a secret value
+// identified by its path and key. The default value is returned when the
+// secret can't be resolved.
+func SecretOrDefault(secretReaders []SecretReaderFunc) func(interface{}, string, string) interface{} {
+	return func(defaultValue interface{}, secretPath, key string) interface{} {
+		// Resolve secret path
+		secrets, err := readSecret(secretReaders, secretPath)
+		if err != nil {
+			return defaultValue
+		}
+
+		// Lookup secret key
+		value, ok := secrets[key]
+		if !ok {
+			return defaultValue
+		}
+
+		return value
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+func readSecret(secretReaders []SecretReaderFunc, secretPath string) (map[string]interface{}, error) {
+	// For all secret readers
+	for _, sr := range secretReaders {
+		value, err := sr(secretPath)
+		if err != nil {
+			// Check next secret reader
+			continue
 		}
 
-		// Return error
-		return nil, fmt.Errorf("no value found for '%s', check secret path or secret reader settings", secretPath)
+		// No error
+		return value, nil
 	}
+
+	// Return error
+	return nil, fmt.Errorf("no value found for '%s', check secret path or secret reader settings", secretPath)
 }
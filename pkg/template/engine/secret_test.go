@@ -0,0 +1,70 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package engine
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretFuncs(t *testing.T) {
+	readers := []SecretReaderFunc{
+		func(path string) (map[string]interface{}, error) {
+			return nil, errors.New("not found")
+		},
+		func(path string) (map[string]interface{}, error) {
+			if path != "app/prod/db" {
+				return nil, errors.New("not found")
+			}
+			return map[string]interface{}{"user": "admin", "password": "foo"}, nil
+		},
+	}
+
+	tests := []struct {
+		name    string
+		tpl     string
+		expect  string
+		wantErr bool
+	}{
+		{name: "map", tpl: `{{ $s := secret "app/prod/db" }}{{ $s.user }}`, expect: "admin"},
+		{name: "key", tpl: `{{ secret "app/prod/db" "password" }}`, expect: "foo"},
+		{name: "missing path", tpl: `{{ secret "app/prod/cache" "password" }}`, wantErr: true},
+		{name: "missing key", tpl: `{{ secret "app/prod/db" "token" }}`, wantErr: true},
+		{name: "too many keys", tpl: `{{ secret "app/prod/db" "user" "password" }}`, wantErr: true},
+		{name: "optional", tpl: `{{ secretOr "default" "app/prod/db" "password" }}`, expect: "foo"},
+		{name: "optional missing path", tpl: `{{ secretOr "default" "app/prod/cache" "password" }}`, expect: "default"},
+		{name: "optional missing key", tpl: `{{ secretOr "default" "app/prod/db" "token" }}`, expect: "default"},
+	}
+	for _, tc := range tests {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			var b strings.Builder
+			err := template.Must(template.New("test").Funcs(FuncMap(readers)).Parse(testCase.tpl)).Execute(&b, nil)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expect, b.String())
+		})
+	}
+}
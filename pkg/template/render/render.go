@@ -0,0 +1,181 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package render writes rendered outputs only when their content changes so
+// that unchanged files keep their modification time.
+package render
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+// defaultFileMode is used for new files, rendered outputs often contain secrets.
+const defaultFileMode os.FileMode = 0o600
+
+// WriteIfChanged writes the given data to the given path on the OS filesystem
+// only when the content differs from the existing file. It returns true when
+// the file has been written.
+func WriteIfChanged(path string, data []byte) (bool, error) {
+	return NewWriter(afero.NewOsFs()).WriteIfChanged(path, data)
+}
+
+// Writer writes rendered outputs and records the changed paths.
+type Writer struct {
+	fs      afero.Fs
+	mu      sync.Mutex
+	changed []string
+}
+
+// NewWriter returns a writer instance working with the given filesystem.
+func NewWriter(fs afero.Fs) *Writer {
+	return &Writer{
+		fs: fs,
+	}
+}
+
+// WriteIfChanged writes the given data to the given path only when the content
+// hash differs from the existing file one. The file is replaced atomically
+// and keeps its permissions.
+func (w *Writer) WriteIfChanged(path string, data []byte) (bool, error) {
+	// Check arguments
+	if path == "" {
+		return false, errors.New("unable to write to a blank path")
+	}
+
+	// Compare with the existing file
+	mode := defaultFileMode
+	info, err := w.fs.Stat(path)
+	switch {
+	case err == nil:
+		if info.IsDir() {
+			return false, fmt.Errorf("unable to write '%s': path is a directory", path)
+		}
+		mode = info.Mode().Perm()
+
+		if info.Size() == int64(len(data)) {
+			same, errCompare := w.sameContent(path, data)
+			if errCompare != nil {
+				return false, errCompare
+			}
+			if same {
+				return false, nil
+			}
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return false, fmt.Errorf("unable to stat '%s': %w", path, err)
+	}
+
+	// Write the new content
+	if err := w.replace(path, data, mode); err != nil {
+		return false, err
+	}
+
+	// Record changed path
+	w.mu.Lock()
+	w.changed = append(w.changed, path)
+	w.mu.Unlock()
+
+	// No error
+	return true, nil
+}
+
+// Changed returns the sorted list of written paths.
+func (w *Writer) Changed() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	// Deduplicate paths
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, p := range w.changed {
+		if _, ok := seen[p]; ok {
+			continue
+		}
+		seen[p] = struct{}{}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// -----------------------------------------------------------------------------
+
+func (w *Writer) sameContent(path string, data []byte) (bool, error) {
+	f, err := w.fs.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("unable to open '%s': %w", path, err)
+	}
+	defer f.Close()
+
+	// Compute existing content hash
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false, fmt.Errorf("unable to read '%s': %w", path, err)
+	}
+	expected := sha256.Sum256(data)
+
+	return bytes.Equal(h.Sum(nil), expected[:]), nil
+}
+
+func (w *Writer) replace(path string, data []byte, mode os.FileMode) error {
+	// Ensure parent directory
+	dir := filepath.Dir(path)
+	if err := w.fs.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("unable to create '%s' directory: %w", dir, err)
+	}
+
+	// Write to a temporary file in the same directory
+	tmp, err := afero.TempFile(w.fs, dir, "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return fmt.Errorf("unable to create temporary file for '%s': %w", path, err)
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		_ = w.fs.Remove(tmpName)
+		return fmt.Errorf("unable to write temporary file for '%s': %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = w.fs.Remove(tmpName)
+		return fmt.Errorf("unable to close temporary file for '%s': %w", path, err)
+	}
+	if err := w.fs.Chmod(tmpName, mode); err != nil {
+		_ = w.fs.Remove(tmpName)
+		return fmt.Errorf("unable to set '%s' permissions: %w", path, err)
+	}
+
+	// Replace the file
+	if err := w.fs.Rename(tmpName, path); err != nil {
+		_ = w.fs.Remove(tmpName)
+		return fmt.Errorf("unable to replace '%s': %w", path, err)
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,102 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter_WriteIfChanged(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	underTest := NewWriter(fs)
+
+	// Created
+	changed, err := underTest.WriteIfChanged("out/app.conf", []byte("port=8080"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// Unchanged
+	changed, err = underTest.WriteIfChanged("out/app.conf", []byte("port=8080"))
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	// Same size, different content
+	assert.NoError(t, fs.Chmod("out/app.conf", 0o600))
+	changed, err = underTest.WriteIfChanged("out/app.conf", []byte("port=9090"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	content, err := afero.ReadFile(fs, "out/app.conf")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("port=9090"), content)
+
+	info, err := fs.Stat("out/app.conf")
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// Other file
+	changed, err = underTest.WriteIfChanged("out/db.conf", []byte("host=localhost"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	assert.Equal(t, []string{"out/app.conf", "out/db.conf"}, underTest.Changed())
+
+	// No temporary file left
+	files, err := afero.ReadDir(fs, "out")
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+}
+
+func TestWriter_WriteIfChanged_Errors(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	underTest := NewWriter(fs)
+
+	_, err := underTest.WriteIfChanged("", []byte("test"))
+	assert.Error(t, err)
+
+	assert.NoError(t, fs.MkdirAll("out", 0o755))
+	_, err = underTest.WriteIfChanged("out", []byte("test"))
+	assert.Error(t, err)
+
+	assert.Empty(t, underTest.Changed())
+}
+
+func TestWriteIfChanged_KeepsModificationTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.conf")
+
+	changed, err := WriteIfChanged(path, []byte("port=8080"))
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	assert.NoError(t, os.Chtimes(path, past, past))
+
+	changed, err = WriteIfChanged(path, []byte("port=8080"))
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(past))
+}
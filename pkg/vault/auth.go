@@ -0,0 +1,168 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+)
+
+const (
+	defaultAppRoleMountPath             = "approle"
+	defaultKubernetesMountPath          = "kubernetes"
+	defaultKubernetesServiceAccountPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+)
+
+// Authenticator describes Vault authentication method contract.
+type Authenticator interface {
+	Login(ctx context.Context, client *api.Client) (*api.Secret, error)
+}
+
+// AuthOption defines authentication method functional option pattern.
+type AuthOption func(*authOptions)
+
+type authOptions struct {
+	mountPath string
+	tokenPath string
+}
+
+// WithMountPath sets the authentication backend mount path.
+func WithMountPath(value string) AuthOption {
+	return func(opts *authOptions) {
+		opts.mountPath = value
+	}
+}
+
+// WithServiceAccountTokenPath sets the Kubernetes service account token path.
+func WithServiceAccountTokenPath(value string) AuthOption {
+	return func(opts *authOptions) {
+		opts.tokenPath = value
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// AppRole returns an authenticator using AppRole authentication method.
+func AppRole(roleID, secretID string, opts ...AuthOption) Authenticator {
+	dopts := &authOptions{
+		mountPath: defaultAppRoleMountPath,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	return &appRoleAuth{
+		roleID:    roleID,
+		secretID:  secretID,
+		mountPath: dopts.mountPath,
+	}
+}
+
+type appRoleAuth struct {
+	roleID    string
+	secretID  string
+	mountPath string
+}
+
+func (a *appRoleAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	// Check arguments
+	if client == nil {
+		return nil, errors.New("unable to login with a nil client")
+	}
+	if a.roleID == "" {
+		return nil, errors.New("approle: role_id is required")
+	}
+
+	// Send login request
+	secret, err := client.Logical().Write(loginPath(a.mountPath), map[string]interface{}{
+		"role_id":   a.roleID,
+		"secret_id": a.secretID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approle: unable to login: %w", err)
+	}
+
+	// No error
+	return secret, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// Kubernetes returns an authenticator using Kubernetes authentication method
+// with the pod service account token.
+func Kubernetes(role string, opts ...AuthOption) Authenticator {
+	dopts := &authOptions{
+		mountPath: defaultKubernetesMountPath,
+		tokenPath: defaultKubernetesServiceAccountPath,
+	}
+	for _, o := range opts {
+		o(dopts)
+	}
+
+	return &kubernetesAuth{
+		role:      role,
+		mountPath: dopts.mountPath,
+		tokenPath: dopts.tokenPath,
+	}
+}
+
+type kubernetesAuth struct {
+	role      string
+	mountPath string
+	tokenPath string
+}
+
+func (a *kubernetesAuth) Login(ctx context.Context, client *api.Client) (*api.Secret, error) {
+	// Check arguments
+	if client == nil {
+		return nil, errors.New("unable to login with a nil client")
+	}
+	if a.role == "" {
+		return nil, errors.New("kubernetes: role is required")
+	}
+
+	// Read service account token (read on each login to handle token rotation)
+	jwt, err := os.ReadFile(a.tokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: unable to read service account token: %w", err)
+	}
+
+	// Send login request
+	secret, err := client.Logical().Write(loginPath(a.mountPath), map[string]interface{}{
+		"role": a.role,
+		"jwt":  strings.TrimSpace(string(jwt)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes: unable to login: %w", err)
+	}
+
+	// No error
+	return secret, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func loginPath(mountPath string) string {
+	return path.Join("auth", strings.Trim(mountPath, "/"), "login")
+}
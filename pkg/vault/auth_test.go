@@ -0,0 +1,124 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func authServer(t *testing.T, loginPath string, expected map[string]interface{}) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != loginPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for k, v := range expected {
+			if body[k] != v {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"s.harp","renewable":true,"lease_duration":3600}}`))
+	}))
+}
+
+func testConfig(address string) *api.Config {
+	conf := api.DefaultConfig()
+	conf.Address = address
+	return conf
+}
+
+func TestNewClient_AppRole(t *testing.T) {
+	srv := authServer(t, "/v1/auth/ci/login", map[string]interface{}{
+		"role_id":   "role",
+		"secret_id": "secret",
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := NewClient(ctx, &Config{
+		API:  testConfig(srv.URL),
+		Auth: AppRole("role", "secret", WithMountPath("ci")),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "s.harp", client.Token())
+
+	// Invalid credentials
+	_, err = NewClient(ctx, &Config{
+		API:  testConfig(srv.URL),
+		Auth: AppRole("role", "invalid", WithMountPath("ci")),
+	})
+	assert.Error(t, err)
+
+	// Missing role
+	_, err = NewClient(ctx, &Config{
+		API:  testConfig(srv.URL),
+		Auth: AppRole("", "secret"),
+	})
+	assert.Error(t, err)
+}
+
+func TestNewClient_Kubernetes(t *testing.T) {
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, os.WriteFile(tokenPath, []byte("service-account-jwt\n"), 0o600))
+
+	srv := authServer(t, "/v1/auth/kubernetes/login", map[string]interface{}{
+		"role": "harp",
+		"jwt":  "service-account-jwt",
+	})
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client, err := NewClient(ctx, &Config{
+		API:       testConfig(srv.URL),
+		Namespace: "team",
+		Auth:      Kubernetes("harp", WithServiceAccountTokenPath(tokenPath)),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "s.harp", client.Token())
+
+	// Missing service account token
+	_, err = NewClient(ctx, &Config{
+		API:  testConfig(srv.URL),
+		Auth: Kubernetes("harp", WithServiceAccountTokenPath(filepath.Join(t.TempDir(), "missing"))),
+	})
+	assert.Error(t, err)
+}
@@ -18,9 +18,15 @@
 package vault
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/hashicorp/vault/api"
+	"go.uber.org/zap"
+
+	"github.com/elastic/harp/pkg/sdk/log"
+	"github.com/elastic/harp/pkg/sdk/types"
 
 	"github.com/elastic/harp/pkg/vault/cubbyhole"
 	"github.com/elastic/harp/pkg/vault/kv"
@@ -32,12 +38,66 @@ import (
 // ServiceFactory defines Vault client cervice contract.
 type ServiceFactory interface {
 	KV(mountPath string) (kv.Service, error)
-	Transit(mounthPath, keyName string) (transit.Service, error)
+	Transit(mounthPath, keyName string, opts ...transit.Option) (transit.Service, error)
 	Cubbyhole(mountPath string) (cubbyhole.Service, error)
 }
 
 // -----------------------------------------------------------------------------
 
+// Config defines Vault client settings.
+type Config struct {
+	// API defines the Vault API client configuration, api.DefaultConfig() is
+	// used when nil.
+	API *api.Config
+	// Namespace defines the Vault namespace to use.
+	Namespace string
+	// Auth defines the authentication method to use, the token is resolved
+	// from the environment when nil.
+	Auth Authenticator
+}
+
+// NewClient initializes a Vault client according to the given configuration.
+//
+// When an authentication method is used, the token is automatically renewed
+// before its expiration and a new login is made when the token can't be
+// renewed anymore, until the given context is cancelled.
+func NewClient(ctx context.Context, cfg *Config) (*api.Client, error) {
+	// Check arguments
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	conf := cfg.API
+	if conf == nil {
+		conf = api.DefaultConfig()
+	}
+
+	// Initialize vault client
+	vaultClient, err := api.NewClient(conf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize vault client: %w", err)
+	}
+	if cfg.Namespace != "" {
+		vaultClient.SetNamespace(cfg.Namespace)
+	}
+
+	// Use environment token
+	if types.IsNil(cfg.Auth) {
+		return vaultClient, nil
+	}
+
+	// Authenticate
+	secret, err := vaultClient.Auth().Login(ctx, cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("unable to authenticate vault client: %w", err)
+	}
+
+	// Start token lifecycle management
+	go keepAlive(ctx, vaultClient, cfg.Auth, secret)
+
+	// No error
+	return vaultClient, nil
+}
+
 // DefaultClient initialize a Vault client and wrap it in a Service factory.
 func DefaultClient() (ServiceFactory, error) {
 	// Initialize default config
@@ -72,10 +132,70 @@ func (c *client) KV(mountPath string) (kv.Service, error) {
 	return kv.New(c.Client, mountPath)
 }
 
-func (c *client) Transit(mountPath, keyName string) (transit.Service, error) {
-	return transit.New(c.Client, mountPath, keyName)
+func (c *client) Transit(mountPath, keyName string, opts ...transit.Option) (transit.Service, error) {
+	return transit.New(c.Client, mountPath, keyName, opts...)
 }
 
 func (c *client) Cubbyhole(mountPath string) (cubbyhole.Service, error) {
 	return cubbyhole.New(c.Client, mountPath)
 }
+
+// -----------------------------------------------------------------------------
+
+// reloginDelay defines the delay between failed login attempts.
+var reloginDelay = 5 * time.Second
+
+// keepAlive renews the client token until it expires and then logs in again.
+func keepAlive(ctx context.Context, client *api.Client, auth Authenticator, secret *api.Secret) {
+	for {
+		// Watch token lifetime
+		watcher, err := client.NewLifetimeWatcher(&api.LifetimeWatcherInput{
+			Secret: secret,
+		})
+		if err != nil {
+			log.For(ctx).Error("unable to initialize vault token lifetime watcher", zap.Error(err))
+			return
+		}
+		go watcher.Start()
+
+		// Wait for token expiration
+		if !waitExpiration(ctx, watcher) {
+			return
+		}
+
+		// Login again
+		for {
+			secret, err = client.Auth().Login(ctx, auth)
+			if err == nil {
+				break
+			}
+			log.For(ctx).Error("unable to renew vault authentication", zap.Error(err))
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(reloginDelay):
+			}
+		}
+	}
+}
+
+// waitExpiration returns true when the token can't be renewed anymore and false
+// when the context is cancelled.
+func waitExpiration(ctx context.Context, watcher *api.LifetimeWatcher) bool {
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.For(ctx).Warn("vault token renewal failed", zap.Error(err))
+			}
+			return true
+		case <-watcher.RenewCh():
+			log.For(ctx).Debug("vault token renewed")
+		}
+	}
+}
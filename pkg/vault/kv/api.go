@@ -30,6 +30,9 @@ var (
 	// ErrCustomMetadataDisabled is raised when trying to write a custom
 	// metadata with globally disabled feature.
 	ErrCustomMetadataDisabled = errors.New("custom metadata is disabled")
+	// ErrCASMismatch is raised when a check-and-set write is rejected because
+	// the secret current version doesn't match the expected one.
+	ErrCASMismatch = errors.New("check-and-set version mismatch")
 )
 
 // VaultMetadataDataKey represents the secret data key used to store
@@ -70,6 +73,13 @@ type SecretWriter interface {
 	WriteWithMeta(ctx context.Context, path string, secrets SecretData, meta SecretMetadata) error
 }
 
+// SecretCASWriter represents check-and-set secret writer feature contract
+// (K/V v2 only). The write is rejected if the secret current version doesn't
+// match the given version, a zero version only allows secret creation.
+type SecretCASWriter interface {
+	WriteCAS(ctx context.Context, path string, secrets SecretData, meta SecretMetadata, version uint32) error
+}
+
 // Service declares vault service contract.
 type Service interface {
 	SecretLister
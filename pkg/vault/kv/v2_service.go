@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/vault/api"
 
@@ -168,6 +169,18 @@ func (s *kvv2Backend) Write(ctx context.Context, path string, data SecretData) e
 }
 
 func (s *kvv2Backend) WriteWithMeta(ctx context.Context, path string, data SecretData, meta SecretMetadata) error {
+	return s.write(ctx, path, data, meta, nil)
+}
+
+func (s *kvv2Backend) WriteCAS(ctx context.Context, path string, data SecretData, meta SecretMetadata, version uint32) error {
+	return s.write(ctx, path, data, meta, map[string]interface{}{
+		"cas": version,
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+func (s *kvv2Backend) write(ctx context.Context, path string, data SecretData, meta SecretMetadata, options map[string]interface{}) error {
 	// Clean path first
 	secretPath := vpath.SanitizePath(path)
 	if secretPath == "" {
@@ -199,11 +212,20 @@ func (s *kvv2Backend) WriteWithMeta(ctx context.Context, path string, data Secre
 		data[VaultMetadataDataKey] = meta
 	}
 
-	// Write data
-	_, err := s.logical.Write(vpath.AddPrefixToVKVPath(secretPath, s.mountPath, "data"), map[string]interface{}{
+	// Prepare request
+	body := map[string]interface{}{
 		"data": data,
-	})
+	}
+	if len(options) > 0 {
+		body["options"] = options
+	}
+
+	// Write data
+	_, err := s.logical.Write(vpath.AddPrefixToVKVPath(secretPath, s.mountPath, "data"), body)
 	if err != nil {
+		if _, ok := options["cas"]; ok && strings.Contains(err.Error(), "check-and-set") {
+			return fmt.Errorf("unable to write secret data for path '%s': %w", path, ErrCASMismatch)
+		}
 		return fmt.Errorf("unable to write secret data for path '%s': %w", path, err)
 	}
 
@@ -19,6 +19,7 @@ package kv
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
@@ -532,3 +533,38 @@ func Test_KVV2_WriteWithMeta(t *testing.T) {
 		})
 	}
 }
+
+func Test_KVV2_WriteCAS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Arm mocks
+	logicalMock := logical.NewMockLogical(ctrl)
+	underTest, ok := V2(logicalMock, "secrets/", false).(SecretCASWriter)
+	if !ok {
+		t.Fatal("K/V v2 backend must implement check-and-set writes")
+	}
+
+	// Valid write
+	logicalMock.EXPECT().Write("secrets/data/application/foo", map[string]interface{}{
+		"data":    SecretData{"key": "value"},
+		"options": map[string]interface{}{"cas": uint32(3)},
+	}).Return(&vaultApi.Secret{}, nil)
+	if err := underTest.WriteCAS(context.Background(), "application/foo", SecretData{"key": "value"}, nil, 3); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Version mismatch
+	logicalMock.EXPECT().Write("secrets/data/application/foo", gomock.Any()).Return(nil, fmt.Errorf("Error making API request.\n\nCode: 400. Errors:\n\n* check-and-set parameter did not match the current version"))
+	err := underTest.WriteCAS(context.Background(), "application/foo", SecretData{"key": "value"}, nil, 0)
+	if !errors.Is(err, ErrCASMismatch) {
+		t.Errorf("expected ErrCASMismatch, got %v", err)
+	}
+
+	// Other errors
+	logicalMock.EXPECT().Write("secrets/data/application/foo", gomock.Any()).Return(nil, fmt.Errorf("foo"))
+	err = underTest.WriteCAS(context.Background(), "application/foo", SecretData{"key": "value"}, nil, 0)
+	if err == nil || errors.Is(err, ErrCASMismatch) {
+		t.Errorf("expected a non CAS error, got %v", err)
+	}
+}
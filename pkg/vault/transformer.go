@@ -18,6 +18,8 @@
 package vault
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"path"
 	"strings"
@@ -27,7 +29,9 @@ import (
 	"github.com/elastic/harp/pkg/sdk/value/encryption/aead"
 	"github.com/elastic/harp/pkg/sdk/value/encryption/envelope"
 	"github.com/elastic/harp/pkg/sdk/value/encryption/secretbox"
+	"github.com/elastic/harp/pkg/sdk/types"
 	vaultpath "github.com/elastic/harp/pkg/vault/path"
+	"github.com/elastic/harp/pkg/vault/transit"
 )
 
 type DataEncryption string
@@ -45,10 +49,19 @@ func init() {
 // Vault returns an envelope encryption using a remote transit backend for key
 // encryption.
 // vault:<path>:<data encryption>
+//
+// Values could also be directly encrypted by the transit backend so that key
+// material never leaves Vault.
+// vault:transit:<path>[:<base64 derivation context>]
 func FromKey(key string) (value.Transformer, error) {
 	// Remove the prefix
 	key = strings.TrimPrefix(key, "vault:")
 
+	// Direct transit encryption
+	if strings.HasPrefix(key, "transit:") {
+		return fromTransitKey(strings.TrimPrefix(key, "transit:"))
+	}
+
 	// Split path / encryption
 	parts := strings.SplitN(key, ":", 2)
 	if len(parts) != 2 {
@@ -97,3 +110,78 @@ func Transformer(mountPath, keyName string, dataEncryption DataEncryption) (valu
 	// Wrap the transformer with envelope
 	return envelope.Transformer(backend, dataEncryptionFunc)
 }
+
+// TransitTransformer returns a value transformer using the given transit
+// backend service to encrypt and decrypt values.
+func TransitTransformer(backend transit.Service) (value.Transformer, error) {
+	// Check arguments
+	if types.IsNil(backend) {
+		return nil, fmt.Errorf("unable to initialize transit transformer with a nil backend")
+	}
+
+	return &transitTransformer{
+		backend: backend,
+	}, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func fromTransitKey(key string) (value.Transformer, error) {
+	// Split path / context
+	parts := strings.SplitN(key, ":", 2)
+
+	// Split transit backend path
+	mountPath, keyName := path.Split(parts[0])
+	if mountPath == "" || keyName == "" {
+		return nil, fmt.Errorf("key format error, transit key path must be '<mount>/<key>'")
+	}
+
+	// Decode derivation context
+	opts := []transit.Option{}
+	if len(parts) == 2 {
+		derivationContext, err := base64.StdEncoding.DecodeString(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("key format error, unable to decode transit derivation context: %w", err)
+		}
+		opts = append(opts, transit.WithContext(derivationContext))
+	}
+
+	// Create default vault client
+	client, err := DefaultClient()
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize vault client: %w", err)
+	}
+
+	// Create transit backend service
+	backend, err := client.Transit(vaultpath.SanitizePath(mountPath), keyName, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize vault transit backend service: %w", err)
+	}
+
+	// Delegate to transformer
+	return TransitTransformer(backend)
+}
+
+type transitTransformer struct {
+	backend transit.Service
+}
+
+func (t *transitTransformer) To(ctx context.Context, input []byte) ([]byte, error) {
+	out, err := t.backend.Encrypt(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("vault: unable to encrypt value: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
+
+func (t *transitTransformer) From(ctx context.Context, input []byte) ([]byte, error) {
+	out, err := t.backend.Decrypt(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("vault: unable to decrypt value: %w", err)
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,69 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package vault
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTransit struct{}
+
+func (fakeTransit) Encrypt(_ context.Context, cleartext []byte) ([]byte, error) {
+	return append([]byte("vault:v1:"), cleartext...), nil
+}
+
+func (fakeTransit) Decrypt(_ context.Context, encrypted []byte) ([]byte, error) {
+	if len(encrypted) < 9 || string(encrypted[:9]) != "vault:v1:" {
+		return nil, errors.New("invalid ciphertext")
+	}
+	return encrypted[9:], nil
+}
+
+func TestTransitTransformer(t *testing.T) {
+	_, err := TransitTransformer(nil)
+	assert.Error(t, err)
+
+	underTest, err := TransitTransformer(fakeTransit{})
+	assert.NoError(t, err)
+
+	encrypted, err := underTest.To(context.Background(), []byte("msg"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("vault:v1:msg"), encrypted)
+
+	decrypted, err := underTest.From(context.Background(), encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("msg"), decrypted)
+
+	_, err = underTest.From(context.Background(), []byte("msg"))
+	assert.Error(t, err)
+}
+
+func TestFromKey_Transit(t *testing.T) {
+	for _, key := range []string{
+		"vault:transit:harp",
+		"vault:transit:transit/",
+		"vault:transit:transit/harp:%%%",
+	} {
+		_, err := FromKey(key)
+		assert.Error(t, err, key)
+	}
+}
@@ -36,15 +36,39 @@ type service struct {
 	logical   logical.Logical
 	mountPath string
 	keyName   string
+	context   []byte
+}
+
+// Option defines the transit service functional option pattern.
+type Option func(*service)
+
+// WithContext sets the key derivation context used for encryption and
+// decryption operations, it is required by transit keys created with
+// derivation enabled.
+func WithContext(value []byte) Option {
+	return func(s *service) {
+		s.context = value
+	}
 }
 
 // New instanciates a Vault transit backend encryption service.
-func New(client *api.Client, mountPath, keyName string) (Service, error) {
-	return &service{
-		logical:   client.Logical(),
+func New(client *api.Client, mountPath, keyName string, opts ...Option) (Service, error) {
+	return fromLogical(client.Logical(), mountPath, keyName, opts...), nil
+}
+
+func fromLogical(l logical.Logical, mountPath, keyName string, opts ...Option) Service {
+	s := &service{
+		logical:   l,
 		mountPath: strings.TrimSuffix(path.Clean(mountPath), "/"),
 		keyName:   keyName,
-	}, nil
+	}
+
+	// Apply options
+	for _, o := range opts {
+		o(s)
+	}
+
+	return s
 }
 
 // -----------------------------------------------------------------------------
@@ -55,6 +79,9 @@ func (s *service) Encrypt(ctx context.Context, cleartext []byte) ([]byte, error)
 	data := map[string]interface{}{
 		"plaintext": base64.StdEncoding.EncodeToString(cleartext),
 	}
+	if len(s.context) > 0 {
+		data["context"] = base64.StdEncoding.EncodeToString(s.context)
+	}
 
 	// Send to Vault.
 	secret, err := s.logical.Write(encryptPath, data)
@@ -86,6 +113,9 @@ func (s *service) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error
 	data := map[string]interface{}{
 		"ciphertext": string(ciphertext),
 	}
+	if len(s.context) > 0 {
+		data["context"] = base64.StdEncoding.EncodeToString(s.context)
+	}
 
 	// Send to Vault.
 	secret, err := s.logical.Write(decryptPath, data)
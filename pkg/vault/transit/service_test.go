@@ -0,0 +1,66 @@
+// Licensed to Elasticsearch B.V. under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. Elasticsearch B.V. licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package transit
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	vaultApi "github.com/hashicorp/vault/api"
+
+	"github.com/elastic/harp/pkg/vault/logical"
+)
+
+func TestService_Context(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Arm mocks
+	logicalMock := logical.NewMockLogical(ctrl)
+	underTest := fromLogical(logicalMock, "transit/", "harp", WithContext([]byte("tenant-1")))
+
+	logicalMock.EXPECT().Write("transit/encrypt/harp", map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString([]byte("msg")),
+		"context":   base64.StdEncoding.EncodeToString([]byte("tenant-1")),
+	}).Return(&vaultApi.Secret{
+		Data: map[string]interface{}{"ciphertext": "vault:v1:Zm9v"},
+	}, nil)
+	encrypted, err := underTest.Encrypt(context.Background(), []byte("msg"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(encrypted) != "vault:v1:Zm9v" {
+		t.Errorf("unexpected ciphertext: %s", encrypted)
+	}
+
+	logicalMock.EXPECT().Write("transit/decrypt/harp", map[string]interface{}{
+		"ciphertext": "vault:v1:Zm9v",
+		"context":    base64.StdEncoding.EncodeToString([]byte("tenant-1")),
+	}).Return(&vaultApi.Secret{
+		Data: map[string]interface{}{"plaintext": base64.StdEncoding.EncodeToString([]byte("msg"))},
+	}, nil)
+	decrypted, err := underTest.Decrypt(context.Background(), encrypted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(decrypted) != "msg" {
+		t.Errorf("unexpected plaintext: %s", decrypted)
+	}
+}
@@ -42,3 +42,15 @@ func (Fuzz) TemplateReader() {
 		golang.FuzzRun("template-reader"),
 	)
 }
+
+func (Fuzz) PasetoDecrypt() {
+	mg.SerialDeps(
+		golang.FuzzNative("github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4", "FuzzDecrypt"),
+	)
+}
+
+func (Fuzz) PasetoVerify() {
+	mg.SerialDeps(
+		golang.FuzzNative("github.com/elastic/harp/pkg/sdk/security/crypto/paseto/v4", "FuzzVerify"),
+	)
+}